@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jarfernandez/check-image/internal/imageutil"
@@ -11,15 +12,36 @@ import (
 )
 
 var maxAge uint
+var ageNotBefore string
+var ageNotAfter string
+
+// Constraints of the age check, as reported in AgeDetails.FailedConstraints.
+const (
+	constraintMaxAge    = "max-age"
+	constraintNotBefore = "not-before"
+	constraintNotAfter  = "not-after"
+)
+
+// ageBounds are the absolute bounds of the creation date of the age check,
+// nil when not set.
+type ageBounds struct {
+	notBefore *time.Time
+	notAfter  *time.Time
+}
 
 var ageCmd = &cobra.Command{
 	Use:   "age image",
 	Short: "Validate container image age",
 	Long: `Validate the age of a container image.
 
+The image must be at most --max-age days old. --not-before and --not-after
+additionally bound its creation date to absolute RFC3339 timestamps, e.g. to
+require images built after a security patch was released.
+
 ` + imageArgFormatsDoc,
 	Example: `  check-image age nginx:latest
   check-image age nginx:latest --max-age 30
+  check-image age nginx:latest --not-before 2024-07-01T00:00:00Z
   check-image age oci:/path/to/layout:1.0
   check-image age oci-archive:/path/to/image.tar:latest
   check-image age docker-archive:/path/to/image.tar:tag`,
@@ -27,7 +49,11 @@ var ageCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		return runCheckCmd(checkAge, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runAge(ctx, img, maxAge)
+			bounds, err := parseAgeBounds(ageNotBefore, ageNotAfter)
+			if err != nil {
+				return nil, err
+			}
+			return runAge(ctx, img, maxAge, bounds)
 		}, ctx, args[0], OutputFmt)
 	},
 }
@@ -35,9 +61,43 @@ var ageCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(ageCmd)
 	ageCmd.Flags().UintVarP(&maxAge, "max-age", "a", defaultMaxAgeDays, "Maximum age in days (optional)")
+	addAgeBoundFlags(ageCmd)
+}
+
+// addAgeBoundFlags registers the absolute creation date bounds of the age
+// check on cmd.
+func addAgeBoundFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&ageNotBefore, "not-before", "", "Fail when the image was created before this RFC3339 timestamp, e.g. 2024-07-01T00:00:00Z (optional)")
+	cmd.Flags().StringVar(&ageNotAfter, "not-after", "", "Fail when the image was created after this RFC3339 timestamp (optional)")
+}
+
+// parseAgeBounds parses the RFC3339 bounds of the creation date. Empty values
+// leave a bound unset.
+func parseAgeBounds(notBefore, notAfter string) (ageBounds, error) {
+	var b ageBounds
+	for _, v := range []struct {
+		name, value string
+		target      **time.Time
+	}{
+		{constraintNotBefore, notBefore, &b.notBefore},
+		{constraintNotAfter, notAfter, &b.notAfter},
+	} {
+		if v.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v.value)
+		if err != nil {
+			return ageBounds{}, fmt.Errorf("invalid %s %q, expected an RFC3339 timestamp such as 2024-07-01T00:00:00Z", v.name, v.value)
+		}
+		*v.target = &t
+	}
+	if b.notBefore != nil && b.notAfter != nil && b.notAfter.Before(*b.notBefore) {
+		return ageBounds{}, fmt.Errorf("invalid age bounds: not-after %s is before not-before %s", notAfter, notBefore)
+	}
+	return b, nil
 }
 
-func runAge(ctx context.Context, imageName string, maxAgeDays uint) (*output.CheckResult, error) {
+func runAge(ctx context.Context, imageName string, maxAgeDays uint, bounds ageBounds) (*output.CheckResult, error) {
 	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
 	if err != nil {
 		return nil, err
@@ -48,14 +108,46 @@ func runAge(ctx context.Context, imageName string, maxAgeDays uint) (*output.Che
 		return nil, fmt.Errorf("image creation date is not set")
 	}
 
-	age := time.Since(config.Created.Time).Hours() / 24
-	passed := age <= float64(maxAgeDays)
+	created := config.Created.Time
+	age := time.Since(created).Hours() / 24
+	details := output.AgeDetails{
+		CreatedAt: config.Created.Format(time.RFC3339),
+		AgeDays:   age,
+		MaxAge:    maxAgeDays,
+	}
+
+	var failures []string
+	if age > float64(maxAgeDays) {
+		details.FailedConstraints = append(details.FailedConstraints, constraintMaxAge)
+		failures = append(failures, fmt.Sprintf("Image is older than %d days", maxAgeDays))
+	}
+	if bounds.notBefore != nil {
+		details.NotBefore = bounds.notBefore.Format(time.RFC3339)
+		if created.Before(*bounds.notBefore) {
+			details.FailedConstraints = append(details.FailedConstraints, constraintNotBefore)
+			failures = append(failures, fmt.Sprintf("Image was created before the not-before bound %s", details.NotBefore))
+		}
+	}
+	if bounds.notAfter != nil {
+		details.NotAfter = bounds.notAfter.Format(time.RFC3339)
+		if created.After(*bounds.notAfter) {
+			details.FailedConstraints = append(details.FailedConstraints, constraintNotAfter)
+			failures = append(failures, fmt.Sprintf("Image was created after the not-after bound %s", details.NotAfter))
+		}
+	}
 
-	var msg string
+	passed := len(failures) == 0
+	msg := strings.Join(failures, "; ")
 	if passed {
 		msg = fmt.Sprintf("Image is less than %d days old", maxAgeDays)
-	} else {
-		msg = fmt.Sprintf("Image is older than %d days", maxAgeDays)
+		switch {
+		case details.NotBefore != "" && details.NotAfter != "":
+			msg += fmt.Sprintf(" and was created between %s and %s", details.NotBefore, details.NotAfter)
+		case details.NotBefore != "":
+			msg += fmt.Sprintf(" and was created after %s", details.NotBefore)
+		case details.NotAfter != "":
+			msg += fmt.Sprintf(" and was created before %s", details.NotAfter)
+		}
 	}
 
 	return &output.CheckResult{
@@ -63,10 +155,18 @@ func runAge(ctx context.Context, imageName string, maxAgeDays uint) (*output.Che
 		Image:   imageName,
 		Passed:  passed,
 		Message: msg,
-		Details: output.AgeDetails{
-			CreatedAt: config.Created.Format(time.RFC3339),
-			AgeDays:   age,
-			MaxAge:    maxAgeDays,
-		},
+		Details: details,
 	}, nil
 }
+
+// validateAgeBoundsConfig checks the not-before and not-after bounds of the
+// age section of cfg.
+func validateAgeBoundsConfig(cfg *allConfig) error {
+	if cfg.Checks.Age == nil {
+		return nil
+	}
+	if _, err := parseAgeBounds(cfg.Checks.Age.NotBefore, cfg.Checks.Age.NotAfter); err != nil {
+		return fmt.Errorf("invalid checks.age: %w", err)
+	}
+	return nil
+}
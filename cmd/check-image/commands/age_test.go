@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,6 +35,9 @@ func TestAgeCommandFlags(t *testing.T) {
 	assert.NotNil(t, flag)
 	assert.Equal(t, "a", flag.Shorthand)
 	assert.Equal(t, "90", flag.DefValue)
+
+	assert.NotNil(t, ageCmd.Flags().Lookup("not-before"))
+	assert.NotNil(t, ageCmd.Flags().Lookup("not-after"))
 }
 
 func TestRunAge(t *testing.T) {
@@ -91,7 +95,7 @@ func TestRunAge(t *testing.T) {
 			})
 
 			// Run command
-			result, err := runAge(context.Background(), imageRef, tt.maxAge)
+			result, err := runAge(context.Background(), imageRef, tt.maxAge, ageBounds{})
 			require.NoError(t, err)
 
 			// Assert on struct
@@ -114,6 +118,151 @@ func TestRunAge(t *testing.T) {
 
 func TestRunAge_InvalidImage(t *testing.T) {
 	// Test with invalid image reference
-	_, err := runAge(context.Background(), "nonexistent:image", 90)
+	_, err := runAge(context.Background(), "nonexistent:image", 90, ageBounds{})
 	require.Error(t, err)
 }
+
+func TestParseAgeBounds(t *testing.T) {
+	b, err := parseAgeBounds("", "")
+	require.NoError(t, err)
+	assert.Nil(t, b.notBefore)
+	assert.Nil(t, b.notAfter)
+
+	b, err = parseAgeBounds("2024-07-01T00:00:00Z", "2025-01-01T12:00:00+02:00")
+	require.NoError(t, err)
+	require.NotNil(t, b.notBefore)
+	require.NotNil(t, b.notAfter)
+	assert.True(t, b.notBefore.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, b.notAfter.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)))
+
+	_, err = parseAgeBounds("2024-07-01", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid not-before "2024-07-01", expected an RFC3339 timestamp`)
+
+	_, err = parseAgeBounds("", "tomorrow")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid not-after "tomorrow"`)
+
+	_, err = parseAgeBounds("2025-01-01T00:00:00Z", "2024-01-01T00:00:00Z")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not-after 2024-01-01T00:00:00Z is before not-before 2025-01-01T00:00:00Z")
+}
+
+func TestRunAge_Bounds(t *testing.T) {
+	created := time.Now().Add(-10 * 24 * time.Hour).UTC().Truncate(time.Second)
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: created})
+	at := func(d time.Duration) *time.Time {
+		t := created.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		maxAge      uint
+		bounds      ageBounds
+		wantPass    bool
+		wantFailed  []string
+		wantMessage string
+	}{
+		{
+			name:        "Created after not-before",
+			maxAge:      90,
+			bounds:      ageBounds{notBefore: at(-time.Hour)},
+			wantPass:    true,
+			wantMessage: "Image is less than 90 days old and was created after ",
+		},
+		{
+			name:        "Created exactly at not-before",
+			maxAge:      90,
+			bounds:      ageBounds{notBefore: at(0)},
+			wantPass:    true,
+			wantMessage: "and was created after",
+		},
+		{
+			name:        "Created before not-before",
+			maxAge:      90,
+			bounds:      ageBounds{notBefore: at(time.Hour)},
+			wantFailed:  []string{constraintNotBefore},
+			wantMessage: "Image was created before the not-before bound " + at(time.Hour).Format(time.RFC3339),
+		},
+		{
+			name:        "Created after not-after",
+			maxAge:      90,
+			bounds:      ageBounds{notAfter: at(-time.Hour)},
+			wantFailed:  []string{constraintNotAfter},
+			wantMessage: "Image was created after the not-after bound " + at(-time.Hour).Format(time.RFC3339),
+		},
+		{
+			name:        "Within both bounds",
+			maxAge:      90,
+			bounds:      ageBounds{notBefore: at(-time.Hour), notAfter: at(time.Hour)},
+			wantPass:    true,
+			wantMessage: "and was created between",
+		},
+		{
+			name:        "Too old and before not-before",
+			maxAge:      5,
+			bounds:      ageBounds{notBefore: at(time.Hour)},
+			wantFailed:  []string{constraintMaxAge, constraintNotBefore},
+			wantMessage: "Image is older than 5 days; Image was created before the not-before bound",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runAge(context.Background(), imageRef, tt.maxAge, tt.bounds)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPass, result.Passed)
+			assert.Contains(t, result.Message, tt.wantMessage)
+
+			details, ok := result.Details.(output.AgeDetails)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantFailed, details.FailedConstraints)
+			if tt.bounds.notBefore != nil {
+				assert.Equal(t, tt.bounds.notBefore.Format(time.RFC3339), details.NotBefore)
+			}
+			if tt.bounds.notAfter != nil {
+				assert.Equal(t, tt.bounds.notAfter.Format(time.RFC3339), details.NotAfter)
+			}
+		})
+	}
+}
+
+func TestParseAllConfig_AgeBounds(t *testing.T) {
+	cfg, err := parseAllConfig([]byte("checks:\n  age:\n    not-before: 2024-07-01T00:00:00Z\n    not-after: 2030-01-01T00:00:00Z\n"), "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-07-01T00:00:00Z", cfg.Checks.Age.NotBefore)
+	assert.Equal(t, "2030-01-01T00:00:00Z", cfg.Checks.Age.NotAfter)
+
+	_, err = parseAllConfig([]byte(`{"checks": {"age": {"not-before": "July 1st"}}}`), "config.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid checks.age: invalid not-before "July 1st"`)
+}
+
+func TestApplyAgeConfig_Bounds(t *testing.T) {
+	cfg := &ageCheckConfig{NotBefore: "2024-07-01T00:00:00Z", NotAfter: "2030-01-01T00:00:00Z"}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().UintVar(&maxAge, "max-age", 90, "")
+		addAgeBoundFlags(cmd)
+		return cmd
+	}
+
+	t.Run("config bounds apply", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("max-age", "10"))
+		applyAgeConfig(cmd, cfg)
+		assert.Equal(t, "2024-07-01T00:00:00Z", ageNotBefore, "bounds apply even when --max-age is set")
+		assert.Equal(t, "2030-01-01T00:00:00Z", ageNotAfter)
+	})
+
+	t.Run("flags take precedence", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("not-before", "2025-01-01T00:00:00Z"))
+		applyAgeConfig(cmd, cfg)
+		assert.Equal(t, "2025-01-01T00:00:00Z", ageNotBefore)
+		assert.Equal(t, "2030-01-01T00:00:00Z", ageNotAfter)
+	})
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// ageRules are the per-source max-age rules of checks.age.rules, nil when
+// none apply.
+var ageRules []ageRule
+
+// ageRuleConfig is an entry of checks.age.rules: the max-age of the images
+// whose repository matches Match.
+type ageRuleConfig struct {
+	// Match is a repository, e.g. ghcr.io/internal/app, matched exactly, or by
+	// prefix when it ends with "*", e.g. ghcr.io/internal/*.
+	Match  string `json:"match"             yaml:"match"`
+	MaxAge *uint  `json:"max-age,omitempty" yaml:"max-age,omitempty"`
+}
+
+// ageRule is a validated ageRuleConfig.
+type ageRule struct {
+	match  string
+	maxAge uint
+}
+
+func newAgeRules(cfgs []ageRuleConfig) []ageRule {
+	var rules []ageRule
+	for _, c := range cfgs {
+		rules = append(rules, ageRule{match: c.Match, maxAge: *c.MaxAge})
+	}
+	return rules
+}
+
+// matches reports whether the rule matches any of the names of a repository.
+func (r ageRule) matches(names []string) bool {
+	prefix, wildcard := strings.CutSuffix(r.match, "*")
+	for _, name := range names {
+		if name == r.match || wildcard && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAgeRules checks the rules of the age section of cfg.
+func validateAgeRules(cfg *allConfig) error {
+	if cfg.Checks.Age == nil {
+		return nil
+	}
+	for i, r := range cfg.Checks.Age.Rules {
+		switch {
+		case r.Match == "":
+			return fmt.Errorf("invalid checks.age.rules entry %d: match is required", i+1)
+		case strings.Contains(strings.TrimSuffix(r.Match, "*"), "*"):
+			return fmt.Errorf("invalid checks.age.rules entry %d: %q may only contain * at the end", i+1, r.Match)
+		case r.MaxAge == nil:
+			return fmt.Errorf("invalid checks.age.rules entry %d: max-age is required", i+1)
+		}
+	}
+	return nil
+}
+
+// repositoryNames returns the names an age rule can match for an image: its
+// repository with the registry, and for Docker Hub images also with docker.io
+// instead of index.docker.io. Images of other transports have no names.
+func repositoryNames(imageName string) []string {
+	repo, err := imageutil.GetImageRepository(imageName)
+	if err != nil {
+		return nil
+	}
+	names := []string{repo}
+	if rest, ok := strings.CutPrefix(repo, "index.docker.io/"); ok {
+		names = append(names, "docker.io/"+rest)
+	}
+	return names
+}
+
+// maxAgeFor returns the max-age of an image: that of the first rule matching
+// its repository, or defaultMaxAge. The match of the rule is returned too,
+// empty when none matches.
+func maxAgeFor(imageName string, rules []ageRule, defaultMaxAge uint) (uint, string) {
+	if len(rules) == 0 {
+		return defaultMaxAge, ""
+	}
+	names := repositoryNames(imageName)
+	for _, r := range rules {
+		if r.matches(names) {
+			return r.maxAge, r.match
+		}
+	}
+	return defaultMaxAge, ""
+}
+
+// runAgeWithRules runs the age check with the max-age of the image and the
+// bounds, recording the matching rule in the details.
+func runAgeWithRules(ctx context.Context, imageName string, rules []ageRule, defaultMaxAge uint, bounds ageBounds) (*output.CheckResult, error) {
+	limit, match := maxAgeFor(imageName, rules, defaultMaxAge)
+	result, err := runAge(ctx, imageName, limit, bounds)
+	if err != nil || match == "" {
+		return result, err
+	}
+	if d, ok := result.Details.(output.AgeDetails); ok {
+		d.Rule = match
+		result.Details = d
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxAgeFor(t *testing.T) {
+	rules := []ageRule{
+		{match: "ghcr.io/internal/*", maxAge: 30},
+		{match: "docker.io/*", maxAge: 180},
+		{match: "quay.io/org/app", maxAge: 60},
+	}
+
+	tests := []struct {
+		image     string
+		wantAge   uint
+		wantMatch string
+	}{
+		{"ghcr.io/internal/api:1.0", 30, "ghcr.io/internal/*"},
+		{"ghcr.io/internal/team/web@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", 30, "ghcr.io/internal/*"},
+		{"ghcr.io/external/api:1.0", 90, ""},
+		{"nginx:latest", 180, "docker.io/*"},
+		{"index.docker.io/org/app:1.0", 180, "docker.io/*"},
+		{"quay.io/org/app:2.0", 60, "quay.io/org/app"},
+		{"quay.io/org/app-debug:2.0", 90, ""},
+		{"oci:/path/to/layout:latest", 90, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			age, match := maxAgeFor(tt.image, rules, 90)
+			assert.Equal(t, tt.wantAge, age)
+			assert.Equal(t, tt.wantMatch, match)
+		})
+	}
+}
+
+func TestMaxAgeFor_FirstMatchWins(t *testing.T) {
+	rules := []ageRule{
+		{match: "ghcr.io/internal/legacy/*", maxAge: 365},
+		{match: "ghcr.io/internal/*", maxAge: 30},
+	}
+	age, match := maxAgeFor("ghcr.io/internal/legacy/app:1", rules, 90)
+	assert.Equal(t, uint(365), age)
+	assert.Equal(t, "ghcr.io/internal/legacy/*", match)
+}
+
+func TestParseAllConfig_AgeRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", "checks:\n  age:\n    rules:\n      - match: ghcr.io/internal/*\n        max-age: 30\n", ""},
+		{"missing match", "checks:\n  age:\n    rules:\n      - max-age: 30\n", "entry 1: match is required"},
+		{"missing max-age", "checks:\n  age:\n    rules:\n      - match: docker.io/*\n", "entry 1: max-age is required"},
+		{"inner wildcard", "checks:\n  age:\n    rules:\n      - match: ghcr.io/*/app\n        max-age: 30\n", `"ghcr.io/*/app" may only contain * at the end`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseAllConfig([]byte(tt.data), "config.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Len(t, cfg.Checks.Age.Rules, 1)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid checks.age.rules")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestApplyAgeConfig_Rules(t *testing.T) {
+	ruleAge := uint(30)
+	windowAge := uint(365)
+	cfg := &ageCheckConfig{Rules: []ageRuleConfig{{Match: "ghcr.io/internal/*", MaxAge: &ruleAge}}}
+
+	t.Run("rules apply", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := &cobra.Command{}
+		cmd.Flags().UintVar(&maxAge, "max-age", 90, "")
+		applyAgeConfig(cmd, cfg)
+		assert.Equal(t, []ageRule{{match: "ghcr.io/internal/*", maxAge: 30}}, ageRules)
+	})
+
+	t.Run("max-age flag disables rules", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := &cobra.Command{}
+		cmd.Flags().UintVar(&maxAge, "max-age", 90, "")
+		require.NoError(t, cmd.Flags().Set("max-age", "10"))
+		applyAgeConfig(cmd, cfg)
+		assert.Nil(t, ageRules)
+	})
+
+	t.Run("active window disables rules", func(t *testing.T) {
+		resetAllGlobals(t)
+		policyNow = func() time.Time { return time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC) }
+		withWindow := *cfg
+		withWindow.Windows = []ageWindowConfig{{policyWindow: policyWindow{From: "2026-07-01", Until: "2026-07-31"}, MaxAge: &windowAge}}
+		cmd := &cobra.Command{}
+		cmd.Flags().UintVar(&maxAge, "max-age", 90, "")
+		applyAgeConfig(cmd, &withWindow)
+		assert.Nil(t, ageRules)
+		assert.Equal(t, uint(365), maxAge)
+	})
+}
+
+func TestRunAgeWithRules(t *testing.T) {
+	registry := newTestRegistry(t)
+	image := registry + "/internal/app:1.0"
+	_, err := imageutil.CopyImage(context.Background(), createTestImage(t, testImageOptions{created: time.Now().Add(-45 * 24 * time.Hour)}), image)
+	require.NoError(t, err)
+
+	rules := []ageRule{{match: registry + "/internal/*", maxAge: 30}}
+	result, err := runAgeWithRules(context.Background(), image, rules, 90, ageBounds{})
+	require.NoError(t, err)
+	assert.False(t, result.Passed, "the rule lowers max-age below the image age")
+	details := result.Details.(output.AgeDetails)
+	assert.Equal(t, uint(30), details.MaxAge)
+	assert.Equal(t, registry+"/internal/*", details.Rule)
+
+	result, err = runAgeWithRules(context.Background(), image, nil, 90, ageBounds{})
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.Details.(output.AgeDetails).Rule)
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	ver "github.com/jarfernandez/check-image/internal/version"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// validationArtifactType is the artifact type of the referrers pushed
+	// with --annotate-registry.
+	validationArtifactType = "application/vnd.check-image.validation.v1+json"
+
+	annotationPassed     = "dev.check-image.passed"
+	annotationPolicyHash = "dev.check-image.policy-hash"
+	annotationCreated    = "org.opencontainers.image.created"
+)
+
+var annotateRegistry bool
+
+// validateAnnotateFlag fails early when --annotate-registry cannot be honoured,
+// so the checks do not run against an image that cannot be annotated.
+func validateAnnotateFlag(imageName string) error {
+	if !annotateRegistry {
+		return nil
+	}
+	if _, err := imageutil.ParseDestination(imageName); err != nil {
+		return fmt.Errorf("--annotate-registry requires a registry image reference: %w", err)
+	}
+	return nil
+}
+
+// policyHash identifies the policy an image was validated against: the checks
+// that were selected, their parameters, and the content of the policy files
+// they read. Policy files are identified by their content rather than their
+// path, so inline policies, written to a new temporary file on every run,
+// keep their hash; files that cannot be read again (stdin) are identified by
+// their path only. Policy windows are left out, as the limits they set are
+// part of the parameters.
+func policyHash(checks []checkDef, p checkParams) string {
+	h := sha256.New()
+	for _, c := range checks {
+		fmt.Fprintf(h, "check:%s\n", c.name)
+	}
+	for i, path := range []*string{&p.registryPolicy, &p.secretsPolicy, &p.labelsPolicy, &p.userPolicy, &p.provenancePolicy, &p.goldenSpec, &p.entrypointPolicy, &p.entropyPolicy} {
+		if *path == "" || *path == "-" {
+			continue
+		}
+		if data, err := os.ReadFile(*path); err == nil {
+			fmt.Fprintf(h, "file:%d:%d\n", i, len(data))
+			h.Write(data)
+			*path = ""
+		}
+	}
+	p.ageWindow, p.sizeWindow = nil, nil
+	fmt.Fprintf(h, "params:%+v\n", p)
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// annotateValidation records the outcome of run in the registry as an OCI
+// referrer artifact of the validated image and returns the artifact digest.
+// The outcome is also set as manifest annotations so tools can read it from
+// the referrers index without fetching the payload.
+func annotateValidation(ctx context.Context, imageName string, run *allRun, passed bool) (string, error) {
+	subject, err := imageutil.ResolveDescriptor(ctx, imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image for annotation: %w", err)
+	}
+
+	checks := make([]string, 0, len(run.results))
+	for _, r := range run.results {
+		checks = append(checks, r.Check)
+	}
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+	data, err := json.Marshal(output.ValidationAnnotation{
+		Image:      redactText(imageName),
+		Digest:     subject.Digest.String(),
+		Passed:     passed,
+		PolicyHash: run.policyHash,
+		Checks:     checks,
+		Version:    ver.GetBuildInfo().Version,
+		CheckedAt:  checkedAt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode validation annotation: %w", err)
+	}
+
+	digest, err := imageutil.AttachArtifact(ctx, imageName, *subject, validationArtifactType, reportMediaType, data, map[string]string{
+		annotationPassed:     strconv.FormatBool(passed),
+		annotationPolicyHash: run.policyHash,
+		annotationCreated:    checkedAt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to annotate image: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"image":    logutil.SanitizeLogValue(imageName),
+		"artifact": digest.String(),
+		"passed":   passed,
+	}).Debug("Validation outcome recorded in the registry")
+	return digest.String(), nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAll_AnnotateRegistry(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		wantPassed bool
+	}{
+		{name: "Passing image", user: "1000", wantPassed: true},
+		{name: "Failing image", user: "root", wantPassed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			includeChecks = "age,user"
+			annotateRegistry = true
+			OutputFmt = output.FormatJSON
+
+			src := createTestImage(t, testImageOptions{
+				user:    tt.user,
+				created: time.Now().Add(-24 * time.Hour),
+			})
+			image := newTestRegistry(t) + "/org/app:1.0"
+			desc, err := imageutil.CopyImage(context.Background(), src, image)
+			require.NoError(t, err)
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runAll(allCmd, image))
+			})
+
+			var result output.AllResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			require.NotEmpty(t, result.Annotation)
+
+			ref, err := name.ParseReference(image)
+			require.NoError(t, err)
+			idx, err := remote.Referrers(ref.Context().Digest(desc.Digest.String()))
+			require.NoError(t, err)
+			manifest, err := idx.IndexManifest()
+			require.NoError(t, err)
+			require.Len(t, manifest.Manifests, 1)
+			referrer := manifest.Manifests[0]
+			assert.Equal(t, result.Annotation, referrer.Digest.String())
+			assert.Equal(t, validationArtifactType, referrer.ArtifactType)
+
+			artifactRef := ref.Context().Digest(referrer.Digest.String())
+			artifact, err := remote.Image(artifactRef)
+			require.NoError(t, err)
+			artifactManifest, err := artifact.Manifest()
+			require.NoError(t, err)
+			annotations := artifactManifest.Annotations
+			assert.Equal(t, strconv.FormatBool(tt.wantPassed), annotations[annotationPassed])
+			assert.Contains(t, annotations[annotationPolicyHash], "sha256:")
+
+			data, err := imageutil.GetArtifactData(context.Background(), artifactRef.String())
+			require.NoError(t, err)
+			var payload output.ValidationAnnotation
+			require.NoError(t, json.Unmarshal(data, &payload))
+			assert.Equal(t, tt.wantPassed, payload.Passed)
+			assert.Equal(t, desc.Digest.String(), payload.Digest)
+			assert.Equal(t, []string{checkAge, checkUser}, payload.Checks)
+			assert.Equal(t, annotations[annotationPolicyHash], payload.PolicyHash)
+		})
+	}
+}
+
+func TestRunAll_AnnotateRegistry_RequiresRegistryImage(t *testing.T) {
+	resetAllGlobals(t)
+	annotateRegistry = true
+
+	err := runAll(allCmd, createTestImage(t, testImageOptions{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--annotate-registry requires a registry image reference")
+}
+
+func TestPolicyHash(t *testing.T) {
+	checks := []checkDef{{name: checkAge}, {name: checkUser}}
+	p := checkParams{maxAge: 30}
+
+	base := policyHash(checks, p)
+	assert.Equal(t, base, policyHash(checks, p), "hash must be stable")
+	assert.NotEqual(t, base, policyHash(checks[:1], p), "check selection changes the hash")
+	assert.NotEqual(t, base, policyHash(checks, checkParams{maxAge: 60}), "parameters change the hash")
+
+	policy := writeRequiredConfig(t, "min-uid: 1000\n")
+	withPolicy := policyHash(checks, checkParams{maxAge: 30, userPolicy: policy})
+	require.NoError(t, os.WriteFile(policy, []byte("min-uid: 2000\n"), 0600))
+	assert.NotEqual(t, withPolicy, policyHash(checks, checkParams{maxAge: 30, userPolicy: policy}), "policy content changes the hash")
+
+	moved := filepath.Join(t.TempDir(), "user-policy.yaml")
+	require.NoError(t, os.WriteFile(moved, []byte("min-uid: 2000\n"), 0600))
+	assert.Equal(t, policyHash(checks, checkParams{maxAge: 30, userPolicy: policy}), policyHash(checks, checkParams{maxAge: 30, userPolicy: moved}), "the policy path does not change the hash")
+
+	window := &output.PolicyWindow{Reason: "freeze"}
+	assert.Equal(t, base, policyHash(checks, checkParams{maxAge: 30, ageWindow: window}), "windows are represented by their limits")
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// anonymize replaces the registry and repository names of the validated image
+// with pseudonyms in every rendered result and log line.
+var anonymize bool
+
+// applyAnonymizeConfig applies the anonymize config key unless --anonymize
+// was given.
+func applyAnonymizeConfig(cmd *cobra.Command, enabled *bool) {
+	if enabled != nil && !cmd.Flags().Changed("anonymize") {
+		anonymize = *enabled
+	}
+}
+
+// setupAnonymization registers the names of imageName with the active
+// redactor when anonymization is enabled, on top of the redaction patterns of
+// the config. Names of images validated earlier in the same process stay
+// registered until a config is applied again, which resets the redactor.
+func setupAnonymization(imageName string) {
+	if !anonymize {
+		return
+	}
+	names := imageNamePseudonyms(imageName)
+	if len(names) == 0 {
+		return
+	}
+	activeRedactor = activeRedactor.WithNames(names)
+	installRedactingFormatter()
+}
+
+// imageNamePseudonyms maps the names under which the registry and repository
+// of imageName can appear in results to their pseudonyms: the registry, the
+// repository path, the full repository, and the repository as written, e.g.
+// nginx for index.docker.io/library/nginx. Tags and digests are kept. Images
+// of other transports than daemon/registry have no names.
+func imageNamePseudonyms(imageName string) map[string]string {
+	ref, err := imageutil.ParseReference(imageName)
+	if err != nil || ref.Transport != imageutil.TransportDaemonRegistry {
+		return nil
+	}
+	parsed, err := name.ParseReference(ref.Path)
+	if err != nil {
+		return nil
+	}
+	repo := parsed.Context()
+	registry := redact.Pseudonym("registry", repo.RegistryStr())
+	repository := redact.Pseudonym("repository", repo.RepositoryStr())
+	full := registry + "/" + repository
+
+	names := map[string]string{
+		repo.RegistryStr():          registry,
+		repo.RepositoryStr():        repository,
+		repo.Name():                 full,
+		writtenRepository(ref.Path): full,
+	}
+	if repo.RegistryStr() == name.DefaultRegistry {
+		names["docker.io"] = registry
+		names["index.docker.io"] = registry
+	}
+	return names
+}
+
+// writtenRepository returns the repository part of an image reference as
+// written, without its tag or digest.
+func writtenRepository(ref string) string {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
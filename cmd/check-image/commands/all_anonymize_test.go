@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/redact"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageNamePseudonyms(t *testing.T) {
+	registry := redact.Pseudonym("registry", "ghcr.io")
+	repository := redact.Pseudonym("repository", "org/app")
+
+	names := imageNamePseudonyms("ghcr.io/org/app:1.0")
+	assert.Equal(t, map[string]string{
+		"ghcr.io":         registry,
+		"org/app":         repository,
+		"ghcr.io/org/app": registry + "/" + repository,
+	}, names)
+
+	hub := imageNamePseudonyms("nginx@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	full := redact.Pseudonym("registry", "index.docker.io") + "/" + redact.Pseudonym("repository", "library/nginx")
+	assert.Equal(t, full, hub["nginx"], "the repository as written is anonymized")
+	assert.Equal(t, full, hub["index.docker.io/library/nginx"])
+	assert.Contains(t, hub, "docker.io")
+
+	assert.Nil(t, imageNamePseudonyms("oci:/path/to/layout:latest"))
+}
+
+func TestWrittenRepository(t *testing.T) {
+	assert.Equal(t, "nginx", writtenRepository("nginx:latest"))
+	assert.Equal(t, "localhost:5000/org/app", writtenRepository("localhost:5000/org/app:1.0"))
+	assert.Equal(t, "localhost:5000/org/app", writtenRepository("localhost:5000/org/app@sha256:abc"))
+	assert.Equal(t, "localhost:5000/org/app", writtenRepository("localhost:5000/org/app"))
+}
+
+func TestApplyAnonymizeConfig(t *testing.T) {
+	enabled := true
+	resetAllGlobals(t)
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "")
+	applyAnonymizeConfig(cmd, &enabled)
+	assert.True(t, anonymize)
+
+	resetAllGlobals(t)
+	cmd = &cobra.Command{}
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "")
+	require.NoError(t, cmd.Flags().Set("anonymize", "false"))
+	applyAnonymizeConfig(cmd, &enabled)
+	assert.False(t, anonymize, "the flag wins over the config")
+}
+
+func TestRunAll_Anonymize(t *testing.T) {
+	resetAllGlobals(t)
+	registry := newTestRegistry(t)
+	image := registry + "/internal/team/app:1.0"
+	desc, err := imageutil.CopyImage(context.Background(), createTestImage(t, testImageOptions{user: "1000"}), image)
+	require.NoError(t, err)
+	digest := desc.Digest.String()
+
+	anonymize = true
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image+"@"+digest))
+	})
+
+	assert.NotContains(t, out, registry)
+	assert.NotContains(t, out, "internal/team")
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.True(t, result.Redacted)
+	want := redact.Pseudonym("registry", registry) + "/" + redact.Pseudonym("repository", "internal/team/app") + ":1.0@" + digest
+	assert.Equal(t, want, result.Image, "tags and digests are kept")
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	ver "github.com/jarfernandez/check-image/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var auditLog string
+
+// recordAudit appends the validation of imageName to --audit-log, and to the
+// dashboard history of daemon-watch while it is served. Runs where no check
+// was executed are not recorded.
+func recordAudit(ctx context.Context, cmd *cobra.Command, imageName string, run *allRun) error {
+	if (auditLog == "" && watchHistory == nil) || len(run.results) == 0 {
+		return nil
+	}
+	report := run.report(imageName)
+	report.PolicyHash = run.policyHash
+	report.PolicyProfile = run.profile
+	return appendAuditRecord(ctx, newAuditRecord(ctx, cmd, imageName, report))
+}
+
+// recordReusedAudit records report, the result of an earlier validation of
+// the same image and policy reported again for imageName by daemon-watch, as
+// recordAudit does, marked as reused.
+func recordReusedAudit(ctx context.Context, cmd *cobra.Command, imageName string, report output.AllResult) error {
+	if (auditLog == "" && watchHistory == nil) || len(report.Checks) == 0 {
+		return nil
+	}
+	record := newAuditRecord(ctx, cmd, imageName, report)
+	record.Reused = true
+	return appendAuditRecord(ctx, record)
+}
+
+// newAuditRecord returns the audit record of report, the validation of
+// imageName.
+func newAuditRecord(ctx context.Context, cmd *cobra.Command, imageName string, report output.AllResult) auditlog.Record {
+	record := auditlog.NewRecord(cmd.CommandPath())
+	record.Image = report.Image
+	record.Digest = auditImageDigest(ctx, imageName)
+	record.PolicyHash = report.PolicyHash
+	record.PolicyProfile = report.PolicyProfile
+	record.Passed = report.Passed
+	record.FailedChecks = failedCheckNames(report.Checks)
+	record.Version = ver.GetBuildInfo().Version
+	return record
+}
+
+// appendAuditRecord adds record to the dashboard history and --audit-log.
+func appendAuditRecord(ctx context.Context, record auditlog.Record) error {
+	if watchHistory != nil {
+		watchHistory.Add(record)
+	}
+	if auditLog == "" {
+		return nil
+	}
+	return auditlog.Append(ctx, auditLog, record)
+}
+
+// auditImageDigest returns the manifest digest of the validated image, or an
+// empty string when the image cannot be read.
+func auditImageDigest(ctx context.Context, imageName string) string {
+	img, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return ""
+	}
+	defer cleanup()
+	digest, err := img.Digest()
+	if err != nil {
+		return ""
+	}
+	return digest.String()
+}
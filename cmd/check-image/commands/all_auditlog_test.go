@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditLog(t *testing.T, path string) []auditlog.Record {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []auditlog.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r auditlog.Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestRunAll_AuditLog(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	includeChecks = "user"
+	auditLog = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	passing := createTestImage(t, testImageOptions{user: "1000"})
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, passing))
+		require.NoError(t, runAll(allCmd, failing))
+	})
+
+	records := readAuditLog(t, auditLog)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, passing, records[0].Image)
+	assert.True(t, records[0].Passed)
+	assert.Empty(t, records[0].FailedChecks)
+	assert.Equal(t, "check-image all", records[0].Command)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, records[0].Digest)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, records[0].PolicyHash)
+	assert.NotEmpty(t, records[0].Time)
+
+	assert.Equal(t, failing, records[1].Image)
+	assert.False(t, records[1].Passed)
+	assert.Equal(t, []string{"user"}, records[1].FailedChecks)
+	assert.Equal(t, records[0].PolicyHash, records[1].PolicyHash, "both images were validated against the same policy")
+	assert.NotEqual(t, records[0].Digest, records[1].Digest)
+}
+
+func TestRunAll_AuditLogErrors(t *testing.T) {
+	image := createTestImage(t, testImageOptions{user: "1000"})
+
+	t.Run("Invalid destination", func(t *testing.T) {
+		resetAllGlobals(t)
+		includeChecks = "user"
+		auditLog = "-"
+		err := runAll(allCmd, image)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the audit log cannot be written to stdout")
+	})
+
+	t.Run("Unwritable log fails the run", func(t *testing.T) {
+		resetAllGlobals(t)
+		OutputFmt = output.FormatJSON
+		includeChecks = "user"
+		auditLog = filepath.Join(t.TempDir(), "missing", "audit.jsonl")
+		var err error
+		captureStdout(t, func() { err = runAll(allCmd, image) })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to open audit log")
+	})
+}
@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// bulkImageArg is the image argument that makes the all command read the
+// images to validate from stdin.
+const bulkImageArg = "-"
+
+// groupByRepository is the --group-by value that aggregates the results of a
+// bulk run per repository.
+const groupByRepository = "repository"
+
+var groupBy string
+
+// validateGroupBy rejects --group-by values other than repository, and its use
+// outside bulk runs.
+func validateGroupBy(imageName string) error {
+	if groupBy == "" {
+		return nil
+	}
+	if groupBy != groupByRepository {
+		return fmt.Errorf("invalid --group-by value %q: must be %s", groupBy, groupByRepository)
+	}
+	if imageName != bulkImageArg && selectedImageSource() == nil {
+		return fmt.Errorf("--group-by requires reading the image list from stdin, --from-sbom, or --from-lockfile")
+	}
+	return nil
+}
+
+// runAllBulk validates every image listed on stdin with the all-checks
+// validation and renders an aggregated result.
+func runAllBulk(cmd *cobra.Command) error {
+	if err := validateBulkStdin(); err != nil {
+		return err
+	}
+	data, err := fileutil.ReadFileOrStdin(bulkImageArg)
+	if err != nil {
+		return fmt.Errorf("error reading image list: %w", err)
+	}
+	images := parseImageList(string(data))
+	if len(images) == 0 {
+		return fmt.Errorf("no images to validate were read from stdin")
+	}
+	log.WithField("images", len(images)).Info("Validating images from stdin")
+
+	bulk, err := validateBulkImages(cmd, images)
+	if err != nil {
+		return err
+	}
+	return renderBulkImages(bulk)
+}
+
+// renderBulkImages renders the result of a bulk run, grouped per repository
+// with --group-by repository.
+func renderBulkImages(bulk output.BulkResult) error {
+	reports := bulk.Images
+	if groupBy == groupByRepository {
+		bulk.Repositories = groupRepositories(bulk.Images)
+		bulk.Summary.Repositories = len(bulk.Repositories)
+		bulk.Images = nil
+	}
+	return renderBulk(bulk, reports)
+}
+
+// validateBulkImages validates every image in order with the all-checks
+// validation and aggregates their reports.
+func validateBulkImages(cmd *cobra.Command, images []string) (output.BulkResult, error) {
+	for _, image := range images {
+		if err := validateAnnotateFlag(image); err != nil {
+			return output.BulkResult{}, err
+		}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tracker := newProgress(len(images))
+	bulk := output.BulkResult{Images: []output.AllResult{}}
+	for _, image := range images {
+		report, err := bulkImage(ctx, cmd, image)
+		if err != nil {
+			return output.BulkResult{}, err
+		}
+		recordProgress(tracker, report)
+		bulk.Images = append(bulk.Images, report)
+		if report.Passed {
+			bulk.Summary.Passed++
+		} else {
+			bulk.Summary.Failed++
+		}
+	}
+	printProgressSummary(tracker)
+	bulk.Summary.Total = len(bulk.Images)
+	bulk.Passed = bulk.Summary.Failed == 0
+	return bulk, nil
+}
+
+// renderBulk renders an aggregated result. CSV output has the findings of
+// reports, the image reports in the order they were validated.
+func renderBulk(bulk output.BulkResult, reports []output.AllResult) error {
+	hookReport = bulk
+	switch OutputFmt {
+	case output.FormatJSON:
+		return writeReport(bulk)
+	case output.FormatCSV:
+		var findings []output.Finding
+		for _, report := range reports {
+			findings = append(findings, output.ReportFindings(report)...)
+		}
+		return output.RenderCSV(stdout, findings)
+	}
+	printBulkSummary(bulk)
+	return nil
+}
+
+// bulkImage validates one image of a bulk run and returns its report.
+func bulkImage(ctx context.Context, cmd *cobra.Command, image string) (output.AllResult, error) {
+	log.WithField("image", logutil.SanitizeLogValue(image)).Info("Validating image")
+
+	run, report, err := evaluateImage(cmd, image)
+	if err != nil {
+		return output.AllResult{}, err
+	}
+	if len(run.results) == 0 {
+		if OutputFmt == output.FormatText {
+			printNoChecks(run.skipped)
+		}
+		return report, nil
+	}
+	if annotateRegistry {
+		if report.Annotation, err = annotateValidation(ctx, image, run, report.Passed); err != nil {
+			return output.AllResult{}, err
+		}
+		if OutputFmt == output.FormatText {
+			fmt.Fprintf(stdout, "Validation outcome recorded in the registry as %s\n\n", report.Annotation)
+		}
+	}
+	return report, nil
+}
+
+// groupRepositories aggregates image reports per repository, in order of
+// first appearance. Each repository takes the worst outcome of its images.
+func groupRepositories(reports []output.AllResult) []output.RepositoryResult {
+	var repos []output.RepositoryResult
+	index := make(map[string]int)
+	for _, report := range reports {
+		repo := imageRepository(report.Image)
+		i, ok := index[repo]
+		if !ok {
+			i = len(repos)
+			index[repo] = i
+			repos = append(repos, output.RepositoryResult{Repository: repo, Passed: true, Outcome: output.OutcomePassed})
+		}
+		r := &repos[i]
+		r.Images = append(r.Images, report)
+		r.Summary.Total++
+		if report.Passed {
+			r.Summary.Passed++
+		} else {
+			r.Summary.Failed++
+			r.Passed = false
+		}
+		if outcome := imageOutcome(report); outcomeRank(outcome) > outcomeRank(r.Outcome) {
+			r.Outcome = outcome
+		}
+	}
+	return repos
+}
+
+// imageRepository returns the repository of an image reference without its
+// tag or digest: the fully qualified registry repository, or the transport and
+// path of an OCI layout or archive. References that cannot be parsed are
+// their own repository.
+func imageRepository(image string) string {
+	ref, err := imageutil.ParseReference(image)
+	if err != nil {
+		return image
+	}
+	if ref.Transport != imageutil.TransportDaemonRegistry {
+		return string(ref.Transport) + ":" + ref.Path
+	}
+	named, err := name.ParseReference(ref.Path)
+	if err != nil {
+		return image
+	}
+	return named.Context().Name()
+}
+
+// imageOutcome returns the outcome of an image report; execution errors rank
+// worse than failed checks, since the image could not be judged at all.
+func imageOutcome(report output.AllResult) string {
+	switch {
+	case report.Summary.Errored > 0:
+		return output.OutcomeErrored
+	case !report.Passed:
+		return output.OutcomeFailed
+	default:
+		return output.OutcomePassed
+	}
+}
+
+func outcomeRank(outcome string) int {
+	switch outcome {
+	case output.OutcomeErrored:
+		return 2
+	case output.OutcomeFailed:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseImageList returns the image references of an image list in order of
+// first appearance, without duplicates. References are separated by newlines
+// or other whitespace, so both one-per-line lists and space-separated output
+// (such as kubectl jsonpath) are accepted. Lines starting with # are ignored.
+func parseImageList(data string) []string {
+	seen := make(map[string]bool)
+	var images []string
+	for line := range strings.Lines(data) {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, image := range strings.Fields(line) {
+			if seen[image] {
+				continue
+			}
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// validateBulkStdin rejects flags that would also read stdin when the image
+// list is read from it.
+func validateBulkStdin() error {
+	if registryPasswordStdin {
+		return fmt.Errorf("--password-stdin cannot be used when reading the image list from stdin")
+	}
+	stdinFlags := []struct{ name, value, stdin string }{
+		{"config", configFile, "-"},
+		{"required-config", requiredConfig, "-"},
+		{"registry-policy", registryPolicy, "-"},
+		{"labels-policy", labelsPolicy, "-"},
+		{"secrets-policy", secretsPolicy, "-"},
+		{"user-policy", userPolicy, "-"},
+		{"provenance-policy", provenancePolicy, "-"},
+		{"entrypoint-policy", entrypointPolicy, "-"},
+		{"lazy-pull-formats", lazyPullFormats, "@-"},
+		{"golden-spec", goldenSpec, "-"},
+		{"entropy-policy", entropyPolicy, "-"},
+		{"exceptions", exceptionsFile, "-"},
+		{"skip", skipChecks, "@-"},
+		{"include", includeChecks, "@-"},
+		{"allowed-ports", allowedPorts, "@-"},
+		{"allowed-platforms", allowedPlatforms, "@-"},
+		{"blocked-users", blockedUsers, "@-"},
+		{"base-layers", baseLayers, "@-"},
+		{"minimal-criteria", minimalCriteria, "@-"},
+	}
+	for _, f := range stdinFlags {
+		if f.value == f.stdin {
+			return fmt.Errorf("--%s %s cannot be used when reading the image list from stdin", f.name, f.stdin)
+		}
+	}
+	return nil
+}
+
+// printBulkSummary prints the outcome of a bulk run in text mode.
+func printBulkSummary(r output.BulkResult) {
+	if r.Services != nil {
+		printKeyedSummary(r, "services", r.Services)
+		return
+	}
+	if r.Targets != nil {
+		printKeyedSummary(r, "bake targets", r.Targets)
+		return
+	}
+	if r.Repositories != nil {
+		fmt.Fprintf(stdout, "%sValidated %d images of %d repositories: %d passed, %d failed\n",
+			statusPrefix(r.Passed), r.Summary.Total, r.Summary.Repositories, r.Summary.Passed, r.Summary.Failed)
+		for _, repo := range r.Repositories {
+			fmt.Fprintf(stdout, "  %s%s (%s): %d images, %d passed, %d failed\n",
+				statusPrefix(repo.Passed), repo.Repository, repo.Outcome, repo.Summary.Total, repo.Summary.Passed, repo.Summary.Failed)
+			for _, img := range repo.Images {
+				if !img.Passed {
+					fmt.Fprintf(stdout, "    Failed: %s\n", img.Image)
+				}
+			}
+		}
+		return
+	}
+	fmt.Fprintf(stdout, "%sValidated %d images: %d passed, %d failed\n",
+		statusPrefix(r.Passed), r.Summary.Total, r.Summary.Passed, r.Summary.Failed)
+	for _, img := range r.Images {
+		if !img.Passed {
+			fmt.Fprintf(stdout, "  Failed: %s\n", img.Image)
+		}
+	}
+}
+
+// printKeyedSummary prints the summary line of a run whose results are keyed
+// by name, such as services or bake targets, followed by the failed ones.
+func printKeyedSummary(r output.BulkResult, noun string, results map[string]output.AllResult) {
+	fmt.Fprintf(stdout, "%sValidated %d %s: %d passed, %d failed\n",
+		statusPrefix(r.Passed), r.Summary.Total, noun, r.Summary.Passed, r.Summary.Failed)
+	for _, key := range slices.Sorted(maps.Keys(results)) {
+		if img := results[key]; !img.Passed {
+			fmt.Fprintf(stdout, "  Failed: %s (%s)\n", key, img.Image)
+		}
+	}
+}
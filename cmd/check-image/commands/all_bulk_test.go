@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withStdin replaces os.Stdin with a pipe holding input for the test.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	oldStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdin = r
+	go func() {
+		_, _ = w.Write([]byte(input))
+		w.Close()
+	}()
+}
+
+func TestParseImageList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "one per line",
+			input: "nginx:latest\nalpine:3.20\n",
+			want:  []string{"nginx:latest", "alpine:3.20"},
+		},
+		{
+			name:  "deduplicated in order of first appearance",
+			input: "nginx:latest\nalpine:3.20\nnginx:latest\n",
+			want:  []string{"nginx:latest", "alpine:3.20"},
+		},
+		{
+			name:  "space separated, blank lines and comments",
+			input: "# cluster images\n\nnginx:latest alpine:3.20\r\n  redis:7  \n",
+			want:  []string{"nginx:latest", "alpine:3.20", "redis:7"},
+		},
+		{
+			name:  "empty",
+			input: "\n  \n",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseImageList(tt.input))
+		})
+	}
+}
+
+func TestRunAll_Bulk_JSONAggregatesImages(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	withStdin(t, failing+"\n"+passing+"\n"+failing+"\n")
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.False(t, result.Passed)
+	assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1}, result.Summary)
+	require.Len(t, result.Images, 2)
+	assert.Equal(t, failing, result.Images[0].Image)
+	assert.False(t, result.Images[0].Passed)
+	assert.Equal(t, passing, result.Images[1].Image)
+	assert.True(t, result.Images[1].Passed, "each image is judged on its own checks")
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_Bulk_CSVFindings(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatCSV
+
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	withStdin(t, failing+"\n"+passing+"\n")
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	lines := strings.Split(strings.TrimSpace(captured), "\n")
+	require.Len(t, lines, 2, "one header and one finding of the failing image")
+	assert.Equal(t, "image,check,rule,subject,message,severity", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], failing+",user,"))
+	assert.True(t, strings.HasSuffix(lines[1], ",failure"))
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_Bulk_Progress(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	var progress bytes.Buffer
+	progressOut = &progress
+
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	withStdin(t, failing+"\n"+passing+"\n")
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result), "progress does not go to stdout")
+	assert.Contains(t, progress.String(), "Progress: 1/2 images, 0 passed, 1 failed, ETA ")
+	assert.Contains(t, progress.String(), "Progress: 2/2 images, 1 passed, 1 failed\n")
+	assert.Regexp(t, `(?m)^IMAGE\s+RESULT\s+FAILED CHECKS$`, progress.String())
+	assert.Regexp(t, `(?m)^`+regexp.QuoteMeta(failing)+`\s+failed\s+user$`, progress.String())
+	assert.Contains(t, progress.String(), "2 of 2 images validated: 1 passed, 1 failed in ")
+}
+
+func TestRunAll_Bulk_NoProgress(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	noProgress = true
+	var progress bytes.Buffer
+	progressOut = &progress
+
+	withStdin(t, createTestImage(t, testImageOptions{user: "1000", created: time.Now()}))
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	assert.Empty(t, progress.String())
+}
+
+func TestRunAll_Bulk_TextSummary(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	withStdin(t, failing+" "+passing)
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	assert.Contains(t, captured, "Running 1 checks on image "+passing)
+	assert.Contains(t, captured, "Validated 2 images: 1 passed, 1 failed")
+	assert.Contains(t, captured, "Failed: "+failing)
+	assert.NotContains(t, captured, "Failed: "+passing)
+}
+
+func TestRunAll_Bulk_Errors(t *testing.T) {
+	t.Run("empty image list", func(t *testing.T) {
+		resetAllGlobals(t)
+		withStdin(t, "\n# nothing\n")
+		err := runAll(allCmd, "-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no images to validate")
+	})
+
+	t.Run("config from stdin", func(t *testing.T) {
+		resetAllGlobals(t)
+		configFile = "-"
+		err := runAll(allCmd, "-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--config -")
+	})
+
+	t.Run("list flag from stdin", func(t *testing.T) {
+		resetAllGlobals(t)
+		allowedPorts = "@-"
+		err := runAll(allCmd, "-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--allowed-ports @-")
+	})
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.27", "index.docker.io/library/nginx"},
+		{"docker.io/library/nginx@sha256:" + strings.Repeat("a", 64), "index.docker.io/library/nginx"},
+		{"registry.example.com:5000/team/app:v2", "registry.example.com:5000/team/app"},
+		{"oci:/tmp/layout:v1", "oci:/tmp/layout"},
+		{"oci-archive:/tmp/image.tar:latest", "oci-archive:/tmp/image.tar"},
+		{"Invalid Reference", "Invalid Reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.want, imageRepository(tt.image))
+		})
+	}
+}
+
+func TestGroupRepositories(t *testing.T) {
+	reports := []output.AllResult{
+		{Image: "registry.example.com/app:1.0", Passed: true},
+		{Image: "registry.example.com/web:1.0", Passed: false, Summary: output.Summary{Failed: 1}},
+		{Image: "registry.example.com/app:1.1", Passed: false, Summary: output.Summary{Failed: 1}},
+		{Image: "registry.example.com/web:1.1", Passed: false, Summary: output.Summary{Errored: 1}},
+		{Image: "registry.example.com/db:1.0", Passed: true},
+	}
+
+	repos := groupRepositories(reports)
+
+	require.Len(t, repos, 3)
+	assert.Equal(t, "registry.example.com/app", repos[0].Repository)
+	assert.False(t, repos[0].Passed)
+	assert.Equal(t, output.OutcomeFailed, repos[0].Outcome)
+	assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1}, repos[0].Summary)
+	require.Len(t, repos[0].Images, 2)
+	assert.Equal(t, "registry.example.com/app:1.1", repos[0].Images[1].Image)
+
+	assert.Equal(t, "registry.example.com/web", repos[1].Repository)
+	assert.Equal(t, output.OutcomeErrored, repos[1].Outcome, "errors rank worse than failures")
+
+	assert.Equal(t, "registry.example.com/db", repos[2].Repository)
+	assert.True(t, repos[2].Passed)
+	assert.Equal(t, output.OutcomePassed, repos[2].Outcome)
+}
+
+func TestRunAll_Bulk_GroupByRepository(t *testing.T) {
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	t.Run("JSON", func(t *testing.T) {
+		resetAllGlobals(t)
+		includeChecks = "user"
+		groupBy = groupByRepository
+		OutputFmt = output.FormatJSON
+		withStdin(t, failing+"\n"+passing+"\n")
+
+		captured := captureStdout(t, func() {
+			require.NoError(t, runAll(allCmd, "-"))
+		})
+
+		var result output.BulkResult
+		require.NoError(t, json.Unmarshal([]byte(captured), &result))
+		assert.False(t, result.Passed)
+		assert.Empty(t, result.Images)
+		assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1, Repositories: 2}, result.Summary)
+		require.Len(t, result.Repositories, 2)
+		assert.Equal(t, imageRepository(failing), result.Repositories[0].Repository)
+		assert.Equal(t, output.OutcomeFailed, result.Repositories[0].Outcome)
+		require.Len(t, result.Repositories[0].Images, 1)
+		assert.Equal(t, failing, result.Repositories[0].Images[0].Image)
+		assert.True(t, result.Repositories[1].Passed)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		resetAllGlobals(t)
+		includeChecks = "user"
+		groupBy = groupByRepository
+		withStdin(t, failing+"\n"+passing+"\n")
+
+		captured := captureStdout(t, func() {
+			require.NoError(t, runAll(allCmd, "-"))
+		})
+
+		assert.Contains(t, captured, "Validated 2 images of 2 repositories: 1 passed, 1 failed")
+		assert.Contains(t, captured, imageRepository(failing)+" (failed): 1 images, 0 passed, 1 failed")
+		assert.Contains(t, captured, "    Failed: "+failing)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		resetAllGlobals(t)
+		groupBy = "registry"
+		err := runAll(allCmd, "-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid --group-by value "registry"`)
+	})
+
+	t.Run("single image", func(t *testing.T) {
+		resetAllGlobals(t)
+		groupBy = groupByRepository
+		err := runAll(allCmd, passing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--group-by requires reading the image list from stdin, --from-sbom, or --from-lockfile")
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 
+	"github.com/jarfernandez/check-image/internal/deprecation"
 	"github.com/jarfernandez/check-image/internal/fileutil"
 	"github.com/spf13/cobra"
 )
@@ -14,54 +16,115 @@ import (
 // the commands package: in CheckResult.Check, runCheckCmd calls, buildCheckDefs,
 // validateRequiredFlags, and the text-render dispatch switch.
 const (
-	checkAge         = "age"
-	checkSize        = "size"
-	checkPorts       = "ports"
-	checkRegistry    = "registry"
-	checkSecrets     = "secrets"
-	checkHealthcheck = "healthcheck"
-	checkLabels      = "labels"
-	checkEntrypoint  = "entrypoint"
-	checkPlatform    = "platform"
-	checkUser        = "user"
+	checkAge          = "age"
+	checkSize         = "size"
+	checkPorts        = "ports"
+	checkRegistry     = "registry"
+	checkSecrets      = "secrets"
+	checkHealthcheck  = "healthcheck"
+	checkLabels       = "labels"
+	checkEntrypoint   = "entrypoint"
+	checkPlatform     = "platform"
+	checkUser         = "user"
+	checkProvenance   = "provenance"
+	checkLazyPull     = "lazy-pull"
+	checkDrift        = "drift"
+	checkEntropy      = "entropy"
+	checkDeprecation  = "deprecation"
+	checkArchitecture = "architecture"
+	checkMinimal      = "minimal"
+	checkSmoke        = "smoke"
 )
 
 // validCheckNames lists all check names recognized by the all command.
 var validCheckNames = []string{
 	checkAge, checkSize, checkPorts, checkRegistry,
 	checkSecrets, checkHealthcheck, checkLabels, checkEntrypoint, checkPlatform,
-	checkUser,
+	checkUser, checkProvenance, checkLazyPull, checkDrift, checkEntropy,
+	checkDeprecation, checkArchitecture, checkMinimal, checkSmoke,
 }
 
 // allConfig represents the configuration file structure for the all command.
 type allConfig struct {
 	Checks allChecksConfig `json:"checks" yaml:"checks"`
+	// Telemetry opts in to the anonymous usage counter. It is off unless set
+	// to true here or via CHECK_IMAGE_TELEMETRY.
+	Telemetry         *bool  `json:"telemetry,omitempty"          yaml:"telemetry,omitempty"`
+	TelemetryEndpoint string `json:"telemetry-endpoint,omitempty" yaml:"telemetry-endpoint,omitempty"`
+	// Redact lists regular expressions whose matches are replaced in every
+	// rendered result and log line.
+	Redact []string `json:"redact,omitempty" yaml:"redact,omitempty"`
+	// Anonymize replaces registry and repository names with pseudonyms, like
+	// --anonymize.
+	Anonymize *bool `json:"anonymize,omitempty" yaml:"anonymize,omitempty"`
+	// DocsBaseURL overrides the documentation links of check results, e.g. to
+	// point to an internal wiki.
+	DocsBaseURL string `json:"docs-base-url,omitempty" yaml:"docs-base-url,omitempty"`
+	// Units selects how sizes are written, like --units.
+	Units string `json:"units,omitempty" yaml:"units,omitempty"`
+	// Exceptions is the path of an exceptions file, like --exceptions.
+	Exceptions string `json:"exceptions,omitempty" yaml:"exceptions,omitempty"`
+	// Hooks are commands the all command runs after validating.
+	Hooks *hooksConfig `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// ExitPolicy maps finding severities (low, medium, high, critical) to
+	// fail, warn, or ignore, deciding whether failed checks fail the run.
+	ExitPolicy map[string]string `json:"exit-policy,omitempty" yaml:"exit-policy,omitempty"`
+	// Severities sets the severity of the findings of a check, or of a rule
+	// of a check as check/rule, for the exit policy.
+	Severities map[string]string `json:"severities,omitempty" yaml:"severities,omitempty"`
+	// LabelSuppressions allows images to suppress findings with
+	// check-image.suppress labels.
+	LabelSuppressions *labelSuppressionsConfig `json:"label-suppressions,omitempty" yaml:"label-suppressions,omitempty"`
 }
 
 type allChecksConfig struct {
-	Age         *ageCheckConfig         `json:"age,omitempty"       yaml:"age,omitempty"`
-	Size        *sizeCheckConfig        `json:"size,omitempty"      yaml:"size,omitempty"`
-	Ports       *portsCheckConfig       `json:"ports,omitempty"     yaml:"ports,omitempty"`
-	Registry    *registryCheckConfig    `json:"registry,omitempty"  yaml:"registry,omitempty"`
-	Secrets     *secretsCheckConfig     `json:"secrets,omitempty"   yaml:"secrets,omitempty"`
-	Healthcheck *healthcheckCheckConfig `json:"healthcheck,omitempty"  yaml:"healthcheck,omitempty"`
-	Labels      *labelsCheckConfig      `json:"labels,omitempty"       yaml:"labels,omitempty"`
-	Entrypoint  *entrypointCheckConfig  `json:"entrypoint,omitempty"   yaml:"entrypoint,omitempty"`
-	Platform    *platformCheckConfig    `json:"platform,omitempty"     yaml:"platform,omitempty"`
-	User        *userCheckConfig        `json:"user,omitempty"         yaml:"user,omitempty"`
+	Age          *ageCheckConfig          `json:"age,omitempty"       yaml:"age,omitempty"`
+	Size         *sizeCheckConfig         `json:"size,omitempty"      yaml:"size,omitempty"`
+	Ports        *portsCheckConfig        `json:"ports,omitempty"     yaml:"ports,omitempty"`
+	Registry     *registryCheckConfig     `json:"registry,omitempty"  yaml:"registry,omitempty"`
+	Secrets      *secretsCheckConfig      `json:"secrets,omitempty"   yaml:"secrets,omitempty"`
+	Healthcheck  *healthcheckCheckConfig  `json:"healthcheck,omitempty"  yaml:"healthcheck,omitempty"`
+	Labels       *labelsCheckConfig       `json:"labels,omitempty"       yaml:"labels,omitempty"`
+	Entrypoint   *entrypointCheckConfig   `json:"entrypoint,omitempty"   yaml:"entrypoint,omitempty"`
+	Platform     *platformCheckConfig     `json:"platform,omitempty"     yaml:"platform,omitempty"`
+	User         *userCheckConfig         `json:"user,omitempty"         yaml:"user,omitempty"`
+	Provenance   *provenanceCheckConfig   `json:"provenance,omitempty"   yaml:"provenance,omitempty"`
+	LazyPull     *lazyPullCheckConfig     `json:"lazy-pull,omitempty"    yaml:"lazy-pull,omitempty"`
+	Drift        *driftCheckConfig        `json:"drift,omitempty"        yaml:"drift,omitempty"`
+	Entropy      *entropyCheckConfig      `json:"entropy,omitempty"      yaml:"entropy,omitempty"`
+	Deprecation  *deprecationCheckConfig  `json:"deprecation,omitempty"  yaml:"deprecation,omitempty"`
+	Architecture *architectureCheckConfig `json:"architecture,omitempty" yaml:"architecture,omitempty"`
+	Minimal      *minimalCheckConfig      `json:"minimal,omitempty"      yaml:"minimal,omitempty"`
+	Smoke        *smokeCheckConfig        `json:"smoke,omitempty"        yaml:"smoke,omitempty"`
 }
 
 type ageCheckConfig struct {
 	MaxAge *uint `json:"max-age,omitempty" yaml:"max-age,omitempty"`
+	// NotBefore and NotAfter bound the creation date with RFC3339 timestamps.
+	NotBefore string `json:"not-before,omitempty" yaml:"not-before,omitempty"`
+	NotAfter  string `json:"not-after,omitempty"  yaml:"not-after,omitempty"`
+	// Windows override MaxAge while they are active.
+	Windows []ageWindowConfig `json:"windows,omitempty" yaml:"windows,omitempty"`
+	// Rules override MaxAge for the images whose repository they match.
+	Rules []ageRuleConfig `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 type sizeCheckConfig struct {
-	MaxSize   *uint `json:"max-size,omitempty"   yaml:"max-size,omitempty"`
-	MaxLayers *uint `json:"max-layers,omitempty" yaml:"max-layers,omitempty"`
+	MaxSize      *uint `json:"max-size,omitempty"       yaml:"max-size,omitempty"`
+	MaxLayers    *uint `json:"max-layers,omitempty"     yaml:"max-layers,omitempty"`
+	MaxTotalSize *uint `json:"max-total-size,omitempty" yaml:"max-total-size,omitempty"`
+	// Windows override the limits above while they are active.
+	Windows []sizeWindowConfig `json:"windows,omitempty" yaml:"windows,omitempty"`
+
+	CountFromBase *bool    `json:"count-from-base,omitempty" yaml:"count-from-base,omitempty"`
+	BaseImage     string   `json:"base-image,omitempty"      yaml:"base-image,omitempty"`
+	BaseLayers    []string `json:"base-layers,omitempty"     yaml:"base-layers,omitempty"`
 }
 
 type portsCheckConfig struct {
-	AllowedPorts any `json:"allowed-ports,omitempty" yaml:"allowed-ports,omitempty"`
+	AllowedPorts          any   `json:"allowed-ports,omitempty"           yaml:"allowed-ports,omitempty"`
+	MaxExposedPorts       *uint `json:"max-exposed-ports,omitempty"       yaml:"max-exposed-ports,omitempty"`
+	ForbidPrivilegedPorts *bool `json:"forbid-privileged-ports,omitempty" yaml:"forbid-privileged-ports,omitempty"`
 }
 
 type registryCheckConfig struct {
@@ -70,14 +133,38 @@ type registryCheckConfig struct {
 
 type healthcheckCheckConfig struct{}
 
+type deprecationCheckConfig struct{}
+
+type architectureCheckConfig struct {
+	MaxBinaries *uint `json:"max-binaries,omitempty" yaml:"max-binaries,omitempty"`
+}
+
+type minimalCheckConfig struct {
+	MaxSize  *uint `json:"max-size,omitempty" yaml:"max-size,omitempty"`
+	Criteria any   `json:"criteria,omitempty" yaml:"criteria,omitempty"`
+}
+
+type smokeCheckConfig struct {
+	// Command is a string split on whitespace, or a list of arguments.
+	Command    any    `json:"command,omitempty"     yaml:"command,omitempty"`
+	Timeout    string `json:"timeout,omitempty"     yaml:"timeout,omitempty"`
+	HealthyFor string `json:"healthy-for,omitempty" yaml:"healthy-for,omitempty"`
+	// Memory is in megabytes; Memory and PidsLimit are 0 for no limit.
+	Memory    *uint `json:"memory,omitempty"     yaml:"memory,omitempty"`
+	PidsLimit *uint `json:"pids-limit,omitempty" yaml:"pids-limit,omitempty"`
+}
+
 type entrypointCheckConfig struct {
-	AllowShellForm *bool `json:"allow-shell-form,omitempty" yaml:"allow-shell-form,omitempty"`
+	AllowShellForm   *bool `json:"allow-shell-form,omitempty"  yaml:"allow-shell-form,omitempty"`
+	EntrypointPolicy any   `json:"entrypoint-policy,omitempty" yaml:"entrypoint-policy,omitempty"`
 }
 
 type secretsCheckConfig struct {
 	SecretsPolicy any   `json:"secrets-policy,omitempty" yaml:"secrets-policy,omitempty"`
 	SkipEnvVars   *bool `json:"skip-env-vars,omitempty"  yaml:"skip-env-vars,omitempty"`
 	SkipFiles     *bool `json:"skip-files,omitempty"     yaml:"skip-files,omitempty"`
+	// FailOnSeverity is the least finding severity that fails the check.
+	FailOnSeverity string `json:"fail-on-severity,omitempty" yaml:"fail-on-severity,omitempty"`
 }
 
 type labelsCheckConfig struct {
@@ -96,24 +183,42 @@ type userCheckConfig struct {
 	RequireNumeric *bool    `json:"require-numeric,omitempty"  yaml:"require-numeric,omitempty"`
 }
 
-// parseCheckNameList parses a comma-separated list of check names and validates
-// each name against validCheckNames. Returns a map of valid check names.
-func parseCheckNameList(list string) (map[string]bool, error) {
+type provenanceCheckConfig struct {
+	ProvenancePolicy any `json:"provenance-policy,omitempty" yaml:"provenance-policy,omitempty"`
+}
+
+type lazyPullCheckConfig struct {
+	LazyPullFormats any `json:"lazy-pull-formats,omitempty" yaml:"lazy-pull-formats,omitempty"`
+}
+
+type entropyCheckConfig struct {
+	EntropyPolicy any `json:"entropy-policy,omitempty" yaml:"entropy-policy,omitempty"`
+}
+
+type driftCheckConfig struct {
+	GoldenSpec any `json:"golden-spec,omitempty" yaml:"golden-spec,omitempty"`
+}
+
+// parseCheckNameList parses a list of check names (comma-separated or @file,
+// see parseListInput; key is the flag name) and validates each name against
+// validCheckNames. Returns a map of valid check names.
+func parseCheckNameList(list, key string) (map[string]bool, error) {
 	if list == "" {
 		return nil, nil
 	}
 
+	names, err := parseListInput(list, key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", key, err)
+	}
+
 	validNames := make(map[string]bool)
 	for _, name := range validCheckNames {
 		validNames[name] = true
 	}
 
 	nameMap := make(map[string]bool)
-	for part := range strings.SplitSeq(list, ",") {
-		name := strings.TrimSpace(part)
-		if name == "" {
-			continue
-		}
+	for _, name := range names {
 		if !validNames[name] {
 			return nil, fmt.Errorf("unknown check name %q, valid names are: %s", name, strings.Join(validCheckNames, ", "))
 		}
@@ -124,16 +229,114 @@ func parseCheckNameList(list string) (map[string]bool, error) {
 }
 
 func loadAllConfig(path string) (*allConfig, error) {
+	return readAllConfig(path, configParser(strictConfig))
+}
+
+// configParser returns parseStrictAllConfig when strict is set, and
+// parseAllConfig otherwise.
+func configParser(strict bool) func(data []byte, formatPath string) (*allConfig, error) {
+	if strict {
+		return parseStrictAllConfig
+	}
+	return parseAllConfig
+}
+
+// loadUnvalidatedAllConfig loads a config file without validating its inline
+// policies, for policy lint, which reports their problems as findings.
+func loadUnvalidatedAllConfig(path string) (*allConfig, error) {
+	return readAllConfig(path, decodeAllConfig)
+}
+
+func readAllConfig(path string, parse func(data []byte, formatPath string) (*allConfig, error)) (*allConfig, error) {
 	data, err := fileutil.ReadFileOrStdin(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg allConfig
-	if err := fileutil.UnmarshalConfigData(data, &cfg, path); err != nil {
+	cfg, err := parse(data, path)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	return cfg, nil
+}
+
+// parseAllConfig decodes data with decodeAllConfig and validates its inline
+// policies.
+func parseAllConfig(data []byte, formatPath string) (*allConfig, error) {
+	cfg, err := decodeAllConfig(data, formatPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInlinePolicies(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseStrictAllConfig is parseAllConfig that also rejects unknown keys, such
+// as max_age for max-age, which are otherwise ignored.
+func parseStrictAllConfig(data []byte, formatPath string) (*allConfig, error) {
+	if err := checkUnknownConfigKeys(data, formatPath); err != nil {
+		return nil, err
+	}
+	return parseAllConfig(data, formatPath)
+}
+
+// checkUnknownConfigKeys reports the first key of data, after the migration of
+// deprecated keys, that is not part of the config schema, named by its path,
+// e.g. checks.age.max_age. Inline policies are checked by
+// validateInlinePolicies.
+func checkUnknownConfigKeys(data []byte, formatPath string) error {
+	if migrated, _, err := deprecation.MigrateConfig(data, fileutil.IsYAMLConfig(data, formatPath), deprecation.ConfigKeys); err == nil {
+		data = migrated
+	}
+	var v any
+	if err := fileutil.UnmarshalConfigData(data, &v, formatPath); err != nil {
+		return err
+	}
+	if err := checkSchema("", v, reflect.TypeFor[allConfig]()); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// decodeAllConfig migrates deprecated keys in data, warning about each one,
+// and unmarshals the result. formatPath selects the format as in
+// fileutil.UnmarshalConfigData.
+func decodeAllConfig(data []byte, formatPath string) (*allConfig, error) {
+	if migrated, notices, err := deprecation.MigrateConfig(data, fileutil.IsYAMLConfig(data, formatPath), deprecation.ConfigKeys); err == nil {
+		for _, n := range notices {
+			logDeprecation(n)
+		}
+		data = migrated
+	}
+
+	var cfg allConfig
+	if err := fileutil.UnmarshalConfigData(data, &cfg, formatPath); err != nil {
+		return nil, err
+	}
+	if err := validatePolicyWindows(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateAgeRules(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateAgeBoundsConfig(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateHooks(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateExitPolicy(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateLabelSuppressions(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateSmokeConfig(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -160,14 +363,25 @@ func applyConfigValues(cmd *cobra.Command, cfg *allConfig) (func(), error) {
 	applyAgeConfig(cmd, cfg.Checks.Age)
 	applySizeConfig(cmd, cfg.Checks.Size)
 	applyPortsConfig(cmd, cfg.Checks.Ports)
-	applyEntrypointConfig(cmd, cfg.Checks.Entrypoint)
 	applyPlatformConfig(cmd, cfg.Checks.Platform)
+	applyLazyPullConfig(cmd, cfg.Checks.LazyPull)
+	applyArchitectureConfig(cmd, cfg.Checks.Architecture)
+	applyMinimalConfig(cmd, cfg.Checks.Minimal)
+	applySmokeConfig(cmd, cfg.Checks.Smoke)
+	applyExceptionsConfig(cmd, cfg.Exceptions)
+	applyAnonymizeConfig(cmd, cfg.Anonymize)
 
 	results := []configApplyResult{
 		newApplyResult(applyRegistryConfig(cmd, cfg.Checks.Registry)),
 		newApplyResult(applySecretsConfig(cmd, cfg.Checks.Secrets)),
 		newApplyResult(applyLabelsConfig(cmd, cfg.Checks.Labels)),
 		newApplyResult(applyUserConfig(cmd, cfg.Checks.User)),
+		newApplyResult(applyProvenanceConfig(cmd, cfg.Checks.Provenance)),
+		newApplyResult(applyDriftConfig(cmd, cfg.Checks.Drift)),
+		newApplyResult(applyEntropyConfig(cmd, cfg.Checks.Entropy)),
+		newApplyResult(applyEntrypointConfig(cmd, cfg.Checks.Entrypoint)),
+		newApplyResult(applyDocsConfig(cmd, cfg.DocsBaseURL)),
+		newApplyResult(applyUnitsConfig(cmd, cfg.Units)),
 	}
 
 	combined := func() {
@@ -183,10 +397,32 @@ func applyConfigValues(cmd *cobra.Command, cfg *allConfig) (func(), error) {
 	return combined, nil
 }
 
+// applyAgeConfig applies the age config values, overridden by the values of
+// the first window active at policyNow. The rules apply while no window
+// overrides max-age.
 func applyAgeConfig(cmd *cobra.Command, cfg *ageCheckConfig) {
-	if cfg != nil && cfg.MaxAge != nil && !cmd.Flags().Changed("max-age") {
+	if cfg == nil {
+		return
+	}
+	if cfg.NotBefore != "" && !cmd.Flags().Changed("not-before") {
+		ageNotBefore = cfg.NotBefore
+	}
+	if cfg.NotAfter != "" && !cmd.Flags().Changed("not-after") {
+		ageNotAfter = cfg.NotAfter
+	}
+	if cmd.Flags().Changed("max-age") {
+		return
+	}
+	if cfg.MaxAge != nil {
 		maxAge = *cfg.MaxAge
 	}
+	w, ok := activeWindow(cfg.Windows, func(w ageWindowConfig) policyWindow { return w.policyWindow }, policyNow())
+	if ok && w.MaxAge != nil {
+		maxAge = *w.MaxAge
+		ageWindow = w.output()
+		return
+	}
+	ageRules = newAgeRules(cfg.Rules)
 }
 
 func applySizeConfig(cmd *cobra.Command, cfg *sizeCheckConfig) {
@@ -199,12 +435,48 @@ func applySizeConfig(cmd *cobra.Command, cfg *sizeCheckConfig) {
 	if cfg.MaxLayers != nil && !cmd.Flags().Changed("max-layers") {
 		maxLayers = *cfg.MaxLayers
 	}
+	if cfg.MaxTotalSize != nil && !cmd.Flags().Changed("max-total-size") {
+		maxTotalSize = *cfg.MaxTotalSize
+	}
+	if w, ok := activeWindow(cfg.Windows, func(w sizeWindowConfig) policyWindow { return w.policyWindow }, policyNow()); ok {
+		applied := false
+		set := func(flag string, value, dest *uint) {
+			if value != nil && !cmd.Flags().Changed(flag) {
+				*dest = *value
+				applied = true
+			}
+		}
+		set("max-size", w.MaxSize, &maxSize)
+		set("max-layers", w.MaxLayers, &maxLayers)
+		set("max-total-size", w.MaxTotalSize, &maxTotalSize)
+		if applied {
+			sizeWindow = w.output()
+		}
+	}
+	if cfg.CountFromBase != nil && !cmd.Flags().Changed("count-from-base") {
+		countFromBase = *cfg.CountFromBase
+	}
+	if cfg.BaseImage != "" && !cmd.Flags().Changed("base-image") {
+		baseImage = cfg.BaseImage
+	}
+	if cfg.BaseLayers != nil && !cmd.Flags().Changed("base-layers") {
+		baseLayers = strings.Join(cfg.BaseLayers, ",")
+	}
 }
 
 func applyPortsConfig(cmd *cobra.Command, cfg *portsCheckConfig) {
-	if cfg != nil && cfg.AllowedPorts != nil && !cmd.Flags().Changed("allowed-ports") {
+	if cfg == nil {
+		return
+	}
+	if cfg.AllowedPorts != nil && !cmd.Flags().Changed("allowed-ports") {
 		allowedPorts = formatAllowedList(cfg.AllowedPorts)
 	}
+	if cfg.MaxExposedPorts != nil && !cmd.Flags().Changed("max-exposed-ports") {
+		maxExposedPorts = *cfg.MaxExposedPorts
+	}
+	if cfg.ForbidPrivilegedPorts != nil && !cmd.Flags().Changed("forbid-privileged-ports") {
+		forbidPrivilegedPorts = *cfg.ForbidPrivilegedPorts
+	}
 }
 
 // applyInlinePolicy resolves an inline policy value to a temp-file path and sets
@@ -248,6 +520,9 @@ func applySecretsConfig(cmd *cobra.Command, cfg *secretsCheckConfig) (func(), er
 	if cfg.SkipFiles != nil && !cmd.Flags().Changed("skip-files") {
 		skipFiles = *cfg.SkipFiles
 	}
+	if cfg.FailOnSeverity != "" && !cmd.Flags().Changed("fail-on-severity") {
+		failOnSeverity = cfg.FailOnSeverity
+	}
 	return cleanup, nil
 }
 
@@ -258,10 +533,35 @@ func applyLabelsConfig(cmd *cobra.Command, cfg *labelsCheckConfig) (func(), erro
 	return applyInlinePolicy(cmd, "labels-policy", cfg.LabelsPolicy, &labelsPolicy)
 }
 
-func applyEntrypointConfig(cmd *cobra.Command, cfg *entrypointCheckConfig) {
-	if cfg != nil && cfg.AllowShellForm != nil && !cmd.Flags().Changed("allow-shell-form") {
+func applyProvenanceConfig(cmd *cobra.Command, cfg *provenanceCheckConfig) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
+	}
+	return applyInlinePolicy(cmd, "provenance-policy", cfg.ProvenancePolicy, &provenancePolicy)
+}
+
+func applyDriftConfig(cmd *cobra.Command, cfg *driftCheckConfig) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
+	}
+	return applyInlinePolicy(cmd, "golden-spec", cfg.GoldenSpec, &goldenSpec)
+}
+
+func applyEntropyConfig(cmd *cobra.Command, cfg *entropyCheckConfig) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
+	}
+	return applyInlinePolicy(cmd, "entropy-policy", cfg.EntropyPolicy, &entropyPolicy)
+}
+
+func applyEntrypointConfig(cmd *cobra.Command, cfg *entrypointCheckConfig) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
+	}
+	if cfg.AllowShellForm != nil && !cmd.Flags().Changed("allow-shell-form") {
 		allowShellForm = *cfg.AllowShellForm
 	}
+	return applyInlinePolicy(cmd, "entrypoint-policy", cfg.EntrypointPolicy, &entrypointPolicy)
 }
 
 func applyPlatformConfig(cmd *cobra.Command, cfg *platformCheckConfig) {
@@ -270,6 +570,67 @@ func applyPlatformConfig(cmd *cobra.Command, cfg *platformCheckConfig) {
 	}
 }
 
+func applyArchitectureConfig(cmd *cobra.Command, cfg *architectureCheckConfig) {
+	if cfg != nil && cfg.MaxBinaries != nil && !cmd.Flags().Changed("max-binaries") {
+		maxBinaries = *cfg.MaxBinaries
+	}
+}
+
+func applyMinimalConfig(cmd *cobra.Command, cfg *minimalCheckConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.MaxSize != nil && !cmd.Flags().Changed("minimal-max-size") {
+		minimalMaxSize = *cfg.MaxSize
+	}
+	if cfg.Criteria != nil && !cmd.Flags().Changed("minimal-criteria") {
+		minimalCriteria = formatAllowedList(cfg.Criteria)
+	}
+}
+
+// applySmokeConfig applies checks.smoke, whose durations decodeAllConfig has
+// validated.
+func applySmokeConfig(cmd *cobra.Command, cfg *smokeCheckConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Command != nil && !cmd.Flags().Changed("smoke-command") {
+		smokeCommand = formatSmokeCommand(cfg.Command)
+	}
+	timeout, healthyFor, _ := cfg.durations()
+	if cfg.Timeout != "" && !cmd.Flags().Changed("smoke-timeout") {
+		smokeTimeout = timeout
+	}
+	if cfg.HealthyFor != "" && !cmd.Flags().Changed("smoke-healthy-for") {
+		smokeHealthyFor = healthyFor
+	}
+	if cfg.Memory != nil && !cmd.Flags().Changed("smoke-memory") {
+		smokeMemory = *cfg.Memory
+	}
+	if cfg.PidsLimit != nil && !cmd.Flags().Changed("smoke-pids-limit") {
+		smokePidsLimit = *cfg.PidsLimit
+	}
+}
+
+// formatSmokeCommand joins a command list of the config file with spaces, the
+// separator of --smoke-command.
+func formatSmokeCommand(v any) string {
+	if items, ok := v.([]any); ok {
+		parts := make([]string, 0, len(items))
+		for _, p := range items {
+			parts = append(parts, fmt.Sprintf("%v", p))
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func applyLazyPullConfig(cmd *cobra.Command, cfg *lazyPullCheckConfig) {
+	if cfg != nil && cfg.LazyPullFormats != nil && !cmd.Flags().Changed("lazy-pull-formats") {
+		lazyPullFormats = formatAllowedList(cfg.LazyPullFormats)
+	}
+}
+
 func applyUserConfig(cmd *cobra.Command, cfg *userCheckConfig) (func(), error) {
 	if cfg == nil {
 		return func() {}, nil
@@ -340,7 +701,7 @@ func inlinePolicyToTempFile(prefix string, v any) (path string, cleanup func(),
 		if err != nil {
 			return "", func() {}, fmt.Errorf("failed to marshal inline %s: %w", prefix, err)
 		}
-		tmpFile, err := os.CreateTemp("", prefix+"-*.json")
+		tmpFile, err := os.CreateTemp("", "check-image-"+prefix+"-*.json")
 		if err != nil {
 			return "", func() {}, fmt.Errorf("failed to create temp file for inline %s: %w", prefix, err)
 		}
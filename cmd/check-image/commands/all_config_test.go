@@ -66,7 +66,7 @@ func TestParseCheckNameList(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseCheckNameList(tt.input)
+			result, err := parseCheckNameList(tt.input, "skip")
 			if tt.expectErr {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), "unknown check name")
@@ -88,6 +88,7 @@ func TestLoadAllConfig(t *testing.T) {
   size:
     max-size: 200
     max-layers: 10
+    max-total-size: 1500
   user: {}
 `
 		err := os.WriteFile(cfgFile, []byte(content), 0600)
@@ -106,6 +107,8 @@ func TestLoadAllConfig(t *testing.T) {
 		assert.Equal(t, uint(200), *cfg.Checks.Size.MaxSize)
 		require.NotNil(t, cfg.Checks.Size.MaxLayers)
 		assert.Equal(t, uint(10), *cfg.Checks.Size.MaxLayers)
+		require.NotNil(t, cfg.Checks.Size.MaxTotalSize)
+		assert.Equal(t, uint(1500), *cfg.Checks.Size.MaxTotalSize)
 
 		require.NotNil(t, cfg.Checks.User)
 
@@ -473,6 +476,44 @@ func TestApplyPlatformConfig(t *testing.T) {
 	})
 }
 
+func TestApplySizeConfig_BaseLayers(t *testing.T) {
+	t.Run("config values applied when flags not changed", func(t *testing.T) {
+		resetAllGlobals(t)
+
+		cmd := &cobra.Command{}
+		addLayerBaseFlags(cmd)
+
+		countFromBaseCfg := false
+		applySizeConfig(cmd, &sizeCheckConfig{
+			CountFromBase: &countFromBaseCfg,
+			BaseImage:     "node:22-alpine",
+			BaseLayers:    []string{"sha256:aaa", "sha256:bbb"},
+		})
+
+		assert.False(t, countFromBase)
+		assert.Equal(t, "node:22-alpine", baseImage)
+		assert.Equal(t, "sha256:aaa,sha256:bbb", baseLayers)
+	})
+
+	t.Run("config values skipped when flags changed", func(t *testing.T) {
+		resetAllGlobals(t)
+
+		cmd := &cobra.Command{}
+		addLayerBaseFlags(cmd)
+		require.NoError(t, cmd.Flags().Set("count-from-base", "true"))
+		require.NoError(t, cmd.Flags().Set("base-image", "alpine:3.20"))
+
+		countFromBaseCfg := false
+		applySizeConfig(cmd, &sizeCheckConfig{
+			CountFromBase: &countFromBaseCfg,
+			BaseImage:     "node:22-alpine",
+		})
+
+		assert.True(t, countFromBase)
+		assert.Equal(t, "alpine:3.20", baseImage)
+	})
+}
+
 func TestInlinePolicyToTempFile_RegistryPolicy(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1447,3 +1488,21 @@ func TestApplyRegistryConfig(t *testing.T) {
 		assert.Equal(t, "original", registryPolicy)
 	})
 }
+
+func TestApplySecretsConfig_FailOnSeverity(t *testing.T) {
+	resetAllGlobals(t)
+	cfg := &secretsCheckConfig{FailOnSeverity: "critical"}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "")
+	cleanup, err := applySecretsConfig(cmd, cfg)
+	t.Cleanup(cleanup)
+	require.NoError(t, err)
+	assert.Equal(t, "critical", failOnSeverity)
+
+	require.NoError(t, cmd.Flags().Set("fail-on-severity", "high"))
+	cleanup, err = applySecretsConfig(cmd, cfg)
+	t.Cleanup(cleanup)
+	require.NoError(t, err)
+	assert.Equal(t, "high", failOnSeverity, "the flag overrides the config")
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+var effectiveConfig bool
+
+// stdinSource is recorded instead of a value that was read from stdin, which
+// cannot be read again.
+const stdinSource = "stdin"
+
+// buildEffectiveConfig returns the parameters each executed check ran with,
+// named like the keys of the checks section of a config file. Policy files are
+// recorded by the sha256 digest of their content, as inline policies are
+// written to temporary files, and list flags by their resolved items.
+func buildEffectiveConfig(results []output.CheckResult, p checkParams) *output.EffectiveConfig {
+	ec := &output.EffectiveConfig{Checks: make(map[string]map[string]any, len(results))}
+	for _, r := range results {
+		ec.Checks[r.Check] = effectiveCheckParams(r.Check, p)
+	}
+	return ec
+}
+
+func effectiveCheckParams(check string, p checkParams) map[string]any {
+	params := make(map[string]any)
+	if flag, path := checkPolicyFile(check, p); path != "" {
+		params[flag] = policyFileDigest(path)
+	}
+	setList := func(key, value string) {
+		if value != "" {
+			params[key] = effectiveList(value, key)
+		}
+	}
+	setUint := func(key string, value uint) {
+		if value != 0 {
+			params[key] = value
+		}
+	}
+
+	switch check {
+	case checkAge:
+		params["max-age"] = p.maxAge
+		if p.ageNotBefore != "" {
+			params["not-before"] = p.ageNotBefore
+		}
+		if p.ageNotAfter != "" {
+			params["not-after"] = p.ageNotAfter
+		}
+		if p.ageWindow != nil {
+			params["policy-window"] = *p.ageWindow
+		}
+		if len(p.ageRules) > 0 {
+			rules := make([]map[string]any, 0, len(p.ageRules))
+			for _, r := range p.ageRules {
+				rules = append(rules, map[string]any{"match": r.match, "max-age": r.maxAge})
+			}
+			params["rules"] = rules
+		}
+	case checkSize:
+		params["max-size"] = p.maxSize
+		params["max-layers"] = p.maxLayers
+		setUint("max-total-size", p.maxTotalSize)
+		if p.countFromBase {
+			params["count-from-base"] = true
+		}
+		if p.baseImage != "" {
+			params["base-image"] = redactText(p.baseImage)
+		}
+		setList("base-layers", p.baseLayers)
+		if p.sizeWindow != nil {
+			params["policy-window"] = *p.sizeWindow
+		}
+	case checkPorts:
+		setList("allowed-ports", p.allowedPorts)
+		setUint("max-exposed-ports", p.maxExposedPorts)
+		if p.forbidPrivileged {
+			params["forbid-privileged-ports"] = true
+		}
+	case checkSecrets:
+		params["skip-env-vars"] = p.skipEnvVars
+		params["skip-files"] = p.skipFiles
+		if p.failOnSeverity != "" {
+			params["fail-on-severity"] = p.failOnSeverity
+		}
+	case checkEntrypoint:
+		params["allow-shell-form"] = p.allowShellForm
+	case checkPlatform:
+		setList("allowed-platforms", p.allowedPlatforms)
+	case checkUser:
+		setUint("min-uid", p.userMinUID)
+		setUint("max-uid", p.userMaxUID)
+		setList("blocked-users", p.blockedUsers)
+		if p.requireNumeric {
+			params["require-numeric"] = true
+		}
+	case checkLazyPull:
+		setList("lazy-pull-formats", p.lazyPullFormats)
+	case checkArchitecture:
+		params["max-binaries"] = p.maxBinaries
+	case checkMinimal:
+		params["max-size"] = p.minimalMaxSize
+		setList("criteria", p.minimalCriteria)
+	case checkSmoke:
+		if p.smokeCommand != "" {
+			params["command"] = strings.Fields(p.smokeCommand)
+		}
+		if p.smokeHealthyFor > 0 {
+			params["healthy-for"] = p.smokeHealthyFor.String()
+		} else {
+			params["timeout"] = p.smokeTimeout.String()
+		}
+		params["memory"] = p.smokeMemory
+		params["pids-limit"] = p.smokePidsLimit
+	}
+	return params
+}
+
+// policyFileDigest returns the sha256 digest of the content of a policy file,
+// or stdinSource for a policy read from stdin. A URL is not fetched again: it
+// is recorded by the digest of the content fetched by the run, or by its
+// #sha256= pin, or as given when it was not fetched and has no pin. A file
+// that cannot be read again is recorded by its path.
+func policyFileDigest(path string) string {
+	if path == "-" {
+		return stdinSource
+	}
+	if fileutil.IsURL(path) {
+		if digest, ok := fileutil.SourceDigest(path); ok {
+			return digest
+		}
+		if _, pin, ok := strings.Cut(path, "#sha256="); ok {
+			return "sha256:" + strings.ToLower(pin)
+		}
+		return path
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// effectiveList resolves a list flag to its items. Lists read from stdin are
+// recorded as stdinSource, and lists that cannot be resolved as given.
+func effectiveList(value, key string) any {
+	if value == "@-" {
+		return stdinSource
+	}
+	if !strings.HasPrefix(value, "@") {
+		return splitList(value)
+	}
+	items, err := parseListInput(value, key)
+	if err != nil {
+		return value
+	}
+	return items
+}
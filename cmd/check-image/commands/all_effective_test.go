@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAll_EffectiveConfig(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRequiredConfig(t, `checks:
+  age:
+    max-age: 30
+  ports:
+    allowed-ports: [80, 443]
+  user:
+    user-policy:
+      min-uid: 1000
+`)
+	effectiveConfig = true
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.NotNil(t, result.EffectiveConfig)
+	checks := result.EffectiveConfig.Checks
+	require.Len(t, checks, 3)
+	assert.Equal(t, map[string]any{"max-age": float64(30)}, checks[checkAge])
+	assert.Equal(t, []any{"80", "443"}, checks[checkPorts]["allowed-ports"])
+
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, checks[checkUser]["user-policy"], "inline policies are recorded by their content")
+}
+
+func TestRunAll_EffectiveConfigDisabled(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "age"
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{created: time.Now()})
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.NotContains(t, out, "effective-config")
+}
+
+func TestEffectiveCheckParams(t *testing.T) {
+	p := checkParams{
+		maxSize:          500,
+		maxLayers:        20,
+		baseLayers:       "@-",
+		allowShellForm:   true,
+		entrypointPolicy: "-",
+		userMinUID:       1000,
+		blockedUsers:     "root, admin",
+	}
+
+	assert.Equal(t, map[string]any{"max-size": uint(500), "max-layers": uint(20), "base-layers": stdinSource}, effectiveCheckParams(checkSize, p))
+	assert.Equal(t, map[string]any{"allow-shell-form": true, "entrypoint-policy": stdinSource}, effectiveCheckParams(checkEntrypoint, p))
+	assert.Equal(t, map[string]any{"min-uid": uint(1000), "blocked-users": []string{"root", "admin"}}, effectiveCheckParams(checkUser, p))
+	assert.Empty(t, effectiveCheckParams(checkHealthcheck, p))
+	assert.Equal(t, "missing.yaml", policyFileDigest("missing.yaml"))
+	assert.Equal(t, "https://example.com/policy.yaml", policyFileDigest("https://example.com/policy.yaml"))
+	assert.Equal(t, "sha256:"+strings.Repeat("a", 64), policyFileDigest("https://example.com/policy.yaml#sha256="+strings.Repeat("A", 64)))
+	policy := writeRequiredConfig(t, "min-uid: 1000\n")
+	assert.Equal(t, "sha256:d9acc1bc47fbefb567a7f1eda7d5f505140b88e14517c323ec530c1d6dc93db1", policyFileDigest(policy))
+}
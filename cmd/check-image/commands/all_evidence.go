@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/evidence"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+var evidenceDir string
+var evidenceContentBytes uint
+
+// validateEvidenceFlags rejects --evidence-content-bytes without
+// --evidence-dir.
+func validateEvidenceFlags() error {
+	if evidenceDir == "" && evidenceContentBytes > 0 {
+		return fmt.Errorf("--evidence-content-bytes requires --evidence-dir")
+	}
+	return nil
+}
+
+// writeEvidence stores the evidence of the file findings of the secrets and
+// entropy checks of run in --evidence-dir. Runs where no check was executed
+// are not recorded; runs without file findings record an empty index, so
+// that the directory shows the image was checked.
+func writeEvidence(ctx context.Context, imageName string, run *allRun) error {
+	if evidenceDir == "" || len(run.results) == 0 {
+		return nil
+	}
+	img, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if _, ok := imageutil.ArtifactOf(err); ok && artifactsMode == artifactsSkip {
+		// The checks were skipped: an artifact has no files to collect
+		// evidence of.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to collect evidence: %w", err)
+	}
+	defer cleanup()
+
+	var digest string
+	if d, err := img.Digest(); err == nil {
+		digest = d.String()
+	}
+	records, err := evidence.Collect(ctx, img, config, evidenceFindings(run.results), int64(evidenceContentBytes))
+	if err != nil {
+		return fmt.Errorf("unable to collect evidence: %w", err)
+	}
+	dir, err := evidence.Write(evidenceDir, redactText(imageName), digest, records)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"dir": dir, "findings": len(records)}).Info("Evidence written")
+	return nil
+}
+
+// evidenceFindings returns the file findings of results that evidence is
+// collected for, in report order.
+func evidenceFindings(results []output.CheckResult) []evidence.Finding {
+	var findings []evidence.Finding
+	for _, r := range results {
+		switch d := r.Details.(type) {
+		case output.SecretsDetails:
+			for _, f := range d.FileFindings {
+				findings = append(findings, evidence.Finding{Check: r.Check, Path: f.Path, LayerIndex: f.LayerIndex})
+			}
+		case output.EntropyDetails:
+			for _, f := range d.Findings {
+				findings = append(findings, evidence.Finding{Check: r.Check, Path: f.Path, LayerIndex: f.LayerIndex})
+			}
+		}
+	}
+	return findings
+}
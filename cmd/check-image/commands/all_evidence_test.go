@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/evidence"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAll_EvidenceDir(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	includeChecks = "secrets,user"
+	skipEnvVars = true
+	evidenceDir = t.TempDir()
+	evidenceContentBytes = 4
+
+	image := createTestImage(t, testImageOptions{
+		user:       "1000",
+		layerCount: 2,
+		layerFiles: []map[string]string{
+			{"app/main": "binary"},
+			{"home/app/.ssh/id_rsa": "PRIVATE KEY"},
+		},
+	})
+	captureStdout(t, func() { require.NoError(t, runAll(allCmd, image)) })
+
+	dirs, err := os.ReadDir(evidenceDir)
+	require.NoError(t, err)
+	require.Len(t, dirs, 1)
+	assert.Regexp(t, `^sha256-[0-9a-f]{64}$`, dirs[0].Name(), "evidence is stored per image digest")
+	imageDir := filepath.Join(evidenceDir, dirs[0].Name())
+
+	var index evidence.Index
+	data, err := os.ReadFile(filepath.Join(imageDir, evidence.IndexFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &index))
+	assert.Equal(t, image, index.Image)
+	require.Len(t, index.Entries, 1)
+	assert.Equal(t, evidence.IndexEntry{Check: "secrets", Path: "home/app/.ssh/id_rsa", LayerIndex: 1, Dir: "secrets/1"}, index.Entries[0])
+
+	var record evidence.Record
+	data, err = os.ReadFile(filepath.Join(imageDir, "secrets", "1", evidence.EvidenceFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &record))
+	require.NotNil(t, record.Header)
+	assert.Equal(t, int64(len("PRIVATE KEY")), record.Header.Size)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, record.SHA256)
+	assert.True(t, record.Truncated)
+
+	content, err := os.ReadFile(filepath.Join(imageDir, "secrets", "1", evidence.ContentFile))
+	require.NoError(t, err)
+	assert.Equal(t, "PRIV", string(content))
+}
+
+func TestRunAll_EvidenceDirWithoutFindings(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	includeChecks = "user"
+	evidenceDir = t.TempDir()
+
+	image := createTestImage(t, testImageOptions{user: "1000"})
+	captureStdout(t, func() { require.NoError(t, runAll(allCmd, image)) })
+
+	matches, err := filepath.Glob(filepath.Join(evidenceDir, "*", evidence.IndexFile))
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "an image without file findings has an empty index")
+	data, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"findings": []`)
+}
+
+func TestEvidenceFindings(t *testing.T) {
+	results := []output.CheckResult{
+		{Check: "secrets", Details: output.SecretsDetails{FileFindings: []output.FileFinding{{Path: "etc/key.pem", LayerIndex: 2}}}},
+		{Check: "user", Details: output.UserDetails{}},
+		{Check: "entropy", Details: output.EntropyDetails{Findings: []output.EntropyFinding{{Path: "opt/blob", LayerIndex: 0}}}},
+		{Check: "size", Error: "boom"},
+	}
+	assert.Equal(t, []evidence.Finding{
+		{Check: "secrets", Path: "etc/key.pem", LayerIndex: 2},
+		{Check: "entropy", Path: "opt/blob", LayerIndex: 0},
+	}, evidenceFindings(results))
+}
+
+func TestRunAll_EvidenceContentBytesRequiresDir(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	evidenceContentBytes = 16
+
+	err := runAll(allCmd, createTestImage(t, testImageOptions{user: "1000"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--evidence-content-bytes requires --evidence-dir")
+}
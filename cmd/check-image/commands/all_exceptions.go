@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/exceptions"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exceptionsFile string
+
+// imageExceptions holds the loaded exceptions and the digests of the image
+// being validated, so each check result can be matched against them.
+type imageExceptions struct {
+	file    *exceptions.File
+	digests []string
+	now     time.Time
+}
+
+// activeExceptions is set by setupExceptions for the image being validated
+// and is nil when no exceptions file is in use.
+var activeExceptions *imageExceptions
+
+func applyExceptionsConfig(cmd *cobra.Command, path string) {
+	if path != "" && !cmd.Flags().Changed("exceptions") {
+		exceptionsFile = path
+	}
+}
+
+// setupExceptions loads --exceptions and resolves the digests of imageName
+// for matching. It returns a policy violation for every expired exception
+// that covers one of checks, so expired exemptions fail the run instead of
+// silently lapsing.
+func setupExceptions(ctx context.Context, imageName string, checks []checkDef) ([]string, error) {
+	activeExceptions = nil
+	if exceptionsFile == "" {
+		return nil, nil
+	}
+	f, err := exceptions.Load(exceptionsFile, validCheckNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exceptions file: %w", err)
+	}
+	if len(f.Exceptions) == 0 {
+		return nil, nil
+	}
+
+	digests, err := imageutil.ImageDigests(ctx, imageName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"image": logutil.SanitizeLogValue(imageName),
+			"error": err,
+		}).Warn("Unable to resolve the image digest, exceptions are not applied")
+		return nil, nil
+	}
+
+	ex := &imageExceptions{file: f, digests: digests, now: time.Now()}
+	var violations []string
+	for _, c := range checks {
+		_, expired := f.Match(digests, c.name, ex.now)
+		for _, e := range expired {
+			log.WithFields(log.Fields{
+				"check":    c.name,
+				"digest":   e.Digest,
+				"expires":  e.Expires,
+				"approver": logutil.SanitizeLogValue(e.Approver),
+				"ticket":   logutil.SanitizeLogValue(e.Ticket),
+			}).Error("Exception has expired")
+			violations = append(violations, fmt.Sprintf("exception for check %q on %s expired on %s (approved by %s)",
+				c.name, e.Digest, e.Expires, e.Approver))
+		}
+	}
+	activeExceptions = ex
+	return violations, nil
+}
+
+// applyException passes a failed check result when an active exception
+// covers it, recording the exception in the result. Execution errors are
+// never exempted.
+func applyException(result *output.CheckResult) {
+	if activeExceptions == nil || result.Passed || result.Error != "" {
+		return
+	}
+	active, _ := activeExceptions.file.Match(activeExceptions.digests, result.Check, activeExceptions.now)
+	if len(active) == 0 {
+		return
+	}
+	e := active[0]
+	result.Passed = true
+	result.Exception = checkException(e)
+	result.Message = fmt.Sprintf("%s (exempted until %s, approved by %s)", result.Message, e.Expires, e.Approver)
+	log.WithFields(log.Fields{
+		"check":    result.Check,
+		"digest":   e.Digest,
+		"approver": logutil.SanitizeLogValue(e.Approver),
+	}).Info("Check failure exempted by exception")
+}
+
+func checkException(e exceptions.Exception) *output.CheckException {
+	return &output.CheckException{
+		Digest:   e.Digest,
+		Approver: e.Approver,
+		Ticket:   e.Ticket,
+		Reason:   e.Reason,
+		Expires:  e.Expires,
+	}
+}
+
+// printExceptionLine prints the exception that passed a check in text mode.
+func printExceptionLine(w io.Writer, result *output.CheckResult) {
+	e := result.Exception
+	if e == nil {
+		return
+	}
+	line := fmt.Sprintf("Exempted: approved by %s until %s", e.Approver, e.Expires)
+	if e.Ticket != "" {
+		line += " (" + e.Ticket + ")"
+	}
+	fmt.Fprintln(w, line)
+}
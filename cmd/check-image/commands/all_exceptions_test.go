@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeExceptionsFile writes an exceptions file granting digest an exemption
+// from the user check that expires at expires.
+func writeExceptionsFile(t *testing.T, digest, expires string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "exceptions.yaml")
+	content := fmt.Sprintf(`exceptions:
+  - digest: %s
+    checks: [user]
+    approver: jane@example.com
+    ticket: https://issues.example.com/SEC-42
+    reason: Legacy image, migration planned
+    expires: %q
+`, digest, expires)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func rootImageDigest(t *testing.T) (string, string) {
+	t.Helper()
+	image := createTestImage(t, testImageOptions{user: "root"})
+	digests, err := imageutil.ImageDigests(context.Background(), image)
+	require.NoError(t, err)
+	require.NotEmpty(t, digests)
+	return image, digests[len(digests)-1]
+}
+
+func TestRunAll_Exceptions(t *testing.T) {
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	past := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+
+	tests := []struct {
+		name           string
+		expires        string
+		otherDigest    bool
+		wantPassed     bool
+		wantException  bool
+		wantViolations int
+	}{
+		{name: "Active exception passes the failed check", expires: future, wantPassed: true, wantException: true},
+		{name: "Expired exception fails loudly", expires: past, wantPassed: false, wantViolations: 1},
+		{name: "Exception for another digest is ignored", expires: future, otherDigest: true, wantPassed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			includeChecks = "user"
+			OutputFmt = output.FormatJSON
+
+			image, digest := rootImageDigest(t)
+			if tt.otherDigest {
+				digest = "sha256:" + fmt.Sprintf("%064d", 1)
+			}
+			exceptionsFile = writeExceptionsFile(t, digest, tt.expires)
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runAll(allCmd, image))
+			})
+
+			var result output.AllResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Len(t, result.PolicyViolations, tt.wantViolations)
+			require.Len(t, result.Checks, 1)
+			check := result.Checks[0]
+			if !tt.wantException {
+				assert.Nil(t, check.Exception)
+				assert.False(t, check.Passed)
+				return
+			}
+			assert.True(t, check.Passed)
+			require.NotNil(t, check.Exception)
+			assert.Equal(t, "jane@example.com", check.Exception.Approver)
+			assert.Equal(t, "https://issues.example.com/SEC-42", check.Exception.Ticket)
+			assert.Contains(t, check.Message, "exempted until "+tt.expires)
+			assert.Equal(t, ValidationSucceeded, Result)
+		})
+	}
+}
+
+func TestRunAll_ExceptionsTextOutput(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	image, digest := rootImageDigest(t)
+	exceptionsFile = writeExceptionsFile(t, digest, time.Now().AddDate(0, 1, 0).Format("2006-01-02"))
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image))
+	})
+
+	assert.Contains(t, out, "Exempted: approved by jane@example.com until ")
+	assert.Contains(t, out, "(https://issues.example.com/SEC-42)")
+}
+
+func TestRunAll_ExceptionsFromConfig(t *testing.T) {
+	resetAllGlobals(t)
+	image, digest := rootImageDigest(t)
+	path := writeExceptionsFile(t, digest, time.Now().AddDate(0, 0, -1).Format("2006-01-02"))
+	configFile = writeRequiredConfig(t, "exceptions: "+path+"\nchecks:\n  user: {}\n")
+
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image))
+	})
+	assert.Equal(t, ValidationFailed, Result, "the expired exception of the config applies")
+	assert.Empty(t, exceptionsFile, "the config value is restored after the run")
+}
+
+func TestRunAll_InvalidExceptionsFile(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	exceptionsFile = writeRequiredConfig(t, "exceptions:\n  - {digest: nginx, checks: [user], approver: ops, expires: 2026-12-31}\n")
+
+	err := runAll(allCmd, createTestImage(t, testImageOptions{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid exceptions file")
+}
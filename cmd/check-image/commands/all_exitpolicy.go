@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/secrets"
+	log "github.com/sirupsen/logrus"
+)
+
+// Actions of the exit policy for the findings of a severity, from the least
+// strict to the strictest.
+const (
+	exitActionIgnore = "ignore"
+	exitActionWarn   = "warn"
+	exitActionFail   = "fail"
+)
+
+var exitActions = []string{exitActionIgnore, exitActionWarn, exitActionFail}
+
+// defaultExitAction is the action of severities the exit policy omits.
+const defaultExitAction = exitActionWarn
+
+// defaultFindingSeverity is the severity of findings whose check and rule
+// have none in severities. Secrets findings carry their own severity.
+const defaultFindingSeverity = secrets.SeverityHigh
+
+// exitPolicy decides from the severity of their findings whether failed
+// checks fail the run.
+type exitPolicy struct {
+	// actions maps severities to actions.
+	actions map[string]string
+	// severities maps check and check/rule names to severities.
+	severities map[string]string
+}
+
+// activeExitPolicy is the exit policy of the config file the all command
+// validates with, set by evaluateAll; nil when it has none.
+var activeExitPolicy *exitPolicy
+
+// newExitPolicy returns the exit policy of cfg, or nil when it has none.
+func newExitPolicy(cfg *allConfig) *exitPolicy {
+	if cfg == nil || len(cfg.ExitPolicy) == 0 {
+		return nil
+	}
+	return &exitPolicy{actions: cfg.ExitPolicy, severities: cfg.Severities}
+}
+
+// validateExitPolicy checks the exit-policy and severities of cfg and
+// normalizes their severities.
+func validateExitPolicy(cfg *allConfig) error {
+	if len(cfg.ExitPolicy) == 0 {
+		if len(cfg.Severities) > 0 {
+			return fmt.Errorf("severities requires exit-policy")
+		}
+		return nil
+	}
+
+	actions := make(map[string]string, len(cfg.ExitPolicy))
+	for s, action := range cfg.ExitPolicy {
+		severity, err := secrets.ParseSeverity(s)
+		if err != nil {
+			return fmt.Errorf("invalid exit-policy: %w", err)
+		}
+		if !slices.Contains(exitActions, action) {
+			return fmt.Errorf("invalid exit-policy action %q for %s, valid values are: %s", action, severity, strings.Join(exitActions, ", "))
+		}
+		actions[severity] = action
+	}
+	cfg.ExitPolicy = actions
+
+	severities := make(map[string]string, len(cfg.Severities))
+	for key, s := range cfg.Severities {
+		check, _, _ := strings.Cut(key, "/")
+		if !slices.Contains(validCheckNames, check) {
+			return fmt.Errorf("invalid severities key %q: unknown check %q", key, check)
+		}
+		severity, err := secrets.ParseSeverity(s)
+		if err != nil {
+			return fmt.Errorf("invalid severities.%s: %w", key, err)
+		}
+		severities[key] = severity
+	}
+	cfg.Severities = severities
+	return nil
+}
+
+// severity returns the severity of the findings of rule of check.
+func (p *exitPolicy) severity(check, rule string) string {
+	if rule != "" {
+		if s, ok := p.severities[check+"/"+rule]; ok {
+			return s
+		}
+	}
+	if s, ok := p.severities[check]; ok {
+		return s
+	}
+	return defaultFindingSeverity
+}
+
+// action returns the action of severity.
+func (p *exitPolicy) action(severity string) string {
+	if a, ok := p.actions[severity]; ok {
+		return a
+	}
+	return defaultExitAction
+}
+
+// findingSeverities returns the severity of every finding of a failed result.
+// Secrets findings below the --fail-on-severity of the check did not fail it
+// and are left out.
+func (p *exitPolicy) findingSeverities(r *output.CheckResult) []string {
+	var severities []string
+	if d, ok := r.Details.(output.SecretsDetails); ok {
+		add := func(s string) {
+			if s == "" {
+				s = secrets.DefaultSeverity
+			}
+			if d.FailOnSeverity == "" || secrets.AtLeast(s, d.FailOnSeverity) {
+				severities = append(severities, s)
+			}
+		}
+		for _, f := range d.EnvVarFindings {
+			add(f.Severity)
+		}
+		for _, f := range d.FileFindings {
+			add(f.Severity)
+		}
+		if len(severities) > 0 {
+			return severities
+		}
+	}
+	for _, f := range output.CheckFindings(*r) {
+		severities = append(severities, p.severity(r.Check, f.Rule))
+	}
+	return severities
+}
+
+// decide returns the strictest action of the findings of a failed result and
+// the highest severity with that action.
+func (p *exitPolicy) decide(r *output.CheckResult) (action, severity string) {
+	for _, s := range p.findingSeverities(r) {
+		a := p.action(s)
+		rank, current := slices.Index(exitActions, a), slices.Index(exitActions, action)
+		if rank > current || (rank == current && secrets.AtLeast(s, severity)) {
+			action, severity = a, s
+		}
+	}
+	return action, severity
+}
+
+// applyExitPolicy passes a failed check whose findings are all of severities
+// the active exit policy only warns about or ignores. Results that errored
+// are left as they are.
+func applyExitPolicy(result *output.CheckResult) {
+	if activeExitPolicy == nil || result.Passed || result.Error != "" {
+		return
+	}
+	action, severity := activeExitPolicy.decide(result)
+	result.ExitPolicy = &output.CheckExitPolicy{Severity: severity, Action: action}
+	if action == exitActionFail {
+		return
+	}
+	result.Passed = true
+	result.Message = fmt.Sprintf("%s (%s severity, %s by the exit policy)", result.Message, severity, action)
+	entry := log.WithFields(log.Fields{"check": result.Check, "severity": severity})
+	if action == exitActionWarn {
+		entry.Warn("Check failure does not fail the run under the exit policy")
+	} else {
+		entry.Debug("Check failure ignored by the exit policy")
+	}
+}
+
+// printExitPolicyLine prints the exit policy decision of a failed check in
+// text mode.
+func printExitPolicyLine(w io.Writer, result *output.CheckResult) {
+	e := result.ExitPolicy
+	if e == nil {
+		return
+	}
+	fmt.Fprintf(w, "Exit policy: %s severity, %s\n", e.Severity, e.Action)
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllConfig_ExitPolicy(t *testing.T) {
+	cfg, err := parseAllConfig([]byte("exit-policy:\n  Critical: fail\n  high: fail\n  medium: warn\nseverities:\n  labels: medium\n  ports/allowed-ports: LOW\n"), "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"critical": "fail", "high": "fail", "medium": "warn"}, cfg.ExitPolicy)
+	assert.Equal(t, map[string]string{"labels": "medium", "ports/allowed-ports": "low"}, cfg.Severities)
+
+	tests := map[string]string{
+		`{"exit-policy": {"severe": "fail"}}`:                             `invalid exit-policy: invalid severity "severe"`,
+		`{"exit-policy": {"high": "block"}}`:                              `invalid exit-policy action "block" for high`,
+		`{"exit-policy": {"high": "fail"}, "severities": {"cve": "low"}}`: `invalid severities key "cve": unknown check "cve"`,
+		`{"exit-policy": {"high": "fail"}, "severities": {"user": "x"}}`:  `invalid severities.user: invalid severity "x"`,
+		`{"severities": {"user": "low"}}`:                                 `severities requires exit-policy`,
+	}
+	for config, want := range tests {
+		_, err := parseAllConfig([]byte(config), "config.json")
+		require.Error(t, err, config)
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestExitPolicy_Decide(t *testing.T) {
+	p := &exitPolicy{
+		actions:    map[string]string{"critical": "fail", "high": "fail", "medium": "warn", "low": "ignore"},
+		severities: map[string]string{"labels": "medium", "labels/invalid-label": "low"},
+	}
+	labels := func(missing []string, invalid []output.InvalidLabelDetail) *output.CheckResult {
+		return &output.CheckResult{Check: "labels", Details: output.LabelsDetails{MissingLabels: missing, InvalidLabels: invalid}}
+	}
+	secretsResult := func(failOn string, severities ...string) *output.CheckResult {
+		var findings []output.FileFinding
+		for _, s := range severities {
+			findings = append(findings, output.FileFinding{Path: "f", Severity: s})
+		}
+		return &output.CheckResult{Check: "secrets", Details: output.SecretsDetails{FileFindings: findings, FailOnSeverity: failOn}}
+	}
+
+	tests := []struct {
+		name         string
+		result       *output.CheckResult
+		wantAction   string
+		wantSeverity string
+	}{
+		{"rule severity", labels(nil, []output.InvalidLabelDetail{{Name: "version"}}), "ignore", "low"},
+		{"check severity", labels([]string{"owner"}, []output.InvalidLabelDetail{{Name: "version"}}), "warn", "medium"},
+		{"default severity", &output.CheckResult{Check: "user", Message: "runs as root"}, "fail", "high"},
+		{"secrets severities", secretsResult("", "low", "medium"), "warn", "medium"},
+		{"critical secret", secretsResult("", "medium", "critical"), "fail", "critical"},
+		{"below fail-on-severity", secretsResult("high", "low", "high"), "fail", "high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, severity := p.decide(tt.result)
+			assert.Equal(t, tt.wantAction, action)
+			assert.Equal(t, tt.wantSeverity, severity)
+		})
+	}
+
+	omitted := &exitPolicy{actions: map[string]string{"critical": "fail"}}
+	action, _ := omitted.decide(&output.CheckResult{Check: "user"})
+	assert.Equal(t, defaultExitAction, action, "severities the exit policy omits warn")
+}
+
+func TestApplyExitPolicy(t *testing.T) {
+	resetAllGlobals(t)
+	failed := output.CheckResult{Check: "labels", Message: "Missing labels", Details: output.LabelsDetails{MissingLabels: []string{"owner"}}}
+
+	result := failed
+	applyExitPolicy(&result)
+	assert.Equal(t, failed, result, "without an exit policy results are unchanged")
+
+	activeExitPolicy = &exitPolicy{actions: map[string]string{"high": "fail", "medium": "warn"}, severities: map[string]string{"labels": "medium"}}
+	result = failed
+	applyExitPolicy(&result)
+	assert.True(t, result.Passed)
+	assert.Equal(t, &output.CheckExitPolicy{Severity: "medium", Action: "warn"}, result.ExitPolicy)
+	assert.Equal(t, "Missing labels (medium severity, warn by the exit policy)", result.Message)
+
+	activeExitPolicy.severities = nil
+	result = failed
+	applyExitPolicy(&result)
+	assert.False(t, result.Passed)
+	assert.Equal(t, &output.CheckExitPolicy{Severity: "high", Action: "fail"}, result.ExitPolicy)
+
+	errored := output.CheckResult{Check: "labels", Error: "boom"}
+	applyExitPolicy(&errored)
+	assert.Nil(t, errored.ExitPolicy, "execution errors are not subject to the exit policy")
+}
+
+func TestRunAll_ExitPolicy(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	OutputFmt = output.FormatJSON
+	imageRef := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+
+	run := func(config string) output.AllResult {
+		t.Helper()
+		Result = ValidationSkipped
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0600))
+		captured := captureStdout(t, func() {
+			require.NoError(t, runAll(allCmd, imageRef))
+		})
+		var report output.AllResult
+		require.NoError(t, json.Unmarshal([]byte(captured), &report))
+		return report
+	}
+
+	report := run("checks:\n  user: {}\nexit-policy:\n  high: fail\n  medium: warn\nseverities:\n  user: medium\n")
+	assert.True(t, report.Passed, "findings that only warn do not fail the run")
+	assert.Equal(t, ValidationSucceeded, Result)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, &output.CheckExitPolicy{Severity: "medium", Action: "warn"}, report.Checks[0].ExitPolicy)
+
+	report = run("checks:\n  user: {}\nexit-policy:\n  high: fail\n  medium: warn\n")
+	assert.False(t, report.Passed)
+	assert.Equal(t, ValidationFailed, Result)
+	assert.Equal(t, &output.CheckExitPolicy{Severity: "high", Action: "fail"}, report.Checks[0].ExitPolicy)
+}
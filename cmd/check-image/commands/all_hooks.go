@@ -0,0 +1,389 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// Verdicts passed to hooks in CHECK_IMAGE_VERDICT.
+const (
+	hookVerdictPassed = "passed"
+	hookVerdictFailed = "failed"
+	hookVerdictError  = "error"
+)
+
+// Limits of a hook unless its configuration overrides them.
+const (
+	defaultHookTimeout   = 5 * time.Minute
+	defaultHookMaxOutput = 1 << 20
+)
+
+// hooksConfig is the top-level hooks key: chains of commands run after the
+// all command finishes, selected by the verdict.
+type hooksConfig struct {
+	OnSuccess []hookConfig `json:"on-success,omitempty" yaml:"on-success,omitempty"`
+	OnFailure []hookConfig `json:"on-failure,omitempty" yaml:"on-failure,omitempty"`
+	OnError   []hookConfig `json:"on-error,omitempty"   yaml:"on-error,omitempty"`
+	// Always runs after the chain of the verdict, whatever it is.
+	Always []hookConfig `json:"always,omitempty" yaml:"always,omitempty"`
+}
+
+// hookConfig is a command of a hook chain.
+type hookConfig struct {
+	// Run is a shell command (sh -c, or cmd /C on Windows).
+	Run string `json:"run" yaml:"run"`
+	// WithReport writes the JSON report to a temporary file whose path is
+	// passed in CHECK_IMAGE_REPORT.
+	WithReport bool `json:"with-report,omitempty" yaml:"with-report,omitempty"`
+	// Timeout is the Go duration after which the hook is killed (default
+	// 5m, 0 for none).
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxOutput is the number of bytes of output kept; the rest is dropped
+	// (default 1 MiB, 0 for no limit).
+	MaxOutput *int64 `json:"max-output,omitempty" yaml:"max-output,omitempty"`
+	// PassEnv restricts the environment of check-image passed to the hook to
+	// these variables (a trailing * matches a prefix), PATH, and the
+	// CHECK_IMAGE_ variables of the hook. Nil passes the whole environment.
+	PassEnv []string `json:"pass-env,omitempty" yaml:"pass-env,omitempty"`
+	// IsolateWorkdir runs the hook in an empty temporary directory, removed
+	// after it exits, instead of the working directory of check-image.
+	IsolateWorkdir bool `json:"isolate-workdir,omitempty" yaml:"isolate-workdir,omitempty"`
+}
+
+// timeout returns the timeout of the hook, 0 for none.
+func (h hookConfig) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return defaultHookTimeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q, expected a duration such as 30s", h.Timeout)
+	}
+	return d, nil
+}
+
+// maxOutput returns the output limit of the hook in bytes, 0 for none.
+func (h hookConfig) maxOutput() int64 {
+	if h.MaxOutput == nil {
+		return defaultHookMaxOutput
+	}
+	return *h.MaxOutput
+}
+
+// validate checks the limits of the hook.
+func (h hookConfig) validate() error {
+	if h.Run == "" {
+		return fmt.Errorf("run is required")
+	}
+	if _, err := h.timeout(); err != nil {
+		return err
+	}
+	if h.MaxOutput != nil && *h.MaxOutput < 0 {
+		return fmt.Errorf("invalid max-output %d, expected a number of bytes", *h.MaxOutput)
+	}
+	for _, name := range h.PassEnv {
+		if strings.TrimSuffix(name, "*") == "" || strings.Contains(name, "=") {
+			return fmt.Errorf("invalid pass-env variable %q", name)
+		}
+	}
+	return nil
+}
+
+// hookChain is a hook chain with its event name.
+type hookChain struct {
+	event string
+	hooks []hookConfig
+}
+
+// chains returns the chains to run for verdict, in order.
+func (h *hooksConfig) chains(verdict string) []hookChain {
+	var chains []hookChain
+	switch verdict {
+	case hookVerdictPassed:
+		chains = append(chains, hookChain{"on-success", h.OnSuccess})
+	case hookVerdictFailed:
+		chains = append(chains, hookChain{"on-failure", h.OnFailure})
+	case hookVerdictError:
+		chains = append(chains, hookChain{"on-error", h.OnError})
+	}
+	return append(chains, hookChain{"always", h.Always})
+}
+
+// all returns every chain.
+func (h *hooksConfig) all() []hookChain {
+	return []hookChain{
+		{"on-success", h.OnSuccess},
+		{"on-failure", h.OnFailure},
+		{"on-error", h.OnError},
+		{"always", h.Always},
+	}
+}
+
+// wantReport reports whether any hook has with-report set.
+func (h *hooksConfig) wantReport() bool {
+	for _, chain := range h.all() {
+		for _, hook := range chain.hooks {
+			if hook.WithReport {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// activeHooks are the hooks of the config file the all command validates
+// with, set by evaluateAll; nil when it has none.
+var activeHooks *hooksConfig
+
+// hookReport is the report the all command rendered last, written for hooks
+// with with-report.
+var hookReport any
+
+// hookOut receives the output of hooks, so that stdout only carries the
+// report; tests replace it.
+var hookOut io.Writer = os.Stderr
+
+// validateHooks checks the hooks of cfg.
+func validateHooks(cfg *allConfig) error {
+	if cfg.Hooks == nil {
+		return nil
+	}
+	for _, chain := range cfg.Hooks.all() {
+		for i, h := range chain.hooks {
+			if err := h.validate(); err != nil {
+				return fmt.Errorf("invalid hooks.%s entry %d: %w", chain.event, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkHooksSource rejects the hooks of a config read from stdin or a URL.
+// Hooks run commands on the host, so they are only accepted from a local
+// config file or policy profile.
+func checkHooksSource(path string, cfg *allConfig) error {
+	if cfg == nil || cfg.Hooks == nil {
+		return nil
+	}
+	switch {
+	case path == "-":
+		return fmt.Errorf("hooks are only accepted from a local config file, not from stdin")
+	case fileutil.IsURL(path):
+		return fmt.Errorf("hooks are only accepted from a local config file, not from a URL")
+	}
+	return nil
+}
+
+// withHooks runs fn and then the hook chains of the verdict configured in
+// the hooks key of the config file. Hooks of a chain run in order, and a hook
+// that fails stops its chain. Hook failures are logged and do not change the
+// validation result.
+func withHooks(ctx context.Context, imageName string, fn func() error) error {
+	runErr := fn()
+	if activeHooks == nil {
+		return runErr
+	}
+
+	verdict := hookVerdict(runErr)
+	env := []string{"CHECK_IMAGE_VERDICT=" + verdict}
+	if imageName != "" && imageName != bulkImageArg {
+		env = append(env, "CHECK_IMAGE_IMAGE="+redactText(imageName))
+	}
+
+	reportPath, cleanup, err := writeHookReport()
+	defer cleanup()
+	if err != nil {
+		log.WithField("error", err).Warn("Failed to write the report for hooks")
+	}
+
+	for _, chain := range activeHooks.chains(verdict) {
+		runHookChain(ctx, chain, env, reportPath)
+	}
+	return runErr
+}
+
+// hookVerdict returns the verdict of the run, as commitStatus does.
+func hookVerdict(runErr error) string {
+	switch {
+	case runErr != nil || Result == ExecutionError:
+		return hookVerdictError
+	case Result == ValidationFailed:
+		return hookVerdictFailed
+	}
+	return hookVerdictPassed
+}
+
+// writeHookReport writes hookReport to a temporary file when a hook asks for
+// it, returning its path and a function that removes it. The path is empty
+// when no report was rendered.
+func writeHookReport() (string, func(), error) {
+	if hookReport == nil || !activeHooks.wantReport() {
+		return "", func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "check-image-hook-")
+	if err != nil {
+		return "", func() {}, fmt.Errorf("error creating report directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, "report.json")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("error creating report file: %w", err)
+	}
+	err = output.RenderJSON(f, hookReport)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", cleanup, fmt.Errorf("error writing report file: %w", err)
+	}
+	return path, cleanup, nil
+}
+
+// runHookChain runs the hooks of chain in order, stopping at the first one
+// that fails.
+func runHookChain(ctx context.Context, chain hookChain, env []string, reportPath string) {
+	for i, h := range chain.hooks {
+		hookEnv := append(slices.Clone(env), "CHECK_IMAGE_HOOK="+chain.event)
+		if h.WithReport && reportPath != "" {
+			hookEnv = append(hookEnv, "CHECK_IMAGE_REPORT="+reportPath)
+		}
+		fields := log.Fields{"hook": chain.event, "command": redactText(h.Run)}
+		log.WithFields(fields).Debug("Running hook")
+		if err := runHook(ctx, h, hookEnv); err != nil {
+			log.WithFields(fields).WithField("error", err).Warn("Hook failed")
+			if rest := len(chain.hooks) - i - 1; rest > 0 {
+				log.WithFields(fields).WithField("skipped", rest).Warn("Skipping the remaining hooks of the chain")
+			}
+			return
+		}
+	}
+}
+
+// runHook runs the command of h with the shell of the platform, adding env to
+// the environment passed from check-image, within the limits of h.
+func runHook(ctx context.Context, h hookConfig, env []string) error {
+	timeout, err := h.timeout()
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	// #nosec G204 -- hooks are commands of the config file, run on purpose
+	c := exec.CommandContext(ctx, shell, flag, h.Run)
+	c.Env = append(hookEnviron(os.Environ(), h.PassEnv), env...)
+	out := &cappedWriter{w: hookOut, limit: h.maxOutput()}
+	c.Stdout = out
+	c.Stderr = out
+	// Background processes of the hook keeping its output open must not
+	// block check-image once the hook is killed.
+	c.WaitDelay = time.Second
+
+	if h.IsolateWorkdir {
+		dir, err := os.MkdirTemp("", "check-image-hook-workdir-")
+		if err != nil {
+			return fmt.Errorf("error creating working directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		c.Dir = dir
+	}
+
+	err = c.Run()
+	if dropped := out.dropped; dropped > 0 {
+		log.WithFields(log.Fields{"limit": out.limit, "dropped": dropped}).Warn("Hook output exceeded max-output and was truncated")
+	}
+	if err != nil {
+		if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("exit status %d", exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+// hookEnviron returns the variables of environ passed to a hook: all of them
+// when passEnv is nil, otherwise PATH (and what cmd needs on Windows) and the
+// variables named in passEnv, where a trailing * matches a prefix.
+func hookEnviron(environ, passEnv []string) []string {
+	if passEnv == nil {
+		return environ
+	}
+	names := append([]string{"PATH"}, passEnv...)
+	if runtime.GOOS == "windows" {
+		names = append(names, "SystemRoot", "ComSpec", "PATHEXT")
+	}
+	var env []string
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if slices.ContainsFunc(names, func(name string) bool { return envNameMatches(name, key) }) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// envNameMatches reports whether the variable key matches name, a variable
+// name or a prefix ending in *. Names are case-insensitive on Windows.
+func envNameMatches(name, key string) bool {
+	if runtime.GOOS == "windows" {
+		name, key = strings.ToUpper(name), strings.ToUpper(key)
+	}
+	if prefix, ok := strings.CutSuffix(name, "*"); ok {
+		return strings.HasPrefix(key, prefix)
+	}
+	return name == key
+}
+
+// cappedWriter writes up to limit bytes to w (all of them when limit is 0)
+// and drops the rest, counting it, without failing the writes so that the
+// hook is not killed by a broken pipe.
+type cappedWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	limit   int64
+	written int64
+	dropped int64
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keep := int64(len(p))
+	if c.limit > 0 {
+		keep = min(keep, max(c.limit-c.written, 0))
+	}
+	if keep > 0 {
+		if _, err := c.w.Write(p[:keep]); err != nil {
+			return 0, err
+		}
+		c.written += keep
+	}
+	c.dropped += int64(len(p)) - keep
+	return len(p), nil
+}
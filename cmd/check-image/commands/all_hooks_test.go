@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllConfig_Hooks(t *testing.T) {
+	cfg, err := parseAllConfig([]byte("hooks:\n  on-failure:\n    - run: ./notify.sh\n      with-report: true\n  always:\n    - run: echo done\n"), "config.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Hooks)
+	assert.Equal(t, []hookConfig{{Run: "./notify.sh", WithReport: true}}, cfg.Hooks.OnFailure)
+	assert.Equal(t, []hookConfig{{Run: "echo done"}}, cfg.Hooks.Always)
+
+	_, err = parseAllConfig([]byte(`{"hooks": {"on-error": [{"run": "a"}, {"with-report": true}]}}`), "config.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid hooks.on-error entry 2: run is required")
+}
+
+func TestParseAllConfig_HookLimits(t *testing.T) {
+	config := strings.Join([]string{
+		"hooks:",
+		"  always:",
+		"    - run: ./upload.sh",
+		"      timeout: 30s",
+		"      max-output: 4096",
+		"      pass-env: [UPLOAD_TOKEN, AWS_*]",
+		"      isolate-workdir: true",
+	}, "\n")
+	cfg, err := parseAllConfig([]byte(config), "config.yaml")
+	require.NoError(t, err)
+	require.Len(t, cfg.Hooks.Always, 1)
+	h := cfg.Hooks.Always[0]
+	timeout, err := h.timeout()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+	assert.Equal(t, int64(4096), h.maxOutput())
+	assert.Equal(t, []string{"UPLOAD_TOKEN", "AWS_*"}, h.PassEnv)
+	assert.True(t, h.IsolateWorkdir)
+
+	defaults := hookConfig{Run: "true"}
+	timeout, err = defaults.timeout()
+	require.NoError(t, err)
+	assert.Equal(t, defaultHookTimeout, timeout)
+	assert.Equal(t, int64(defaultHookMaxOutput), defaults.maxOutput())
+
+	tests := map[string]string{
+		`{"run": "a", "timeout": "soon"}`:       `invalid timeout "soon"`,
+		`{"run": "a", "timeout": "-1s"}`:        `invalid timeout "-1s"`,
+		`{"run": "a", "max-output": -1}`:        "invalid max-output -1",
+		`{"run": "a", "pass-env": ["A=b"]}`:     `invalid pass-env variable "A=b"`,
+		`{"run": "a", "pass-env": ["*"]}`:       `invalid pass-env variable "*"`,
+		`{"run": "a", "pass-env": ["HOME"]}`:    "",
+		`{"run": "a", "timeout": "0"}`:          "",
+		`{"run": "a", "max-output": 0}`:         "",
+		`{"run": "a", "isolate-workdir": true}`: "",
+	}
+	for hook, want := range tests {
+		_, err := parseAllConfig([]byte(`{"hooks": {"on-success": [`+hook+`]}}`), "config.json")
+		if want == "" {
+			assert.NoError(t, err, hook)
+			continue
+		}
+		require.Error(t, err, hook)
+		assert.Contains(t, err.Error(), "invalid hooks.on-success entry 1: "+want)
+	}
+}
+
+func TestHookVerdict(t *testing.T) {
+	tests := []struct {
+		result ValidationResult
+		runErr error
+		want   string
+	}{
+		{ValidationSucceeded, nil, hookVerdictPassed},
+		{ValidationSkipped, nil, hookVerdictPassed},
+		{ValidationFailed, nil, hookVerdictFailed},
+		{ExecutionError, nil, hookVerdictError},
+		{ValidationSucceeded, errors.New("boom"), hookVerdictError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			resetAllGlobals(t)
+			Result = tt.result
+			assert.Equal(t, tt.want, hookVerdict(tt.runErr))
+		})
+	}
+}
+
+func TestWithHooks_RunsChainsOfVerdict(t *testing.T) {
+	resetAllGlobals(t)
+	dir := t.TempDir()
+	activeHooks = &hooksConfig{
+		OnSuccess: []hookConfig{{Run: "touch " + filepath.Join(dir, "success")}},
+		OnFailure: []hookConfig{
+			{Run: `echo "$CHECK_IMAGE_HOOK $CHECK_IMAGE_VERDICT $CHECK_IMAGE_IMAGE" > ` + filepath.Join(dir, "env")},
+			{Run: `cp "$CHECK_IMAGE_REPORT" ` + filepath.Join(dir, "report.json"), WithReport: true},
+		},
+		Always: []hookConfig{{Run: `echo "$CHECK_IMAGE_HOOK ${CHECK_IMAGE_REPORT:-none}" > ` + filepath.Join(dir, "always")}},
+	}
+
+	err := withHooks(context.Background(), "nginx:latest", func() error {
+		Result = ValidationFailed
+		hookReport = output.AllResult{Image: "nginx:latest", Passed: false}
+		return nil
+	})
+	require.NoError(t, err)
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	require.NoError(t, err)
+	assert.Equal(t, "on-failure failed nginx:latest\n", string(env))
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	require.NoError(t, err)
+	var report output.AllResult
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "nginx:latest", report.Image)
+
+	always, err := os.ReadFile(filepath.Join(dir, "always"))
+	require.NoError(t, err)
+	assert.Equal(t, "always none\n", string(always), "the report is only passed to hooks with with-report")
+
+	assert.NoFileExists(t, filepath.Join(dir, "success"))
+}
+
+func TestWithHooks_FailedHookStopsChain(t *testing.T) {
+	resetAllGlobals(t)
+	dir := t.TempDir()
+	activeHooks = &hooksConfig{
+		OnError: []hookConfig{
+			{Run: "exit 3"},
+			{Run: "touch " + filepath.Join(dir, "skipped")},
+		},
+		Always: []hookConfig{{Run: "touch " + filepath.Join(dir, "always")}},
+	}
+
+	runErr := errors.New("image not found")
+	err := withHooks(context.Background(), "", func() error { return runErr })
+	assert.Equal(t, runErr, err, "hooks never change the result of the run")
+	assert.NoFileExists(t, filepath.Join(dir, "skipped"))
+	assert.FileExists(t, filepath.Join(dir, "always"))
+}
+
+func TestWithHooks_NoHooks(t *testing.T) {
+	resetAllGlobals(t)
+	called := false
+	require.NoError(t, withHooks(context.Background(), "nginx:latest", func() error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+}
+
+func TestRunAll_HooksFromConfig(t *testing.T) {
+	resetAllGlobals(t)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "report.json")
+	configFile = filepath.Join(dir, "config.yaml")
+	config := strings.Join([]string{
+		"checks:",
+		"  user: {}",
+		"hooks:",
+		"  on-success:",
+		`    - run: cp "$CHECK_IMAGE_REPORT" ` + out,
+		"      with-report: true",
+	}, "\n")
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0600))
+	image := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	captureStdout(t, func() {
+		require.NoError(t, withHooks(context.Background(), image, func() error { return runAll(allCmd, image) }))
+	})
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var report output.AllResult
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, image, report.Image)
+	assert.True(t, report.Passed)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "user", report.Checks[0].Check)
+}
+
+func TestCheckHooksSource(t *testing.T) {
+	hooks := &allConfig{Hooks: &hooksConfig{Always: []hookConfig{{Run: "echo done"}}}}
+	tests := []struct {
+		name    string
+		path    string
+		cfg     *allConfig
+		wantErr string
+	}{
+		{name: "Local file", path: "config.yaml", cfg: hooks},
+		{name: "Stdin", path: "-", cfg: hooks, wantErr: "not from stdin"},
+		{name: "URL", path: "https://example.com/config.yaml", cfg: hooks, wantErr: "not from a URL"},
+		{name: "URL without hooks", path: "https://example.com/config.yaml", cfg: &allConfig{}},
+		{name: "No config", path: "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHooksSource(tt.path, tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRunAll_HooksFromStdinConfig(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = "-"
+	withStdin(t, "checks:\n  user: {}\nhooks:\n  always:\n    - run: echo done\n")
+	image := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	err := runAll(allCmd, image)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hooks are only accepted from a local config file, not from stdin")
+	assert.Nil(t, activeHooks)
+}
+
+func TestRunHook_Timeout(t *testing.T) {
+	resetAllGlobals(t)
+	start := time.Now()
+	err := runHook(context.Background(), hookConfig{Run: "sleep 10", Timeout: "100ms"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, "timed out after 100ms", err.Error())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRunHook_MaxOutput(t *testing.T) {
+	resetAllGlobals(t)
+	var out strings.Builder
+	hookOut = &out
+
+	limit := int64(10)
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: "echo 0123456789abcdef; echo more >&2", MaxOutput: &limit}, nil))
+	assert.Equal(t, "0123456789", out.String())
+
+	out.Reset()
+	unlimited := int64(0)
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: "echo 0123456789abcdef", MaxOutput: &unlimited}, nil))
+	assert.Equal(t, "0123456789abcdef\n", out.String())
+}
+
+func TestRunHook_PassEnv(t *testing.T) {
+	resetAllGlobals(t)
+	t.Setenv("HOOK_TEST_TOKEN", "secret")
+	t.Setenv("HOOK_TEST_REGION", "eu-west-1")
+	t.Setenv("HOOK_TEST_OTHER", "other")
+	var out strings.Builder
+	hookOut = &out
+	run := `echo "${HOOK_TEST_TOKEN:-unset} ${HOOK_TEST_REGION:-unset} ${HOOK_TEST_OTHER:-unset} $CHECK_IMAGE_VERDICT"`
+	env := []string{"CHECK_IMAGE_VERDICT=passed"}
+
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: run}, env))
+	assert.Equal(t, "secret eu-west-1 other passed\n", out.String(), "the whole environment is passed by default")
+
+	out.Reset()
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: run, PassEnv: []string{"HOOK_TEST_TOKEN"}}, env))
+	assert.Equal(t, "secret unset unset passed\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: run, PassEnv: []string{"HOOK_TEST_R*"}}, env))
+	assert.Equal(t, "unset eu-west-1 unset passed\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: run + "; ls / > /dev/null", PassEnv: []string{}}, env))
+	assert.Equal(t, "unset unset unset passed\n", out.String(), "PATH is always passed")
+}
+
+func TestHookEnviron(t *testing.T) {
+	environ := []string{"PATH=/bin", "HOME=/root", "AWS_REGION=eu-west-1", "AWS_PROFILE=ci", "TOKEN=x"}
+	assert.Equal(t, environ, hookEnviron(environ, nil))
+	assert.Equal(t, []string{"PATH=/bin"}, hookEnviron(environ, []string{}))
+	assert.Equal(t, []string{"PATH=/bin", "AWS_REGION=eu-west-1", "AWS_PROFILE=ci", "TOKEN=x"}, hookEnviron(environ, []string{"AWS_*", "TOKEN"}))
+}
+
+func TestRunHook_IsolateWorkdir(t *testing.T) {
+	resetAllGlobals(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("existing", nil, 0600))
+	var out strings.Builder
+	hookOut = &out
+
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: "pwd; ls -A; touch created", IsolateWorkdir: true}, nil))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1, "the working directory is empty")
+	assert.NotEqual(t, dir, lines[0])
+	assert.NoDirExists(t, lines[0], "the working directory is removed")
+	assert.NoFileExists(t, filepath.Join(dir, "created"))
+
+	out.Reset()
+	require.NoError(t, runHook(context.Background(), hookConfig{Run: "ls"}, nil))
+	assert.Equal(t, "existing\n", out.String())
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/imagerefs"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var fromSBOM string
+var fromLockfile string
+
+// imageSource is a file the all command extracts the images to validate
+// from, instead of an image argument.
+type imageSource struct {
+	flag    string
+	path    string
+	extract func([]byte) ([]string, error)
+}
+
+// selectedImageSource returns the --from-sbom or --from-lockfile source, nil
+// when neither is set.
+func selectedImageSource() *imageSource {
+	switch {
+	case fromSBOM != "":
+		return &imageSource{flag: "from-sbom", path: fromSBOM, extract: imagerefs.FromSBOM}
+	case fromLockfile != "":
+		return &imageSource{flag: "from-lockfile", path: fromLockfile, extract: imagerefs.FromLockfile}
+	}
+	return nil
+}
+
+// validateImageSources rejects combining --from-sbom or --from-lockfile with
+// each other or with another way of naming the images.
+func validateImageSources(imageName string) error {
+	source := selectedImageSource()
+	switch {
+	case source == nil:
+		return nil
+	case fromSBOM != "" && fromLockfile != "":
+		return fmt.Errorf("--from-sbom and --from-lockfile are mutually exclusive")
+	case imageName != "":
+		return fmt.Errorf("--%s cannot be combined with an image argument", source.flag)
+	case imageTemplate != "":
+		return fmt.Errorf("--%s cannot be combined with --image-template", source.flag)
+	}
+	return nil
+}
+
+// runAllFromSource validates every image referenced by the --from-sbom or
+// --from-lockfile file like a bulk run.
+func runAllFromSource(cmd *cobra.Command, source *imageSource) error {
+	if source.path == "-" {
+		if err := validateBulkStdin(); err != nil {
+			return err
+		}
+	}
+	data, err := fileutil.ReadFileOrStdin(source.path)
+	if err != nil {
+		return fmt.Errorf("error reading --%s: %w", source.flag, err)
+	}
+	images, err := source.extract(data)
+	if err != nil {
+		return fmt.Errorf("error reading --%s: %w", source.flag, err)
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no image references were found in %s", source.path)
+	}
+	log.WithFields(log.Fields{"images": len(images), "source": source.path}).Info("Validating images referenced by " + source.flag)
+
+	bulk, err := validateBulkImages(cmd, images)
+	if err != nil {
+		return err
+	}
+	return renderBulkImages(bulk)
+}
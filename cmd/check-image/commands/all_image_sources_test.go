@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImageSources(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		sbom      string
+		lockfile  string
+		template  string
+		wantError string
+	}{
+		{name: "no source", image: "nginx:latest"},
+		{name: "sbom", sbom: "sbom.json"},
+		{name: "lockfile", lockfile: "helmfile.lock"},
+		{name: "both sources", sbom: "sbom.json", lockfile: "helmfile.lock", wantError: "--from-sbom and --from-lockfile are mutually exclusive"},
+		{name: "source and image", image: "nginx:latest", sbom: "sbom.json", wantError: "--from-sbom cannot be combined with an image argument"},
+		{name: "source and template", lockfile: "helmfile.lock", template: "ghcr.io/org/{service}", wantError: "--from-lockfile cannot be combined with --image-template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			fromSBOM, fromLockfile, imageTemplate = tt.sbom, tt.lockfile, tt.template
+
+			err := validateImageSources(tt.image)
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantError)
+		})
+	}
+}
+
+func TestRunAll_FromSBOM(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+	_, err := imageutil.CopyImage(ctx, createTestImage(t, testImageOptions{user: "root", created: time.Now()}), registry+"/org/api:1.0")
+	require.NoError(t, err)
+	_, err = imageutil.CopyImage(ctx, createTestImage(t, testImageOptions{user: "1000", created: time.Now()}), registry+"/org/web:1.0")
+	require.NoError(t, err)
+
+	sbom := fmt.Sprintf(`{
+		"bomFormat": "CycloneDX",
+		"components": [
+			{"type": "container", "name": "%[1]s/org/api", "version": "1.0"},
+			{"type": "library", "name": "openssl", "purl": "pkg:deb/debian/openssl@3.0.13"},
+			{"type": "container", "name": "web", "purl": "pkg:docker/org/web@1.0?repository_url=%[1]s"}
+		]
+	}`, registry)
+	fromSBOM = filepath.Join(t.TempDir(), "sbom.json")
+	require.NoError(t, os.WriteFile(fromSBOM, []byte(sbom), 0600))
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, ""))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Images, 2)
+	assert.Equal(t, registry+"/org/api:1.0", result.Images[0].Image)
+	assert.False(t, result.Images[0].Passed)
+	assert.Equal(t, registry+"/org/web:1.0", result.Images[1].Image)
+	assert.True(t, result.Images[1].Passed)
+	assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1}, result.Summary)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_FromLockfile_GroupByRepository(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	groupBy = groupByRepository
+
+	registry := newTestRegistry(t)
+	_, err := imageutil.CopyImage(context.Background(), createTestImage(t, testImageOptions{user: "1000", created: time.Now()}), registry+"/charts/app:1.2.0")
+	require.NoError(t, err)
+
+	lock := strings.Join([]string{
+		"dependencies:",
+		"- name: app",
+		"  repository: oci://" + registry + "/charts",
+		"  version: 1.2.0",
+		"- name: ingress-nginx",
+		"  repository: https://kubernetes.github.io/ingress-nginx",
+		"  version: 4.10.0",
+	}, "\n")
+	fromLockfile = filepath.Join(t.TempDir(), "helmfile.lock")
+	require.NoError(t, os.WriteFile(fromLockfile, []byte(lock), 0600))
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, ""))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Repositories, 1)
+	assert.Equal(t, registry+"/charts/app", result.Repositories[0].Repository)
+	assert.True(t, result.Passed)
+}
+
+func TestRunAll_FromSource_Errors(t *testing.T) {
+	t.Run("no references", func(t *testing.T) {
+		resetAllGlobals(t)
+		fromSBOM = filepath.Join(t.TempDir(), "sbom.json")
+		require.NoError(t, os.WriteFile(fromSBOM, []byte(`{"spdxVersion": "SPDX-2.3", "packages": []}`), 0600))
+
+		err := runAll(allCmd, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no image references were found in "+fromSBOM)
+	})
+
+	t.Run("unrecognized format", func(t *testing.T) {
+		resetAllGlobals(t)
+		fromLockfile = filepath.Join(t.TempDir(), "go.sum")
+		require.NoError(t, os.WriteFile(fromLockfile, []byte("version: 1\n"), 0600))
+
+		err := runAll(allCmd, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading --from-lockfile: unrecognized lockfile format")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		resetAllGlobals(t)
+		fromSBOM = filepath.Join(t.TempDir(), "missing.json")
+
+		err := runAll(allCmd, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading --from-sbom")
+	})
+}
@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/drift"
+	"github.com/jarfernandez/check-image/internal/entropy"
+	entrypointpolicy "github.com/jarfernandez/check-image/internal/entrypoint"
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/provenance"
+	"github.com/jarfernandez/check-image/internal/registry"
+	"github.com/jarfernandez/check-image/internal/secrets"
+	"github.com/jarfernandez/check-image/internal/user"
+)
+
+// inlinePolicy is a policy value of a config file, with the policy type it
+// must decode into.
+type inlinePolicy struct {
+	path   string
+	value  any
+	policy func() any
+}
+
+// validateInlinePolicies checks every inline policy of cfg against the schema
+// of its policy type, and then with the policy's own validation, so that a
+// mistake is reported when the config is loaded rather than when the check
+// runs. Errors name the config path of the offending key. Policies given as
+// file paths are left to their loaders.
+func validateInlinePolicies(cfg *allConfig) error {
+	for _, p := range configInlinePolicies(cfg) {
+		switch v := p.value.(type) {
+		case nil, string:
+			continue
+		case map[string]any:
+			if err := validateInlinePolicy(p.path, v, p.policy()); err != nil {
+				return fmt.Errorf("invalid inline policy: %w", err)
+			}
+		default:
+			return fmt.Errorf("invalid inline policy: %s: must be either a string (file path) or an object (inline policy), got %s", p.path, schemaKind(v))
+		}
+	}
+	return nil
+}
+
+func configInlinePolicies(cfg *allConfig) []inlinePolicy {
+	c := cfg.Checks
+	var policies []inlinePolicy
+	add := func(check, key string, value any, policy func() any) {
+		policies = append(policies, inlinePolicy{path: "checks." + check + "." + key, value: value, policy: policy})
+	}
+	if c.Registry != nil {
+		add(checkRegistry, "registry-policy", c.Registry.RegistryPolicy, func() any { return &registry.Policy{} })
+	}
+	if c.Secrets != nil {
+		add(checkSecrets, "secrets-policy", c.Secrets.SecretsPolicy, func() any { return &secrets.Policy{} })
+	}
+	if c.Labels != nil {
+		add(checkLabels, "labels-policy", c.Labels.LabelsPolicy, func() any { return &labels.Policy{} })
+	}
+	if c.Entrypoint != nil {
+		add(checkEntrypoint, "entrypoint-policy", c.Entrypoint.EntrypointPolicy, func() any { return &entrypointpolicy.Policy{} })
+	}
+	if c.User != nil {
+		add(checkUser, "user-policy", c.User.UserPolicy, func() any { return &user.Policy{} })
+	}
+	if c.Provenance != nil {
+		add(checkProvenance, "provenance-policy", c.Provenance.ProvenancePolicy, func() any { return &provenance.Policy{} })
+	}
+	if c.Drift != nil {
+		add(checkDrift, "golden-spec", c.Drift.GoldenSpec, func() any { return &drift.Spec{} })
+	}
+	if c.Entropy != nil {
+		add(checkEntropy, "entropy-policy", c.Entropy.EntropyPolicy, func() any { return &entropy.Policy{} })
+	}
+	return policies
+}
+
+// validateInlinePolicy checks value against the schema of policy, a pointer
+// to a zero policy, and then decodes it into policy and runs its Validate
+// method, if it has one.
+func validateInlinePolicy(path string, value map[string]any, policy any) error {
+	if err := checkSchema(path, value, reflect.TypeOf(policy)); err != nil {
+		return err
+	}
+	v, ok := policy.(interface{ Validate() error })
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// checkSchema reports the first part of v, decoded from JSON or YAML, that
+// does not fit type t: an unknown key or a value of the wrong type, named by
+// its path from the config root. Null values are accepted as unset.
+func checkSchema(path string, v any, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return schemaTypeError(path, "an object", v)
+		}
+		fields := schemaFields(t)
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			ft, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("%s: unknown key, valid keys are: %s", schemaPath(path, key), strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+			}
+			if err := checkSchema(schemaPath(path, key), m[key], ft); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return schemaTypeError(path, "an object", v)
+		}
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			if err := checkSchema(schemaPath(path, key), m[key], t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		items, ok := v.([]any)
+		if !ok {
+			return schemaTypeError(path, "a list", v)
+		}
+		for i, item := range items {
+			if err := checkSchema(fmt.Sprintf("%s[%d]", path, i), item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		// YAML decodes unquoted dates as timestamps, which decode into strings
+		// as written.
+		switch v.(type) {
+		case string, time.Time:
+		default:
+			return schemaTypeError(path, "a string", v)
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return schemaTypeError(path, "a boolean", v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isNonNegativeInteger(v) {
+			return schemaTypeError(path, "a non-negative integer", v)
+		}
+	case reflect.Float32, reflect.Float64:
+		if schemaKind(v) != "a number" {
+			return schemaTypeError(path, "a number", v)
+		}
+	}
+	return nil
+}
+
+// schemaPath returns the path of key in the object at path, which is empty
+// for the config root.
+func schemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// schemaFields maps the JSON names of the exported fields of struct type t to
+// their types.
+func schemaFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for f := range t.Fields() {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			// Fields of embedded structs are promoted, as in encoding/json.
+			maps.Copy(fields, schemaFields(f.Type))
+			continue
+		}
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// isNonNegativeInteger reports whether v is a whole number of at least zero,
+// as decoded from JSON (float64) or YAML (int, int64, uint64).
+func isNonNegativeInteger(v any) bool {
+	switch n := v.(type) {
+	case float64:
+		return n >= 0 && n == math.Trunc(n)
+	case int:
+		return n >= 0
+	case int64:
+		return n >= 0
+	case uint64:
+		return true
+	}
+	return false
+}
+
+func schemaTypeError(path, want string, v any) error {
+	return fmt.Errorf("%s: expected %s, got %s", path, want, schemaKind(v))
+}
+
+// schemaKind names the type of a value decoded from JSON or YAML.
+func schemaKind(v any) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64, int, int64, uint64:
+		return "a number"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllConfig_InlinePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		path    string
+		wantErr string
+	}{
+		{
+			name: "valid inline policies",
+			data: `{"checks": {
+				"registry": {"registry-policy": {"trusted-registries": ["docker.io"]}},
+				"secrets": {"secrets-policy": {"check-env-vars": true, "excluded-paths": ["/usr/**"]}},
+				"labels": {"labels-policy": {"required-labels": [{"name": "maintainer"}]}},
+				"user": {"user-policy": {"min-uid": 1000, "max-uid": 65535}},
+				"drift": {"golden-spec": {"user": "1000", "labels": {"team": "platform"}}}
+			}}`,
+			path: "config.json",
+		},
+		{
+			name: "valid YAML integers",
+			data: "checks:\n  user:\n    user-policy:\n      min-uid: 1000\n",
+			path: "config.yaml",
+		},
+		{
+			name: "valid YAML date as a string",
+			data: "checks:\n  drift:\n    golden-spec:\n      labels:\n        release: 2026-01-01\n",
+			path: "config.yaml",
+		},
+		{
+			name: "policy file paths are not validated",
+			data: `{"checks": {"registry": {"registry-policy": "missing.yaml"}}}`,
+			path: "config.json",
+		},
+		{
+			name:    "unknown key",
+			data:    `{"checks": {"registry": {"registry-policy": {"trusted-registry": ["docker.io"]}}}}`,
+			path:    "config.json",
+			wantErr: "checks.registry.registry-policy.trusted-registry: unknown key, valid keys are: excluded-registries, trusted-registries",
+		},
+		{
+			name:    "wrong list item type",
+			data:    `{"checks": {"registry": {"registry-policy": {"trusted-registries": ["docker.io", 5]}}}}`,
+			path:    "config.json",
+			wantErr: "checks.registry.registry-policy.trusted-registries[1]: expected a string, got a number",
+		},
+		{
+			name:    "list instead of boolean",
+			data:    "checks:\n  secrets:\n    secrets-policy:\n      check-files: [yes]\n",
+			path:    "config.yaml",
+			wantErr: "checks.secrets.secrets-policy.check-files: expected a boolean, got a list",
+		},
+		{
+			name:    "unknown key in a list item",
+			data:    `{"checks": {"labels": {"labels-policy": {"required-labels": [{"name": "a"}, {"nmae": "b"}]}}}}`,
+			path:    "config.json",
+			wantErr: "checks.labels.labels-policy.required-labels[1].nmae: unknown key",
+		},
+		{
+			name:    "negative integer",
+			data:    "checks:\n  user:\n    user-policy:\n      min-uid: -1\n",
+			path:    "config.yaml",
+			wantErr: "checks.user.user-policy.min-uid: expected a non-negative integer, got a number",
+		},
+		{
+			name: "valid YAML numbers",
+			data: "checks:\n  entropy:\n    entropy-policy:\n      max-entropy: 7\n      min-size: 50\n",
+			path: "config.yaml",
+		},
+		{
+			name:    "string instead of number",
+			data:    `{"checks": {"entropy": {"entropy-policy": {"max-entropy": "7.5"}}}}`,
+			path:    "config.json",
+			wantErr: "checks.entropy.entropy-policy.max-entropy: expected a number, got a string",
+		},
+		{
+			name:    "policy validation",
+			data:    `{"checks": {"registry": {"registry-policy": {"trusted-registries": ["a.io"], "excluded-registries": ["b.io"]}}}}`,
+			path:    "config.json",
+			wantErr: "checks.registry.registry-policy: policy must specify either trusted-registries or excluded-registries, not both",
+		},
+		{
+			name:    "invalid allowed hash",
+			data:    `{"checks": {"secrets": {"secrets-policy": {"allowed-hashes": ["abc"]}}}}`,
+			path:    "config.json",
+			wantErr: `checks.secrets.secrets-policy: invalid allowed hash "abc"`,
+		},
+		{
+			name:    "neither path nor object",
+			data:    `{"checks": {"labels": {"labels-policy": ["maintainer"]}}}`,
+			path:    "config.json",
+			wantErr: "checks.labels.labels-policy: must be either a string (file path) or an object (inline policy), got a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseAllConfig([]byte(tt.data), tt.path)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid inline policy")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseAllConfig_SampleConfigs(t *testing.T) {
+	for _, name := range []string{"config.json", "config.yaml", "config-inline.json", "config-inline.yaml", "required-config.json", "required-config.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "..", "..", "config", name))
+			require.NoError(t, err)
+			_, err = parseAllConfig(data, name)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadUnvalidatedAllConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"checks": {"registry": {"registry-policy": {"trusted-registries": ["a.io"], "excluded-registries": ["b.io"]}}}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	_, err := loadAllConfig(path)
+	require.Error(t, err)
+
+	cfg, err := loadUnvalidatedAllConfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Checks.Registry.RegistryPolicy)
+}
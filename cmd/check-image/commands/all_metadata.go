@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"github.com/jarfernandez/check-image/internal/output"
+	ver "github.com/jarfernandez/check-image/internal/version"
+)
+
+// reportMetadata identifies the check-image build, the config file, and the
+// policy files of the selected checks that a report was produced with, so
+// that cached results and baselines can be invalidated when any of them
+// changes.
+func reportMetadata(checks []checkDef, p checkParams) *output.ReportMetadata {
+	info := ver.GetBuildInfo()
+	md := &output.ReportMetadata{Version: info.Version, Commit: info.Commit}
+	if path, err := configSource(); err == nil && path != "" {
+		md.ConfigHash = policyFileDigest(path)
+	}
+
+	for _, c := range checks {
+		flag, path := checkPolicyFile(c.name, p)
+		if path == "" {
+			continue
+		}
+		if md.PolicyFiles == nil {
+			md.PolicyFiles = make(map[string]string)
+		}
+		md.PolicyFiles[flag] = policyFileDigest(path)
+	}
+	return md
+}
+
+// checkPolicyFile returns the flag and path of the policy file a check reads,
+// or an empty path when it reads none.
+func checkPolicyFile(check string, p checkParams) (string, string) {
+	switch check {
+	case checkRegistry:
+		return "registry-policy", p.registryPolicy
+	case checkSecrets:
+		return "secrets-policy", p.secretsPolicy
+	case checkLabels:
+		return "labels-policy", p.labelsPolicy
+	case checkEntrypoint:
+		return "entrypoint-policy", p.entrypointPolicy
+	case checkUser:
+		return "user-policy", p.userPolicy
+	case checkProvenance:
+		return "provenance-policy", p.provenancePolicy
+	case checkDrift:
+		return "golden-spec", p.goldenSpec
+	case checkEntropy:
+		return "entropy-policy", p.entropyPolicy
+	}
+	return "", ""
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	ver "github.com/jarfernandez/check-image/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAll_ReportMetadata(t *testing.T) {
+	resetAllGlobals(t)
+	userPolicy = writeRequiredConfig(t, "min-uid: 1000\n")
+	configFile = writeRequiredConfig(t, "checks:\n  age:\n    max-age: 30\n  user: {}\n")
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, result.PolicyHash)
+	require.NotNil(t, result.Metadata)
+	assert.Equal(t, ver.GetBuildInfo().Version, result.Metadata.Version)
+	assert.Equal(t, policyFileDigest(configFile), result.Metadata.ConfigHash)
+	assert.Equal(t, map[string]string{"user-policy": "sha256:d9acc1bc47fbefb567a7f1eda7d5f505140b88e14517c323ec530c1d6dc93db1"}, result.Metadata.PolicyFiles)
+}
+
+func TestReportMetadata_WithoutConfig(t *testing.T) {
+	resetAllGlobals(t)
+	checks := []checkDef{{name: checkAge}, {name: checkLabels}}
+
+	md := reportMetadata(checks, checkParams{labelsPolicy: "-", userPolicy: "unused.yaml"})
+	assert.Empty(t, md.ConfigHash)
+	assert.Equal(t, map[string]string{"labels-policy": stdinSource}, md.PolicyFiles, "only the policies of selected checks are recorded")
+}
@@ -3,10 +3,16 @@ package commands
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"strings"
+	"time"
 
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/telemetry"
 	"github.com/jarfernandez/check-image/internal/user"
+	ver "github.com/jarfernandez/check-image/internal/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -15,9 +21,11 @@ var configFile string
 var skipChecks string
 var includeChecks string
 var failFast bool
+var requiredConfig string
+var strictConfig bool
 
 var allCmd = &cobra.Command{
-	Use:   "all image",
+	Use:   "all [image]",
 	Short: "Run all validation checks on a container image",
 	Long: `Run all validation checks on a container image at once.
 
@@ -26,6 +34,35 @@ Use --config to specify which checks to run and their parameters.
 Use --include to run only specific checks.
 Use --skip to skip specific checks.
 Use --fail-fast to stop on the first check failure.
+Use --sign-results to sign the JSON report so later pipeline stages can check it
+with the verify-report command.
+Use --required-config to enforce a centrally managed set of checks that cannot
+be skipped locally; attempts to skip them are reported as policy violations.
+Use --annotate-registry to push the outcome next to a registry image as an OCI
+referrer artifact of type ` + validationArtifactType + `, annotated
+with the result and a hash of the policy, so other tools can discover it.
+Use --report-status to post the verdict as a commit status of the commit a
+GitHub Actions or GitLab CI pipeline is building, linking to the CI run or to
+--report-status-url.
+
+Use "-" as the image to read the images to validate from stdin, one per line
+(whitespace-separated lists are accepted too, and duplicates are validated
+once). Each image is judged on its own checks, and the output aggregates the
+results of all images. Flags that read stdin cannot be combined with it.
+Use --group-by repository to aggregate the results per repository, reporting
+the worst outcome of its tags with a per-image breakdown.
+
+Use --image-template with --service-list instead of an image to validate the
+image of every service of a monorepo, such as ghcr.io/org/{service}:{tag} with
+--image-tag v1.2.0; the results are keyed by service name.
+
+Use --from-sbom with a CycloneDX or SPDX JSON SBOM, or --from-lockfile with a
+helmfile.lock, Chart.lock, imgpkg ImagesLock, or kbld lock file, instead of an
+image to validate every image the file references, like a list read from stdin.
+
+When many images are validated, a progress line with the estimated time
+remaining and a final summary table are printed to stderr, unless
+--no-progress is set.
 
 Note: --include and --skip are mutually exclusive.
 
@@ -40,20 +77,48 @@ Precedence rules:
   3. --include overrides config file check selection (runs only specified checks)
   4. CLI flags override config file values
   5. --include and --skip always take precedence over the config file
+  6. --required-config checks always run and its values override both CLI
+     flags and the config file
+
+` + listInputDoc + `
 
 ` + imageArgFormatsDoc,
 	Example: `  check-image all nginx:latest --include age,size,user --max-age 30 --max-size 200
   check-image all nginx:latest --skip registry,secrets,labels,platform
+  check-image all nginx:latest --skip @skip-checks.yaml
   check-image all nginx:latest --allowed-platforms linux/amd64,linux/arm64 --skip registry,labels
   check-image all nginx:latest --config config/config.json
   check-image all nginx:latest -c config/config.yaml --max-age 20 --skip secrets
   check-image all oci:/path/to/layout:1.0 --include age,size,user,ports,healthcheck
   check-image all oci-archive:/path/to/image.tar:latest --skip ports,registry,secrets,labels,platform
   check-image all nginx:latest --fail-fast --skip registry --config config/config.yaml --output json
-  cat config/config.json | check-image all nginx:latest --config -`,
-	Args: cobra.ExactArgs(1),
+  cat config/config.json | check-image all nginx:latest --config -
+  check-image all nginx:latest -c config/config.yaml --required-config https://policies.example.com/required.yaml
+  check-image all nginx:latest --required-config oci://ghcr.io/example/policies/required:v1
+  check-image all nginx:latest -c config/config.yaml -o json --sign-results key.pem > report.json
+  check-image all registry.example.com/app:1.0 -c config/config.yaml --annotate-registry
+  check-image all registry.example.com/app:1.0 -c config/config.yaml --report-status auto
+  kubectl get pods -o jsonpath='{range .items[*].spec.containers[*]}{.image}{"\n"}{end}' | check-image all - -c config/config.yaml
+  crane ls registry.example.com/app | sed 's|^|registry.example.com/app:|' | check-image all - -c config/config.yaml --group-by repository -o json
+  check-image all --image-template 'ghcr.io/org/{service}:{tag}' --service-list services.txt --image-tag v1.2.0 -c config/config.yaml
+  check-image all --from-sbom sbom.cdx.json -c config/config.yaml -o json
+  check-image all --from-lockfile helmfile.lock -c config/config.yaml --group-by repository`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := runAll(cmd, args[0]); err != nil {
+		var imageName string
+		if len(args) > 0 {
+			imageName = args[0]
+		}
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		err := withStatusReport(ctx, imageName, func() error {
+			return withHooks(ctx, imageName, func() error {
+				return withReportFile(func() error { return runAll(cmd, imageName) })
+			})
+		})
+		if err != nil {
 			return fmt.Errorf("check all operation failed: %w", err)
 		}
 
@@ -63,110 +128,255 @@ Precedence rules:
 
 func init() {
 	rootCmd.AddCommand(allCmd)
+	addAllCheckFlags(allCmd)
+	allCmd.Flags().BoolVar(&annotateRegistry, "annotate-registry", false, "Record the validation outcome in the registry as an OCI referrer of the image (optional)")
+	allCmd.Flags().StringVar(&groupBy, "group-by", "", "Aggregate the results of images read from stdin, --from-sbom, or --from-lockfile per repository; the only value is repository (optional)")
+	allCmd.Flags().StringVar(&imageTemplate, "image-template", "", "Image reference with {service} and {tag} placeholders, validated for every service of --service-list instead of an image argument (optional)")
+	allCmd.Flags().StringVar(&serviceList, "service-list", "", "File listing the services to validate with --image-template, one per line, or - for stdin (optional)")
+	allCmd.Flags().StringVar(&imageTag, "image-tag", "", "Value of the {tag} placeholder of --image-template (optional)")
+	allCmd.Flags().StringVar(&fromSBOM, "from-sbom", "", "CycloneDX or SPDX JSON SBOM whose container images are validated instead of an image argument, or - for stdin (optional)")
+	allCmd.Flags().StringVar(&fromLockfile, "from-lockfile", "", "helmfile.lock, Chart.lock, imgpkg ImagesLock, or kbld lock file whose OCI references are validated instead of an image argument, or - for stdin (optional)")
+	allCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Do not print the progress line and summary table to stderr when images are read from stdin (optional)")
+	addReportStatusFlags(allCmd)
+}
 
-	allCmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file (JSON or YAML) (optional)")
-	allCmd.Flags().StringVar(&skipChecks, "skip", "", "Comma-separated list of checks to skip (age, size, ports, registry, secrets, healthcheck, labels, entrypoint, platform, user) (optional)")
-	allCmd.Flags().StringVar(&includeChecks, "include", "", "Comma-separated list of checks to run (age, size, ports, registry, secrets, healthcheck, labels, entrypoint, platform, user) (optional)")
-	allCmd.Flags().UintVarP(&maxAge, "max-age", "a", defaultMaxAgeDays, "Maximum age in days (optional)")
-	allCmd.Flags().UintVarP(&maxSize, "max-size", "m", defaultMaxSizeMB, "Maximum size in megabytes (optional)")
-	allCmd.Flags().UintVarP(&maxLayers, "max-layers", "y", defaultMaxLayerCount, "Maximum number of layers (optional)")
-	allCmd.Flags().StringVarP(&allowedPorts, "allowed-ports", "p", "", "Comma-separated list of allowed ports or @<file> with JSON or YAML array (optional)")
-	allCmd.Flags().StringVarP(&registryPolicy, "registry-policy", "r", "", "Registry policy file (JSON or YAML)")
-	allCmd.Flags().StringVarP(&secretsPolicy, "secrets-policy", "s", "", "Secrets policy file (JSON or YAML) (optional)")
-	allCmd.Flags().BoolVar(&skipEnvVars, "skip-env-vars", false, "Skip environment variable checks in secrets detection (optional)")
-	allCmd.Flags().BoolVar(&skipFiles, "skip-files", false, "Skip file system checks in secrets detection (optional)")
-	allCmd.Flags().StringVar(&labelsPolicy, "labels-policy", "", "Labels policy file (JSON or YAML)")
-	allCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop on first check failure (optional)")
-	allCmd.Flags().BoolVar(&allowShellForm, "allow-shell-form", false, "Allow shell form for entrypoint or cmd (optional)")
-	allCmd.Flags().StringVar(&allowedPlatforms, "allowed-platforms", "", "Comma-separated list of allowed platforms or @<file> with JSON or YAML array")
-	allCmd.Flags().StringVar(&userPolicy, "user-policy", "", "User policy file (JSON or YAML) (optional)")
-	allCmd.Flags().UintVar(&userMinUID, "min-uid", 0, "Minimum allowed UID (optional)")
-	allCmd.Flags().UintVar(&userMaxUID, "max-uid", 0, "Maximum allowed UID (optional)")
-	allCmd.Flags().StringVar(&blockedUsers, "blocked-users", "", "Comma-separated list of blocked usernames (optional)")
-	allCmd.Flags().BoolVar(&requireNumeric, "require-numeric", false, "Require user to be a numeric UID (optional)")
+// addAllCheckFlags registers the check selection, check parameter, and report
+// flags of the all command on cmd. Commands that run the all-checks
+// validation, such as promote, share the same flags and variables.
+func addAllCheckFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file (JSON or YAML) (optional)")
+	cmd.Flags().BoolVar(&strictConfig, "strict-config", false, "Reject unknown keys in the configuration files instead of ignoring them (optional)")
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "Replace the registry and repository names of the image with hashed pseudonyms in the output, keeping tags and digests (optional)")
+	cmd.Flags().StringVar(&policyDir, "policy-dir", "", "Directory of named policy profiles (<name>.yaml, .yml, or .json configuration files); the default profile is used without --policy (optional)")
+	cmd.Flags().StringVar(&policyProfile, "policy", "", "Name of the policy profile of --policy-dir to validate with (optional)")
+	cmd.Flags().StringVar(&policyLabel, "policy-label", "", "Image label naming the policy profile of --policy-dir to validate the image with, e.g. policy-profile; images without it use --policy (optional)")
+	cmd.Flags().StringVar(&allowedPolicies, "allowed-policies", "", "Comma-separated list of the policy profiles --policy-label may select, or @<file> (required with --policy-label)")
+	cmd.Flags().StringVar(&skipChecks, "skip", "", "Comma-separated list of checks to skip (age, size, ports, registry, secrets, healthcheck, labels, entrypoint, platform, user, provenance, lazy-pull, drift, entropy, deprecation, architecture, minimal, smoke) or @<file> (optional)")
+	cmd.Flags().StringVar(&includeChecks, "include", "", "Comma-separated list of checks to run (age, size, ports, registry, secrets, healthcheck, labels, entrypoint, platform, user, provenance, lazy-pull, drift, entropy, deprecation, architecture, minimal, smoke) or @<file> (optional)")
+	cmd.Flags().UintVarP(&maxAge, "max-age", "a", defaultMaxAgeDays, "Maximum age in days (optional)")
+	addAgeBoundFlags(cmd)
+	cmd.Flags().UintVarP(&maxSize, "max-size", "m", defaultMaxSizeMB, "Maximum size in megabytes (optional)")
+	cmd.Flags().UintVarP(&maxLayers, "max-layers", "y", defaultMaxLayerCount, "Maximum number of layers (optional)")
+	cmd.Flags().UintVar(&maxTotalSize, "max-total-size", 0, "Maximum total size in megabytes of all platforms of a multi-platform image, 0 to disable (optional)")
+	addLayerBaseFlags(cmd)
+	cmd.Flags().StringVarP(&allowedPorts, "allowed-ports", "p", "", "Comma-separated list of allowed ports or @<file> with JSON or YAML array (optional)")
+	addPortLimitFlags(cmd)
+	cmd.Flags().StringVarP(&registryPolicy, "registry-policy", "r", "", "Registry policy file (JSON or YAML)")
+	cmd.Flags().StringVarP(&secretsPolicy, "secrets-policy", "s", "", "Secrets policy file (JSON or YAML) (optional)")
+	cmd.Flags().BoolVar(&skipEnvVars, "skip-env-vars", false, "Skip environment variable checks in secrets detection (optional)")
+	cmd.Flags().BoolVar(&skipFiles, "skip-files", false, "Skip file system checks in secrets detection (optional)")
+	cmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "Fail the secrets check only on findings of this severity or higher: low, medium, high, critical (optional)")
+	cmd.Flags().StringVar(&labelsPolicy, "labels-policy", "", "Labels policy file (JSON or YAML)")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop on first check failure (optional)")
+	cmd.Flags().StringVar(&showSections, "show", showAll, "Check sections printed in text mode: all, or failed to hide passing and skipped checks; JSON and CSV output stay complete (optional)")
+	cmd.Flags().BoolVar(&earlyExitOnMetadataFailure, "early-exit-on-metadata-failure", false, "Skip the layer checks (secrets, entrypoint) when a metadata check fails (optional)")
+	cmd.Flags().StringVar(&signResults, "sign-results", "", "Sign the JSON report with this PEM private key and write a detached JWS signature (requires --output json) (optional)")
+	cmd.Flags().StringVar(&signatureOutput, "signature-output", defaultSignatureFile, "File to write the detached report signature to when --sign-results is set (optional)")
+	addReportFileFlags(cmd)
+	cmd.Flags().StringVar(&exceptionsFile, "exceptions", "", "Exceptions file (JSON or YAML) granting image digests time-boxed exemptions from checks (optional)")
+	cmd.Flags().BoolVar(&reproducible, "reproducible", false, "Make the JSON report byte-identical across runs on the same image and policy: run times are zeroed, lists sorted, and environment-specific fields omitted (optional)")
+	cmd.Flags().BoolVar(&effectiveConfig, "effective-config", false, "Add the resolved parameters of every executed check to the JSON report (optional)")
+	cmd.Flags().StringVar(&auditLog, "audit-log", "", "Append a record of every validation to this JSON lines file, or send it to syslog (syslog, syslog://host:port, syslog+tcp://host:port) (optional)")
+	cmd.Flags().StringVar(&evidenceDir, "evidence-dir", "", "Directory to store the evidence of the file findings of the secrets and entropy checks in: tar header, layer history entry, and content digest of each matched file (optional)")
+	cmd.Flags().UintVar(&evidenceContentBytes, "evidence-content-bytes", 0, "Number of leading bytes of the content of each matched file to store with --evidence-dir, 0 for none (optional)")
+	cmd.Flags().StringVar(&requiredConfig, "required-config", "", "Locked configuration whose checks cannot be skipped: local file, https:// URL, or oci:// artifact reference (optional)")
+	cmd.Flags().BoolVar(&allowShellForm, "allow-shell-form", false, "Allow shell form for entrypoint or cmd (optional)")
+	cmd.Flags().StringVar(&entrypointPolicy, "entrypoint-policy", "", "Entrypoint policy file (JSON or YAML) with rules for the startup command arguments (optional)")
+	cmd.Flags().StringVar(&allowedPlatforms, "allowed-platforms", "", "Comma-separated list of allowed platforms or @<file> with JSON or YAML array")
+	cmd.Flags().StringVar(&userPolicy, "user-policy", "", "User policy file (JSON or YAML) (optional)")
+	cmd.Flags().UintVar(&userMinUID, "min-uid", 0, "Minimum allowed UID (optional)")
+	cmd.Flags().UintVar(&userMaxUID, "max-uid", 0, "Maximum allowed UID (optional)")
+	cmd.Flags().StringVar(&blockedUsers, "blocked-users", "", "Comma-separated list of blocked usernames or @<file> (optional)")
+	cmd.Flags().BoolVar(&requireNumeric, "require-numeric", false, "Require user to be a numeric UID (optional)")
+	cmd.Flags().StringVar(&provenancePolicy, "provenance-policy", "", "Provenance policy file (JSON or YAML); enables the provenance check (optional)")
+	cmd.Flags().StringVar(&lazyPullFormats, "lazy-pull-formats", "", "Comma-separated list of accepted lazy-pull formats (estargz, nydus) or @<file>; enables the lazy-pull check (optional)")
+	cmd.Flags().StringVar(&goldenSpec, "golden-spec", "", "Golden spec file (JSON or YAML) recorded with drift --record; enables the drift check (optional)")
+	cmd.Flags().StringVar(&entropyPolicy, "entropy-policy", "", "Entropy policy file (JSON or YAML); enables the entropy check (optional)")
+	cmd.Flags().BoolVar(&checkDeprecationFlag, "check-deprecation", false, "Check whether Docker Hub official images are deprecated (queries hub.docker.com); enables the deprecation check (optional)")
+	cmd.Flags().BoolVar(&checkArchitectureFlag, "check-architecture", false, "Check that sampled ELF binaries match the declared architecture; enables the architecture check (optional)")
+	cmd.Flags().UintVar(&maxBinaries, "max-binaries", defaultMaxBinaries, "Maximum number of ELF binaries the architecture check inspects, 0 for all (optional)")
+	cmd.Flags().BoolVar(&checkMinimalFlag, "check-minimal", false, "Check that the image has no shell, package manager, or compiler and fits a small size budget; enables the minimal check (optional)")
+	cmd.Flags().UintVar(&minimalMaxSize, "minimal-max-size", defaultMinimalMaxSizeMB, "Size budget in megabytes of the minimal check (optional)")
+	cmd.Flags().StringVar(&minimalCriteria, "minimal-criteria", "", "Comma-separated list of criteria the minimal check evaluates (shell, package-manager, compiler, size) or @<file>, default all (optional)")
+	cmd.Flags().BoolVar(&checkSmokeFlag, "check-smoke", false, "Run the image in the local Docker daemon and check that it exits 0 or keeps running; enables the smoke check (optional)")
+	cmd.Flags().StringVar(&smokeCommand, "smoke-command", "", "Command the smoke check runs instead of the CMD of the image, split on whitespace (optional)")
+	cmd.Flags().DurationVar(&smokeTimeout, "smoke-timeout", defaultSmokeTimeout, "Time the container of the smoke check may take to exit (optional)")
+	cmd.Flags().DurationVar(&smokeHealthyFor, "smoke-healthy-for", 0, "Require the container of the smoke check to keep running, and stay healthy, for this long instead of exiting (optional)")
+	cmd.Flags().UintVar(&smokeMemory, "smoke-memory", defaultSmokeMemoryMB, "Memory limit of the container of the smoke check in megabytes, 0 for no limit (optional)")
+	cmd.Flags().UintVar(&smokePidsLimit, "smoke-pids-limit", defaultSmokePidsLimit, "Maximum number of processes of the container of the smoke check, 0 for no limit (optional)")
 }
 
 type checkDef struct {
 	name    string
 	enabled bool
 	run     func(context.Context, string) (*output.CheckResult, error)
-	render  func(*output.CheckResult)
+	render  func(io.Writer, *output.CheckResult)
 }
 
 // checkParams captures the flag values that buildCheckDefs needs, making the
 // data flow explicit instead of reading package-level globals in closures.
 type checkParams struct {
-	maxAge           uint
-	maxSize          uint
-	maxLayers        uint
-	allowedPorts     string
-	registryPolicy   string
-	secretsPolicy    string
-	skipEnvVars      bool
-	skipFiles        bool
-	labelsPolicy     string
-	allowShellForm   bool
-	allowedPlatforms string
-	userPolicy       string
-	userMinUID       uint
-	userMaxUID       uint
-	blockedUsers     string
-	requireNumeric   bool
+	maxAge            uint
+	ageNotBefore      string
+	ageNotAfter       string
+	maxSize           uint
+	maxLayers         uint
+	maxTotalSize      uint
+	ageWindow         *output.PolicyWindow
+	ageRules          []ageRule
+	sizeWindow        *output.PolicyWindow
+	countFromBase     bool
+	baseImage         string
+	baseLayers        string
+	allowedPorts      string
+	maxExposedPorts   uint
+	forbidPrivileged  bool
+	registryPolicy    string
+	secretsPolicy     string
+	skipEnvVars       bool
+	skipFiles         bool
+	failOnSeverity    string
+	labelsPolicy      string
+	allowShellForm    bool
+	entrypointPolicy  string
+	allowedPlatforms  string
+	userPolicy        string
+	userMinUID        uint
+	userMaxUID        uint
+	blockedUsers      string
+	requireNumeric    bool
+	provenancePolicy  string
+	lazyPullFormats   string
+	goldenSpec        string
+	entropyPolicy     string
+	checkDeprecation  bool
+	checkArchitecture bool
+	maxBinaries       uint
+	checkMinimal      bool
+	minimalMaxSize    uint
+	minimalCriteria   string
+	checkSmoke        bool
+	smokeCommand      string
+	smokeTimeout      time.Duration
+	smokeHealthyFor   time.Duration
+	smokeMemory       uint
+	smokePidsLimit    uint
+	// defaultChecks replaces the checks enabled without --config, from the
+	// defaults of the global config; nil for the built-in defaults.
+	defaultChecks map[string]bool
 }
 
 func currentCheckParams() checkParams {
 	return checkParams{
-		maxAge:           maxAge,
-		maxSize:          maxSize,
-		maxLayers:        maxLayers,
-		allowedPorts:     allowedPorts,
-		registryPolicy:   registryPolicy,
-		secretsPolicy:    secretsPolicy,
-		skipEnvVars:      skipEnvVars,
-		skipFiles:        skipFiles,
-		labelsPolicy:     labelsPolicy,
-		allowShellForm:   allowShellForm,
-		allowedPlatforms: allowedPlatforms,
-		userPolicy:       userPolicy,
-		userMinUID:       userMinUID,
-		userMaxUID:       userMaxUID,
-		blockedUsers:     blockedUsers,
-		requireNumeric:   requireNumeric,
+		maxAge:            maxAge,
+		ageNotBefore:      ageNotBefore,
+		ageNotAfter:       ageNotAfter,
+		maxSize:           maxSize,
+		maxLayers:         maxLayers,
+		maxTotalSize:      maxTotalSize,
+		ageWindow:         ageWindow,
+		ageRules:          ageRules,
+		sizeWindow:        sizeWindow,
+		countFromBase:     countFromBase,
+		baseImage:         baseImage,
+		baseLayers:        baseLayers,
+		allowedPorts:      allowedPorts,
+		maxExposedPorts:   maxExposedPorts,
+		forbidPrivileged:  forbidPrivilegedPorts,
+		registryPolicy:    registryPolicy,
+		secretsPolicy:     secretsPolicy,
+		skipEnvVars:       skipEnvVars,
+		skipFiles:         skipFiles,
+		failOnSeverity:    failOnSeverity,
+		labelsPolicy:      labelsPolicy,
+		allowShellForm:    allowShellForm,
+		entrypointPolicy:  entrypointPolicy,
+		allowedPlatforms:  allowedPlatforms,
+		userPolicy:        userPolicy,
+		userMinUID:        userMinUID,
+		userMaxUID:        userMaxUID,
+		blockedUsers:      blockedUsers,
+		requireNumeric:    requireNumeric,
+		provenancePolicy:  provenancePolicy,
+		lazyPullFormats:   lazyPullFormats,
+		goldenSpec:        goldenSpec,
+		entropyPolicy:     entropyPolicy,
+		checkDeprecation:  checkDeprecationFlag,
+		checkArchitecture: checkArchitectureFlag,
+		maxBinaries:       maxBinaries,
+		checkMinimal:      checkMinimalFlag,
+		minimalMaxSize:    minimalMaxSize,
+		minimalCriteria:   minimalCriteria,
+		checkSmoke:        checkSmokeFlag,
+		smokeCommand:      smokeCommand,
+		smokeTimeout:      smokeTimeout,
+		smokeHealthyFor:   smokeHealthyFor,
+		smokeMemory:       smokeMemory,
+		smokePidsLimit:    smokePidsLimit,
+		defaultChecks:     activeGlobalConfig.defaultChecks(),
 	}
 }
 
 // buildCheckDefs returns the full list of checks with their enabled state.
-// When cfg is nil every check is enabled; otherwise only checks present in the
-// config file are enabled. Short-circuit evaluation of || ensures cfg.Checks
-// fields are never accessed when cfg is nil.
+// When cfg is nil every check is enabled, except the opt-in provenance,
+// lazy-pull, drift, entropy, deprecation, architecture, minimal, and smoke
+// checks, which are only enabled when their policy flag (--check-deprecation,
+// --check-architecture, --check-minimal, and --check-smoke for the last four)
+// is given;
+// otherwise only checks present in the config file are enabled. When cfg is
+// nil, the defaults of the global config replace the default checks, and
+// policy flags still enable their opt-in checks.
 func buildCheckDefs(cfg *allConfig, p checkParams) []checkDef {
+	defs := checkDefs(cfg, p)
+	if cfg == nil && p.defaultChecks != nil {
+		for i := range defs {
+			defs[i].enabled = p.defaultChecks[defs[i].name] || optInChecks[defs[i].name] && defs[i].enabled
+		}
+	}
+	return defs
+}
+
+// checkDefs returns the checks with their enabled state before the defaults
+// of the global config apply. Short-circuit evaluation of || ensures
+// cfg.Checks fields are never accessed when cfg is nil.
+func checkDefs(cfg *allConfig, p checkParams) []checkDef {
 	noCfg := cfg == nil
 	return []checkDef{
 		{checkAge, noCfg || cfg.Checks.Age != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runAge(ctx, img, p.maxAge)
+			bounds, err := parseAgeBounds(p.ageNotBefore, p.ageNotAfter)
+			if err != nil {
+				return nil, err
+			}
+			result, err := runAgeWithRules(ctx, img, p.ageRules, p.maxAge, bounds)
+			return withPolicyWindow(result, p.ageWindow), err
 		}, renderAgeText},
 		{checkSize, noCfg || cfg.Checks.Size != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runSize(ctx, img, p.maxSize, p.maxLayers)
+			base, err := newLayerBase(p.countFromBase, p.baseImage, p.baseLayers)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base layers: %w", err)
+			}
+			result, err := runSize(ctx, img, p.maxSize, p.maxLayers, p.maxTotalSize, base)
+			return withPolicyWindow(result, p.sizeWindow), err
 		}, renderSizeText},
 		{checkPorts, noCfg || cfg.Checks.Ports != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
 			ports, err := parseAllowedPortsFrom(p.allowedPorts)
 			if err != nil {
 				return nil, fmt.Errorf("invalid allowed ports: %w", err)
 			}
-			return runPorts(ctx, img, ports)
+			return runPorts(ctx, img, ports, portLimits{maxExposed: p.maxExposedPorts, forbidPrivileged: p.forbidPrivileged})
 		}, renderPortsText},
 		{checkRegistry, noCfg || cfg.Checks.Registry != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
 			return runRegistry(ctx, img, p.registryPolicy)
 		}, renderRegistryText},
 		{checkSecrets, noCfg || cfg.Checks.Secrets != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runSecrets(ctx, img, p.secretsPolicy, p.skipEnvVars, p.skipFiles)
+			return runSecrets(ctx, img, p.secretsPolicy, p.skipEnvVars, p.skipFiles, p.failOnSeverity)
 		}, renderSecretsText},
 		{checkHealthcheck, noCfg || cfg.Checks.Healthcheck != nil, runHealthcheck, renderHealthcheckText},
 		{checkLabels, noCfg || cfg.Checks.Labels != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
 			return runLabels(ctx, img, p.labelsPolicy)
 		}, renderLabelsText},
 		{checkEntrypoint, noCfg || cfg.Checks.Entrypoint != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runEntrypoint(ctx, img, p.allowShellForm)
+			return runEntrypoint(ctx, img, p.allowShellForm, p.entrypointPolicy)
 		}, renderEntrypointText},
 		{checkPlatform, noCfg || cfg.Checks.Platform != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
 			platforms, err := parseAllowedPlatformsFrom(p.allowedPlatforms)
@@ -182,6 +392,39 @@ func buildCheckDefs(cfg *allConfig, p checkParams) []checkDef {
 			}
 			return runUser(ctx, img, policy)
 		}, renderUserText},
+		{checkProvenance, noCfg && p.provenancePolicy != "" || !noCfg && cfg.Checks.Provenance != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runProvenance(ctx, img, p.provenancePolicy)
+		}, renderProvenanceText},
+		{checkLazyPull, noCfg && p.lazyPullFormats != "" || !noCfg && cfg.Checks.LazyPull != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			formats, err := parseLazyPullFormatsFrom(p.lazyPullFormats)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lazy-pull formats: %w", err)
+			}
+			return runLazyPull(ctx, img, formats)
+		}, renderLazyPullText},
+		{checkDrift, noCfg && p.goldenSpec != "" || !noCfg && cfg.Checks.Drift != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runDrift(ctx, img, p.goldenSpec)
+		}, renderDriftText},
+		{checkEntropy, noCfg && p.entropyPolicy != "" || !noCfg && cfg.Checks.Entropy != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runEntropy(ctx, img, p.entropyPolicy)
+		}, renderEntropyText},
+		{checkDeprecation, noCfg && p.checkDeprecation || !noCfg && cfg.Checks.Deprecation != nil, runDeprecation, renderDeprecationText},
+		{checkArchitecture, noCfg && p.checkArchitecture || !noCfg && cfg.Checks.Architecture != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runArchitecture(ctx, img, p.maxBinaries)
+		}, renderArchitectureText},
+		{checkMinimal, noCfg && p.checkMinimal || !noCfg && cfg.Checks.Minimal != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			criteria, err := parseMinimalCriteria(p.minimalCriteria)
+			if err != nil {
+				return nil, fmt.Errorf("invalid minimal criteria: %w", err)
+			}
+			return runMinimal(ctx, img, criteria, p.minimalMaxSize)
+		}, renderMinimalText},
+		{checkSmoke, noCfg && p.checkSmoke || !noCfg && cfg.Checks.Smoke != nil, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			if err := validateSmokeDurations(p.smokeTimeout, p.smokeHealthyFor); err != nil {
+				return nil, err
+			}
+			return runSmoke(ctx, img, strings.Fields(p.smokeCommand), p.smokeTimeout, p.smokeHealthyFor, smokeLimits{p.smokeMemory, p.smokePidsLimit})
+		}, renderSmokeText},
 	}
 }
 
@@ -203,7 +446,7 @@ func determineChecks(cfg *allConfig, skipMap map[string]bool, includeMap map[str
 		}
 	}
 
-	return checks
+	return orderChecks(checks)
 }
 
 // buildUserPolicyFromParams constructs a *user.Policy from checkParams values.
@@ -238,7 +481,11 @@ func buildUserPolicyFromParams(p checkParams) (*user.Policy, error) {
 		policy.MaxUID = &p.userMaxUID
 	}
 	if p.blockedUsers != "" {
-		policy.BlockedUsers = parseBlockedUsers(p.blockedUsers)
+		users, err := parseBlockedUsers(p.blockedUsers)
+		if err != nil {
+			return nil, err
+		}
+		policy.BlockedUsers = users
 	}
 	if p.requireNumeric {
 		policy.RequireNumeric = &p.requireNumeric
@@ -252,63 +499,378 @@ func buildUserPolicyFromParams(p checkParams) (*user.Policy, error) {
 }
 
 func runAll(cmd *cobra.Command, imageName string) error {
+	if err := validateImageSources(imageName); err != nil {
+		return err
+	}
+	if err := validateGroupBy(imageName); err != nil {
+		return err
+	}
+	if source := selectedImageSource(); source != nil {
+		return runAllFromSource(cmd, source)
+	}
+	if err := validateImageTemplate(imageName); err != nil {
+		return err
+	}
+	if imageTemplate != "" {
+		return runAllServices(cmd)
+	}
+	if imageName == bulkImageArg {
+		return runAllBulk(cmd)
+	}
+	if err := validateAnnotateFlag(imageName); err != nil {
+		return err
+	}
+
+	run, err := evaluateAll(cmd, imageName)
+	if err != nil {
+		return err
+	}
+
+	if len(run.results) == 0 {
+		return renderEmptyResult(imageName, run.skipped, OutputFmt)
+	}
+
+	if annotateRegistry {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		passed := Result != ValidationFailed && Result != ExecutionError
+		if run.annotation, err = annotateValidation(ctx, imageName, run, passed); err != nil {
+			return err
+		}
+		if OutputFmt == output.FormatText {
+			fmt.Fprintf(stdout, "Validation outcome recorded in the registry as %s\n", run.annotation)
+		}
+	}
+
+	report := run.report(imageName)
+	hookReport = report
+	switch OutputFmt {
+	case output.FormatJSON:
+		return writeReport(report)
+	case output.FormatCSV:
+		return output.RenderCSV(stdout, output.ReportFindings(report))
+	}
+
+	printAllSummary(report)
+	return nil
+}
+
+// printAllSummary prints the final summary block of the all command in text
+// mode, mirroring the JSON summary: the check counts, the checks that failed
+// or errored, and the overall verdict.
+func printAllSummary(r output.AllResult) {
+	s := r.Summary
+	fmt.Fprintln(stdout, sectionHeader("summary"))
+	fmt.Fprintf(stdout, "Checks: %s\n", valueStyle.Render(fmt.Sprintf("%d run, %d passed, %d failed, %d errored, %d skipped",
+		s.Total, s.Passed, s.Failed, s.Errored, len(s.Skipped))))
+
+	var failed, errored []string
+	for _, c := range r.Checks {
+		switch {
+		case c.Error != "":
+			errored = append(errored, c.Check)
+		case !c.Passed:
+			failed = append(failed, c.Check)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(stdout, "Failed: %s\n", strings.Join(failed, ", "))
+	}
+	if len(errored) > 0 {
+		fmt.Fprintf(stdout, "Errored: %s\n", strings.Join(errored, ", "))
+	}
+	if len(r.PolicyViolations) > 0 {
+		fmt.Fprintf(stdout, "Policy violations: %d\n", len(r.PolicyViolations))
+	}
+	if r.ServedBy != "" {
+		fmt.Fprintf(stdout, "Served by: %s\n", r.ServedBy)
+	}
+
+	verdict := "Image passed all checks"
+	if !r.Passed {
+		verdict = "Image failed validation"
+	}
+	fmt.Fprintln(stdout, statusPrefix(r.Passed)+verdict)
+}
+
+// allRun holds everything the all command needs to render its result. It is
+// produced by evaluateAll and shared with commands that gate on validation,
+// such as promote.
+type allRun struct {
+	results    []output.CheckResult
+	skipped    []output.SkippedCheck
+	violations []string
+	// policyHash identifies the checks and parameters of the run.
+	policyHash string
+	// metadata identifies the build and the files of the run.
+	metadata *output.ReportMetadata
+	// annotation is the digest of the referrer pushed with --annotate-registry.
+	annotation string
+	// profile is the --policy-dir profile the image was validated with.
+	profile string
+	// servedBy is the registry mirror or upstream that served the image.
+	servedBy string
+	// effectiveConfig holds the parameters of the executed checks with
+	// --effective-config.
+	effectiveConfig *output.EffectiveConfig
+}
+
+// report returns the aggregated AllResult for the run.
+func (r *allRun) report(imageName string) output.AllResult {
+	if len(r.results) == 0 {
+		return emptyAllResult(imageName, r.skipped)
+	}
+	result := buildAllResult(imageName, r.results, r.skipped, r.violations)
+	result.Annotation = r.annotation
+	result.PolicyHash = r.policyHash
+	result.PolicyProfile = r.profile
+	result.ServedBy = r.servedBy
+	result.EffectiveConfig = r.effectiveConfig
+	result.Metadata = r.metadata
+	return result
+}
+
+// evaluateAll resolves the check selection from flags and config files and
+// executes the checks, printing per-check sections in text mode. It updates the
+// global Result but leaves the final JSON rendering to the caller. No checks
+// are executed (and results is empty) when the selection is empty.
+func evaluateAll(cmd *cobra.Command, imageName string) (*allRun, error) {
 	ctx := cmd.Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	skipMap, err := parseCheckNameList(skipChecks)
+	// The checks of an oci-archive image share a single extraction of it.
+	defer imageutil.RetainOCIArchives()()
+	imageutil.ClearServedBy(imageName)
+
+	skipMap, err := parseCheckNameList(skipChecks, "skip")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	includeMap, err := parseCheckNameList(includeChecks)
+	includeMap, err := parseCheckNameList(includeChecks, "include")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if skipMap != nil && includeMap != nil {
-		return fmt.Errorf("--include and --skip are mutually exclusive, use only one")
+		return nil, fmt.Errorf("--include and --skip are mutually exclusive, use only one")
 	}
 
-	var cfg *allConfig
-	if configFile != "" {
-		cfg, err = loadAllConfig(configFile)
-		if err != nil {
-			return err
-		}
-		cleanup, err := applyConfigValues(cmd, cfg)
-		defer cleanup()
-		if err != nil {
-			return err
-		}
+	labelViolations, restoreProfile, err := selectLabelProfile(ctx, imageName)
+	defer restoreProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, cleanupCfg, err := loadAndApplyConfig(cmd)
+	defer cleanupCfg()
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		activeHooks = cfg.Hooks
+	}
+	activeExitPolicy = newExitPolicy(cfg)
+
+	violations, cleanupRequired, err := setupRequiredConfig(ctx, cfg, skipMap, includeMap)
+	defer cleanupRequired()
+	if err != nil {
+		return nil, err
 	}
+	violations = append(labelViolations, violations...)
+
+	setupAnonymization(imageName)
 
 	p := currentCheckParams()
 	checks := determineChecks(cfg, skipMap, includeMap, p)
 
 	if err := validateRequiredFlags(checks, p); err != nil {
-		return err
+		return nil, err
+	}
+
+	expired, err := setupExceptions(ctx, imageName, checks)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, expired...)
+	setupLabelSuppressions(ctx, cfg, imageName)
+
+	telemetrySettings, err := resolveTelemetrySettings(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	outFmt := OutputFmt
 
+	if err := validateSigningFlags(outFmt); err != nil {
+		return nil, err
+	}
+	if err := auditlog.ValidateDest(auditLog); err != nil {
+		return nil, err
+	}
+	if err := validateEvidenceFlags(); err != nil {
+		return nil, err
+	}
+	if err := validateShowFlag(showSections); err != nil {
+		return nil, err
+	}
+
+	run := &allRun{violations: violations, profile: activePolicyProfile()}
 	if len(checks) == 0 {
-		return renderEmptyResult(imageName, skipMap, includeMap, outFmt)
+		run.skipped = skippedChecks(cfg, skipMap, includeMap, nil)
+		return run, nil
+	}
+
+	run.policyHash = policyHash(checks, p)
+	run.metadata = reportMetadata(checks, p)
+
+	if len(violations) > 0 {
+		UpdateResult(ValidationFailed)
 	}
 
 	if outFmt == output.FormatText {
-		fmt.Println(headerStyle.Render(fmt.Sprintf("Running %d checks on image %s", len(checks), imageName)))
-		fmt.Println()
+		header := fmt.Sprintf("Running %d checks on image %s", len(checks), redactText(imageName))
+		if run.profile != "" {
+			header += fmt.Sprintf(" with policy profile %s", run.profile)
+		}
+		fmt.Fprintln(stdout, headerStyle.Render(header))
+		fmt.Fprintln(stdout)
+		printPolicyViolations(violations)
 	}
 
-	results := executeChecks(ctx, checks, imageName, outFmt)
+	run.results = executeChecks(ctx, checks, imageName, outFmt)
+	run.servedBy = imageutil.ServedBy(imageName)
+	if effectiveConfig {
+		run.effectiveConfig = buildEffectiveConfig(run.results, p)
+	}
+	run.skipped = skippedChecks(cfg, skipMap, includeMap, run.results)
+	if outFmt == output.FormatText && len(run.skipped) > 0 {
+		printSkippedChecks(run.skipped)
+		fmt.Fprintln(stdout)
+	}
+	reportTelemetry(ctx, telemetrySettings, run.results)
+	if err := recordAudit(ctx, cmd, imageName, run); err != nil {
+		return nil, err
+	}
+	if err := writeEvidence(ctx, imageName, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// evaluateImage runs evaluateAll for one image of a command that validates
+// several images (daemon-watch, audit) and returns the run with its report.
+// The global Result is scoped to the image while its checks run, so fail-fast
+// and the report's passed flag reflect this image only; it is then merged
+// back into the overall Result.
+func evaluateImage(cmd *cobra.Command, imageName string) (*allRun, output.AllResult, error) {
+	overall := Result
+	Result = ValidationSkipped
+	defer func() {
+		image := Result
+		Result = overall
+		UpdateResult(image)
+	}()
 
-	if outFmt == output.FormatJSON {
-		return renderAllJSON(imageName, results, skipMap, includeMap)
+	run, err := evaluateAll(cmd, imageName)
+	if err != nil {
+		return nil, output.AllResult{}, err
 	}
+	return run, run.report(imageName), nil
+}
 
-	return nil
+// loadAndApplyConfig loads --config or the --policy profile when set and applies its values to the
+// package-level flag variables. It returns a nil config and a no-op cleanup
+// when no config file is given. The cleanup must always be deferred; it
+// restores the values the config overrode, so that with several images, each
+// possibly with its own --policy-label profile, every image starts from the
+// flags.
+// daemon-watch pins the config it validates with in watchConfig, which is
+// then applied instead of reading the file.
+func loadAndApplyConfig(cmd *cobra.Command) (*allConfig, func(), error) {
+	path, err := configSource()
+	if err != nil || path == "" {
+		return nil, func() {}, err
+	}
+	cfg := watchConfig
+	if cfg != nil {
+		resetConfigValues(cmd)
+	} else {
+		loaded, err := loadAllConfig(path)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		cfg = loaded
+	}
+	if err := checkHooksSource(path, cfg); err != nil {
+		return nil, func() {}, err
+	}
+	if err := setupRedaction(cfg); err != nil {
+		return nil, func() {}, err
+	}
+	restore := snapshotConfigValues(cmd)
+	cleanup, err := applyConfigValues(cmd, cfg)
+	return cfg, func() {
+		cleanup()
+		restore()
+	}, err
+}
+
+// setupRequiredConfig applies --required-config when set and returns the
+// resulting policy violations. It is a no-op when no required config is given.
+func setupRequiredConfig(ctx context.Context, cfg *allConfig, skipMap, includeMap map[string]bool) ([]string, func(), error) {
+	if requiredConfig == "" {
+		return nil, func() {}, nil
+	}
+	return applyRequiredConfig(ctx, requiredConfig, cfg, skipMap, includeMap)
+}
+
+// printPolicyViolations lists required-config violations in text mode.
+func printPolicyViolations(violations []string) {
+	if len(violations) == 0 {
+		return
+	}
+	for _, v := range violations {
+		fmt.Fprintf(stdout, "%sPolicy violation: %s\n", statusPrefix(false), redactText(v))
+	}
+	fmt.Fprintln(stdout)
+}
+
+// resolveTelemetrySettings combines the config file telemetry keys with the
+// environment overrides. Telemetry is disabled when no config sets it.
+func resolveTelemetrySettings(cfg *allConfig) (telemetry.Settings, error) {
+	var enabled bool
+	var endpoint string
+	if cfg != nil {
+		if cfg.Telemetry != nil {
+			enabled = *cfg.Telemetry
+		}
+		endpoint = cfg.TelemetryEndpoint
+	}
+	return telemetry.ResolveSettings(enabled, endpoint)
+}
+
+// reportTelemetry posts the anonymous usage report when telemetry is enabled.
+// Failures are logged at debug level and never affect the validation result.
+func reportTelemetry(ctx context.Context, s telemetry.Settings, results []output.CheckResult) {
+	if !s.Enabled {
+		return
+	}
+	if s.Endpoint == "" {
+		log.Warn("Telemetry is enabled but no endpoint is configured (set telemetry-endpoint or " + telemetry.EnvEndpoint + ")")
+		return
+	}
+	report := telemetry.BuildReport(ver.GetBuildInfo().Version, results)
+	if err := telemetry.Send(ctx, s.Endpoint, report); err != nil {
+		log.WithField("error", err).Debug("Failed to send telemetry report")
+		return
+	}
+	log.Debug("Telemetry report sent")
 }
 
 // validateRequiredFlags checks that required flags are provided when their checks will run.
@@ -328,75 +890,144 @@ func validateRequiredFlags(checks []checkDef, p checkParams) error {
 }
 
 // renderEmptyResult handles output when no checks are selected to run.
-func renderEmptyResult(imageName string, skipMap, includeMap map[string]bool, outFmt output.Format) error {
-	if outFmt == output.FormatJSON {
-		skipped := skippedCheckNames(skipMap, includeMap)
-		allResult := output.AllResult{
-			Image:  imageName,
-			Passed: true,
-			Checks: []output.CheckResult{},
-			Summary: output.Summary{
-				Total:   0,
-				Skipped: skipped,
-			},
-		}
-		return output.RenderJSON(os.Stdout, allResult)
+func renderEmptyResult(imageName string, skipped []output.SkippedCheck, outFmt output.Format) error {
+	hookReport = emptyAllResult(imageName, skipped)
+	switch outFmt {
+	case output.FormatJSON:
+		return writeReport(hookReport)
+	case output.FormatCSV:
+		return output.RenderCSV(stdout, nil)
 	}
-	fmt.Println("No checks to run")
+	printNoChecks(skipped)
 	return nil
 }
 
+// printNoChecks reports in text mode that no checks were selected, and why.
+func printNoChecks(skipped []output.SkippedCheck) {
+	fmt.Fprintln(stdout, "No checks to run")
+	printSkippedChecks(skipped)
+}
+
+// skipReasonText describes each skip reason in text output.
+var skipReasonText = map[string]string{
+	output.SkipReasonSkipFlag:          "--skip",
+	output.SkipReasonNotIncluded:       "not in --include",
+	output.SkipReasonNotInConfig:       "not in config",
+	output.SkipReasonFailFast:          "--fail-fast",
+	output.SkipReasonNoPolicy:          "no policy provided",
+	output.SkipReasonNotInDefaults:     "not in global defaults",
+	output.SkipReasonMetadataFailure:   "--early-exit-on-metadata-failure",
+	output.SkipReasonNotApplicable:     "not applicable",
+	output.SkipReasonNotContainerImage: "not a container image",
+}
+
+// printSkippedChecks prints the checks that were not evaluated on one line,
+// mirroring the skipped list of the JSON summary.
+func printSkippedChecks(skipped []output.SkippedCheck) {
+	if len(skipped) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(skipped))
+	for _, s := range skipped {
+		parts = append(parts, fmt.Sprintf("%s (%s)", s.Name, skipReasonText[s.Reason]))
+	}
+	fmt.Fprintln(stdout, dimStyle.Render("Skipped: "+strings.Join(parts, ", ")))
+}
+
+// emptyAllResult builds the (redacted) AllResult reported when no checks ran.
+func emptyAllResult(imageName string, skipped []output.SkippedCheck) output.AllResult {
+	return redactReport(output.AllResult{
+		Image:  imageName,
+		Passed: true,
+		Checks: []output.CheckResult{},
+		Summary: output.Summary{
+			Total:   0,
+			Skipped: skipped,
+		},
+	})
+}
+
 // printSectionHeader prints the check's section header in text mode.
-func printSectionHeader(name string, outFmt output.Format) {
+func printSectionHeader(w io.Writer, name string, outFmt output.Format) {
 	if outFmt == output.FormatText {
-		fmt.Println(sectionHeader(name))
+		fmt.Fprintln(w, sectionHeader(name))
 	}
 }
 
 // runSingleCheck executes one check, handles errors, and updates the global Result.
 func runSingleCheck(ctx context.Context, check checkDef, imageName string) output.CheckResult {
 	result, err := check.run(ctx, imageName)
+	if skipped, ok := artifactSkipResult(check.name, imageName, err); ok {
+		result, err = skipped, nil
+	}
 	if err != nil {
 		log.WithFields(log.Fields{"check": check.name, "error": err}).Error("Check failed")
-		UpdateResult(ExecutionError)
+		kind := recordExecutionError(err)
 		return output.CheckResult{
-			Check:   check.name,
-			Image:   imageName,
-			Passed:  false,
-			Message: fmt.Sprintf("check failed with error: %v", err),
-			Error:   err.Error(),
+			Check:     check.name,
+			Image:     imageName,
+			Passed:    false,
+			Message:   fmt.Sprintf("check failed with error: %v", err),
+			Error:     err.Error(),
+			ErrorKind: string(kind),
+			DocsURL:   checkDocsURL(check.name),
 		}
 	}
-	if result.Passed {
-		UpdateResult(ValidationSucceeded)
-	} else {
-		UpdateResult(ValidationFailed)
-	}
+	setDocsURL(result)
+	applyLabelSuppressions(result)
+	applyException(result)
+	applyExitPolicy(result)
+	updateCheckResult(result)
 	return *result
 }
 
 // printSectionFooter renders the check result and prints a blank line in text mode.
-// render is skipped for error results because they carry no typed Details.
-func printSectionFooter(check checkDef, result *output.CheckResult, outFmt output.Format) {
+// render is skipped for error results and for checks skipped before they ran
+// because they carry no typed Details; the message of the latter is printed.
+func printSectionFooter(w io.Writer, check checkDef, result *output.CheckResult, outFmt output.Format) {
 	if outFmt != output.FormatText {
 		return
 	}
-	if check.render != nil && result.Error == "" {
-		check.render(result)
+	if result.Skipped && result.Details == nil {
+		fmt.Fprintln(w, dimStyle.Render(result.Message))
+	} else if check.render != nil && result.Error == "" {
+		check.render(w, result)
+		printSuppressedFindings(w, result)
+		printExceptionLine(w, result)
+		printExitPolicyLine(w, result)
+		printRemediation(w, result)
+		printDocsLink(w, result)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // executeChecks runs each check, collects results, and updates the global Result.
+// An early summary of the metadata checks is reported before the layer checks
+// run.
 func executeChecks(ctx context.Context, checks []checkDef, imageName string, outFmt output.Format) []output.CheckResult {
 	var results []output.CheckResult
 
-	for _, check := range checks {
+	for i, check := range checks {
+		// checks are ordered by orderChecks: the first layer check after a
+		// metadata check ends the metadata phase.
+		if i > 0 && layerChecks[check.name] && !layerChecks[checks[i-1].name] {
+			if !finishMetadataPhase(results, checks[i:], outFmt) {
+				break
+			}
+		}
 		log.WithField("check", check.name).Debug("Running check")
-		printSectionHeader(check.name, outFmt)
-		result := runSingleCheck(ctx, check, imageName)
+		// The section of a check is written at once when it finishes, or
+		// dropped when --show hides it.
+		section := stdout.Section()
+		printSectionHeader(section, check.name, outFmt)
+		result := redactResult(runSingleCheck(ctx, check, imageName))
 		results = append(results, result)
-		printSectionFooter(check, &result, outFmt)
+		printSectionFooter(section, check, &result, outFmt)
+		if showSection(showSections, result) {
+			if err := section.Flush(); err != nil {
+				log.WithFields(log.Fields{"check": check.name, "error": err}).Warn("Unable to write the check output")
+			}
+		}
 		if failFast && (Result == ValidationFailed || Result == ExecutionError) {
 			break
 		}
@@ -405,14 +1036,18 @@ func executeChecks(ctx context.Context, checks []checkDef, imageName string, out
 	return results
 }
 
-// renderAllJSON renders the aggregated results as a single JSON object.
-func renderAllJSON(imageName string, results []output.CheckResult, skipMap map[string]bool, includeMap map[string]bool) error {
-	skipped := skippedCheckNames(skipMap, includeMap)
+// buildAllResult aggregates check results into an AllResult. Passed reflects
+// the global Result, so it must be called after the checks have run. Results
+// skipped by their check are not counted as run. The image and policy
+// violations are redacted; the results must already be.
+func buildAllResult(imageName string, results []output.CheckResult, skipped []output.SkippedCheck, violations []string) output.AllResult {
 	var passed, failed, errored int
 	for _, r := range results {
 		switch {
 		case r.Error != "":
 			errored++
+		case r.Skipped:
+			// reported in skipped by skippedChecks
 		case r.Passed:
 			passed++
 		default:
@@ -420,44 +1055,67 @@ func renderAllJSON(imageName string, results []output.CheckResult, skipMap map[s
 		}
 	}
 
-	allResult := output.AllResult{
-		Image:  imageName,
-		Passed: Result != ValidationFailed && Result != ExecutionError,
-		Checks: results,
+	return redactReport(output.AllResult{
+		Image:            imageName,
+		Passed:           Result != ValidationFailed && Result != ExecutionError,
+		Checks:           results,
+		PolicyViolations: violations,
 		Summary: output.Summary{
-			Total:   len(results),
+			Total:   passed + failed + errored,
 			Passed:  passed,
 			Failed:  failed,
 			Errored: errored,
 			Skipped: skipped,
 		},
-	}
-	return output.RenderJSON(os.Stdout, allResult)
+	})
 }
 
-// skippedCheckNames returns the list of check names that did not run.
-func skippedCheckNames(skipMap map[string]bool, includeMap map[string]bool) []string {
-	if includeMap != nil {
-		var names []string
-		for _, name := range validCheckNames {
-			if !includeMap[name] {
-				names = append(names, name)
-			}
-		}
-		if len(names) == 0 {
-			return nil
+// skippedChecks returns the checks that did not run or skipped themselves,
+// in check order, with the reason for each. results holds the checks that
+// ran; a selected check missing from it was cut short by --fail-fast, or by
+// --early-exit-on-metadata-failure for a layer check after a failed metadata
+// check.
+func skippedChecks(cfg *allConfig, skipMap, includeMap map[string]bool, results []output.CheckResult) []output.SkippedCheck {
+	ran := make(map[string]*output.CheckResult, len(results))
+	for i, r := range results {
+		ran[r.Check] = &results[i]
+	}
+	earlyExit := earlyExitOnMetadataFailure && metadataFailed(results)
+	cutShort := func(name string) string {
+		if earlyExit && layerChecks[name] {
+			return output.SkipReasonMetadataFailure
 		}
-		return names
+		return output.SkipReasonFailFast
 	}
 
-	if len(skipMap) == 0 {
-		return nil
-	}
-	var names []string
-	for _, name := range validCheckNames {
-		if skipMap[name] {
-			names = append(names, name)
+	var skipped []output.SkippedCheck
+	for _, def := range buildCheckDefs(cfg, currentCheckParams()) {
+		if r, ok := ran[def.name]; ok {
+			if r.Skipped {
+				skipped = append(skipped, output.SkippedCheck{Name: r.Check, Reason: r.SkipReason, Message: r.Message})
+			}
+			continue
+		}
+		var reason string
+		switch {
+		case includeMap != nil:
+			if includeMap[def.name] {
+				reason = cutShort(def.name)
+			} else {
+				reason = output.SkipReasonNotIncluded
+			}
+		case skipMap[def.name]:
+			reason = output.SkipReasonSkipFlag
+		case !def.enabled && cfg == nil && activeGlobalConfig.defaultChecks() != nil:
+			reason = output.SkipReasonNotInDefaults
+		case !def.enabled && cfg == nil:
+			reason = output.SkipReasonNoPolicy
+		case !def.enabled:
+			reason = output.SkipReasonNotInConfig
+		default:
+			reason = cutShort(def.name)
 		}
+		skipped = append(skipped, output.SkippedCheck{Name: def.name, Reason: reason})
 	}
-	return names
+	return skipped
 }
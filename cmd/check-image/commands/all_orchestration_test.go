@@ -4,28 +4,38 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/bake"
+	"github.com/jarfernandez/check-image/internal/daemonwatch"
+	"github.com/jarfernandez/check-image/internal/dockerhub"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/telemetry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAllCommand(t *testing.T) {
 	assert.NotNil(t, allCmd)
-	assert.Equal(t, "all image", allCmd.Use)
+	assert.Equal(t, "all [image]", allCmd.Use)
 	assert.Contains(t, allCmd.Short, "all")
 
-	// Test that it requires exactly 1 argument
+	// Test that it accepts at most 1 argument; without one, --image-template
+	// is required (see TestValidateImageTemplate)
 	assert.NotNil(t, allCmd.Args)
 	err := allCmd.Args(allCmd, []string{})
-	assert.Error(t, err)
+	assert.NoError(t, err)
 
 	err = allCmd.Args(allCmd, []string{"image"})
 	assert.NoError(t, err)
@@ -91,7 +101,8 @@ func TestDetermineChecks(t *testing.T) {
 		for i, c := range checks {
 			names[i] = c.name
 		}
-		assert.Equal(t, []string{"age", "size", "ports", "registry", "secrets", "healthcheck", "labels", "entrypoint", "platform", "user"}, names)
+		// Metadata checks run before the layer checks
+		assert.Equal(t, []string{"age", "size", "ports", "registry", "healthcheck", "labels", "platform", "user", "secrets", "entrypoint"}, names)
 	})
 
 	t.Run("skip excludes checks", func(t *testing.T) {
@@ -186,22 +197,98 @@ func TestDetermineChecks(t *testing.T) {
 // doResetGlobals sets all package-level command variables back to their defaults.
 func doResetGlobals() {
 	Result = ValidationSkipped
+	executionErrorKind = ""
+	executionErrors = 0
 	OutputFmt = output.FormatText
 	colorMode = "auto"
 	maxAge = 90
 	maxSize = 500
 	maxLayers = 20
+	maxTotalSize = 0
+	ageWindow = nil
+	sizeWindow = nil
+	ageRules = nil
+	ageNotBefore, ageNotAfter = "", ""
+	showSections = showAll
+	policyNow = time.Now
+	countFromBase = true
+	baseImage = ""
+	baseLayers = ""
 	allowedPorts = ""
+	maxExposedPorts = 0
+	forbidPrivilegedPorts = false
 	registryPolicy = ""
 	labelsPolicy = ""
 	secretsPolicy = ""
 	skipEnvVars = false
 	skipFiles = false
 	allowShellForm = false
+	entrypointPolicy = ""
+	effectiveConfig = false
+	reproducible = false
+	activeSuppressions = nil
 	configFile = ""
 	skipChecks = ""
 	includeChecks = ""
 	failFast = false
+	requiredConfig = ""
+	signResults = ""
+	signatureOutput = defaultSignatureFile
+	promoteAttest = false
+	annotateRegistry = false
+	groupBy = ""
+	imageTemplate = ""
+	serviceList = ""
+	imageTag = ""
+	fromSBOM = ""
+	fromLockfile = ""
+	bakeTargets = nil
+	bakeMetadataFile = ""
+	bakePrint = bake.Print
+	noProgress = false
+	earlyExitOnMetadataFailure = false
+	failOnSeverity = ""
+	progressOut = io.Discard // keeps the output of bulk tests quiet
+	provenancePolicy = ""
+	lazyPullFormats = ""
+	goldenSpec = ""
+	entropyPolicy = ""
+	checkDeprecationFlag = false
+	checkArchitectureFlag = false
+	maxBinaries = defaultMaxBinaries
+	checkMinimalFlag = false
+	minimalMaxSize = defaultMinimalMaxSizeMB
+	minimalCriteria = ""
+	checkSmokeFlag = false
+	smokeCommand = ""
+	smokeTimeout = defaultSmokeTimeout
+	smokeHealthyFor = 0
+	smokeMemory = defaultSmokeMemoryMB
+	smokePidsLimit = defaultSmokePidsLimit
+	activeGlobalConfig = nil
+	policyLabel = ""
+	allowedPolicies = ""
+	labelProfile = ""
+	dockerHubAPIURL = dockerhub.DefaultAPIURL
+	driftRecord = false
+	outputFile = ""
+	compressMode = string(output.CompressionAuto)
+	reportOut = nil
+	exceptionsFile = ""
+	exceptionsExpiring = ""
+	activeExceptions = nil
+	activeHooks = nil
+	activeExitPolicy = nil
+	hookReport = nil
+	hookOut = io.Discard
+	auditStateFile = ""
+	auditMaxImages = 0
+	auditShuffle = false
+	auditInterval = 0
+	auditTagHygiene = false
+	auditMaxTagIssues = 0
+	auditCoverageReports = nil
+	auditCoverageTop = 10
 	allowedPlatforms = ""
 	userPolicy = ""
 	userMinUID = 0
@@ -209,6 +296,41 @@ func doResetGlobals() {
 	blockedUsers = ""
 	requireNumeric = false
 	imageutil.ResetKeychain()
+	cacheDir = ""
+	cleanupDryRun = false
+	cleanupOlderThan = defaultCleanupOlderThan
+	cleanupCacheTTL = defaultCacheTTL
+	cleanupCacheMaxSize = 0
+	imageutil.ResetLayerCache()
+	resetRedaction()
+	docsBaseURL = defaultDocsBaseURL
+	artifactsMode = artifactsError
+	sizeUnits = string(output.UnitsMB)
+	strictConfig = false
+	anonymize = false
+	reportStatus = ""
+	reportStatusURL = ""
+	reportStatusContext = defaultReportStatusContext
+	validateStrictConfig = true
+	migrateWrite = false
+	watchEvents = strings.Join(daemonwatch.DefaultActions, ",")
+	alertWebhook = ""
+	healthAddr = ""
+	uiAddr = ""
+	uiHistorySize = defaultUIHistorySize
+	watchHistory = nil
+	authTokenFile = ""
+	shutdownTimeout = defaultShutdownTimeout
+	reloadInterval = defaultReloadInterval
+	dedupTTL = defaultDedupTTL
+	auditLog = ""
+	evidenceDir = ""
+	evidenceContentBytes = 0
+	watchConfig = nil
+	watchPolicyHash = ""
+	watchBaseline = nil
+	policyDir = ""
+	policyProfile = ""
 }
 
 // resetAllGlobals resets package-level state immediately and registers a
@@ -578,6 +700,139 @@ func TestRunAll_FailFast_StopsOnExecutionError(t *testing.T) {
 	assert.NotContains(t, output, "── user")
 }
 
+func TestRunAll_FailFast_JSONRecordsSkipReasons(t *testing.T) {
+	resetAllGlobals(t)
+	skipChecks = "registry,healthcheck,labels,entrypoint,platform"
+	failFast = true
+	allowedPorts = "invalid-port"
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{
+		user:       "1000",
+		created:    time.Now().Add(-10 * 24 * time.Hour),
+		layerCount: 2,
+	})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{Name: "registry", Reason: output.SkipReasonSkipFlag})
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{Name: "secrets", Reason: output.SkipReasonFailFast})
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{Name: "user", Reason: output.SkipReasonFailFast})
+	assert.NotContains(t, result.Summary.Skipped, output.SkippedCheck{Name: "ports", Reason: output.SkipReasonFailFast})
+}
+
+func TestRunAll_TextListsSkippedChecks(t *testing.T) {
+	resetAllGlobals(t)
+	skipChecks = "registry,healthcheck,labels,entrypoint,platform"
+	failFast = true
+	allowedPorts = "invalid-port"
+
+	imageRef := createTestImage(t, testImageOptions{
+		user:       "1000",
+		created:    time.Now().Add(-10 * 24 * time.Hour),
+		layerCount: 2,
+	})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, captured, "Skipped: ")
+	assert.Contains(t, captured, "registry (--skip)")
+	assert.Contains(t, captured, "user (--fail-fast)")
+	assert.NotContains(t, captured, "ports (")
+}
+
+func TestRunAll_TextSummary(t *testing.T) {
+	resetAllGlobals(t)
+	skipChecks = "registry,healthcheck,labels,entrypoint,platform,secrets"
+
+	imageRef := createTestImage(t, testImageOptions{
+		user:       "root",
+		created:    time.Now().Add(-10 * 24 * time.Hour),
+		layerCount: 2,
+	})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, captured, "── summary ")
+	assert.Contains(t, captured, "Checks: 4 run, 3 passed, 1 failed, 0 errored, 14 skipped")
+	assert.Contains(t, captured, "Failed: user\n")
+	assert.NotContains(t, captured, "Errored:")
+	assert.Contains(t, captured, "✗ Image failed validation")
+}
+
+func TestRunAll_NotApplicableCheckIsSkipped(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "registry,user"
+	registryPolicy = filepath.Join(t.TempDir(), "registry-policy.json")
+	require.NoError(t, os.WriteFile(registryPolicy, []byte(`{"trusted-registries": ["ghcr.io"]}`), 0600))
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.True(t, result.Passed)
+	require.Len(t, result.Checks, 2)
+	assert.Equal(t, output.StatusSkipped, result.Checks[0].Status())
+	assert.Equal(t, output.SkipReasonNotApplicable, result.Checks[0].SkipReason)
+	assert.Equal(t, 1, result.Summary.Total, "skipped checks are not counted as run")
+	assert.Equal(t, 1, result.Summary.Passed)
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{
+		Name:    "registry",
+		Reason:  output.SkipReasonNotApplicable,
+		Message: "Registry validation skipped (not applicable for this transport)",
+	})
+}
+
+func TestPrintAllSummary(t *testing.T) {
+	result := output.AllResult{
+		Passed: false,
+		Checks: []output.CheckResult{
+			{Check: "age", Passed: true},
+			{Check: "size", Passed: false},
+			{Check: "provenance", Passed: false, Error: "no attestations"},
+		},
+		PolicyViolations: []string{"check user is required"},
+		Summary:          output.Summary{Total: 3, Passed: 1, Failed: 1, Errored: 1},
+	}
+
+	captured := captureStdout(t, func() { printAllSummary(result) })
+	assert.Contains(t, captured, "Checks: 3 run, 1 passed, 1 failed, 1 errored, 0 skipped")
+	assert.Contains(t, captured, "Failed: size\n")
+	assert.Contains(t, captured, "Errored: provenance\n")
+	assert.Contains(t, captured, "Policy violations: 1\n")
+	assert.Contains(t, captured, "Image failed validation")
+
+	result = output.AllResult{Passed: true, Checks: []output.CheckResult{{Check: "age", Passed: true}}, Summary: output.Summary{Total: 1, Passed: 1}}
+	captured = captureStdout(t, func() { printAllSummary(result) })
+	assert.NotContains(t, captured, "Failed:")
+	assert.Contains(t, captured, "Image passed all checks")
+}
+
+func TestPrintSkippedChecks(t *testing.T) {
+	captured := captureStdout(t, func() {
+		printSkippedChecks([]output.SkippedCheck{
+			{Name: "registry", Reason: output.SkipReasonSkipFlag},
+			{Name: "labels", Reason: output.SkipReasonNotInConfig},
+			{Name: "secrets", Reason: output.SkipReasonNotIncluded},
+		})
+	})
+	assert.Equal(t, "Skipped: registry (--skip), labels (not in config), secrets (not in --include)\n", captured)
+
+	assert.Empty(t, captureStdout(t, func() { printSkippedChecks(nil) }))
+}
+
 func TestRunAll_FailFastDisabled_RunsAllChecks(t *testing.T) {
 	resetAllGlobals(t)
 	skipChecks = "registry,healthcheck,labels,platform" // skip checks that require policy files or missing healthcheck
@@ -719,47 +974,93 @@ func TestDetermineChecks_IncludeMap(t *testing.T) {
 	})
 }
 
-func TestSkippedCheckNames(t *testing.T) {
+func TestSkippedChecks(t *testing.T) {
+	ran := func(names ...string) []output.CheckResult {
+		var results []output.CheckResult
+		for _, n := range names {
+			results = append(results, output.CheckResult{Check: n})
+		}
+		return results
+	}
+	allNames := []string{
+		"age", "size", "ports", "registry", "secrets", "healthcheck",
+		"labels", "entrypoint", "platform", "user", "provenance", "lazy-pull", "drift", "entropy",
+		"deprecation", "architecture", "minimal", "smoke",
+	}
+
 	t.Run("with skip map", func(t *testing.T) {
 		skipMap := map[string]bool{"age": true, "size": true}
-		names := skippedCheckNames(skipMap, nil)
-		assert.Equal(t, []string{"age", "size"}, names)
+		skipped := skippedChecks(nil, skipMap, nil, ran(allNames[2:]...))
+		assert.Equal(t, []output.SkippedCheck{
+			{Name: "age", Reason: output.SkipReasonSkipFlag},
+			{Name: "size", Reason: output.SkipReasonSkipFlag},
+		}, skipped)
 	})
 
 	t.Run("with include map", func(t *testing.T) {
 		includeMap := map[string]bool{"age": true, "size": true}
-		names := skippedCheckNames(nil, includeMap)
-		assert.Len(t, names, 8)
-		assert.NotContains(t, names, "age")
-		assert.NotContains(t, names, "size")
-		assert.Contains(t, names, "ports")
-		assert.Contains(t, names, "registry")
-		assert.Contains(t, names, "secrets")
-		assert.Contains(t, names, "healthcheck")
-		assert.Contains(t, names, "labels")
-		assert.Contains(t, names, "entrypoint")
-		assert.Contains(t, names, "platform")
-		assert.Contains(t, names, "user")
-	})
-
-	t.Run("with neither", func(t *testing.T) {
-		names := skippedCheckNames(nil, nil)
-		assert.Nil(t, names)
-	})
-
-	t.Run("include all checks returns nil", func(t *testing.T) {
-		includeMap := map[string]bool{
-			"age": true, "size": true, "ports": true, "registry": true,
-			"secrets": true, "healthcheck": true, "labels": true, "entrypoint": true,
-			"platform": true, "user": true,
+		skipped := skippedChecks(nil, nil, includeMap, ran("age", "size"))
+		require.Len(t, skipped, 16)
+		for _, s := range skipped {
+			assert.NotContains(t, []string{"age", "size"}, s.Name)
+			assert.Equal(t, output.SkipReasonNotIncluded, s.Reason, s.Name)
 		}
-		names := skippedCheckNames(nil, includeMap)
-		assert.Nil(t, names)
 	})
 
-	t.Run("empty skip map returns nil", func(t *testing.T) {
-		names := skippedCheckNames(map[string]bool{}, nil)
-		assert.Nil(t, names)
+	t.Run("absent from config", func(t *testing.T) {
+		cfg := &allConfig{Checks: allChecksConfig{Age: &ageCheckConfig{}}}
+		skipped := skippedChecks(cfg, nil, nil, ran("age"))
+		require.Len(t, skipped, 17)
+		for _, s := range skipped {
+			assert.Equal(t, output.SkipReasonNotInConfig, s.Reason, s.Name)
+		}
+	})
+
+	t.Run("skip flag takes precedence over config", func(t *testing.T) {
+		cfg := &allConfig{Checks: allChecksConfig{Age: &ageCheckConfig{}}}
+		skipped := skippedChecks(cfg, map[string]bool{"age": true}, nil, nil)
+		require.NotEmpty(t, skipped)
+		assert.Equal(t, output.SkippedCheck{Name: "age", Reason: output.SkipReasonSkipFlag}, skipped[0])
+	})
+
+	t.Run("selected checks not run are fail-fast", func(t *testing.T) {
+		includeMap := map[string]bool{"age": true, "size": true, "user": true}
+		skipped := skippedChecks(nil, nil, includeMap, ran("age"))
+		assert.Contains(t, skipped, output.SkippedCheck{Name: "size", Reason: output.SkipReasonFailFast})
+		assert.Contains(t, skipped, output.SkippedCheck{Name: "user", Reason: output.SkipReasonFailFast})
+		assert.Contains(t, skipped, output.SkippedCheck{Name: "ports", Reason: output.SkipReasonNotIncluded})
+
+		skipped = skippedChecks(nil, map[string]bool{"user": true}, nil, ran("age"))
+		assert.Contains(t, skipped, output.SkippedCheck{Name: "size", Reason: output.SkipReasonFailFast})
+		assert.Contains(t, skipped, output.SkippedCheck{Name: "user", Reason: output.SkipReasonSkipFlag})
+	})
+
+	t.Run("opt-in checks without policy", func(t *testing.T) {
+		resetAllGlobals(t)
+		skipped := skippedChecks(nil, nil, nil, ran(allNames[:len(allNames)-8]...))
+		assert.Equal(t, []output.SkippedCheck{
+			{Name: "provenance", Reason: output.SkipReasonNoPolicy},
+			{Name: "lazy-pull", Reason: output.SkipReasonNoPolicy},
+			{Name: "drift", Reason: output.SkipReasonNoPolicy},
+			{Name: "entropy", Reason: output.SkipReasonNoPolicy},
+			{Name: "deprecation", Reason: output.SkipReasonNoPolicy},
+			{Name: "architecture", Reason: output.SkipReasonNoPolicy},
+			{Name: "minimal", Reason: output.SkipReasonNoPolicy},
+			{Name: "smoke", Reason: output.SkipReasonNoPolicy},
+		}, skipped)
+	})
+
+	t.Run("checks that skipped themselves", func(t *testing.T) {
+		results := ran(allNames...)
+		results[3] = output.CheckResult{Check: "registry", Passed: true, Message: "not a registry image", Skipped: true, SkipReason: output.SkipReasonNotApplicable}
+		assert.Equal(t, []output.SkippedCheck{
+			{Name: "registry", Reason: output.SkipReasonNotApplicable, Message: "not a registry image"},
+		}, skippedChecks(nil, nil, nil, results))
+	})
+
+	t.Run("all checks ran returns nil", func(t *testing.T) {
+		assert.Nil(t, skippedChecks(nil, nil, nil, ran(allNames...)))
+		assert.Nil(t, skippedChecks(nil, map[string]bool{}, nil, ran(allNames...)))
 	})
 }
 
@@ -963,6 +1264,7 @@ func TestRunAll_EntrypointWithAllowShellFormViaConfig(t *testing.T) {
 		user:       "1000",
 		created:    time.Now(),
 		entrypoint: []string{"/bin/sh", "-c", "nginx"},
+		layerFiles: []map[string]string{{"bin/sh": "shell"}},
 	})
 
 	captureStdout(t, func() {
@@ -974,6 +1276,29 @@ func TestRunAll_EntrypointWithAllowShellFormViaConfig(t *testing.T) {
 	assert.Equal(t, ValidationSucceeded, Result)
 }
 
+func TestRunAll_EntrypointPolicyInlineConfig(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRequiredConfig(t, `checks:
+  entrypoint:
+    entrypoint-policy:
+      forbidden-args:
+        - name: insecure-flags
+          pattern: '^--insecure$'
+`)
+
+	imageRef := createTestImage(t, testImageOptions{
+		user:       "1000",
+		created:    time.Now(),
+		entrypoint: []string{"/app/server", "--insecure"},
+	})
+
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Equal(t, ValidationFailed, Result)
+}
+
 func TestRunAll_PlatformPasses(t *testing.T) {
 	resetAllGlobals(t)
 	includeChecks = "platform"
@@ -1135,18 +1460,33 @@ func TestRunSingleCheck(t *testing.T) {
 		assert.False(t, got.Passed)
 		assert.Equal(t, "ports", got.Check)
 		assert.NotEmpty(t, got.Error)
+		assert.Empty(t, got.ErrorKind)
 		assert.Equal(t, ExecutionError, Result)
 	})
+
+	t.Run("check returns classified error", func(t *testing.T) {
+		resetAllGlobals(t)
+		check := checkDef{
+			name: "age",
+			run: func(ctx context.Context, _ string) (*output.CheckResult, error) {
+				_, _, err := imageutil.GetImage(ctx, "oci:"+t.TempDir()+":latest")
+				return nil, err
+			},
+		}
+		got := runSingleCheck(context.Background(), check, "img")
+		assert.Equal(t, "not-found", got.ErrorKind)
+		assert.Equal(t, imageutil.ErrorKindNotFound, executionErrorKind)
+	})
 }
 
 func TestPrintSectionHeader(t *testing.T) {
 	t.Run("text mode prints header", func(t *testing.T) {
-		out := captureStdout(t, func() { printSectionHeader("age", output.FormatText) })
+		out := captureStdout(t, func() { printSectionHeader(stdout, "age", output.FormatText) })
 		assert.Contains(t, out, "age")
 	})
 
 	t.Run("json mode prints nothing", func(t *testing.T) {
-		out := captureStdout(t, func() { printSectionHeader("age", output.FormatJSON) })
+		out := captureStdout(t, func() { printSectionHeader(stdout, "age", output.FormatJSON) })
 		assert.Empty(t, out)
 	})
 }
@@ -1157,9 +1497,9 @@ func TestPrintSectionFooter(t *testing.T) {
 		result := &output.CheckResult{Check: "age", Passed: true}
 		check := checkDef{
 			name:   "age",
-			render: func(*output.CheckResult) { rendered = true },
+			render: func(io.Writer, *output.CheckResult) { rendered = true },
 		}
-		out := captureStdout(t, func() { printSectionFooter(check, result, output.FormatText) })
+		out := captureStdout(t, func() { printSectionFooter(stdout, check, result, output.FormatText) })
 		assert.True(t, rendered)
 		assert.Equal(t, "\n", out)
 	})
@@ -1167,7 +1507,7 @@ func TestPrintSectionFooter(t *testing.T) {
 	t.Run("text mode with nil render prints only blank line", func(t *testing.T) {
 		result := &output.CheckResult{Check: "age", Passed: true}
 		check := checkDef{name: "age", render: nil}
-		out := captureStdout(t, func() { printSectionFooter(check, result, output.FormatText) })
+		out := captureStdout(t, func() { printSectionFooter(stdout, check, result, output.FormatText) })
 		assert.Equal(t, "\n", out)
 	})
 
@@ -1176,9 +1516,9 @@ func TestPrintSectionFooter(t *testing.T) {
 		result := &output.CheckResult{Check: "ports", Passed: false, Error: "some error"}
 		check := checkDef{
 			name:   "ports",
-			render: func(*output.CheckResult) { rendered = true },
+			render: func(io.Writer, *output.CheckResult) { rendered = true },
 		}
-		out := captureStdout(t, func() { printSectionFooter(check, result, output.FormatText) })
+		out := captureStdout(t, func() { printSectionFooter(stdout, check, result, output.FormatText) })
 		assert.False(t, rendered)
 		assert.Equal(t, "\n", out)
 	})
@@ -1188,16 +1528,98 @@ func TestPrintSectionFooter(t *testing.T) {
 		result := &output.CheckResult{Check: "age", Passed: true}
 		check := checkDef{
 			name:   "age",
-			render: func(*output.CheckResult) { rendered = true },
+			render: func(io.Writer, *output.CheckResult) { rendered = true },
 		}
-		out := captureStdout(t, func() { printSectionFooter(check, result, output.FormatJSON) })
+		out := captureStdout(t, func() { printSectionFooter(stdout, check, result, output.FormatJSON) })
 		assert.False(t, rendered)
 		assert.Empty(t, out)
 	})
 }
 
-// TestRenderAllJSON_AllPassing tests renderAllJSON when all checks pass.
-func TestRenderAllJSON_AllPassing(t *testing.T) {
+// sectionWriter records every write it receives separately.
+type sectionWriter struct {
+	writes []string
+}
+
+func (w *sectionWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestExecuteChecks_WritesEachSectionAtOnce(t *testing.T) {
+	resetAllGlobals(t)
+	var rec sectionWriter
+	previous := stdout
+	stdout = output.NewSink(&rec)
+	t.Cleanup(func() { stdout = previous })
+
+	check := func(name string) checkDef {
+		return checkDef{
+			name: name,
+			run: func(context.Context, string) (*output.CheckResult, error) {
+				return &output.CheckResult{Check: name, Passed: true, Message: name + " ok"}, nil
+			},
+			render: func(w io.Writer, r *output.CheckResult) {
+				_, _ = fmt.Fprintln(w, "Checking", r.Check)
+				_, _ = fmt.Fprintln(w, r.Message)
+			},
+		}
+	}
+	results := executeChecks(context.Background(), []checkDef{check("age"), check("user")}, "img", output.FormatText)
+
+	require.Len(t, results, 2)
+	require.Len(t, rec.writes, 2, "the header, rendering, and footer of a check are a single write")
+	assert.Contains(t, rec.writes[0], "age")
+	assert.Contains(t, rec.writes[0], "Checking age\nage ok\n\n")
+	assert.Contains(t, rec.writes[1], "Checking user\nuser ok\n\n")
+}
+
+func TestExecuteChecks_ShowFailed(t *testing.T) {
+	resetAllGlobals(t)
+	showSections = showFailed
+	var rec sectionWriter
+	previous := stdout
+	stdout = output.NewSink(&rec)
+	t.Cleanup(func() { stdout = previous })
+
+	check := func(name string, result *output.CheckResult, err error) checkDef {
+		return checkDef{
+			name: name,
+			run: func(context.Context, string) (*output.CheckResult, error) {
+				return result, err
+			},
+			render: func(w io.Writer, r *output.CheckResult) {
+				_, _ = fmt.Fprintln(w, "Checking", r.Check)
+			},
+		}
+	}
+	checks := []checkDef{
+		check(checkAge, &output.CheckResult{Check: checkAge, Passed: true}, nil),
+		check(checkUser, &output.CheckResult{Check: checkUser, Passed: false}, nil),
+		check(checkSize, nil, errors.New("boom")),
+		check(checkRegistry, &output.CheckResult{Check: checkRegistry, Passed: true, Skipped: true}, nil),
+	}
+	results := executeChecks(context.Background(), checks, "img", output.FormatText)
+
+	require.Len(t, results, 4, "hidden checks are still reported")
+	written := strings.Join(rec.writes, "")
+	assert.NotContains(t, written, "Checking age")
+	assert.Contains(t, written, "Checking user")
+	assert.Contains(t, written, sectionHeader(checkSize))
+	assert.NotContains(t, written, sectionHeader(checkRegistry))
+}
+
+func TestValidateShowFlag(t *testing.T) {
+	require.NoError(t, validateShowFlag(showAll))
+	require.NoError(t, validateShowFlag(showFailed))
+
+	err := validateShowFlag("passed")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported --show value "passed", valid values are: failed, all`)
+}
+
+// TestWriteReport_AllResult_AllPassing tests writing the AllResult when all checks pass.
+func TestWriteReport_AllResult_AllPassing(t *testing.T) {
 	resetAllGlobals(t)
 	Result = ValidationSucceeded
 
@@ -1207,7 +1629,7 @@ func TestRenderAllJSON_AllPassing(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		err := renderAllJSON("nginx:latest", results, nil, nil)
+		err := writeReport(buildAllResult("nginx:latest", results, nil, nil))
 		require.NoError(t, err)
 	})
 
@@ -1223,8 +1645,8 @@ func TestRenderAllJSON_AllPassing(t *testing.T) {
 	assert.Nil(t, summary["skipped"]) // no skipped checks
 }
 
-// TestRenderAllJSON_WithFailures tests renderAllJSON when some checks fail or error.
-func TestRenderAllJSON_WithFailures(t *testing.T) {
+// TestWriteReport_AllResult_WithFailures tests writing the AllResult when some checks fail or error.
+func TestWriteReport_AllResult_WithFailures(t *testing.T) {
 	resetAllGlobals(t)
 	Result = ValidationFailed
 
@@ -1235,7 +1657,7 @@ func TestRenderAllJSON_WithFailures(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		err := renderAllJSON("nginx:latest", results, nil, nil)
+		err := writeReport(buildAllResult("nginx:latest", results, nil, nil))
 		require.NoError(t, err)
 	})
 
@@ -1249,8 +1671,8 @@ func TestRenderAllJSON_WithFailures(t *testing.T) {
 	assert.Equal(t, float64(1), summary["errored"])
 }
 
-// TestRenderAllJSON_WithSkipMap tests renderAllJSON with a skip map.
-func TestRenderAllJSON_WithSkipMap(t *testing.T) {
+// TestWriteReport_AllResult_WithSkipMap tests writing the AllResult with a skip map.
+func TestWriteReport_AllResult_WithSkipMap(t *testing.T) {
 	resetAllGlobals(t)
 	Result = ValidationSucceeded
 
@@ -1258,9 +1680,10 @@ func TestRenderAllJSON_WithSkipMap(t *testing.T) {
 		{Check: checkAge, Image: "nginx:latest", Passed: true, Message: "Image is recent"},
 	}
 	skipMap := map[string]bool{"registry": true, "secrets": true}
+	skipped := skippedChecks(nil, skipMap, nil, results)
 
 	captured := captureStdout(t, func() {
-		err := renderAllJSON("nginx:latest", results, skipMap, nil)
+		err := writeReport(buildAllResult("nginx:latest", results, skipped, nil))
 		require.NoError(t, err)
 	})
 
@@ -1268,13 +1691,13 @@ func TestRenderAllJSON_WithSkipMap(t *testing.T) {
 	require.NoError(t, json.Unmarshal([]byte(captured), &data))
 	assert.Equal(t, true, data["passed"])
 	summary := data["summary"].(map[string]any)
-	skipped := summary["skipped"].([]any)
-	assert.Contains(t, skipped, "registry")
-	assert.Contains(t, skipped, "secrets")
+	entries := summary["skipped"].([]any)
+	assert.Contains(t, entries, map[string]any{"name": "registry", "reason": "skip-flag"})
+	assert.Contains(t, entries, map[string]any{"name": "secrets", "reason": "skip-flag"})
 }
 
-// TestRenderAllJSON_WithIncludeMap tests renderAllJSON with an include map.
-func TestRenderAllJSON_WithIncludeMap(t *testing.T) {
+// TestWriteReport_AllResult_WithIncludeMap tests writing the AllResult with an include map.
+func TestWriteReport_AllResult_WithIncludeMap(t *testing.T) {
 	resetAllGlobals(t)
 	Result = ValidationSucceeded
 
@@ -1282,9 +1705,10 @@ func TestRenderAllJSON_WithIncludeMap(t *testing.T) {
 		{Check: checkAge, Image: "nginx:latest", Passed: true, Message: "Image is recent"},
 	}
 	includeMap := map[string]bool{"age": true}
+	skipped := skippedChecks(nil, nil, includeMap, results)
 
 	captured := captureStdout(t, func() {
-		err := renderAllJSON("nginx:latest", results, nil, includeMap)
+		err := writeReport(buildAllResult("nginx:latest", results, skipped, nil))
 		require.NoError(t, err)
 	})
 
@@ -1293,15 +1717,16 @@ func TestRenderAllJSON_WithIncludeMap(t *testing.T) {
 	assert.Equal(t, true, data["passed"])
 	summary := data["summary"].(map[string]any)
 	// All checks except "age" should appear in skipped
-	skipped := summary["skipped"].([]any)
-	assert.NotContains(t, skipped, "age")
-	assert.Contains(t, skipped, "size")
-	assert.Contains(t, skipped, "registry")
+	entries := summary["skipped"].([]any)
+	assert.Len(t, entries, 17)
+	assert.NotContains(t, entries, map[string]any{"name": "age", "reason": "not-included"})
+	assert.Contains(t, entries, map[string]any{"name": "size", "reason": "not-included"})
+	assert.Contains(t, entries, map[string]any{"name": "registry", "reason": "not-included"})
 }
 
 func TestRenderEmptyResult_TextMode(t *testing.T) {
 	captured := captureStdout(t, func() {
-		err := renderEmptyResult("nginx:latest", nil, nil, output.FormatText)
+		err := renderEmptyResult("nginx:latest", nil, output.FormatText)
 		require.NoError(t, err)
 	})
 
@@ -1309,10 +1734,13 @@ func TestRenderEmptyResult_TextMode(t *testing.T) {
 }
 
 func TestRenderEmptyResult_JSONMode(t *testing.T) {
-	skipMap := map[string]bool{"registry": true, "secrets": true}
+	skipped := []output.SkippedCheck{
+		{Name: "registry", Reason: output.SkipReasonSkipFlag},
+		{Name: "secrets", Reason: output.SkipReasonNotInConfig},
+	}
 
 	captured := captureStdout(t, func() {
-		err := renderEmptyResult("nginx:latest", skipMap, nil, output.FormatJSON)
+		err := renderEmptyResult("nginx:latest", skipped, output.FormatJSON)
 		require.NoError(t, err)
 	})
 
@@ -1324,9 +1752,10 @@ func TestRenderEmptyResult_JSONMode(t *testing.T) {
 	assert.Len(t, checks, 0)
 	summary := data["summary"].(map[string]any)
 	assert.Equal(t, float64(0), summary["total"])
-	skipped := summary["skipped"].([]any)
-	assert.Contains(t, skipped, "registry")
-	assert.Contains(t, skipped, "secrets")
+	assert.Equal(t, []any{
+		map[string]any{"name": "registry", "reason": "skip-flag"},
+		map[string]any{"name": "secrets", "reason": "not-in-config"},
+	}, summary["skipped"])
 }
 
 // TestRunAll_EntrypointWithCmdField tests that the entrypoint check renders
@@ -1528,3 +1957,80 @@ func TestRunAll_EntrypointWithCmdField(t *testing.T) {
 	assert.Contains(t, out, "Entrypoint:")
 	assert.Contains(t, out, "Cmd:")
 }
+
+func TestRunAll_TelemetryOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	t.Setenv(telemetry.EnvEnabled, "")
+	t.Setenv(telemetry.EnvEndpoint, "")
+
+	var payload []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	cfgFile := filepath.Join(tmpDir, "config.yaml")
+	content := "telemetry: true\ntelemetry-endpoint: " + server.URL + "\nchecks:\n  healthcheck: {}\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+	configFile = cfgFile
+
+	imageRef := createTestImage(t, testImageOptions{})
+
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	require.NotEmpty(t, payload)
+	var report telemetry.Report
+	require.NoError(t, json.Unmarshal(payload, &report))
+	assert.Equal(t, telemetry.CheckCounts{Runs: 1, Failed: 1}, report.Checks["healthcheck"])
+	assert.NotContains(t, string(payload), tmpDir)
+}
+
+func TestRunAll_TelemetryDisabledByDefault(t *testing.T) {
+	resetAllGlobals(t)
+	t.Setenv(telemetry.EnvEnabled, "")
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	t.Setenv(telemetry.EnvEndpoint, server.URL)
+
+	imageRef := createTestImage(t, testImageOptions{})
+	skipChecks = "registry,labels,ports,platform"
+
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.False(t, called)
+}
+
+func TestRunAll_TelemetryEnvOptOut(t *testing.T) {
+	resetAllGlobals(t)
+	t.Setenv(telemetry.EnvEnabled, "false")
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	cfgFile := filepath.Join(tmpDir, "config.yaml")
+	content := "telemetry: true\ntelemetry-endpoint: " + server.URL + "\nchecks:\n  healthcheck: {}\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+	configFile = cfgFile
+
+	imageRef := createTestImage(t, testImageOptions{})
+
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.False(t, called)
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+var earlyExitOnMetadataFailure bool
+
+// layerChecks are the checks that may stream image layers: secrets scans the
+// files of every layer, entrypoint looks for the shell of shell-form
+// commands, architecture samples ELF binaries, minimal looks for shells,
+// package managers, and compilers, and smoke runs the image. The other checks
+// only read the manifest, the config, and registry metadata, so they are cheap
+// and run first.
+var layerChecks = map[string]bool{
+	checkSecrets:      true,
+	checkEntrypoint:   true,
+	checkArchitecture: true,
+	checkMinimal:      true,
+	checkSmoke:        true,
+}
+
+// orderChecks moves the layer checks after the metadata checks, keeping the
+// order of each group, so cheap checks report before the expensive ones.
+func orderChecks(checks []checkDef) []checkDef {
+	ordered := make([]checkDef, 0, len(checks))
+	for _, c := range checks {
+		if !layerChecks[c.name] {
+			ordered = append(ordered, c)
+		}
+	}
+	for _, c := range checks {
+		if layerChecks[c.name] {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered
+}
+
+// metadataFailed reports whether a metadata check of results failed or
+// errored.
+func metadataFailed(results []output.CheckResult) bool {
+	for _, r := range results {
+		if !layerChecks[r.Check] && !r.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// finishMetadataPhase reports the outcome of the metadata checks before the
+// layer checks start, and whether they should start: with
+// --early-exit-on-metadata-failure, a failed metadata check skips them.
+func finishMetadataPhase(results []output.CheckResult, layer []checkDef, outFmt output.Format) bool {
+	var passed, failed int
+	var errored []string
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			errored = append(errored, r.Check)
+		case r.Status() == output.StatusPassed:
+			passed++
+		case r.Status() == output.StatusFailed:
+			failed++
+		}
+	}
+	proceed := !earlyExitOnMetadataFailure || !metadataFailed(results)
+
+	names := make([]string, len(layer))
+	for i, c := range layer {
+		names[i] = c.name
+	}
+	if outFmt != output.FormatText {
+		log.WithFields(log.Fields{
+			"passed":         passed,
+			"failed":         failed,
+			"errored":        len(errored),
+			"errored-checks": strings.Join(errored, ","),
+			"layer-checks":   strings.Join(names, ","),
+			"skipped":        !proceed,
+		}).Info("Metadata checks finished")
+		return proceed
+	}
+
+	fmt.Fprintln(stdout, headerStyle.Render(fmt.Sprintf("Metadata checks: %d passed, %d failed, %d errored", passed, failed, len(errored))))
+	if len(errored) > 0 {
+		fmt.Fprintf(stdout, "Errored: %s\n", strings.Join(errored, ", "))
+	}
+	if proceed {
+		fmt.Fprintf(stdout, "Running layer checks: %s\n", strings.Join(names, ", "))
+	} else {
+		fmt.Fprintf(stdout, "Skipping layer checks after a metadata check failed: %s\n", strings.Join(names, ", "))
+	}
+	fmt.Fprintln(stdout)
+	return proceed
+}
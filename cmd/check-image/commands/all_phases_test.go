@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderChecks(t *testing.T) {
+	checks := []checkDef{{name: checkSecrets}, {name: checkAge}, {name: checkEntrypoint}, {name: checkUser}}
+
+	names := make([]string, 0, len(checks))
+	for _, c := range orderChecks(checks) {
+		names = append(names, c.name)
+	}
+	assert.Equal(t, []string{checkAge, checkUser, checkSecrets, checkEntrypoint}, names)
+}
+
+func TestMetadataFailed(t *testing.T) {
+	assert.False(t, metadataFailed([]output.CheckResult{{Check: checkAge, Passed: true}, {Check: checkSecrets}}),
+		"layer checks are not metadata checks")
+	assert.True(t, metadataFailed([]output.CheckResult{{Check: checkAge, Passed: true}, {Check: checkUser}}))
+}
+
+func TestFinishMetadataPhase_Errored(t *testing.T) {
+	resetAllGlobals(t)
+	results := []output.CheckResult{
+		{Check: checkAge, Passed: true},
+		{Check: checkUser},
+		{Check: checkRegistry, Error: "registry unreachable"},
+	}
+
+	captured := captureStdout(t, func() {
+		assert.True(t, finishMetadataPhase(results, []checkDef{{name: checkSecrets}}, output.FormatText))
+	})
+	assert.Contains(t, captured, "Metadata checks: 1 passed, 1 failed, 1 errored")
+	assert.Contains(t, captured, "Errored: registry")
+}
+
+func TestRunAll_EarlyExitOnMetadataFailure(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user,secrets,entrypoint"
+	earlyExitOnMetadataFailure = true
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Checks, 1)
+	assert.Equal(t, checkUser, result.Checks[0].Check)
+	assert.Equal(t, []output.SkippedCheck{
+		{Name: checkSecrets, Reason: output.SkipReasonMetadataFailure},
+		{Name: checkEntrypoint, Reason: output.SkipReasonMetadataFailure},
+	}, filterSkipped(result.Summary.Skipped, output.SkipReasonMetadataFailure))
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_MetadataPhaseSummary(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "secrets,user"
+
+	imageRef := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, captured, "Metadata checks: 0 passed, 1 failed, 0 errored")
+	assert.Contains(t, captured, "Running layer checks: secrets")
+	assert.Less(t, strings.Index(captured, "Checking user"), strings.Index(captured, "Metadata checks:"),
+		"the user check runs before the early summary")
+}
+
+func TestRunAll_EarlyExitOnMetadataFailure_Text(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "secrets,user"
+	earlyExitOnMetadataFailure = true
+
+	imageRef := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, captured, "Skipping layer checks after a metadata check failed: secrets")
+	assert.Contains(t, captured, "secrets (--early-exit-on-metadata-failure)")
+}
+
+func TestRunAll_EarlyExitOnMetadataFailure_MetadataPassed(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "secrets,user"
+	earlyExitOnMetadataFailure = true
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Checks, 2)
+	assert.Equal(t, checkSecrets, result.Checks[1].Check, "layer checks still run after passing metadata checks")
+}
+
+func filterSkipped(skipped []output.SkippedCheck, reason string) []output.SkippedCheck {
+	var filtered []output.SkippedCheck
+	for _, s := range skipped {
+		if s.Reason == reason {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+const windowDateLayout = "2006-01-02"
+
+// policyNow returns the time policy windows are evaluated at.
+var policyNow = time.Now
+
+// The policy windows that set the effective check values, nil when none
+// applies. They are reported in the details of the check results.
+var (
+	ageWindow  *output.PolicyWindow
+	sizeWindow *output.PolicyWindow
+)
+
+// policyWindow bounds when the values of a windows entry apply. From is
+// inclusive. A date-only Until is valid through that day (UTC); a timestamp
+// Until is exclusive.
+type policyWindow struct {
+	From   string `json:"from,omitempty"   yaml:"from,omitempty"`
+	Until  string `json:"until,omitempty"  yaml:"until,omitempty"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+func (w policyWindow) bounds() (from, until time.Time, err error) {
+	if w.From == "" && w.Until == "" {
+		return from, until, fmt.Errorf("from or until is required")
+	}
+	if w.From != "" {
+		if from, err = parseWindowTime(w.From, false); err != nil {
+			return from, until, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if w.Until != "" {
+		if until, err = parseWindowTime(w.Until, true); err != nil {
+			return from, until, fmt.Errorf("invalid until: %w", err)
+		}
+	}
+	if w.From != "" && w.Until != "" && !from.Before(until) {
+		return from, until, fmt.Errorf("from %q is not before until %q", w.From, w.Until)
+	}
+	return from, until, nil
+}
+
+// active reports whether now falls within the window. Windows are validated
+// when the configuration is loaded, so invalid ones are never active.
+func (w policyWindow) active(now time.Time) bool {
+	from, until, err := w.bounds()
+	if err != nil {
+		return false
+	}
+	return (w.From == "" || !now.Before(from)) && (w.Until == "" || now.Before(until))
+}
+
+func (w policyWindow) output() *output.PolicyWindow {
+	return &output.PolicyWindow{From: w.From, Until: w.Until, Reason: w.Reason}
+}
+
+// parseWindowTime parses a YYYY-MM-DD date (UTC) or an RFC 3339 timestamp.
+// With endOfDay, a date stands for the end of that day.
+func parseWindowTime(s string, endOfDay bool) (time.Time, error) {
+	if d, err := time.Parse(windowDateLayout, s); err == nil {
+		if endOfDay {
+			return d.AddDate(0, 0, 1), nil
+		}
+		return d, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date or an RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
+
+type ageWindowConfig struct {
+	policyWindow `yaml:",inline"`
+	MaxAge       *uint `json:"max-age,omitempty" yaml:"max-age,omitempty"`
+}
+
+type sizeWindowConfig struct {
+	policyWindow `yaml:",inline"`
+	MaxSize      *uint `json:"max-size,omitempty"       yaml:"max-size,omitempty"`
+	MaxLayers    *uint `json:"max-layers,omitempty"     yaml:"max-layers,omitempty"`
+	MaxTotalSize *uint `json:"max-total-size,omitempty" yaml:"max-total-size,omitempty"`
+}
+
+// activeWindow returns the first of windows that is active at now.
+func activeWindow[T any](windows []T, window func(T) policyWindow, now time.Time) (T, bool) {
+	for _, w := range windows {
+		if window(w).active(now) {
+			return w, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// validatePolicyWindows checks the windows of every check of cfg.
+func validatePolicyWindows(cfg *allConfig) error {
+	windows := make(map[string][]policyWindow)
+	if cfg.Checks.Age != nil {
+		for _, w := range cfg.Checks.Age.Windows {
+			windows[checkAge] = append(windows[checkAge], w.policyWindow)
+		}
+	}
+	if cfg.Checks.Size != nil {
+		for _, w := range cfg.Checks.Size.Windows {
+			windows[checkSize] = append(windows[checkSize], w.policyWindow)
+		}
+	}
+
+	for _, check := range []string{checkAge, checkSize} {
+		for i, w := range windows[check] {
+			if _, _, err := w.bounds(); err != nil {
+				return fmt.Errorf("invalid checks.%s.windows entry %d: %w", check, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// withPolicyWindow records the policy window that set the values of a check
+// in the details of its result.
+func withPolicyWindow(result *output.CheckResult, w *output.PolicyWindow) *output.CheckResult {
+	if result == nil || w == nil {
+		return result
+	}
+	switch d := result.Details.(type) {
+	case output.AgeDetails:
+		d.PolicyWindow = w
+		result.Details = d
+	case output.SizeDetails:
+		d.PolicyWindow = w
+		result.Details = d
+	}
+	return result
+}
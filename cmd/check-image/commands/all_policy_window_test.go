@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyWindowActive(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		ts, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	tests := []struct {
+		name   string
+		window policyWindow
+		now    string
+		want   bool
+	}{
+		{"before from", policyWindow{From: "2026-07-01"}, "2026-06-30T23:59:59Z", false},
+		{"at from", policyWindow{From: "2026-07-01"}, "2026-07-01T00:00:00Z", true},
+		{"date until is inclusive", policyWindow{Until: "2026-07-01"}, "2026-07-01T23:59:59Z", true},
+		{"after date until", policyWindow{Until: "2026-07-01"}, "2026-07-02T00:00:00Z", false},
+		{"timestamp until is exclusive", policyWindow{Until: "2026-07-01T12:00:00Z"}, "2026-07-01T12:00:00Z", false},
+		{"within both bounds", policyWindow{From: "2026-07-01", Until: "2026-07-31"}, "2026-07-15T08:00:00Z", true},
+		{"invalid window", policyWindow{From: "July"}, "2026-07-15T08:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.window.active(at(tt.now)))
+		})
+	}
+}
+
+func TestPolicyWindowBounds_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  policyWindow
+		wantErr string
+	}{
+		{"no bounds", policyWindow{Reason: "migration"}, "from or until is required"},
+		{"invalid from", policyWindow{From: "01/07/2026"}, "invalid from"},
+		{"invalid until", policyWindow{Until: "soon"}, "invalid until"},
+		{"from after until", policyWindow{From: "2026-08-01", Until: "2026-07-01"}, "is not before until"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.window.bounds()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseAllConfig_PolicyWindows(t *testing.T) {
+	t.Run("valid windows", func(t *testing.T) {
+		cfg, err := parseAllConfig([]byte(`
+checks:
+  age:
+    max-age: 90
+    windows:
+      - from: "2026-07-01"
+        max-age: 30
+        reason: Base image migration deadline
+`), "config.yaml")
+		require.NoError(t, err)
+		require.Len(t, cfg.Checks.Age.Windows, 1)
+		w := cfg.Checks.Age.Windows[0]
+		assert.Equal(t, "2026-07-01", w.From)
+		assert.Equal(t, "Base image migration deadline", w.Reason)
+		require.NotNil(t, w.MaxAge)
+		assert.Equal(t, uint(30), *w.MaxAge)
+	})
+
+	t.Run("valid JSON windows", func(t *testing.T) {
+		cfg, err := parseAllConfig([]byte(`{"checks":{"size":{"windows":[{"until":"2026-07-01","max-size":800}]}}}`), "config.json")
+		require.NoError(t, err)
+		require.Len(t, cfg.Checks.Size.Windows, 1)
+		assert.Equal(t, "2026-07-01", cfg.Checks.Size.Windows[0].Until)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		_, err := parseAllConfig([]byte(`
+checks:
+  size:
+    windows:
+      - from: "2026-07-01"
+      - until: next week
+`), "config.yaml")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid checks.size.windows entry 2")
+	})
+}
+
+func TestApplyConfig_PolicyWindows(t *testing.T) {
+	thirty, sixty, ninety := uint(30), uint(60), uint(90)
+	ageCfg := &ageCheckConfig{
+		MaxAge: &ninety,
+		Windows: []ageWindowConfig{
+			{policyWindow: policyWindow{From: "2026-07-01", Until: "2026-07-31", Reason: "Freeze"}, MaxAge: &sixty},
+			{policyWindow: policyWindow{From: "2026-07-01", Reason: "Migration deadline"}, MaxAge: &thirty},
+		},
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().UintVarP(&maxAge, "max-age", "a", 90, "")
+		cmd.Flags().UintVarP(&maxSize, "max-size", "m", 500, "")
+		cmd.Flags().UintVarP(&maxLayers, "max-layers", "y", 20, "")
+		cmd.Flags().UintVar(&maxTotalSize, "max-total-size", 0, "")
+		return cmd
+	}
+	setNow := func(s string) {
+		ts, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		policyNow = func() time.Time { return ts }
+	}
+
+	t.Run("no active window", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-06-15T00:00:00Z")
+
+		applyAgeConfig(newCmd(), ageCfg)
+
+		assert.Equal(t, uint(90), maxAge)
+		assert.Nil(t, ageWindow)
+	})
+
+	t.Run("first active window wins", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-07-15T00:00:00Z")
+
+		applyAgeConfig(newCmd(), ageCfg)
+
+		assert.Equal(t, uint(60), maxAge)
+		assert.Equal(t, &output.PolicyWindow{From: "2026-07-01", Until: "2026-07-31", Reason: "Freeze"}, ageWindow)
+	})
+
+	t.Run("later window after the first ends", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-08-01T00:00:00Z")
+
+		applyAgeConfig(newCmd(), ageCfg)
+
+		assert.Equal(t, uint(30), maxAge)
+		require.NotNil(t, ageWindow)
+		assert.Equal(t, "Migration deadline", ageWindow.Reason)
+	})
+
+	t.Run("flag overrides windows", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-08-01T00:00:00Z")
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("max-age", "10"))
+
+		applyAgeConfig(cmd, ageCfg)
+
+		assert.Equal(t, uint(10), maxAge)
+		assert.Nil(t, ageWindow)
+	})
+
+	t.Run("size window overrides only the limits it sets", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-08-01T00:00:00Z")
+		twoHundred, ten := uint(200), uint(10)
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("max-layers", "15"))
+
+		applySizeConfig(cmd, &sizeCheckConfig{
+			MaxSize: &ninety,
+			Windows: []sizeWindowConfig{
+				{policyWindow: policyWindow{From: "2026-07-01", Reason: "Slim images"}, MaxSize: &twoHundred, MaxLayers: &ten},
+			},
+		})
+
+		assert.Equal(t, uint(200), maxSize)
+		assert.Equal(t, uint(15), maxLayers)
+		require.NotNil(t, sizeWindow)
+		assert.Equal(t, "Slim images", sizeWindow.Reason)
+	})
+
+	t.Run("size window with only overridden limits is not reported", func(t *testing.T) {
+		resetAllGlobals(t)
+		setNow("2026-08-01T00:00:00Z")
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("max-size", "300"))
+
+		applySizeConfig(cmd, &sizeCheckConfig{
+			Windows: []sizeWindowConfig{
+				{policyWindow: policyWindow{From: "2026-07-01"}, MaxSize: &thirty},
+			},
+		})
+
+		assert.Equal(t, uint(300), maxSize)
+		assert.Nil(t, sizeWindow)
+	})
+}
+
+func TestWithPolicyWindow(t *testing.T) {
+	w := &output.PolicyWindow{From: "2026-07-01", Reason: "Migration deadline"}
+
+	age := withPolicyWindow(&output.CheckResult{Check: checkAge, Details: output.AgeDetails{MaxAge: 30}}, w)
+	assert.Equal(t, w, age.Details.(output.AgeDetails).PolicyWindow)
+
+	size := withPolicyWindow(&output.CheckResult{Check: checkSize, Details: output.SizeDetails{MaxSizeMB: 200}}, w)
+	assert.Equal(t, w, size.Details.(output.SizeDetails).PolicyWindow)
+
+	unchanged := withPolicyWindow(&output.CheckResult{Check: checkAge, Details: output.AgeDetails{}}, nil)
+	assert.Nil(t, unchanged.Details.(output.AgeDetails).PolicyWindow)
+
+	assert.Nil(t, withPolicyWindow(nil, w))
+}
+
+func TestRenderPolicyWindow(t *testing.T) {
+	out := captureStdout(t, func() {
+		renderAgeText(stdout, &output.CheckResult{
+			Check:   checkAge,
+			Image:   "nginx:latest",
+			Passed:  false,
+			Message: "Image is older than 30 days",
+			Details: output.AgeDetails{
+				CreatedAt:    "2026-01-01T00:00:00Z",
+				AgeDays:      200,
+				MaxAge:       30,
+				PolicyWindow: &output.PolicyWindow{From: "2026-07-01", Until: "2026-12-31", Reason: "Migration deadline"},
+			},
+		})
+	})
+	assert.Contains(t, out, "Policy window: Migration deadline (from 2026-07-01 until 2026-12-31)")
+
+	out = captureStdout(t, func() {
+		renderPolicyWindow(stdout, &output.PolicyWindow{Until: "2026-12-31"})
+	})
+	assert.Contains(t, out, "Policy window: no reason given (until 2026-12-31)")
+}
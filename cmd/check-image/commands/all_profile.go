@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var policyDir string
+var policyProfile string
+
+// defaultPolicyProfile is the profile used when --policy-dir is given without
+// --policy.
+const defaultPolicyProfile = "default"
+
+// profileExtensions are the file extensions of profiles, in lookup order.
+var profileExtensions = []string{".yaml", ".yml", ".json"}
+
+// profileNamePattern restricts profile names to plain file names, so a name
+// can only select a profile of the policy directory.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// configSource returns the configuration file to validate with: --config, the
+// --policy profile of --policy-dir, or an empty path when neither is given.
+func configSource() (string, error) {
+	if policyProfile != "" && policyDir == "" {
+		return "", fmt.Errorf("--policy requires --policy-dir")
+	}
+	if policyDir == "" {
+		return configFile, nil
+	}
+	if configFile != "" {
+		return "", fmt.Errorf("--config and --policy-dir are mutually exclusive, use only one")
+	}
+	return resolvePolicyProfile(policyDir, activePolicyProfile())
+}
+
+// activePolicyProfile returns the name of the profile in use: the profile
+// selected by the --policy-label label of the image, --policy, or the default
+// one. It returns an empty string when no policy directory is given.
+func activePolicyProfile() string {
+	switch {
+	case policyDir == "":
+		return ""
+	case labelProfile != "":
+		return labelProfile
+	case policyProfile == "":
+		return defaultPolicyProfile
+	default:
+		return policyProfile
+	}
+}
+
+// resolvePolicyProfile returns the path of the profile name in dir: a
+// configuration file named <name>.yaml, <name>.yml, or <name>.json.
+func resolvePolicyProfile(dir, name string) (string, error) {
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid policy profile name %q: use lowercase letters, digits, '.', '_', and '-'", name)
+	}
+	for _, ext := range profileExtensions {
+		path := filepath.Join(dir, name+ext)
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() {
+			return path, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read policy profile %q: %w", name, err)
+		}
+	}
+
+	available, err := listPolicyProfiles(dir)
+	if err != nil {
+		return "", err
+	}
+	if name == defaultPolicyProfile && policyProfile == "" {
+		return "", fmt.Errorf("no default policy profile in %s, select one with --policy (available: %s)", dir, formatProfiles(available))
+	}
+	return "", fmt.Errorf("policy profile %q not found in %s (available: %s)", name, dir, formatProfiles(available))
+}
+
+// listPolicyProfiles returns the sorted names of the profiles in dir.
+func listPolicyProfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy directory: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		name := strings.TrimSuffix(e.Name(), ext)
+		if e.Type().IsRegular() && slices.Contains(profileExtensions, ext) && profileNamePattern.MatchString(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func formatProfiles(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	log "github.com/sirupsen/logrus"
+)
+
+var policyLabel string
+var allowedPolicies string
+
+// labelProfile is the profile selected by the --policy-label label of the
+// image being validated, or an empty string when none is.
+var labelProfile string
+
+// validatePolicyLabelFlags checks that --policy-label comes with a policy
+// directory and the allow-list of profiles images may select.
+func validatePolicyLabelFlags() error {
+	switch {
+	case policyLabel == "" && allowedPolicies != "":
+		return fmt.Errorf("--allowed-policies requires --policy-label")
+	case policyLabel == "":
+		return nil
+	case policyDir == "":
+		return fmt.Errorf("--policy-label requires --policy-dir")
+	case allowedPolicies == "":
+		return fmt.Errorf("--policy-label requires --allowed-policies, the profiles images may select")
+	}
+	return nil
+}
+
+// parseAllowedPolicies returns the profile names of --allowed-policies.
+func parseAllowedPolicies() ([]string, error) {
+	names, err := parseListInput(allowedPolicies, "allowed-policies")
+	if err != nil {
+		return nil, fmt.Errorf("invalid --allowed-policies: %w", err)
+	}
+	for _, name := range names {
+		if !profileNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid --allowed-policies: invalid policy profile name %q", name)
+		}
+	}
+	return names, nil
+}
+
+// selectLabelProfile selects the policy profile named by the --policy-label
+// label of the image, when it is in --allowed-policies. Without the label,
+// the --policy profile (or the default one) applies. A label naming a profile
+// that is not allowed is returned as a policy violation, and the --policy
+// profile applies instead. The returned function restores the previous
+// selection and must always be deferred.
+func selectLabelProfile(ctx context.Context, imageName string) ([]string, func(), error) {
+	if err := validatePolicyLabelFlags(); err != nil || policyLabel == "" {
+		return nil, func() {}, err
+	}
+	allowed, err := parseAllowedPolicies()
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup()
+
+	value, ok := config.Config.Labels[policyLabel]
+	value = strings.TrimSpace(value)
+	fields := log.Fields{"label": policyLabel, "profile": value}
+	switch {
+	case !ok || value == "":
+		log.WithField("label", policyLabel).Debug("Image has no policy label, using the --policy profile")
+		return nil, func() {}, nil
+	case !slices.Contains(allowed, value):
+		log.WithFields(fields).Warn("Image selects a policy profile that is not allowed")
+		return []string{fmt.Sprintf("label %s=%s selects policy profile %q, which is not in --allowed-policies (%s); validated with the %s profile instead",
+			policyLabel, value, value, strings.Join(allowed, ", "), activePolicyProfile())}, func() {}, nil
+	}
+
+	log.WithFields(fields).Info("Using the policy profile selected by the image label")
+	previous := labelProfile
+	labelProfile = value
+	return nil, func() { labelProfile = previous }, nil
+}
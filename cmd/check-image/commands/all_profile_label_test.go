@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePolicyLabelFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		label     string
+		allowed   string
+		wantError string
+	}{
+		{name: "not set"},
+		{name: "complete", dir: "policies", label: "policy-profile", allowed: "frontend,batch"},
+		{name: "allow-list without label", allowed: "frontend", wantError: "--allowed-policies requires --policy-label"},
+		{name: "label without directory", label: "policy-profile", allowed: "frontend", wantError: "--policy-label requires --policy-dir"},
+		{name: "label without allow-list", dir: "policies", label: "policy-profile", wantError: "--policy-label requires --allowed-policies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			policyDir, policyLabel, allowedPolicies = tt.dir, tt.label, tt.allowed
+
+			err := validatePolicyLabelFlags()
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantError)
+		})
+	}
+}
+
+func TestSelectLabelProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        map[string]string
+		allowed       string
+		wantProfile   string
+		wantViolation string
+		wantError     string
+	}{
+		{name: "allowed profile", labels: map[string]string{"policy-profile": "frontend"}, allowed: "frontend,batch", wantProfile: "frontend"},
+		{name: "no label", labels: map[string]string{"team": "web"}, allowed: "frontend", wantProfile: "default"},
+		{name: "profile not allowed", labels: map[string]string{"policy-profile": "lenient"}, allowed: "frontend,batch", wantProfile: "default",
+			wantViolation: `label policy-profile=lenient selects policy profile "lenient", which is not in --allowed-policies (frontend, batch); validated with the default profile instead`},
+		{name: "invalid allow-list", labels: map[string]string{"policy-profile": "frontend"}, allowed: "Frontend", wantError: `invalid policy profile name "Frontend"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			policyDir, policyLabel, allowedPolicies = "policies", "policy-profile", tt.allowed
+			imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now(), labels: tt.labels})
+
+			violations, restore, err := selectLabelProfile(context.Background(), imageRef)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProfile, activePolicyProfile())
+			if tt.wantViolation != "" {
+				assert.Equal(t, []string{tt.wantViolation}, violations)
+			} else {
+				assert.Empty(t, violations)
+			}
+			restore()
+			assert.Equal(t, "default", activePolicyProfile())
+		})
+	}
+}
+
+func TestRunAll_PolicyLabel(t *testing.T) {
+	dir := writeProfiles(t, map[string]string{
+		"default.yaml":  "checks:\n  user: {}\n",
+		"frontend.yaml": "checks:\n  age:\n    max-age: 1\n",
+	})
+
+	tests := []struct {
+		name           string
+		labels         map[string]string
+		wantProfile    string
+		wantCheck      string
+		wantViolations int
+	}{
+		{name: "label profile", labels: map[string]string{"policy-profile": "frontend"}, wantProfile: "frontend", wantCheck: checkAge},
+		{name: "fallback profile", wantProfile: "default", wantCheck: checkUser},
+		{name: "profile not allowed", labels: map[string]string{"policy-profile": "default-lax"}, wantProfile: "default", wantCheck: checkUser, wantViolations: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			OutputFmt = output.FormatJSON
+			policyDir, policyLabel, allowedPolicies = dir, "policy-profile", "frontend"
+			imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now(), labels: tt.labels})
+
+			captured := captureStdout(t, func() {
+				require.NoError(t, runAll(allCmd, imageRef))
+			})
+
+			var result output.AllResult
+			require.NoError(t, json.Unmarshal([]byte(captured), &result))
+			assert.Equal(t, tt.wantProfile, result.PolicyProfile)
+			require.Len(t, result.Checks, 1)
+			assert.Equal(t, tt.wantCheck, result.Checks[0].Check)
+			assert.Len(t, result.PolicyViolations, tt.wantViolations)
+			assert.Equal(t, tt.wantViolations == 0, result.Passed)
+			assert.Empty(t, labelProfile)
+		})
+	}
+}
+
+func TestRunAll_PolicyLabel_Bulk(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	policyDir = writeProfiles(t, map[string]string{
+		"default.yaml": "checks:\n  size: {}\n",
+		"strict.yaml":  "checks:\n  size:\n    max-layers: 1\n",
+	})
+	policyLabel, allowedPolicies = "policy-profile", "strict"
+	flagMaxLayers := maxLayers
+
+	strict := createTestImage(t, testImageOptions{created: time.Now(), layerCount: 3, labels: map[string]string{"policy-profile": "strict"}})
+	lenient := createTestImage(t, testImageOptions{created: time.Now(), layerCount: 3})
+	withStdin(t, strict+"\n"+lenient+"\n")
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, "-"))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Images, 2)
+	assert.Equal(t, "strict", result.Images[0].PolicyProfile)
+	assert.False(t, result.Images[0].Passed, "the strict profile allows 1 layer")
+	assert.Equal(t, "default", result.Images[1].PolicyProfile)
+	assert.True(t, result.Images[1].Passed, "the max-layers of the strict profile does not carry over to the next image")
+	assert.Equal(t, flagMaxLayers, maxLayers)
+}
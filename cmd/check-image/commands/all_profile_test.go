@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfiles(t *testing.T, profiles map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for file, content := range profiles {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestConfigSource(t *testing.T) {
+	dir := writeProfiles(t, map[string]string{
+		"default.yaml":  "checks:\n  user: {}\n",
+		"team-a.json":   `{"checks": {"age": {}}}`,
+		"team-b.yml":    "checks:\n  size: {}\n",
+		"README.md":     "not a profile",
+		"Invalid.yaml":  "checks: {}\n",
+		"team-a.yaml.1": "checks: {}\n",
+	})
+	noDefault := writeProfiles(t, map[string]string{"team-a.yaml": "checks: {}\n"})
+
+	tests := []struct {
+		name    string
+		config  string
+		dir     string
+		profile string
+		want    string
+		wantErr string
+	}{
+		{name: "No config", want: ""},
+		{name: "Config file", config: "config.yaml", want: "config.yaml"},
+		{name: "Default profile", dir: dir, want: filepath.Join(dir, "default.yaml")},
+		{name: "Named JSON profile", dir: dir, profile: "team-a", want: filepath.Join(dir, "team-a.json")},
+		{name: "Named yml profile", dir: dir, profile: "team-b", want: filepath.Join(dir, "team-b.yml")},
+		{name: "Unknown profile", dir: dir, profile: "team-c", wantErr: `policy profile "team-c" not found in ` + dir + " (available: default, team-a, team-b)"},
+		{name: "Path traversal", dir: dir, profile: "../default", wantErr: `invalid policy profile name "../default"`},
+		{name: "No default profile", dir: noDefault, wantErr: "no default policy profile in " + noDefault + ", select one with --policy (available: team-a)"},
+		{name: "Missing directory", dir: filepath.Join(dir, "missing"), profile: "team-a", wantErr: "failed to read policy directory"},
+		{name: "Policy without directory", profile: "team-a", wantErr: "--policy requires --policy-dir"},
+		{name: "Config and directory", config: "config.yaml", dir: dir, wantErr: "--config and --policy-dir are mutually exclusive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			configFile = tt.config
+			policyDir = tt.dir
+			policyProfile = tt.profile
+
+			got, err := configSource()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunAll_PolicyProfile(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	policyDir = writeProfiles(t, map[string]string{
+		"default.yaml": "checks:\n  user: {}\n",
+		"strict.yaml":  "checks:\n  user: {}\n  healthcheck: {}\n",
+	})
+	policyProfile = "strict"
+	image := createTestImage(t, testImageOptions{user: "1000"})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image))
+	})
+
+	var report output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, "strict", report.PolicyProfile)
+	assert.Len(t, report.Checks, 2)
+}
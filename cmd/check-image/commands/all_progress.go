@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"io"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/progress"
+	"github.com/mattn/go-isatty"
+)
+
+var noProgress bool
+
+// progressOut receives the progress of bulk runs; tests replace it.
+var progressOut io.Writer = os.Stderr
+
+// newProgress returns a tracker for a run over total images, or nil with
+// --no-progress. The progress line is rewritten in place only when stderr is
+// a terminal and stdout carries no text output to interleave with it.
+func newProgress(total int) *progress.Tracker {
+	if noProgress {
+		return nil
+	}
+	live := OutputFmt != output.FormatText && progressOut == os.Stderr && isatty.IsTerminal(os.Stderr.Fd())
+	return progress.New(progressOut, total, live)
+}
+
+// recordProgress adds the report of an image to tracker, when there is one.
+func recordProgress(tracker *progress.Tracker, report output.AllResult) {
+	if tracker == nil {
+		return
+	}
+	tracker.Record(progress.Result{
+		Image:        report.Image,
+		Outcome:      imageOutcome(report),
+		FailedChecks: failedCheckNames(report.Checks),
+	})
+}
+
+// printProgressSummary prints the fleet summary table of tracker, when there
+// is one.
+func printProgressSummary(tracker *progress.Tracker) {
+	if tracker != nil {
+		tracker.Summary()
+	}
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/redact"
+	log "github.com/sirupsen/logrus"
+)
+
+// activeRedactor holds the redaction patterns from the config file. It is nil
+// (no redaction) unless the config sets redact.
+var activeRedactor *redact.Redactor
+
+// savedLogFormatter is the log formatter replaced by setupRedaction, restored
+// by resetRedaction.
+var savedLogFormatter log.Formatter
+
+// redactingFormatter redacts every formatted log entry so that values hidden
+// in the report never reach stderr either.
+// It uses activeRedactor at the time of each entry, so that names registered
+// by setupAnonymization apply too.
+type redactingFormatter struct {
+	log.Formatter
+}
+
+func (f *redactingFormatter) Format(entry *log.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err != nil {
+		return b, err
+	}
+	return []byte(redactText(string(b))), nil
+}
+
+// setupRedaction compiles the config's redaction patterns and installs them
+// for check results, text output, and log output. The redaction of a
+// previous config is always removed first, so that when the config of the
+// next image or a reloaded policy has no patterns, nothing is redacted.
+func setupRedaction(cfg *allConfig) error {
+	resetRedaction()
+	if cfg == nil || len(cfg.Redact) == 0 {
+		return nil
+	}
+	r, err := redact.New(cfg.Redact)
+	if err != nil {
+		return fmt.Errorf("invalid redact configuration: %w", err)
+	}
+	activeRedactor = r
+	installRedactingFormatter()
+	return nil
+}
+
+// installRedactingFormatter wraps the log formatter with redactingFormatter,
+// unless it already is.
+func installRedactingFormatter() {
+	if savedLogFormatter != nil {
+		return
+	}
+	savedLogFormatter = log.StandardLogger().Formatter
+	log.SetFormatter(&redactingFormatter{Formatter: savedLogFormatter})
+}
+
+// resetRedaction disables redaction and restores the original log formatter.
+func resetRedaction() {
+	if savedLogFormatter != nil {
+		log.SetFormatter(savedLogFormatter)
+		savedLogFormatter = nil
+	}
+	activeRedactor = nil
+}
+
+// redactText redacts a string printed outside of a result envelope.
+func redactText(s string) string {
+	out, _ := activeRedactor.String(s)
+	return out
+}
+
+// redactResult returns r with every string field redacted and Redacted set
+// when anything was altered.
+func redactResult(r output.CheckResult) output.CheckResult {
+	v, changed := activeRedactor.Apply(r)
+	if !changed {
+		return r
+	}
+	out := v.(output.CheckResult)
+	out.Redacted = true
+	return out
+}
+
+// redactReport redacts the report fields that are not part of a check result.
+// Check results are redacted as they are produced by executeChecks.
+func redactReport(r output.AllResult) output.AllResult {
+	image, imageChanged := activeRedactor.String(r.Image)
+	violations, violationsChanged := activeRedactor.Apply(r.PolicyViolations)
+	if !imageChanged && !violationsChanged {
+		return r
+	}
+	r.Image = image
+	r.PolicyViolations = violations.([]string)
+	r.Redacted = true
+	return r
+}
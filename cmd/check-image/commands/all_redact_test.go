@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRedactConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "checks:\n  user: {}\nredact:\n  - 'svc-[a-z]+'\n  - 'oci-layout'\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSetupRedaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *allConfig
+		wantActive bool
+		wantErr    string
+	}{
+		{name: "No config", cfg: nil},
+		{name: "No patterns", cfg: &allConfig{}},
+		{name: "Valid patterns", cfg: &allConfig{Redact: []string{`internal\.corp`}}, wantActive: true},
+		{name: "Invalid pattern", cfg: &allConfig{Redact: []string{`(`}}, wantErr: "invalid redact configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			err := setupRedaction(tt.cfg)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActive, activeRedactor != nil)
+		})
+	}
+}
+
+func TestRunAll_RedactsJSONOutput(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRedactConfig(t)
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "svc-deploy"})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.NotContains(t, out, "svc-deploy")
+	assert.NotContains(t, out, "oci-layout")
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.True(t, result.Redacted)
+	assert.Contains(t, result.Image, "[REDACTED]")
+	require.Len(t, result.Checks, 1)
+	assert.True(t, result.Checks[0].Redacted)
+	details, ok := result.Checks[0].Details.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "[REDACTED]", details["user"])
+}
+
+func TestRunAll_RedactsTextOutput(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRedactConfig(t)
+
+	imageRef := createTestImage(t, testImageOptions{user: "svc-deploy"})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, out, "Running 1 checks on image")
+	assert.Contains(t, out, "[REDACTED]")
+	assert.NotContains(t, out, "svc-deploy")
+	assert.NotContains(t, out, "oci-layout")
+}
+
+func TestRedactingFormatter(t *testing.T) {
+	resetAllGlobals(t)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	require.NoError(t, setupRedaction(&allConfig{Redact: []string{`internal\.corp`}}))
+	log.WithField("image", "registry.internal.corp/app").Warn("Pulling from registry.internal.corp")
+	assert.NotContains(t, buf.String(), "internal.corp")
+	assert.Contains(t, buf.String(), "[REDACTED]")
+
+	resetRedaction()
+	buf.Reset()
+	log.Warn("Pulling from registry.internal.corp")
+	assert.Contains(t, buf.String(), "registry.internal.corp", "reset must restore the original formatter")
+}
+
+func TestSetupRedaction_ConfigWithoutPatternsResets(t *testing.T) {
+	resetAllGlobals(t)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	require.NoError(t, setupRedaction(&allConfig{Redact: []string{`internal\.corp`}}))
+	require.NotNil(t, activeRedactor)
+
+	require.NoError(t, setupRedaction(&allConfig{}))
+	assert.Nil(t, activeRedactor, "the patterns of the previous config do not apply")
+	log.Warn("Pulling from registry.internal.corp")
+	assert.Contains(t, buf.String(), "registry.internal.corp", "the original formatter is restored")
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// reproducible makes JSON reports byte-identical across runs on the same
+// image digest and policy, so that they can be committed and diffed.
+var reproducible bool
+
+// reproducibleReport returns report with the values that change from run to
+// run zeroed, its lists sorted, and the fields that depend on the environment
+// omitted, when --reproducible is set. Lists whose order has a meaning, such
+// as the layers of an image or the arguments of its entrypoint, keep their
+// order. Reports of other types are returned unchanged.
+func reproducibleReport(report any) any {
+	if !reproducible {
+		return report
+	}
+	switch r := report.(type) {
+	case output.AllResult:
+		return reproducibleAllResult(r)
+	case output.BulkResult:
+		return reproducibleBulkResult(r)
+	case output.PromoteResult:
+		// The attestation records when the image was promoted.
+		r.Attestation = ""
+		r.Validation = reproducibleAllResult(r.Validation)
+		return r
+	}
+	return report
+}
+
+// reproducibleAllResult normalizes the report of one image. The annotation
+// digest is omitted because the annotation records when the image was
+// checked, the metadata version and commit because they identify the
+// check-image build installed in the environment, and the endpoint that
+// served the image because it depends on which mirrors were available.
+func reproducibleAllResult(r output.AllResult) output.AllResult {
+	r.Annotation = ""
+	r.ServedBy = ""
+	if r.Metadata != nil {
+		md := *r.Metadata
+		md.Version, md.Commit = "", ""
+		r.Metadata = &md
+	}
+	r.PolicyViolations = sorted(r.PolicyViolations)
+	r.Summary.Skipped = sortedFunc(r.Summary.Skipped, func(a, b output.SkippedCheck) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	checks := make([]output.CheckResult, len(r.Checks))
+	for i, c := range r.Checks {
+		checks[i] = reproducibleCheckResult(c)
+	}
+	slices.SortStableFunc(checks, func(a, b output.CheckResult) int {
+		return cmp.Compare(a.Check, b.Check)
+	})
+	r.Checks = checks
+	return r
+}
+
+// reproducibleBulkResult normalizes the reports of a bulk run and sorts them
+// by image, and the repositories by name.
+func reproducibleBulkResult(b output.BulkResult) output.BulkResult {
+	b.Images = reproducibleImages(b.Images)
+	repositories := make([]output.RepositoryResult, len(b.Repositories))
+	for i, repo := range b.Repositories {
+		repo.Images = reproducibleImages(repo.Images)
+		repositories[i] = repo
+	}
+	slices.SortStableFunc(repositories, func(a, b output.RepositoryResult) int {
+		return cmp.Compare(a.Repository, b.Repository)
+	})
+	if b.Repositories != nil {
+		b.Repositories = repositories
+	}
+	if b.Services != nil {
+		services := make(map[string]output.AllResult, len(b.Services))
+		for name, r := range b.Services {
+			services[name] = reproducibleAllResult(r)
+		}
+		b.Services = services
+	}
+	if b.Targets != nil {
+		targets := make(map[string]output.AllResult, len(b.Targets))
+		for name, r := range b.Targets {
+			targets[name] = reproducibleAllResult(r)
+		}
+		b.Targets = targets
+	}
+	return b
+}
+
+// reproducibleImages normalizes the reports of images and sorts them by image.
+func reproducibleImages(images []output.AllResult) []output.AllResult {
+	if images == nil {
+		return nil
+	}
+	normalized := make([]output.AllResult, len(images))
+	for i, r := range images {
+		normalized[i] = reproducibleAllResult(r)
+	}
+	slices.SortStableFunc(normalized, func(a, b output.AllResult) int {
+		return cmp.Compare(a.Image, b.Image)
+	})
+	return normalized
+}
+
+// reproducibleCheckResult normalizes the details of a check result. The age
+// of an image is zeroed because it depends on when the check ran, keeping its
+// creation date, and so is the run time of the smoke test.
+func reproducibleCheckResult(c output.CheckResult) output.CheckResult {
+	if c.Exception != nil {
+		e := *c.Exception
+		e.Checks = sorted(e.Checks)
+		c.Exception = &e
+	}
+	c.SuppressedFindings = sortedFunc(c.SuppressedFindings, func(a, b output.SuppressedFinding) int {
+		return cmp.Or(cmp.Compare(a.Subject, b.Subject), cmp.Compare(a.Rule, b.Rule))
+	})
+
+	switch d := c.Details.(type) {
+	case output.AgeDetails:
+		d.AgeDays = 0
+		c.Details = d
+	case output.PortsDetails:
+		d.ExposedPorts = sorted(d.ExposedPorts)
+		d.AllowedPorts = sorted(d.AllowedPorts)
+		d.UnauthorizedPorts = sorted(d.UnauthorizedPorts)
+		d.PrivilegedPorts = sorted(d.PrivilegedPorts)
+		d.FailedConstraints = sorted(d.FailedConstraints)
+		c.Details = d
+	case output.SecretsDetails:
+		d.EnvVarFindings = sortedFunc(d.EnvVarFindings, func(a, b output.EnvVarFinding) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Description, b.Description))
+		})
+		d.FileFindings = sortedFunc(d.FileFindings, func(a, b output.FileFinding) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.LayerIndex, b.LayerIndex), cmp.Compare(a.Description, b.Description))
+		})
+		c.Details = d
+	case output.LabelsDetails:
+		d.RequiredLabels = sortedFunc(d.RequiredLabels, func(a, b output.RequiredLabelCheck) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+		d.MissingLabels = sorted(d.MissingLabels)
+		d.InvalidLabels = sortedFunc(d.InvalidLabels, func(a, b output.InvalidLabelDetail) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+		c.Details = d
+	case output.PlatformDetails:
+		d.AllowedPlatforms = sorted(d.AllowedPlatforms)
+		c.Details = d
+	case output.EntrypointDetails:
+		d.Violations = sortedFunc(d.Violations, func(a, b output.EntrypointViolation) int {
+			return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Argument, b.Argument), cmp.Compare(a.Message, b.Message))
+		})
+		c.Details = d
+	case output.UserDetails:
+		d.BlockedUsers = sorted(d.BlockedUsers)
+		d.Violations = sortedFunc(d.Violations, func(a, b output.UserViolation) int {
+			return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Message, b.Message))
+		})
+		c.Details = d
+	case output.LazyPullDetails:
+		d.AcceptedFormats = sorted(d.AcceptedFormats)
+		c.Details = d
+	case output.ProvenanceDetails:
+		d.Provenance = sortedFunc(d.Provenance, func(a, b output.ProvenanceStatement) int {
+			return cmp.Or(cmp.Compare(a.Manifest, b.Manifest), cmp.Compare(a.Source, b.Source), cmp.Compare(a.PredicateType, b.PredicateType))
+		})
+		d.TrustedBuilders = sorted(d.TrustedBuilders)
+		d.SourceRepositories = sorted(d.SourceRepositories)
+		d.BuildTypes = sorted(d.BuildTypes)
+		d.TrustedKeys = sorted(d.TrustedKeys)
+		d.Violations = sortedFunc(d.Violations, func(a, b output.ProvenanceViolation) int {
+			return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Message, b.Message))
+		})
+		c.Details = d
+	case output.DriftDetails:
+		d.Differences = sortedFunc(d.Differences, func(a, b output.DriftDifference) int {
+			return cmp.Or(cmp.Compare(a.Field, b.Field), cmp.Compare(a.Key, b.Key), cmp.Compare(a.Kind, b.Kind))
+		})
+		c.Details = d
+	case output.SmokeDetails:
+		d.ElapsedSeconds = 0
+		c.Details = d
+	case output.EntropyDetails:
+		d.Findings = sortedFunc(d.Findings, func(a, b output.EntropyFinding) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.LayerIndex, b.LayerIndex))
+		})
+		c.Details = d
+	case output.ArchitectureDetails:
+		d.Mismatches = sortedFunc(d.Mismatches, func(a, b output.ArchitectureMismatch) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.LayerIndex, b.LayerIndex))
+		})
+		c.Details = d
+	}
+	return c
+}
+
+// sorted returns a sorted copy of s, leaving s, which other reports may
+// share, unchanged. A nil s stays nil.
+func sorted[S ~[]E, E cmp.Ordered](s S) S {
+	if s == nil {
+		return nil
+	}
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
+
+// sortedFunc returns a copy of s sorted by compare, keeping the order of
+// equal elements. A nil s stays nil.
+func sortedFunc[S ~[]E, E any](s S, compare func(a, b E) int) S {
+	if s == nil {
+		return nil
+	}
+	s = slices.Clone(s)
+	slices.SortStableFunc(s, compare)
+	return s
+}
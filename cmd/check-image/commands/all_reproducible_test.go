@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReproducibleReport(t *testing.T) {
+	resetAllGlobals(t)
+	report := output.AllResult{
+		Image:            "nginx:1.27",
+		Annotation:       "sha256:abc",
+		ServedBy:         "mirror.gcr.io",
+		PolicyViolations: []string{"b", "a"},
+		Checks: []output.CheckResult{
+			{Check: "user", Details: output.UserDetails{BlockedUsers: []string{"root", "admin"}}},
+			{Check: "age", Details: output.AgeDetails{CreatedAt: "2026-01-01T00:00:00Z", AgeDays: 12.5}},
+			{Check: "ports", Details: output.PortsDetails{ExposedPorts: []int{8080, 80}}},
+			{Check: "size", Details: output.SizeDetails{Layers: []output.LayerInfo{{Index: 1}, {Index: 0}}}},
+		},
+		Summary:  output.Summary{Skipped: []output.SkippedCheck{{Name: "secrets"}, {Name: "labels"}}},
+		Metadata: &output.ReportMetadata{Version: "v1.2.3", Commit: "abc123", ConfigHash: "sha256:def"},
+	}
+
+	assert.Equal(t, report, reproducibleReport(report), "reports are unchanged without --reproducible")
+
+	reproducible = true
+	got, ok := reproducibleReport(report).(output.AllResult)
+	require.True(t, ok)
+	assert.Empty(t, got.Annotation)
+	assert.Empty(t, got.ServedBy)
+	assert.Equal(t, &output.ReportMetadata{ConfigHash: "sha256:def"}, got.Metadata)
+	assert.Equal(t, []string{"a", "b"}, got.PolicyViolations)
+	assert.Equal(t, []output.SkippedCheck{{Name: "labels"}, {Name: "secrets"}}, got.Summary.Skipped)
+
+	require.Len(t, got.Checks, 4)
+	assert.Equal(t, output.AgeDetails{CreatedAt: "2026-01-01T00:00:00Z"}, got.Checks[0].Details)
+	assert.Equal(t, []int{80, 8080}, got.Checks[1].Details.(output.PortsDetails).ExposedPorts)
+	assert.Equal(t, []output.LayerInfo{{Index: 1}, {Index: 0}}, got.Checks[2].Details.(output.SizeDetails).Layers, "layers keep their order")
+	assert.Equal(t, []string{"admin", "root"}, got.Checks[3].Details.(output.UserDetails).BlockedUsers)
+
+	assert.Equal(t, []int{8080, 80}, report.Checks[2].Details.(output.PortsDetails).ExposedPorts, "the original report is not modified")
+	assert.Equal(t, "sha256:abc", report.Annotation)
+
+	bulk, ok := reproducibleReport(output.BulkResult{Images: []output.AllResult{{Image: "b"}, {Image: "a"}}}).(output.BulkResult)
+	require.True(t, ok)
+	assert.Equal(t, "a", bulk.Images[0].Image)
+	assert.Equal(t, "b", bulk.Images[1].Image)
+}
+
+func TestRunAll_ReproducibleReportsAreIdentical(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("checks:\n  age: {}\n  ports: {}\n"), 0600))
+	OutputFmt = output.FormatJSON
+	reproducible = true
+	imageRef := createTestImage(t, testImageOptions{
+		created:      time.Now().Add(-time.Hour),
+		exposedPorts: map[string]struct{}{"8080/tcp": {}, "443/tcp": {}},
+	})
+
+	run := func() string {
+		t.Helper()
+		return captureStdout(t, func() {
+			require.NoError(t, runAll(allCmd, imageRef))
+		})
+	}
+	first := run()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, first, run())
+	assert.Contains(t, first, `"age-days": 0`)
+}
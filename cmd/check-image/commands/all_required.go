@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// ociSourcePrefix marks a required-config source stored as an OCI artifact.
+const ociSourcePrefix = "oci://"
+
+// readRequiredConfigData loads the raw required-config document from an
+// http(s) URL, an oci:// artifact reference, or a local file path.
+func readRequiredConfigData(ctx context.Context, source string) ([]byte, error) {
+	switch {
+	case fileutil.IsURL(source):
+		return fileutil.ReadURL(ctx, source)
+	case strings.HasPrefix(source, ociSourcePrefix):
+		return imageutil.GetArtifactData(ctx, strings.TrimPrefix(source, ociSourcePrefix))
+	default:
+		return fileutil.ReadFileOrStdin(source)
+	}
+}
+
+func loadRequiredConfig(ctx context.Context, source string) (*allConfig, error) {
+	data, err := readRequiredConfigData(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read required config: %w", err)
+	}
+
+	// OCI references carry no file extension, so detect the format from content
+	// the same way stdin is handled.
+	formatPath := source
+	if strings.HasPrefix(source, ociSourcePrefix) {
+		formatPath = "-"
+	}
+
+	cfg, err := configParser(strictConfig)(data, formatPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse required config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// mergeRequiredChecks enables in local every check section that is present in
+// required but missing locally. Existing local sections are left untouched;
+// their values are overridden separately by applyConfigValues.
+func mergeRequiredChecks(local, required *allChecksConfig) {
+	lv := reflect.ValueOf(local).Elem()
+	rv := reflect.ValueOf(required).Elem()
+	for i := range lv.NumField() {
+		if lv.Field(i).IsNil() && !rv.Field(i).IsNil() {
+			lv.Field(i).Set(rv.Field(i))
+		}
+	}
+}
+
+// applyRequiredConfig loads the required config from source, applies its check
+// values on top of any local config and CLI flags, and records every attempt
+// by --skip or --include to leave out one of its checks as a policy violation.
+//
+// Required checks are merged into local (when non-nil), removed from skipMap,
+// and added to includeMap (when non-nil) so that downstream selection and
+// reporting treat them as enabled. The returned cleanup must always be
+// deferred, even when err != nil.
+func applyRequiredConfig(ctx context.Context, source string, local *allConfig, skipMap, includeMap map[string]bool) ([]string, func(), error) {
+	cfg, err := loadRequiredConfig(ctx, source)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	// A fresh command has no flags marked as changed, so every value from the
+	// required config is applied unconditionally and cannot be loosened locally.
+	cleanup, err := applyConfigValues(&cobra.Command{}, cfg)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	if local != nil {
+		mergeRequiredChecks(&local.Checks, &cfg.Checks)
+	}
+
+	var violations []string
+	for _, def := range buildCheckDefs(cfg, checkParams{}) {
+		if !def.enabled {
+			continue
+		}
+		if skipMap[def.name] || (includeMap != nil && !includeMap[def.name]) {
+			msg := fmt.Sprintf("check %q is required by the required config and cannot be skipped", def.name)
+			violations = append(violations, msg)
+			log.WithFields(log.Fields{
+				"check":  def.name,
+				"source": logutil.SanitizeLogValue(source),
+			}).Warn("Attempt to skip a required check")
+		}
+		delete(skipMap, def.name)
+		if includeMap != nil {
+			includeMap[def.name] = true
+		}
+	}
+
+	return violations, cleanup, nil
+}
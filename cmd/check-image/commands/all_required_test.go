@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRequiredConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "required.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestApplyRequiredConfig(t *testing.T) {
+	tests := []struct {
+		name               string
+		skipMap            map[string]bool
+		includeMap         map[string]bool
+		expectedViolations int
+		expectedSkip       map[string]bool
+		expectedInclude    map[string]bool
+	}{
+		{
+			name:               "No local selection",
+			expectedViolations: 0,
+		},
+		{
+			name:               "Skipping a required check",
+			skipMap:            map[string]bool{checkAge: true, checkSize: true},
+			expectedViolations: 1,
+			expectedSkip:       map[string]bool{checkSize: true},
+		},
+		{
+			name:               "Include without required check",
+			includeMap:         map[string]bool{checkSize: true},
+			expectedViolations: 2,
+			expectedInclude:    map[string]bool{checkSize: true, checkAge: true, checkHealthcheck: true},
+		},
+		{
+			name:               "Skipping several required checks",
+			skipMap:            map[string]bool{checkAge: true, checkHealthcheck: true},
+			expectedViolations: 2,
+			expectedSkip:       map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			path := writeRequiredConfig(t, "checks:\n  age:\n    max-age: 7\n  healthcheck: {}\n")
+
+			violations, cleanup, err := applyRequiredConfig(context.Background(), path, nil, tt.skipMap, tt.includeMap)
+			defer cleanup()
+			require.NoError(t, err)
+
+			assert.Len(t, violations, tt.expectedViolations)
+			assert.Equal(t, uint(7), maxAge)
+			if tt.expectedSkip != nil {
+				assert.Equal(t, tt.expectedSkip, tt.skipMap)
+			}
+			if tt.expectedInclude != nil {
+				assert.Equal(t, tt.expectedInclude, tt.includeMap)
+			}
+		})
+	}
+}
+
+func TestApplyRequiredConfig_MergesIntoLocalConfig(t *testing.T) {
+	resetAllGlobals(t)
+	path := writeRequiredConfig(t, "checks:\n  age:\n    max-age: 7\n  healthcheck: {}\n")
+
+	maxAge := uint(365)
+	local := &allConfig{Checks: allChecksConfig{Age: &ageCheckConfig{MaxAge: &maxAge}, Size: &sizeCheckConfig{}}}
+
+	_, cleanup, err := applyRequiredConfig(context.Background(), path, local, nil, nil)
+	defer cleanup()
+	require.NoError(t, err)
+
+	assert.NotNil(t, local.Checks.Size)
+	assert.NotNil(t, local.Checks.Healthcheck)
+	assert.Equal(t, uint(365), *local.Checks.Age.MaxAge, "existing local section is kept")
+}
+
+func TestApplyRequiredConfig_InvalidSource(t *testing.T) {
+	resetAllGlobals(t)
+
+	_, cleanup, err := applyRequiredConfig(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), nil, nil, nil)
+	defer cleanup()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read required config")
+}
+
+func TestRunAll_RequiredConfigSkipIsPolicyViolation(t *testing.T) {
+	resetAllGlobals(t)
+	requiredConfig = writeRequiredConfig(t, "checks:\n  age:\n    max-age: 30\n")
+	includeChecks = "healthcheck"
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{
+		created:     time.Now().Add(-24 * time.Hour),
+		healthcheck: nil,
+	})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.False(t, result.Passed)
+	require.Len(t, result.PolicyViolations, 1)
+	assert.Contains(t, result.PolicyViolations[0], `"age"`)
+
+	var names []string
+	for _, c := range result.Checks {
+		names = append(names, c.Check)
+	}
+	assert.Contains(t, names, checkAge)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_RequiredConfigFromURL(t *testing.T) {
+	resetAllGlobals(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("checks:\n  age:\n    max-age: 30\n"))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	cfgFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("checks:\n  user: {}\n"), 0600))
+	configFile = cfgFile
+	requiredConfig = server.URL + "/required.yaml"
+
+	imageRef := createTestImage(t, testImageOptions{
+		user:    "1000",
+		created: time.Now().Add(-24 * time.Hour),
+	})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, out, "Running 2 checks")
+	assert.Contains(t, out, "── age")
+	assert.NotContains(t, out, "Policy violation")
+	assert.Equal(t, ValidationSucceeded, Result)
+}
+
+func TestRunAll_RequiredConfigFromOCIArtifact(t *testing.T) {
+	resetAllGlobals(t)
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	layer := static.NewLayer([]byte("checks:\n  age:\n    max-age: 30\n"), types.MediaType("application/yaml"))
+	artifact, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/org/required:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, artifact))
+
+	requiredConfig = "oci://" + ref.String()
+	skipChecks = "age,registry,labels,platform,ports"
+
+	imageRef := createTestImage(t, testImageOptions{created: time.Now().Add(-24 * time.Hour)})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	assert.Contains(t, out, "Policy violation")
+	assert.Contains(t, out, "── age")
+	assert.Equal(t, ValidationFailed, Result)
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// Values of --show.
+const (
+	showAll    = "all"
+	showFailed = "failed"
+)
+
+// showSections selects the check sections printed in text mode.
+var showSections = showAll
+
+// validateShowFlag rejects values of --show other than all and failed.
+func validateShowFlag(show string) error {
+	switch show {
+	case showAll, showFailed:
+		return nil
+	}
+	return fmt.Errorf("unsupported --show value %q, valid values are: %s, %s", show, showFailed, showAll)
+}
+
+// showSection reports whether the text section of result is printed: always
+// with --show all, and only for failed and errored checks with --show failed.
+// The JSON and CSV output always hold every check.
+func showSection(show string, result output.CheckResult) bool {
+	return show != showFailed || result.Status() == output.StatusFailed
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/signing"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultSignatureFile is where the detached report signature is written
+// and where verify-report looks for it by default.
+const defaultSignatureFile = "check-image-report.jws"
+
+var signResults string
+var signatureOutput string
+
+// validateSigningFlags fails early when --sign-results cannot be honoured, so a
+// misconfigured pipeline does not run every check before reporting the error.
+func validateSigningFlags(outFmt output.Format) error {
+	if signResults == "" {
+		return nil
+	}
+	if outFmt != output.FormatJSON {
+		return fmt.Errorf("--sign-results requires --output json")
+	}
+	if _, err := signing.LoadPrivateKey(signResults); err != nil {
+		return fmt.Errorf("unable to load signing key: %w", err)
+	}
+	return nil
+}
+
+// writeReport renders a JSON report (the all command's AllResult, or a
+// result embedding it) to stdout, or to --output-file. When --sign-results is set, the exact bytes
+// written are signed and the detached JWS is stored in --signature-output.
+// With --reproducible, the report is normalized first.
+func writeReport(report any) error {
+	report = reproducibleReport(report)
+	if signResults == "" {
+		return output.RenderJSON(reportOutput(), report)
+	}
+
+	var buf bytes.Buffer
+	if err := output.RenderJSON(&buf, report); err != nil {
+		return err
+	}
+
+	key, err := signing.LoadPrivateKey(signResults)
+	if err != nil {
+		return fmt.Errorf("unable to load signing key: %w", err)
+	}
+	jws, err := signing.SignDetached(key, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(signatureOutput, []byte(jws+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write signature file: %w", err)
+	}
+	log.WithField("file", signatureOutput).Debug("Report signature written")
+
+	_, err = reportOutput().Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/exceptions"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// labelSuppressionsConfig allows images to suppress findings of the listed
+// checks with check-image.suppress labels. Without it, suppression labels
+// are ignored.
+type labelSuppressionsConfig struct {
+	// Checks lists the checks whose findings labels may suppress.
+	Checks []string `json:"checks" yaml:"checks"`
+	// Subjects, when set, lists the path.Match patterns of the subjects
+	// labels may suppress findings about; suppressions of other subjects are
+	// ignored. Label subjects themselves are literal.
+	Subjects []string `json:"subjects,omitempty" yaml:"subjects,omitempty"`
+	// RequireExpiry ignores suppressions without an expires date.
+	RequireExpiry bool `json:"require-expiry,omitempty" yaml:"require-expiry,omitempty"`
+}
+
+// activeSuppressions holds the suppressions the image being validated
+// declares that the config file allows, set by setupLabelSuppressions; nil
+// when there are none.
+var activeSuppressions []exceptions.Suppression
+
+// validateLabelSuppressions checks the label-suppressions of cfg.
+func validateLabelSuppressions(cfg *allConfig) error {
+	ls := cfg.LabelSuppressions
+	if ls == nil {
+		return nil
+	}
+	if len(ls.Checks) == 0 {
+		return fmt.Errorf("label-suppressions.checks must list at least one check")
+	}
+	for _, c := range ls.Checks {
+		if !slices.Contains(validCheckNames, c) {
+			return fmt.Errorf("invalid label-suppressions.checks: unknown check %q, valid checks are: %s", c, strings.Join(validCheckNames, ", "))
+		}
+	}
+	for _, p := range ls.Subjects {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid label-suppressions.subjects pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// subjectAllowed reports whether the label-suppressions of the config allow
+// suppressions of subject.
+func (ls *labelSuppressionsConfig) subjectAllowed(subject string) bool {
+	if len(ls.Subjects) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(ls.Subjects, func(p string) bool {
+		ok, _ := path.Match(p, subject)
+		return ok
+	})
+}
+
+// setupLabelSuppressions reads the suppression labels of imageName when the
+// config file allows them. Suppressions that are malformed, expired, for
+// checks or subjects the config does not allow, or without a required expiry
+// are ignored with a warning, so the findings they name fail as usual.
+func setupLabelSuppressions(ctx context.Context, cfg *allConfig, imageName string) {
+	activeSuppressions = nil
+	if cfg == nil || cfg.LabelSuppressions == nil {
+		return
+	}
+	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"image": logutil.SanitizeLogValue(imageName),
+			"error": err,
+		}).Warn("Unable to read the image labels, suppression labels are not applied")
+		return
+	}
+	cleanup()
+
+	suppressions, errs := exceptions.ParseSuppressionLabels(config.Config.Labels, validCheckNames)
+	for _, err := range errs {
+		log.WithField("error", logutil.SanitizeLogValue(err.Error())).Warn("Ignoring invalid suppression label")
+	}
+	now := time.Now()
+	for _, s := range suppressions {
+		fields := log.Fields{"label": s.Label, "check": s.Check, "subject": logutil.SanitizeLogValue(s.Subject)}
+		switch {
+		case !slices.Contains(cfg.LabelSuppressions.Checks, s.Check):
+			log.WithFields(fields).Warn("Ignoring suppression label for a check the config does not allow suppressions of")
+		case !cfg.LabelSuppressions.subjectAllowed(s.Subject):
+			log.WithFields(fields).Warn("Ignoring suppression label for a subject the config does not allow suppressions of")
+		case cfg.LabelSuppressions.RequireExpiry && s.Expires == "":
+			log.WithFields(fields).Warn("Ignoring suppression label without the expiry the config requires")
+		case s.Expired(now):
+			fields["expires"] = s.Expires
+			log.WithFields(fields).Warn("Ignoring expired suppression label")
+		default:
+			activeSuppressions = append(activeSuppressions, s)
+		}
+	}
+}
+
+// applyLabelSuppressions records the findings of a failed check that the
+// active suppressions cover, and passes the check when they cover every
+// finding. Execution errors and findings without a subject are never
+// suppressed.
+func applyLabelSuppressions(result *output.CheckResult) {
+	if len(activeSuppressions) == 0 || result.Passed || result.Error != "" {
+		return
+	}
+	findings := output.CheckFindings(*result)
+	var suppressed []output.SuppressedFinding
+	for _, f := range findings {
+		i := slices.IndexFunc(activeSuppressions, func(s exceptions.Suppression) bool {
+			return s.Matches(result.Check, f.Subject)
+		})
+		if i < 0 {
+			continue
+		}
+		s := activeSuppressions[i]
+		suppressed = append(suppressed, output.SuppressedFinding{
+			Rule:    f.Rule,
+			Subject: f.Subject,
+			Label:   s.Label,
+			Expires: s.Expires,
+			Reason:  s.Reason,
+		})
+	}
+	if len(suppressed) == 0 {
+		return
+	}
+	result.SuppressedFindings = suppressed
+	log.WithFields(log.Fields{"check": result.Check, "suppressed": len(suppressed), "findings": len(findings)}).
+		Info("Findings suppressed by image labels")
+	if len(suppressed) == len(findings) {
+		result.Passed = true
+		result.Message = fmt.Sprintf("%s (%d suppressed by image labels)", result.Message, len(suppressed))
+	}
+}
+
+// printSuppressedFindings lists the findings suppressed by image labels in
+// text mode.
+func printSuppressedFindings(w io.Writer, result *output.CheckResult) {
+	for _, f := range result.SuppressedFindings {
+		line := fmt.Sprintf("Suppressed: %s by label %s", f.Subject, f.Label)
+		if f.Expires != "" {
+			line += " until " + f.Expires
+		}
+		if f.Reason != "" {
+			line += " (" + f.Reason + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
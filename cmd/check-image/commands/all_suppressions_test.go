@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/exceptions"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllConfig_LabelSuppressions(t *testing.T) {
+	cfg, err := parseAllConfig([]byte("label-suppressions:\n  checks: [secrets, ports]\n  subjects: [/app/test/*]\n  require-expiry: true\n"), "config.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.LabelSuppressions)
+	assert.Equal(t, []string{"secrets", "ports"}, cfg.LabelSuppressions.Checks)
+	assert.Equal(t, []string{"/app/test/*"}, cfg.LabelSuppressions.Subjects)
+	assert.True(t, cfg.LabelSuppressions.RequireExpiry)
+	assert.True(t, cfg.LabelSuppressions.subjectAllowed("/app/test/key.pem"))
+	assert.False(t, cfg.LabelSuppressions.subjectAllowed("/app/key.pem"))
+
+	tests := map[string]string{
+		`{"label-suppressions": {}}`:                                              "label-suppressions.checks must list at least one check",
+		`{"label-suppressions": {"checks": ["cve"]}}`:                             `unknown check "cve"`,
+		`{"label-suppressions": {"checks": ["secrets"], "subjects": ["/app/["]}}`: `invalid label-suppressions.subjects pattern "/app/["`,
+	}
+	for config, want := range tests {
+		_, err := parseAllConfig([]byte(config), "config.json")
+		require.Error(t, err, config)
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestApplyLabelSuppressions(t *testing.T) {
+	resetAllGlobals(t)
+	failed := output.CheckResult{
+		Check:   "ports",
+		Message: "Image exposes unauthorized ports",
+		Details: output.PortsDetails{ExposedPorts: []int{8080, 9090}, UnauthorizedPorts: []int{8080, 9090}, FailedConstraints: []string{"allowed-ports"}},
+	}
+
+	result := failed
+	applyLabelSuppressions(&result)
+	assert.Equal(t, failed, result, "without suppressions results are unchanged")
+
+	activeSuppressions = []exceptions.Suppression{{Label: "check-image.suppress", Check: "ports", Subject: "8080", Expires: "2099-01-01", Reason: "debug port"}}
+	result = failed
+	applyLabelSuppressions(&result)
+	assert.False(t, result.Passed, "findings that are not suppressed still fail the check")
+	assert.Equal(t, []output.SuppressedFinding{
+		{Rule: "allowed-ports", Subject: "8080", Label: "check-image.suppress", Expires: "2099-01-01", Reason: "debug port"},
+	}, result.SuppressedFindings)
+
+	activeSuppressions = append(activeSuppressions, exceptions.Suppression{Label: "check-image.suppress.metrics", Check: "ports", Subject: "9090"})
+	result = failed
+	applyLabelSuppressions(&result)
+	assert.True(t, result.Passed)
+	assert.Len(t, result.SuppressedFindings, 2)
+	assert.Equal(t, "Image exposes unauthorized ports (2 suppressed by image labels)", result.Message)
+
+	errored := output.CheckResult{Check: "ports", Error: "boom"}
+	applyLabelSuppressions(&errored)
+	assert.Nil(t, errored.SuppressedFindings, "execution errors are never suppressed")
+}
+
+func TestRunAll_LabelSuppressions(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	OutputFmt = output.FormatJSON
+	imageRef := createTestImage(t, testImageOptions{
+		created:      time.Now(),
+		exposedPorts: map[string]struct{}{"8080/tcp": {}},
+		labels: map[string]string{
+			"check-image.suppress":      "ports:8080;reason=debug port",
+			"check-image.suppress.user": "user:root",
+		},
+	})
+
+	run := func(config string) output.CheckResult {
+		t.Helper()
+		Result = ValidationSkipped
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0600))
+		captured := captureStdout(t, func() {
+			require.NoError(t, runAll(allCmd, imageRef))
+		})
+		var report output.AllResult
+		require.NoError(t, json.Unmarshal([]byte(captured), &report))
+		require.Len(t, report.Checks, 1)
+		return report.Checks[0]
+	}
+
+	result := run("checks:\n  ports:\n    allowed-ports: [443]\n")
+	assert.False(t, result.Passed, "suppression labels are ignored unless the config allows them")
+	assert.Empty(t, result.SuppressedFindings)
+
+	result = run("checks:\n  ports:\n    allowed-ports: [443]\nlabel-suppressions:\n  checks: [ports]\n")
+	assert.True(t, result.Passed)
+	assert.Equal(t, ValidationSucceeded, Result)
+	assert.Equal(t, []output.SuppressedFinding{{Rule: "allowed-ports", Subject: "8080", Label: "check-image.suppress", Reason: "debug port"}}, result.SuppressedFindings)
+
+	result = run("checks:\n  ports:\n    allowed-ports: [443]\nlabel-suppressions:\n  checks: [ports]\n  require-expiry: true\n")
+	assert.False(t, result.Passed, "suppressions without the required expiry are ignored")
+
+	result = run("checks:\n  ports:\n    allowed-ports: [443]\nlabel-suppressions:\n  checks: [ports]\n  subjects: [\"443\"]\n")
+	assert.False(t, result.Passed, "suppressions of subjects the config does not list are ignored")
+}
+
+func TestRunAll_LabelSuppressions_WildcardIgnored(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	OutputFmt = output.FormatJSON
+	require.NoError(t, os.WriteFile(configFile, []byte("checks:\n  ports:\n    allowed-ports: [443]\nlabel-suppressions:\n  checks: [ports]\n"), 0600))
+	imageRef := createTestImage(t, testImageOptions{
+		created:      time.Now(),
+		exposedPorts: map[string]struct{}{"8080/tcp": {}},
+		labels:       map[string]string{"check-image.suppress": "ports:*"},
+	})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+	var report output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &report))
+	require.Len(t, report.Checks, 1)
+	assert.False(t, report.Checks[0].Passed, "a wildcard label subject suppresses nothing")
+	assert.Empty(t, report.Checks[0].SuppressedFindings)
+	assert.Equal(t, ValidationFailed, Result)
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Placeholders of --image-template.
+const (
+	servicePlaceholder = "{service}"
+	tagPlaceholder     = "{tag}"
+)
+
+var imageTemplate string
+var serviceList string
+var imageTag string
+
+var templatePlaceholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateImageTemplate checks that --image-template, --service-list, and
+// --image-tag are used together and consistently, instead of an image
+// argument.
+func validateImageTemplate(imageName string) error {
+	if imageTemplate == "" {
+		if serviceList != "" || imageTag != "" {
+			return fmt.Errorf("--service-list and --image-tag require --image-template")
+		}
+		if imageName == "" {
+			return fmt.Errorf("an image argument, - to read a list of images from stdin, --from-sbom, --from-lockfile, or --image-template with --service-list is required")
+		}
+		return nil
+	}
+
+	if imageName != "" {
+		return fmt.Errorf("--image-template cannot be combined with an image argument")
+	}
+	if serviceList == "" {
+		return fmt.Errorf("--image-template requires --service-list")
+	}
+	for _, p := range templatePlaceholderRe.FindAllString(imageTemplate, -1) {
+		if p != servicePlaceholder && p != tagPlaceholder {
+			return fmt.Errorf("unknown placeholder %s in --image-template, valid placeholders are: %s, %s", p, servicePlaceholder, tagPlaceholder)
+		}
+	}
+	if !strings.Contains(imageTemplate, servicePlaceholder) {
+		return fmt.Errorf("--image-template must contain the %s placeholder", servicePlaceholder)
+	}
+	hasTag := strings.Contains(imageTemplate, tagPlaceholder)
+	if hasTag && imageTag == "" {
+		return fmt.Errorf("--image-template contains %s, which requires --image-tag", tagPlaceholder)
+	}
+	if !hasTag && imageTag != "" {
+		return fmt.Errorf("--image-tag requires the %s placeholder in --image-template", tagPlaceholder)
+	}
+	return nil
+}
+
+// expandImageTemplate returns the image of service.
+func expandImageTemplate(service string) string {
+	return strings.NewReplacer(servicePlaceholder, service, tagPlaceholder, imageTag).Replace(imageTemplate)
+}
+
+// runAllServices validates the image of every service of --service-list,
+// built from --image-template, and renders the results keyed by service.
+func runAllServices(cmd *cobra.Command) error {
+	if serviceList == "-" {
+		if err := validateBulkStdin(); err != nil {
+			return err
+		}
+	}
+	data, err := fileutil.ReadFileOrStdin(serviceList)
+	if err != nil {
+		return fmt.Errorf("error reading service list: %w", err)
+	}
+	// Service lists share the format of image lists.
+	services := parseImageList(string(data))
+	if len(services) == 0 {
+		return fmt.Errorf("no services to validate were read from %s", serviceList)
+	}
+	images := make([]string, len(services))
+	for i, service := range services {
+		images[i] = expandImageTemplate(service)
+	}
+	log.WithField("services", len(services)).Info("Validating service images")
+
+	bulk, err := validateBulkImages(cmd, images)
+	if err != nil {
+		return err
+	}
+	reports := bulk.Images
+	bulk.Services = make(map[string]output.AllResult, len(services))
+	for i, service := range services {
+		bulk.Services[service] = reports[i]
+	}
+	bulk.Images = nil
+	return renderBulk(bulk, reports)
+}
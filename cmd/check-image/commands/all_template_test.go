@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImageTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		template  string
+		services  string
+		tag       string
+		wantError string
+	}{
+		{name: "image argument", image: "nginx:latest"},
+		{name: "template with tag", template: "ghcr.io/org/{service}:{tag}", services: "services.txt", tag: "v1.2.0"},
+		{name: "template without tag", template: "ghcr.io/org/{service}:latest", services: "services.txt"},
+		{name: "neither image nor template", wantError: "an image argument, - to read a list of images from stdin, --from-sbom, --from-lockfile, or --image-template with --service-list is required"},
+		{name: "service list without template", image: "nginx:latest", services: "services.txt", wantError: "--service-list and --image-tag require --image-template"},
+		{name: "template and image", image: "nginx:latest", template: "ghcr.io/org/{service}", services: "services.txt", wantError: "cannot be combined with an image argument"},
+		{name: "template without service list", template: "ghcr.io/org/{service}", wantError: "--image-template requires --service-list"},
+		{name: "missing service placeholder", template: "ghcr.io/org/app:{tag}", services: "services.txt", tag: "v1", wantError: "must contain the {service} placeholder"},
+		{name: "unknown placeholder", template: "ghcr.io/{org}/{service}", services: "services.txt", wantError: "unknown placeholder {org}"},
+		{name: "tag placeholder without tag", template: "ghcr.io/org/{service}:{tag}", services: "services.txt", wantError: "requires --image-tag"},
+		{name: "tag without placeholder", template: "ghcr.io/org/{service}", services: "services.txt", tag: "v1", wantError: "--image-tag requires the {tag} placeholder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			imageTemplate, serviceList, imageTag = tt.template, tt.services, tt.tag
+
+			err := validateImageTemplate(tt.image)
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantError)
+		})
+	}
+}
+
+func TestExpandImageTemplate(t *testing.T) {
+	resetAllGlobals(t)
+	imageTemplate = "ghcr.io/org/{service}:{tag}-{service}"
+	imageTag = "v1.2.0"
+	assert.Equal(t, "ghcr.io/org/api:v1.2.0-api", expandImageTemplate("api"))
+}
+
+func TestRunAll_ImageTemplate_KeyedByService(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	base := t.TempDir()
+	require.NoError(t, os.Symlink(createTestOCILayout(t, "v1", testImageOptions{user: "root", created: time.Now()}), filepath.Join(base, "api")))
+	require.NoError(t, os.Symlink(createTestOCILayout(t, "v1", testImageOptions{user: "1000", created: time.Now()}), filepath.Join(base, "web")))
+	serviceList = filepath.Join(t.TempDir(), "services.txt")
+	require.NoError(t, os.WriteFile(serviceList, []byte("# release services\napi\nweb\n"), 0600))
+	imageTemplate = "oci:" + base + "/{service}:{tag}"
+	imageTag = "v1"
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, ""))
+	})
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.Empty(t, result.Images)
+	require.Len(t, result.Services, 2)
+	assert.Equal(t, "oci:"+base+"/api:v1", result.Services["api"].Image)
+	assert.False(t, result.Services["api"].Passed)
+	assert.True(t, result.Services["web"].Passed)
+	assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1}, result.Summary)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAll_ImageTemplate_TextSummary(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+
+	base := t.TempDir()
+	require.NoError(t, os.Symlink(createTestOCILayout(t, "latest", testImageOptions{user: "root", created: time.Now()}), filepath.Join(base, "api")))
+	serviceList = filepath.Join(t.TempDir(), "services.txt")
+	require.NoError(t, os.WriteFile(serviceList, []byte("api\n"), 0600))
+	imageTemplate = "oci:" + base + "/{service}:latest"
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, ""))
+	})
+
+	assert.Contains(t, captured, "Validated 1 services: 0 passed, 1 failed")
+	assert.Contains(t, captured, "Failed: api (oci:"+base+"/api:latest)")
+}
+
+func TestRunAll_ImageTemplate_EmptyServiceList(t *testing.T) {
+	resetAllGlobals(t)
+	serviceList = filepath.Join(t.TempDir(), "services.txt")
+	require.NoError(t, os.WriteFile(serviceList, []byte("# nothing yet\n"), 0600))
+	imageTemplate = "ghcr.io/org/{service}:latest"
+
+	err := runAll(allCmd, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no services to validate were read from")
+}
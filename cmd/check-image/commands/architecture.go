@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/elfarch"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// defaultMaxBinaries is the number of ELF binaries the architecture check
+// samples by default.
+const defaultMaxBinaries = 100
+
+// checkArchitectureFlag enables the opt-in architecture check of the all
+// command.
+var checkArchitectureFlag bool
+var maxBinaries uint = defaultMaxBinaries
+
+var architectureCmd = &cobra.Command{
+	Use:   "architecture image",
+	Short: "Validate that the binaries of the image match its declared architecture",
+	Long: `Validate that the binaries of the image match its declared architecture.
+
+ELF executables and shared libraries are sampled from the layers, from the
+top layer down so that the binaries added on top of the base image come
+first, and the machine each was built for is compared with the architecture
+of the image config. The check fails when any sampled binary was built for
+another architecture, e.g. an image that declares linux/arm64 but ships amd64
+binaries copied from the build host. Up to max-binaries binaries are
+inspected (default 100, 0 for all).
+
+` + imageArgFormatsDoc,
+	Example: `  check-image architecture nginx:latest --platform linux/arm64
+  check-image architecture registry.example.com/app:1.0 --max-binaries 0 -o json
+  check-image architecture oci:/path/to/layout:1.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		return runCheckCmd(checkArchitecture, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runArchitecture(ctx, img, maxBinaries)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(architectureCmd)
+	architectureCmd.Flags().UintVar(&maxBinaries, "max-binaries", defaultMaxBinaries, "Maximum number of ELF binaries to inspect, 0 for all (optional)")
+}
+
+func runArchitecture(ctx context.Context, imageName string, maxBinaries uint) (*output.CheckResult, error) {
+	image, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if config.Architecture == "" {
+		return nil, fmt.Errorf("image config declares no architecture")
+	}
+
+	scan, err := elfarch.ScanLayers(ctx, image, config.Architecture, maxBinaries)
+	if err != nil {
+		return nil, err
+	}
+
+	passed := len(scan.Mismatches) == 0
+	var msg string
+	switch {
+	case scan.Inspected == 0:
+		msg = "No ELF binaries found"
+	case passed:
+		msg = fmt.Sprintf("All %d inspected binaries are built for %s", scan.Inspected, config.Architecture)
+	default:
+		msg = fmt.Sprintf("%d of %d inspected binaries are not built for %s", len(scan.Mismatches), scan.Inspected, config.Architecture)
+	}
+
+	return &output.CheckResult{
+		Check:   checkArchitecture,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: output.ArchitectureDetails{
+			Architecture:      config.Architecture,
+			MaxBinaries:       maxBinaries,
+			InspectedBinaries: scan.Inspected,
+			Architectures:     scan.Architectures,
+			Mismatches:        scan.Mismatches,
+		},
+	}, nil
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elfBinary returns the start of a little-endian 64-bit ELF executable built
+// for machine.
+func elfBinary(machine elf.Machine) string {
+	head := make([]byte, 64)
+	copy(head, elf.ELFMAG)
+	head[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	head[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	binary.LittleEndian.PutUint16(head[16:], uint16(elf.ET_EXEC))
+	binary.LittleEndian.PutUint16(head[18:], uint16(machine))
+	return string(head)
+}
+
+func TestArchitectureCommand(t *testing.T) {
+	assert.Equal(t, "architecture image", architectureCmd.Use)
+	assert.Error(t, architectureCmd.Args(architectureCmd, []string{}))
+	assert.NoError(t, architectureCmd.Args(architectureCmd, []string{"image"}))
+
+	flag := architectureCmd.Flags().Lookup("max-binaries")
+	require.NotNil(t, flag)
+	assert.Equal(t, "100", flag.DefValue)
+}
+
+func TestRunArchitecture(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{
+		os:           "linux",
+		architecture: "arm64",
+		layerFiles: []map[string]string{
+			{"bin/sh": elfBinary(elf.EM_AARCH64), "etc/motd": "hello"},
+			{"usr/local/bin/app": elfBinary(elf.EM_X86_64)},
+		},
+	})
+
+	result, err := runArchitecture(context.Background(), imageRef, defaultMaxBinaries)
+	require.NoError(t, err)
+	assert.Equal(t, checkArchitecture, result.Check)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "1 of 2 inspected binaries are not built for arm64", result.Message)
+	details := result.Details.(output.ArchitectureDetails)
+	assert.Equal(t, "arm64", details.Architecture)
+	assert.Equal(t, map[string]int{"arm64": 1, "amd64": 1}, details.Architectures)
+	require.Len(t, details.Mismatches, 1)
+	assert.Equal(t, "usr/local/bin/app", details.Mismatches[0].Path)
+	assert.Equal(t, "amd64", details.Mismatches[0].Architecture)
+}
+
+func TestRunArchitecture_Passed(t *testing.T) {
+	matching := createTestImage(t, testImageOptions{
+		architecture: "amd64",
+		layerFiles:   []map[string]string{{"usr/local/bin/app": elfBinary(elf.EM_X86_64)}},
+	})
+	result, err := runArchitecture(context.Background(), matching, defaultMaxBinaries)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "All 1 inspected binaries are built for amd64", result.Message)
+
+	scripts := createTestImage(t, testImageOptions{
+		architecture: "amd64",
+		layerFiles:   []map[string]string{{"app/start.sh": "#!/bin/sh\necho started\n"}},
+	})
+	result, err = runArchitecture(context.Background(), scripts, defaultMaxBinaries)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "No ELF binaries found", result.Message)
+}
+
+func TestRunArchitecture_ImageError(t *testing.T) {
+	_, err := runArchitecture(context.Background(), "oci:/nonexistent:latest", defaultMaxBinaries)
+	require.Error(t, err)
+}
+
+func TestBuildCheckDefs_ArchitectureOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	enabled := func(p checkParams, cfg *allConfig) bool {
+		for _, def := range buildCheckDefs(cfg, p) {
+			if def.name == checkArchitecture {
+				return def.enabled
+			}
+		}
+		t.Fatal("architecture check not defined")
+		return false
+	}
+
+	assert.False(t, enabled(checkParams{}, nil))
+	assert.True(t, enabled(checkParams{checkArchitecture: true}, nil))
+	assert.False(t, enabled(checkParams{checkArchitecture: true}, &allConfig{}))
+	assert.True(t, enabled(checkParams{}, &allConfig{Checks: allChecksConfig{Architecture: &architectureCheckConfig{}}}))
+}
+
+func TestApplyArchitectureConfig(t *testing.T) {
+	resetAllGlobals(t)
+	limit := uint(5)
+	applyArchitectureConfig(allCmd, &architectureCheckConfig{MaxBinaries: &limit})
+	assert.Equal(t, uint(5), maxBinaries)
+
+	resetAllGlobals(t)
+	applyArchitectureConfig(allCmd, &architectureCheckConfig{})
+	assert.Equal(t, uint(defaultMaxBinaries), maxBinaries)
+}
+
+func TestRenderArchitectureText(t *testing.T) {
+	result := &output.CheckResult{
+		Check:   checkArchitecture,
+		Image:   "app:1.0",
+		Message: "1 of 12 inspected binaries are not built for arm64",
+		Details: output.ArchitectureDetails{
+			Architecture:      "arm64",
+			MaxBinaries:       100,
+			InspectedBinaries: 12,
+			Mismatches:        []output.ArchitectureMismatch{{Path: "usr/local/bin/app", LayerIndex: 3, Architecture: "amd64"}},
+		},
+	}
+
+	captured := captureStdout(t, func() { renderArchitectureText(stdout, result) })
+	assert.Contains(t, captured, "Checking binary architectures of image app:1.0")
+	assert.Contains(t, captured, "Declared architecture: arm64")
+	assert.Contains(t, captured, "Binaries inspected: 12")
+	assert.Contains(t, captured, "Layer 4:")
+	assert.Contains(t, captured, "usr/local/bin/app (amd64)")
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// Values of --artifacts.
+const (
+	artifactsError = "error"
+	artifactsSkip  = "skip"
+)
+
+// artifactsMode selects how checks handle OCI artifacts that are not
+// container images, such as Helm charts.
+var artifactsMode = artifactsError
+
+// validateArtifactsFlag rejects values of --artifacts other than error and
+// skip.
+func validateArtifactsFlag(mode string) error {
+	switch mode {
+	case artifactsError, artifactsSkip:
+		return nil
+	}
+	return fmt.Errorf("unsupported --artifacts value %q, valid values are: %s, %s", mode, artifactsError, artifactsSkip)
+}
+
+// artifactSkipResult returns the skipped result of check with --artifacts
+// skip when err reports that imageName is an OCI artifact other than a
+// container image. It returns false otherwise, and the error stands.
+func artifactSkipResult(check, imageName string, err error) (*output.CheckResult, bool) {
+	if artifactsMode != artifactsSkip {
+		return nil, false
+	}
+	artifact, ok := imageutil.ArtifactOf(err)
+	if !ok {
+		return nil, false
+	}
+	log.WithFields(log.Fields{"check": check, "artifact": artifact.String()}).Info("Check skipped, the image is not a container image")
+	return &output.CheckResult{
+		Check:      check,
+		Image:      imageName,
+		Passed:     true,
+		Message:    fmt.Sprintf("Check skipped, not a container image: %s", artifact),
+		Skipped:    true,
+		SkipReason: output.SkipReasonNotContainerImage,
+	}, true
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createHelmChartImage stores an artifact shaped like a Helm chart in an OCI
+// layout and returns its reference.
+func createHelmChartImage(t *testing.T) string {
+	t.Helper()
+	layoutPath := filepath.Join(t.TempDir(), "chart")
+	p, err := layout.Write(layoutPath, empty.Index)
+	require.NoError(t, err)
+	chart := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), "application/vnd.cncf.helm.config.v1+json")
+	require.NoError(t, p.AppendImage(chart))
+	digest, err := chart.Digest()
+	require.NoError(t, err)
+	return "oci:" + layoutPath + "@" + digest.String()
+}
+
+// imageLoadingCheck returns a check that fails when its image cannot be
+// loaded.
+func imageLoadingCheck(name string) checkDef {
+	return checkDef{
+		name: name,
+		run: func(ctx context.Context, imageName string) (*output.CheckResult, error) {
+			_, _, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+			if err != nil {
+				return nil, err
+			}
+			cleanup()
+			return &output.CheckResult{Check: name, Image: imageName, Passed: true}, nil
+		},
+	}
+}
+
+func TestValidateArtifactsFlag(t *testing.T) {
+	require.NoError(t, validateArtifactsFlag(artifactsError))
+	require.NoError(t, validateArtifactsFlag(artifactsSkip))
+
+	err := validateArtifactsFlag("route")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported --artifacts value "route", valid values are: error, skip`)
+}
+
+func TestRunSingleCheck_Artifact(t *testing.T) {
+	chart := createHelmChartImage(t)
+
+	t.Run("error by default", func(t *testing.T) {
+		resetAllGlobals(t)
+		got := runSingleCheck(context.Background(), imageLoadingCheck(checkUser), chart)
+		assert.False(t, got.Passed)
+		assert.Equal(t, "not-runnable", got.ErrorKind)
+		assert.Contains(t, got.Error, "not a runnable container image: Helm chart")
+		assert.Equal(t, ExecutionError, Result)
+	})
+
+	t.Run("skipped with --artifacts skip", func(t *testing.T) {
+		resetAllGlobals(t)
+		artifactsMode = artifactsSkip
+		got := runSingleCheck(context.Background(), imageLoadingCheck(checkUser), chart)
+		assert.True(t, got.Passed)
+		assert.True(t, got.Skipped)
+		assert.Equal(t, output.SkipReasonNotContainerImage, got.SkipReason)
+		assert.Equal(t, "Check skipped, not a container image: Helm chart (config media type application/vnd.cncf.helm.config.v1+json)", got.Message)
+		assert.Empty(t, got.Error)
+		assert.Equal(t, ValidationSkipped, Result)
+	})
+
+	t.Run("other errors stand", func(t *testing.T) {
+		resetAllGlobals(t)
+		artifactsMode = artifactsSkip
+		got := runSingleCheck(context.Background(), imageLoadingCheck(checkUser), "oci:"+t.TempDir()+":latest")
+		assert.False(t, got.Skipped)
+		assert.Equal(t, "not-found", got.ErrorKind)
+	})
+}
+
+func TestExecuteChecks_ArtifactSkipped(t *testing.T) {
+	resetAllGlobals(t)
+	artifactsMode = artifactsSkip
+	chart := createHelmChartImage(t)
+
+	// The renderers of the checks expect their details, which a skipped
+	// artifact does not have.
+	check := imageLoadingCheck(checkUser)
+	check.render = renderUserText
+	out := captureStdout(t, func() {
+		results := executeChecks(context.Background(), []checkDef{check}, chart, output.FormatText)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Skipped)
+	})
+	assert.Contains(t, out, "Check skipped, not a container image: Helm chart")
+}
+
+func TestRunCheckCmd_Artifact(t *testing.T) {
+	resetAllGlobals(t)
+	artifactsMode = artifactsSkip
+	chart := createHelmChartImage(t)
+
+	out := captureStdout(t, func() {
+		err := runCheckCmd(checkUser, imageLoadingCheck(checkUser).run, context.Background(), chart, output.FormatText)
+		require.NoError(t, err)
+	})
+	assert.Contains(t, out, "Check skipped, not a container image: Helm chart")
+	assert.Equal(t, ValidationSkipped, Result)
+
+	artifactsMode = artifactsError
+	err := runCheckCmd(checkUser, imageLoadingCheck(checkUser).run, context.Background(), chart, output.FormatText)
+	require.Error(t, err)
+	assert.Equal(t, imageutil.ErrorKindNotRunnable, imageutil.ErrorKindOf(err))
+}
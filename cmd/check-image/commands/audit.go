@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/audit"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/progress"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var auditStateFile string
+var auditMaxImages uint
+var auditShuffle bool
+var auditInterval time.Duration
+
+var auditCmd = &cobra.Command{
+	Use:   "audit repository",
+	Short: "Validate every tagged image of a registry repository",
+	Long: `Run the same checks as the all command on every image of a registry
+repository. Tags are listed and resolved to manifest digests; tags that share
+a digest are validated once.
+
+With --state-file, each validated digest is recorded with its outcome and
+failed checks as soon as its checks finish, so an interrupted audit resumes
+where it left off when run again with the same state file; failures recorded
+by earlier runs are included in the summary and the exit code. Use --max-images and --shuffle to validate a random sample
+of a large repository, and --interval to spread the registry requests out.
+With --coverage-report, a policy coverage report of the images validated in
+this run is written: the pass rate of each check, the rules failed by the most
+images, and the largest and oldest images, as JSON (.json) or as a Markdown
+(.md) or HTML (.html) dashboard.
+A progress line with the estimated time remaining and a final summary table
+are printed to stderr, unless --no-progress is set.`,
+	Example: `  check-image audit registry.example.com/org/app --config config.yaml
+  check-image audit registry.example.com/org/app -c config.yaml --state-file audit-state.json
+  check-image audit registry.example.com/org/app -c config.yaml --max-images 20 --shuffle --interval 2s -o json
+  check-image audit registry.example.com/org/app -c config.yaml --coverage-report coverage.json --coverage-report coverage.html`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := withReportFile(func() error { return runAudit(cmd, args[0]) }); err != nil {
+			return fmt.Errorf("audit operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	addAllCheckFlags(auditCmd)
+	auditCmd.Flags().StringVar(&auditStateFile, "state-file", "", "File recording validated digests, to resume an interrupted audit (optional)")
+	auditCmd.Flags().UintVar(&auditMaxImages, "max-images", 0, "Maximum number of images to validate in this run, 0 for all (optional)")
+	auditCmd.Flags().BoolVar(&auditShuffle, "shuffle", false, "Validate images in random order, e.g. to sample with --max-images (optional)")
+	auditCmd.Flags().DurationVar(&auditInterval, "interval", 0, "Wait between images to limit the registry request rate (optional)")
+	auditCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Do not print the progress line and summary table to stderr (optional)")
+	auditCmd.Flags().BoolVar(&auditTagHygiene, "tag-hygiene", false, "Check that latest points at the newest release tag and that immutable-looking tags were not re-pushed (optional)")
+	auditCmd.Flags().UintVar(&auditMaxTagIssues, "max-tag-issues", 0, "Maximum number of tag hygiene issues before the audit fails (optional)")
+	auditCmd.Flags().StringSliceVar(&auditCoverageReports, "coverage-report", nil, "Files to write the policy coverage report to, formatted by extension: .json, .md or .html (optional)")
+	auditCmd.Flags().UintVar(&auditCoverageTop, "coverage-top", 10, "Number of entries of the failing rule and largest and oldest image rankings of the coverage report, 0 for all (optional)")
+}
+
+func runAudit(cmd *cobra.Command, repository string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := validateCoverageReports(); err != nil {
+		return err
+	}
+
+	var state *audit.State
+	if auditStateFile != "" {
+		var err error
+		if state, err = audit.LoadState(auditStateFile); err != nil {
+			return err
+		}
+	}
+
+	tagged, err := imageutil.ListRepository(ctx, repository)
+	if err != nil {
+		return err
+	}
+	tags := tagDigests(tagged)
+	images := repositoryImages(repository, tagged)
+	selected, resumed := audit.Select(images, state, audit.Options{
+		MaxImages: int(auditMaxImages),
+		Shuffle:   auditShuffle,
+	})
+
+	log.WithFields(log.Fields{
+		"repository": logutil.SanitizeLogValue(repository),
+		"images":     len(images),
+		"completed":  resumed,
+		"selected":   len(selected),
+	}).Info("Auditing repository")
+
+	if OutputFmt == output.FormatCSV {
+		if err := output.RenderCSV(stdout, nil); err != nil {
+			return err
+		}
+	}
+
+	if auditTagHygiene {
+		if err := runTagHygiene(repository, tags, state, auditMaxTagIssues); err != nil {
+			return err
+		}
+	}
+	if state != nil {
+		state.RecordTags(tags)
+		if err := state.Save(auditStateFile); err != nil {
+			return err
+		}
+	}
+
+	tracker := newProgress(len(selected))
+	passed, failed := resumedOutcomes(images, state)
+	var reports []output.AllResult
+	for i, img := range selected {
+		if i > 0 && !waitInterval(ctx, auditInterval) {
+			break
+		}
+		report, err := auditImage(cmd, img, tracker)
+		if err != nil {
+			return err
+		}
+		if len(auditCoverageReports) > 0 {
+			reports = append(reports, report)
+		}
+		if report.Passed {
+			passed++
+		} else {
+			failed++
+		}
+		if state != nil {
+			state.MarkDone(img.Digest, audit.Outcome{
+				Passed:       report.Passed,
+				FailedChecks: failedCheckNames(report.Checks),
+			})
+			if err := state.Save(auditStateFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	printProgressSummary(tracker)
+	if OutputFmt == output.FormatText {
+		fmt.Fprintf(stdout, "Audited %d images of %s: %d passed, %d failed (%d already completed)\n",
+			passed+failed, repository, passed, failed, resumed)
+	}
+	return writeCoverageReports(repository, reports)
+}
+
+// resumedOutcomes returns the number of images a previous run recorded in
+// state as passed and as failed, and merges them into the global Result so
+// that a resumed audit still fails when an earlier run found failures.
+// Digests completed without a recorded outcome are counted in neither.
+func resumedOutcomes(images []audit.Image, state *audit.State) (passed, failed int) {
+	if state == nil {
+		return 0, 0
+	}
+	for _, img := range images {
+		outcome, ok := state.Outcome(img.Digest)
+		if !ok {
+			continue
+		}
+		if outcome.Passed {
+			passed++
+			UpdateResult(ValidationSucceeded)
+			continue
+		}
+		failed++
+		UpdateResult(ValidationFailed)
+		log.WithFields(log.Fields{
+			"image":  logutil.SanitizeLogValue(img.Ref),
+			"failed": logutil.SanitizeLogValue(strings.Join(outcome.FailedChecks, ",")),
+		}).Warn("Image failed validation in a previous run")
+	}
+	return passed, failed
+}
+
+// repositoryImages returns one audit image per distinct digest, referenced by
+// digest so that tags moved during the audit do not change what is validated.
+func repositoryImages(repository string, tagged []imageutil.TaggedDigest) []audit.Image {
+	seen := make(map[string]bool, len(tagged))
+	var images []audit.Image
+	for _, t := range tagged {
+		if seen[t.Digest] {
+			continue
+		}
+		seen[t.Digest] = true
+		images = append(images, audit.Image{Ref: repository + "@" + t.Digest, Digest: t.Digest})
+	}
+	return images
+}
+
+// waitInterval waits for d and reports false when ctx ends first.
+func waitInterval(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// auditImage runs the all-checks validation on one image and returns its
+// report. Only configuration errors are returned; failures to read the image
+// are recorded in its report.
+func auditImage(cmd *cobra.Command, img audit.Image, tracker *progress.Tracker) (output.AllResult, error) {
+	log.WithField("image", logutil.SanitizeLogValue(img.Ref)).Info("Validating image")
+
+	run, report, err := evaluateImage(cmd, img.Ref)
+	if err != nil {
+		return output.AllResult{}, err
+	}
+
+	switch {
+	case OutputFmt == output.FormatJSON:
+		if err := writeReport(report); err != nil {
+			return report, err
+		}
+	case OutputFmt == output.FormatCSV:
+		if err := output.RenderCSVRows(stdout, output.ReportFindings(report)); err != nil {
+			return report, err
+		}
+	case len(run.results) == 0:
+		printNoChecks(run.skipped)
+	}
+
+	recordProgress(tracker, report)
+	if !report.Passed {
+		log.WithFields(log.Fields{
+			"image":  logutil.SanitizeLogValue(report.Image),
+			"failed": strings.Join(failedCheckNames(report.Checks), ","),
+		}).Warn("Image failed validation")
+	}
+	return report, nil
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/audit"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+var auditCoverageReports []string
+var auditCoverageTop uint
+
+// Formats of coverage reports, selected by the file extension.
+const (
+	coverageFormatJSON     = "json"
+	coverageFormatMarkdown = "markdown"
+	coverageFormatHTML     = "html"
+)
+
+// coverageFormat returns the format of the coverage report written to path.
+func coverageFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return coverageFormatJSON, nil
+	case ".md", ".markdown":
+		return coverageFormatMarkdown, nil
+	case ".html", ".htm":
+		return coverageFormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported --coverage-report extension %q for %s, use .json, .md or .html", ext, path)
+	}
+}
+
+// validateCoverageReports checks the --coverage-report files before the audit
+// starts, so that a typo does not waste a long audit.
+func validateCoverageReports() error {
+	for _, path := range auditCoverageReports {
+		if _, err := coverageFormat(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCoverageReports writes the coverage of the reports of an audit of
+// repository to every --coverage-report file.
+func writeCoverageReports(repository string, reports []output.AllResult) error {
+	if len(auditCoverageReports) == 0 {
+		return nil
+	}
+	coverage := audit.BuildCoverage(repository, reports, int(auditCoverageTop))
+	for _, path := range auditCoverageReports {
+		if err := writeCoverageReport(path, coverage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCoverageReport(path string, coverage audit.Coverage) error {
+	format, err := coverageFormat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create coverage report: %w", err)
+	}
+	switch format {
+	case coverageFormatMarkdown:
+		err = audit.RenderCoverageMarkdown(f, coverage)
+	case coverageFormatHTML:
+		err = audit.RenderCoverageHTML(f, coverage)
+	default:
+		err = output.RenderJSON(f, coverage)
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		return fmt.Errorf("error writing coverage report %s: %w", path, err)
+	}
+	log.WithFields(log.Fields{"file": path, "format": format}).Info("Wrote coverage report")
+	return nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/audit"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkTagHygiene is the name of the repository-level check of audit.
+const checkTagHygiene = "tag-hygiene"
+
+var auditTagHygiene bool
+var auditMaxTagIssues uint
+
+// tagDigests maps the tags of a repository listing to their digests.
+func tagDigests(tagged []imageutil.TaggedDigest) map[string]string {
+	tags := make(map[string]string, len(tagged))
+	for _, t := range tagged {
+		tags[t.Tag] = t.Digest
+	}
+	return tags
+}
+
+// runTagHygiene checks the tags of repository against the tags recorded in
+// state, which may be nil, and renders the result. More than maxIssues issues
+// fail the audit.
+func runTagHygiene(repository string, tags map[string]string, state *audit.State, maxIssues uint) error {
+	var recorded map[string]string
+	if state != nil {
+		recorded = state.Tags
+	} else {
+		log.Debug("Re-pushed tags are only detected with --state-file, which records the tags of earlier audits")
+	}
+
+	issues := audit.CheckTagHygiene(tags, recorded)
+	result := output.TagHygieneResult{
+		Check:      checkTagHygiene,
+		Repository: repository,
+		Passed:     uint(len(issues)) <= maxIssues,
+		MaxIssues:  maxIssues,
+		Issues:     make([]output.TagIssue, 0, len(issues)),
+	}
+	for _, i := range issues {
+		result.Issues = append(result.Issues, output.TagIssue{
+			Kind:           i.Kind,
+			Tag:            i.Tag,
+			Digest:         i.Digest,
+			ExpectedDigest: i.Expected,
+			Message:        i.Message,
+		})
+	}
+	switch {
+	case len(issues) == 0:
+		result.Message = "No tag hygiene issues found"
+	case result.Passed:
+		result.Message = fmt.Sprintf("Repository has %d tag hygiene issues, within the limit of %d", len(issues), maxIssues)
+	default:
+		result.Message = fmt.Sprintf("Repository has %d tag hygiene issues (max %d)", len(issues), maxIssues)
+	}
+
+	if result.Passed {
+		UpdateResult(ValidationSucceeded)
+	} else {
+		UpdateResult(ValidationFailed)
+	}
+	return renderTagHygiene(result)
+}
+
+func renderTagHygiene(r output.TagHygieneResult) error {
+	switch OutputFmt {
+	case output.FormatJSON:
+		return writeReport(r)
+	case output.FormatCSV:
+		if r.Passed {
+			return nil
+		}
+		findings := make([]output.Finding, 0, len(r.Issues))
+		for _, i := range r.Issues {
+			findings = append(findings, output.Finding{
+				Image:    r.Repository,
+				Check:    r.Check,
+				Rule:     i.Kind,
+				Subject:  i.Tag,
+				Message:  i.Message,
+				Severity: output.SeverityFailure,
+			})
+		}
+		return output.RenderCSVRows(stdout, findings)
+	}
+
+	fmt.Fprintln(stdout, headerStyle.Render(fmt.Sprintf("Checking tag hygiene of %s", r.Repository)))
+	for _, i := range r.Issues {
+		fmt.Fprintf(stdout, "  - %s: %s\n", FailStyle.Render(i.Tag), i.Message)
+	}
+	fmt.Fprintln(stdout, statusPrefix(r.Passed)+r.Message)
+	fmt.Fprintln(stdout)
+	return nil
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/audit"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushAuditRepository pushes a failing image tagged "a" and a passing image
+// tagged "b" and "c" to a new registry and returns the repository.
+func pushAuditRepository(t *testing.T) string {
+	t.Helper()
+	repo := newTestRegistry(t) + "/org/app"
+	failing := createTestImage(t, testImageOptions{user: "root", created: time.Now()})
+	passing := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	for tag, src := range map[string]string{"a": failing, "b": passing, "c": passing} {
+		_, err := imageutil.CopyImage(context.Background(), src, repo+":"+tag)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+func decodeReports(t *testing.T, out string) []output.AllResult {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []output.AllResult
+	for dec.More() {
+		var r output.AllResult
+		require.NoError(t, dec.Decode(&r))
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+func TestRunAudit(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	repo := pushAuditRepository(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	reports := decodeReports(t, out)
+	require.Len(t, reports, 2, "tags sharing a digest are validated once")
+	assert.True(t, strings.HasPrefix(reports[0].Image, repo+"@sha256:"))
+	assert.False(t, reports[0].Passed)
+	assert.True(t, reports[1].Passed, "each image is judged on its own checks")
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunAudit_CSV(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatCSV
+	repo := pushAuditRepository(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2, "a single header row and the finding of the failing image")
+	assert.Equal(t, "image,check,rule,subject,message,severity", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], repo+"@sha256:"))
+}
+
+func TestRunAudit_ResumeFromStateFile(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	auditStateFile = filepath.Join(t.TempDir(), "state.json")
+	auditMaxImages = 1
+	repo := pushAuditRepository(t)
+
+	first := decodeReports(t, captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	}))
+	require.Len(t, first, 1)
+
+	second := decodeReports(t, captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	}))
+	require.Len(t, second, 1)
+	assert.NotEqual(t, first[0].Image, second[0].Image, "the second run resumes with the next image")
+
+	third := decodeReports(t, captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	}))
+	assert.Empty(t, third, "every image is already completed")
+
+	state, err := audit.LoadState(auditStateFile)
+	require.NoError(t, err)
+	assert.Len(t, state.Completed, 2)
+}
+
+func TestRunAudit_ResumeKeepsEarlierFailures(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	auditStateFile = filepath.Join(t.TempDir(), "state.json")
+	auditMaxImages = 1
+	repo := pushAuditRepository(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+	require.Equal(t, ValidationFailed, Result, "the first run validates the failing image")
+
+	state, err := audit.LoadState(auditStateFile)
+	require.NoError(t, err)
+	require.Len(t, state.Completed, 1)
+	outcome, ok := state.Outcome(state.Completed[0])
+	require.True(t, ok)
+	assert.Equal(t, audit.Outcome{FailedChecks: []string{"user"}}, outcome)
+
+	Result = ValidationSkipped
+	out := captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	assert.Contains(t, out, "Audited 2 images of "+repo+": 1 passed, 1 failed (1 already completed)")
+	assert.Equal(t, ValidationFailed, Result, "the failure of the interrupted run still fails the audit")
+}
+
+func TestRunAudit_TextSummary(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	auditShuffle = true
+	repo := pushAuditRepository(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	assert.Contains(t, out, "Audited 2 images of "+repo+": 1 passed, 1 failed (0 already completed)")
+}
+
+func TestRunAudit_WritesToStdoutSink(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatCSV
+	var rec sectionWriter
+	previous := stdout
+	stdout = output.NewSink(&rec)
+	t.Cleanup(func() { stdout = previous })
+	repo := pushAuditRepository(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	assert.Empty(t, out, "nothing bypasses the sink")
+	joined := strings.Join(rec.writes, "")
+	assert.True(t, strings.HasPrefix(joined, "image,check,rule,subject,message,severity\n"))
+	assert.Contains(t, joined, repo+"@sha256:")
+}
+
+func TestRunAudit_TagHygiene(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	auditTagHygiene = true
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	auditStateFile = stateFile
+	repo := newTestRegistry(t) + "/org/app"
+	older := createTestImage(t, testImageOptions{user: "1000", created: time.Now().Add(-time.Hour)})
+	newer := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+	push := func(src, tag string) string {
+		desc, err := imageutil.CopyImage(context.Background(), src, repo+":"+tag)
+		require.NoError(t, err)
+		return desc.Digest.String()
+	}
+	olderDigest := push(older, "v1.0.0")
+	push(older, "latest")
+	newerDigest := push(newer, "v1.1.0")
+
+	decodeTagHygiene := func(out string) output.TagHygieneResult {
+		var r output.TagHygieneResult
+		require.NoError(t, json.NewDecoder(strings.NewReader(out)).Decode(&r))
+		return r
+	}
+
+	first := decodeTagHygiene(captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	}))
+	assert.Equal(t, checkTagHygiene, first.Check)
+	assert.False(t, first.Passed)
+	require.Len(t, first.Issues, 1)
+	assert.Equal(t, output.TagIssue{
+		Kind:           "latest-diverges",
+		Tag:            "latest",
+		Digest:         olderDigest,
+		ExpectedDigest: newerDigest,
+		Message:        "latest points at " + olderDigest + ", but the newest release v1.1.0 points at " + newerDigest,
+	}, first.Issues[0])
+	assert.Equal(t, ValidationFailed, Result)
+
+	push(newer, "latest")
+	push(newer, "v1.0.0")
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	auditTagHygiene = true
+	auditMaxTagIssues = 1
+	auditStateFile = stateFile
+
+	second := decodeTagHygiene(captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	}))
+	assert.True(t, second.Passed, "one issue is within --max-tag-issues")
+	require.Len(t, second.Issues, 1)
+	assert.Equal(t, "repushed", second.Issues[0].Kind)
+	assert.Equal(t, "v1.0.0", second.Issues[0].Tag)
+	assert.Equal(t, olderDigest, second.Issues[0].ExpectedDigest)
+}
+
+func TestRunAudit_CoverageReport(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user,age"
+	dir := t.TempDir()
+	auditCoverageReports = []string{filepath.Join(dir, "coverage.json"), filepath.Join(dir, "coverage.md"), filepath.Join(dir, "coverage.html")}
+	repo := pushAuditRepository(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, runAudit(auditCmd, repo))
+	})
+
+	data, err := os.ReadFile(auditCoverageReports[0])
+	require.NoError(t, err)
+	var coverage audit.Coverage
+	require.NoError(t, json.Unmarshal(data, &coverage))
+	assert.Equal(t, repo, coverage.Repository)
+	assert.Equal(t, 2, coverage.Images)
+	assert.Equal(t, 1, coverage.Passed)
+	assert.Equal(t, []audit.CheckCoverage{
+		{Check: "user", Images: 2, Passed: 1, Failed: 1, PassRate: 50},
+		{Check: "age", Images: 2, Passed: 2, PassRate: 100},
+	}, coverage.Checks)
+	require.Len(t, coverage.TopFailingRules, 1)
+	assert.Equal(t, "user", coverage.TopFailingRules[0].Check)
+	assert.Len(t, coverage.OldestImages, 2)
+	assert.Empty(t, coverage.LargestImages, "the size check did not run")
+
+	markdown, err := os.ReadFile(auditCoverageReports[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "# Policy coverage of "+repo)
+	html, err := os.ReadFile(auditCoverageReports[2])
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<h1>Policy coverage of "+repo+"</h1>")
+}
+
+func TestRunAudit_CoverageReportExtension(t *testing.T) {
+	resetAllGlobals(t)
+	auditCoverageReports = []string{"coverage.txt"}
+
+	err := runAudit(auditCmd, "registry.example.com/org/app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported --coverage-report extension ".txt"`)
+}
+
+func TestRunAudit_Errors(t *testing.T) {
+	resetAllGlobals(t)
+	err := runAudit(auditCmd, newTestRegistry(t)+"/org/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error listing tags")
+}
+
+func TestWaitInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.True(t, waitInterval(ctx, 0))
+	assert.True(t, waitInterval(ctx, time.Millisecond))
+	cancel()
+	assert.False(t, waitInterval(ctx, 0))
+	assert.False(t, waitInterval(ctx, time.Hour))
+}
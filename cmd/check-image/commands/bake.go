@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/bake"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var bakeTargets []string
+var bakeMetadataFile string
+
+// bakePrint resolves bake files into a definition; tests replace it.
+var bakePrint = bake.Print
+
+var bakeCmd = &cobra.Command{
+	Use:   "bake [file...]",
+	Short: "Validate every image built by a docker buildx bake build",
+	Long: `Run the same checks as the all command against the image of every target of
+a docker buildx bake build, and report the results keyed by target name.
+
+The targets are resolved with docker buildx bake --print, so HCL variables,
+functions, and inheritance are evaluated as bake does; with no files, bake
+looks for its default files (docker-bake.hcl, docker-compose.yaml, etc.), and
+with no --target, the default group is used. Each target is validated by its
+first tag, from the local daemon and falling back to the registry.
+
+With --metadata-file, the file written by docker buildx bake --metadata-file
+locates the images instead: each target is validated by its first image name
+pinned to the digest it was built with, which requires the images to be
+pushed. Without bake files, the targets of the metadata file are used and
+docker is not needed.
+
+Targets that produce no image, such as test or lint targets without tags,
+are skipped.`,
+	Example: `  check-image bake docker-bake.hcl -c config/config.yaml
+  check-image bake docker-bake.hcl --target backend -c config/config.yaml -o json
+  docker buildx bake --push --metadata-file bake-metadata.json && check-image bake --metadata-file bake-metadata.json -c config/config.yaml`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := withReportFile(func() error { return runBake(cmd, args) }); err != nil {
+			return fmt.Errorf("bake operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bakeCmd)
+	addAllCheckFlags(bakeCmd)
+	bakeCmd.Flags().StringSliceVar(&bakeTargets, "target", nil, "Bake targets or groups to validate, comma-separated or repeated (default: the default group) (optional)")
+	bakeCmd.Flags().StringVar(&bakeMetadataFile, "metadata-file", "", "Bake metadata file (docker buildx bake --metadata-file) that pins every target to the digest it was built with (optional)")
+	bakeCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Do not print the progress line and summary table to stderr (optional)")
+}
+
+// runBake validates the image of every bake target and renders the results
+// keyed by target.
+func runBake(cmd *cobra.Command, files []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	images, err := resolveBakeImages(ctx, files)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no bake target produces an image")
+	}
+	log.WithField("targets", len(images)).Info("Validating bake targets")
+
+	refs := make([]string, len(images))
+	for i, img := range images {
+		refs[i] = img.Ref
+	}
+	bulk, err := validateBulkImages(cmd, refs)
+	if err != nil {
+		return err
+	}
+	reports := bulk.Images
+	bulk.Targets = make(map[string]output.AllResult, len(images))
+	for i, img := range images {
+		bulk.Targets[img.Target] = reports[i]
+	}
+	bulk.Images = nil
+	return renderBulk(bulk, reports)
+}
+
+// resolveBakeImages returns the images of the bake targets, from the bake
+// definition and the metadata file.
+func resolveBakeImages(ctx context.Context, files []string) ([]bake.Image, error) {
+	var meta map[string]bake.Metadata
+	if bakeMetadataFile != "" {
+		data, err := fileutil.ReadFileOrStdin(bakeMetadataFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading bake metadata file: %w", err)
+		}
+		if meta, err = bake.ParseMetadata(data); err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return filterBakeTargets(bake.Images(nil, meta), meta)
+		}
+	}
+
+	data, err := bakePrint(ctx, files, bakeTargets)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving bake targets: %w", err)
+	}
+	def, err := bake.ParseDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return bake.Images(def, meta), nil
+}
+
+// filterBakeTargets keeps the images of --target when reading only a
+// metadata file, which has no groups, so the names must be targets.
+func filterBakeTargets(images []bake.Image, meta map[string]bake.Metadata) ([]bake.Image, error) {
+	if len(bakeTargets) == 0 {
+		return images, nil
+	}
+	for _, t := range bakeTargets {
+		if _, ok := meta[t]; !ok {
+			return nil, fmt.Errorf("target %q not found in the bake metadata file", t)
+		}
+	}
+	var selected []bake.Image
+	for _, img := range images {
+		for _, t := range bakeTargets {
+			if img.Target == t {
+				selected = append(selected, img)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
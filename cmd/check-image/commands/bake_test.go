@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushBakeImages pushes an image running as root for api and one running as
+// a non-root user for web, returning the registry.
+func pushBakeImages(t *testing.T) string {
+	t.Helper()
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+	_, err := imageutil.CopyImage(ctx, createTestImage(t, testImageOptions{user: "root", created: time.Now()}), registry+"/org/api:1.0")
+	require.NoError(t, err)
+	_, err = imageutil.CopyImage(ctx, createTestImage(t, testImageOptions{user: "1000", created: time.Now()}), registry+"/org/web:1.0")
+	require.NoError(t, err)
+	return registry
+}
+
+func TestRunBake_Definition(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	registry := pushBakeImages(t)
+
+	var gotFiles, gotTargets []string
+	bakePrint = func(_ context.Context, files, targets []string) ([]byte, error) {
+		gotFiles, gotTargets = files, targets
+		return fmt.Appendf(nil, `{"target": {
+			"api": {"tags": ["%[1]s/org/api:1.0"]},
+			"web": {"tags": ["%[1]s/org/web:1.0"]},
+			"test": {"target": "test"}
+		}}`, registry), nil
+	}
+	bakeTargets = []string{"default", "test"}
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runBake(bakeCmd, []string{"docker-bake.hcl"}))
+	})
+
+	assert.Equal(t, []string{"docker-bake.hcl"}, gotFiles)
+	assert.Equal(t, []string{"default", "test"}, gotTargets)
+
+	var result output.BulkResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.False(t, result.Passed)
+	assert.Empty(t, result.Images)
+	require.Len(t, result.Targets, 2)
+	assert.False(t, result.Targets["api"].Passed)
+	assert.Equal(t, registry+"/org/api:1.0", result.Targets["api"].Image)
+	assert.True(t, result.Targets["web"].Passed)
+	assert.Equal(t, output.BulkSummary{Total: 2, Passed: 1, Failed: 1}, result.Summary)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunBake_MetadataFile(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatText
+	registry := pushBakeImages(t)
+
+	bakePrint = func(context.Context, []string, []string) ([]byte, error) {
+		t.Fatal("docker buildx bake --print must not run without bake files")
+		return nil, nil
+	}
+	bakeMetadataFile = filepath.Join(t.TempDir(), "bake-metadata.json")
+	metadata := fmt.Sprintf(`{
+		"api": {"image.name": "%[1]s/org/api:1.0"},
+		"web": {"image.name": "%[1]s/org/web:1.0"},
+		"buildx.build.warnings": []
+	}`, registry)
+	require.NoError(t, os.WriteFile(bakeMetadataFile, []byte(metadata), 0600))
+	bakeTargets = []string{"web"}
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runBake(bakeCmd, nil))
+	})
+
+	assert.Contains(t, captured, "Validated 1 bake targets: 1 passed, 0 failed")
+	assert.NotContains(t, captured, "org/api")
+	assert.Equal(t, ValidationSucceeded, Result)
+}
+
+func TestRunBake_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		print     func(context.Context, []string, []string) ([]byte, error)
+		metadata  string
+		targets   []string
+		wantError string
+	}{
+		{
+			name: "bake fails",
+			print: func(context.Context, []string, []string) ([]byte, error) {
+				return nil, errors.New("docker buildx bake --print failed: exit status 1")
+			},
+			wantError: "error resolving bake targets: docker buildx bake --print failed",
+		},
+		{
+			name: "invalid definition",
+			print: func(context.Context, []string, []string) ([]byte, error) {
+				return []byte("not json"), nil
+			},
+			wantError: "error parsing bake definition",
+		},
+		{
+			name: "no images",
+			print: func(context.Context, []string, []string) ([]byte, error) {
+				return []byte(`{"target": {"lint": {"target": "lint"}}}`), nil
+			},
+			wantError: "no bake target produces an image",
+		},
+		{
+			name:      "unknown metadata target",
+			metadata:  `{"api": {"image.name": "ghcr.io/org/api:1.0"}}`,
+			targets:   []string{"web"},
+			wantError: `target "web" not found in the bake metadata file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			includeChecks = "user"
+			bakeTargets = tt.targets
+			var files []string
+			if tt.print != nil {
+				bakePrint = tt.print
+				files = []string{"docker-bake.hcl"}
+			}
+			if tt.metadata != "" {
+				bakeMetadataFile = filepath.Join(t.TempDir(), "bake-metadata.json")
+				require.NoError(t, os.WriteFile(bakeMetadataFile, []byte(tt.metadata), 0600))
+			}
+
+			err := runBake(bakeCmd, files)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantError)
+		})
+	}
+}
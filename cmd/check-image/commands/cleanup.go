@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/cleanup"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// defaultCleanupOlderThan is the age temporary files must reach before
+// cleanup removes them, so that the files of runs in progress are kept.
+const defaultCleanupOlderThan = 24 * time.Hour
+
+// defaultCacheTTL is how long a cached layer is kept after its last use.
+const defaultCacheTTL = 30 * 24 * time.Hour
+
+var (
+	cleanupDryRun       bool
+	cleanupOlderThan    = defaultCleanupOlderThan
+	cleanupCacheTTL     = defaultCacheTTL
+	cleanupCacheMaxSize uint
+)
+
+// cleanupTempPatterns are the temporary files and directories check-image
+// creates in the system temporary directory.
+var cleanupTempPatterns = []cleanup.TempPattern{
+	// Inline policies of config files, e.g. check-image-secrets-policy-123.json.
+	{Kind: "temp-policy", Pattern: "check-image-*-policy-*.json"},
+	{Kind: "oci-archive", Pattern: "check-image-oci-archive-*"},
+	// Reports and working directories of hooks.
+	{Kind: "hook", Pattern: "check-image-hook-*"},
+	// Layer caches of promote runs without --cache-dir.
+	{Kind: "promote-cache", Pattern: "check-image-cache-*"},
+}
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove stale temporary files and prune the layer cache",
+	Long: `Remove stale temporary files and prune the layer cache.
+
+Runs that are killed leave temporary files behind: inline policies written
+from config files, extracted OCI archives, hook reports, and the layer caches
+of promote. They are removed from the system temporary directory once they
+are older than --older-than (default 24h), so the files of runs in progress
+are kept.
+
+With --cache-dir, the layer cache is pruned too: layers not used within
+--cache-ttl (default 720h, 0 keeps them) are removed, then the least recently
+used layers until the cache is at most --cache-max-size MB (0 for no limit).
+Partial downloads older than --older-than are always removed.
+
+With --dry-run, the files that would be removed are listed and nothing is
+deleted.`,
+	Example: `  check-image cleanup --dry-run
+  check-image cleanup --older-than 6h
+  check-image cleanup --cache-dir /var/cache/check-image --cache-ttl 168h --cache-max-size 10240 -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runCleanup(time.Now()); err != nil {
+			return fmt.Errorf("cleanup operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "List the files that would be removed without removing them (optional)")
+	cleanupCmd.Flags().DurationVar(&cleanupOlderThan, "older-than", defaultCleanupOlderThan, "Only remove temporary files older than this (optional)")
+	cleanupCmd.Flags().DurationVar(&cleanupCacheTTL, "cache-ttl", defaultCacheTTL, "Remove cached layers not used within this duration, 0 to keep them (optional)")
+	cleanupCmd.Flags().UintVar(&cleanupCacheMaxSize, "cache-max-size", 0, "Maximum size of the layer cache in MB, least recently used layers are removed first; 0 for no limit (optional)")
+}
+
+func runCleanup(now time.Time) error {
+	if cleanupOlderThan < 0 || cleanupCacheTTL < 0 {
+		return fmt.Errorf("--older-than and --cache-ttl must not be negative")
+	}
+
+	maxCacheBytes, err := megabytesToBytes("--cache-max-size", cleanupCacheMaxSize)
+	if err != nil {
+		return err
+	}
+
+	result := output.CleanupResult{DryRun: cleanupDryRun, TempDir: os.TempDir(), CacheDir: cacheDir, Items: []output.CleanupItem{}}
+	cutoff := now.Add(-cleanupOlderThan)
+
+	items, err := cleanup.FindTemp(result.TempDir, cleanupTempPatterns, cutoff)
+	if err != nil {
+		return err
+	}
+	result.Items = append(result.Items, items...)
+
+	if cacheDir != "" {
+		items, kept, err := cleanup.FindCache(cacheDir, cleanup.CacheOptions{
+			TTL:           cleanupCacheTTL,
+			MaxSize:       maxCacheBytes,
+			PartialCutoff: cutoff,
+			Now:           now,
+		})
+		if err != nil {
+			return err
+		}
+		result.Items = append(result.Items, items...)
+		result.CacheBytes = kept
+	}
+
+	for _, item := range result.Items {
+		result.RemovedBytes += item.Size
+	}
+	if !cleanupDryRun {
+		if err := cleanup.Remove(result.Items); err != nil {
+			return err
+		}
+	}
+
+	UpdateResult(ValidationSucceeded)
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	printCleanup(result)
+	return nil
+}
+
+func printCleanup(r output.CleanupResult) {
+	verb := "Removed"
+	if r.DryRun {
+		verb = "Would remove"
+	}
+	for _, item := range r.Items {
+		fmt.Printf("%s %s (%s, %s, %s)\n", verb, item.Path, item.Kind, item.Reason, formatSize(item.Size))
+	}
+	if len(r.Items) == 0 {
+		fmt.Println("Nothing to clean up")
+	} else {
+		fmt.Printf("%s %d items, %s\n", verb, len(r.Items), formatSize(r.RemovedBytes))
+	}
+	if r.CacheDir != "" {
+		fmt.Printf("Layer cache %s: %s\n", r.CacheDir, formatSize(r.CacheBytes))
+	}
+}
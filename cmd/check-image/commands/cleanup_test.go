@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupCommand(t *testing.T) {
+	assert.Equal(t, "cleanup", cleanupCmd.Use)
+	assert.Error(t, cleanupCmd.Args(cleanupCmd, []string{"extra"}))
+	for _, name := range []string{"dry-run", "older-than", "cache-ttl", "cache-max-size"} {
+		assert.NotNil(t, cleanupCmd.Flags().Lookup(name), name)
+	}
+}
+
+// staleFile creates path, last modified two days ago.
+func staleFile(t *testing.T, path string, size int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0600))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+}
+
+func TestRunCleanup(t *testing.T) {
+	resetAllGlobals(t)
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	cacheDir = t.TempDir()
+	oldEntry := filepath.Join(cacheDir, "sha256", strings.Repeat("a", 64))
+	newEntry := filepath.Join(cacheDir, "sha256", strings.Repeat("b", 64))
+
+	staleFile(t, filepath.Join(tmp, "check-image-secrets-policy-1.json"), 10)
+	staleFile(t, filepath.Join(tmp, "other.json"), 10)
+	staleFile(t, filepath.Join(tmp, "app-policy-3.json"), 10)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmp, "oci-archive-4"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "check-image-user-policy-2.json"), nil, 0600))
+	staleFile(t, oldEntry, 2*1024*1024)
+	require.NoError(t, os.WriteFile(newEntry, make([]byte, 1024*1024), 0600))
+
+	cleanupDryRun = true
+	cleanupCacheMaxSize = 1
+	captured := captureStdout(t, func() {
+		require.NoError(t, runCleanup(time.Now()))
+	})
+	assert.Contains(t, captured, "Would remove "+filepath.Join(tmp, "check-image-secrets-policy-1.json")+" (temp-policy, stale, ")
+	assert.Contains(t, captured, "Would remove "+oldEntry+" (cache-entry, over-size, ")
+	assert.Contains(t, captured, "Would remove 2 items")
+	assert.FileExists(t, filepath.Join(tmp, "check-image-secrets-policy-1.json"), "a dry run removes nothing")
+
+	cleanupDryRun = false
+	OutputFmt = output.FormatJSON
+	captured = captureStdout(t, func() {
+		require.NoError(t, runCleanup(time.Now()))
+	})
+	var result output.CleanupResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	assert.False(t, result.DryRun)
+	assert.Len(t, result.Items, 2)
+	assert.Equal(t, int64(10+2*1024*1024), result.RemovedBytes)
+	assert.Equal(t, int64(1024*1024), result.CacheBytes)
+	assert.NoFileExists(t, filepath.Join(tmp, "check-image-secrets-policy-1.json"))
+	assert.NoFileExists(t, oldEntry)
+	assert.FileExists(t, filepath.Join(tmp, "other.json"), "files check-image did not create are kept")
+	assert.FileExists(t, filepath.Join(tmp, "app-policy-3.json"), "only files with the check-image- prefix are removed")
+	assert.DirExists(t, filepath.Join(tmp, "oci-archive-4"), "only directories with the check-image- prefix are removed")
+	assert.FileExists(t, filepath.Join(tmp, "check-image-user-policy-2.json"), "recent files are kept")
+	assert.FileExists(t, newEntry)
+	assert.Equal(t, ValidationSucceeded, Result)
+}
+
+func TestRunCleanup_Nothing(t *testing.T) {
+	resetAllGlobals(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runCleanup(time.Now()))
+	})
+	assert.Equal(t, "Nothing to clean up\n", captured)
+}
+
+func TestRunCleanup_InvalidDurations(t *testing.T) {
+	resetAllGlobals(t)
+	cleanupOlderThan = -time.Hour
+	err := runCleanup(time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not be negative")
+}
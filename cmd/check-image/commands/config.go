@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/deprecation"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var migrateWrite bool
+var validateStrictConfig bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage check-image configuration files",
+	Long:  `Manage configuration files for the all command.`,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate file",
+	Short: "Rewrite deprecated keys of a configuration file to the current schema",
+	Long: `Rewrite deprecated keys of an all-command configuration file (JSON or YAML) to
+their current names.
+
+The migrated configuration is printed to stdout. With --write, the file is
+updated in place instead. Key order is kept, and YAML comments are preserved.
+Use "-" to read the configuration from stdin.`,
+	Example: `  check-image config migrate config.yaml
+  check-image config migrate config.yaml --write
+  cat config.json | check-image config migrate -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runConfigMigrate(args[0]); err != nil {
+			return fmt.Errorf("config migrate operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate file",
+	Short: "Validate a configuration file",
+	Long: `Validate an all-command configuration file (JSON or YAML) the way the all
+command loads it: deprecated keys are migrated, and policy windows, age rules,
+and inline policies are checked.
+
+With --strict-config, the default, keys that are not part of the schema are
+rejected with their path (e.g. checks.age.max_age) instead of being ignored.
+Use "-" to read the configuration from stdin.`,
+	Example: `  check-image config validate config.yaml
+  check-image config validate config.yaml --strict-config=false
+  cat config.json | check-image config validate -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runConfigValidate(args[0]); err != nil {
+			return fmt.Errorf("config validate operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configMigrateCmd.Flags().BoolVar(&migrateWrite, "write", false, "Write the migrated configuration back to the file instead of printing it (optional)")
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().BoolVar(&validateStrictConfig, "strict-config", true, "Reject unknown keys instead of ignoring them (optional)")
+}
+
+// logDeprecation warns about a deprecated flag or configuration key with
+// structured fields so CI log processors can pick them up.
+func logDeprecation(n deprecation.Notice) {
+	log.WithFields(log.Fields{
+		"deprecated":  n.Old,
+		"replacement": n.New,
+		"since":       n.Since,
+	}).Warn(n.Message)
+}
+
+func runConfigMigrate(path string) error {
+	if migrateWrite && path == "-" {
+		return fmt.Errorf("--write cannot be used when reading from stdin")
+	}
+
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	migrated, notices, err := deprecation.MigrateConfig(data, fileutil.IsYAMLConfig(data, path), deprecation.ConfigKeys)
+	if err != nil {
+		return err
+	}
+
+	result := output.ConfigMigrationResult{File: path, Changes: []output.ConfigChange{}}
+	for _, n := range notices {
+		logDeprecation(n)
+		result.Changes = append(result.Changes, output.ConfigChange(n))
+	}
+
+	if migrateWrite {
+		if len(notices) > 0 {
+			if err := writeMigratedConfig(path, migrated); err != nil {
+				return err
+			}
+			result.Written = true
+		}
+	} else {
+		result.Config = string(migrated)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	printConfigMigrationResult(result, len(notices))
+	return nil
+}
+
+// writeMigratedConfig replaces the file content while keeping its permissions.
+func writeMigratedConfig(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+func printConfigMigrationResult(result output.ConfigMigrationResult, changes int) {
+	if !migrateWrite {
+		fmt.Print(result.Config)
+		return
+	}
+	if changes == 0 {
+		fmt.Printf("%sNo deprecated keys found in %s\n", statusPrefix(true), result.File)
+		return
+	}
+	fmt.Printf("%sMigrated %d deprecated key(s) in %s\n", statusPrefix(true), changes, result.File)
+}
+
+func runConfigValidate(path string) error {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	result := output.ConfigValidationResult{File: path, Valid: true, Strict: validateStrictConfig}
+	if _, err := configParser(validateStrictConfig)(data, path); err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+		UpdateResult(ValidationFailed)
+	} else {
+		UpdateResult(ValidationSucceeded)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	if result.Valid {
+		fmt.Printf("%s%s is valid\n", statusPrefix(true), result.File)
+	} else {
+		fmt.Printf("%s%s is invalid: %s\n", statusPrefix(false), result.File, result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecatedConfig = "checks:\n  age:\n    max-age: 30\n  root-user: {}\n"
+const migratedConfig = "checks:\n  age:\n    max-age: 30\n  user: {}\n"
+
+func writeDeprecatedConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(deprecatedConfig), 0640))
+	return path
+}
+
+func TestRunConfigMigrate_PrintsMigratedConfig(t *testing.T) {
+	resetAllGlobals(t)
+	path := writeDeprecatedConfig(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runConfigMigrate(path))
+	})
+
+	assert.Equal(t, migratedConfig, out)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, deprecatedConfig, string(data), "file must not change without --write")
+}
+
+func TestRunConfigMigrate_Write(t *testing.T) {
+	resetAllGlobals(t)
+	migrateWrite = true
+	path := writeDeprecatedConfig(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runConfigMigrate(path))
+	})
+
+	assert.Contains(t, out, "Migrated 1 deprecated key(s)")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, migratedConfig, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	out = captureStdout(t, func() {
+		require.NoError(t, runConfigMigrate(path))
+	})
+	assert.Contains(t, out, "No deprecated keys found")
+}
+
+func TestRunConfigMigrate_JSONOutput(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	path := writeDeprecatedConfig(t)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runConfigMigrate(path))
+	})
+
+	var result output.ConfigMigrationResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, path, result.File)
+	assert.False(t, result.Written)
+	assert.Equal(t, migratedConfig, result.Config)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, "checks.root-user", result.Changes[0].Old)
+	assert.Equal(t, "checks.user", result.Changes[0].New)
+}
+
+func TestRunConfigMigrate_Errors(t *testing.T) {
+	t.Run("Write with stdin", func(t *testing.T) {
+		resetAllGlobals(t)
+		migrateWrite = true
+		err := runConfigMigrate("-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--write cannot be used when reading from stdin")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		resetAllGlobals(t)
+		err := runConfigMigrate(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read config file")
+	})
+}
+
+func TestLoadAllConfig_MigratesDeprecatedKeys(t *testing.T) {
+	resetAllGlobals(t)
+	path := writeDeprecatedConfig(t)
+
+	cfg, err := loadAllConfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Checks.User, "root-user must enable the user check")
+	require.NotNil(t, cfg.Checks.Age)
+	assert.Equal(t, uint(30), *cfg.Checks.Age.MaxAge)
+}
+
+func TestParseStrictAllConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		path    string
+		wantErr string
+	}{
+		{name: "valid YAML", data: "checks:\n  age:\n    max-age: 30\n    windows:\n      - from: 2026-01-01\n        max-age: 5\nunits: iec\n", path: "config.yaml"},
+		{name: "deprecated keys are migrated first", data: deprecatedConfig, path: "config.yaml"},
+		{name: "inline policies are not schema keys", data: `{"checks": {"registry": {"registry-policy": {"trusted-registries": ["docker.io"]}}}}`, path: "config.json"},
+		{name: "unknown top-level key", data: `{"check": {}}`, path: "config.json", wantErr: "check: unknown key, valid keys are: anonymize, checks,"},
+		{name: "unknown check key", data: "checks:\n  age:\n    max_age: 30\n", path: "config.yaml", wantErr: "checks.age.max_age: unknown key, valid keys are: max-age, not-after, not-before, rules, windows"},
+		{name: "unknown window key", data: "checks:\n  size:\n    windows:\n      - until: 2026-01-31\n        form: 2026-01-01\n", path: "config.yaml", wantErr: "checks.size.windows[0].form: unknown key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseStrictAllConfig([]byte(tt.data), tt.path)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid config: "+tt.wantErr)
+
+			_, err = parseAllConfig([]byte(tt.data), tt.path)
+			assert.NoError(t, err, "unknown keys are ignored without strict mode")
+		})
+	}
+}
+
+func TestLoadAllConfig_StrictConfig(t *testing.T) {
+	resetAllGlobals(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("checks:\n  age:\n    max_age: 30\n"), 0600))
+
+	_, err := loadAllConfig(path)
+	require.NoError(t, err)
+
+	strictConfig = true
+	_, err = loadAllConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checks.age.max_age: unknown key")
+}
+
+func TestRunConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("checks:\n  age:\n    max-age: 30\n"), 0600))
+	typo := filepath.Join(dir, "typo.yaml")
+	require.NoError(t, os.WriteFile(typo, []byte("checks:\n  age:\n    max_age: 30\n"), 0600))
+
+	t.Run("Valid config", func(t *testing.T) {
+		resetAllGlobals(t)
+		out := captureStdout(t, func() {
+			require.NoError(t, runConfigValidate(valid))
+		})
+		assert.Contains(t, out, "is valid")
+		assert.Equal(t, ValidationSucceeded, Result)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		resetAllGlobals(t)
+		OutputFmt = output.FormatJSON
+		out := captureStdout(t, func() {
+			require.NoError(t, runConfigValidate(typo))
+		})
+		var result output.ConfigValidationResult
+		require.NoError(t, json.Unmarshal([]byte(out), &result))
+		assert.False(t, result.Valid)
+		assert.True(t, result.Strict)
+		assert.Contains(t, result.Error, "checks.age.max_age: unknown key")
+		assert.Equal(t, ValidationFailed, Result)
+	})
+
+	t.Run("Strict mode disabled", func(t *testing.T) {
+		resetAllGlobals(t)
+		validateStrictConfig = false
+		out := captureStdout(t, func() {
+			require.NoError(t, runConfigValidate(typo))
+		})
+		assert.Contains(t, out, "is valid")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		resetAllGlobals(t)
+		err := runConfigValidate(filepath.Join(dir, "missing.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read config file")
+	})
+}
+
+func TestLoadAllConfig_JSONComments(t *testing.T) {
+	resetAllGlobals(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  // Approved in the platform review of 2026-03.
+  "checks": {
+    "age": {"max-age": 30}, /* stricter than the default */
+    "root-user": {}
+  }
+}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	strictConfig = true
+	cfg, err := loadAllConfig(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Checks.Age)
+	assert.Equal(t, uint(30), *cfg.Checks.Age.MaxAge)
+	assert.NotNil(t, cfg.Checks.User, "deprecated keys are migrated in JSONC files")
+}
+
+func TestLoadAllConfig_WindowsEncoding(t *testing.T) {
+	resetAllGlobals(t)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("\xef\xbb\xbfchecks:\r\n  age:\r\n    max-age: 30\r\n  root-user: {}\r\n"), 0600))
+	strictConfig = true
+	cfg, err := loadAllConfig(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Checks.Age)
+	assert.Equal(t, uint(30), *cfg.Checks.Age.MaxAge)
+	assert.NotNil(t, cfg.Checks.User, "deprecated keys are migrated in files with a byte order mark")
+
+	utf16 := filepath.Join(dir, "utf16.json")
+	require.NoError(t, os.WriteFile(utf16, []byte("\xff\xfe{\x00}\x00"), 0600))
+	_, err = loadAllConfig(utf16)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file is UTF-16 encoded, save it as UTF-8")
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy source destination",
+	Short: "Copy an image to a registry, reusing layers cached during validation",
+	Long: `Copy an image to a destination registry reference without running any checks.
+
+With --cache-dir, layers downloaded by an earlier validation run that used the
+same cache directory are read from disk instead of being downloaded again, so a
+validate-then-push pipeline fetches each layer only once.
+
+The source accepts any supported transport (registry, oci:, oci-archive:,
+docker-archive:). The destination must be a registry reference. Registry
+sources are copied as stored, so multi-platform indexes keep all platforms.`,
+	Example: `  check-image all registry.example.com/app:rc -c config.yaml --cache-dir .cache/layers
+  check-image copy registry.example.com/app:rc prod.example.com/app:1.0 --cache-dir .cache/layers
+  check-image copy oci:./layout:app registry.example.com/app:1.0 -o json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runCopy(cmd.Context(), args[0], args[1]); err != nil {
+			return fmt.Errorf("copy operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(copyCmd)
+}
+
+func runCopy(ctx context.Context, src, dst string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	desc, err := imageutil.CopyImage(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+
+	result := output.CopyResult{
+		Source:      src,
+		Destination: dst,
+		Digest:      desc.Digest.String(),
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	fmt.Printf("%sCopied %s to %s@%s\n", statusPrefix(true), result.Source, result.Destination, result.Digest)
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCopy(t *testing.T) {
+	tests := []struct {
+		name   string
+		format output.Format
+	}{
+		{name: "Text output", format: output.FormatText},
+		{name: "JSON output", format: output.FormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			OutputFmt = tt.format
+
+			src := createTestImage(t, testImageOptions{user: "1000"})
+			dst := newTestRegistry(t) + "/org/app:1.0"
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runCopy(t.Context(), src, dst))
+			})
+
+			dstRef, err := name.ParseReference(dst)
+			require.NoError(t, err)
+			desc, err := remote.Head(dstRef)
+			require.NoError(t, err)
+
+			if tt.format == output.FormatJSON {
+				var result output.CopyResult
+				require.NoError(t, json.Unmarshal([]byte(out), &result))
+				assert.Equal(t, src, result.Source)
+				assert.Equal(t, dst, result.Destination)
+				assert.Equal(t, desc.Digest.String(), result.Digest)
+			} else {
+				assert.Contains(t, out, "Copied "+src+" to "+dst+"@"+desc.Digest.String())
+			}
+			assert.Equal(t, ValidationSkipped, Result, "copy must not change the validation result")
+		})
+	}
+}
+
+func TestRunCopy_InvalidDestination(t *testing.T) {
+	resetAllGlobals(t)
+
+	src := createTestImage(t, testImageOptions{})
+
+	err := runCopy(t.Context(), src, "oci:/tmp/layout:latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination must be a registry reference")
+}
@@ -0,0 +1,427 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/jarfernandez/check-image/internal/daemonwatch"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var watchEvents string
+var alertWebhook string
+var healthAddr string
+var uiAddr string
+var uiHistorySize = defaultUIHistorySize
+var authTokenFile string
+var shutdownTimeout = defaultShutdownTimeout
+var reloadInterval = defaultReloadInterval
+var dedupTTL = defaultDedupTTL
+
+// defaultShutdownTimeout is how long an in-flight validation may run after
+// the watch is asked to stop.
+const defaultShutdownTimeout = 30 * time.Second
+
+// defaultReloadInterval is how often the config and policy files are checked
+// for changes between validations.
+const defaultReloadInterval = 30 * time.Second
+
+// defaultDedupTTL is how long the result of an image is reused for further
+// events of the same image under the same policy.
+const defaultDedupTTL = 10 * time.Minute
+
+// defaultUIHistorySize is the number of validations the dashboard keeps.
+const defaultUIHistorySize = 1000
+
+// watchHistory holds the validations shown on the dashboard while it is
+// served; recordAudit adds every validation to it.
+var watchHistory *daemonwatch.History
+
+// authTokenEnv holds the token of /policy and the dashboard when
+// --auth-token-file is not set.
+const authTokenEnv = "CHECK_IMAGE_AUTH_TOKEN"
+
+// newWatchSource creates the daemon event source. Tests replace it with a
+// fake source.
+var newWatchSource = daemonwatch.NewDockerSource
+
+var daemonWatchCmd = &cobra.Command{
+	Use:   "daemon-watch",
+	Short: "Validate every image pulled or loaded into the local Docker daemon",
+	Long: `Subscribe to image events of the local Docker daemon and run the same checks as
+the all command on each new image, until interrupted.
+
+By default, pull, load, and tag events are watched (builds appear as tag
+events). Images that fail validation are logged as warnings and, with
+--alert-webhook, posted as JSON reports to a webhook.
+
+With --health-addr, /healthz and /readyz probes are served on that address.
+With --ui-addr, a dashboard of the last --ui-history validations is served on
+/ui of its own listener; it starts with the last validations of --audit-log
+when the log is a file. With --auth-token-file (or the CHECK_IMAGE_AUTH_TOKEN
+environment variable), /policy and the dashboard require that token, as a
+bearer token or the password of HTTP basic authentication; the probes stay
+unauthenticated. Without a token, --ui-addr must be a loopback address.
+/readyz reports ready once the event stream is subscribed. On SIGINT or
+SIGTERM, no new events are accepted, /readyz reports not ready, and the
+in-flight validation is given --shutdown-timeout to finish before it is
+cancelled.
+
+The config and policy files are read again before every validation and every
+--reload-interval, so policy changes apply without restarting the watch. A
+config that does not parse is logged and the active policy is kept. The hash
+of the active policy is included in every report and served on /policy.
+
+Results are reused for --dedup-ttl when the same image (by image ID) arrives
+again under the same policy, such as a pull followed by tags of the image, so
+bursts of events validate and alert once.
+
+The daemon is selected with the standard Docker environment variables
+(DOCKER_HOST, DOCKER_CERT_PATH, DOCKER_TLS_VERIFY).`,
+	Example: `  check-image daemon-watch --config config.yaml
+  check-image daemon-watch -c config.yaml --events pull,load --alert-webhook https://hooks.example.com/check-image
+  check-image daemon-watch -c config.yaml -o json >> validations.json
+  check-image daemon-watch -c config.yaml --health-addr :8081 --shutdown-timeout 1m
+  check-image daemon-watch -c config.yaml --ui-addr localhost:8082 --audit-log /var/log/check-image/audit.jsonl
+  check-image daemon-watch -c config.yaml --ui-addr :8082 --auth-token-file /run/secrets/check-image-token`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := withReportFile(func() error { return runDaemonWatch(cmd) }); err != nil {
+			return fmt.Errorf("daemon-watch operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonWatchCmd)
+	addAllCheckFlags(daemonWatchCmd)
+	daemonWatchCmd.Flags().StringVar(&watchEvents, "events", strings.Join(daemonwatch.DefaultActions, ","), "Comma-separated list of image events to validate ("+strings.Join(daemonwatch.Actions, ", ")+") or @<file> (optional)")
+	daemonWatchCmd.Flags().StringVar(&alertWebhook, "alert-webhook", "", "URL to POST the JSON report of each image that fails validation to (optional)")
+	daemonWatchCmd.Flags().StringVar(&healthAddr, "health-addr", "", "Address to serve the /healthz and /readyz probes on, e.g. :8081 (optional)")
+	daemonWatchCmd.Flags().StringVar(&uiAddr, "ui-addr", "", "Address to serve a dashboard of the validations on /ui, e.g. localhost:8082; other than loopback addresses require a token (optional)")
+	daemonWatchCmd.Flags().IntVar(&uiHistorySize, "ui-history", defaultUIHistorySize, "Number of most recent validations the dashboard keeps")
+	daemonWatchCmd.Flags().StringVar(&authTokenFile, "auth-token-file", "", "File holding the token required by /policy and the dashboard; the probes are not authenticated (env: "+authTokenEnv+") (optional)")
+	daemonWatchCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "Time the in-flight validation is given to finish on shutdown")
+	daemonWatchCmd.Flags().DurationVar(&reloadInterval, "reload-interval", defaultReloadInterval, "How often the config and policy files are checked for changes between validations; 0 disables the periodic check")
+	daemonWatchCmd.Flags().DurationVar(&dedupTTL, "dedup-ttl", defaultDedupTTL, "How long the result of an image is reused for further events of the same image under the same policy; 0 validates every event")
+}
+
+func runDaemonWatch(cmd *cobra.Command) error {
+	list, err := parseListInput(watchEvents, "events")
+	if err != nil {
+		return fmt.Errorf("invalid --events: %w", err)
+	}
+	actions, err := daemonwatch.ParseActions(strings.Join(list, ","))
+	if err != nil {
+		return err
+	}
+	if alertWebhook != "" && !fileutil.IsURL(alertWebhook) {
+		return fmt.Errorf("--alert-webhook must be an http or https URL")
+	}
+	if shutdownTimeout < 0 {
+		return fmt.Errorf("--shutdown-timeout must not be negative")
+	}
+	if reloadInterval < 0 {
+		return fmt.Errorf("--reload-interval must not be negative")
+	}
+	if dedupTTL < 0 {
+		return fmt.Errorf("--dedup-ttl must not be negative")
+	}
+	if policyLabel != "" {
+		return fmt.Errorf("--policy-label is not supported by daemon-watch, which validates every image with one policy")
+	}
+	if authTokenFile != "" && healthAddr == "" && uiAddr == "" {
+		return fmt.Errorf("--auth-token-file requires --health-addr or --ui-addr")
+	}
+	token, err := loadAuthToken(authTokenFile, os.Getenv)
+	if err != nil {
+		return err
+	}
+	if err := validateWatchUI(uiAddr, uiHistorySize, token); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Validations and the health server outlive ctx: on shutdown the
+	// in-flight validation is drained, and is only cancelled once
+	// --shutdown-timeout has passed.
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRun()
+	health := &daemonwatch.Health{Token: token}
+	captureConfigBaseline(cmd)
+	defer func() { watchConfig, watchPolicyHash, watchBaseline = nil, "", nil }()
+	if err := reloadWatchPolicy(cmd, health); err != nil {
+		return err
+	}
+	var reload <-chan time.Time
+	if reloadInterval > 0 {
+		ticker := time.NewTicker(reloadInterval)
+		defer ticker.Stop()
+		reload = ticker.C
+	}
+
+	var healthErrs <-chan error
+	if healthAddr != "" {
+		if healthErrs, err = health.Serve(runCtx, healthAddr); err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{"address": healthAddr, "authenticated": token != ""}).Info("Serving health probes")
+	}
+	var uiErrs <-chan error
+	if uiAddr != "" {
+		watchHistory = daemonwatch.NewHistory(uiHistorySize)
+		defer func() { watchHistory = nil }()
+		seedHistory(watchHistory, auditLog, uiHistorySize)
+		var ui http.Handler = daemonwatch.NewUI(watchHistory)
+		if token != "" {
+			ui = daemonwatch.RequireToken(token, ui)
+		}
+		if uiErrs, err = daemonwatch.ServeUI(runCtx, uiAddr, ui); err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{"address": uiAddr, "authenticated": token != ""}).Info("Serving the validation dashboard on /ui")
+	}
+	stopDrain := context.AfterFunc(ctx, func() {
+		health.SetReady(false)
+		log.WithField("timeout", shutdownTimeout).Info("Shutting down, draining in-flight validation")
+		time.AfterFunc(shutdownTimeout, cancelRun)
+	})
+	defer stopDrain()
+	cmd.SetContext(runCtx)
+	defer cmd.SetContext(ctx)
+
+	source, err := newWatchSource(actions)
+	if err != nil {
+		return err
+	}
+	events, errs := source.Events(ctx)
+	results := daemonwatch.NewCache[output.AllResult](dedupTTL)
+	health.SetReady(ctx.Err() == nil)
+
+	log.WithField("events", strings.Join(actions, ",")).Info("Watching the Docker daemon for image events")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case err := <-healthErrs:
+			return err
+		case err := <-uiErrs:
+			return err
+		case <-reload:
+			if err := reloadWatchPolicy(cmd, health); err != nil {
+				return err
+			}
+		case ev, ok := <-events:
+			if !ok {
+				// A source that fails sends its error before closing the
+				// stream, and the closed stream may be selected first.
+				select {
+				case err := <-errs:
+					return err
+				default:
+					return nil
+				}
+			}
+			if err := reloadWatchPolicy(cmd, health); err != nil {
+				return err
+			}
+			if err := validateWatchedImage(runCtx, cmd, ev, results); err != nil {
+				return err
+			}
+			// Events that arrived during a drained validation are left
+			// for the next instance.
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// validateWatchUI checks the size of the dashboard history, and that a
+// dashboard served without a token is only reachable from the local host.
+func validateWatchUI(addr string, historySize int, token string) error {
+	if addr == "" {
+		return nil
+	}
+	if historySize < 1 {
+		return fmt.Errorf("--ui-history must be at least 1")
+	}
+	if token == "" && !isLoopbackAddr(addr) {
+		return fmt.Errorf("--ui-addr %s is not a loopback address; set --auth-token-file or %s to require a token for the dashboard", addr, authTokenEnv)
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether the host of addr is localhost or a loopback
+// IP address. An address without a host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loadAuthToken returns the token of the health server endpoints other than
+// the probes: the content of the file at path, or the authTokenEnv environment
+// variable when path is empty, without surrounding whitespace. An empty token
+// disables authentication, but a token file must not be empty.
+func loadAuthToken(path string, getenv func(string) string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(getenv(authTokenEnv)), nil
+	}
+	data, err := fileutil.ReadSecureFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --auth-token-file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("--auth-token-file %s is empty", path)
+	}
+	return token, nil
+}
+
+// seedHistory adds the last size validations of the audit log file at dest
+// to history, so that the dashboard does not start empty. A log that is not
+// a file, does not exist yet or cannot be read leaves history empty.
+func seedHistory(history *daemonwatch.History, dest string, size int) {
+	if dest == "" || auditlog.IsSyslog(dest) {
+		return
+	}
+	records, err := auditlog.ReadTail(dest, size)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.WithField("error", err).Warn("Unable to read the audit log for the dashboard")
+		}
+		return
+	}
+	for _, r := range records {
+		history.Add(r)
+	}
+}
+
+// validateWatchedImage runs the all-checks validation on one event's image
+// and reports failures. Only configuration errors stop the watch; failures to
+// read a single image are recorded in its report. A result in results for the
+// same image and policy is reported again instead of validating the image.
+func validateWatchedImage(ctx context.Context, cmd *cobra.Command, ev daemonwatch.Event, results *daemonwatch.Cache[output.AllResult]) error {
+	key := ""
+	if dedupTTL > 0 {
+		if id, err := watchedImageID(ctx, ev.Image); err == nil {
+			key = id + " " + watchPolicyHash
+		}
+	}
+	if cached, ok := results.Get(key); ok {
+		log.WithFields(log.Fields{
+			"image":  logutil.SanitizeLogValue(ev.Image),
+			"action": ev.Action,
+			"passed": cached.Passed,
+		}).Info("Image already validated with the active policy, reusing the result")
+		cached.Image = redactText(ev.Image)
+		if OutputFmt == output.FormatJSON {
+			if err := writeReport(cached); err != nil {
+				return err
+			}
+		}
+		if err := recordReusedAudit(ctx, cmd, ev.Image, cached); err != nil {
+			log.WithField("error", err).Warn("Failed to record the reused result in the audit log")
+		}
+		if !cached.Passed {
+			warnFailedValidation(cached)
+		}
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"image":  logutil.SanitizeLogValue(ev.Image),
+		"action": ev.Action,
+	}).Info("Validating image")
+
+	run, report, err := evaluateImage(cmd, ev.Image)
+	if err != nil {
+		return err
+	}
+	report.PolicyHash = watchPolicyHash
+	if key != "" {
+		results.Put(key, report)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		if err := writeReport(report); err != nil {
+			return err
+		}
+	} else if len(run.results) == 0 {
+		printNoChecks(run.skipped)
+	}
+
+	if report.Passed {
+		return nil
+	}
+
+	warnFailedValidation(report)
+
+	if alertWebhook != "" {
+		if err := daemonwatch.SendAlert(ctx, alertWebhook, report); err != nil {
+			log.WithField("error", err).Warn("Failed to send validation alert")
+		}
+	}
+	return nil
+}
+
+// warnFailedValidation logs the failed checks of report.
+func warnFailedValidation(report output.AllResult) {
+	log.WithFields(log.Fields{
+		"image":  logutil.SanitizeLogValue(report.Image),
+		"failed": strings.Join(failedCheckNames(report.Checks), ","),
+	}).Warn("Image failed validation")
+}
+
+// watchedImageID returns the image ID (config digest) of a watched image,
+// which is the same for every tag of the image.
+func watchedImageID(ctx context.Context, imageName string) (string, error) {
+	img, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	id, err := img.ConfigName()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// failedCheckNames returns the names of the checks that did not pass.
+func failedCheckNames(results []output.CheckResult) []string {
+	var names []string
+	for _, r := range results {
+		if !r.Passed {
+			names = append(names, r.Check)
+		}
+	}
+	return names
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"github.com/jarfernandez/check-image/internal/daemonwatch"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// watchConfig is the configuration daemon-watch validates with. It is only
+// replaced once a changed --config file parses, so a file that is being
+// rewritten never takes effect half-written. It is nil outside daemon-watch.
+var watchConfig *allConfig
+
+// watchPolicyHash is the policy hash of watchConfig and the policy files it
+// references, reported with every validation of daemon-watch.
+var watchPolicyHash string
+
+// reloadWatchPolicy reads --config and the policy files again and makes them
+// the active policy of daemon-watch. The first load must succeed; later
+// failures are logged and keep the active policy.
+func reloadWatchPolicy(cmd *cobra.Command, health *daemonwatch.Health) error {
+	cfg, err := loadWatchConfig()
+	if err == nil {
+		var hash string
+		if hash, err = resolvePolicyHash(cmd, cfg); err == nil {
+			if watchPolicyHash != "" && hash != watchPolicyHash {
+				log.WithFields(log.Fields{"previous": watchPolicyHash, "policy-hash": hash}).Info("Policy reloaded")
+			}
+			watchConfig = cfg
+			watchPolicyHash = hash
+			health.SetPolicyHash(hash)
+			return nil
+		}
+	}
+	if watchPolicyHash == "" {
+		return err
+	}
+	log.WithFields(log.Fields{"error": err, "policy-hash": watchPolicyHash}).Warn("Invalid policy, keeping the active policy")
+	return nil
+}
+
+// loadWatchConfig reads --config or the --policy profile, or returns nil
+// without one. A config read from stdin cannot be read again and is kept.
+func loadWatchConfig() (*allConfig, error) {
+	path, err := configSource()
+	switch {
+	case err != nil:
+		return nil, err
+	case path == "":
+		return nil, nil
+	case path == "-" && watchConfig != nil:
+		return watchConfig, nil
+	default:
+		return loadAllConfig(path)
+	}
+}
+
+// resolvePolicyHash returns the policy hash of a validation with cfg, the
+// same hash --annotate-registry records.
+func resolvePolicyHash(cmd *cobra.Command, cfg *allConfig) (string, error) {
+	skipMap, err := parseCheckNameList(skipChecks, "skip")
+	if err != nil {
+		return "", err
+	}
+	includeMap, err := parseCheckNameList(includeChecks, "include")
+	if err != nil {
+		return "", err
+	}
+	if cfg != nil {
+		resetConfigValues(cmd)
+		cleanup, err := applyConfigValues(cmd, cfg)
+		defer cleanup()
+		if err != nil {
+			return "", err
+		}
+	}
+	p := currentCheckParams()
+	return policyHash(determineChecks(cfg, skipMap, includeMap, p), p), nil
+}
+
+// watchBaseline holds the values of the daemon-watch flags before any config
+// was applied, keyed by flag name.
+var watchBaseline map[string]string
+
+// captureConfigBaseline records the flag values that resetConfigValues
+// restores.
+func captureConfigBaseline(cmd *cobra.Command) {
+	watchBaseline = configFlagValues(cmd)
+}
+
+// resetConfigValues restores the flag values recorded by
+// captureConfigBaseline, so values removed from a reloaded config file stop
+// applying.
+func resetConfigValues(cmd *cobra.Command) {
+	restoreFlagValues(cmd, watchBaseline)
+	ageWindow, sizeWindow = nil, nil
+	ageRules = nil
+}
+
+// configFlagValues returns the values of the flags of cmd a config file may
+// override, keyed by flag name: its local flags and the global
+// --docs-base-url and --units.
+func configFlagValues(cmd *cobra.Command) map[string]string {
+	values := map[string]string{}
+	record := func(f *pflag.Flag) { values[f.Name] = f.Value.String() }
+	cmd.LocalFlags().VisitAll(record)
+	for _, name := range []string{"docs-base-url", "units"} {
+		if f := cmd.Flags().Lookup(name); f != nil {
+			record(f)
+		}
+	}
+	return values
+}
+
+// restoreFlagValues sets the flags of cmd back to values. Flags that kept
+// their value are left alone, as setting a slice flag appends to it.
+func restoreFlagValues(cmd *cobra.Command, values map[string]string) {
+	for name, value := range values {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() != value {
+			_ = f.Value.Set(value)
+		}
+	}
+}
+
+// snapshotConfigValues records the values a config file may override and
+// returns a function that restores them, so that the config or profile of
+// one image does not apply to the images validated after it.
+func snapshotConfigValues(cmd *cobra.Command) func() {
+	flags := configFlagValues(cmd)
+	prevAgeWindow, prevSizeWindow, prevAgeRules := ageWindow, sizeWindow, ageRules
+	return func() {
+		restoreFlagValues(cmd, flags)
+		ageWindow, sizeWindow, ageRules = prevAgeWindow, prevSizeWindow, prevAgeRules
+	}
+}
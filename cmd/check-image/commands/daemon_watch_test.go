@@ -0,0 +1,488 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/jarfernandez/check-image/internal/daemonwatch"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWatchSource replays a fixed list of events, then ends the stream with
+// err (if set) or by closing the event channel. With closeOnErr, the event
+// channel is also closed after err is sent, as the Docker source does, before
+// the stream is returned, so that both are ready when the watch selects.
+type fakeWatchSource struct {
+	events     []daemonwatch.Event
+	err        error
+	closeOnErr bool
+}
+
+func (s *fakeWatchSource) Events(ctx context.Context) (<-chan daemonwatch.Event, <-chan error) {
+	out := make(chan daemonwatch.Event)
+	errs := make(chan error, 1)
+	if s.closeOnErr {
+		buffered := make(chan daemonwatch.Event, len(s.events))
+		for _, ev := range s.events {
+			buffered <- ev
+		}
+		errs <- s.err
+		close(buffered)
+		return buffered, errs
+	}
+	go func() {
+		for _, ev := range s.events {
+			out <- ev
+		}
+		if s.err != nil {
+			errs <- s.err
+			return
+		}
+		close(out)
+	}()
+	return out, errs
+}
+
+func useFakeWatchSource(t *testing.T, src *fakeWatchSource) *[]string {
+	t.Helper()
+	var actions []string
+	prev := newWatchSource
+	newWatchSource = func(a []string) (daemonwatch.Source, error) {
+		actions = a
+		return src, nil
+	}
+	t.Cleanup(func() { newWatchSource = prev })
+	return &actions
+}
+
+func TestRunDaemonWatch(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	var mu sync.Mutex
+	var alerts []output.AllResult
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var report output.AllResult
+		_ = json.Unmarshal(body, &report)
+		mu.Lock()
+		alerts = append(alerts, report)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	alertWebhook = server.URL
+	watchEvents = "pull,load"
+
+	passing := createTestImage(t, testImageOptions{user: "1000"})
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	actions := useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: passing},
+		{Action: "load", Image: failing},
+	}})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	assert.Equal(t, []string{"pull", "load"}, *actions)
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []output.AllResult
+	for dec.More() {
+		var r output.AllResult
+		require.NoError(t, dec.Decode(&r))
+		reports = append(reports, r)
+	}
+	require.Len(t, reports, 2)
+	assert.Equal(t, passing, reports[0].Image)
+	assert.Equal(t, failing, reports[1].Image)
+	assert.False(t, reports[1].Passed)
+
+	require.Len(t, alerts, 1, "only the failing image is alerted")
+	assert.Equal(t, failing, alerts[0].Image)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunDaemonWatch_ResultIsScopedPerImage(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	failFast = true
+	OutputFmt = output.FormatJSON
+
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	passing := createTestImage(t, testImageOptions{user: "1000"})
+	useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: failing},
+		{Action: "pull", Image: passing},
+	}})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []output.AllResult
+	for dec.More() {
+		var r output.AllResult
+		require.NoError(t, dec.Decode(&r))
+		reports = append(reports, r)
+	}
+	require.Len(t, reports, 2)
+	assert.False(t, reports[0].Passed)
+	assert.True(t, reports[1].Passed, "an earlier failure must not fail later images")
+	assert.Len(t, reports[1].Checks, 1)
+	assert.Equal(t, ValidationFailed, Result, "the overall result keeps the failure")
+}
+
+func TestRunDaemonWatch_SourceError(t *testing.T) {
+	resetAllGlobals(t)
+	useFakeWatchSource(t, &fakeWatchSource{err: errors.New("connection reset")})
+
+	err := runDaemonWatch(daemonWatchCmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+}
+
+func TestRunDaemonWatch_SourceErrorClosesStream(t *testing.T) {
+	// The closed event channel and the error are ready at once, and select
+	// picks either; repeat so that both orders are taken.
+	for range 20 {
+		resetAllGlobals(t)
+		useFakeWatchSource(t, &fakeWatchSource{err: errors.New("connection reset"), closeOnErr: true})
+
+		err := runDaemonWatch(daemonWatchCmd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection reset")
+	}
+}
+
+func TestRunDaemonWatch_DrainsInFlightValidation(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The webhook receives the alert of the first image while its validation
+	// is in flight, and stops the watch as a SIGTERM would.
+	var alerts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		alerts++
+	}))
+	defer server.Close()
+	alertWebhook = server.URL
+
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: failing},
+		{Action: "pull", Image: failing},
+	}})
+
+	daemonWatchCmd.SetContext(ctx)
+	t.Cleanup(func() { daemonWatchCmd.SetContext(context.Background()) })
+	out := captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	var report output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &report), "only the in-flight image is reported")
+	assert.Equal(t, failing, report.Image)
+	assert.Equal(t, 1, alerts, "the in-flight alert completes after shutdown")
+	assert.Equal(t, ctx, daemonWatchCmd.Context(), "the command context is restored")
+}
+
+func TestRunDaemonWatch_ReloadsPolicy(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	dedupTTL = 0
+	configFile = writeRequiredConfig(t, "checks:\n  user: {}\n")
+
+	// Each alert rewrites the config before the next image is validated:
+	// first with a config that does not parse, then with one more check.
+	var alerts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alerts++
+		content := "checks: [\n"
+		if alerts > 1 {
+			content = "checks:\n  user: {}\n  age:\n    max-age: 36500\n"
+		}
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+	}))
+	defer server.Close()
+	alertWebhook = server.URL
+
+	image := createTestImage(t, testImageOptions{user: "root"})
+	useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: image},
+		{Action: "pull", Image: image},
+		{Action: "pull", Image: image},
+	}})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []output.AllResult
+	for dec.More() {
+		var r output.AllResult
+		require.NoError(t, dec.Decode(&r))
+		reports = append(reports, r)
+	}
+	require.Len(t, reports, 3)
+	assert.NotEmpty(t, reports[0].PolicyHash)
+	assert.Len(t, reports[0].Checks, 1)
+	assert.Equal(t, reports[0].PolicyHash, reports[1].PolicyHash, "an invalid config keeps the active policy")
+	assert.Len(t, reports[1].Checks, 1)
+	assert.NotEqual(t, reports[0].PolicyHash, reports[2].PolicyHash, "a changed config is reloaded")
+	assert.Len(t, reports[2].Checks, 2)
+	assert.Nil(t, watchConfig, "the pinned config is released when the watch ends")
+}
+
+func TestRunDaemonWatch_ReusesResultOfSameImage(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	var alerts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alerts++
+	}))
+	defer server.Close()
+	alertWebhook = server.URL
+
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	other := createTestImage(t, testImageOptions{user: "0"})
+	useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: failing},
+		{Action: "tag", Image: failing},
+		{Action: "pull", Image: other},
+	}})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []output.AllResult
+	for dec.More() {
+		var r output.AllResult
+		require.NoError(t, dec.Decode(&r))
+		reports = append(reports, r)
+	}
+	require.Len(t, reports, 3, "every event is reported")
+	assert.Equal(t, reports[0], reports[1])
+	assert.Equal(t, 2, alerts, "the repeated image is alerted once")
+}
+
+func TestRunDaemonWatch_ReusedFailureIsReported(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	auditLog = filepath.Join(t.TempDir(), "audit.jsonl")
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	failing := createTestImage(t, testImageOptions{user: "root"})
+	useFakeWatchSource(t, &fakeWatchSource{events: []daemonwatch.Event{
+		{Action: "pull", Image: failing},
+		{Action: "tag", Image: failing},
+	}})
+
+	captureStdout(t, func() {
+		require.NoError(t, runDaemonWatch(daemonWatchCmd))
+	})
+
+	assert.Equal(t, 2, strings.Count(logs.String(), "Image failed validation"), "the reused failure is warned about in text mode")
+	records, err := auditlog.ReadTail(auditLog, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.False(t, records[0].Reused)
+	assert.True(t, records[1].Reused)
+	assert.False(t, records[1].Passed)
+	assert.Equal(t, []string{"user"}, records[1].FailedChecks)
+	assert.Equal(t, records[0].PolicyHash, records[1].PolicyHash)
+}
+
+func TestRunDaemonWatch_InvalidInitialConfig(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRequiredConfig(t, "checks: [\n")
+	useFakeWatchSource(t, &fakeWatchSource{})
+
+	err := runDaemonWatch(daemonWatchCmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config file")
+}
+
+func TestResetConfigValues(t *testing.T) {
+	resetAllGlobals(t)
+	maxSize = 100
+	captureConfigBaseline(daemonWatchCmd)
+
+	maxAge = 7
+	maxSize = 200
+	ageWindow = &output.PolicyWindow{Reason: "freeze"}
+	resetConfigValues(daemonWatchCmd)
+
+	assert.Equal(t, uint(defaultMaxAgeDays), maxAge, "values applied from a config are reset")
+	assert.Equal(t, uint(100), maxSize, "values set before the watch are restored")
+	assert.Nil(t, ageWindow)
+}
+
+func TestRunDaemonWatch_InvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  string
+		webhook string
+		timeout time.Duration
+		reload  time.Duration
+		dedup   time.Duration
+		token   string
+		ui      string
+		wantErr string
+	}{
+		{name: "Unsupported event", events: "delete", wantErr: "unsupported event"},
+		{name: "Webhook is not a URL", events: "pull", webhook: "hooks.example.com", wantErr: "--alert-webhook must be an http or https URL"},
+		{name: "Negative shutdown timeout", events: "pull", timeout: -time.Second, wantErr: "--shutdown-timeout must not be negative"},
+		{name: "Negative reload interval", events: "pull", reload: -time.Second, wantErr: "--reload-interval must not be negative"},
+		{name: "Negative dedup TTL", events: "pull", dedup: -time.Second, wantErr: "--dedup-ttl must not be negative"},
+		{name: "Token without health address", events: "pull", token: "token", wantErr: "--auth-token-file requires --health-addr or --ui-addr"},
+		{name: "Dashboard on every interface without a token", events: "pull", ui: ":8082", wantErr: "--ui-addr :8082 is not a loopback address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			watchEvents = tt.events
+			alertWebhook = tt.webhook
+			shutdownTimeout = tt.timeout
+			reloadInterval = tt.reload
+			dedupTTL = tt.dedup
+			authTokenFile = tt.token
+			uiAddr = tt.ui
+			useFakeWatchSource(t, &fakeWatchSource{})
+
+			err := runDaemonWatch(daemonWatchCmd)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateWatchUI(t *testing.T) {
+	require.NoError(t, validateWatchUI("", 0, ""))
+	for _, addr := range []string{"localhost:8082", "127.0.0.1:8082", "[::1]:8082"} {
+		require.NoError(t, validateWatchUI(addr, 10, ""), addr)
+	}
+	for _, addr := range []string{":8082", "0.0.0.0:8082", "10.0.0.5:8082", "dashboard.example.com:8082", "invalid"} {
+		err := validateWatchUI(addr, 10, "")
+		require.Error(t, err, addr)
+		assert.Contains(t, err.Error(), "is not a loopback address; set --auth-token-file or CHECK_IMAGE_AUTH_TOKEN")
+		require.NoError(t, validateWatchUI(addr, 10, "s3cret"), "a token allows any address")
+	}
+
+	err := validateWatchUI("localhost:8082", 0, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--ui-history must be at least 1")
+}
+
+func TestLoadAuthToken(t *testing.T) {
+	noEnv := func(string) string { return "" }
+
+	token, err := loadAuthToken("", noEnv)
+	require.NoError(t, err)
+	assert.Empty(t, token, "no token disables authentication")
+
+	token, err = loadAuthToken("", func(key string) string {
+		assert.Equal(t, authTokenEnv, key)
+		return " from-env\n"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", token)
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+	token, err = loadAuthToken(path, func(string) string { return "from-env" })
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", token, "the file takes precedence over the environment")
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0600))
+	_, err = loadAuthToken(empty, noEnv)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is empty")
+
+	_, err = loadAuthToken(filepath.Join(t.TempDir(), "missing"), noEnv)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read --auth-token-file")
+}
+
+func TestSeedHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	history := daemonwatch.NewHistory(2)
+	seedHistory(history, path, 2)
+	records, _ := history.Records()
+	assert.Empty(t, records, "a log not written yet has no validations")
+
+	for _, image := range []string{"nginx:1.26", "nginx:1.27", "app:1.0"} {
+		require.NoError(t, auditlog.Append(context.Background(), path, auditlog.Record{Image: image, Passed: true}))
+	}
+	history = daemonwatch.NewHistory(2)
+	seedHistory(history, path, 2)
+	records, first := history.Records()
+	require.Len(t, records, 2)
+	assert.Equal(t, "nginx:1.27", records[0].Image, "only the last validations are read")
+	assert.Equal(t, "app:1.0", records[1].Image)
+	assert.Equal(t, 1, first)
+
+	history = daemonwatch.NewHistory(2)
+	seedHistory(history, "syslog", 2)
+	records, _ = history.Records()
+	assert.Empty(t, records)
+}
+
+func TestRecordAudit_DashboardHistory(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	includeChecks = "user"
+	watchHistory = daemonwatch.NewHistory(10)
+
+	image := createTestImage(t, testImageOptions{user: "root"})
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image))
+	})
+
+	records, _ := watchHistory.Records()
+	require.Len(t, records, 1, "validations are kept without --audit-log")
+	assert.Equal(t, image, records[0].Image)
+	assert.False(t, records[0].Passed)
+	assert.Equal(t, []string{"user"}, records[0].FailedChecks)
+}
+
+func TestFailedCheckNames(t *testing.T) {
+	results := []output.CheckResult{
+		{Check: checkAge, Passed: true},
+		{Check: checkUser, Passed: false},
+		{Check: checkSize, Passed: false, Error: "boom"},
+	}
+	assert.Equal(t, []string{checkUser, checkSize}, failedCheckNames(results))
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/dockerhub"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// checkDeprecationFlag enables the opt-in deprecation check of the all
+// command.
+var checkDeprecationFlag bool
+
+// dockerHubAPIURL is the Docker Hub API queried by the deprecation check;
+// tests replace it.
+var dockerHubAPIURL = dockerhub.DefaultAPIURL
+
+var deprecationCmd = &cobra.Command{
+	Use:   "deprecation image",
+	Short: "Validate that the image is not a deprecated Docker Hub official image",
+	Long: `Validate that the image is not a deprecated Docker Hub official image.
+
+For docker.io/library images (e.g., openjdk, centos), the Docker Hub API is
+queried, and the check fails when the image description carries a deprecation
+notice. The maintained replacement is suggested in the remediation field when
+known. Other images pass without a query, as do official images Docker Hub no
+longer lists. The check needs access to hub.docker.com.
+
+` + imageArgFormatsDoc,
+	Example: `  check-image deprecation openjdk:17
+  check-image deprecation docker.io/library/centos:7 -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		return runCheckCmd(checkDeprecation, runDeprecation, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deprecationCmd)
+}
+
+func runDeprecation(ctx context.Context, imageName string) (*output.CheckResult, error) {
+	result := &output.CheckResult{
+		Check:   checkDeprecation,
+		Image:   imageName,
+		Passed:  true,
+		Message: "Image is not a Docker Hub official image",
+		Details: output.DeprecationDetails{},
+	}
+
+	ref, err := imageutil.ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Transport != imageutil.TransportDaemonRegistry {
+		return result, nil
+	}
+	repository, err := imageutil.GetImageRepository(imageName)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := dockerhub.OfficialImage(repository)
+	if !ok {
+		return result, nil
+	}
+
+	status, err := dockerhub.Lookup(ctx, dockerHubAPIURL, name)
+	if err != nil {
+		return nil, err
+	}
+	result.Details = output.DeprecationDetails{
+		OfficialImage: name,
+		Deprecated:    status.Deprecated,
+		Notice:        status.Notice,
+		Replacement:   status.Replacement,
+	}
+	switch {
+	case !status.Found:
+		result.Message = fmt.Sprintf("Official image %s is not listed on Docker Hub", name)
+	case !status.Deprecated:
+		result.Message = fmt.Sprintf("Official image %s is not deprecated", name)
+	default:
+		result.Passed = false
+		result.Message = fmt.Sprintf("Official image %s is deprecated", name)
+		result.Remediation = deprecationRemediation(name, status.Replacement)
+	}
+	return result, nil
+}
+
+// deprecationRemediation suggests the replacement of the deprecated official
+// image name, or its Docker Hub page when the replacement is not known.
+func deprecationRemediation(name, replacement string) string {
+	if replacement != "" {
+		return fmt.Sprintf("Replace %s with the maintained %s image", name, replacement)
+	}
+	return fmt.Sprintf("Replace %s with a maintained image; see the alternatives at https://hub.docker.com/_/%s", name, name)
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDockerHub serves a Docker Hub API that lists openjdk as deprecated
+// and nginx as maintained.
+func newTestDockerHub(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/repositories/library/openjdk/":
+			_, _ = w.Write([]byte(`{"full_description": "# DEPRECATION NOTICE\n\nThis image is officially deprecated.\n"}`))
+		case "/v2/repositories/library/nginx/":
+			_, _ = w.Write([]byte(`{"full_description": "# Quick reference\n"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRunDeprecation(t *testing.T) {
+	tests := []struct {
+		name            string
+		image           string
+		wantPassed      bool
+		wantMessage     string
+		wantRemediation string
+		wantDetails     output.DeprecationDetails
+	}{
+		{
+			name:            "deprecated official image",
+			image:           "openjdk:17",
+			wantMessage:     "Official image openjdk is deprecated",
+			wantRemediation: "Replace openjdk with the maintained eclipse-temurin image",
+			wantDetails: output.DeprecationDetails{
+				OfficialImage: "openjdk",
+				Deprecated:    true,
+				Notice:        "This image is officially deprecated.",
+				Replacement:   "eclipse-temurin",
+			},
+		},
+		{
+			name:        "maintained official image",
+			image:       "docker.io/library/nginx:latest",
+			wantPassed:  true,
+			wantMessage: "Official image nginx is not deprecated",
+			wantDetails: output.DeprecationDetails{OfficialImage: "nginx"},
+		},
+		{
+			name:        "unlisted official image",
+			image:       "busybox:latest",
+			wantPassed:  true,
+			wantMessage: "Official image busybox is not listed on Docker Hub",
+			wantDetails: output.DeprecationDetails{OfficialImage: "busybox"},
+		},
+		{
+			name:        "other registry",
+			image:       "ghcr.io/org/openjdk:17",
+			wantPassed:  true,
+			wantMessage: "Image is not a Docker Hub official image",
+		},
+		{
+			name:        "other transport",
+			image:       "oci:/path/to/layout:latest",
+			wantPassed:  true,
+			wantMessage: "Image is not a Docker Hub official image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			dockerHubAPIURL = newTestDockerHub(t)
+
+			result, err := runDeprecation(context.Background(), tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, checkDeprecation, result.Check)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Equal(t, tt.wantMessage, result.Message)
+			assert.Equal(t, tt.wantRemediation, result.Remediation)
+			assert.Equal(t, tt.wantDetails, result.Details)
+		})
+	}
+}
+
+func TestDeprecationRemediation(t *testing.T) {
+	assert.Equal(t, "Replace java with the maintained eclipse-temurin image", deprecationRemediation("java", "eclipse-temurin"))
+	assert.Equal(t, "Replace clefos with a maintained image; see the alternatives at https://hub.docker.com/_/clefos", deprecationRemediation("clefos", ""))
+}
+
+func TestRunDeprecation_HubUnavailable(t *testing.T) {
+	resetAllGlobals(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	dockerHubAPIURL = server.URL
+
+	_, err := runDeprecation(context.Background(), "openjdk:17")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 503")
+}
+
+func TestBuildCheckDefs_DeprecationOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	enabled := func(p checkParams, cfg *allConfig) bool {
+		for _, def := range buildCheckDefs(cfg, p) {
+			if def.name == checkDeprecation {
+				return def.enabled
+			}
+		}
+		t.Fatal("deprecation check not defined")
+		return false
+	}
+
+	assert.False(t, enabled(checkParams{}, nil))
+	assert.True(t, enabled(checkParams{checkDeprecation: true}, nil))
+	assert.False(t, enabled(checkParams{checkDeprecation: true}, &allConfig{}))
+	assert.True(t, enabled(checkParams{}, &allConfig{Checks: allChecksConfig{Deprecation: &deprecationCheckConfig{}}}))
+}
+
+func TestRenderDeprecationText(t *testing.T) {
+	out := captureStdout(t, func() {
+		require.NoError(t, renderResult(&output.CheckResult{
+			Check:       checkDeprecation,
+			Image:       "openjdk:17",
+			Message:     "Official image openjdk is deprecated",
+			Remediation: "Replace openjdk with the maintained eclipse-temurin image",
+			Details:     output.DeprecationDetails{OfficialImage: "openjdk", Deprecated: true, Notice: "This image is officially deprecated."},
+		}, output.FormatText))
+	})
+	assert.Contains(t, out, "Notice: This image is officially deprecated.")
+	assert.Contains(t, out, "Remediation: Replace openjdk with the maintained eclipse-temurin image")
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// defaultDocsBaseURL points each check to its section of the project README.
+const defaultDocsBaseURL = "https://github.com/jarfernandez/check-image#{check}"
+
+// docsCheckPlaceholder is replaced with the check name in the docs base URL.
+// Without it, the check name is appended as the last path segment.
+const docsCheckPlaceholder = "{check}"
+
+var docsBaseURL = defaultDocsBaseURL
+
+// validateDocsBaseURL ensures the docs base URL is an absolute http(s) URL.
+// An empty value is valid and disables documentation links.
+func validateDocsBaseURL(base string) error {
+	if base == "" {
+		return nil
+	}
+	u, err := url.Parse(strings.ReplaceAll(base, docsCheckPlaceholder, "check"))
+	if err != nil {
+		return fmt.Errorf("invalid docs base URL %q: %w", base, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid docs base URL %q: must be an absolute http or https URL", base)
+	}
+	return nil
+}
+
+// checkDocsURL returns the documentation URL of a check, or an empty string
+// when documentation links are disabled.
+func checkDocsURL(check string) string {
+	if docsBaseURL == "" {
+		return ""
+	}
+	if strings.Contains(docsBaseURL, docsCheckPlaceholder) {
+		return strings.ReplaceAll(docsBaseURL, docsCheckPlaceholder, url.PathEscape(check))
+	}
+	return strings.TrimRight(docsBaseURL, "/") + "/" + url.PathEscape(check)
+}
+
+// setDocsURL fills in the documentation URL of a check result.
+func setDocsURL(r *output.CheckResult) {
+	r.DocsURL = checkDocsURL(r.Check)
+}
+
+// printDocsLink prints the documentation link of a failed check in text mode.
+// The URL is wrapped in an OSC 8 hyperlink on terminals that support it.
+func printDocsLink(w io.Writer, r *output.CheckResult) {
+	if r.Passed || r.DocsURL == "" {
+		return
+	}
+	link := r.DocsURL
+	if hyperlinks {
+		link = termenv.Hyperlink(r.DocsURL, r.DocsURL)
+	}
+	fmt.Fprintf(w, "%s %s\n", dimStyle.Render("Docs:"), link)
+}
+
+// applyDocsConfig applies the docs-base-url of a config file. The returned
+// cleanup restores the previous base URL, so that the config of one image
+// does not leak into the next validation of a bulk or daemon-watch run.
+func applyDocsConfig(cmd *cobra.Command, base string) (func(), error) {
+	if base == "" || cmd.Flags().Changed("docs-base-url") {
+		return func() {}, nil
+	}
+	if err := validateDocsBaseURL(base); err != nil {
+		return func() {}, err
+	}
+	previous := docsBaseURL
+	docsBaseURL = base
+	return func() { docsBaseURL = previous }, nil
+}
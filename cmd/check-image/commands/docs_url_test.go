@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDocsURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		want string
+	}{
+		{name: "Default README anchor", base: defaultDocsBaseURL, want: "https://github.com/jarfernandez/check-image#age"},
+		{name: "Placeholder in path", base: "https://wiki.example.com/checks/{check}/index.html", want: "https://wiki.example.com/checks/age/index.html"},
+		{name: "Appended as path segment", base: "https://wiki.example.com/checks", want: "https://wiki.example.com/checks/age"},
+		{name: "Trailing slash", base: "https://wiki.example.com/checks/", want: "https://wiki.example.com/checks/age"},
+		{name: "Disabled", base: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			docsBaseURL = tt.base
+			assert.Equal(t, tt.want, checkDocsURL(checkAge))
+		})
+	}
+}
+
+func TestValidateDocsBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		wantErr bool
+	}{
+		{name: "Empty disables links", base: ""},
+		{name: "HTTPS with placeholder", base: "https://wiki.example.com/{check}"},
+		{name: "HTTP", base: "http://wiki.internal/checks"},
+		{name: "Relative path", base: "/checks", wantErr: true},
+		{name: "Unsupported scheme", base: "ftp://wiki.example.com", wantErr: true},
+		{name: "Missing host", base: "https://", wantErr: true},
+		{name: "Malformed", base: "https://wiki.example.com/%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDocsBaseURL(tt.base)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid docs base URL")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestApplyDocsConfig(t *testing.T) {
+	t.Run("Config value is applied", func(t *testing.T) {
+		resetAllGlobals(t)
+		cleanup, err := applyDocsConfig(&cobra.Command{}, "https://wiki.example.com/checks")
+		require.NoError(t, err)
+		assert.Equal(t, "https://wiki.example.com/checks", docsBaseURL)
+		cleanup()
+		assert.Equal(t, defaultDocsBaseURL, docsBaseURL, "the cleanup restores the previous base URL")
+	})
+
+	t.Run("Cleanup of applyConfigValues restores the base URL", func(t *testing.T) {
+		resetAllGlobals(t)
+		cleanup, err := applyConfigValues(&cobra.Command{}, &allConfig{DocsBaseURL: "https://wiki.example.com/checks"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://wiki.example.com/checks", docsBaseURL)
+		cleanup()
+		assert.Equal(t, defaultDocsBaseURL, docsBaseURL)
+	})
+
+	t.Run("CLI flag takes precedence", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVar(&docsBaseURL, "docs-base-url", defaultDocsBaseURL, "")
+		require.NoError(t, cmd.Flags().Set("docs-base-url", "https://cli.example.com"))
+		cleanup, err := applyDocsConfig(cmd, "https://wiki.example.com/checks")
+		require.NoError(t, err)
+		cleanup()
+		assert.Equal(t, "https://cli.example.com", docsBaseURL)
+	})
+
+	t.Run("Invalid config value", func(t *testing.T) {
+		resetAllGlobals(t)
+		_, err := applyDocsConfig(&cobra.Command{}, "wiki/checks")
+		require.Error(t, err)
+		assert.Equal(t, defaultDocsBaseURL, docsBaseURL)
+	})
+}
+
+func TestPrintDocsLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     output.CheckResult
+		hyperlinks bool
+		want       string
+	}{
+		{
+			name:   "Failed check prints plain link",
+			result: output.CheckResult{Check: checkAge, DocsURL: "https://wiki.example.com/age"},
+			want:   "Docs: https://wiki.example.com/age\n",
+		},
+		{
+			name:       "Failed check prints OSC 8 hyperlink",
+			result:     output.CheckResult{Check: checkAge, DocsURL: "https://wiki.example.com/age"},
+			hyperlinks: true,
+			want:       "Docs: \x1b]8;;https://wiki.example.com/age\x1b\\https://wiki.example.com/age\x1b]8;;\x1b\\\n",
+		},
+		{
+			name:   "Passed check prints nothing",
+			result: output.CheckResult{Check: checkAge, Passed: true, DocsURL: "https://wiki.example.com/age"},
+		},
+		{
+			name:   "No URL prints nothing",
+			result: output.CheckResult{Check: checkAge},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := hyperlinks
+			hyperlinks = tt.hyperlinks
+			t.Cleanup(func() { hyperlinks = prev })
+
+			out := captureStdout(t, func() {
+				printDocsLink(stdout, &tt.result)
+			})
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestRunAll_DocsURLInJSON(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	docsBaseURL = "https://wiki.example.com/check-image/{check}"
+	OutputFmt = output.FormatJSON
+
+	imageRef := createTestImage(t, testImageOptions{user: "root"})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Len(t, result.Checks, 1)
+	assert.Equal(t, "https://wiki.example.com/check-image/user", result.Checks[0].DocsURL)
+}
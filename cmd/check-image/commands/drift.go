@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/drift"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var goldenSpec string
+var driftRecord bool
+
+var driftCmd = &cobra.Command{
+	Use:   "drift image",
+	Short: "Validate that the image configuration matches a golden spec",
+	Long: `Validate that the image configuration has not drifted from a golden spec
+recorded from a previously approved image.
+
+The user, environment variable names, entrypoint, cmd, exposed ports, and labels
+are compared; environment variable values are not. Any difference fails the
+check. Labels listed in the spec's ignored-labels are not compared; recorded
+specs ignore the OCI created, revision, and version labels, which change with
+every build.
+
+Record the spec of an approved image with --record, then validate new builds
+against it. The spec is written as YAML, or as JSON when the file name ends
+in .json; "-" writes it to stdout.
+
+` + imageArgFormatsDoc,
+	Example: `  check-image drift appliance:1.0 --golden-spec golden-spec.yaml --record
+  check-image drift appliance:1.1 --golden-spec golden-spec.yaml
+  check-image drift oci:/path/to/layout:1.1 --golden-spec golden-spec.json -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if driftRecord {
+			if err := runDriftRecord(ctx, args[0], goldenSpec); err != nil {
+				return fmt.Errorf("drift operation failed: %w", err)
+			}
+			return nil
+		}
+		return runCheckCmd(checkDrift, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runDrift(ctx, img, goldenSpec)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+	driftCmd.Flags().StringVar(&goldenSpec, "golden-spec", "", "Golden spec file (JSON or YAML) recorded from an approved image")
+	driftCmd.Flags().BoolVar(&driftRecord, "record", false, "Record the configuration of the image to --golden-spec instead of validating it (optional)")
+	if err := driftCmd.MarkFlagRequired("golden-spec"); err != nil {
+		panic(fmt.Sprintf("failed to mark golden-spec flag as required: %v", err))
+	}
+}
+
+func runDrift(ctx context.Context, imageName string, specPath string) (*output.CheckResult, error) {
+	spec, err := drift.LoadSpec(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load golden spec: %w", err)
+	}
+
+	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	diffs := drift.Compare(spec, config)
+	log.Debugf("Golden spec differences: %d", len(diffs))
+
+	details := output.DriftDetails{Differences: make([]output.DriftDifference, 0, len(diffs))}
+	for _, d := range diffs {
+		details.Differences = append(details.Differences, output.DriftDifference{
+			Field:    d.Field,
+			Kind:     d.Kind,
+			Key:      d.Key,
+			Expected: d.Expected,
+			Actual:   d.Actual,
+		})
+	}
+
+	passed := len(diffs) == 0
+	msg := "Image configuration matches the golden spec"
+	if !passed {
+		msg = fmt.Sprintf("Image configuration drifted from the golden spec (%d differences)", len(diffs))
+	}
+
+	return &output.CheckResult{
+		Check:   checkDrift,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: details,
+	}, nil
+}
+
+// runDriftRecord writes the golden spec of an image to specPath, or to stdout
+// when specPath is "-".
+func runDriftRecord(ctx context.Context, imageName string, specPath string) error {
+	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	spec := drift.Record(config)
+	toStdout := specPath == "-"
+	asJSON := strings.EqualFold(filepath.Ext(specPath), ".json") || toStdout && OutputFmt == output.FormatJSON
+	data, err := spec.Marshal(asJSON)
+	if err != nil {
+		return err
+	}
+
+	if toStdout {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return err
+		}
+		UpdateResult(ValidationSucceeded)
+		return nil
+	}
+	if err := os.WriteFile(specPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write golden spec: %w", err)
+	}
+	UpdateResult(ValidationSucceeded)
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, output.DriftRecordResult{Image: imageName, Path: specPath, Spec: spec})
+	}
+	fmt.Println(statusPrefix(true) + fmt.Sprintf("Recorded the golden spec of %s to %s", imageName, specPath))
+	return nil
+}
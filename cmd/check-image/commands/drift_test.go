@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var driftTestImage = testImageOptions{
+	user:         "1000",
+	env:          []string{"PATH=/usr/bin", "APP_MODE=prod"},
+	entrypoint:   []string{"/app/server"},
+	exposedPorts: map[string]struct{}{"8080/tcp": {}},
+	labels: map[string]string{
+		"org.opencontainers.image.title":   "app",
+		"org.opencontainers.image.created": "2026-01-01T00:00:00Z",
+	},
+}
+
+// recordGoldenSpec records the golden spec of image to a file named name.
+func recordGoldenSpec(t *testing.T, image, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	captureStdout(t, func() {
+		require.NoError(t, runDriftRecord(context.Background(), image, path))
+	})
+	return path
+}
+
+func TestDriftCommand(t *testing.T) {
+	assert.Equal(t, "drift image", driftCmd.Use)
+	assert.Error(t, driftCmd.Args(driftCmd, []string{}))
+	assert.NoError(t, driftCmd.Args(driftCmd, []string{"image"}))
+
+	for _, name := range []string{"golden-spec", "record"} {
+		assert.NotNil(t, driftCmd.Flags().Lookup(name), name)
+	}
+}
+
+func TestRunDriftRecord(t *testing.T) {
+	resetAllGlobals(t)
+	image := createTestImage(t, driftTestImage)
+
+	path := filepath.Join(t.TempDir(), "golden-spec.yaml")
+	out := captureStdout(t, func() {
+		require.NoError(t, runDriftRecord(context.Background(), image, path))
+	})
+	assert.Contains(t, out, "Recorded the golden spec of")
+	assert.Equal(t, ValidationSucceeded, Result)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "user: \"1000\"")
+	assert.Contains(t, string(data), "- 8080/tcp")
+	assert.NotContains(t, string(data), "2026-01-01", "ignored labels are not recorded")
+
+	t.Run("JSON to stdout", func(t *testing.T) {
+		resetAllGlobals(t)
+		OutputFmt = output.FormatJSON
+		out := captureStdout(t, func() {
+			require.NoError(t, runDriftRecord(context.Background(), image, "-"))
+		})
+		var spec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &spec))
+		assert.Equal(t, "1000", spec["user"])
+	})
+}
+
+func TestRunDrift(t *testing.T) {
+	spec := recordGoldenSpec(t, createTestImage(t, driftTestImage), "golden-spec.json")
+
+	t.Run("No drift", func(t *testing.T) {
+		opts := driftTestImage
+		opts.env = []string{"PATH=/bin", "APP_MODE=staging"}
+		opts.labels = map[string]string{
+			"org.opencontainers.image.title":   "app",
+			"org.opencontainers.image.created": "2026-02-01T00:00:00Z",
+		}
+
+		result, err := runDrift(context.Background(), createTestImage(t, opts), spec)
+		require.NoError(t, err)
+		assert.True(t, result.Passed)
+		assert.Equal(t, "Image configuration matches the golden spec", result.Message)
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		opts := driftTestImage
+		opts.user = "root"
+		opts.exposedPorts = map[string]struct{}{"8080/tcp": {}, "22/tcp": {}}
+
+		result, err := runDrift(context.Background(), createTestImage(t, opts), spec)
+		require.NoError(t, err)
+		assert.False(t, result.Passed)
+		assert.Equal(t, "Image configuration drifted from the golden spec (2 differences)", result.Message)
+		details := result.Details.(output.DriftDetails)
+		assert.Equal(t, []output.DriftDifference{
+			{Field: "user", Kind: "changed", Expected: "1000", Actual: "root"},
+			{Field: "exposed-ports", Kind: "added", Key: "22/tcp"},
+		}, details.Differences)
+
+		out := captureStdout(t, func() { renderDriftText(stdout, result) })
+		assert.Contains(t, out, "Checking configuration drift of image")
+		assert.Contains(t, out, "user expected 1000, got root")
+		assert.Contains(t, out, "exposed-ports 22/tcp added")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := runDrift(context.Background(), createTestImage(t, driftTestImage), "/nonexistent/spec.yaml")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to load golden spec")
+	})
+}
+
+func TestRunAll_DriftOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	image := createTestImage(t, driftTestImage)
+	spec := recordGoldenSpec(t, image, "golden-spec.yaml")
+
+	for _, c := range determineChecks(nil, map[string]bool{}, nil, checkParams{}) {
+		assert.NotEqual(t, checkDrift, c.name, "drift must be opt-in without a golden spec")
+	}
+
+	var found bool
+	for _, c := range determineChecks(nil, map[string]bool{}, nil, checkParams{goldenSpec: spec}) {
+		if c.name == checkDrift {
+			found = true
+			result, err := c.run(context.Background(), image)
+			require.NoError(t, err)
+			assert.True(t, result.Passed)
+		}
+	}
+	assert.True(t, found)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&goldenSpec, "golden-spec", "", "")
+	cleanup, err := applyDriftConfig(cmd, &driftCheckConfig{GoldenSpec: map[string]any{"user": "1000"}})
+	defer cleanup()
+	require.NoError(t, err)
+	loaded, err := os.ReadFile(goldenSpec)
+	require.NoError(t, err)
+	assert.Contains(t, string(loaded), "1000", "inline specs are written to a temporary file")
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/entropy"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var entropyPolicy string
+
+var entropyCmd = &cobra.Command{
+	Use:   "entropy image",
+	Short: "Validate that the image has no large files of near-random content",
+	Long: `Validate that the image has no large files of near-random content.
+
+The Shannon entropy of every regular file of at least min-size megabytes
+(default 10) is measured across all layers, and the check fails when a file is
+above max-entropy bits per byte (default 7.5, out of 8). Such files are often
+encrypted archives, packed binaries, or model weights smuggled into an image.
+Files that start with the signature of a compressed format (gzip, zip, PNG,
+...) are high-entropy by design and are skipped unless the policy sets
+check-known-formats.
+
+` + imageArgFormatsDoc,
+	Example: `  check-image entropy nginx:latest
+  check-image entropy nginx:latest --entropy-policy entropy-policy.yaml
+  check-image entropy oci:/path/to/layout:1.0 --entropy-policy entropy-policy.json -o json
+  check-image entropy oci-archive:/path/to/image.tar:latest
+  cat entropy-policy.yaml | check-image entropy nginx:latest --entropy-policy -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		return runCheckCmd(checkEntropy, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runEntropy(ctx, img, entropyPolicy)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(entropyCmd)
+	entropyCmd.Flags().StringVar(&entropyPolicy, "entropy-policy", "", "Entropy policy file (JSON or YAML) (optional)")
+}
+
+func runEntropy(ctx context.Context, imageName string, policyPath string) (*output.CheckResult, error) {
+	policy, err := entropy.LoadPolicy(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load entropy policy: %w", err)
+	}
+
+	image, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	scan, err := entropy.ScanLayers(ctx, image, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	passed := len(scan.Findings) == 0
+	var msg string
+	if passed {
+		msg = "No high-entropy files detected"
+	} else {
+		msg = "High-entropy files detected"
+	}
+
+	return &output.CheckResult{
+		Check:   checkEntropy,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: output.EntropyDetails{
+			MinSize:       policy.MinSize,
+			MaxEntropy:    policy.MaxEntropy,
+			MeasuredFiles: scan.Measured,
+			Findings:      scan.Findings,
+		},
+	}, nil
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntropyCommand(t *testing.T) {
+	assert.Equal(t, "entropy image", entropyCmd.Use)
+	assert.Error(t, entropyCmd.Args(entropyCmd, []string{}))
+	assert.NoError(t, entropyCmd.Args(entropyCmd, []string{"image"}))
+
+	flag := entropyCmd.Flags().Lookup("entropy-policy")
+	require.NotNil(t, flag)
+	assert.Equal(t, "", flag.DefValue)
+}
+
+func TestRunEntropy(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	payload := make([]byte, 1024*1024)
+	for i := range payload {
+		payload[i] = byte(r.Uint32())
+	}
+	imageRef := createTestImage(t, testImageOptions{
+		layerFiles: []map[string]string{{"opt/payload.bin": string(payload), "etc/motd": "hello"}},
+	})
+
+	policyPath := filepath.Join(t.TempDir(), "entropy-policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("min-size: 1\n"), 0600))
+
+	result, err := runEntropy(context.Background(), imageRef, policyPath)
+	require.NoError(t, err)
+	assert.Equal(t, checkEntropy, result.Check)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "High-entropy files detected", result.Message)
+	details := result.Details.(output.EntropyDetails)
+	assert.Equal(t, uint(1), details.MinSize)
+	assert.Equal(t, 1, details.MeasuredFiles)
+	require.Len(t, details.Findings, 1)
+	assert.Equal(t, "opt/payload.bin", details.Findings[0].Path)
+
+	result, err = runEntropy(context.Background(), imageRef, "")
+	require.NoError(t, err)
+	assert.True(t, result.Passed, "the payload is below the default min-size")
+	assert.Equal(t, "No high-entropy files detected", result.Message)
+}
+
+func TestRunEntropy_InvalidPolicy(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "entropy-policy.json")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`{"max-entropy": 10}`), 0600))
+
+	_, err := runEntropy(context.Background(), "oci:/nonexistent:latest", policyPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load entropy policy")
+}
+
+func TestRenderEntropyText(t *testing.T) {
+	result := &output.CheckResult{
+		Check:   checkEntropy,
+		Image:   "nginx:latest",
+		Passed:  false,
+		Message: "High-entropy files detected",
+		Details: output.EntropyDetails{
+			MinSize:       10,
+			MaxEntropy:    7.5,
+			MeasuredFiles: 3,
+			Findings: []output.EntropyFinding{
+				{Path: "opt/payload.bin", LayerIndex: 1, Size: 20 * 1024 * 1024, Entropy: 7.999},
+				{Path: "opt/data.zip", LayerIndex: 1, Size: 12 * 1024 * 1024, Entropy: 7.98, Format: "zip"},
+			},
+		},
+	}
+
+	captured := captureStdout(t, func() { renderEntropyText(stdout, result) })
+	assert.Contains(t, captured, "Checking high-entropy files in image nginx:latest")
+	assert.Contains(t, captured, "measured: 3")
+	assert.Contains(t, captured, "Layer 2:")
+	assert.Contains(t, captured, "opt/payload.bin (20.00 MB, 7.999 bits per byte)")
+	assert.Contains(t, captured, "opt/data.zip (12.00 MB, 7.980 bits per byte, zip)")
+	assert.Contains(t, captured, "High-entropy files detected")
+}
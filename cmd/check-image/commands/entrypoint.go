@@ -2,10 +2,14 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"slices"
 
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	entrypointpolicy "github.com/jarfernandez/check-image/internal/entrypoint"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +18,7 @@ const shellFlagArg = "-c"
 var shellInterpreters = []string{"/bin/sh", "/bin/bash"}
 
 var allowShellForm bool
+var entrypointPolicy string
 
 var entrypointCmd = &cobra.Command{
 	Use:   "entrypoint image",
@@ -22,10 +27,16 @@ var entrypointCmd = &cobra.Command{
 
 By default the check fails if shell form is detected. Use --allow-shell-form to allow it.
 
+With --entrypoint-policy, the arguments of the startup command (ENTRYPOINT
+followed by CMD) are also checked against regex rules: forbidden arguments such
+as --insecure, interpreters run with inline scripts such as sh -c, and a
+pattern the executable must match, such as an absolute path.
+
 ` + imageArgFormatsDoc,
 	Example: `  check-image entrypoint nginx:latest
   check-image entrypoint nginx:latest -o json
   check-image entrypoint nginx:latest --allow-shell-form
+  check-image entrypoint nginx:latest --entrypoint-policy entrypoint-policy.yaml
   check-image entrypoint oci:/path/to/layout:1.0
   check-image entrypoint oci-archive:/path/to/image.tar:latest
   check-image entrypoint docker-archive:/path/to/image.tar:tag`,
@@ -33,7 +44,7 @@ By default the check fails if shell form is detected. Use --allow-shell-form to
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		return runCheckCmd(checkEntrypoint, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runEntrypoint(ctx, img, allowShellForm)
+			return runEntrypoint(ctx, img, allowShellForm, entrypointPolicy)
 		}, ctx, args[0], OutputFmt)
 	},
 }
@@ -42,10 +53,20 @@ func init() {
 	rootCmd.AddCommand(entrypointCmd)
 	entrypointCmd.Flags().BoolVar(&allowShellForm, "allow-shell-form", false,
 		"Allow shell form for entrypoint or cmd without failing (optional)")
+	entrypointCmd.Flags().StringVar(&entrypointPolicy, "entrypoint-policy", "",
+		"Entrypoint policy file (JSON or YAML) with rules for the startup command arguments (optional)")
 }
 
-func runEntrypoint(ctx context.Context, imageName string, shellFormAllowed bool) (*output.CheckResult, error) {
-	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+func runEntrypoint(ctx context.Context, imageName string, shellFormAllowed bool, policyPath string) (*output.CheckResult, error) {
+	var policy *entrypointpolicy.Policy
+	if policyPath != "" {
+		var err error
+		if policy, err = entrypointpolicy.LoadPolicy(policyPath); err != nil {
+			return nil, fmt.Errorf("unable to load entrypoint policy: %w", err)
+		}
+	}
+
+	img, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
 	if err != nil {
 		return nil, err
 	}
@@ -70,22 +91,43 @@ func runEntrypoint(ctx context.Context, imageName string, shellFormAllowed bool)
 	shellForm := isShellFormCommand(entrypoint) || isShellFormCommand(startCmd)
 	execForm := !shellForm
 
+	var shell string
+	var shellAvailable *bool
+	if shellForm {
+		shell, shellAvailable = shellAvailability(ctx, img, entrypoint, startCmd)
+	}
+	shellMissing := shellAvailable != nil && !*shellAvailable
+
+	var violations []output.EntrypointViolation
+	if policy != nil {
+		violations = entrypointViolations(entrypointpolicy.ValidateCommand(entrypointpolicy.Command(entrypoint, startCmd), policy))
+	}
+
 	var msg string
 	var passed bool
 	switch {
+	case !execForm && !shellFormAllowed && shellMissing:
+		passed, msg = false, fmt.Sprintf("Image uses shell form for entrypoint or cmd, and it would fail at runtime as %s does not exist in the image", shell)
+	case !execForm && !shellFormAllowed:
+		passed, msg = false, "Image uses shell form for entrypoint or cmd"
+	case shellMissing:
+		passed, msg = false, fmt.Sprintf("Image uses shell form but %s does not exist in the image", shell)
+	case len(violations) > 0:
+		passed, msg = false, "Image entrypoint or cmd violates the entrypoint policy"
 	case execForm:
 		passed, msg = true, "Image has a valid exec-form entrypoint" // #nosec G101 -- false positive: not a credential
-	case shellFormAllowed:
-		passed, msg = true, "Image uses shell form but it is allowed"
 	default:
-		passed, msg = false, "Image uses shell form for entrypoint or cmd"
+		passed, msg = true, "Image uses shell form but it is allowed"
 	}
 
 	details := output.EntrypointDetails{
-		HasEntrypoint: true,
-		ExecForm:      execForm,
-		Entrypoint:    entrypoint,
-		Cmd:           startCmd,
+		HasEntrypoint:  true,
+		ExecForm:       execForm,
+		Entrypoint:     entrypoint,
+		Cmd:            startCmd,
+		Shell:          shell,
+		ShellAvailable: shellAvailable,
+		Violations:     violations,
 	}
 	if !execForm && shellFormAllowed {
 		details.ShellFormAllowed = true
@@ -100,6 +142,43 @@ func runEntrypoint(ctx context.Context, imageName string, shellFormAllowed bool)
 	}, nil
 }
 
+// shellAvailability returns the shell that the shell-form commands run and
+// whether it exists in the layers of img. When entrypoint and cmd run
+// different shells, the first missing one is returned. Availability is nil
+// when the layers cannot be read, as the check then falls back to the form of
+// the commands only.
+func shellAvailability(ctx context.Context, img cr.Image, commands ...[]string) (string, *bool) {
+	var shells []string
+	for _, c := range commands {
+		if isShellFormCommand(c) && !slices.Contains(shells, c[0]) {
+			shells = append(shells, c[0])
+		}
+	}
+
+	exists, err := imageutil.FilesExist(ctx, img, shells)
+	if err != nil {
+		log.WithField("error", err).Warn("Unable to read image layers, shell availability was not checked")
+		return shells[0], nil
+	}
+	for _, s := range shells {
+		if !exists[s] {
+			return s, new(false)
+		}
+	}
+	return shells[0], new(true)
+}
+
+func entrypointViolations(violations []entrypointpolicy.Violation) []output.EntrypointViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+	out := make([]output.EntrypointViolation, len(violations))
+	for i, v := range violations {
+		out[i] = output.EntrypointViolation{Rule: v.Rule, Argument: v.Argument, Message: v.Message}
+	}
+	return out
+}
+
 // isShellFormCommand returns true if the command slice represents shell form,
 // i.e., the first element is /bin/sh or /bin/bash and the second is -c.
 // This is how Docker stores ENTRYPOINT/CMD when using shell form in a Dockerfile.
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,6 +89,7 @@ func TestRunEntrypoint(t *testing.T) {
 		name                 string
 		entrypoint           []string
 		cmd                  []string
+		files                map[string]string
 		allowShellFormFlag   bool
 		expectedPass         bool
 		expectedMsg          string
@@ -129,6 +131,7 @@ func TestRunEntrypoint(t *testing.T) {
 			name:               "shell form in entrypoint, no allow-shell-form",
 			entrypoint:         []string{"/bin/sh", "-c", "nginx -g 'daemon off;'"},
 			cmd:                nil,
+			files:              map[string]string{"bin/sh": "shell"},
 			allowShellFormFlag: false,
 			expectedPass:       false,
 			expectedMsg:        "Image uses shell form for entrypoint or cmd",
@@ -139,6 +142,7 @@ func TestRunEntrypoint(t *testing.T) {
 			name:                 "shell form in entrypoint, with allow-shell-form",
 			entrypoint:           []string{"/bin/sh", "-c", "nginx -g 'daemon off;'"},
 			cmd:                  nil,
+			files:                map[string]string{"bin/sh": "shell"},
 			allowShellFormFlag:   true,
 			expectedPass:         true,
 			expectedMsg:          "Image uses shell form but it is allowed",
@@ -150,6 +154,7 @@ func TestRunEntrypoint(t *testing.T) {
 			name:               "shell form in cmd, no allow-shell-form",
 			entrypoint:         nil,
 			cmd:                []string{"/bin/sh", "-c", "nginx -g 'daemon off;'"},
+			files:              map[string]string{"bin/sh": "shell"},
 			allowShellFormFlag: false,
 			expectedPass:       false,
 			expectedMsg:        "Image uses shell form for entrypoint or cmd",
@@ -160,6 +165,7 @@ func TestRunEntrypoint(t *testing.T) {
 			name:                 "shell form with /bin/bash, with allow-shell-form",
 			entrypoint:           []string{"/bin/bash", "-c", "start.sh"},
 			cmd:                  nil,
+			files:                map[string]string{"bin/bash": "shell"},
 			allowShellFormFlag:   true,
 			expectedPass:         true,
 			expectedMsg:          "Image uses shell form but it is allowed",
@@ -167,6 +173,26 @@ func TestRunEntrypoint(t *testing.T) {
 			expectedExecForm:     false,
 			expectedShellAllowed: true,
 		},
+		{
+			name:               "shell form without shell, no allow-shell-form",
+			cmd:                []string{"/bin/sh", "-c", "server"},
+			allowShellFormFlag: false,
+			expectedPass:       false,
+			expectedMsg:        "Image uses shell form for entrypoint or cmd, and it would fail at runtime as /bin/sh does not exist in the image",
+			expectedHas:        true,
+			expectedExecForm:   false,
+		},
+		{
+			name:                 "shell form without shell, with allow-shell-form",
+			entrypoint:           []string{"/bin/bash", "-c", "start.sh"},
+			files:                map[string]string{"bin/sh": "shell"},
+			allowShellFormFlag:   true,
+			expectedPass:         false,
+			expectedMsg:          "Image uses shell form but /bin/bash does not exist in the image",
+			expectedHas:          true,
+			expectedExecForm:     false,
+			expectedShellAllowed: true,
+		},
 		{
 			name:               "no entrypoint and no cmd",
 			entrypoint:         nil,
@@ -196,9 +222,10 @@ func TestRunEntrypoint(t *testing.T) {
 				created:    time.Now(),
 				entrypoint: tt.entrypoint,
 				cmd:        tt.cmd,
+				layerFiles: []map[string]string{tt.files},
 			})
 
-			result, err := runEntrypoint(context.Background(), imageRef, tt.allowShellFormFlag)
+			result, err := runEntrypoint(context.Background(), imageRef, tt.allowShellFormFlag, "")
 			require.NoError(t, err)
 
 			assert.Equal(t, "entrypoint", result.Check)
@@ -211,6 +238,13 @@ func TestRunEntrypoint(t *testing.T) {
 			assert.Equal(t, tt.expectedHas, details.HasEntrypoint)
 			assert.Equal(t, tt.expectedExecForm, details.ExecForm)
 			assert.Equal(t, tt.expectedShellAllowed, details.ShellFormAllowed)
+			if tt.expectedExecForm {
+				assert.Empty(t, details.Shell)
+				assert.Nil(t, details.ShellAvailable)
+			} else if tt.expectedHas {
+				require.NotNil(t, details.ShellAvailable)
+				assert.Equal(t, !strings.Contains(tt.expectedMsg, "does not exist"), *details.ShellAvailable)
+			}
 
 			if tt.expectedHas {
 				// Entrypoint and Cmd fields should reflect what was set
@@ -222,6 +256,95 @@ func TestRunEntrypoint(t *testing.T) {
 }
 
 func TestRunEntrypoint_InvalidImage(t *testing.T) {
-	_, err := runEntrypoint(context.Background(), "nonexistent:image", false)
+	_, err := runEntrypoint(context.Background(), "nonexistent:image", false, "")
+	require.Error(t, err)
+}
+
+func TestRunEntrypoint_Policy(t *testing.T) {
+	policy := writeRequiredConfig(t, `forbidden-args:
+  - name: insecure-flags
+    pattern: '^--(insecure|disable-auth)(=.*)?$'
+inline-scripts:
+  - name: shell-one-liner
+    interpreter: '(^|/)(sh|bash)$'
+    flag: '^-c$'
+required-executable:
+  name: absolute-path
+  pattern: '^/'
+`)
+
+	tests := []struct {
+		name           string
+		entrypoint     []string
+		cmd            []string
+		allowShellForm bool
+		expectedPass   bool
+		expectedMsg    string
+		expectedRules  []string
+	}{
+		{
+			name:         "compliant command",
+			entrypoint:   []string{"/app/server"},
+			cmd:          []string{"--port", "8080"},
+			expectedPass: true,
+			expectedMsg:  "Image has a valid exec-form entrypoint",
+		},
+		{
+			name:          "forbidden flag in cmd",
+			entrypoint:    []string{"/app/server"},
+			cmd:           []string{"--insecure"},
+			expectedMsg:   "Image entrypoint or cmd violates the entrypoint policy",
+			expectedRules: []string{"insecure-flags"},
+		},
+		{
+			name:          "relative executable",
+			cmd:           []string{"server", "--disable-auth=true"},
+			expectedMsg:   "Image entrypoint or cmd violates the entrypoint policy",
+			expectedRules: []string{"insecure-flags", "absolute-path"},
+		},
+		{
+			name:           "allowed shell form still matches inline script rule",
+			cmd:            []string{"/bin/sh", "-c", "server"},
+			allowShellForm: true,
+			expectedMsg:    "Image entrypoint or cmd violates the entrypoint policy",
+			expectedRules:  []string{"shell-one-liner"},
+		},
+		{
+			name:          "shell form message takes precedence",
+			cmd:           []string{"/bin/sh", "-c", "server"},
+			expectedMsg:   "Image uses shell form for entrypoint or cmd",
+			expectedRules: []string{"shell-one-liner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageRef := createTestImage(t, testImageOptions{
+				entrypoint: tt.entrypoint,
+				cmd:        tt.cmd,
+				layerFiles: []map[string]string{{"bin/sh": "shell"}},
+			})
+
+			result, err := runEntrypoint(context.Background(), imageRef, tt.allowShellForm, policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPass, result.Passed)
+			assert.Equal(t, tt.expectedMsg, result.Message)
+
+			details := result.Details.(output.EntrypointDetails)
+			var rules []string
+			for _, v := range details.Violations {
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestRunEntrypoint_InvalidPolicy(t *testing.T) {
+	policy := writeRequiredConfig(t, "forbidden-args:\n  - name: bad\n    pattern: '('\n")
+	imageRef := createTestImage(t, testImageOptions{cmd: []string{"/app"}})
+
+	_, err := runEntrypoint(context.Background(), imageRef, false, policy)
 	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load entrypoint policy")
 }
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/exceptions"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var exceptionsExpiring string
+
+var exceptionsCmd = &cobra.Command{
+	Use:   "exceptions",
+	Short: "Manage check exceptions files",
+	Long:  `Manage exceptions files that grant image digests time-boxed exemptions from checks.`,
+}
+
+var exceptionsListCmd = &cobra.Command{
+	Use:   "list file",
+	Short: "List the exceptions of a file and their expiry",
+	Long: `List the exceptions of an exceptions file (JSON or YAML), ordered by expiry.
+
+With --expiring, only exceptions that expire within the window (e.g. 30d or
+72h) are listed, together with those that have already expired. Use "-" to
+read the file from stdin.
+
+Exit codes: 0 when no listed exception has expired, 1 when at least one has,
+2 on errors.`,
+	Example: `  check-image exceptions list exceptions.yaml
+  check-image exceptions list exceptions.yaml --expiring 30d
+  check-image exceptions list exceptions.yaml --expiring 14d -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runExceptionsList(args[0], time.Now()); err != nil {
+			return fmt.Errorf("exceptions list operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exceptionsCmd)
+	exceptionsCmd.AddCommand(exceptionsListCmd)
+	exceptionsListCmd.Flags().StringVar(&exceptionsExpiring, "expiring", "", "Only list exceptions expiring within this window, e.g. 30d or 72h (optional)")
+}
+
+func runExceptionsList(path string, now time.Time) error {
+	f, err := exceptions.Load(path, validCheckNames)
+	if err != nil {
+		return err
+	}
+
+	listed := f.ByExpiry()
+	if exceptionsExpiring != "" {
+		within, err := exceptions.ParseWindow(exceptionsExpiring)
+		if err != nil {
+			return err
+		}
+		listed = f.Expiring(now, within)
+	}
+
+	result := output.ExceptionsListResult{
+		File:       path,
+		Expiring:   exceptionsExpiring,
+		Exceptions: []output.CheckException{},
+	}
+	for _, e := range listed {
+		entry := checkException(e)
+		entry.Checks = e.Checks
+		entry.Expired = e.Expired(now)
+		if entry.Expired {
+			result.Expired++
+		}
+		result.Exceptions = append(result.Exceptions, *entry)
+	}
+
+	if result.Expired > 0 {
+		UpdateResult(ValidationFailed)
+	} else {
+		UpdateResult(ValidationSucceeded)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	printExceptionsList(result)
+	return nil
+}
+
+func printExceptionsList(r output.ExceptionsListResult) {
+	if len(r.Exceptions) == 0 {
+		if r.Expiring != "" {
+			fmt.Printf("No exceptions expire within %s\n", r.Expiring)
+		} else {
+			fmt.Println("No exceptions")
+		}
+		return
+	}
+	for _, e := range r.Exceptions {
+		state := "expires"
+		if e.Expired {
+			state = "EXPIRED"
+		}
+		fmt.Printf("%s%s %v: %s %s, approved by %s\n", statusPrefix(!e.Expired), e.Digest, e.Checks, state, e.Expires, e.Approver)
+		if e.Ticket != "" {
+			fmt.Printf("  Ticket: %s\n", e.Ticket)
+		}
+		if e.Reason != "" {
+			fmt.Printf("  Reason: %s\n", e.Reason)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exceptionsListFixture = `exceptions:
+  - digest: sha256:1111111111111111111111111111111111111111111111111111111111111111
+    checks: [user]
+    approver: jane
+    ticket: https://issues.example.com/SEC-1
+    expires: 2026-01-20
+  - digest: sha256:2222222222222222222222222222222222222222222222222222222222222222
+    checks: [secrets, labels]
+    approver: ops
+    reason: Vendor image
+    expires: 2026-02-10
+  - digest: sha256:3333333333333333333333333333333333333333333333333333333333333333
+    checks: [age]
+    approver: ops
+    expires: 2026-06-30
+`
+
+func TestRunExceptionsList(t *testing.T) {
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		expiring    string
+		wantExpires []string
+		wantExpired int
+		wantResult  ValidationResult
+	}{
+		{name: "All exceptions", wantExpires: []string{"2026-01-20", "2026-02-10", "2026-06-30"}, wantExpired: 1, wantResult: ValidationFailed},
+		{name: "Expiring within 30 days", expiring: "30d", wantExpires: []string{"2026-01-20", "2026-02-10"}, wantExpired: 1, wantResult: ValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			OutputFmt = output.FormatJSON
+			exceptionsExpiring = tt.expiring
+			path := writeRequiredConfig(t, exceptionsListFixture)
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runExceptionsList(path, now))
+			})
+
+			var result output.ExceptionsListResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			var expires []string
+			for _, e := range result.Exceptions {
+				expires = append(expires, e.Expires)
+			}
+			assert.Equal(t, tt.wantExpires, expires)
+			assert.Equal(t, tt.wantExpired, result.Expired)
+			assert.True(t, result.Exceptions[0].Expired)
+			assert.Equal(t, []string{"user"}, result.Exceptions[0].Checks)
+			assert.Equal(t, tt.wantResult, Result)
+		})
+	}
+}
+
+func TestRunExceptionsList_TextOutput(t *testing.T) {
+	resetAllGlobals(t)
+	exceptionsExpiring = "30d"
+	path := writeRequiredConfig(t, exceptionsListFixture)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runExceptionsList(path, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	assert.Contains(t, out, "[user]: expires 2026-01-20, approved by jane")
+	assert.Contains(t, out, "Ticket: https://issues.example.com/SEC-1")
+	assert.NotContains(t, out, "2026-02-10")
+	assert.Equal(t, ValidationSucceeded, Result)
+
+	exceptionsExpiring = "0d"
+	out = captureStdout(t, func() {
+		require.NoError(t, runExceptionsList(path, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+	assert.Contains(t, out, "No exceptions expire within 0d")
+}
+
+func TestRunExceptionsList_InvalidWindow(t *testing.T) {
+	resetAllGlobals(t)
+	exceptionsExpiring = "soon"
+	err := runExceptionsList(writeRequiredConfig(t, exceptionsListFixture), time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid window")
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	log "github.com/sirupsen/logrus"
+)
+
+// globalConfigEnv names the global config file, instead of the discovered
+// one. An empty value disables the global config.
+const globalConfigEnv = "CHECK_IMAGE_GLOBAL_CONFIG"
+
+// globalConfigNames are the file names the global config is discovered as,
+// in order, in each of globalConfigDirs.
+var globalConfigNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// globalConfigDirs returns the directories the global config is discovered
+// in, in order; tests replace it.
+var globalConfigDirs = defaultGlobalConfigDirs
+
+// optInChecks are the checks that do not run by default without --config.
+var optInChecks = map[string]bool{
+	checkProvenance:   true,
+	checkLazyPull:     true,
+	checkDrift:        true,
+	checkEntropy:      true,
+	checkDeprecation:  true,
+	checkArchitecture: true,
+	checkMinimal:      true,
+	checkSmoke:        true,
+}
+
+// globalConfig is the machine- or user-wide configuration of check-image,
+// read on every run, e.g. from a base image or devcontainer. Unlike --config,
+// it does not select checks or set their parameters; it changes the defaults
+// that apply when no --config is given.
+type globalConfig struct {
+	Defaults *globalDefaults `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// InsecureRegistries are reached over plain HTTP or TLS without
+	// certificate verification, in addition to --insecure-registry.
+	InsecureRegistries []string `json:"insecure-registries,omitempty" yaml:"insecure-registries,omitempty"`
+	// RegistryMirrors maps upstream registries to their mirrors, in the order
+	// they are tried. --registry-mirror replaces the mirrors of an upstream.
+	RegistryMirrors map[string][]string `json:"registry-mirrors,omitempty" yaml:"registry-mirrors,omitempty"`
+}
+
+// globalDefaults is the defaults section of the global config.
+type globalDefaults struct {
+	// Checks replaces the checks run by default by the all command without
+	// --config. Opt-in checks listed here run without their policy flag.
+	Checks []string `json:"checks,omitempty" yaml:"checks,omitempty"`
+}
+
+// activeGlobalConfig is the global config loaded by the root command, nil
+// when there is none.
+var activeGlobalConfig *globalConfig
+
+// defaultChecks returns the checks the all command runs by default, or nil
+// for the built-in default set.
+func (g *globalConfig) defaultChecks() map[string]bool {
+	if g == nil || g.Defaults == nil || g.Defaults.Checks == nil {
+		return nil
+	}
+	checks := make(map[string]bool, len(g.Defaults.Checks))
+	for _, name := range g.Defaults.Checks {
+		checks[name] = true
+	}
+	return checks
+}
+
+// insecureRegistries returns the insecure registries of the global config.
+func (g *globalConfig) insecureRegistries() []string {
+	if g == nil {
+		return nil
+	}
+	return g.InsecureRegistries
+}
+
+// registryMirrors returns the registry mirrors of the global config.
+func (g *globalConfig) registryMirrors() map[string][]string {
+	if g == nil {
+		return nil
+	}
+	return g.RegistryMirrors
+}
+
+func defaultGlobalConfigDirs() []string {
+	var dirs []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "check-image"))
+	}
+	if runtime.GOOS != "windows" {
+		dirs = append(dirs, "/etc/check-image")
+	}
+	return dirs
+}
+
+// findGlobalConfig returns the path of the global config: the file named by
+// CHECK_IMAGE_GLOBAL_CONFIG when set, else the first config file of the user
+// config directory (e.g. ~/.config/check-image) and /etc/check-image. It
+// returns "" when there is none.
+func findGlobalConfig() string {
+	if path, ok := os.LookupEnv(globalConfigEnv); ok {
+		return path
+	}
+	for _, dir := range globalConfigDirs() {
+		for _, name := range globalConfigNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// loadGlobalConfig reads and validates the global config, returning nil when
+// there is none.
+func loadGlobalConfig() (*globalConfig, error) {
+	path := findGlobalConfig()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := fileutil.ReadSecureFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("global config %s (%s) does not exist", path, globalConfigEnv)
+		}
+		return nil, fmt.Errorf("error reading global config %s: %w", path, err)
+	}
+	var cfg globalConfig
+	if err := fileutil.UnmarshalConfigData(data, &cfg, path); err != nil {
+		return nil, fmt.Errorf("error parsing global config %s: %w", path, err)
+	}
+	if err := validateGlobalConfig(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid global config %s: %w", path, err)
+	}
+	log.WithField("path", path).Debug("Loaded global config")
+	return &cfg, nil
+}
+
+func validateGlobalConfig(cfg *globalConfig) error {
+	for _, r := range cfg.InsecureRegistries {
+		if _, err := imageutil.NormalizeRegistry(r); err != nil {
+			return fmt.Errorf("invalid insecure-registries: %w", err)
+		}
+	}
+	if err := imageutil.ValidateRegistryMirrors(cfg.RegistryMirrors); err != nil {
+		return fmt.Errorf("invalid registry-mirrors: %w", err)
+	}
+	if cfg.Defaults == nil {
+		return nil
+	}
+	for _, name := range cfg.Defaults.Checks {
+		if !slices.Contains(validCheckNames, name) {
+			return fmt.Errorf("unknown check name %q in defaults.checks, valid names are: %s", name, strings.Join(validCheckNames, ", "))
+		}
+	}
+	return nil
+}
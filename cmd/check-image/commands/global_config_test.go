@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useGlobalConfigDirs makes the global config discovered in dirs only.
+func useGlobalConfigDirs(t *testing.T, dirs ...string) {
+	t.Helper()
+	saved := globalConfigDirs
+	globalConfigDirs = func() []string { return dirs }
+	t.Cleanup(func() { globalConfigDirs = saved })
+}
+
+func TestFindGlobalConfig(t *testing.T) {
+	userDir, systemDir := t.TempDir(), t.TempDir()
+	useGlobalConfigDirs(t, userDir, systemDir)
+
+	t.Run("none", func(t *testing.T) {
+		assert.Empty(t, findGlobalConfig())
+	})
+
+	systemConfig := filepath.Join(systemDir, "config.yaml")
+	require.NoError(t, os.WriteFile(systemConfig, []byte("defaults: {}\n"), 0600))
+	t.Run("system", func(t *testing.T) {
+		assert.Equal(t, systemConfig, findGlobalConfig())
+	})
+
+	userConfig := filepath.Join(userDir, "config.json")
+	require.NoError(t, os.WriteFile(userConfig, []byte("{}"), 0600))
+	t.Run("user before system", func(t *testing.T) {
+		assert.Equal(t, userConfig, findGlobalConfig())
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(globalConfigEnv, "/path/to/global.yaml")
+		assert.Equal(t, "/path/to/global.yaml", findGlobalConfig())
+	})
+
+	t.Run("disabled by environment", func(t *testing.T) {
+		t.Setenv(globalConfigEnv, "")
+		assert.Empty(t, findGlobalConfig())
+	})
+}
+
+func TestLoadGlobalConfig(t *testing.T) {
+	useGlobalConfigDirs(t)
+
+	tests := []struct {
+		name       string
+		content    string
+		missing    bool
+		wantChecks map[string]bool
+		wantError  string
+	}{
+		{
+			name:       "defaults",
+			content:    "defaults:\n  checks: [age, user, deprecation]\n",
+			wantChecks: map[string]bool{"age": true, "user": true, "deprecation": true},
+		},
+		{
+			name:       "empty defaults",
+			content:    "defaults:\n  checks: []\n",
+			wantChecks: map[string]bool{},
+		},
+		{
+			name:    "no defaults",
+			content: "{}",
+		},
+		{
+			name:      "unknown check",
+			content:   "defaults:\n  checks: [age, vulnerabilities]\n",
+			wantError: `unknown check name "vulnerabilities" in defaults.checks`,
+		},
+		{
+			name:    "insecure registries",
+			content: "insecure-registries: [dev-registry:5000, localhost:5001]\n",
+		},
+		{
+			name:      "invalid insecure registry",
+			content:   "insecure-registries: [dev-registry:5000/org]\n",
+			wantError: "invalid insecure-registries",
+		},
+		{
+			name:    "registry mirrors",
+			content: "registry-mirrors:\n  docker.io: [mirror.gcr.io, registry.example.com/dockerhub]\n",
+		},
+		{
+			name:      "invalid registry mirror",
+			content:   "registry-mirrors:\n  docker.io: [https://mirror.gcr.io]\n",
+			wantError: "invalid registry-mirrors",
+		},
+		{
+			name:      "invalid yaml",
+			content:   "defaults: [\n",
+			wantError: "error parsing global config",
+		},
+		{
+			name:      "missing file",
+			missing:   true,
+			wantError: "does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if !tt.missing {
+				require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+			}
+			t.Setenv(globalConfigEnv, path)
+
+			cfg, err := loadGlobalConfig()
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, cfg)
+			assert.Equal(t, tt.wantChecks, cfg.defaultChecks())
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		cfg, err := loadGlobalConfig()
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestBuildCheckDefs_GlobalDefaults(t *testing.T) {
+	enabled := func(defs []checkDef) []string {
+		var names []string
+		for _, def := range defs {
+			if def.enabled {
+				names = append(names, def.name)
+			}
+		}
+		return names
+	}
+	defaults := map[string]bool{"age": true, "user": true, "deprecation": true}
+
+	assert.Equal(t, []string{"age", "user", "deprecation"}, enabled(buildCheckDefs(nil, checkParams{defaultChecks: defaults})))
+	assert.Equal(t, []string{"age", "user", "entropy", "deprecation"}, enabled(buildCheckDefs(nil, checkParams{defaultChecks: defaults, entropyPolicy: "entropy.yaml"})))
+
+	cfg := &allConfig{Checks: allChecksConfig{Size: &sizeCheckConfig{}}}
+	assert.Equal(t, []string{"size"}, enabled(buildCheckDefs(cfg, checkParams{defaultChecks: defaults})))
+}
+
+func TestRunAll_GlobalDefaults(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	activeGlobalConfig = &globalConfig{Defaults: &globalDefaults{Checks: []string{"age", "user"}}}
+	skipChecks = "age"
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal([]byte(captured), &result))
+	require.Len(t, result.Checks, 1)
+	assert.Equal(t, "user", result.Checks[0].Check)
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{Name: "age", Reason: output.SkipReasonSkipFlag})
+	assert.Contains(t, result.Summary.Skipped, output.SkippedCheck{Name: "size", Reason: output.SkipReasonNotInDefaults})
+}
@@ -76,35 +76,34 @@ func createTestOCILayout(t *testing.T, tag string, opts testImageOptions) string
 	img, err = mutate.ConfigFile(img, cfg)
 	require.NoError(t, err)
 
-	// Add layers if requested
-	if opts.layerCount > 0 || len(opts.layerFiles) > 0 {
-		var layers []v1.Layer
-
-		// Determine actual number of layers to create
-		numLayers := max(len(opts.layerFiles), opts.layerCount)
-
-		for i := range numLayers {
-			var layer v1.Layer
-
-			// If files are specified for this layer, create layer with files
-			if opts.layerFiles != nil && i < len(opts.layerFiles) && len(opts.layerFiles[i]) > 0 {
-				layer = createLayerWithFiles(t, opts.layerFiles[i])
+	// Add layers; images without layers are not runnable, so there is always
+	// at least one
+	var layers []v1.Layer
+
+	// Determine actual number of layers to create
+	numLayers := max(len(opts.layerFiles), opts.layerCount, 1)
+
+	for i := range numLayers {
+		var layer v1.Layer
+
+		// If files are specified for this layer, create layer with files
+		if opts.layerFiles != nil && i < len(opts.layerFiles) && len(opts.layerFiles[i]) > 0 {
+			layer = createLayerWithFiles(t, opts.layerFiles[i])
+		} else {
+			// Otherwise create layer with specific size
+			var size int64
+			if opts.layerSizes != nil && i < len(opts.layerSizes) {
+				size = opts.layerSizes[i]
 			} else {
-				// Otherwise create layer with specific size
-				var size int64
-				if opts.layerSizes != nil && i < len(opts.layerSizes) {
-					size = opts.layerSizes[i]
-				} else {
-					// Default: 1KB per layer
-					size = 1024
-				}
-				layer = createTestLayer(t, size)
+				// Default: 1KB per layer
+				size = 1024
 			}
-			layers = append(layers, layer)
+			layer = createTestLayer(t, size)
 		}
-		img, err = mutate.AppendLayers(img, layers...)
-		require.NoError(t, err)
+		layers = append(layers, layer)
 	}
+	img, err = mutate.AppendLayers(img, layers...)
+	require.NoError(t, err)
 
 	// Create layout
 	p, err := layout.Write(layoutPath, empty.Index)
@@ -177,6 +176,9 @@ func createTestOCIImage(t *testing.T, layoutPath string, labels map[string]strin
 	img, err = mutate.ConfigFile(img, cfg)
 	require.NoError(t, err)
 
+	img, err = mutate.AppendLayers(img, createTestLayer(t, 1024))
+	require.NoError(t, err)
+
 	// Create layout
 	p, err := layout.Write(layoutPath, empty.Index)
 	require.NoError(t, err)
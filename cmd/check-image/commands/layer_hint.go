@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+)
+
+// layerInspectHint returns a command that lists the files of the layer with
+// the given digest of an image, or "" when none applies: crane for registry
+// images, tar on the blob for OCI layouts. Layers are assumed to be gzip
+// compressed, as most are.
+func layerInspectHint(imageName, digest string) string {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || hex == "" {
+		return ""
+	}
+	ref, err := imageutil.ParseReference(imageName)
+	if err != nil {
+		return ""
+	}
+	switch ref.Transport {
+	case imageutil.TransportDaemonRegistry:
+		repo, err := imageutil.GetImageRepository(imageName)
+		if err != nil {
+			return ""
+		}
+		return "crane blob " + repo + "@" + digest + " | tar -tzf -"
+	case imageutil.TransportOCI:
+		return "tar -tzf " + filepath.Join(ref.Path, "blobs", algorithm, hex)
+	}
+	return ""
+}
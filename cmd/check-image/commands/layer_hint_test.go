@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayerInspectHint(t *testing.T) {
+	hex := strings.Repeat("a", 64)
+	digest := "sha256:" + hex
+
+	tests := []struct {
+		name   string
+		image  string
+		digest string
+		want   string
+	}{
+		{"registry image", "ghcr.io/org/app:1.0", digest, "crane blob ghcr.io/org/app@" + digest + " | tar -tzf -"},
+		{"Docker Hub image", "nginx", digest, "crane blob index.docker.io/library/nginx@" + digest + " | tar -tzf -"},
+		{"OCI layout", "oci:/tmp/layout:latest", digest, "tar -tzf " + filepath.Join("/tmp/layout", "blobs", "sha256", hex)},
+		{"archive", "oci-archive:/tmp/image.tar:latest", digest, ""},
+		{"no digest", "ghcr.io/org/app:1.0", "", ""},
+		{"malformed digest", "ghcr.io/org/app:1.0", "sha256", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, layerInspectHint(tt.image, tt.digest))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Lazy-pull formats accepted by --lazy-pull-formats.
+const (
+	lazyPullEStargz = "estargz"
+	lazyPullNydus   = "nydus"
+)
+
+var validLazyPullFormats = []string{lazyPullEStargz, lazyPullNydus}
+
+// Layer annotations and media types written by the eStargz and Nydus
+// converters (ctr-remote, nerdctl image convert, nydusify).
+const (
+	estargzTOCDigestAnnotation = "containerd.io/snapshot/stargz/toc.digest"
+	nydusBlobAnnotation        = "containerd.io/snapshot/nydus-blob"
+	nydusBootstrapAnnotation   = "containerd.io/snapshot/nydus-bootstrap"
+	nydusBlobMediaType         = "application/vnd.oci.image.layer.nydus.blob.v1"
+)
+
+var lazyPullFormats string
+
+var lazyPullCmd = &cobra.Command{
+	Use:   "lazy-pull image",
+	Short: "Validate that the image can be lazy-pulled (eStargz or Nydus)",
+	Long: `Validate that the image can be lazy-pulled by a stargz or Nydus snapshotter.
+
+An image is eStargz when every layer carries a TOC digest annotation, and Nydus
+when it has a Nydus bootstrap layer and every other layer is a Nydus blob. The
+check passes when the image is in one of the --lazy-pull-formats (default: any).
+Layer annotations are only kept by registries and OCI layouts; docker-archive
+and daemon images never pass. For multi-platform images, the manifest of the
+resolved platform is inspected.
+
+` + imageArgFormatsDoc,
+	Example: `  check-image lazy-pull ghcr.io/org/app:1.0-esgz
+  check-image lazy-pull ghcr.io/org/app:1.0-nydus --lazy-pull-formats nydus
+  check-image lazy-pull oci:/path/to/layout:1.0 --lazy-pull-formats estargz,nydus -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formats, err := parseLazyPullFormatsFrom(lazyPullFormats)
+		if err != nil {
+			return fmt.Errorf("invalid check lazy-pull arguments: %w", err)
+		}
+
+		ctx := cmd.Context()
+		return runCheckCmd(checkLazyPull, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runLazyPull(ctx, img, formats)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lazyPullCmd)
+	lazyPullCmd.Flags().StringVar(&lazyPullFormats, "lazy-pull-formats", "", "Comma-separated list of accepted lazy-pull formats (estargz, nydus) or @<file>, default any (optional)")
+}
+
+// parseLazyPullFormatsFrom parses a --lazy-pull-formats value. An empty value
+// accepts every format.
+func parseLazyPullFormatsFrom(s string) ([]string, error) {
+	if s == "" {
+		return slices.Clone(validLazyPullFormats), nil
+	}
+	formats, err := parseListInput(s, "lazy-pull-formats")
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range formats {
+		formats[i] = strings.ToLower(f)
+		if !slices.Contains(validLazyPullFormats, formats[i]) {
+			return nil, fmt.Errorf("unknown lazy-pull format %q: valid formats are %s", f, strings.Join(validLazyPullFormats, ", "))
+		}
+	}
+	return formats, nil
+}
+
+// detectLazyPull classifies the layers of a manifest and returns the lazy-pull
+// format of the image, or "" when it has none.
+func detectLazyPull(layers []cr.Descriptor) (string, output.LazyPullDetails) {
+	d := output.LazyPullDetails{Layers: len(layers)}
+	for _, l := range layers {
+		switch {
+		case l.Annotations[nydusBootstrapAnnotation] == "true":
+			d.NydusBootstrap = true
+		case string(l.MediaType) == nydusBlobMediaType || l.Annotations[nydusBlobAnnotation] == "true":
+			d.NydusBlobLayers++
+		case l.Annotations[estargzTOCDigestAnnotation] != "":
+			d.EStargzLayers++
+		}
+	}
+
+	switch {
+	case d.NydusBootstrap && d.NydusBlobLayers == d.Layers-1:
+		return lazyPullNydus, d
+	case d.Layers > 0 && d.EStargzLayers == d.Layers:
+		return lazyPullEStargz, d
+	default:
+		return "", d
+	}
+}
+
+func runLazyPull(ctx context.Context, imageName string, formats []string) (*output.CheckResult, error) {
+	img, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the image manifest: %w", err)
+	}
+
+	format, details := detectLazyPull(manifest.Layers)
+	details.Format = format
+	details.AcceptedFormats = formats
+	log.Debugf("Lazy-pull format: %q (estargz layers: %d, nydus blobs: %d, nydus bootstrap: %t, layers: %d)",
+		format, details.EStargzLayers, details.NydusBlobLayers, details.NydusBootstrap, details.Layers)
+
+	var msg string
+	passed := format != "" && slices.Contains(formats, format)
+	switch {
+	case passed:
+		msg = fmt.Sprintf("Image can be lazy-pulled as %s", format)
+	case format != "":
+		msg = fmt.Sprintf("Image is %s, which is not an accepted lazy-pull format", format)
+	case details.EStargzLayers > 0:
+		msg = fmt.Sprintf("Image is not fully eStargz: %d of %d layers have no TOC", details.Layers-details.EStargzLayers, details.Layers)
+	default:
+		msg = "Image has no lazy-pull artifacts"
+	}
+
+	return &output.CheckResult{
+		Check:   checkLazyPull,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: details,
+	}, nil
+}
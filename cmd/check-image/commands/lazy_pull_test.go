@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func estargzLayer(toc string) cr.Descriptor {
+	d := cr.Descriptor{MediaType: types.OCILayer}
+	if toc != "" {
+		d.Annotations = map[string]string{estargzTOCDigestAnnotation: toc}
+	}
+	return d
+}
+
+// createLazyPullImage writes an OCI layout holding an image whose layers carry
+// the given annotations and media types.
+func createLazyPullImage(t *testing.T, layers []mutate.Addendum) string {
+	t.Helper()
+	img, err := mutate.Append(empty.Image, layers...)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(img, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "latest",
+	})))
+	return "oci:" + dir + ":latest"
+}
+
+func TestLazyPullCommand(t *testing.T) {
+	assert.Equal(t, "lazy-pull image", lazyPullCmd.Use)
+	assert.Error(t, lazyPullCmd.Args(lazyPullCmd, []string{}))
+	assert.NoError(t, lazyPullCmd.Args(lazyPullCmd, []string{"image"}))
+
+	flag := lazyPullCmd.Flags().Lookup("lazy-pull-formats")
+	require.NotNil(t, flag)
+	assert.Equal(t, "", flag.DefValue)
+}
+
+func TestParseLazyPullFormatsFrom(t *testing.T) {
+	formats, err := parseLazyPullFormatsFrom("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"estargz", "nydus"}, formats)
+
+	formats, err = parseLazyPullFormatsFrom("Nydus")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nydus"}, formats)
+
+	_, err = parseLazyPullFormatsFrom("estargz,soci")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown lazy-pull format "soci"`)
+}
+
+func TestDetectLazyPull(t *testing.T) {
+	bootstrap := cr.Descriptor{MediaType: types.OCILayer, Annotations: map[string]string{nydusBootstrapAnnotation: "true"}}
+	blob := cr.Descriptor{MediaType: nydusBlobMediaType}
+
+	tests := []struct {
+		name   string
+		layers []cr.Descriptor
+		want   string
+	}{
+		{name: "No layers", want: ""},
+		{name: "Plain layers", layers: []cr.Descriptor{estargzLayer(""), estargzLayer("")}, want: ""},
+		{name: "All eStargz", layers: []cr.Descriptor{estargzLayer("sha256:a"), estargzLayer("sha256:b")}, want: lazyPullEStargz},
+		{name: "Partial eStargz", layers: []cr.Descriptor{estargzLayer("sha256:a"), estargzLayer("")}, want: ""},
+		{name: "Nydus", layers: []cr.Descriptor{blob, blob, bootstrap}, want: lazyPullNydus},
+		{name: "Nydus without bootstrap", layers: []cr.Descriptor{blob, blob}, want: ""},
+		{name: "Nydus with plain layer", layers: []cr.Descriptor{blob, estargzLayer(""), bootstrap}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, d := detectLazyPull(tt.layers)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.layers), d.Layers)
+		})
+	}
+}
+
+func TestRunLazyPull(t *testing.T) {
+	layer := func(annotations map[string]string) mutate.Addendum {
+		return mutate.Addendum{
+			Layer:       static.NewLayer([]byte(annotations[estargzTOCDigestAnnotation]+"layer"), types.OCILayer),
+			Annotations: annotations,
+		}
+	}
+	estargz := createLazyPullImage(t, []mutate.Addendum{
+		layer(map[string]string{estargzTOCDigestAnnotation: "sha256:a"}),
+		layer(map[string]string{estargzTOCDigestAnnotation: "sha256:b"}),
+	})
+	partial := createLazyPullImage(t, []mutate.Addendum{
+		layer(map[string]string{estargzTOCDigestAnnotation: "sha256:a"}),
+		layer(nil),
+	})
+
+	tests := []struct {
+		name     string
+		image    string
+		formats  []string
+		wantPass bool
+		wantMsg  string
+	}{
+		{"eStargz image", estargz, []string{"estargz", "nydus"}, true, "Image can be lazy-pulled as estargz"},
+		{"eStargz not accepted", estargz, []string{"nydus"}, false, "Image is estargz, which is not an accepted lazy-pull format"},
+		{"Partial eStargz", partial, []string{"estargz"}, false, "Image is not fully eStargz: 1 of 2 layers have no TOC"},
+		{"Plain image", createTestImage(t, testImageOptions{layerCount: 1}), []string{"estargz"}, false, "Image has no lazy-pull artifacts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runLazyPull(context.Background(), tt.image, tt.formats)
+			require.NoError(t, err)
+			assert.Equal(t, checkLazyPull, result.Check)
+			assert.Equal(t, tt.wantPass, result.Passed)
+			assert.Equal(t, tt.wantMsg, result.Message)
+			details, ok := result.Details.(output.LazyPullDetails)
+			require.True(t, ok)
+			assert.Equal(t, tt.formats, details.AcceptedFormats)
+		})
+	}
+}
+
+func TestRenderLazyPullText(t *testing.T) {
+	r := &output.CheckResult{
+		Check:   checkLazyPull,
+		Image:   "app:1.0",
+		Passed:  true,
+		Message: "Image can be lazy-pulled as estargz",
+		Details: output.LazyPullDetails{Format: "estargz", AcceptedFormats: []string{"estargz", "nydus"}, Layers: 2, EStargzLayers: 2},
+	}
+	out := captureStdout(t, func() { renderLazyPullText(stdout, r) })
+	assert.Contains(t, out, "Format: estargz")
+	assert.Contains(t, out, "Accepted formats: estargz, nydus")
+	assert.Contains(t, out, "Layers: 2 (eStargz: 2, Nydus blobs: 0, Nydus bootstrap: false)")
+}
+
+func TestRunAll_LazyPullOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	for _, c := range determineChecks(nil, map[string]bool{}, nil, checkParams{}) {
+		assert.NotEqual(t, checkLazyPull, c.name)
+	}
+	var found bool
+	for _, c := range determineChecks(nil, map[string]bool{}, nil, checkParams{lazyPullFormats: "nydus"}) {
+		found = found || c.name == checkLazyPull
+	}
+	assert.True(t, found)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&lazyPullFormats, "lazy-pull-formats", "", "")
+	applyLazyPullConfig(cmd, &lazyPullCheckConfig{LazyPullFormats: []any{"estargz", "nydus"}})
+	assert.Equal(t, "estargz,nydus", lazyPullFormats)
+}
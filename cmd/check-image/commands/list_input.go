@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// listInputDoc describes the @file convention shared by list-valued flags.
+const listInputDoc = `List-valued flags accept a comma-separated list or @<file> naming a JSON or
+YAML file (@- reads stdin) that holds either an array or an object with the
+list under the flag name, e.g. {"skip": ["age", "size"]}.`
+
+// parseListInput resolves a list-valued flag. A value starting with "@" names
+// a JSON or YAML file ("@-" reads stdin) containing either an array or an
+// object with the list under key (a missing key yields no items); any other
+// value is split on commas. Items are trimmed and empty items are dropped; an
+// empty file list is returned as an empty, non-nil slice. Scalar file items
+// (numbers, booleans) are returned in their text form so callers validate
+// every source the same way.
+func parseListInput(value, key string) ([]string, error) {
+	path, isFile := strings.CutPrefix(value, "@")
+	if !isFile {
+		return splitList(value), nil
+	}
+
+	var raw any
+	if err := parseAllowedListFromFile(path, &raw); err != nil {
+		return nil, err
+	}
+	if m, ok := raw.(map[string]any); ok {
+		raw = m[key]
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a list or an object with a %q list, got %T", path, key, raw)
+	}
+
+	list := make([]string, 0, len(items))
+	for i, item := range items {
+		s, err := listItemString(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: item %d: %w", path, i+1, err)
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func listItemString(item any) (string, error) {
+	switch v := item.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("expected a string or number, got %T", item)
+	}
+}
+
+// splitList splits a comma-separated list, trimming whitespace and dropping
+// empty items.
+func splitList(s string) []string {
+	var list []string
+	for part := range strings.SplitSeq(s, ",") {
+		if item := strings.TrimSpace(part); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
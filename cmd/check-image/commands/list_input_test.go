@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		fileName string
+		content  string
+		want     []string
+		wantErr  string
+	}{
+		{name: "Comma-separated", value: "age, size,,user", want: []string{"age", "size", "user"}},
+		{name: "Empty value", value: "", want: nil},
+		{name: "JSON array", fileName: "list.json", content: `["age", "size"]`, want: []string{"age", "size"}},
+		{name: "YAML array", fileName: "list.yaml", content: "- age\n- size\n", want: []string{"age", "size"}},
+		{name: "JSON object under key", fileName: "list.json", content: `{"skip": ["secrets"]}`, want: []string{"secrets"}},
+		{name: "YAML object under key", fileName: "list.yaml", content: "skip:\n  - registry\n  - ' labels '\n", want: []string{"registry", "labels"}},
+		{name: "Object without key", fileName: "list.json", content: `{"include": ["age"]}`, want: nil},
+		{name: "Empty array", fileName: "list.json", content: `[]`, want: []string{}},
+		{name: "Numbers", fileName: "list.yaml", content: "- 80\n- 443\n", want: []string{"80", "443"}},
+		{name: "JSON numbers", fileName: "list.json", content: `[8080, 1.5]`, want: []string{"8080", "1.5"}},
+		{name: "Nested item", fileName: "list.json", content: `[["age"]]`, wantErr: "item 1: expected a string or number"},
+		{name: "Scalar instead of list", fileName: "list.json", content: `{"skip": "age"}`, wantErr: `expected a list or an object with a "skip" list`},
+		{name: "Invalid YAML", fileName: "list.yaml", content: "skip: [age", wantErr: "invalid YAML"},
+		{name: "Missing file", value: "@/nonexistent/list.json", wantErr: "failed to read file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := tt.value
+			if tt.fileName != "" {
+				path := filepath.Join(t.TempDir(), tt.fileName)
+				require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+				value = "@" + path
+			}
+
+			got, err := parseListInput(value, "skip")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseListInput_Stdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = w.WriteString(`{"include": ["age", "user"]}`)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	got, err := parseListInput("@-", "include")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"age", "user"}, got)
+}
+
+func TestListFlags_FromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return "@" + path
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		got, err := parseCheckNameList(write("skip.yaml", "skip:\n  - age\n  - size\n"), "skip")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"age": true, "size": true}, got)
+	})
+
+	t.Run("include with unknown check", func(t *testing.T) {
+		_, err := parseCheckNameList(write("include.json", `["age", "bogus"]`), "include")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown check name")
+	})
+
+	t.Run("blocked-users", func(t *testing.T) {
+		got, err := parseBlockedUsers(write("users.json", `{"blocked-users": ["daemon", "nobody"]}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"daemon", "nobody"}, got)
+	})
+
+	t.Run("base-layers", func(t *testing.T) {
+		digest := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		base, err := newLayerBase(false, "", write("base.yaml", "- "+digest+"\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{digest}, base.digests)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		_, err := parseBlockedUsers("@/nonexistent/users.json")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --blocked-users")
+	})
+}
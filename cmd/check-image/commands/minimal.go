@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/minimal"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// defaultMinimalMaxSizeMB is the size budget of the minimal check, well above
+// distroless base images and well below images with a full distribution.
+const defaultMinimalMaxSizeMB = 50
+
+// checkMinimalFlag enables the opt-in minimal check of the all command.
+var checkMinimalFlag bool
+var minimalMaxSize uint = defaultMinimalMaxSizeMB
+var minimalCriteria string
+
+var minimalCmd = &cobra.Command{
+	Use:   "minimal image",
+	Short: "Validate that the image is minimal: no shell, package manager, or compiler, and a small size",
+	Long: `Validate that the image is minimal, in the style of distroless images.
+
+The check evaluates four criteria at once and reports which of them fail:
+  - shell: no shell (sh, bash, ash, dash, ...) in the bin directories
+  - package-manager: no package manager (apt, dpkg, apk, yum, dnf, rpm, ...)
+  - compiler: no compiler toolchain (cc, gcc, clang, go, rustc, javac, ...)
+  - size: the compressed size is within max-size megabytes (default 50)
+
+Executables are looked up in /bin, /sbin, /usr/bin, /usr/sbin, /usr/local/bin,
+and /usr/local/sbin of the merged filesystem of the image, following symbolic
+links. Use --criteria to evaluate only some of the criteria.
+
+` + imageArgFormatsDoc,
+	Example: `  check-image minimal gcr.io/distroless/static-debian12
+  check-image minimal registry.example.com/app:1.0 --max-size 20 -o json
+  check-image minimal registry.example.com/app:1.0 --criteria shell,package-manager
+  check-image minimal oci:/path/to/layout:1.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		criteria, err := parseMinimalCriteria(minimalCriteria)
+		if err != nil {
+			return err
+		}
+		return runCheckCmd(checkMinimal, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runMinimal(ctx, img, criteria, minimalMaxSize)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(minimalCmd)
+	minimalCmd.Flags().UintVar(&minimalMaxSize, "max-size", defaultMinimalMaxSizeMB, "Size budget in megabytes of the size criterion (optional)")
+	minimalCmd.Flags().StringVar(&minimalCriteria, "criteria", "", "Comma-separated list of criteria to evaluate (shell, package-manager, compiler, size) or @<file>, default all (optional)")
+}
+
+// parseMinimalCriteria parses a --criteria value. An empty value selects every
+// criterion.
+func parseMinimalCriteria(s string) ([]string, error) {
+	var names []string
+	if s != "" {
+		var err error
+		if names, err = parseListInput(s, "criteria"); err != nil {
+			return nil, err
+		}
+	}
+	return minimal.ParseCriteria(names)
+}
+
+func runMinimal(ctx context.Context, imageName string, criteria []string, maxSizeMB uint) (*output.CheckResult, error) {
+	image, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	maxSizeBytes, err := megabytesToBytes("max-size", maxSizeMB)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the layers: %w", err)
+	}
+	var totalSize int64
+	for i, layer := range layers {
+		size, err := layer.Size()
+		if err != nil {
+			return nil, fmt.Errorf("error getting size of layer %d: %w", i+1, err)
+		}
+		totalSize += size
+	}
+
+	found, err := minimal.FindExecutables(ctx, image, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	details := output.MinimalDetails{
+		Criteria:        criteria,
+		Shells:          found[minimal.CriterionShell],
+		PackageManagers: found[minimal.CriterionPackageManager],
+		Compilers:       found[minimal.CriterionCompiler],
+		TotalBytes:      totalSize,
+		TotalMB:         float64(totalSize) / 1024 / 1024,
+		MaxSizeMB:       maxSizeMB,
+	}
+	for _, c := range criteria {
+		if len(found[c]) > 0 || c == minimal.CriterionSize && totalSize > maxSizeBytes {
+			details.FailedCriteria = append(details.FailedCriteria, c)
+		}
+	}
+
+	passed := len(details.FailedCriteria) == 0
+	msg := fmt.Sprintf("Image meets all minimal criteria (%s)", strings.Join(criteria, ", "))
+	if !passed {
+		msg = fmt.Sprintf("Image fails %d of %d minimal criteria (%s)", len(details.FailedCriteria), len(criteria), strings.Join(details.FailedCriteria, ", "))
+	}
+
+	return &output.CheckResult{
+		Check:   checkMinimal,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: details,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimalCommand(t *testing.T) {
+	assert.Equal(t, "minimal image", minimalCmd.Use)
+	assert.Error(t, minimalCmd.Args(minimalCmd, []string{}))
+	assert.NoError(t, minimalCmd.Args(minimalCmd, []string{"image"}))
+
+	flag := minimalCmd.Flags().Lookup("max-size")
+	require.NotNil(t, flag)
+	assert.Equal(t, "50", flag.DefValue)
+	assert.NotNil(t, minimalCmd.Flags().Lookup("criteria"))
+}
+
+func TestParseMinimalCriteria(t *testing.T) {
+	criteria, err := parseMinimalCriteria("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell", "package-manager", "compiler", "size"}, criteria)
+
+	criteria, err = parseMinimalCriteria("size, shell")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell", "size"}, criteria)
+
+	_, err = parseMinimalCriteria("shell,debugger")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown minimal criterion "debugger"`)
+}
+
+func TestRunMinimal(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{
+		layerFiles: []map[string]string{
+			{"bin/sh": "shell", "usr/bin/apt-get": "apt"},
+			{"app/server": "binary"},
+		},
+	})
+
+	result, err := runMinimal(context.Background(), imageRef, []string{"shell", "package-manager", "compiler", "size"}, defaultMinimalMaxSizeMB)
+	require.NoError(t, err)
+	assert.Equal(t, checkMinimal, result.Check)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "Image fails 2 of 4 minimal criteria (shell, package-manager)", result.Message)
+	details := result.Details.(output.MinimalDetails)
+	assert.Equal(t, []string{"shell", "package-manager"}, details.FailedCriteria)
+	assert.Equal(t, []string{"/bin/sh"}, details.Shells)
+	assert.Equal(t, []string{"/usr/bin/apt-get"}, details.PackageManagers)
+	assert.Empty(t, details.Compilers)
+	assert.Positive(t, details.TotalBytes)
+
+	result, err = runMinimal(context.Background(), imageRef, []string{"compiler", "size"}, defaultMinimalMaxSizeMB)
+	require.NoError(t, err)
+	assert.True(t, result.Passed, "criteria that are not selected are not evaluated")
+	assert.Equal(t, "Image meets all minimal criteria (compiler, size)", result.Message)
+	assert.Empty(t, result.Details.(output.MinimalDetails).Shells)
+}
+
+func TestRunMinimal_SizeBudget(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{layerCount: 1, layerSizes: []int64{2 * 1024 * 1024}})
+
+	result, err := runMinimal(context.Background(), imageRef, []string{"size"}, 1)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, []string{"size"}, result.Details.(output.MinimalDetails).FailedCriteria)
+
+	result, err = runMinimal(context.Background(), imageRef, []string{"size"}, 10)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+}
+
+func TestRunMinimal_ImageError(t *testing.T) {
+	_, err := runMinimal(context.Background(), "oci:/nonexistent:latest", []string{"shell"}, defaultMinimalMaxSizeMB)
+	require.Error(t, err)
+}
+
+func TestBuildCheckDefs_MinimalOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	enabled := func(p checkParams, cfg *allConfig) bool {
+		for _, def := range buildCheckDefs(cfg, p) {
+			if def.name == checkMinimal {
+				return def.enabled
+			}
+		}
+		t.Fatal("minimal check not defined")
+		return false
+	}
+
+	assert.False(t, enabled(checkParams{}, nil))
+	assert.True(t, enabled(checkParams{checkMinimal: true}, nil))
+	assert.False(t, enabled(checkParams{checkMinimal: true}, &allConfig{}))
+	assert.True(t, enabled(checkParams{}, &allConfig{Checks: allChecksConfig{Minimal: &minimalCheckConfig{}}}))
+}
+
+func TestApplyMinimalConfig(t *testing.T) {
+	resetAllGlobals(t)
+	cfg, err := parseAllConfig([]byte("checks:\n  minimal:\n    max-size: 20\n    criteria: [shell, size]\n"), "config.yaml")
+	require.NoError(t, err)
+	applyMinimalConfig(allCmd, cfg.Checks.Minimal)
+	assert.Equal(t, uint(20), minimalMaxSize)
+	assert.Equal(t, "shell,size", minimalCriteria)
+
+	resetAllGlobals(t)
+	applyMinimalConfig(allCmd, &minimalCheckConfig{})
+	assert.Equal(t, uint(defaultMinimalMaxSizeMB), minimalMaxSize)
+	assert.Empty(t, minimalCriteria)
+}
+
+func TestRenderMinimalText(t *testing.T) {
+	result := &output.CheckResult{
+		Check:   checkMinimal,
+		Image:   "app:1.0",
+		Message: "Image fails 2 of 4 minimal criteria (shell, size)",
+		Details: output.MinimalDetails{
+			Criteria:       []string{"shell", "package-manager", "compiler", "size"},
+			FailedCriteria: []string{"shell", "size"},
+			Shells:         []string{"/bin/sh", "/bin/bash"},
+			TotalBytes:     80 * 1024 * 1024,
+			TotalMB:        80,
+			MaxSizeMB:      50,
+		},
+	}
+
+	captured := captureStdout(t, func() { renderMinimalText(stdout, result) })
+	assert.Contains(t, captured, "Checking that image app:1.0 is minimal")
+	assert.Contains(t, captured, "shell: fail")
+	assert.Contains(t, captured, "- /bin/bash")
+	assert.Contains(t, captured, "package-manager: pass")
+	assert.Contains(t, captured, "size: fail")
+	assert.Contains(t, captured, "50 MB")
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/opainput"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var opaInputCmd = &cobra.Command{
+	Use:   "opa-input image",
+	Short: "Print the normalized JSON document a Rego policy evaluates for an image",
+	Long: `Print the normalized JSON document that describes the image to Open Policy
+Agent: its reference, digest, platform, and creation time, its runtime
+configuration, and its layers. The same image always produces the same
+document, so it can be saved as test data and used with 'opa eval' or
+'opa test' while developing Rego policies. No checks are run.
+
+The document is always printed as JSON, regardless of --output.`,
+	Example: `  check-image opa-input nginx:latest > input.json
+  opa eval -i input.json -d policy.rego 'data.image.deny'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := runOPAInput(ctx, args[0]); err != nil {
+			return fmt.Errorf("opa-input operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(opaInputCmd)
+}
+
+func runOPAInput(ctx context.Context, imageName string) error {
+	ref, err := imageutil.ParseReference(imageName)
+	if err != nil {
+		return err
+	}
+	img, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// The registry is only known for registry references.
+	registry, _ := imageutil.GetImageRegistry(imageName)
+
+	input, err := opainput.Build(imageName, string(ref.Transport), registry, img, config)
+	if err != nil {
+		return fmt.Errorf("failed to build input document: %w", err)
+	}
+	return output.RenderJSON(os.Stdout, input)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/opainput"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOPAInput(t *testing.T) {
+	resetAllGlobals(t)
+	image := createTestImage(t, testImageOptions{
+		user:         "1000",
+		created:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		exposedPorts: map[string]struct{}{"8080/tcp": {}},
+		labels:       map[string]string{"team": "platform"},
+		os:           "linux",
+		architecture: "amd64",
+		layerCount:   1,
+	})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runOPAInput(context.Background(), image))
+	})
+
+	var input opainput.Input
+	require.NoError(t, json.Unmarshal([]byte(out), &input))
+	assert.Equal(t, image, input.Image.Reference)
+	assert.Equal(t, "oci", input.Image.Transport)
+	assert.Empty(t, input.Image.Registry)
+	assert.Equal(t, "linux/amd64", input.Image.Platform)
+	assert.Equal(t, "2026-01-02T03:04:05Z", input.Image.Created)
+	assert.Contains(t, input.Image.Digest, "sha256:")
+	assert.Equal(t, "1000", input.Config.User)
+	assert.Equal(t, []string{"8080/tcp"}, input.Config.ExposedPorts)
+	assert.Equal(t, map[string]string{"team": "platform"}, input.Config.Labels)
+	assert.Len(t, input.Manifest.Layers, 1)
+	assert.Equal(t, ValidationSkipped, Result, "opa-input does not change the result")
+}
+
+func TestRunOPAInput_InvalidImage(t *testing.T) {
+	resetAllGlobals(t)
+	err := runOPAInput(context.Background(), "oci:/nonexistent/layout:latest")
+	require.Error(t, err)
+}
+
+func TestOPAInputCommand(t *testing.T) {
+	assert.Equal(t, "opa-input image", opaInputCmd.Use)
+	require.Error(t, opaInputCmd.Args(opaInputCmd, []string{}))
+	require.NoError(t, opaInputCmd.Args(opaInputCmd, []string{"nginx:latest"}))
+}
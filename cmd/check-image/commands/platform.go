@@ -63,30 +63,14 @@ func parseAllowedPlatformsFrom(platformsStr string) ([]string, error) {
 		return nil, fmt.Errorf("--allowed-platforms is required")
 	}
 
-	if after, ok := strings.CutPrefix(platformsStr, "@"); ok {
-		var platformsFromFile allowedPlatformsFile
-		if err := parseAllowedListFromFile(after, &platformsFromFile); err != nil {
-			return nil, err
-		}
-		for _, p := range platformsFromFile.AllowedPlatforms {
-			if err := validatePlatformFormat(p); err != nil {
-				return nil, err
-			}
-		}
-		return platformsFromFile.AllowedPlatforms, nil
+	platforms, err := parseListInput(platformsStr, "allowed-platforms")
+	if err != nil {
+		return nil, err
 	}
-
-	parts := strings.Split(platformsStr, ",")
-	var platforms []string
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed == "" {
-			continue
-		}
-		if err := validatePlatformFormat(trimmed); err != nil {
+	for _, p := range platforms {
+		if err := validatePlatformFormat(p); err != nil {
 			return nil, err
 		}
-		platforms = append(platforms, trimmed)
 	}
 
 	return platforms, nil
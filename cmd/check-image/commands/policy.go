@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/policyexport"
+	"github.com/jarfernandez/check-image/internal/registry"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+var exportConfigFile string
+var exportRegistryPolicy string
+var exportLabelsPolicy string
+var exportRequireDigest bool
+var exportName string
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Work with check-image policies",
+	Long:  `Work with check-image policies.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var policyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export policies as Kyverno or Gatekeeper admission policies",
+	Long: `Export the subset of check-image policies that can be enforced at admission time
+as native Kubernetes policies, giving a migration path to cluster enforcement.
+
+Exportable policies:
+  - Registry allow-list or block-list (--registry-policy or checks.registry in --config)
+  - Required image labels (--labels-policy or checks.labels in --config), Kyverno only
+  - Digest pinning (--require-digest)
+
+Other checks (age, size, secrets, ...) need the image contents and are ignored.
+CLI flags override values from the config file. Resources are written to stdout
+as multi-document YAML, or as a Kubernetes List with --output json.`,
+	Example: `  check-image policy export --format kyverno --config config/config.yaml
+  check-image policy export --format gatekeeper --registry-policy config/registry-policy.yaml --require-digest
+  check-image policy export --format kyverno --labels-policy config/labels-policy.yaml | kubectl apply -f -`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runPolicyExport(); err != nil {
+			return fmt.Errorf("policy export operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyExportCmd)
+
+	policyExportCmd.Flags().StringVar(&exportFormat, "format", "", "Policy format: kyverno, gatekeeper")
+	policyExportCmd.Flags().StringVarP(&exportConfigFile, "config", "c", "", "All-checks configuration file (JSON or YAML) to read policies from (optional)")
+	policyExportCmd.Flags().StringVarP(&exportRegistryPolicy, "registry-policy", "r", "", "Registry policy file (JSON or YAML) (optional)")
+	policyExportCmd.Flags().StringVar(&exportLabelsPolicy, "labels-policy", "", "Labels policy file (JSON or YAML) (optional)")
+	policyExportCmd.Flags().BoolVar(&exportRequireDigest, "require-digest", false, "Require images to be pinned by digest (optional)")
+	policyExportCmd.Flags().StringVar(&exportName, "name", "check-image", "Name (or name prefix) of the generated resources (optional)")
+	if err := policyExportCmd.MarkFlagRequired("format"); err != nil {
+		panic(fmt.Sprintf("failed to mark format flag as required: %v", err))
+	}
+}
+
+func runPolicyExport() error {
+	format, err := policyexport.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	registryPath, labelsPath, cleanup, err := resolveExportPolicyPaths()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	in := policyexport.Input{Name: exportName, RequireDigest: exportRequireDigest}
+	if registryPath != "" {
+		if in.Registry, err = registry.LoadRegistryPolicy(registryPath); err != nil {
+			return fmt.Errorf("unable to load registry policy: %w", err)
+		}
+	}
+	if labelsPath != "" {
+		if in.Labels, err = labels.LoadLabelsPolicy(labelsPath); err != nil {
+			return fmt.Errorf("unable to load labels policy: %w", err)
+		}
+	}
+
+	res, err := policyexport.Export(format, in)
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		log.Warn(w)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return policyexport.RenderJSON(os.Stdout, res.Resources)
+	}
+	return policyexport.RenderYAML(os.Stdout, res.Resources)
+}
+
+// resolveExportPolicyPaths returns the registry and labels policy paths to
+// export, taking CLI flags over the config file. Inline policies in the
+// config file are written to temporary files removed by the returned cleanup,
+// which must always be deferred.
+func resolveExportPolicyPaths() (string, string, func(), error) {
+	registryPath, labelsPath := exportRegistryPolicy, exportLabelsPolicy
+	if exportConfigFile == "" {
+		return registryPath, labelsPath, func() {}, nil
+	}
+
+	cfg, err := loadAllConfig(exportConfigFile)
+	if err != nil {
+		return "", "", func() {}, err
+	}
+	warnNonExportableChecks(cfg)
+
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	if registryPath == "" && cfg.Checks.Registry != nil && cfg.Checks.Registry.RegistryPolicy != nil {
+		path, c, err := inlinePolicyToTempFile("registry-policy", cfg.Checks.Registry.RegistryPolicy)
+		if err != nil {
+			return "", "", cleanup, err
+		}
+		cleanups = append(cleanups, c)
+		registryPath = path
+	}
+	if labelsPath == "" && cfg.Checks.Labels != nil && cfg.Checks.Labels.LabelsPolicy != nil {
+		path, c, err := inlinePolicyToTempFile("labels-policy", cfg.Checks.Labels.LabelsPolicy)
+		if err != nil {
+			return "", "", cleanup, err
+		}
+		cleanups = append(cleanups, c)
+		labelsPath = path
+	}
+
+	return registryPath, labelsPath, cleanup, nil
+}
+
+// warnNonExportableChecks logs the checks in cfg that cannot be translated
+// into admission policies.
+func warnNonExportableChecks(cfg *allConfig) {
+	var ignored []string
+	for _, def := range buildCheckDefs(cfg, checkParams{}) {
+		if def.enabled && def.name != checkRegistry && def.name != checkLabels {
+			ignored = append(ignored, def.name)
+		}
+	}
+	if len(ignored) > 0 {
+		log.WithField("checks", strings.Join(ignored, ", ")).
+			Info("Checks that need image contents cannot be enforced at admission time and are not exported")
+	}
+}
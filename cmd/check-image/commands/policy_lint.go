@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/policylint"
+	"github.com/jarfernandez/check-image/internal/registry"
+	"github.com/jarfernandez/check-image/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var lintConfigFile string
+var lintRegistryPolicy string
+var lintSecretsPolicy string
+var lintLabelsPolicy string
+var lintStrict bool
+
+var policyLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Statically analyze registry, secrets, and labels policies for problems",
+	Long: `Statically analyze registry, secrets, and labels policies for entries that can
+never take effect or that weaken the policy more than intended, without pulling
+any image.
+
+Findings have two levels:
+  - error: the policy does not behave as written, e.g. a registry with a scheme
+    or wildcard that never matches, a label pattern that can never match, an
+    excluded path like /** that disables the file scan, or a registry both
+    trusted and excluded
+  - warning: likely mistakes, e.g. duplicate entries, custom patterns already
+    covered by the defaults, or label patterns not anchored with ^...$
+
+Policies are read from the policy flags, or from the checks of --config (file
+paths or inline policies). The command fails (exit code 1) when there are
+errors, or warnings with --strict.`,
+	Example: `  check-image policy lint --config config/config.yaml
+  check-image policy lint --registry-policy config/registry-policy.yaml --labels-policy config/labels-policy.yaml
+  check-image policy lint --secrets-policy config/secrets-policy.yaml --strict -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runPolicyLint(); err != nil {
+			return fmt.Errorf("policy lint operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyLintCmd)
+
+	policyLintCmd.Flags().StringVarP(&lintConfigFile, "config", "c", "", "All-checks configuration file (JSON or YAML) to read policies from (optional)")
+	policyLintCmd.Flags().StringVarP(&lintRegistryPolicy, "registry-policy", "r", "", "Registry policy file (JSON or YAML) (optional)")
+	policyLintCmd.Flags().StringVarP(&lintSecretsPolicy, "secrets-policy", "s", "", "Secrets policy file (JSON or YAML) (optional)")
+	policyLintCmd.Flags().StringVar(&lintLabelsPolicy, "labels-policy", "", "Labels policy file (JSON or YAML) (optional)")
+	policyLintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Fail on warnings as well as errors (optional)")
+}
+
+// lintTarget is a policy to lint. name is what findings report: the file
+// path, or the config key for inline policies.
+type lintTarget struct {
+	name string
+	path string
+	lint func(data []byte, path string) ([]policylint.Finding, error)
+}
+
+func runPolicyLint() error {
+	targets, cleanup, err := resolveLintTargets()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no policy to lint: use --config, --registry-policy, --secrets-policy, or --labels-policy")
+	}
+
+	result := output.PolicyLintResult{Findings: []output.PolicyLintFinding{}}
+	for _, t := range targets {
+		data, err := fileutil.ReadFileOrStdin(t.path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", t.name, err)
+		}
+		findings, err := t.lint(data, t.path)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", t.name, err)
+		}
+		for _, f := range findings {
+			result.Findings = append(result.Findings, output.PolicyLintFinding{
+				Policy:   t.name,
+				Severity: string(f.Severity),
+				Rule:     f.Rule,
+				Field:    f.Field,
+				Message:  f.Message,
+			})
+			if f.Severity == policylint.SeverityError {
+				result.Summary.Errors++
+			} else {
+				result.Summary.Warnings++
+			}
+		}
+	}
+	result.Summary.Policies = len(targets)
+	result.Passed = result.Summary.Errors == 0 && (!lintStrict || result.Summary.Warnings == 0)
+
+	if result.Passed {
+		UpdateResult(ValidationSucceeded)
+	} else {
+		UpdateResult(ValidationFailed)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	renderPolicyLintText(result)
+	return nil
+}
+
+// resolveLintTargets returns the policies to lint, taking CLI flags over the
+// config file. Inline policies in the config file are written to temporary
+// files removed by the returned cleanup, which must always be deferred.
+func resolveLintTargets() ([]lintTarget, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	var cfgChecks allChecksConfig
+	if lintConfigFile != "" {
+		cfg, err := loadUnvalidatedAllConfig(lintConfigFile)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cfgChecks = cfg.Checks
+	}
+
+	kinds := []struct {
+		flagPath string
+		key      string
+		inline   func() any
+		lint     func(data []byte, path string) ([]policylint.Finding, error)
+	}{
+		{lintRegistryPolicy, "registry-policy", func() any {
+			if cfgChecks.Registry == nil {
+				return nil
+			}
+			return cfgChecks.Registry.RegistryPolicy
+		}, lintRegistryData},
+		{lintSecretsPolicy, "secrets-policy", func() any {
+			if cfgChecks.Secrets == nil {
+				return nil
+			}
+			return cfgChecks.Secrets.SecretsPolicy
+		}, lintSecretsData},
+		{lintLabelsPolicy, "labels-policy", func() any {
+			if cfgChecks.Labels == nil {
+				return nil
+			}
+			return cfgChecks.Labels.LabelsPolicy
+		}, lintLabelsData},
+	}
+
+	var targets []lintTarget
+	for _, k := range kinds {
+		if k.flagPath != "" {
+			targets = append(targets, lintTarget{name: k.flagPath, path: k.flagPath, lint: k.lint})
+			continue
+		}
+		v := k.inline()
+		if v == nil {
+			continue
+		}
+		path, c, err := inlinePolicyToTempFile(k.key, v)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanups = append(cleanups, c)
+		name := path
+		if _, isPath := v.(string); !isPath {
+			name = fmt.Sprintf("%s (inline %s)", lintConfigFile, k.key)
+		}
+		targets = append(targets, lintTarget{name: name, path: path, lint: k.lint})
+	}
+	return targets, cleanup, nil
+}
+
+// The lint functions parse policies without the loaders' validation, so that
+// problems the loaders would reject are reported as findings too.
+
+func lintRegistryData(data []byte, path string) ([]policylint.Finding, error) {
+	var p registry.Policy
+	if err := fileutil.UnmarshalConfigData(data, &p, path); err != nil {
+		return nil, err
+	}
+	return policylint.Registry(&p), nil
+}
+
+func lintSecretsData(data []byte, path string) ([]policylint.Finding, error) {
+	var p secrets.Policy
+	if err := fileutil.UnmarshalConfigData(data, &p, path); err != nil {
+		return nil, err
+	}
+	return policylint.Secrets(&p), nil
+}
+
+func lintLabelsData(data []byte, path string) ([]policylint.Finding, error) {
+	var p labels.Policy
+	if err := fileutil.UnmarshalConfigData(data, &p, path); err != nil {
+		return nil, err
+	}
+	return policylint.Labels(&p), nil
+}
+
+func renderPolicyLintText(result output.PolicyLintResult) {
+	for _, f := range result.Findings {
+		level := FailStyle.Render("error")
+		if f.Severity == string(policylint.SeverityWarning) {
+			level = valueStyle.Render("warning")
+		}
+		location := f.Policy
+		if f.Field != "" {
+			location += ": " + f.Field
+		}
+		fmt.Printf("%s %s: %s %s\n", level, location, f.Message, dimStyle.Render("("+f.Rule+")"))
+	}
+	if len(result.Findings) > 0 {
+		fmt.Println()
+	}
+
+	msg := fmt.Sprintf("Linted %d policies: %d errors, %d warnings",
+		result.Summary.Policies, result.Summary.Errors, result.Summary.Warnings)
+	fmt.Println(statusPrefix(result.Passed) + msg)
+}
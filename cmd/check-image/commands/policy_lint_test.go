@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetPolicyLintGlobals(t *testing.T) {
+	t.Helper()
+	resetAllGlobals(t)
+	reset := func() {
+		lintConfigFile = ""
+		lintRegistryPolicy = ""
+		lintSecretsPolicy = ""
+		lintLabelsPolicy = ""
+		lintStrict = false
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestPolicyLintCommand(t *testing.T) {
+	assert.Equal(t, "lint", policyLintCmd.Use)
+	assert.Equal(t, policyCmd, policyLintCmd.Parent())
+	for _, name := range []string{"config", "registry-policy", "secrets-policy", "labels-policy", "strict"} {
+		assert.NotNil(t, policyLintCmd.Flags().Lookup(name), name)
+	}
+}
+
+func TestRunPolicyLint_Files(t *testing.T) {
+	resetPolicyLintGlobals(t)
+	dir := t.TempDir()
+	lintRegistryPolicy = filepath.Join(dir, "registry.yaml")
+	require.NoError(t, os.WriteFile(lintRegistryPolicy, []byte("trusted-registries:\n  - docker.io\n  - ghcr.io\n"), 0600))
+	lintLabelsPolicy = filepath.Join(dir, "labels.yaml")
+	require.NoError(t, os.WriteFile(lintLabelsPolicy, []byte("required-labels:\n  - name: version\n    pattern: \"v1\"\n"), 0600))
+	OutputFmt = output.FormatJSON
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPolicyLint())
+	})
+
+	var result output.PolicyLintResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.False(t, result.Passed)
+	assert.Equal(t, output.PolicyLintSummary{Policies: 2, Errors: 1, Warnings: 1}, result.Summary)
+	require.Len(t, result.Findings, 2)
+	assert.Equal(t, output.PolicyLintFinding{
+		Policy:   lintRegistryPolicy,
+		Severity: "error",
+		Rule:     "unreachable-entry",
+		Field:    "trusted-registries[0]",
+		Message:  `"docker.io" never matches: Docker Hub images are reported as index.docker.io`,
+	}, result.Findings[0])
+	assert.Equal(t, "unanchored-pattern", result.Findings[1].Rule)
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunPolicyLint_InlineConfigAndStrict(t *testing.T) {
+	resetPolicyLintGlobals(t)
+	lintConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	content := `checks:
+  secrets:
+    secrets-policy:
+      check-env-vars: true
+      check-files: true
+      custom-file-patterns:
+        - id_rsa
+`
+	require.NoError(t, os.WriteFile(lintConfigFile, []byte(content), 0600))
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPolicyLint())
+	})
+	assert.Contains(t, out, "warning "+lintConfigFile+" (inline secrets-policy): custom-file-patterns[0]")
+	assert.Contains(t, out, "Linted 1 policies: 0 errors, 1 warnings")
+	assert.Equal(t, ValidationSucceeded, Result)
+
+	Result = ValidationSucceeded
+	lintStrict = true
+	captureStdout(t, func() {
+		require.NoError(t, runPolicyLint())
+	})
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunPolicyLint_Errors(t *testing.T) {
+	resetPolicyLintGlobals(t)
+	err := runPolicyLint()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no policy to lint")
+
+	lintSecretsPolicy = filepath.Join(t.TempDir(), "missing.yaml")
+	err = runPolicyLint()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading "+lintSecretsPolicy)
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetPolicyExportGlobals(t *testing.T) {
+	t.Helper()
+	resetAllGlobals(t)
+	reset := func() {
+		exportFormat = ""
+		exportConfigFile = ""
+		exportRegistryPolicy = ""
+		exportLabelsPolicy = ""
+		exportRequireDigest = false
+		exportName = "check-image"
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestPolicyExportCommand(t *testing.T) {
+	assert.Equal(t, "export", policyExportCmd.Use)
+	assert.Equal(t, policyCmd, policyExportCmd.Parent())
+	for _, name := range []string{"format", "config", "registry-policy", "labels-policy", "require-digest", "name"} {
+		assert.NotNil(t, policyExportCmd.Flags().Lookup(name), name)
+	}
+}
+
+func TestRunPolicyExport_InlineConfig(t *testing.T) {
+	resetPolicyExportGlobals(t)
+
+	cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `checks:
+  age:
+    max-age: 30
+  registry:
+    registry-policy:
+      trusted-registries:
+        - ghcr.io
+  labels:
+    labels-policy:
+      required-labels:
+        - name: maintainer
+`
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+	exportConfigFile = cfgFile
+	exportFormat = "kyverno"
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPolicyExport())
+	})
+
+	assert.Contains(t, out, "kind: ClusterPolicy")
+	assert.Contains(t, out, "- ghcr.io")
+	assert.Contains(t, out, "name: required-labels")
+	assert.NotContains(t, out, "require-digest")
+	assert.Equal(t, ValidationSkipped, Result)
+}
+
+func TestRunPolicyExport_FlagsOverrideConfig(t *testing.T) {
+	resetPolicyExportGlobals(t)
+
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("checks:\n  registry:\n    registry-policy:\n      trusted-registries: [ghcr.io]\n"), 0600))
+	policyFile := filepath.Join(dir, "registry-policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte("trusted-registries:\n  - quay.io\n"), 0600))
+
+	exportConfigFile = cfgFile
+	exportRegistryPolicy = policyFile
+	exportFormat = "gatekeeper"
+	exportRequireDigest = true
+	OutputFmt = output.FormatJSON
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPolicyExport())
+	})
+
+	var list struct {
+		Kind  string           `json:"kind"`
+		Items []map[string]any `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &list))
+	assert.Equal(t, "List", list.Kind)
+	require.Len(t, list.Items, 4)
+	assert.Contains(t, out, "quay.io")
+	assert.NotContains(t, out, "ghcr.io")
+}
+
+func TestRunPolicyExport_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(t *testing.T)
+		expectedError string
+	}{
+		{
+			name:          "Invalid format",
+			setup:         func(t *testing.T) { exportFormat = "opa"; exportRequireDigest = true },
+			expectedError: "unsupported policy format",
+		},
+		{
+			name:          "Nothing to export",
+			setup:         func(t *testing.T) { exportFormat = "kyverno" },
+			expectedError: "nothing to export",
+		},
+		{
+			name: "Invalid registry policy",
+			setup: func(t *testing.T) {
+				exportFormat = "kyverno"
+				exportRegistryPolicy = filepath.Join(t.TempDir(), "missing.yaml")
+			},
+			expectedError: "unable to load registry policy",
+		},
+		{
+			name: "Invalid config file",
+			setup: func(t *testing.T) {
+				exportFormat = "kyverno"
+				exportConfigFile = filepath.Join(t.TempDir(), "missing.yaml")
+			},
+			expectedError: "failed to read config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPolicyExportGlobals(t)
+			tt.setup(t)
+
+			err := runPolicyExport()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jarfernandez/check-image/internal/decision"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
 	log "github.com/sirupsen/logrus"
@@ -18,14 +19,45 @@ type allowedPortsFile struct {
 }
 
 var allowedPorts string
+var maxExposedPorts uint
+var forbidPrivilegedPorts bool
+
+// msgNoAllowedPorts is the message of a failed check without constraints.
+const msgNoAllowedPorts = "No allowed ports were provided"
+
+// maxPrivilegedPort is the highest port that only root can bind by default.
+const maxPrivilegedPort = 1023
+
+// Constraints of the ports check, reported in PortsDetails.FailedConstraints.
+const (
+	constraintAllowedPorts          = "allowed-ports"
+	constraintMaxExposedPorts       = "max-exposed-ports"
+	constraintForbidPrivilegedPorts = "forbid-privileged-ports"
+)
+
+// portLimits are the ports check constraints besides the allowed list.
+type portLimits struct {
+	// maxExposed is the maximum number of exposed ports, 0 for no limit.
+	maxExposed       uint
+	forbidPrivileged bool
+}
+
+func (l portLimits) enabled() bool {
+	return l.maxExposed > 0 || l.forbidPrivileged
+}
 
 var portsCmd = &cobra.Command{
 	Use:   "ports image",
 	Short: "Validate that the image does not expose unauthorized ports",
 	Long: `Validate that the image does not expose unauthorized ports.
 
+Exposed ports are checked against --allowed-ports, and optionally limited in
+number with --max-exposed-ports. --forbid-privileged-ports rejects ports below
+1024, which non-root processes cannot bind by default.
+
 ` + imageArgFormatsDoc,
 	Example: `  check-image ports nginx:latest --allowed-ports 80,443
+  check-image ports myapp:latest --max-exposed-ports 2 --forbid-privileged-ports
   check-image ports nginx:latest --allowed-ports @allowed-ports.json
   check-image ports nginx:latest --allowed-ports @allowed-ports.yaml
   check-image ports oci:/path/to/layout:1.0 --allowed-ports 8080,8443
@@ -43,7 +75,7 @@ var portsCmd = &cobra.Command{
 
 		ctx := cmd.Context()
 		return runCheckCmd(checkPorts, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runPorts(ctx, img, ports)
+			return runPorts(ctx, img, ports, portLimits{maxExposed: maxExposedPorts, forbidPrivileged: forbidPrivilegedPorts})
 		}, ctx, args[0], OutputFmt)
 	},
 }
@@ -51,6 +83,14 @@ var portsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(portsCmd)
 	portsCmd.Flags().StringVarP(&allowedPorts, "allowed-ports", "p", "", "Comma-separated list of allowed ports or @<file> with JSON or YAML array (optional)")
+	addPortLimitFlags(portsCmd)
+}
+
+// addPortLimitFlags registers the ports check constraints besides the
+// allowed list on cmd.
+func addPortLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().UintVar(&maxExposedPorts, "max-exposed-ports", 0, "Maximum number of exposed ports, 0 for no limit (optional)")
+	cmd.Flags().BoolVar(&forbidPrivilegedPorts, "forbid-privileged-ports", false, "Fail when a privileged port (below 1024) is exposed (optional)")
 }
 
 func parseAllowedPorts() ([]int, error) {
@@ -62,29 +102,16 @@ func parseAllowedPortsFrom(portsStr string) ([]int, error) {
 		return nil, nil
 	}
 
-	if after, ok := strings.CutPrefix(portsStr, "@"); ok {
-		var portsFromFile allowedPortsFile
-		if err := parseAllowedListFromFile(after, &portsFromFile); err != nil {
-			return nil, err
-		}
-		for _, port := range portsFromFile.AllowedPorts {
-			if port < 1 || port > 65535 {
-				return nil, fmt.Errorf("port %d out of valid range 1-65535", port)
-			}
-		}
-		return portsFromFile.AllowedPorts, nil
+	items, err := parseListInput(portsStr, "allowed-ports")
+	if err != nil || items == nil {
+		return nil, err
 	}
 
-	parts := strings.Split(portsStr, ",")
-	var ports []int
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed == "" {
-			continue
-		}
-		port, err := strconv.Atoi(trimmed)
+	ports := make([]int, 0, len(items))
+	for _, item := range items {
+		port, err := strconv.Atoi(item)
 		if err != nil {
-			return nil, fmt.Errorf("invalid port '%s': %w", trimmed, err)
+			return nil, fmt.Errorf("invalid port '%s': %w", item, err)
 		}
 		if port < 1 || port > 65535 {
 			return nil, fmt.Errorf("port %d out of valid range 1-65535", port)
@@ -95,7 +122,7 @@ func parseAllowedPortsFrom(portsStr string) ([]int, error) {
 	return ports, nil
 }
 
-func runPorts(ctx context.Context, imageName string, allowedPortsList []int) (*output.CheckResult, error) {
+func runPorts(ctx context.Context, imageName string, allowedPortsList []int, limits portLimits) (*output.CheckResult, error) {
 	_, config, cleanup, err := imageutil.GetImageAndConfig(ctx, imageName)
 	if err != nil {
 		return nil, err
@@ -115,11 +142,13 @@ func runPorts(ctx context.Context, imageName string, allowedPortsList []int) (*o
 			exposedPorts = append(exposedPorts, port)
 		}
 	}
+	slices.Sort(exposedPorts)
 
 	details := output.PortsDetails{
 		ExposedPorts:      exposedPorts,
 		AllowedPorts:      allowedPortsList,
 		UnauthorizedPorts: nil,
+		MaxExposedPorts:   limits.maxExposed,
 	}
 
 	if len(exposedPorts) == 0 {
@@ -132,31 +161,69 @@ func runPorts(ctx context.Context, imageName string, allowedPortsList []int) (*o
 		}, nil
 	}
 
-	if len(allowedPortsList) == 0 {
+	if len(allowedPortsList) == 0 && !limits.enabled() {
 		return &output.CheckResult{
 			Check:   checkPorts,
 			Image:   imageName,
 			Passed:  false,
-			Message: "No allowed ports were provided",
+			Message: msgNoAllowedPorts,
 			Details: details,
 		}, nil
 	}
 
-	// Check if all exposed ports are in the allowed list
-	unauthorizedPorts := make([]int, 0)
-	for _, exposedPort := range exposedPorts {
-		isAllowed := slices.Contains(allowedPortsList, exposedPort)
-		if !isAllowed {
-			unauthorizedPorts = append(unauthorizedPorts, exposedPort)
+	var failures []string
+	if len(allowedPortsList) > 0 {
+		// Check if all exposed ports are in the allowed list
+		unauthorizedPorts := make([]int, 0)
+		for _, exposedPort := range exposedPorts {
+			isAllowed := slices.Contains(allowedPortsList, exposedPort)
+			if isAllowed {
+				decision.Explain(checkPorts, constraintAllowedPorts, strconv.Itoa(exposedPort), true, "port %d is in the allowed list", exposedPort)
+			} else {
+				decision.Explain(checkPorts, constraintAllowedPorts, strconv.Itoa(exposedPort), false, "port %d is not in the allowed list", exposedPort)
+				unauthorizedPorts = append(unauthorizedPorts, exposedPort)
+			}
+		}
+		details.UnauthorizedPorts = unauthorizedPorts
+		if len(unauthorizedPorts) > 0 {
+			details.FailedConstraints = append(details.FailedConstraints, constraintAllowedPorts)
+		}
+	}
+
+	if limits.maxExposed > 0 {
+		decision.Explain(checkPorts, constraintMaxExposedPorts, imageName, uint(len(exposedPorts)) > limits.maxExposed,
+			"%d ports are exposed, and the maximum is %d", len(exposedPorts), limits.maxExposed)
+	}
+	if limits.maxExposed > 0 && uint(len(exposedPorts)) > limits.maxExposed {
+		details.FailedConstraints = append(details.FailedConstraints, constraintMaxExposedPorts)
+		failures = append(failures, fmt.Sprintf("%d ports are exposed, more than the maximum of %d", len(exposedPorts), limits.maxExposed))
+	}
+
+	if limits.forbidPrivileged {
+		for _, port := range exposedPorts {
+			if port <= maxPrivilegedPort {
+				decision.Explain(checkPorts, constraintForbidPrivilegedPorts, strconv.Itoa(port), true, "port %d is privileged (below 1024)", port)
+				details.PrivilegedPorts = append(details.PrivilegedPorts, port)
+			} else {
+				decision.Explain(checkPorts, constraintForbidPrivilegedPorts, strconv.Itoa(port), false, "port %d is not privileged", port)
+			}
+		}
+		if len(details.PrivilegedPorts) > 0 {
+			details.FailedConstraints = append(details.FailedConstraints, constraintForbidPrivilegedPorts)
+			failures = append(failures, fmt.Sprintf("privileged ports below 1024 are exposed: %s", joinPorts(details.PrivilegedPorts)))
 		}
 	}
 
-	details.UnauthorizedPorts = unauthorizedPorts
-	passed := len(unauthorizedPorts) == 0
+	passed := len(details.FailedConstraints) == 0
 
 	var msg string
-	if passed {
+	switch {
+	case passed && len(allowedPortsList) > 0:
 		msg = "All exposed ports are in the allowed list"
+	case passed:
+		msg = "Exposed ports are within the configured limits"
+	case len(failures) > 0:
+		msg = strings.Join(failures, "; ")
 	}
 
 	return &output.CheckResult{
@@ -167,3 +234,11 @@ func runPorts(ctx context.Context, imageName string, allowedPortsList []int) (*o
 		Details: details,
 	}, nil
 }
+
+func joinPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,11 +1,15 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/jarfernandez/check-image/internal/decision"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -272,7 +276,7 @@ func TestRunPorts_NoExposedPorts(t *testing.T) {
 		exposedPorts: nil,
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443}, portLimits{})
 	require.NoError(t, err)
 	assert.True(t, result.Passed)
 }
@@ -286,7 +290,7 @@ func TestRunPorts_ExposedPortsWithNoAllowedList(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, nil)
+	result, err := runPorts(context.Background(), imageRef, nil, portLimits{})
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when exposed ports exist but no allowed list is provided")
 }
@@ -300,7 +304,7 @@ func TestRunPorts_AllPortsAllowed(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 8080})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 8080}, portLimits{})
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when all exposed ports are in allowed list")
 }
@@ -315,7 +319,7 @@ func TestRunPorts_SomePortsNotAllowed(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443}, portLimits{})
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when some exposed ports are not in allowed list")
 }
@@ -328,7 +332,7 @@ func TestRunPorts_NoPortsAllowed(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443}, portLimits{})
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when no exposed ports are in allowed list")
 }
@@ -342,13 +346,13 @@ func TestRunPorts_DifferentProtocols(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 53})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 53}, portLimits{})
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should handle different protocols (tcp/udp)")
 }
 
 func TestRunPorts_InvalidImageReference(t *testing.T) {
-	_, err := runPorts(context.Background(), "oci:/nonexistent/path:latest", []int{80, 443})
+	_, err := runPorts(context.Background(), "oci:/nonexistent/path:latest", []int{80, 443}, portLimits{})
 	require.Error(t, err)
 }
 
@@ -360,7 +364,7 @@ func TestRunPorts_SubsetOfAllowedPorts(t *testing.T) {
 		},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 8080, 9090, 3000})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443, 8080, 9090, 3000}, portLimits{})
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when exposed ports are a subset of allowed ports")
 }
@@ -370,7 +374,7 @@ func TestRunPorts_EmptyExposedPortsMap(t *testing.T) {
 		exposedPorts: map[string]struct{}{},
 	})
 
-	result, err := runPorts(context.Background(), imageRef, []int{80, 443})
+	result, err := runPorts(context.Background(), imageRef, []int{80, 443}, portLimits{})
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when exposed ports map is empty")
 }
@@ -452,3 +456,113 @@ func TestParseAllowedPorts_FromStdin(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPorts_Limits(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{
+		exposedPorts: map[string]struct{}{
+			"8080/tcp": {},
+			"443/tcp":  {},
+			"80/tcp":   {},
+		},
+	})
+
+	tests := []struct {
+		name            string
+		allowed         []int
+		limits          portLimits
+		wantPassed      bool
+		wantConstraints []string
+		wantPrivileged  []int
+		wantMessage     string
+	}{
+		{
+			name:        "Within the maximum",
+			limits:      portLimits{maxExposed: 3},
+			wantPassed:  true,
+			wantMessage: "Exposed ports are within the configured limits",
+		},
+		{
+			name:            "Too many ports",
+			limits:          portLimits{maxExposed: 2},
+			wantConstraints: []string{"max-exposed-ports"},
+			wantMessage:     "3 ports are exposed, more than the maximum of 2",
+		},
+		{
+			name:            "Privileged ports",
+			limits:          portLimits{forbidPrivileged: true},
+			wantConstraints: []string{"forbid-privileged-ports"},
+			wantPrivileged:  []int{80, 443},
+			wantMessage:     "privileged ports below 1024 are exposed: 80, 443",
+		},
+		{
+			name:            "Every constraint fails",
+			allowed:         []int{8080},
+			limits:          portLimits{maxExposed: 1, forbidPrivileged: true},
+			wantConstraints: []string{"allowed-ports", "max-exposed-ports", "forbid-privileged-ports"},
+			wantPrivileged:  []int{80, 443},
+			wantMessage:     "3 ports are exposed, more than the maximum of 1; privileged ports below 1024 are exposed: 80, 443",
+		},
+		{
+			name:        "Allowed list and limits pass",
+			allowed:     []int{80, 443, 8080},
+			limits:      portLimits{maxExposed: 3},
+			wantPassed:  true,
+			wantMessage: "All exposed ports are in the allowed list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runPorts(context.Background(), imageRef, tt.allowed, tt.limits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Equal(t, tt.wantMessage, result.Message)
+
+			details, ok := result.Details.(output.PortsDetails)
+			require.True(t, ok)
+			assert.Equal(t, []int{80, 443, 8080}, details.ExposedPorts, "exposed ports are sorted")
+			assert.Equal(t, tt.wantConstraints, details.FailedConstraints)
+			assert.Equal(t, tt.wantPrivileged, details.PrivilegedPorts)
+			assert.Equal(t, tt.limits.maxExposed, details.MaxExposedPorts)
+		})
+	}
+}
+
+func TestApplyPortsConfig_Limits(t *testing.T) {
+	resetAllGlobals(t)
+	limit := uint(2)
+	forbid := true
+
+	applyPortsConfig(allCmd, &portsCheckConfig{MaxExposedPorts: &limit, ForbidPrivilegedPorts: &forbid})
+
+	assert.Equal(t, uint(2), maxExposedPorts)
+	assert.True(t, forbidPrivilegedPorts)
+}
+
+func TestRunPorts_ExplainDecisions(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{
+		exposedPorts: map[string]struct{}{
+			"80/tcp":   {},
+			"8080/tcp": {},
+		},
+	})
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	decision.SetEnabled(true)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		decision.SetEnabled(false)
+	})
+
+	result, err := runPorts(context.Background(), imageRef, []int{8080}, portLimits{forbidPrivileged: true})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+
+	logs := buf.String()
+	assert.Contains(t, logs, "port 80 is not in the allowed list")
+	assert.Contains(t, logs, "port 8080 is in the allowed list")
+	assert.Contains(t, logs, "port 80 is privileged (below 1024)")
+	assert.Contains(t, logs, "port 8080 is not privileged")
+	assert.Contains(t, logs, "rule=forbid-privileged-ports")
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// reportArtifactType is the artifact type of validation reports attached
+	// to promoted images with --attest.
+	reportArtifactType = "application/vnd.check-image.report.v1+json"
+	reportMediaType    = "application/json"
+)
+
+var promoteAttest bool
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote source destination",
+	Short: "Validate an image and copy it to a destination only if all checks pass",
+	Long: `Run the same checks as the all command against the source image and, only if
+every check passes, copy it to the destination registry reference.
+
+The source accepts any supported transport (registry, oci:, oci-archive:,
+docker-archive:). The destination must be a registry reference. The source is
+resolved once: registry and OCI layout tags are pinned to their digest, which
+is what the checks validate and what is copied, so a tag moved in the meantime
+cannot get an unvalidated image promoted. Registry sources are copied as
+stored; a multi-platform index is refused, since the checks validate a single
+platform, unless it holds one platform besides attestation manifests.
+
+With --attest, the JSON validation report is pushed next to the promoted image
+as an OCI referrer artifact of type ` + reportArtifactType + `, annotated with
+the outcome so the status command can find it.
+
+Nothing is copied when a check fails, a check errors, a required-config policy
+is violated, or no checks are selected.`,
+	Example: `  check-image promote registry.example.com/app:rc registry.example.com/app:1.0 --config config.yaml
+  check-image promote oci:./layout:app registry.example.com/app:1.0 --config config.yaml --attest
+  check-image promote staging.example.com/app:1.0 prod.example.com/app:1.0 -c config.yaml -o json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := withReportFile(func() error { return runPromote(cmd, args[0], args[1]) }); err != nil {
+			return fmt.Errorf("promote operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+	addAllCheckFlags(promoteCmd)
+	promoteCmd.Flags().BoolVar(&promoteAttest, "attest", false, "Attach the JSON validation report to the promoted image as an OCI referrer (optional)")
+}
+
+func runPromote(cmd *cobra.Command, src, dst string) error {
+	if _, err := imageutil.ParseDestination(dst); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cleanupCache, err := ensureLayerCache()
+	if err != nil {
+		return err
+	}
+	defer cleanupCache()
+
+	// The source is resolved once, so that a tag moved during the validation
+	// does not get an unvalidated image promoted.
+	pinned, err := imageutil.PinSource(ctx, src)
+	if err != nil {
+		return err
+	}
+	if pinned.Platforms > 1 {
+		return fmt.Errorf("%s is a multi-platform index of %d platforms, but a single platform is validated; refusing to promote the index, promote the image of each platform by digest instead", src, pinned.Platforms)
+	}
+
+	run, err := evaluateAll(cmd, pinned.Ref)
+	if err != nil {
+		return err
+	}
+	if len(run.results) == 0 {
+		return fmt.Errorf("no checks to run, refusing to promote an unvalidated image")
+	}
+
+	result := output.PromoteResult{
+		Source:      src,
+		Destination: dst,
+		Validation:  run.report(pinned.Ref),
+	}
+
+	if Result == ValidationSucceeded {
+		if err := promoteImage(ctx, pinned, &result); err != nil {
+			return err
+		}
+	}
+
+	result = redactPromoteResult(result)
+	if OutputFmt == output.FormatJSON {
+		return writeReport(result)
+	}
+	printPromoteResult(result)
+	return nil
+}
+
+// redactPromoteResult redacts the source and destination references for
+// rendering. The validation report is already redacted.
+func redactPromoteResult(r output.PromoteResult) output.PromoteResult {
+	src, srcChanged := activeRedactor.String(r.Source)
+	dst, dstChanged := activeRedactor.String(r.Destination)
+	r.Source, r.Destination = src, dst
+	r.Redacted = srcChanged || dstChanged
+	return r
+}
+
+// ensureLayerCache enables a temporary layer cache for the run when
+// --cache-dir is not set, so layers downloaded by the secrets check are not
+// downloaded again for the copy. The cleanup must always be deferred.
+func ensureLayerCache() (func(), error) {
+	if imageutil.LayerCacheEnabled() {
+		return func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "check-image-cache-")
+	if err != nil {
+		return func() {}, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := imageutil.SetLayerCache(dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return func() {}, err
+	}
+	return func() {
+		imageutil.ResetLayerCache()
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithField("error", err).Warn("Failed to remove temporary cache directory")
+		}
+	}, nil
+}
+
+// promoteImage copies the validated source to the destination and, with
+// --attest, attaches the validation report to the pushed image.
+func promoteImage(ctx context.Context, src *imageutil.PinnedSource, result *output.PromoteResult) error {
+	desc, err := imageutil.CopyPinned(ctx, src, result.Destination)
+	if err != nil {
+		return fmt.Errorf("failed to copy image: %w", err)
+	}
+	result.Promoted = true
+	result.Digest = desc.Digest.String()
+
+	log.WithFields(log.Fields{
+		"source":      logutil.SanitizeLogValue(result.Source),
+		"destination": logutil.SanitizeLogValue(result.Destination),
+		"digest":      result.Digest,
+	}).Debug("Promoted image")
+
+	if !promoteAttest {
+		return nil
+	}
+
+	data, err := json.Marshal(result.Validation)
+	if err != nil {
+		return fmt.Errorf("failed to encode validation report: %w", err)
+	}
+	attestation, err := imageutil.AttachArtifact(ctx, result.Destination, *desc, reportArtifactType, reportMediaType, data, map[string]string{
+		annotationPassed:  strconv.FormatBool(result.Validation.Passed),
+		annotationCreated: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach validation report: %w", err)
+	}
+	result.Attestation = attestation.String()
+	return nil
+}
+
+func printPromoteResult(result output.PromoteResult) {
+	fmt.Println()
+	if !result.Promoted {
+		fmt.Printf("%sNot promoted: %s did not pass validation\n", statusPrefix(false), result.Source)
+		return
+	}
+	fmt.Printf("%sPromoted %s to %s@%s\n", statusPrefix(true), result.Source, result.Destination, result.Digest)
+	if result.Attestation != "" {
+		fmt.Printf("  Validation report attached as %s\n", result.Attestation)
+	}
+}
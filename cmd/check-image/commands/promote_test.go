@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestRunPromote(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         string
+		attest       bool
+		wantPromoted bool
+		wantResult   ValidationResult
+	}{
+		{
+			name:         "Passing image is promoted",
+			user:         "1000",
+			wantPromoted: true,
+			wantResult:   ValidationSucceeded,
+		},
+		{
+			name:         "Passing image is promoted with attestation",
+			user:         "1000",
+			attest:       true,
+			wantPromoted: true,
+			wantResult:   ValidationSucceeded,
+		},
+		{
+			name:         "Failing image is not promoted",
+			user:         "root",
+			wantPromoted: false,
+			wantResult:   ValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			includeChecks = "age,user"
+			promoteAttest = tt.attest
+			OutputFmt = output.FormatJSON
+
+			src := createTestImage(t, testImageOptions{
+				user:    tt.user,
+				created: time.Now().Add(-24 * time.Hour),
+			})
+			dst := newTestRegistry(t) + "/org/app:1.0"
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runPromote(promoteCmd, src, dst))
+			})
+
+			var result output.PromoteResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			assert.Equal(t, tt.wantPromoted, result.Promoted)
+			assert.Equal(t, !tt.wantPromoted, !result.Validation.Passed)
+			assert.Equal(t, tt.wantResult, Result)
+
+			dstRef, err := name.ParseReference(dst)
+			require.NoError(t, err)
+			desc, err := remote.Head(dstRef)
+			if !tt.wantPromoted {
+				assert.Error(t, err, "destination must not exist when validation fails")
+				assert.Empty(t, result.Digest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, desc.Digest.String(), result.Digest)
+
+			if !tt.attest {
+				assert.Empty(t, result.Attestation)
+				return
+			}
+			idx, err := remote.Referrers(dstRef.Context().Digest(result.Digest))
+			require.NoError(t, err)
+			manifest, err := idx.IndexManifest()
+			require.NoError(t, err)
+			require.Len(t, manifest.Manifests, 1)
+			assert.Equal(t, result.Attestation, manifest.Manifests[0].Digest.String())
+			assert.Equal(t, reportArtifactType, manifest.Manifests[0].ArtifactType)
+		})
+	}
+}
+
+func TestRunPromote_TextOutput(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+
+	src := createTestImage(t, testImageOptions{user: "1000"})
+	dst := newTestRegistry(t) + "/org/app:1.0"
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPromote(promoteCmd, src, dst))
+	})
+
+	assert.Contains(t, out, "Running 1 checks")
+	assert.Contains(t, out, "Promoted "+src+" to "+dst+"@sha256:")
+	assert.False(t, imageutil.LayerCacheEnabled(), "temporary layer cache must be removed after the run")
+}
+
+func TestRunPromote_NoChecks(t *testing.T) {
+	resetAllGlobals(t)
+	configFile = writeRequiredConfig(t, "checks: {}\n")
+
+	src := createTestImage(t, testImageOptions{})
+	dst := newTestRegistry(t) + "/org/app:1.0"
+
+	err := runPromote(promoteCmd, src, dst)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to promote")
+}
+
+func TestRunPromote_MultiPlatformIndex(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+
+	host := newTestRegistry(t)
+	idx, err := random.Index(256, 1, 2)
+	require.NoError(t, err)
+	src, err := name.ParseReference(host + "/src/multi:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(src, idx))
+
+	err = runPromote(promoteCmd, src.String(), host+"/prod/multi:1.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multi-platform index of 2 platforms")
+
+	dst, err := name.ParseReference(host + "/prod/multi:1.0")
+	require.NoError(t, err)
+	_, err = remote.Head(dst)
+	assert.Error(t, err, "nothing is pushed")
+}
+
+func TestRunPromote_ValidatesPinnedDigest(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+
+	src := createTestImage(t, testImageOptions{user: "1000"})
+	dst := newTestRegistry(t) + "/org/app:1.0"
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runPromote(promoteCmd, src, dst))
+	})
+	var result output.PromoteResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, src, result.Source)
+	assert.Contains(t, result.Validation.Image, "@sha256:", "the layout tag is validated by digest")
+	assert.True(t, strings.HasSuffix(result.Validation.Image, "@"+result.Digest), "the validated digest is the promoted one")
+}
+
+func TestRunPromote_InvalidDestination(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+
+	src := createTestImage(t, testImageOptions{user: "1000"})
+
+	err := runPromote(promoteCmd, src, "oci:/tmp/layout:latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination must be a registry reference")
+}
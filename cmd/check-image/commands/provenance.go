@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/provenance"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var provenancePolicy string
+
+var provenanceCmd = &cobra.Command{
+	Use:   "provenance image",
+	Short: "Validate the SLSA provenance attestations of the image",
+	Long: `Validate the SLSA provenance attestations of the image.
+
+Provenance is read from in-toto attestations stored in the image index (BuildKit),
+attached as OCI referrers, or pushed by cosign under the sha256-<digest>.att tag.
+SLSA provenance v0.2 and v1 are supported. The check fails when the image has no
+provenance, when a provenance statement is not a DSSE envelope signed by one of
+the trusted-keys of the --provenance-policy, or when it names a builder, source
+repository, or build type that the policy does not allow. Unsigned provenance,
+such as the provenance BuildKit stores in the image index, is only accepted
+with allow-unsigned: true in the policy.
+
+Registry references and OCI layouts are supported; other transports do not keep
+attestations.`,
+	Example: `  check-image provenance ghcr.io/org/app:1.0
+  check-image provenance ghcr.io/org/app:1.0 --provenance-policy provenance-policy.yaml
+  check-image provenance oci:/path/to/layout:1.0 --provenance-policy provenance-policy.json -o json
+  cat provenance-policy.yaml | check-image provenance ghcr.io/org/app:1.0 --provenance-policy -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		return runCheckCmd(checkProvenance, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runProvenance(ctx, img, provenancePolicy)
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(provenanceCmd)
+	provenanceCmd.Flags().StringVar(&provenancePolicy, "provenance-policy", "", "Provenance policy file (JSON or YAML) (optional)")
+}
+
+func runProvenance(ctx context.Context, imageName string, policyPath string) (*output.CheckResult, error) {
+	var policy *provenance.Policy
+	if policyPath != "" {
+		p, err := provenance.LoadPolicy(policyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load provenance policy: %w", err)
+		}
+		policy = p
+	}
+
+	atts, err := imageutil.GetAttestations(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []*provenance.Provenance
+	found := make(map[*provenance.Provenance]imageutil.Attestation)
+	for _, a := range atts.Attestations {
+		p, err := provenance.Parse(a.Data)
+		if errors.Is(err, provenance.ErrNotProvenance) {
+			continue
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"manifest": a.Manifest, "error": err}).Warn("Skipping unreadable attestation")
+			continue
+		}
+		statements = append(statements, p)
+		found[p] = a
+	}
+
+	applicable, result := provenance.Validate(statements, atts.Digests, policy)
+	log.Debugf("Attestations: %d, provenance statements: %d, applicable: %d, violations: %d",
+		len(atts.Attestations), len(statements), len(applicable), len(result.Violations))
+
+	details := output.ProvenanceDetails{Provenance: []output.ProvenanceStatement{}}
+	for _, p := range applicable {
+		a := found[p]
+		details.Provenance = append(details.Provenance, output.ProvenanceStatement{
+			Source:           a.Source,
+			Manifest:         a.Manifest,
+			PredicateType:    p.PredicateType,
+			BuilderID:        p.BuilderID,
+			BuildType:        p.BuildType,
+			SourceRepository: p.SourceRepository,
+			Signed:           p.Signed,
+			Verified:         policy != nil && policy.Trusts(p),
+		})
+	}
+	for _, v := range result.Violations {
+		details.Violations = append(details.Violations, output.ProvenanceViolation{
+			Rule:    v.Rule,
+			Message: v.Message,
+		})
+	}
+	if policy != nil {
+		details.TrustedBuilders = policy.TrustedBuilders
+		details.SourceRepositories = policy.SourceRepositories
+		details.BuildTypes = policy.BuildTypes
+		details.TrustedKeys = policy.TrustedKeys
+		details.AllowUnsigned = policy.AllowUnsigned
+	}
+
+	var msg string
+	switch {
+	case result.Passed:
+		msg = "Image provenance meets all requirements"
+	case len(applicable) == 0:
+		msg = "Image has no SLSA provenance"
+	default:
+		msg = "Image provenance does not meet requirements"
+	}
+
+	return &output.CheckResult{
+		Check:   checkProvenance,
+		Image:   imageName,
+		Passed:  result.Passed,
+		Message: msg,
+		Details: details,
+	}, nil
+}
@@ -0,0 +1,270 @@
+package commands
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProvenanceStatement = `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+      "externalParameters": {"workflow": {"repository": "https://github.com/org/app"}}
+    },
+    "runDetails": {"builder": {"id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"}}
+  }
+}`
+
+// createProvenanceImage writes an OCI layout holding an index with one image
+// and a BuildKit attestation manifest carrying statement.
+func createProvenanceImage(t *testing.T, statement string) string {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	att, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte(statement), "application/vnd.in-toto+json"))
+	require.NoError(t, err)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: img},
+		mutate.IndexAddendum{Add: att, Descriptor: cr.Descriptor{
+			Annotations: map[string]string{"vnd.docker.reference.type": "attestation-manifest"},
+		}},
+	)
+
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "latest",
+	})))
+	return "oci:" + dir + ":latest"
+}
+
+func writeProvenancePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "provenance-policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestProvenanceCommand(t *testing.T) {
+	assert.Equal(t, "provenance image", provenanceCmd.Use)
+	assert.Error(t, provenanceCmd.Args(provenanceCmd, []string{}))
+	assert.NoError(t, provenanceCmd.Args(provenanceCmd, []string{"image"}))
+
+	flag := provenanceCmd.Flags().Lookup("provenance-policy")
+	require.NotNil(t, flag)
+	assert.Equal(t, "", flag.DefValue)
+}
+
+func TestRunProvenance(t *testing.T) {
+	image := createProvenanceImage(t, testProvenanceStatement)
+
+	tests := []struct {
+		name        string
+		image       string
+		policy      string
+		wantPass    bool
+		wantMsg     string
+		wantRules   []string
+		wantEntries int
+	}{
+		{
+			name:        "Unsigned provenance without policy",
+			image:       image,
+			wantMsg:     "Image provenance does not meet requirements",
+			wantRules:   []string{"signature"},
+			wantEntries: 1,
+		},
+		{
+			name:        "Unsigned provenance allowed",
+			image:       image,
+			policy:      "allow-unsigned: true\n",
+			wantPass:    true,
+			wantMsg:     "Image provenance meets all requirements",
+			wantEntries: 1,
+		},
+		{
+			name:        "Trusted builder and source",
+			image:       image,
+			policy:      "allow-unsigned: true\ntrusted-builders:\n  - https://github.com/slsa-framework/slsa-github-generator/*\nsource-repositories:\n  - https://github.com/org/app\n",
+			wantPass:    true,
+			wantMsg:     "Image provenance meets all requirements",
+			wantEntries: 1,
+		},
+		{
+			name:        "Untrusted builder",
+			image:       image,
+			policy:      "trusted-builders:\n  - https://github.com/docker/buildx*\n",
+			wantMsg:     "Image provenance does not meet requirements",
+			wantRules:   []string{"signature", "builder"},
+			wantEntries: 1,
+		},
+		{
+			name:      "Missing provenance",
+			image:     createTestImage(t, testImageOptions{user: "1000"}),
+			wantMsg:   "Image has no SLSA provenance",
+			wantRules: []string{"missing"},
+		},
+		{
+			name:      "Only non-provenance attestations",
+			image:     createProvenanceImage(t, `{"predicateType": "https://spdx.dev/Document", "predicate": {}}`),
+			wantMsg:   "Image has no SLSA provenance",
+			wantRules: []string{"missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyPath := ""
+			if tt.policy != "" {
+				policyPath = writeProvenancePolicy(t, tt.policy)
+			}
+			result, err := runProvenance(context.Background(), tt.image, policyPath)
+			require.NoError(t, err)
+			assert.Equal(t, checkProvenance, result.Check)
+			assert.Equal(t, tt.wantPass, result.Passed)
+			assert.Equal(t, tt.wantMsg, result.Message)
+
+			details, ok := result.Details.(output.ProvenanceDetails)
+			require.True(t, ok)
+			assert.Len(t, details.Provenance, tt.wantEntries)
+			rules := make([]string, 0, len(details.Violations))
+			for _, v := range details.Violations {
+				rules = append(rules, v.Rule)
+			}
+			assert.ElementsMatch(t, tt.wantRules, rules)
+		})
+	}
+}
+
+func TestRunProvenance_Details(t *testing.T) {
+	policyPath := writeProvenancePolicy(t, "build-types:\n  - https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1\n")
+	result, err := runProvenance(context.Background(), createProvenanceImage(t, testProvenanceStatement), policyPath)
+	require.NoError(t, err)
+
+	details := result.Details.(output.ProvenanceDetails)
+	require.Len(t, details.Provenance, 1)
+	p := details.Provenance[0]
+	assert.Equal(t, "index", p.Source)
+	assert.Equal(t, "https://slsa.dev/provenance/v1", p.PredicateType)
+	assert.Equal(t, "https://github.com/org/app", p.SourceRepository)
+	assert.Equal(t, []string{"https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"}, details.BuildTypes)
+	assert.False(t, p.Signed)
+	assert.False(t, p.Verified)
+}
+
+func TestRunProvenance_SignedStatement(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	payload := []byte(testProvenanceStatement)
+	digest := sha256.Sum256(fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(provenance.InTotoPayloadType), provenance.InTotoPayloadType, len(payload), payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	envelope := fmt.Sprintf(`{"payloadType": %q, "payload": %q, "signatures": [{"sig": %q}]}`,
+		provenance.InTotoPayloadType, base64.StdEncoding.EncodeToString(payload), base64.StdEncoding.EncodeToString(sig))
+	image := createProvenanceImage(t, envelope)
+
+	result, err := runProvenance(context.Background(), image, writeProvenancePolicy(t, "trusted-keys:\n  - "+keyPath+"\n"))
+	require.NoError(t, err)
+	assert.True(t, result.Passed, "%+v", result.Details)
+	details := result.Details.(output.ProvenanceDetails)
+	require.Len(t, details.Provenance, 1)
+	assert.True(t, details.Provenance[0].Signed)
+	assert.True(t, details.Provenance[0].Verified)
+	assert.Equal(t, []string{keyPath}, details.TrustedKeys)
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(other.Public())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	result, err = runProvenance(context.Background(), image, writeProvenancePolicy(t, "trusted-keys:\n  - "+keyPath+"\n"))
+	require.NoError(t, err)
+	assert.False(t, result.Passed, "signed by another key")
+	assert.False(t, result.Details.(output.ProvenanceDetails).Provenance[0].Verified)
+}
+
+func TestRunProvenance_Errors(t *testing.T) {
+	_, err := runProvenance(context.Background(), createTestImage(t, testImageOptions{}), "/nonexistent/policy.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load provenance policy")
+
+	_, err = runProvenance(context.Background(), "oci-archive:/tmp/app.tar:latest", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "attestations are not available")
+}
+
+func TestRunAll_ProvenanceOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	image := createProvenanceImage(t, testProvenanceStatement)
+
+	include := map[string]bool{checkProvenance: true}
+	checks := determineChecks(nil, map[string]bool{}, nil, checkParams{})
+	for _, c := range checks {
+		assert.NotEqual(t, checkProvenance, c.name, "provenance must be opt-in without a policy")
+	}
+	checks = determineChecks(nil, nil, include, checkParams{})
+	require.Len(t, checks, 1)
+	result, err := checks[0].run(context.Background(), image)
+	require.NoError(t, err)
+	assert.False(t, result.Passed, "unsigned provenance fails without a policy that allows it")
+
+	checks = determineChecks(nil, map[string]bool{}, nil, checkParams{provenancePolicy: writeProvenancePolicy(t, "trusted-builders:\n  - https://example.com/*\n")})
+	var found bool
+	for _, c := range checks {
+		if c.name == checkProvenance {
+			found = true
+			result, err := c.run(context.Background(), image)
+			require.NoError(t, err)
+			assert.False(t, result.Passed)
+		}
+	}
+	assert.True(t, found)
+
+	cfg := &allConfig{Checks: allChecksConfig{Provenance: &provenanceCheckConfig{}}}
+	checks = determineChecks(cfg, map[string]bool{}, nil, checkParams{})
+	require.Len(t, checks, 1)
+	assert.Equal(t, checkProvenance, checks[0].name)
+}
+
+func TestRenderProvenanceText(t *testing.T) {
+	result, err := runProvenance(context.Background(), createProvenanceImage(t, testProvenanceStatement),
+		writeProvenancePolicy(t, "source-repositories:\n  - https://github.com/other/*\n"))
+	require.NoError(t, err)
+
+	out := captureStdout(t, func() { renderProvenanceText(stdout, result) })
+	assert.Contains(t, out, "Checking provenance of image")
+	assert.Contains(t, out, "Builder: https://github.com/slsa-framework/slsa-github-generator/")
+	assert.Contains(t, out, "Source repository: https://github.com/org/app")
+	assert.Contains(t, out, "Signature: unsigned")
+	assert.Contains(t, out, `source repository "https://github.com/org/app" is not allowed`)
+	assert.Contains(t, out, "Image provenance does not meet requirements")
+}
@@ -30,22 +30,13 @@ Note: Registry validation is only applicable for registry images and will be ski
 		if err != nil {
 			return fmt.Errorf("check registry operation failed: %w", err)
 		}
+		setDocsURL(result)
 
 		if err := renderResult(result, OutputFmt); err != nil {
 			return err
 		}
 
-		// Check if skipped (non-registry transport)
-		if d, ok := result.Details.(output.RegistryDetails); ok && d.Skipped {
-			return nil
-		}
-
-		if result.Passed {
-			UpdateResult(ValidationSucceeded)
-		} else {
-			UpdateResult(ValidationFailed)
-		}
-
+		updateCheckResult(result)
 		return nil
 	},
 }
@@ -67,11 +58,13 @@ func runRegistry(_ context.Context, imageName string, policyPath string) (*outpu
 	}
 	if ref.Transport != imageutil.TransportDaemonRegistry {
 		return &output.CheckResult{
-			Check:   checkRegistry,
-			Image:   imageName,
-			Passed:  true,
-			Message: "Registry validation skipped (not applicable for this transport)",
-			Details: output.RegistryDetails{Skipped: true},
+			Check:      checkRegistry,
+			Image:      imageName,
+			Passed:     true,
+			Message:    "Registry validation skipped (not applicable for this transport)",
+			Details:    output.RegistryDetails{Skipped: true},
+			Skipped:    true,
+			SkipReason: output.SkipReasonNotApplicable,
 		}, nil
 	}
 
@@ -188,6 +188,9 @@ func TestRunRegistry_OCITransportSkipped(t *testing.T) {
 	details, ok := result.Details.(output.RegistryDetails)
 	require.True(t, ok)
 	assert.True(t, details.Skipped, "Should skip validation for OCI transport")
+	assert.True(t, result.Passed)
+	assert.True(t, result.Skipped)
+	assert.Equal(t, output.SkipReasonNotApplicable, result.SkipReason)
 }
 
 func TestRunRegistry_OCIArchiveTransportSkipped(t *testing.T) {
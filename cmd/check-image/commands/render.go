@@ -2,12 +2,22 @@ package commands
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"slices"
 	"sort"
+	"strings"
 
+	"github.com/jarfernandez/check-image/internal/drift"
+	"github.com/jarfernandez/check-image/internal/minimal"
 	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
 )
 
+// stdout serializes the writes of the commands to os.Stdout. Output that
+// spans several writes and must stay together, such as the section of a
+// check, is buffered in an output.Section and flushed at once.
+var stdout = output.NewSink(output.Stdout)
+
 // mustDetails extracts typed details from r.Details.
 // A mismatch between Check and Details is always a programming error, never a
 // user input error, so it panics with a clear message instead of returning an
@@ -23,123 +33,213 @@ func mustDetails[T any](r *output.CheckResult) T {
 }
 
 // textRenderers maps each check name to its text rendering function.
-var textRenderers = map[string]func(*output.CheckResult){
-	checkAge:         renderAgeText,
-	checkSize:        renderSizeText,
-	checkPorts:       renderPortsText,
-	checkRegistry:    renderRegistryText,
-	checkSecrets:     renderSecretsText,
-	checkHealthcheck: renderHealthcheckText,
-	checkLabels:      renderLabelsText,
-	checkEntrypoint:  renderEntrypointText,
-	checkPlatform:    renderPlatformText,
-	checkUser:        renderUserText,
+var textRenderers = map[string]func(io.Writer, *output.CheckResult){
+	checkAge:          renderAgeText,
+	checkSize:         renderSizeText,
+	checkPorts:        renderPortsText,
+	checkRegistry:     renderRegistryText,
+	checkSecrets:      renderSecretsText,
+	checkHealthcheck:  renderHealthcheckText,
+	checkLabels:       renderLabelsText,
+	checkEntrypoint:   renderEntrypointText,
+	checkPlatform:     renderPlatformText,
+	checkUser:         renderUserText,
+	checkProvenance:   renderProvenanceText,
+	checkLazyPull:     renderLazyPullText,
+	checkDrift:        renderDriftText,
+	checkEntropy:      renderEntropyText,
+	checkDeprecation:  renderDeprecationText,
+	checkArchitecture: renderArchitectureText,
+	checkMinimal:      renderMinimalText,
+	checkSmoke:        renderSmokeText,
+}
+
+// csvCommands lists the commands besides the checks that support --output csv.
+var csvCommands = []string{"all", "audit"}
+
+// supportsCSV reports whether cmd produces check results, the only output
+// that --output csv can represent.
+func supportsCSV(cmd *cobra.Command) bool {
+	return slices.Contains(validCheckNames, cmd.Name()) || slices.Contains(csvCommands, cmd.Name())
 }
 
 // renderResult renders a CheckResult according to the given output format.
 // In text mode, it calls the appropriate text renderer.
-// In JSON mode, it writes JSON to stdout.
+// In JSON mode, it writes JSON to stdout, and in CSV mode one row per finding.
+// The output is written to stdout in a single write.
 func renderResult(r *output.CheckResult, outFmt output.Format) error {
-	if outFmt == output.FormatJSON {
-		return output.RenderJSON(os.Stdout, r)
+	section := stdout.Section()
+	if err := writeResult(section, r, outFmt); err != nil {
+		return err
+	}
+	return section.Flush()
+}
+
+// writeResult writes a CheckResult to w according to the given output format.
+func writeResult(w io.Writer, r *output.CheckResult, outFmt output.Format) error {
+	switch outFmt {
+	case output.FormatJSON:
+		return output.RenderJSON(w, r)
+	case output.FormatCSV:
+		return output.RenderCSV(w, output.CheckFindings(*r))
 	}
 
-	// Error results have no Details; guard here to prevent a nil type assertion
-	// panic in the check-specific renderers below.
+	// Error results and checks skipped before they ran have no Details; guard
+	// here to prevent a nil type assertion panic in the check-specific
+	// renderers below.
 	if r.Error != "" {
-		fmt.Println(FailStyle.Render(r.Message))
+		fmt.Fprintln(w, FailStyle.Render(r.Message))
+		return nil
+	}
+	if r.Skipped && r.Details == nil {
+		fmt.Fprintln(w, dimStyle.Render(r.Message))
 		return nil
 	}
 
 	if fn, ok := textRenderers[r.Check]; ok {
-		fn(r)
+		fn(w, r)
 	} else {
-		fmt.Printf("(no text renderer for check %q)\n", r.Check)
+		fmt.Fprintf(w, "(no text renderer for check %q)\n", r.Check)
 	}
+	printRemediation(w, r)
+	printDocsLink(w, r)
 
 	return nil
 }
 
-func renderAgeText(r *output.CheckResult) {
+func renderAgeText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.AgeDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking age of image %s", r.Image)))
-	fmt.Printf("Image creation date: %s\n", valueStyle.Render(d.CreatedAt))
-	fmt.Printf("Image age: %s\n", valueStyle.Render(fmt.Sprintf("%.0f days", d.AgeDays)))
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking age of image %s", r.Image)))
+	fmt.Fprintf(w, "Image creation date: %s\n", valueStyle.Render(d.CreatedAt))
+	fmt.Fprintf(w, "Image age: %s\n", valueStyle.Render(fmt.Sprintf("%.0f days", d.AgeDays)))
+	if d.NotBefore != "" {
+		fmt.Fprintf(w, "Not before: %s\n", valueStyle.Render(d.NotBefore))
+	}
+	if d.NotAfter != "" {
+		fmt.Fprintf(w, "Not after: %s\n", valueStyle.Render(d.NotAfter))
+	}
+	if d.Rule != "" {
+		fmt.Fprintf(w, "Age rule: %s\n", valueStyle.Render(fmt.Sprintf("%s (max %d days)", d.Rule, d.MaxAge)))
+	}
+	renderPolicyWindow(w, d.PolicyWindow)
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderSizeText(r *output.CheckResult) {
+func renderSizeText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.SizeDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking size and layers of image %s", r.Image)))
-	fmt.Printf("Number of layers: %s\n", valueStyle.Render(fmt.Sprintf("%d", d.LayerCount)))
-	// #nosec G115 -- LayerCount is always non-negative (derived from layer enumeration)
-	if uint(d.LayerCount) > d.MaxLayers {
-		fmt.Printf("Image has more than %s layers\n", valueStyle.Render(fmt.Sprintf("%d", d.MaxLayers)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking size and layers of image %s", r.Image)))
+	fmt.Fprintf(w, "Number of layers: %s\n", valueStyle.Render(fmt.Sprintf("%d", d.LayerCount)))
+	counted := d.LayerCount
+	if d.BaseLayerCount != nil {
+		fmt.Fprintf(w, "Base image layers (not counted): %s\n", valueStyle.Render(fmt.Sprintf("%d", *d.BaseLayerCount)))
+		counted -= *d.BaseLayerCount
+	}
+	// #nosec G115 -- counted is always non-negative (derived from layer enumeration)
+	if uint(counted) > d.MaxLayers {
+		fmt.Fprintf(w, "Image has more than %s layers\n", valueStyle.Render(fmt.Sprintf("%d", d.MaxLayers)))
 	}
 	for _, l := range d.Layers {
-		fmt.Printf("  Layer %d: %s\n", l.Index, dimStyle.Render(fmt.Sprintf("%d bytes", l.Bytes)))
+		layerSize := fmt.Sprintf("%d bytes", l.Bytes)
+		if output.Units(sizeUnits) != output.UnitsMB {
+			layerSize += " (" + formatSize(l.Bytes) + ")"
+		}
+		fmt.Fprintf(w, "  Layer %d: %s\n", l.Index, dimStyle.Render(layerSize))
+		if l.CreatedBy != "" {
+			fmt.Fprintf(w, "    %s\n", dimStyle.Render("Created by: "+l.CreatedBy))
+		}
+		if hint := layerInspectHint(r.Image, l.Digest); hint != "" {
+			fmt.Fprintf(w, "    %s\n", dimStyle.Render("Inspect: "+hint))
+		}
+	}
+	fmt.Fprintf(w, "Total size: %s\n", valueStyle.Render(fmt.Sprintf("%d bytes (%s)", d.TotalBytes, formatSizeDetail(d.TotalBytes, d.TotalMB))))
+	if d.MaxTotalSizeMB > 0 {
+		fmt.Fprintf(w, "Total size of all platforms: %s\n", valueStyle.Render(fmt.Sprintf("%d bytes (%s) across %d platforms", d.IndexTotalBytes, formatSizeDetail(d.IndexTotalBytes, d.IndexTotalMB), d.Platforms)))
 	}
-	fmt.Printf("Total size: %s\n", valueStyle.Render(fmt.Sprintf("%d bytes (%.2f MB)", d.TotalBytes, d.TotalMB)))
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	renderPolicyWindow(w, d.PolicyWindow)
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderPortsText(r *output.CheckResult) {
+// renderPolicyWindow prints the policy window that set the limits of a check,
+// if any.
+func renderPolicyWindow(w io.Writer, pw *output.PolicyWindow) {
+	if pw == nil {
+		return
+	}
+	var bounds []string
+	if pw.From != "" {
+		bounds = append(bounds, "from "+pw.From)
+	}
+	if pw.Until != "" {
+		bounds = append(bounds, "until "+pw.Until)
+	}
+	reason := pw.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	fmt.Fprintf(w, "Policy window: %s %s\n", valueStyle.Render(reason), dimStyle.Render("("+strings.Join(bounds, " ")+")"))
+}
+
+func renderPortsText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.PortsDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking ports of image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking ports of image %s", r.Image)))
 
 	if len(d.ExposedPorts) == 0 {
-		fmt.Println(statusPrefix(r.Passed) + "No ports are exposed in this image")
+		fmt.Fprintln(w, statusPrefix(r.Passed)+"No ports are exposed in this image")
 		return
 	}
 
-	fmt.Println("Exposed ports:")
+	fmt.Fprintln(w, "Exposed ports:")
 	for _, port := range d.ExposedPorts {
-		fmt.Printf("  - %s\n", valueStyle.Render(fmt.Sprintf("%d", port)))
+		fmt.Fprintf(w, "  - %s\n", valueStyle.Render(fmt.Sprintf("%d", port)))
 	}
 
-	if len(d.AllowedPorts) == 0 {
-		fmt.Println("No allowed ports were provided")
+	if len(d.AllowedPorts) == 0 && len(d.FailedConstraints) == 0 && !r.Passed {
+		fmt.Fprintln(w, msgNoAllowedPorts)
 		return
 	}
 
 	if len(d.UnauthorizedPorts) > 0 {
-		fmt.Println("The following ports are not in the allowed list:")
+		fmt.Fprintln(w, "The following ports are not in the allowed list:")
 		for _, port := range d.UnauthorizedPorts {
-			fmt.Printf("  - %s\n", FailStyle.Render(fmt.Sprintf("%d", port)))
+			fmt.Fprintf(w, "  - %s\n", FailStyle.Render(fmt.Sprintf("%d", port)))
 		}
 	}
 
+	if d.MaxExposedPorts > 0 {
+		fmt.Fprintf(w, "Maximum exposed ports: %s\n", valueStyle.Render(fmt.Sprintf("%d", d.MaxExposedPorts)))
+	}
+
 	if r.Message != "" {
-		fmt.Println(statusPrefix(r.Passed) + r.Message)
+		fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 	}
 }
 
-func renderRegistryText(r *output.CheckResult) {
+func renderRegistryText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.RegistryDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking registry of image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking registry of image %s", r.Image)))
 
 	if d.Skipped {
-		fmt.Println(dimStyle.Render("Registry validation skipped (not applicable for this transport)"))
+		fmt.Fprintln(w, dimStyle.Render("Registry validation skipped (not applicable for this transport)"))
 		return
 	}
 
-	fmt.Printf("Image registry: %s\n", valueStyle.Render(d.Registry))
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	fmt.Fprintf(w, "Image registry: %s\n", valueStyle.Render(d.Registry))
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderSecretsText(r *output.CheckResult) {
+func renderSecretsText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.SecretsDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking secrets in image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking secrets in image %s", r.Image)))
 
 	if len(d.EnvVarFindings) > 0 {
-		fmt.Printf("\nEnvironment variables:\n")
+		fmt.Fprintf(w, "\nEnvironment variables:\n")
 		for _, finding := range d.EnvVarFindings {
-			fmt.Printf("  - %s (%s)\n", FailStyle.Render(finding.Name), finding.Description)
+			fmt.Fprintf(w, "  - %s (%s)\n", FailStyle.Render(finding.Name), secretFindingLabel(finding.Description, finding.Severity))
 		}
 	}
 
 	if len(d.FileFindings) > 0 {
-		fmt.Printf("\nFiles:\n")
+		fmt.Fprintf(w, "\nFiles:\n")
 
 		// Group findings by layer for better readability
 		layerMap := make(map[int][]output.FileFinding)
@@ -157,86 +257,116 @@ func renderSecretsText(r *output.CheckResult) {
 
 		for _, layerIdx := range layerIndices {
 			findings := layerMap[layerIdx]
-			fmt.Printf("  Layer %d:\n", layerIdx+1)
+			origin := ""
+			if base := findings[0].BaseLayer; base != nil {
+				if *base {
+					origin = " (base image)"
+				} else {
+					origin = " (not in base image)"
+				}
+			}
+			fmt.Fprintf(w, "  Layer %d%s:\n", layerIdx+1, origin)
+			if findings[0].CreatedBy != "" {
+				fmt.Fprintf(w, "    %s\n", dimStyle.Render("Created by: "+findings[0].CreatedBy))
+			}
+			if hint := layerInspectHint(r.Image, findings[0].LayerDigest); hint != "" {
+				fmt.Fprintf(w, "    %s\n", dimStyle.Render("Inspect: "+hint))
+			}
 			for _, finding := range findings {
-				fmt.Printf("    - %s (%s)\n", FailStyle.Render(finding.Path), finding.Description)
+				fmt.Fprintf(w, "    - %s (%s)\n", FailStyle.Render(finding.Path), secretFindingLabel(finding.Description, finding.Severity))
 			}
 		}
 	}
 
-	fmt.Printf("\nTotal findings: %s", valueStyle.Render(fmt.Sprintf("%d", d.TotalFindings)))
+	fmt.Fprintf(w, "\nTotal findings: %s", valueStyle.Render(fmt.Sprintf("%d", d.TotalFindings)))
 	if d.EnvVarCount >= 0 && d.FileCount >= 0 && (d.EnvVarCount > 0 || d.FileCount > 0 || d.TotalFindings > 0) {
-		fmt.Printf(" (%d environment variables, %d files)\n", d.EnvVarCount, d.FileCount)
+		fmt.Fprintf(w, " (%d environment variables, %d files)\n", d.EnvVarCount, d.FileCount)
 	} else {
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	if d.FailOnSeverity != "" {
+		fmt.Fprintf(w, "Fail on severity: %s\n", valueStyle.Render(d.FailOnSeverity))
+	}
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderHealthcheckText(r *output.CheckResult) {
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking if image %s has a healthcheck defined", r.Image)))
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+// secretFindingLabel returns the description of a secrets finding followed by
+// its severity, when it has one.
+func secretFindingLabel(description, severity string) string {
+	if severity == "" {
+		return description
+	}
+	return description + ", " + severity
 }
 
-func renderEntrypointText(r *output.CheckResult) {
+func renderHealthcheckText(w io.Writer, r *output.CheckResult) {
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking if image %s has a healthcheck defined", r.Image)))
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderEntrypointText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.EntrypointDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking entrypoint of image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking entrypoint of image %s", r.Image)))
 	if len(d.Entrypoint) > 0 {
-		fmt.Printf("Entrypoint: %s\n", valueStyle.Render(fmt.Sprintf("%v", d.Entrypoint)))
+		fmt.Fprintf(w, "Entrypoint: %s\n", valueStyle.Render(fmt.Sprintf("%v", d.Entrypoint)))
 	}
 	if len(d.Cmd) > 0 {
-		fmt.Printf("Cmd: %s\n", valueStyle.Render(fmt.Sprintf("%v", d.Cmd)))
+		fmt.Fprintf(w, "Cmd: %s\n", valueStyle.Render(fmt.Sprintf("%v", d.Cmd)))
+	}
+	for _, v := range d.Violations {
+		fmt.Fprintf(w, "  - %s\n", FailStyle.Render(v.Rule+": "+v.Message))
 	}
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderPlatformText(r *output.CheckResult) {
+func renderPlatformText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.PlatformDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking platform of image %s", r.Image)))
-	fmt.Printf("Image platform: %s\n", valueStyle.Render(d.Platform))
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking platform of image %s", r.Image)))
+	fmt.Fprintf(w, "Image platform: %s\n", valueStyle.Render(d.Platform))
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderUserText(r *output.CheckResult) {
+func renderUserText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.UserDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking user of image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking user of image %s", r.Image)))
 
 	if d.User == "" {
-		fmt.Println("User: " + dimStyle.Render("(not set)"))
+		fmt.Fprintln(w, "User: "+dimStyle.Render("(not set)"))
 	} else {
-		fmt.Printf("User: %s\n", valueStyle.Render(d.User))
+		fmt.Fprintf(w, "User: %s\n", valueStyle.Render(d.User))
 	}
 
 	for _, v := range d.Violations {
-		fmt.Printf("  - %s\n", FailStyle.Render(v.Message))
+		fmt.Fprintf(w, "  - %s\n", FailStyle.Render(v.Message))
 	}
 
-	fmt.Println(statusPrefix(r.Passed) + r.Message)
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
 }
 
-func renderLabelsText(r *output.CheckResult) {
+func renderLabelsText(w io.Writer, r *output.CheckResult) {
 	d := mustDetails[output.LabelsDetails](r)
-	fmt.Println(headerStyle.Render(fmt.Sprintf("Checking labels of image %s", r.Image)))
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking labels of image %s", r.Image)))
 
 	// Show required labels
 	if len(d.RequiredLabels) > 0 {
-		fmt.Printf("\nRequired labels:\n")
+		fmt.Fprintf(w, "\nRequired labels:\n")
 		for _, req := range d.RequiredLabels {
 			switch {
 			case req.Pattern != "":
-				fmt.Printf("  - %s (pattern: %q)\n", req.Name, req.Pattern)
+				fmt.Fprintf(w, "  - %s (pattern: %q)\n", req.Name, req.Pattern)
 			case req.Value != "":
-				fmt.Printf("  - %s (exact: %q)\n", req.Name, req.Value)
+				fmt.Fprintf(w, "  - %s (exact: %q)\n", req.Name, req.Value)
 			default:
-				fmt.Printf("  - %s (existence check)\n", req.Name)
+				fmt.Fprintf(w, "  - %s (existence check)\n", req.Name)
 			}
 		}
 	}
 
 	// Show actual labels from image
 	if len(d.ActualLabels) > 0 {
-		fmt.Printf("\nActual labels:\n")
+		fmt.Fprintf(w, "\nActual labels:\n")
 		// Sort keys for deterministic output
 		keys := make([]string, 0, len(d.ActualLabels))
 		for k := range d.ActualLabels {
@@ -244,27 +374,241 @@ func renderLabelsText(r *output.CheckResult) {
 		}
 		sort.Strings(keys)
 		for _, k := range keys {
-			fmt.Printf("  - %s: %s\n", k, d.ActualLabels[k])
+			fmt.Fprintf(w, "  - %s: %s\n", k, d.ActualLabels[k])
 		}
 	} else {
-		fmt.Println("\nNo labels found in image")
+		fmt.Fprintln(w, "\nNo labels found in image")
 	}
 
 	// Show missing labels
 	if len(d.MissingLabels) > 0 {
-		fmt.Printf("\nMissing labels:\n")
+		fmt.Fprintf(w, "\nMissing labels:\n")
 		for _, name := range d.MissingLabels {
-			fmt.Printf("  - %s\n", FailStyle.Render(name))
+			fmt.Fprintf(w, "  - %s\n", FailStyle.Render(name))
 		}
 	}
 
 	// Show invalid labels
 	if len(d.InvalidLabels) > 0 {
-		fmt.Printf("\nInvalid labels:\n")
+		fmt.Fprintf(w, "\nInvalid labels:\n")
 		for _, inv := range d.InvalidLabels {
-			fmt.Printf("  - %s: %s\n", FailStyle.Render(inv.Name), inv.Reason)
+			fmt.Fprintf(w, "  - %s: %s\n", FailStyle.Render(inv.Name), inv.Reason)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", statusPrefix(r.Passed)+r.Message)
+}
+
+func renderProvenanceText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.ProvenanceDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking provenance of image %s", r.Image)))
+
+	if len(d.Provenance) == 0 {
+		fmt.Fprintln(w, "Provenance: "+dimStyle.Render("(none)"))
+	}
+	for _, p := range d.Provenance {
+		fmt.Fprintf(w, "\nProvenance (%s, %s):\n", p.Source, p.PredicateType)
+		fmt.Fprintf(w, "  Builder: %s\n", valueStyle.Render(p.BuilderID))
+		fmt.Fprintf(w, "  Build type: %s\n", valueStyle.Render(p.BuildType))
+		if p.SourceRepository != "" {
+			fmt.Fprintf(w, "  Source repository: %s\n", valueStyle.Render(p.SourceRepository))
+		}
+		fmt.Fprintf(w, "  Signature: %s\n", valueStyle.Render(provenanceSignatureState(p)))
+	}
+
+	if len(d.Violations) > 0 {
+		fmt.Fprintf(w, "\nViolations:\n")
+		for _, v := range d.Violations {
+			fmt.Fprintf(w, "  - %s\n", FailStyle.Render(v.Message))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", statusPrefix(r.Passed)+r.Message)
+}
+
+// provenanceSignatureState describes the signature of a provenance
+// statement.
+func provenanceSignatureState(p output.ProvenanceStatement) string {
+	switch {
+	case p.Verified:
+		return "verified"
+	case p.Signed:
+		return "not verified"
+	default:
+		return "unsigned"
+	}
+}
+
+func renderLazyPullText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.LazyPullDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking lazy-pull support of image %s", r.Image)))
+
+	if d.Format == "" {
+		fmt.Fprintln(w, "Format: "+dimStyle.Render("(none)"))
+	} else {
+		fmt.Fprintf(w, "Format: %s\n", valueStyle.Render(d.Format))
+	}
+	fmt.Fprintf(w, "Accepted formats: %s\n", strings.Join(d.AcceptedFormats, ", "))
+	fmt.Fprintf(w, "Layers: %d (eStargz: %d, Nydus blobs: %d, Nydus bootstrap: %t)\n",
+		d.Layers, d.EStargzLayers, d.NydusBlobLayers, d.NydusBootstrap)
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderDriftText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.DriftDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking configuration drift of image %s", r.Image)))
+
+	if len(d.Differences) > 0 {
+		fmt.Fprintf(w, "Differences:\n")
+		for _, diff := range d.Differences {
+			field := diff.Field
+			if diff.Key != "" {
+				field += " " + diff.Key
+			}
+			var change string
+			switch diff.Kind {
+			case drift.Added:
+				change = "added"
+				if diff.Actual != "" {
+					change += ": " + diff.Actual
+				}
+			case drift.Removed:
+				change = "removed"
+				if diff.Expected != "" {
+					change += ", expected " + diff.Expected
+				}
+			default:
+				change = fmt.Sprintf("expected %s, got %s", valueOrNone(diff.Expected), valueOrNone(diff.Actual))
+			}
+			fmt.Fprintf(w, "  - %s %s\n", FailStyle.Render(field), change)
+		}
+	}
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
+
+func renderEntropyText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.EntropyDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking high-entropy files in image %s", r.Image)))
+
+	fmt.Fprintf(w, "Files of %s or more measured: %s\n", formatSizeLimit(d.MinSize), valueStyle.Render(fmt.Sprintf("%d", d.MeasuredFiles)))
+	fmt.Fprintf(w, "Max entropy: %s bits per byte\n", valueStyle.Render(fmt.Sprintf("%g", d.MaxEntropy)))
+
+	if len(d.Findings) > 0 {
+		fmt.Fprintf(w, "\nFiles:\n")
+		layer := -1
+		for _, f := range d.Findings {
+			if f.LayerIndex != layer {
+				layer = f.LayerIndex
+				fmt.Fprintf(w, "  Layer %d:\n", layer+1)
+				if hint := layerInspectHint(r.Image, f.LayerDigest); hint != "" {
+					fmt.Fprintf(w, "    %s\n", dimStyle.Render("Inspect: "+hint))
+				}
+			}
+			label := fmt.Sprintf("%s, %.3f bits per byte", formatSize(f.Size), f.Entropy)
+			if f.Format != "" {
+				label += ", " + f.Format
+			}
+			fmt.Fprintf(w, "    - %s (%s)\n", FailStyle.Render(f.Path), label)
+		}
+	}
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderDeprecationText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.DeprecationDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking if image %s is a deprecated official image", r.Image)))
+	if d.Notice != "" {
+		fmt.Fprintf(w, "Notice: %s\n", valueStyle.Render(d.Notice))
+	}
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderArchitectureText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.ArchitectureDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking binary architectures of image %s", r.Image)))
+
+	fmt.Fprintf(w, "Declared architecture: %s\n", valueStyle.Render(d.Architecture))
+	fmt.Fprintf(w, "Binaries inspected: %s\n", valueStyle.Render(fmt.Sprintf("%d", d.InspectedBinaries)))
+
+	if len(d.Mismatches) > 0 {
+		fmt.Fprintf(w, "\nBinaries of another architecture:\n")
+		layer := -1
+		for _, m := range d.Mismatches {
+			if m.LayerIndex != layer {
+				layer = m.LayerIndex
+				fmt.Fprintf(w, "  Layer %d:\n", layer+1)
+				if hint := layerInspectHint(r.Image, m.LayerDigest); hint != "" {
+					fmt.Fprintf(w, "    %s\n", dimStyle.Render("Inspect: "+hint))
+				}
+			}
+			fmt.Fprintf(w, "    - %s (%s)\n", FailStyle.Render(m.Path), m.Architecture)
+		}
+	}
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderMinimalText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.MinimalDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Checking that image %s is minimal", r.Image)))
+
+	found := map[string][]string{
+		minimal.CriterionShell:          d.Shells,
+		minimal.CriterionPackageManager: d.PackageManagers,
+		minimal.CriterionCompiler:       d.Compilers,
+	}
+	for _, c := range d.Criteria {
+		status := PassStyle.Render("pass")
+		if slices.Contains(d.FailedCriteria, c) {
+			status = FailStyle.Render("fail")
+		}
+		if c == minimal.CriterionSize {
+			fmt.Fprintf(w, "  %s: %s (%s of %s)\n", c, status, valueStyle.Render(formatSizeDetail(d.TotalBytes, d.TotalMB)), formatSizeLimit(d.MaxSizeMB))
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %s\n", c, status)
+		for _, p := range found[c] {
+			fmt.Fprintf(w, "    - %s\n", FailStyle.Render(p))
 		}
 	}
 
-	fmt.Printf("\n%s\n", statusPrefix(r.Passed)+r.Message)
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+func renderSmokeText(w io.Writer, r *output.CheckResult) {
+	d := mustDetails[output.SmokeDetails](r)
+	fmt.Fprintln(w, headerStyle.Render(fmt.Sprintf("Running smoke test of image %s", r.Image)))
+
+	if len(d.Command) > 0 {
+		fmt.Fprintf(w, "Command: %s\n", valueStyle.Render(strings.Join(d.Command, " ")))
+	}
+	if d.Pulled {
+		fmt.Fprintln(w, dimStyle.Render("Image pulled into the daemon"))
+	}
+	if len(d.Logs) > 0 {
+		fmt.Fprintln(w, "Container logs (last lines):")
+		for _, line := range d.Logs {
+			fmt.Fprintf(w, "  %s\n", dimStyle.Render(line))
+		}
+	}
+
+	fmt.Fprintln(w, statusPrefix(r.Passed)+r.Message)
+}
+
+// printRemediation prints the suggested fix of a failed check in text mode.
+func printRemediation(w io.Writer, r *output.CheckResult) {
+	if r.Passed || r.Remediation == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s %s\n", dimStyle.Render("Remediation:"), r.Remediation)
 }
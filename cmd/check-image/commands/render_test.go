@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jarfernandez/check-image/internal/output"
@@ -201,6 +202,25 @@ func TestRenderResult_JSONMode(t *testing.T) {
 	assert.Contains(t, captured, `"message": "Image is recent"`)
 }
 
+func TestRenderResult_CSVMode(t *testing.T) {
+	result := &output.CheckResult{
+		Check:   checkUser,
+		Image:   "nginx:latest",
+		Message: "Image user does not meet the policy",
+		Details: output.UserDetails{
+			User:       "root",
+			Violations: []output.UserViolation{{Rule: "non-root", Message: "user root is not allowed"}},
+		},
+	}
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, renderResult(result, output.FormatCSV))
+	})
+
+	assert.Equal(t, "image,check,rule,subject,message,severity\n"+
+		"nginx:latest,user,non-root,root,user root is not allowed,failure\n", captured)
+}
+
 func TestRenderAgeText_ValidImage(t *testing.T) {
 	result := &output.CheckResult{
 		Check:  checkAge,
@@ -214,7 +234,7 @@ func TestRenderAgeText_ValidImage(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderAgeText(result)
+		renderAgeText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking age of image nginx:latest")
@@ -236,7 +256,7 @@ func TestRenderAgeText_OldImage(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderAgeText(result)
+		renderAgeText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "old-app:v1")
@@ -263,7 +283,7 @@ func TestRenderSizeText_UnderLimits(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSizeText(result)
+		renderSizeText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "alpine:latest")
@@ -293,7 +313,7 @@ func TestRenderSizeText_ExceedsSizeLimit(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSizeText(result)
+		renderSizeText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "large-app:latest")
@@ -318,7 +338,7 @@ func TestRenderSizeText_ExceedsLayersLimit(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSizeText(result)
+		renderSizeText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "many-layers:latest")
@@ -341,7 +361,7 @@ func TestRenderPortsText_AllAllowed(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderPortsText(result)
+		renderPortsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking ports of image nginx:latest")
@@ -366,7 +386,7 @@ func TestRenderPortsText_SomeForbidden(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderPortsText(result)
+		renderPortsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Exposed ports:")
@@ -390,7 +410,7 @@ func TestRenderPortsText_NoPorts(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderPortsText(result)
+		renderPortsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking ports of image distroless:latest")
@@ -412,7 +432,7 @@ func TestRenderPortsText_NoAllowedPorts(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderPortsText(result)
+		renderPortsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Exposed ports:")
@@ -433,7 +453,7 @@ func TestRenderRegistryText_Trusted(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderRegistryText(result)
+		renderRegistryText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking registry of image docker.io/nginx:latest")
@@ -454,7 +474,7 @@ func TestRenderRegistryText_Untrusted(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderRegistryText(result)
+		renderRegistryText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "untrusted.io/app:latest")
@@ -475,7 +495,7 @@ func TestRenderRegistryText_Skipped(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderRegistryText(result)
+		renderRegistryText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking registry of image oci:/local/path:tag")
@@ -499,7 +519,7 @@ func TestRenderSecretsText_NoSecrets(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking secrets in image clean-app:latest")
@@ -528,7 +548,7 @@ func TestRenderSecretsText_EnvVarsOnly(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Environment variables:")
@@ -557,7 +577,7 @@ func TestRenderSecretsText_FilesOnly(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Files:")
@@ -569,6 +589,81 @@ func TestRenderSecretsText_FilesOnly(t *testing.T) {
 	assert.NotContains(t, captured, "Environment variables:")
 }
 
+func TestRenderSecretsText_Severities(t *testing.T) {
+	result := &output.CheckResult{
+		Check:  checkSecrets,
+		Image:  "app:latest",
+		Passed: true,
+		Details: output.SecretsDetails{
+			EnvVarFindings: []output.EnvVarFinding{{Name: "API_TOKEN", Description: "sensitive pattern detected", Severity: "medium"}},
+			FileFindings:   []output.FileFinding{{LayerIndex: 0, Path: "/app/secrets.json", Description: "secrets file", Severity: "medium"}},
+			TotalFindings:  2,
+			EnvVarCount:    1,
+			FileCount:      1,
+			FailOnSeverity: "high",
+		},
+		Message: "Secrets detected, none of high severity or higher",
+	}
+
+	captured := captureStdout(t, func() {
+		renderSecretsText(stdout, result)
+	})
+
+	assert.Contains(t, captured, "API_TOKEN (sensitive pattern detected, medium)")
+	assert.Contains(t, captured, "/app/secrets.json (secrets file, medium)")
+	assert.Contains(t, captured, "Fail on severity: high")
+}
+
+func TestRenderSecretsText_LayerAttribution(t *testing.T) {
+	base, added := true, false
+	result := &output.CheckResult{
+		Check:  checkSecrets,
+		Image:  "app:latest",
+		Passed: false,
+		Details: output.SecretsDetails{
+			FileFindings: []output.FileFinding{
+				{LayerIndex: 0, Path: "/etc/ssl/private/server.key", Description: "Private key", CreatedBy: "ADD rootfs.tar /", BaseLayer: &base},
+				{LayerIndex: 2, Path: "/root/.ssh/id_rsa", Description: "SSH private key", CreatedBy: "COPY id_rsa /root/.ssh/", BaseLayer: &added},
+			},
+			TotalFindings: 2,
+			FileCount:     2,
+		},
+		Message: "Secrets detected",
+	}
+
+	captured := captureStdout(t, func() {
+		renderSecretsText(stdout, result)
+	})
+
+	assert.Contains(t, captured, "Layer 1 (base image):")
+	assert.Contains(t, captured, "Created by: ADD rootfs.tar /")
+	assert.Contains(t, captured, "Layer 3 (not in base image):")
+	assert.Contains(t, captured, "Created by: COPY id_rsa /root/.ssh/")
+}
+
+func TestRenderSecretsText_LayerInspectHint(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	result := &output.CheckResult{
+		Check:  checkSecrets,
+		Image:  "ghcr.io/org/app:1.0",
+		Passed: false,
+		Details: output.SecretsDetails{
+			FileFindings: []output.FileFinding{
+				{LayerIndex: 0, Path: "/root/.ssh/id_rsa", Description: "SSH private key", LayerDigest: digest},
+			},
+			TotalFindings: 1,
+			FileCount:     1,
+		},
+		Message: "Secrets detected",
+	}
+
+	captured := captureStdout(t, func() {
+		renderSecretsText(stdout, result)
+	})
+
+	assert.Contains(t, captured, "Inspect: crane blob ghcr.io/org/app@"+digest+" | tar -tzf -")
+}
+
 func TestRenderSecretsText_Mixed(t *testing.T) {
 	result := &output.CheckResult{
 		Check:  checkSecrets,
@@ -590,7 +685,7 @@ func TestRenderSecretsText_Mixed(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Environment variables:")
@@ -622,7 +717,7 @@ func TestRenderSecretsText_MultipleLayers(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Layer 1:")
@@ -653,7 +748,7 @@ func TestRenderSecretsText_SparseLayerIndices(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderSecretsText(result)
+		renderSecretsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Layer 1:")
@@ -683,7 +778,7 @@ func TestRenderLabelsText_AllValid(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderLabelsText(result)
+		renderLabelsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking labels of image nginx:latest")
@@ -716,7 +811,7 @@ func TestRenderLabelsText_MissingLabels(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderLabelsText(result)
+		renderLabelsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "No labels found in image")
@@ -750,7 +845,7 @@ func TestRenderLabelsText_InvalidValues(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderLabelsText(result)
+		renderLabelsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Invalid labels:")
@@ -782,7 +877,7 @@ func TestRenderLabelsText_MixedValidationModes(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderLabelsText(result)
+		renderLabelsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "maintainer (existence check)")
@@ -807,7 +902,7 @@ func TestRenderLabelsText_EmptyLabels(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderLabelsText(result)
+		renderLabelsText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "No labels found in image")
@@ -843,7 +938,7 @@ func TestRenderEntrypointText_WithBothEntrypointAndCmd(t *testing.T) {
 	}
 
 	captured := captureStdout(t, func() {
-		renderEntrypointText(result)
+		renderEntrypointText(stdout, result)
 	})
 
 	assert.Contains(t, captured, "Checking entrypoint of image nginx:latest")
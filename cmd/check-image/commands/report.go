@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/reportdiff"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Work with JSON reports of the all command",
+	Long:  `Work with JSON reports of the all command.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var reportDiffCmd = &cobra.Command{
+	Use:   "diff old-report new-report",
+	Short: "Compare two JSON reports of the all command and report regressions",
+	Long: `Compare two JSON reports produced by "check-image all -o json", e.g. of the
+image built from the main branch and of the image built from a pull request,
+and report:
+  - newly failing checks: checks that fail in the new report but passed, or
+    did not run, in the old one
+  - newly passing checks
+  - new and removed findings: secret env vars and files, missing and invalid
+    labels, unauthorized ports, user and provenance violations, and
+    high-entropy files
+
+Findings are compared on what they are about (a file path, a label name, a
+port), not on where they were found, and the output is sorted, so the same
+reports always produce the same diff. Checks missing from the new report are
+not compared. Reports compressed with --compress are read as well; use "-"
+to read one of the reports from stdin.
+
+The command fails (exit code 1) only on regressions: newly failing checks or
+new findings. Checks that already failed in the old report do not fail it.`,
+	Example: `  check-image report diff main.json pr.json
+  check-image report diff main.json.gz pr.json -o json
+  check-image all pr-image:latest -c config.yaml -o json | check-image report diff main.json -`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runReportDiff(args[0], args[1]); err != nil {
+			return fmt.Errorf("report diff operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportDiffCmd)
+}
+
+func runReportDiff(oldPath, newPath string) error {
+	if oldPath == "-" && newPath == "-" {
+		return fmt.Errorf("only one report can be read from stdin")
+	}
+	oldReport, err := readReport(oldPath)
+	if err != nil {
+		return err
+	}
+	newReport, err := readReport(newPath)
+	if err != nil {
+		return err
+	}
+
+	d := reportdiff.Compare(oldReport, newReport)
+	result := output.ReportDiffResult{
+		Old:             oldPath,
+		New:             newPath,
+		OldImage:        oldReport.Image,
+		NewImage:        newReport.Image,
+		Passed:          !d.Regressed(),
+		NewlyFailing:    d.NewlyFailing,
+		NewlyPassing:    d.NewlyPassing,
+		NewFindings:     diffFindings(d.NewFindings),
+		RemovedFindings: diffFindings(d.RemovedFindings),
+	}
+
+	if result.Passed {
+		UpdateResult(ValidationSucceeded)
+	} else {
+		UpdateResult(ValidationFailed)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	renderReportDiffText(result)
+	return nil
+}
+
+func readReport(path string) (*output.AllResult, error) {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+	report, err := reportdiff.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+func diffFindings(findings []reportdiff.Finding) []output.ReportDiffFinding {
+	out := make([]output.ReportDiffFinding, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, output.ReportDiffFinding{Check: f.Check, Kind: f.Kind, Value: f.Value})
+	}
+	return out
+}
+
+func renderReportDiffText(r output.ReportDiffResult) {
+	fmt.Println(headerStyle.Render(fmt.Sprintf("Comparing %s (%s) with %s (%s)", r.New, r.NewImage, r.Old, r.OldImage)))
+
+	printList := func(title string, items []string, failed bool) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, item := range items {
+			if failed {
+				item = FailStyle.Render(item)
+			} else {
+				item = PassStyle.Render(item)
+			}
+			fmt.Printf("  - %s\n", item)
+		}
+	}
+	findingLines := func(findings []output.ReportDiffFinding) []string {
+		lines := make([]string, 0, len(findings))
+		for _, f := range findings {
+			lines = append(lines, fmt.Sprintf("%s: %s %s", f.Check, f.Kind, f.Value))
+		}
+		return lines
+	}
+
+	printList("Newly failing checks", r.NewlyFailing, true)
+	printList("New findings", findingLines(r.NewFindings), true)
+	printList("Newly passing checks", r.NewlyPassing, false)
+	printList("Removed findings", findingLines(r.RemovedFindings), false)
+
+	msg := fmt.Sprintf("%d newly failing checks, %d new findings, %d newly passing checks, %d removed findings",
+		len(r.NewlyFailing), len(r.NewFindings), len(r.NewlyPassing), len(r.RemovedFindings))
+	if r.Passed {
+		msg = "No regressions: " + msg
+	} else {
+		msg = "Regressions found: " + msg
+	}
+	fmt.Printf("\n%s\n", statusPrefix(r.Passed)+msg)
+}
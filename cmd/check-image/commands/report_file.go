@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var outputFile string
+var compressMode string
+
+// reportOut receives the JSON reports while --output-file is open; nil means
+// stdout. Like stdout, it serializes the writes to the file.
+var reportOut io.Writer
+
+// addReportFileFlags registers --output-file and --compress on cmd.
+func addReportFileFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the JSON reports to this file instead of stdout (requires --output json) (optional)")
+	cmd.Flags().StringVar(&compressMode, "compress", string(output.CompressionAuto), "Compression of --output-file: auto (from the file extension: .gz, .zst), none, gzip, zstd (optional)")
+}
+
+// reportOutput returns the writer JSON reports are rendered to.
+func reportOutput() io.Writer {
+	if reportOut != nil {
+		return reportOut
+	}
+	return stdout
+}
+
+// withReportFile runs fn with the JSON reports redirected to --output-file,
+// compressed as selected by --compress. The file is flushed and closed when
+// fn returns, so a report stream is complete even when fn fails.
+func withReportFile(fn func() error) error {
+	if outputFile == "" {
+		if compressMode != string(output.CompressionAuto) {
+			return fmt.Errorf("--compress requires --output-file")
+		}
+		return fn()
+	}
+	if OutputFmt != output.FormatJSON {
+		return fmt.Errorf("--output-file requires --output json")
+	}
+	compression, err := output.ParseCompression(compressMode, outputFile)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	w, err := output.NewCompressedWriter(f, compression)
+	if err != nil {
+		return errors.Join(err, f.Close())
+	}
+	log.WithFields(log.Fields{
+		"file":        outputFile,
+		"compression": compression,
+	}).Debug("Writing reports to file")
+
+	reportOut = output.NewSink(w)
+	defer func() { reportOut = nil }()
+
+	err = fn()
+	if closeErr := errors.Join(w.Close(), f.Close()); closeErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to write output file: %w", closeErr))
+	}
+	return err
+}
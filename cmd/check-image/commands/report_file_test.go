@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithReportFile_WritesCompressedReport(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "user"
+	OutputFmt = output.FormatJSON
+	outputFile = filepath.Join(t.TempDir(), "report.json.gz")
+
+	imageRef := createTestImage(t, testImageOptions{user: "1000", created: time.Now()})
+
+	captured := captureStdout(t, func() {
+		require.NoError(t, withReportFile(func() error { return runAll(allCmd, imageRef) }))
+	})
+	assert.Empty(t, captured, "the report goes to the file, not stdout")
+	assert.Nil(t, reportOut)
+
+	f, err := os.Open(outputFile)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var result output.AllResult
+	require.NoError(t, json.NewDecoder(zr).Decode(&result))
+	assert.Equal(t, imageRef, result.Image)
+	assert.True(t, result.Passed)
+}
+
+func TestWithReportFile_Uncompressed(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	outputFile = filepath.Join(t.TempDir(), "report.json")
+
+	require.NoError(t, withReportFile(func() error {
+		return writeReport(output.AllResult{Image: "nginx:latest", Passed: true})
+	}))
+
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	var result output.AllResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "nginx:latest", result.Image)
+}
+
+func TestWithReportFile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T)
+		errMsg string
+	}{
+		{
+			name: "compress without output file",
+			setup: func(t *testing.T) {
+				compressMode = "gzip"
+			},
+			errMsg: "--compress requires --output-file",
+		},
+		{
+			name: "output file requires json",
+			setup: func(t *testing.T) {
+				outputFile = filepath.Join(t.TempDir(), "report.json")
+			},
+			errMsg: "--output-file requires --output json",
+		},
+		{
+			name: "unsupported compression",
+			setup: func(t *testing.T) {
+				OutputFmt = output.FormatJSON
+				outputFile = filepath.Join(t.TempDir(), "report.json")
+				compressMode = "bzip2"
+			},
+			errMsg: "unsupported compression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			OutputFmt = output.FormatText
+			tt.setup(t)
+
+			called := false
+			err := withReportFile(func() error {
+				called = true
+				return nil
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.False(t, called)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/cistatus"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const defaultReportStatusContext = "check-image"
+
+var reportStatus string
+var reportStatusURL string
+var reportStatusContext = defaultReportStatusContext
+
+// addReportStatusFlags registers the commit status flags on cmd.
+func addReportStatusFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&reportStatus, "report-status", "", "Post the verdict as a commit status of the CI commit: github, gitlab, or auto to detect the CI (optional)")
+	cmd.Flags().StringVar(&reportStatusURL, "report-status-url", "", "Link of the commit status, e.g. the uploaded report artifact (default: the CI run) (optional)")
+	cmd.Flags().StringVar(&reportStatusContext, "report-status-context", defaultReportStatusContext, "Name of the commit status (optional)")
+}
+
+// withStatusReport runs fn and then posts the overall verdict as a commit
+// status when --report-status is set. The CI commit is resolved before fn
+// runs, so a misconfigured pipeline fails before any check. A failure to post
+// the status is logged and does not change the validation result.
+func withStatusReport(ctx context.Context, imageName string, fn func() error) error {
+	if reportStatus == "" {
+		if reportStatusURL != "" {
+			return fmt.Errorf("--report-status-url requires --report-status")
+		}
+		return fn()
+	}
+	target, err := cistatus.Detect(reportStatus, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	runErr := fn()
+
+	status := commitStatus(imageName, runErr)
+	if status.TargetURL = reportStatusURL; status.TargetURL == "" {
+		status.TargetURL = target.RunURL
+	}
+	fields := log.Fields{
+		"provider": target.Provider,
+		"commit":   logutil.SanitizeLogValue(target.Commit),
+		"state":    status.State,
+	}
+	if err := cistatus.Post(ctx, target, status); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn("Failed to report the commit status")
+	} else {
+		log.WithFields(fields).Info("Reported the commit status")
+	}
+	return runErr
+}
+
+// commitStatus returns the commit status of the verdict in Result, or an
+// error status when the run failed.
+func commitStatus(imageName string, runErr error) cistatus.Status {
+	subject := "All images"
+	if imageName != "" && imageName != bulkImageArg {
+		subject = redactText(imageName)
+	}
+	s := cistatus.Status{Context: reportStatusContext}
+	switch {
+	case runErr != nil || Result == ExecutionError:
+		s.State, s.Description = cistatus.StateError, "Validation of "+subject+" could not run"
+	case Result == ValidationFailed:
+		s.State, s.Description = cistatus.StateFailure, subject+" failed validation"
+	case Result == ValidationSkipped:
+		s.State, s.Description = cistatus.StateSuccess, "No checks ran on "+subject
+	default:
+		s.State, s.Description = cistatus.StateSuccess, subject+" passed all checks"
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/cistatus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		result    ValidationResult
+		runErr    error
+		wantState string
+		wantDesc  string
+	}{
+		{"Passed", "nginx:latest", ValidationSucceeded, nil, cistatus.StateSuccess, "nginx:latest passed all checks"},
+		{"Failed", "nginx:latest", ValidationFailed, nil, cistatus.StateFailure, "nginx:latest failed validation"},
+		{"Run error", "nginx:latest", ValidationSkipped, errors.New("boom"), cistatus.StateError, "Validation of nginx:latest could not run"},
+		{"No checks", "nginx:latest", ValidationSkipped, nil, cistatus.StateSuccess, "No checks ran on nginx:latest"},
+		{"Bulk", bulkImageArg, ValidationFailed, nil, cistatus.StateFailure, "All images failed validation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			Result = tt.result
+			s := commitStatus(tt.image, tt.runErr)
+			assert.Equal(t, tt.wantState, s.State)
+			assert.Equal(t, tt.wantDesc, s.Description)
+			assert.Equal(t, "check-image", s.Context)
+		})
+	}
+}
+
+func TestWithStatusReport(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/org/app/statuses/abc123", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_SERVER_URL", "https://github.example.com")
+	t.Setenv("GITHUB_REPOSITORY", "org/app")
+	t.Setenv("GITHUB_SHA", "abc123")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_TOKEN", "ghs_token")
+
+	t.Run("Posts the verdict with the run link", func(t *testing.T) {
+		resetAllGlobals(t)
+		reportStatus = cistatus.ProviderAuto
+		err := withStatusReport(context.Background(), "nginx:latest", func() error {
+			UpdateResult(ValidationFailed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "failure", posted["state"])
+		assert.Equal(t, "nginx:latest failed validation", posted["description"])
+		assert.Equal(t, "https://github.example.com/org/app/actions/runs/42", posted["target_url"])
+	})
+
+	t.Run("Custom link and context", func(t *testing.T) {
+		resetAllGlobals(t)
+		reportStatus = cistatus.ProviderGitHub
+		reportStatusURL = "https://artifacts.example.com/report.json"
+		reportStatusContext = "image-policy"
+		require.NoError(t, withStatusReport(context.Background(), "nginx:latest", func() error {
+			UpdateResult(ValidationSucceeded)
+			return nil
+		}))
+		assert.Equal(t, "success", posted["state"])
+		assert.Equal(t, "https://artifacts.example.com/report.json", posted["target_url"])
+		assert.Equal(t, "image-policy", posted["context"])
+	})
+
+	t.Run("Misconfiguration fails before the run", func(t *testing.T) {
+		resetAllGlobals(t)
+		reportStatus = cistatus.ProviderGitLab
+		ran := false
+		err := withStatusReport(context.Background(), "nginx:latest", func() error {
+			ran = true
+			return nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot report status to gitlab")
+		assert.False(t, ran)
+	})
+
+	t.Run("Link without provider", func(t *testing.T) {
+		resetAllGlobals(t)
+		reportStatusURL = "https://artifacts.example.com/report.json"
+		err := withStatusReport(context.Background(), "nginx:latest", func() error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--report-status-url requires --report-status")
+	})
+}
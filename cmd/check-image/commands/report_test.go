@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAllReport(t *testing.T, name string, report output.AllResult) string {
+	t.Helper()
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestReportDiffCommand(t *testing.T) {
+	assert.Equal(t, "diff old-report new-report", reportDiffCmd.Use)
+	assert.Equal(t, reportCmd, reportDiffCmd.Parent())
+	require.Error(t, reportDiffCmd.Args(reportDiffCmd, []string{"old.json"}))
+	require.NoError(t, reportDiffCmd.Args(reportDiffCmd, []string{"old.json", "new.json"}))
+}
+
+func TestRunReportDiff(t *testing.T) {
+	oldPath := writeAllReport(t, "main.json", output.AllResult{
+		Image: "app:main",
+		Checks: []output.CheckResult{
+			{Check: "age", Passed: true},
+			{Check: "secrets", Passed: false, Details: output.SecretsDetails{
+				FileFindings: []output.FileFinding{{Path: "/app/.env"}},
+			}},
+		},
+	})
+	regressed := writeAllReport(t, "pr.json", output.AllResult{
+		Image: "app:pr",
+		Checks: []output.CheckResult{
+			{Check: "age", Passed: false},
+			{Check: "secrets", Passed: false, Details: output.SecretsDetails{
+				FileFindings: []output.FileFinding{{Path: "/app/.env"}, {Path: "/root/.ssh/id_rsa"}},
+			}},
+		},
+	})
+	fixed := writeAllReport(t, "fixed.json", output.AllResult{
+		Image: "app:pr",
+		Checks: []output.CheckResult{
+			{Check: "age", Passed: true},
+			{Check: "secrets", Passed: true, Details: output.SecretsDetails{}},
+		},
+	})
+
+	t.Run("Regression JSON", func(t *testing.T) {
+		resetAllGlobals(t)
+		OutputFmt = output.FormatJSON
+
+		out := captureStdout(t, func() {
+			require.NoError(t, runReportDiff(oldPath, regressed))
+		})
+
+		var result output.ReportDiffResult
+		require.NoError(t, json.Unmarshal([]byte(out), &result))
+		assert.False(t, result.Passed)
+		assert.Equal(t, "app:main", result.OldImage)
+		assert.Equal(t, "app:pr", result.NewImage)
+		assert.Equal(t, []string{"age"}, result.NewlyFailing)
+		assert.Equal(t, []output.ReportDiffFinding{{Check: "secrets", Kind: "file", Value: "/root/.ssh/id_rsa"}}, result.NewFindings)
+		assert.Empty(t, result.RemovedFindings)
+		assert.Equal(t, ValidationFailed, Result)
+	})
+
+	t.Run("Improvement text", func(t *testing.T) {
+		resetAllGlobals(t)
+		OutputFmt = output.FormatText
+
+		out := captureStdout(t, func() {
+			require.NoError(t, runReportDiff(oldPath, fixed))
+		})
+
+		assert.Contains(t, out, "Newly passing checks:")
+		assert.Contains(t, out, "secrets: file /app/.env")
+		assert.Contains(t, out, "No regressions: 0 newly failing checks, 0 new findings, 1 newly passing checks, 1 removed findings")
+		assert.Equal(t, ValidationSucceeded, Result)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		resetAllGlobals(t)
+		err := runReportDiff("-", "-")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only one report can be read from stdin")
+
+		err = runReportDiff(filepath.Join(t.TempDir(), "missing.json"), fixed)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read report")
+
+		invalid := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalid, []byte(`{"check":"age"}`), 0600))
+		err = runReportDiff(oldPath, invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a report of the all command")
+	})
+}
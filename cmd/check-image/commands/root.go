@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/jarfernandez/check-image/internal/decision"
+	"github.com/jarfernandez/check-image/internal/deprecation"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
 	"github.com/mattn/go-isatty"
@@ -33,6 +36,13 @@ var colorMode string
 var registryUsername string
 var registryPassword string
 var registryPasswordStdin bool
+var cacheDir string
+var userAgent string
+var registryHeaders []string
+var imagePlatform string
+var insecureRegistries []string
+var registryMirrorFlags []string
+var explainDecisions bool
 
 // OutputFmt holds the parsed output format after PersistentPreRunE.
 var OutputFmt output.Format
@@ -50,11 +60,18 @@ var rootCmd = &cobra.Command{
 		}
 		log.SetLevel(level)
 		log.Debugln("Log level set to", level.String())
+		decision.SetEnabled(explainDecisions)
+		if err := validateArtifactsFlag(artifactsMode); err != nil {
+			return err
+		}
 
 		f, err := output.ParseFormat(outputFormat)
 		if err != nil {
 			return err
 		}
+		if f == output.FormatCSV && !supportsCSV(cmd) {
+			return fmt.Errorf("--output csv is only supported by the check commands, all, and audit")
+		}
 		OutputFmt = f
 
 		switch colorMode {
@@ -65,6 +82,51 @@ var rootCmd = &cobra.Command{
 		}
 		initRenderer(colorMode, os.Stdout)
 
+		if err := validateDocsBaseURL(docsBaseURL); err != nil {
+			return err
+		}
+
+		globalCfg, err := loadGlobalConfig()
+		if err != nil {
+			return err
+		}
+		activeGlobalConfig = globalCfg
+
+		units, err := output.ParseUnits(sizeUnits)
+		if err != nil {
+			return err
+		}
+		sizeUnits = string(units)
+
+		if cacheDir != "" {
+			if err := imageutil.SetLayerCache(cacheDir); err != nil {
+				return err
+			}
+		}
+
+		if err := imageutil.SetRequestHeaders(userAgent, registryHeaders); err != nil {
+			return err
+		}
+
+		if err := imageutil.SetPlatform(imagePlatform); err != nil {
+			return err
+		}
+
+		if err := imageutil.SetInsecureRegistries(slices.Concat(activeGlobalConfig.insecureRegistries(), insecureRegistries)); err != nil {
+			return err
+		}
+		if hosts := imageutil.InsecureRegistries(); len(hosts) > 0 {
+			log.WithField("registries", strings.Join(hosts, ",")).Info("Certificate verification and HTTPS are not required for insecure registries")
+		}
+
+		mirrors, err := resolveRegistryMirrors(activeGlobalConfig.registryMirrors(), registryMirrorFlags)
+		if err != nil {
+			return err
+		}
+		if err := imageutil.SetRegistryMirrors(mirrors); err != nil {
+			return err
+		}
+
 		// Resolve registry credentials: CLI flags > env vars > DefaultKeychain
 		username, password, err := resolveRegistryCredentials(
 			registryUsername, registryPassword, registryPasswordStdin,
@@ -100,6 +162,29 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// resolveRegistryMirrors combines the mirrors of the global config with the
+// --registry-mirror flags. The flags set the mirrors of an upstream, in the
+// order given, replacing those of the global config for that upstream.
+func resolveRegistryMirrors(configured map[string][]string, flags []string) (map[string][]string, error) {
+	mirrors := make(map[string][]string, len(configured))
+	for upstream, endpoints := range configured {
+		mirrors[upstream] = endpoints
+	}
+	fromFlags := make(map[string]bool)
+	for _, f := range flags {
+		upstream, mirror, err := imageutil.ParseRegistryMirror(f)
+		if err != nil {
+			return nil, err
+		}
+		if !fromFlags[upstream] {
+			fromFlags[upstream] = true
+			delete(mirrors, upstream)
+		}
+		mirrors[upstream] = append(mirrors[upstream], mirror)
+	}
+	return mirrors, nil
+}
+
 // resolveRegistryCredentials resolves the final registry username and password
 // by applying the precedence chain: CLI flags > env vars.
 // It validates mutual-exclusivity and pairing constraints, reads from stdin when
@@ -145,12 +230,24 @@ func init() {
 	log.SetOutput(os.Stderr)
 	log.SetLevel(log.InfoLevel)
 
+	rootCmd.SetGlobalNormalizationFunc(deprecation.FlagNormalizer(deprecation.Flags, logDeprecation))
+
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Sets the log level (trace, debug, info, warn, error, fatal, panic) (optional)")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json (optional)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json, csv (optional)")
 	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color output: auto, always, never (only applies to --output=text) (optional)")
 	rootCmd.PersistentFlags().StringVar(&registryUsername, "username", "", "Registry username for authentication (env: CHECK_IMAGE_USERNAME)")
 	rootCmd.PersistentFlags().StringVar(&registryPassword, "password", "", "Registry password or token for authentication (env: CHECK_IMAGE_PASSWORD). Caution: visible in process list. Prefer --password-stdin or env var.")
 	rootCmd.PersistentFlags().BoolVar(&registryPasswordStdin, "password-stdin", false, "Read registry password from stdin. Cannot be combined with other flags that also read from stdin (--config -, --allowed-ports @-, etc.)")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory for caching downloaded registry layers, shared by validation and copy commands (optional)")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "User-Agent header sent to registries instead of the default one (optional)")
+	rootCmd.PersistentFlags().StringArrayVar(&registryHeaders, "registry-header", nil, "Header sent with every registry request as Name=value, repeatable (optional)")
+	rootCmd.PersistentFlags().StringSliceVar(&insecureRegistries, "insecure-registry", nil, "Registry (host[:port]) reached over plain HTTP or TLS without certificate verification, comma-separated or repeated; all other registries require verified TLS (optional)")
+	rootCmd.PersistentFlags().StringSliceVar(&registryMirrorFlags, "registry-mirror", nil, "Mirror of a registry as upstream=mirror (e.g. docker.io=mirror.gcr.io), comma-separated or repeated; mirrors are tried in order before the upstream, failing over on network errors and HTTP 429/5xx (optional)")
+	rootCmd.PersistentFlags().BoolVar(&explainDecisions, "explain-decisions", false, "Log each policy rule evaluated (registry lists, label requirements, port rules, user rules, exclusions), whether it matched, and why, at info level (optional)")
+	rootCmd.PersistentFlags().StringVar(&artifactsMode, "artifacts", artifactsError, "Handling of OCI artifacts that are not container images (Helm charts, WebAssembly modules, SBOMs): error fails the checks that load the image with a not-runnable execution error, skip skips them (optional)")
+	rootCmd.PersistentFlags().StringVar(&imagePlatform, "platform", "", "Platform (os/arch[/variant]) to load from multi-platform images, e.g. linux/arm64 (optional)")
+	rootCmd.PersistentFlags().StringVar(&docsBaseURL, "docs-base-url", defaultDocsBaseURL, "Base URL of the per-check documentation links; {check} is replaced with the check name, otherwise it is appended. Empty disables the links (optional)")
+	rootCmd.PersistentFlags().StringVar(&sizeUnits, "units", string(output.UnitsMB), "Units of sizes in text output and messages: mb (megabytes of 1024*1024 bytes), iec (auto-scaled KiB, MiB, GiB), si (auto-scaled kB, MB, GB). JSON always includes raw bytes (optional)")
 }
 
 // UpdateResult updates the global Result with proper precedence.
@@ -161,20 +258,47 @@ func UpdateResult(result ValidationResult) {
 	}
 }
 
+// executionErrorKind is the kind shared by every execution error of the run,
+// "" when there was none, or when their kinds differ or are unknown.
+var executionErrorKind imageutil.ErrorKind
+
+// executionErrors counts the execution errors of the run.
+var executionErrors int
+
+// recordExecutionError updates the global Result to ExecutionError and folds
+// the kind of err into executionErrorKind, returning the kind.
+func recordExecutionError(err error) imageutil.ErrorKind {
+	kind := imageutil.ErrorKindOf(err)
+	if executionErrors == 0 {
+		executionErrorKind = kind
+	} else if kind != executionErrorKind {
+		executionErrorKind = ""
+	}
+	executionErrors++
+	UpdateResult(ExecutionError)
+	return kind
+}
+
 // ExecuteResult holds the outcome of a CLI execution for the caller.
 type ExecuteResult struct {
 	Validation ValidationResult
 	Format     output.Format
+	// ErrorKind is the kind shared by all execution errors, if any.
+	ErrorKind imageutil.ErrorKind
 }
 
 func Execute(ctx context.Context) ExecuteResult {
 	rootCmd.SetContext(ctx)
 	if err := rootCmd.Execute(); err != nil {
 		log.Errorf("Error executing check-image: %v", err)
-		Result = ExecutionError
+		recordExecutionError(err)
 	}
-	return ExecuteResult{
+	result := ExecuteResult{
 		Validation: Result,
 		Format:     OutputFmt,
 	}
+	if Result == ExecutionError {
+		result.ErrorKind = executionErrorKind
+	}
+	return result
 }
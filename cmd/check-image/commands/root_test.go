@@ -2,11 +2,14 @@ package commands
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/jarfernandez/check-image/internal/decision"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
 	log "github.com/sirupsen/logrus"
@@ -82,6 +85,33 @@ func TestUpdateResult(t *testing.T) {
 	}
 }
 
+func TestRecordExecutionError(t *testing.T) {
+	notFound := &transport.Error{StatusCode: 404}
+	unauthorized := &transport.Error{StatusCode: 401}
+
+	tests := []struct {
+		name string
+		errs []error
+		want imageutil.ErrorKind
+	}{
+		{"single kind", []error{notFound}, imageutil.ErrorKindNotFound},
+		{"same kinds", []error{unauthorized, fmt.Errorf("wrapped: %w", unauthorized)}, imageutil.ErrorKindUnauthorized},
+		{"different kinds", []error{notFound, unauthorized}, ""},
+		{"unknown kind", []error{notFound, errors.New("boom")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			for _, err := range tt.errs {
+				recordExecutionError(err)
+			}
+			assert.Equal(t, ExecutionError, Result)
+			assert.Equal(t, tt.want, executionErrorKind)
+		})
+	}
+}
+
 func TestRootCommand(t *testing.T) {
 	// Reset Result before test
 	Result = ValidationSkipped
@@ -255,6 +285,25 @@ func TestRootCommandOutputFormat(t *testing.T) {
 	}
 }
 
+func TestRootCommandOutputFormat_CSV(t *testing.T) {
+	origFormat := outputFormat
+	origLogLevel := logLevel
+	defer func() {
+		outputFormat = origFormat
+		logLevel = origLogLevel
+	}()
+	logLevel = "info"
+	outputFormat = "csv"
+
+	require.NoError(t, rootCmd.PersistentPreRunE(allCmd, []string{}))
+	assert.Equal(t, output.FormatCSV, OutputFmt)
+	require.NoError(t, rootCmd.PersistentPreRunE(userCmd, []string{}))
+
+	err := rootCmd.PersistentPreRunE(versionCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--output csv is only supported by the check commands, all, and audit")
+}
+
 // resetAuthState resets all authentication-related global state to defaults.
 // Call this via t.Cleanup in any test that modifies auth state.
 func resetAuthState(t *testing.T) {
@@ -714,3 +763,129 @@ func TestRootCommandAuth_PasswordFlagWarning(t *testing.T) {
 	assert.Contains(t, buf.String(), "process list")
 	assert.Contains(t, buf.String(), "--password-stdin")
 }
+
+func TestRootCommand_RegistryHeaderFlags(t *testing.T) {
+	t.Cleanup(func() {
+		userAgent = ""
+		registryHeaders = nil
+		require.NoError(t, imageutil.SetRequestHeaders("", nil))
+	})
+
+	uaFlag := rootCmd.PersistentFlags().Lookup("user-agent")
+	require.NotNil(t, uaFlag, "flag --user-agent must exist")
+	headerFlag := rootCmd.PersistentFlags().Lookup("registry-header")
+	require.NotNil(t, headerFlag, "flag --registry-header must exist")
+	assert.Equal(t, "stringArray", headerFlag.Value.Type())
+
+	logLevel = "info"
+	outputFormat = "text"
+
+	userAgent = "acme-scanner/1.0"
+	registryHeaders = []string{"X-Team=platform"}
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+
+	registryHeaders = []string{"Authorization=Bearer abc"}
+	err := rootCmd.PersistentPreRunE(rootCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authorization cannot be set")
+}
+
+func TestRootCommand_PlatformFlag(t *testing.T) {
+	t.Cleanup(func() {
+		imagePlatform = ""
+		require.NoError(t, imageutil.SetPlatform(""))
+	})
+
+	require.NotNil(t, rootCmd.PersistentFlags().Lookup("platform"), "flag --platform must exist")
+
+	logLevel = "info"
+	outputFormat = "text"
+
+	imagePlatform = "linux/arm64"
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+
+	imagePlatform = "arm64"
+	err := rootCmd.PersistentPreRunE(rootCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid platform")
+}
+
+func TestRootCommand_ExplainDecisionsFlag(t *testing.T) {
+	t.Cleanup(func() {
+		explainDecisions = false
+		decision.SetEnabled(false)
+	})
+
+	require.NotNil(t, rootCmd.PersistentFlags().Lookup("explain-decisions"), "flag --explain-decisions must exist")
+
+	logLevel = "info"
+	outputFormat = "text"
+
+	explainDecisions = true
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+	assert.True(t, decision.Enabled())
+
+	explainDecisions = false
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+	assert.False(t, decision.Enabled())
+}
+
+func TestRootCommand_InsecureRegistryFlag(t *testing.T) {
+	t.Cleanup(func() {
+		insecureRegistries = nil
+		require.NoError(t, imageutil.SetInsecureRegistries(nil))
+	})
+
+	require.NotNil(t, rootCmd.PersistentFlags().Lookup("insecure-registry"), "flag --insecure-registry must exist")
+
+	logLevel = "info"
+	outputFormat = "text"
+
+	insecureRegistries = []string{"dev-registry:5000", "localhost:5001"}
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+	assert.Equal(t, []string{"dev-registry:5000", "localhost:5001"}, imageutil.InsecureRegistries())
+
+	insecureRegistries = []string{"dev-registry:5000/org"}
+	err := rootCmd.PersistentPreRunE(rootCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid insecure registry")
+}
+
+func TestRootCommand_RegistryMirrorFlag(t *testing.T) {
+	t.Cleanup(func() {
+		registryMirrorFlags = nil
+		require.NoError(t, imageutil.SetRegistryMirrors(nil))
+	})
+
+	require.NotNil(t, rootCmd.PersistentFlags().Lookup("registry-mirror"), "flag --registry-mirror must exist")
+
+	logLevel = "info"
+	outputFormat = "text"
+
+	registryMirrorFlags = []string{"docker.io=mirror.gcr.io", "docker.io=registry.example.com/dockerhub"}
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, []string{}))
+	assert.Equal(t, []string{"mirror.gcr.io", "registry.example.com/dockerhub"}, imageutil.RegistryMirrors("docker.io"))
+
+	registryMirrorFlags = []string{"mirror.gcr.io"}
+	err := rootCmd.PersistentPreRunE(rootCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected upstream=mirror")
+}
+
+func TestResolveRegistryMirrors(t *testing.T) {
+	configured := map[string][]string{
+		"docker.io": {"mirror.gcr.io"},
+		"quay.io":   {"quay-mirror.example.com"},
+	}
+	got, err := resolveRegistryMirrors(configured, []string{"docker.io=a.example.com", "docker.io=b.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"docker.io": {"a.example.com", "b.example.com"},
+		"quay.io":   {"quay-mirror.example.com"},
+	}, got, "the flags replace the mirrors of the global config for their upstream")
+	assert.Equal(t, []string{"mirror.gcr.io"}, configured["docker.io"], "the global config is not modified")
+
+	got, err = resolveRegistryMirrors(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -11,16 +11,28 @@ import (
 // checkName is used only for the error message; run is the check implementation.
 func runCheckCmd(checkName string, run func(context.Context, string) (*output.CheckResult, error), ctx context.Context, imageName string, outFmt output.Format) error {
 	result, err := run(ctx, imageName)
+	if skipped, ok := artifactSkipResult(checkName, imageName, err); ok {
+		result, err = skipped, nil
+	}
 	if err != nil {
 		return fmt.Errorf("check %s operation failed: %w", checkName, err)
 	}
+	setDocsURL(result)
 	if err := renderResult(result, outFmt); err != nil {
 		return err
 	}
-	if result.Passed {
+	updateCheckResult(result)
+	return nil
+}
+
+// updateCheckResult updates the global Result with the status of result.
+func updateCheckResult(result *output.CheckResult) {
+	switch result.Status() {
+	case output.StatusSkipped:
+		UpdateResult(ValidationSkipped)
+	case output.StatusPassed:
 		UpdateResult(ValidationSucceeded)
-	} else {
+	default:
 		UpdateResult(ValidationFailed)
 	}
-	return nil
 }
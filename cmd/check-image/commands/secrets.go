@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	cr "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
 	"github.com/jarfernandez/check-image/internal/output"
 	"github.com/jarfernandez/check-image/internal/secrets"
 	log "github.com/sirupsen/logrus"
@@ -12,9 +14,10 @@ import (
 )
 
 var (
-	secretsPolicy string
-	skipEnvVars   bool
-	skipFiles     bool
+	secretsPolicy  string
+	skipEnvVars    bool
+	skipFiles      bool
+	failOnSeverity string
 )
 
 var secretsCmd = &cobra.Command{
@@ -29,6 +32,7 @@ Scans both environment variables and files across all image layers.
   check-image secrets nginx:latest --secrets-policy secrets-policy.yaml
   check-image secrets nginx:latest --skip-env-vars
   check-image secrets nginx:latest --skip-files
+  check-image secrets nginx:latest --fail-on-severity high
   check-image secrets oci:/path/to/layout:1.0
   check-image secrets oci-archive:/path/to/image.tar:latest --secrets-policy secrets-policy.json
   check-image secrets docker-archive:/path/to/image.tar:tag --skip-files
@@ -37,7 +41,7 @@ Scans both environment variables and files across all image layers.
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		return runCheckCmd(checkSecrets, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runSecrets(ctx, img, secretsPolicy, skipEnvVars, skipFiles)
+			return runSecrets(ctx, img, secretsPolicy, skipEnvVars, skipFiles, failOnSeverity)
 		}, ctx, args[0], OutputFmt)
 	},
 }
@@ -47,9 +51,20 @@ func init() {
 	secretsCmd.Flags().StringVarP(&secretsPolicy, "secrets-policy", "s", "", "Secrets policy file (JSON or YAML) (optional)")
 	secretsCmd.Flags().BoolVar(&skipEnvVars, "skip-env-vars", false, "Skip environment variable checks (optional)")
 	secretsCmd.Flags().BoolVar(&skipFiles, "skip-files", false, "Skip file system checks (optional)")
+	secretsCmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "Fail only on findings of this severity or higher: low, medium, high, critical (optional)")
 }
 
-func runSecrets(ctx context.Context, imageName string, policyPath string, noEnvVars bool, noFiles bool) (*output.CheckResult, error) {
+// runSecrets scans the image for secrets. With a failOnSeverity, findings
+// below that severity are reported without failing the check.
+func runSecrets(ctx context.Context, imageName string, policyPath string, noEnvVars bool, noFiles bool, failOnSeverity string) (*output.CheckResult, error) {
+	if failOnSeverity != "" {
+		severity, err := secrets.ParseSeverity(failOnSeverity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --fail-on-severity: %w", err)
+		}
+		failOnSeverity = severity
+	}
+
 	policy, err := secrets.LoadSecretsPolicy(policyPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to load secrets policy: %w", err)
@@ -86,17 +101,21 @@ func runSecrets(ctx context.Context, imageName string, policyPath string, noEnvV
 		if err != nil {
 			return nil, fmt.Errorf("error scanning files: %w", err)
 		}
+		attributeFileFindings(ctx, image, config, fileFindings)
 	}
 
 	envCount := len(envFindings)
 	fileCount := len(fileFindings)
 	totalFindings := envCount + fileCount
-	passed := totalFindings == 0
+	passed := countFailingFindings(envFindings, fileFindings, failOnSeverity) == 0
 
 	var msg string
-	if passed {
+	switch {
+	case totalFindings == 0:
 		msg = "No secrets detected"
-	} else {
+	case passed:
+		msg = fmt.Sprintf("Secrets detected, none of %s severity or higher", failOnSeverity)
+	default:
 		msg = "Secrets detected"
 	}
 
@@ -106,6 +125,7 @@ func runSecrets(ctx context.Context, imageName string, policyPath string, noEnvV
 		TotalFindings:  totalFindings,
 		EnvVarCount:    envCount,
 		FileCount:      fileCount,
+		FailOnSeverity: failOnSeverity,
 	}
 
 	return &output.CheckResult{
@@ -116,3 +136,67 @@ func runSecrets(ctx context.Context, imageName string, policyPath string, noEnvV
 		Details: details,
 	}, nil
 }
+
+// countFailingFindings returns the number of findings of failOnSeverity or
+// higher, or of all findings without a failOnSeverity.
+func countFailingFindings(envFindings []output.EnvVarFinding, fileFindings []output.FileFinding, failOnSeverity string) int {
+	if failOnSeverity == "" {
+		return len(envFindings) + len(fileFindings)
+	}
+	count := 0
+	for _, f := range envFindings {
+		if secrets.AtLeast(f.Severity, failOnSeverity) {
+			count++
+		}
+	}
+	for _, f := range fileFindings {
+		if secrets.AtLeast(f.Severity, failOnSeverity) {
+			count++
+		}
+	}
+	return count
+}
+
+// attributeFileFindings records on each finding the history entry that
+// created its layer and, when the image declares its base image, whether the
+// layer comes from it. Attribution is best effort: failures are logged and
+// leave the findings unchanged.
+func attributeFileFindings(ctx context.Context, image cr.Image, config *cr.ConfigFile, findings []output.FileFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	layers, err := image.Layers()
+	if err != nil {
+		log.WithField("error", err).Warn("Unable to read the layers for finding attribution")
+		return
+	}
+
+	history := imageutil.LayerHistory(config, len(layers))
+
+	var baseLayers *int
+	if ref := imageutil.DeclaredBaseImage(image, config); ref != "" {
+		n, err := countBaseLayers(ctx, layers, &layerBase{image: ref})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"base-image": logutil.SanitizeLogValue(ref),
+				"error":      err,
+			}).Warn("Unable to read the declared base image for finding attribution")
+		} else {
+			baseLayers = &n
+		}
+	}
+
+	for i := range findings {
+		idx := findings[i].LayerIndex
+		findings[i].CreatedBy = imageutil.LayerCreatedBy(history, idx)
+		if idx >= 0 && idx < len(layers) {
+			if digest, err := layers[idx].Digest(); err == nil {
+				findings[i].LayerDigest = digest.String()
+			}
+		}
+		if baseLayers != nil {
+			base := idx < *baseLayers
+			findings[i].BaseLayer = &base
+		}
+	}
+}
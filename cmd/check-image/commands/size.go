@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"math"
 
+	cr "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var (
-	maxSize   uint
-	maxLayers uint
+	maxSize      uint
+	maxLayers    uint
+	maxTotalSize uint
+
+	countFromBase bool
+	baseImage     string
+	baseLayers    string
 )
 
 var sizeCmd = &cobra.Command{
@@ -23,14 +30,20 @@ var sizeCmd = &cobra.Command{
 ` + imageArgFormatsDoc,
 	Example: `  check-image size nginx:latest
   check-image size nginx:latest --max-size 300 --max-layers 15
+  check-image size nginx:latest --max-total-size 1500
+  check-image size myapp:1.0 --max-layers 5 --count-from-base=false --base-image node:22-alpine
   check-image size oci:/path/to/layout:1.0
   check-image size oci-archive:/path/to/image.tar:latest
   check-image size docker-archive:/path/to/image.tar:tag`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
+		base, err := newLayerBase(countFromBase, baseImage, baseLayers)
+		if err != nil {
+			return err
+		}
 		return runCheckCmd(checkSize, func(ctx context.Context, img string) (*output.CheckResult, error) {
-			return runSize(ctx, img, maxSize, maxLayers)
+			return runSize(ctx, img, maxSize, maxLayers, maxTotalSize, base)
 		}, ctx, args[0], OutputFmt)
 	},
 }
@@ -39,9 +52,127 @@ func init() {
 	rootCmd.AddCommand(sizeCmd)
 	sizeCmd.Flags().UintVarP(&maxSize, "max-size", "m", defaultMaxSizeMB, "Maximum size in megabytes (optional)")
 	sizeCmd.Flags().UintVarP(&maxLayers, "max-layers", "y", defaultMaxLayerCount, "Maximum number of layers (optional)")
+	sizeCmd.Flags().UintVar(&maxTotalSize, "max-total-size", 0, "Maximum total size in megabytes of all platforms of a multi-platform image, 0 to disable (optional)")
+	addLayerBaseFlags(sizeCmd)
+}
+
+// addLayerBaseFlags registers the flags that exclude base image layers from
+// the layer count.
+func addLayerBaseFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&countFromBase, "count-from-base", true, "Count the layers of the base image against --max-layers; set to false to count only layers added on top of --base-image or --base-layers (optional)")
+	cmd.Flags().StringVar(&baseImage, "base-image", "", "Approved base image whose layers are not counted when --count-from-base=false (optional)")
+	cmd.Flags().StringVar(&baseLayers, "base-layers", "", "Comma-separated list or @<file> of approved base layer digests (compressed or uncompressed) not counted when --count-from-base=false (optional)")
+}
+
+// layerBase identifies the approved base layers that are excluded from the
+// layer count.
+type layerBase struct {
+	image   string
+	digests []string
+}
+
+// newLayerBase validates the base layer settings. It returns nil when base
+// layers are counted.
+func newLayerBase(countFromBase bool, image, digests string) (*layerBase, error) {
+	if countFromBase {
+		return nil, nil
+	}
+	list, err := parseListInput(digests, "base-layers")
+	if err != nil {
+		return nil, fmt.Errorf("invalid --base-layers: %w", err)
+	}
+	base := &layerBase{image: image}
+	for _, d := range list {
+		if _, err := cr.NewHash(d); err != nil {
+			return nil, fmt.Errorf("invalid base layer digest %q: %w", d, err)
+		}
+		base.digests = append(base.digests, d)
+	}
+	if base.image == "" && len(base.digests) == 0 {
+		return nil, fmt.Errorf("count-from-base=false requires base-image or base-layers")
+	}
+	return base, nil
+}
+
+// layerSet returns the digests and diff IDs of the approved base layers.
+func (b *layerBase) layerSet(ctx context.Context) (map[cr.Hash]bool, error) {
+	set := make(map[cr.Hash]bool)
+	for _, d := range b.digests {
+		h, err := cr.NewHash(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base layer digest %q: %w", d, err)
+		}
+		set[h] = true
+	}
+	if b.image == "" {
+		return set, nil
+	}
+
+	img, cleanup, err := imageutil.GetImage(ctx, b.image)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the base image: %w", err)
+	}
+	defer cleanup()
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the base image layers: %w", err)
+	}
+	for _, l := range layers {
+		if err := addLayerHashes(set, l); err != nil {
+			return nil, fmt.Errorf("error reading the base image layers: %w", err)
+		}
+	}
+	return set, nil
+}
+
+func containsAny(set, hashes map[cr.Hash]bool) bool {
+	for h := range hashes {
+		if set[h] {
+			return true
+		}
+	}
+	return false
 }
 
-func runSize(ctx context.Context, imageName string, maxSizeMB uint, maxLayerCount uint) (*output.CheckResult, error) {
+func addLayerHashes(set map[cr.Hash]bool, l cr.Layer) error {
+	digest, err := l.Digest()
+	if err != nil {
+		return err
+	}
+	diffID, err := l.DiffID()
+	if err != nil {
+		return err
+	}
+	set[digest] = true
+	set[diffID] = true
+	return nil
+}
+
+// countBaseLayers returns the number of leading layers that belong to the
+// approved base. Layers are matched by compressed digest or diff ID, so a
+// base image that was re-compressed (e.g., loaded into a daemon) still
+// matches. Counting stops at the first layer that is not part of the base.
+func countBaseLayers(ctx context.Context, layers []cr.Layer, base *layerBase) (int, error) {
+	set, err := base.layerSet(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, l := range layers {
+		own := make(map[cr.Hash]bool, 2)
+		if err := addLayerHashes(own, l); err != nil {
+			return 0, fmt.Errorf("error reading layer %d: %w", i+1, err)
+		}
+		if !containsAny(set, own) {
+			return i, nil
+		}
+	}
+	return len(layers), nil
+}
+
+// runSize validates the size and layer count of an image. When base is set,
+// the leading layers of the approved base image are not counted against
+// maxLayerCount.
+func runSize(ctx context.Context, imageName string, maxSizeMB uint, maxLayerCount uint, maxTotalSizeMB uint, base *layerBase) (*output.CheckResult, error) {
 	image, cleanup, err := imageutil.GetImage(ctx, imageName)
 	if err != nil {
 		return nil, err
@@ -53,6 +184,8 @@ func runSize(ctx context.Context, imageName string, maxSizeMB uint, maxLayerCoun
 		return nil, fmt.Errorf("error retrieving the layers: %w", err)
 	}
 
+	history := imageutil.LayerHistory(sizeConfigFile(image), len(layers))
+
 	layerInfos := make([]output.LayerInfo, 0, len(layers))
 	var totalSize int64
 	for i, layer := range layers {
@@ -61,43 +194,124 @@ func runSize(ctx context.Context, imageName string, maxSizeMB uint, maxLayerCoun
 			return nil, fmt.Errorf("error getting size of layer %d: %w", i+1, err)
 		}
 		totalSize += size
-		layerInfos = append(layerInfos, output.LayerInfo{Index: i + 1, Bytes: size})
+		info := output.LayerInfo{Index: i + 1, Bytes: size, CreatedBy: imageutil.LayerCreatedBy(history, i)}
+		if digest, err := layer.Digest(); err == nil {
+			info.Digest = digest.String()
+		}
+		layerInfos = append(layerInfos, info)
 	}
 
-	// Validate that maxSizeMB doesn't overflow when converting to int64
-	if maxSizeMB > math.MaxInt64/(1024*1024) {
-		return nil, fmt.Errorf("max-size value %d is too large", maxSizeMB)
+	maxSizeBytes, err := megabytesToBytes("max-size", maxSizeMB)
+	if err != nil {
+		return nil, err
 	}
-	maxSizeBytes := int64(maxSizeMB) * 1024 * 1024
 
-	layersOK := uint(len(layers)) <= maxLayerCount
+	countedLayers := len(layers)
+	var baseLayerCount *int
+	if base != nil {
+		n, err := countBaseLayers(ctx, layers, base)
+		if err != nil {
+			return nil, err
+		}
+		baseLayerCount = &n
+		countedLayers -= n
+	}
+
+	// #nosec G115 -- countedLayers is never negative (at most len(layers) base layers)
+	layersOK := uint(countedLayers) <= maxLayerCount
 	sizeOK := totalSize <= maxSizeBytes
-	passed := layersOK && sizeOK
+
+	details := output.SizeDetails{
+		TotalBytes: totalSize,
+		TotalMB:    float64(totalSize) / 1024 / 1024,
+		MaxSizeMB:  maxSizeMB,
+		LayerCount: len(layers),
+		MaxLayers:  maxLayerCount,
+		Layers:     layerInfos,
+
+		BaseLayerCount: baseLayerCount,
+	}
+
+	totalOK := true
+	if maxTotalSizeMB > 0 {
+		totalOK, err = checkIndexTotalSize(ctx, imageName, maxTotalSizeMB, &details)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &output.CheckResult{
+		Check:   checkSize,
+		Image:   imageName,
+		Passed:  layersOK && sizeOK && totalOK,
+		Message: sizeMessage(layersOK, sizeOK, totalOK, details),
+		Details: details,
+	}, nil
+}
+
+// sizeConfigFile returns the config of an image for layer attribution, or nil
+// when it cannot be read; the size check does not depend on it.
+func sizeConfigFile(image cr.Image) *cr.ConfigFile {
+	config, err := image.ConfigFile()
+	if err != nil {
+		log.WithField("error", err).Warn("Unable to read the image config for layer attribution")
+		return nil
+	}
+	return config
+}
+
+func sizeMessage(layersOK, sizeOK, totalOK bool, d output.SizeDetails) string {
+	layersMsg := fmt.Sprintf("Image has more than %d layers", d.MaxLayers)
+	if d.BaseLayerCount != nil {
+		layersMsg += " on top of its base image"
+	}
 
 	var msg string
 	switch {
 	case !layersOK && !sizeOK:
-		msg = fmt.Sprintf("Image has more than %d layers and size exceeds the recommended limit of %d MB", maxLayerCount, maxSizeMB)
+		msg = fmt.Sprintf("%s and size exceeds the recommended limit of %s", layersMsg, formatSizeLimit(d.MaxSizeMB))
 	case !layersOK:
-		msg = fmt.Sprintf("Image has more than %d layers", maxLayerCount)
+		msg = layersMsg
 	case !sizeOK:
-		msg = fmt.Sprintf("Image size exceeds the recommended limit of %d MB", maxSizeMB)
-	default:
-		msg = fmt.Sprintf("Image size is within the allowed limit of %d MB", maxSizeMB)
+		msg = fmt.Sprintf("Image size exceeds the recommended limit of %s", formatSizeLimit(d.MaxSizeMB))
+	case totalOK:
+		return fmt.Sprintf("Image size is within the allowed limit of %s", formatSizeLimit(d.MaxSizeMB))
+	}
+	if totalOK {
+		return msg
+	}
+	totalMsg := fmt.Sprintf("total size of all platforms (%d) exceeds the limit of %s", d.Platforms, formatSizeLimit(d.MaxTotalSizeMB))
+	if msg == "" {
+		return "Image " + totalMsg
 	}
+	return msg + " and " + totalMsg
+}
 
-	return &output.CheckResult{
-		Check:   checkSize,
-		Image:   imageName,
-		Passed:  passed,
-		Message: msg,
-		Details: output.SizeDetails{
-			TotalBytes: totalSize,
-			TotalMB:    float64(totalSize) / 1024 / 1024,
-			MaxSizeMB:  maxSizeMB,
-			LayerCount: len(layers),
-			MaxLayers:  maxLayerCount,
-			Layers:     layerInfos,
-		},
-	}, nil
+// checkIndexTotalSize records the aggregate size of every platform of the
+// image in details and reports whether it is within maxTotalSizeMB.
+// Registries store and bill every platform's blobs, not only the one that
+// was pulled.
+func checkIndexTotalSize(ctx context.Context, imageName string, maxTotalSizeMB uint, details *output.SizeDetails) (bool, error) {
+	maxTotalBytes, err := megabytesToBytes("max-total-size", maxTotalSizeMB)
+	if err != nil {
+		return false, err
+	}
+	total, err := imageutil.GetIndexSize(ctx, imageName)
+	if err != nil {
+		return false, fmt.Errorf("error computing the total size of all platforms: %w", err)
+	}
+	details.IndexTotalBytes = total.Bytes
+	details.IndexTotalMB = float64(total.Bytes) / 1024 / 1024
+	details.Platforms = total.Platforms
+	details.MaxTotalSizeMB = maxTotalSizeMB
+	return total.Bytes <= maxTotalBytes, nil
+}
+
+// megabytesToBytes converts a megabyte limit to bytes, rejecting values that
+// would overflow int64.
+func megabytesToBytes(flagName string, mb uint) (int64, error) {
+	if mb > math.MaxInt64/(1024*1024) {
+		return 0, fmt.Errorf("%s value %d is too large", flagName, mb)
+	}
+	return int64(mb) * 1024 * 1024, nil
 }
@@ -3,8 +3,13 @@ package commands
 import (
 	"context"
 	"math"
+	"strings"
 	"testing"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/jarfernandez/check-image/internal/output"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -48,7 +53,7 @@ func TestRunSize_WithinLimits(t *testing.T) {
 		layerSizes: []int64{1024, 1024, 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 10, 5)
+	result, err := runSize(context.Background(), imageRef, 10, 5, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when within size and layer limits")
 
@@ -63,7 +68,7 @@ func TestRunSize_ExceedsSizeLimit(t *testing.T) {
 		layerSizes: []int64{600 * 1024, 600 * 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 1, 10)
+	result, err := runSize(context.Background(), imageRef, 1, 10, 0, nil)
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when size exceeds limit")
 }
@@ -74,7 +79,7 @@ func TestRunSize_ExceedsLayerLimit(t *testing.T) {
 		layerSizes: []int64{1024, 1024, 1024, 1024, 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 100, 3)
+	result, err := runSize(context.Background(), imageRef, 100, 3, 0, nil)
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when layer count exceeds limit")
 }
@@ -85,7 +90,7 @@ func TestRunSize_ExceedsBothLimits(t *testing.T) {
 		layerSizes: []int64{500 * 1024, 500 * 1024, 500 * 1024, 500 * 1024, 500 * 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 1, 2)
+	result, err := runSize(context.Background(), imageRef, 1, 2, 0, nil)
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when both size and layer count exceed limits")
 }
@@ -95,7 +100,7 @@ func TestRunSize_NoLayers(t *testing.T) {
 		layerCount: 0,
 	})
 
-	result, err := runSize(context.Background(), imageRef, 10, 5)
+	result, err := runSize(context.Background(), imageRef, 10, 5, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed with no layers")
 }
@@ -106,7 +111,7 @@ func TestRunSize_ExactlyAtSizeLimit(t *testing.T) {
 		layerSizes: []int64{1024 * 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 1, 5)
+	result, err := runSize(context.Background(), imageRef, 1, 5, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when exactly at size limit")
 }
@@ -117,7 +122,7 @@ func TestRunSize_ExactlyAtLayerLimit(t *testing.T) {
 		layerSizes: []int64{1024, 1024, 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 100, 3)
+	result, err := runSize(context.Background(), imageRef, 100, 3, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should succeed when exactly at layer limit")
 }
@@ -128,7 +133,7 @@ func TestRunSize_OneByteSizeOverLimit(t *testing.T) {
 		layerSizes: []int64{1024*1024 + 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 1, 5)
+	result, err := runSize(context.Background(), imageRef, 1, 5, 0, nil)
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when even 1 byte over size limit")
 }
@@ -139,13 +144,13 @@ func TestRunSize_OneLayerOverLimit(t *testing.T) {
 		layerSizes: []int64{1024, 1024, 1024, 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 100, 3)
+	result, err := runSize(context.Background(), imageRef, 100, 3, 0, nil)
 	require.NoError(t, err)
 	assert.False(t, result.Passed, "Should fail when 1 layer over limit")
 }
 
 func TestRunSize_InvalidImageReference(t *testing.T) {
-	_, err := runSize(context.Background(), "oci:/nonexistent/path:latest", 100, 10)
+	_, err := runSize(context.Background(), "oci:/nonexistent/path:latest", 100, 10, 0, nil)
 	require.Error(t, err)
 }
 
@@ -155,7 +160,7 @@ func TestRunSize_VeryLargeImage(t *testing.T) {
 		layerSizes: []int64{1024 * 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 1000, 100)
+	result, err := runSize(context.Background(), imageRef, 1000, 100, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should handle large images with many layers")
 }
@@ -172,7 +177,7 @@ func TestRunSize_VariableLayerSizes(t *testing.T) {
 		},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 10, 10)
+	result, err := runSize(context.Background(), imageRef, 10, 10, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should handle variable layer sizes")
 }
@@ -183,7 +188,7 @@ func TestRunSize_MaxSizeOverflow(t *testing.T) {
 		layerSizes: []int64{1024},
 	})
 
-	_, err := runSize(context.Background(), imageRef, math.MaxInt64/(1024*1024)+1, 10)
+	_, err := runSize(context.Background(), imageRef, math.MaxInt64/(1024*1024)+1, 10, 0, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "too large", "Should return error for max-size overflow")
 }
@@ -194,7 +199,190 @@ func TestRunSize_DefaultFlagValues(t *testing.T) {
 		layerSizes: []int64{1024 * 1024},
 	})
 
-	result, err := runSize(context.Background(), imageRef, 500, 20)
+	result, err := runSize(context.Background(), imageRef, 500, 20, 0, nil)
 	require.NoError(t, err)
 	assert.True(t, result.Passed, "Should work with default flag values")
 }
+
+func TestRunSize_MaxTotalSize(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{
+		layerCount: 2,
+		layerSizes: []int64{1024 * 1024, 1024 * 1024},
+	})
+
+	tests := []struct {
+		name         string
+		maxSize      uint
+		maxTotalSize uint
+		wantPassed   bool
+		wantMessage  string
+	}{
+		{name: "Disabled", maxSize: 10, maxTotalSize: 0, wantPassed: true, wantMessage: "Image size is within the allowed limit of 10 MB"},
+		{name: "Within limit", maxSize: 10, maxTotalSize: 5, wantPassed: true, wantMessage: "Image size is within the allowed limit of 10 MB"},
+		{name: "Total exceeds limit", maxSize: 10, maxTotalSize: 1, wantPassed: false, wantMessage: "Image total size of all platforms (1) exceeds the limit of 1 MB"},
+		{name: "Both exceed limits", maxSize: 1, maxTotalSize: 1, wantPassed: false, wantMessage: "Image size exceeds the recommended limit of 1 MB and total size of all platforms (1) exceeds the limit of 1 MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runSize(context.Background(), imageRef, tt.maxSize, 10, tt.maxTotalSize, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Equal(t, tt.wantMessage, result.Message)
+
+			details := result.Details.(output.SizeDetails)
+			assert.Equal(t, tt.maxTotalSize, details.MaxTotalSizeMB)
+			if tt.maxTotalSize == 0 {
+				assert.Zero(t, details.IndexTotalBytes, "index totals are omitted when disabled")
+				return
+			}
+			assert.Equal(t, 1, details.Platforms)
+			assert.Greater(t, details.IndexTotalBytes, details.TotalBytes, "the total includes the config blob")
+		})
+	}
+}
+
+func TestRunSize_MaxTotalSizeOverflow(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{layerCount: 1, layerSizes: []int64{1024}})
+
+	_, err := runSize(context.Background(), imageRef, 10, 10, math.MaxInt64/(1024*1024)+1, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max-total-size value")
+}
+
+func TestRunSize_LayerHistory(t *testing.T) {
+	img, err := mutate.Append(empty.Image,
+		mutate.Addendum{Layer: createTestLayer(t, 1024), History: v1.History{CreatedBy: "ADD rootfs.tar /"}},
+		mutate.Addendum{History: v1.History{CreatedBy: "ENV APP=1", EmptyLayer: true}},
+		mutate.Addendum{Layer: createTestLayer(t, 1024), History: v1.History{CreatedBy: "RUN apk add curl"}},
+	)
+	require.NoError(t, err)
+
+	result, err := runSize(context.Background(), writeLayoutImage(t, img), 10, 5, 0, nil)
+	require.NoError(t, err)
+	details := result.Details.(output.SizeDetails)
+	require.Len(t, details.Layers, 2)
+	assert.Equal(t, "ADD rootfs.tar /", details.Layers[0].CreatedBy)
+	assert.Equal(t, "RUN apk add curl", details.Layers[1].CreatedBy, "empty-layer history entries are skipped")
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", details.Layers[0].Digest)
+}
+
+// writeLayoutImage writes img to a new OCI layout and returns its reference.
+func writeLayoutImage(t *testing.T, img v1.Image) string {
+	t.Helper()
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(img, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "latest",
+	})))
+	return "oci:" + dir + ":latest"
+}
+
+// newBaseAndAppImages returns a two-layer base image and an application
+// image built on top of it with three more layers.
+func newBaseAndAppImages(t *testing.T) (base v1.Image, app v1.Image) {
+	t.Helper()
+	base, err := mutate.AppendLayers(empty.Image, createTestLayer(t, 2048), createTestLayer(t, 2048))
+	require.NoError(t, err)
+	app, err = mutate.AppendLayers(base, createTestLayer(t, 1024), createTestLayer(t, 1024), createTestLayer(t, 1024))
+	require.NoError(t, err)
+	return base, app
+}
+
+func layerHashes(t *testing.T, img v1.Image, diffIDs bool) []string {
+	t.Helper()
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	var out []string
+	for _, l := range layers {
+		h, err := l.Digest()
+		if diffIDs {
+			h, err = l.DiffID()
+		}
+		require.NoError(t, err)
+		out = append(out, h.String())
+	}
+	return out
+}
+
+func TestNewLayerBase(t *testing.T) {
+	tests := []struct {
+		name          string
+		countFromBase bool
+		image         string
+		digests       string
+		wantNil       bool
+		wantDigests   int
+		wantErr       string
+	}{
+		{name: "Base layers counted", countFromBase: true, image: "node:22", wantNil: true},
+		{name: "Base image", image: "node:22"},
+		{name: "Digest list", digests: "sha256:" + strings.Repeat("a", 64) + ", sha256:" + strings.Repeat("b", 64), wantDigests: 2},
+		{name: "No base configured", wantErr: "requires base-image or base-layers"},
+		{name: "Invalid digest", digests: "sha256:short", wantErr: "invalid base layer digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := newLayerBase(tt.countFromBase, tt.image, tt.digests)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, base)
+				return
+			}
+			require.NotNil(t, base)
+			assert.Equal(t, tt.image, base.image)
+			assert.Len(t, base.digests, tt.wantDigests)
+		})
+	}
+}
+
+func TestRunSize_ExcludingBaseLayers(t *testing.T) {
+	baseImg, appImg := newBaseAndAppImages(t)
+	baseRef := writeLayoutImage(t, baseImg)
+	appRef := writeLayoutImage(t, appImg)
+	baseDigests := layerHashes(t, baseImg, false)
+	appDiffIDs := layerHashes(t, appImg, true)
+
+	tests := []struct {
+		name          string
+		base          *layerBase
+		wantPassed    bool
+		wantBaseCount *int
+		wantMessage   string
+	}{
+		{name: "Base layers counted", base: nil, wantPassed: false, wantMessage: "Image has more than 3 layers"},
+		{name: "Base image", base: &layerBase{image: baseRef}, wantPassed: true, wantBaseCount: new(2)},
+		{name: "Base layer digests", base: &layerBase{digests: baseDigests}, wantPassed: true, wantBaseCount: new(2)},
+		{name: "Base layer diff IDs", base: &layerBase{digests: appDiffIDs[:2]}, wantPassed: true, wantBaseCount: new(2)},
+		{name: "Only leading layers match", base: &layerBase{digests: appDiffIDs[1:2]}, wantPassed: false, wantBaseCount: new(0), wantMessage: "Image has more than 3 layers on top of its base image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runSize(context.Background(), appRef, 100, 3, 0, tt.base)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, result.Message)
+			}
+			details := result.Details.(output.SizeDetails)
+			assert.Equal(t, 5, details.LayerCount)
+			assert.Equal(t, tt.wantBaseCount, details.BaseLayerCount)
+		})
+	}
+}
+
+func TestRunSize_BaseImageNotFound(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{layerCount: 1})
+
+	_, err := runSize(context.Background(), imageRef, 100, 3, 0, &layerBase{image: "oci:/nonexistent/base:latest"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error retrieving the base image")
+}
@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/smoke"
+	"github.com/spf13/cobra"
+)
+
+// defaultSmokeTimeout is how long the container of the smoke check may take
+// to exit.
+const defaultSmokeTimeout = 30 * time.Second
+
+// Default resource limits of the container of the smoke check, so that an
+// image under test cannot exhaust the host running the validation.
+const (
+	defaultSmokeMemoryMB  uint = 512
+	defaultSmokePidsLimit uint = 256
+)
+
+// Modes of the smoke check.
+const (
+	smokeModeExit    = "exit"
+	smokeModeHealthy = "healthy"
+)
+
+// checkSmokeFlag enables the opt-in smoke check of the all command.
+var checkSmokeFlag bool
+var smokeCommand string
+var smokeTimeout = defaultSmokeTimeout
+var smokeHealthyFor time.Duration
+var smokeMemory = defaultSmokeMemoryMB
+var smokePidsLimit = defaultSmokePidsLimit
+
+// smokeLimits are the resource limits of the container of the smoke check,
+// 0 for no limit.
+type smokeLimits struct {
+	memoryMB  uint
+	pidsLimit uint
+}
+
+// resolveSmokeImage pins the image of the smoke check. Tests replace it, as
+// they have no daemon or registry to load the image from.
+var resolveSmokeImage = pinSmokeImage
+
+// newSmokeRuntime connects to the container runtime of the smoke check. Tests
+// replace it with a fake runtime.
+var newSmokeRuntime = smoke.NewDockerRuntime
+
+var smokeCmd = &cobra.Command{
+	Use:   "smoke image",
+	Short: "Validate that the image starts by running it in the local Docker daemon",
+	Long: `Validate that the image starts by running it as a container of the local Docker
+daemon.
+
+By default, the container must exit with code 0 within --timeout (default
+30s), which suits images whose command can be replaced with a quick
+self-test, such as --command "/app --version". With --healthy-for, the
+container must instead keep running for that long, and a container whose image
+declares a HEALTHCHECK must not report unhealthy in that time.
+
+The container runs without network access, with no-new-privileges, and with
+at most --memory MB of memory (default 512) and --pids-limit processes
+(default 256), 0 for no limit; it is removed afterwards. The container is
+created from the ID of the image the check resolved, so a tag that moves
+during the validation does not change what runs; an image the daemon does not
+have is pulled by digest first. Images
+of the oci, oci-archive, and docker-archive transports cannot be run and are
+skipped.
+
+The daemon is selected with the standard Docker environment variables
+(DOCKER_HOST, DOCKER_CERT_PATH, DOCKER_TLS_VERIFY).`,
+	Example: `  check-image smoke registry.example.com/app:1.0 --command "/app --version"
+  check-image smoke nginx:latest --healthy-for 10s
+  check-image smoke registry.example.com/job:1.0 --timeout 2m -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := validateSmokeDurations(smokeTimeout, smokeHealthyFor); err != nil {
+			return err
+		}
+		return runCheckCmd(checkSmoke, func(ctx context.Context, img string) (*output.CheckResult, error) {
+			return runSmoke(ctx, img, strings.Fields(smokeCommand), smokeTimeout, smokeHealthyFor, smokeLimits{smokeMemory, smokePidsLimit})
+		}, ctx, args[0], OutputFmt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(smokeCmd)
+	smokeCmd.Flags().StringVar(&smokeCommand, "command", "", "Command run instead of the CMD of the image, split on whitespace (optional)")
+	smokeCmd.Flags().DurationVar(&smokeTimeout, "timeout", defaultSmokeTimeout, "Time the container may take to exit (optional)")
+	smokeCmd.Flags().DurationVar(&smokeHealthyFor, "healthy-for", 0, "Require the container to keep running, and stay healthy, for this long instead of exiting (optional)")
+	smokeCmd.Flags().UintVar(&smokeMemory, "memory", defaultSmokeMemoryMB, "Memory limit of the container in megabytes, 0 for no limit (optional)")
+	smokeCmd.Flags().UintVar(&smokePidsLimit, "pids-limit", defaultSmokePidsLimit, "Maximum number of processes of the container, 0 for no limit (optional)")
+}
+
+// validateSmokeDurations rejects durations the smoke check cannot wait for.
+func validateSmokeDurations(timeout, healthyFor time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("invalid smoke timeout %s, expected a positive duration", timeout)
+	}
+	if healthyFor < 0 {
+		return fmt.Errorf("invalid smoke healthy-for %s, expected a positive duration", healthyFor)
+	}
+	return nil
+}
+
+func runSmoke(ctx context.Context, imageName string, command []string, timeout, healthyFor time.Duration, limits smokeLimits) (*output.CheckResult, error) {
+	ref, err := imageutil.ParseReference(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse image reference: %w", err)
+	}
+	details := output.SmokeDetails{Command: command, Mode: smokeModeExit, MemoryMB: limits.memoryMB, PidsLimit: limits.pidsLimit}
+	if healthyFor > 0 {
+		details.Mode = smokeModeHealthy
+		details.HealthyForSeconds = healthyFor.Seconds()
+	} else {
+		details.TimeoutSeconds = timeout.Seconds()
+	}
+	if ref.Transport != imageutil.TransportDaemonRegistry {
+		return &output.CheckResult{
+			Check:      checkSmoke,
+			Image:      imageName,
+			Passed:     true,
+			Message:    "Smoke test skipped (not applicable for this transport)",
+			Details:    details,
+			Skipped:    true,
+			SkipReason: output.SkipReasonNotApplicable,
+		}, nil
+	}
+
+	pinned, imageID, err := resolveSmokeImage(ctx, imageName, ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	details.ImageID = imageID
+
+	runtime, err := newSmokeRuntime()
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := runtime.Run(ctx, smoke.Spec{
+		Image:      pinned,
+		ImageID:    imageID,
+		Command:    command,
+		Timeout:    timeout,
+		HealthyFor: healthyFor,
+		Platform:   imagePlatform,
+		MemoryMB:   limits.memoryMB,
+		PidsLimit:  int64(limits.pidsLimit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("smoke test failed to run: %w", err)
+	}
+
+	details.ExitCode = outcome.ExitCode
+	details.Health = outcome.Health
+	details.TimedOut = outcome.TimedOut
+	details.Pulled = outcome.Pulled
+	details.ElapsedSeconds = outcome.Elapsed.Seconds()
+	details.Logs = outcome.Logs
+
+	passed, msg := smokeVerdict(outcome, timeout, healthyFor)
+	return &output.CheckResult{
+		Check:   checkSmoke,
+		Image:   imageName,
+		Passed:  passed,
+		Message: msg,
+		Details: details,
+	}, nil
+}
+
+// pinSmokeImage loads the image the other checks validate, from the daemon or
+// the registry, once per validation. It returns repository pinned to the
+// manifest digest of the image, to pull it with, and the image ID, to run it
+// with.
+func pinSmokeImage(ctx context.Context, imageName, repository string) (string, string, error) {
+	img, cleanup, err := imageutil.GetImage(ctx, imageName)
+	if err != nil {
+		return "", "", err
+	}
+	defer cleanup()
+	id, err := img.ConfigName()
+	if err != nil {
+		return "", "", fmt.Errorf("error computing the image ID: %w", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", "", fmt.Errorf("error computing the image digest: %w", err)
+	}
+	ref, err := name.ParseReference(repository)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse image reference: %w", err)
+	}
+	return ref.Context().Digest(digest.String()).Name(), id.String(), nil
+}
+
+// smokeVerdict decides whether the outcome of a smoke run passes. Messages do
+// not include the run time, which is in the details, so that they are the
+// same across runs.
+func smokeVerdict(o *smoke.Outcome, timeout, healthyFor time.Duration) (bool, string) {
+	if healthyFor == 0 {
+		switch {
+		case o.TimedOut || o.ExitCode == nil:
+			return false, fmt.Sprintf("Container did not exit within %s", timeout)
+		case *o.ExitCode != 0:
+			return false, fmt.Sprintf("Container exited with code %d", *o.ExitCode)
+		}
+		return true, "Container exited with code 0"
+	}
+
+	switch {
+	case o.ExitCode != nil:
+		return false, fmt.Sprintf("Container exited with code %d before running for %s", *o.ExitCode, healthyFor)
+	case o.Health == "unhealthy":
+		return false, fmt.Sprintf("Container became unhealthy within %s", healthyFor)
+	case o.Health != "":
+		return true, fmt.Sprintf("Container kept running for %s (health: %s)", healthyFor, o.Health)
+	}
+	return true, fmt.Sprintf("Container kept running for %s", healthyFor)
+}
+
+// validateSmokeConfig checks the durations of checks.smoke of cfg.
+func validateSmokeConfig(cfg *allConfig) error {
+	if cfg.Checks.Smoke == nil {
+		return nil
+	}
+	_, _, err := cfg.Checks.Smoke.durations()
+	return err
+}
+
+// durations returns the timeout and healthy-for of the config, with the
+// defaults of the flags for those not set.
+func (c *smokeCheckConfig) durations() (time.Duration, time.Duration, error) {
+	timeout, healthyFor := defaultSmokeTimeout, time.Duration(0)
+	var err error
+	if c.Timeout != "" {
+		if timeout, err = time.ParseDuration(c.Timeout); err != nil || timeout <= 0 {
+			return 0, 0, fmt.Errorf("invalid checks.smoke.timeout %q, expected a duration such as 30s", c.Timeout)
+		}
+	}
+	if c.HealthyFor != "" {
+		if healthyFor, err = time.ParseDuration(c.HealthyFor); err != nil || healthyFor < 0 {
+			return 0, 0, fmt.Errorf("invalid checks.smoke.healthy-for %q, expected a duration such as 10s", c.HealthyFor)
+		}
+	}
+	return timeout, healthyFor, nil
+}
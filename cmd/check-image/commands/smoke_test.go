@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/smoke"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSmokeRuntime records the spec it is run with and returns a fixed
+// outcome.
+type fakeSmokeRuntime struct {
+	spec    smoke.Spec
+	outcome *smoke.Outcome
+	err     error
+}
+
+func (f *fakeSmokeRuntime) Run(_ context.Context, spec smoke.Spec) (*smoke.Outcome, error) {
+	f.spec = spec
+	return f.outcome, f.err
+}
+
+func useFakeSmokeRuntime(t *testing.T, outcome *smoke.Outcome, err error) *fakeSmokeRuntime {
+	t.Helper()
+	fake := &fakeSmokeRuntime{outcome: outcome, err: err}
+	orig, origResolve := newSmokeRuntime, resolveSmokeImage
+	newSmokeRuntime = func() (smoke.Runtime, error) { return fake, nil }
+	resolveSmokeImage = func(_ context.Context, _, repository string) (string, string, error) {
+		return repository + "@" + testSmokeDigest, testSmokeImageID, nil
+	}
+	t.Cleanup(func() { newSmokeRuntime, resolveSmokeImage = orig, origResolve })
+	return fake
+}
+
+const (
+	testSmokeDigest  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testSmokeImageID = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// defaultSmokeLimits are the default resource limits of the smoke check.
+var defaultSmokeLimits = smokeLimits{defaultSmokeMemoryMB, defaultSmokePidsLimit}
+
+func TestSmokeCommand(t *testing.T) {
+	assert.Equal(t, "smoke image", smokeCmd.Use)
+	assert.Error(t, smokeCmd.Args(smokeCmd, []string{}))
+	assert.NoError(t, smokeCmd.Args(smokeCmd, []string{"image"}))
+
+	flag := smokeCmd.Flags().Lookup("timeout")
+	require.NotNil(t, flag)
+	assert.Equal(t, "30s", flag.DefValue)
+	assert.NotNil(t, smokeCmd.Flags().Lookup("command"))
+	assert.NotNil(t, smokeCmd.Flags().Lookup("healthy-for"))
+	assert.Equal(t, "512", smokeCmd.Flags().Lookup("memory").DefValue)
+	assert.Equal(t, "256", smokeCmd.Flags().Lookup("pids-limit").DefValue)
+}
+
+func TestRunSmoke_Exit(t *testing.T) {
+	fake := useFakeSmokeRuntime(t, &smoke.Outcome{ExitCode: new(0), Elapsed: 1200 * time.Millisecond, Logs: []string{"app 1.0"}}, nil)
+
+	result, err := runSmoke(context.Background(), "app:1.0", []string{"/app", "--version"}, defaultSmokeTimeout, 0, defaultSmokeLimits)
+	require.NoError(t, err)
+	assert.Equal(t, checkSmoke, result.Check)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "Container exited with code 0", result.Message)
+	assert.Equal(t, smoke.Spec{
+		Image:     "app:1.0@" + testSmokeDigest,
+		ImageID:   testSmokeImageID,
+		Command:   []string{"/app", "--version"},
+		Timeout:   defaultSmokeTimeout,
+		MemoryMB:  512,
+		PidsLimit: 256,
+	}, fake.spec, "the container runs the resolved image, with resource limits")
+	details := result.Details.(output.SmokeDetails)
+	assert.Equal(t, smokeModeExit, details.Mode)
+	assert.InDelta(t, 30.0, details.TimeoutSeconds, 0.001)
+	assert.InDelta(t, 1.2, details.ElapsedSeconds, 0.001)
+	assert.Equal(t, []string{"app 1.0"}, details.Logs)
+	assert.Equal(t, testSmokeImageID, details.ImageID)
+	assert.Equal(t, uint(512), details.MemoryMB)
+	assert.Equal(t, uint(256), details.PidsLimit)
+
+	fake.outcome = &smoke.Outcome{ExitCode: new(127)}
+	result, err = runSmoke(context.Background(), "app", nil, defaultSmokeTimeout, 0, smokeLimits{})
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "Container exited with code 127", result.Message)
+	assert.Equal(t, "app:latest@"+testSmokeDigest, fake.spec.Image)
+	assert.Zero(t, fake.spec.MemoryMB, "0 disables the limits")
+	assert.Zero(t, fake.spec.PidsLimit)
+
+	fake.outcome = &smoke.Outcome{TimedOut: true}
+	result, err = runSmoke(context.Background(), "app:1.0", nil, 5*time.Second, 0, defaultSmokeLimits)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "Container did not exit within 5s", result.Message)
+}
+
+func TestRunSmoke_Healthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome smoke.Outcome
+		passed  bool
+		message string
+	}{
+		{"kept running", smoke.Outcome{}, true, "Container kept running for 10s"},
+		{"healthy", smoke.Outcome{Health: "healthy"}, true, "Container kept running for 10s (health: healthy)"},
+		{"exited", smoke.Outcome{ExitCode: new(0)}, false, "Container exited with code 0 before running for 10s"},
+		{"unhealthy", smoke.Outcome{Health: "unhealthy"}, false, "Container became unhealthy within 10s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeSmokeRuntime(t, &tt.outcome, nil)
+			result, err := runSmoke(context.Background(), "app:1.0", nil, defaultSmokeTimeout, 10*time.Second, defaultSmokeLimits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.passed, result.Passed)
+			assert.Equal(t, tt.message, result.Message)
+			assert.Equal(t, smokeModeHealthy, result.Details.(output.SmokeDetails).Mode)
+		})
+	}
+}
+
+func TestRunSmoke_NotApplicable(t *testing.T) {
+	useFakeSmokeRuntime(t, nil, errors.New("must not run"))
+	result, err := runSmoke(context.Background(), "oci:/path/to/layout:1.0", nil, defaultSmokeTimeout, 0, defaultSmokeLimits)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.True(t, result.Skipped)
+	assert.Equal(t, output.SkipReasonNotApplicable, result.SkipReason)
+}
+
+func TestRunSmoke_RuntimeError(t *testing.T) {
+	useFakeSmokeRuntime(t, nil, errors.New("Cannot connect to the Docker daemon"))
+	_, err := runSmoke(context.Background(), "app:1.0", nil, defaultSmokeTimeout, 0, defaultSmokeLimits)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "smoke test failed to run")
+}
+
+func TestPinSmokeImage(t *testing.T) {
+	imageRef := createTestImage(t, testImageOptions{})
+	img, cleanup, err := imageutil.GetImage(context.Background(), imageRef)
+	require.NoError(t, err)
+	defer cleanup()
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	id, err := img.ConfigName()
+	require.NoError(t, err)
+
+	pinned, imageID, err := pinSmokeImage(context.Background(), imageRef, "registry.example.com/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.example.com/app@"+digest.String(), pinned)
+	assert.Equal(t, id.String(), imageID)
+}
+
+func TestValidateSmokeDurations(t *testing.T) {
+	assert.NoError(t, validateSmokeDurations(time.Second, 0))
+	assert.Error(t, validateSmokeDurations(0, 0))
+	assert.Error(t, validateSmokeDurations(time.Second, -time.Second))
+}
+
+func TestBuildCheckDefs_SmokeOptIn(t *testing.T) {
+	resetAllGlobals(t)
+	enabled := func(p checkParams, cfg *allConfig) bool {
+		for _, def := range buildCheckDefs(cfg, p) {
+			if def.name == checkSmoke {
+				return def.enabled
+			}
+		}
+		t.Fatal("smoke check not defined")
+		return false
+	}
+
+	assert.False(t, enabled(checkParams{}, nil))
+	assert.True(t, enabled(checkParams{checkSmoke: true}, nil))
+	assert.False(t, enabled(checkParams{checkSmoke: true}, &allConfig{}))
+	assert.True(t, enabled(checkParams{}, &allConfig{Checks: allChecksConfig{Smoke: &smokeCheckConfig{}}}))
+}
+
+func TestApplySmokeConfig(t *testing.T) {
+	resetAllGlobals(t)
+	cfg, err := parseAllConfig([]byte("checks:\n  smoke:\n    command: [/app, --self-test]\n    healthy-for: 15s\n"), "config.yaml")
+	require.NoError(t, err)
+	applySmokeConfig(allCmd, cfg.Checks.Smoke)
+	assert.Equal(t, "/app --self-test", smokeCommand)
+	assert.Equal(t, defaultSmokeTimeout, smokeTimeout)
+	assert.Equal(t, 15*time.Second, smokeHealthyFor)
+
+	resetAllGlobals(t)
+	cfg, err = parseAllConfig([]byte(`{"checks": {"smoke": {"command": "/app --version", "timeout": "1m"}}}`), "config.json")
+	require.NoError(t, err)
+	applySmokeConfig(allCmd, cfg.Checks.Smoke)
+	assert.Equal(t, "/app --version", smokeCommand)
+	assert.Equal(t, time.Minute, smokeTimeout)
+	assert.Equal(t, defaultSmokeMemoryMB, smokeMemory)
+
+	resetAllGlobals(t)
+	cfg, err = parseAllConfig([]byte("checks:\n  smoke:\n    memory: 1024\n    pids-limit: 0\n"), "config.yaml")
+	require.NoError(t, err)
+	applySmokeConfig(allCmd, cfg.Checks.Smoke)
+	assert.Equal(t, uint(1024), smokeMemory)
+	assert.Equal(t, uint(0), smokePidsLimit)
+
+	for _, config := range []string{
+		`{"checks": {"smoke": {"timeout": "soon"}}}`,
+		`{"checks": {"smoke": {"timeout": "0s"}}}`,
+		`{"checks": {"smoke": {"healthy-for": "-1s"}}}`,
+	} {
+		_, err := parseAllConfig([]byte(config), "config.json")
+		require.Error(t, err, config)
+		assert.Contains(t, err.Error(), "invalid checks.smoke.", config)
+	}
+}
+
+func TestRenderSmokeText(t *testing.T) {
+	result := &output.CheckResult{
+		Check:   checkSmoke,
+		Image:   "app:1.0",
+		Message: "Container exited with code 1",
+		Details: output.SmokeDetails{
+			Command:  []string{"/app", "--version"},
+			Mode:     smokeModeExit,
+			ExitCode: new(1),
+			Pulled:   true,
+			Logs:     []string{"error: missing config"},
+		},
+	}
+
+	captured := captureStdout(t, func() { renderSmokeText(stdout, result) })
+	assert.Contains(t, captured, "Running smoke test of image app:1.0")
+	assert.Contains(t, captured, "Command: /app --version")
+	assert.Contains(t, captured, "Image pulled into the daemon")
+	assert.Contains(t, captured, "error: missing config")
+	assert.Contains(t, captured, "Container exited with code 1")
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status image",
+	Short: "Report the last validation recorded in the registry for an image",
+	Long: `Look up the validation markers (all --annotate-registry) and report
+attestations (promote --attest) attached to the image digest as OCI referrers,
+and report when the image was last validated, against which policy hash, and
+with which outcome. No checks are run.
+
+Markers are referrers of the image manifest, so they are garbage-collected by
+the registry together with the image.
+
+Exit codes: 0 when the last recorded validation passed, 1 when it failed or no
+validation was recorded, 2 on errors.`,
+	Example: `  check-image status registry.example.com/app:1.0
+  check-image status registry.example.com/app@sha256:abc... -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := runStatus(ctx, args[0]); err != nil {
+			return fmt.Errorf("status operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(ctx context.Context, imageName string) error {
+	if _, err := imageutil.ParseDestination(imageName); err != nil {
+		return fmt.Errorf("status requires a registry image reference: %w", err)
+	}
+	subject, referrers, err := imageutil.GetReferrers(ctx, imageName, validationArtifactType, reportArtifactType)
+	if err != nil {
+		return err
+	}
+
+	result := output.StatusResult{
+		Image:   imageName,
+		Digest:  subject.Digest.String(),
+		Markers: len(referrers),
+	}
+	if latest := latestValidation(referrers); latest != nil {
+		result.Validated = true
+		result.Passed = latest.Annotations[annotationPassed] == "true"
+		result.ValidatedAt = latest.Annotations[annotationCreated]
+		result.PolicyHash = latest.Annotations[annotationPolicyHash]
+		result.Marker = latest.Digest.String()
+	}
+	result.Message = statusMessage(result)
+
+	if result.Validated && result.Passed {
+		UpdateResult(ValidationSucceeded)
+	} else {
+		UpdateResult(ValidationFailed)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	printStatusResult(result)
+	return nil
+}
+
+// latestValidation returns the referrer with the most recent creation
+// annotation. RFC 3339 UTC timestamps sort lexically; referrers without one
+// sort first, so they are only used when nothing else is recorded.
+func latestValidation(referrers []imageutil.Referrer) *imageutil.Referrer {
+	var latest *imageutil.Referrer
+	for i := range referrers {
+		r := &referrers[i]
+		if latest == nil || r.Annotations[annotationCreated] > latest.Annotations[annotationCreated] {
+			latest = r
+		}
+	}
+	return latest
+}
+
+func statusMessage(r output.StatusResult) string {
+	switch {
+	case !r.Validated:
+		return "No validation recorded for this image"
+	case r.Passed:
+		return "Image passed its last recorded validation"
+	default:
+		return "Image failed its last recorded validation"
+	}
+}
+
+func printStatusResult(r output.StatusResult) {
+	fmt.Printf("%s%s\n", statusPrefix(r.Validated && r.Passed), r.Message)
+	fmt.Printf("  Image: %s@%s\n", r.Image, r.Digest)
+	if !r.Validated {
+		return
+	}
+	if r.ValidatedAt != "" {
+		fmt.Printf("  Validated at: %s\n", r.ValidatedAt)
+	}
+	if r.PolicyHash != "" {
+		fmt.Printf("  Policy hash: %s\n", r.PolicyHash)
+	}
+	fmt.Printf("  Marker: %s (%d recorded)\n", r.Marker, r.Markers)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushStatusTestImage pushes a test image to a new registry and returns its
+// reference and descriptor.
+func pushStatusTestImage(t *testing.T) (string, *cr.Descriptor) {
+	t.Helper()
+	image := newTestRegistry(t) + "/org/app:1.0"
+	desc, err := imageutil.CopyImage(context.Background(), createTestImage(t, testImageOptions{created: time.Now().Add(-24 * time.Hour)}), image)
+	require.NoError(t, err)
+	return image, desc
+}
+
+func attachTestMarker(t *testing.T, image string, subject *cr.Descriptor, annotations map[string]string) string {
+	t.Helper()
+	digest, err := imageutil.AttachArtifact(context.Background(), image, *subject, validationArtifactType, reportMediaType, []byte(`{}`), annotations)
+	require.NoError(t, err)
+	return digest.String()
+}
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		markers       []map[string]string
+		wantValidated bool
+		wantPassed    bool
+		wantMarker    int
+		wantResult    ValidationResult
+	}{
+		{
+			name:       "No markers",
+			wantResult: ValidationFailed,
+		},
+		{
+			name: "Latest marker passed",
+			markers: []map[string]string{
+				{annotationPassed: "false", annotationCreated: "2026-01-01T00:00:00Z", annotationPolicyHash: "sha256:old"},
+				{annotationPassed: "true", annotationCreated: "2026-02-01T00:00:00Z", annotationPolicyHash: "sha256:new"},
+			},
+			wantValidated: true,
+			wantPassed:    true,
+			wantMarker:    1,
+			wantResult:    ValidationSucceeded,
+		},
+		{
+			name: "Latest marker failed",
+			markers: []map[string]string{
+				{annotationPassed: "false", annotationCreated: "2026-03-01T00:00:00Z", annotationPolicyHash: "sha256:new"},
+				{annotationPassed: "true", annotationCreated: "2026-02-01T00:00:00Z", annotationPolicyHash: "sha256:old"},
+			},
+			wantValidated: true,
+			wantPassed:    false,
+			wantMarker:    0,
+			wantResult:    ValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAllGlobals(t)
+			OutputFmt = output.FormatJSON
+			image, desc := pushStatusTestImage(t)
+			var digests []string
+			for _, m := range tt.markers {
+				digests = append(digests, attachTestMarker(t, image, desc, m))
+			}
+
+			out := captureStdout(t, func() {
+				require.NoError(t, runStatus(context.Background(), image))
+			})
+
+			var result output.StatusResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			assert.Equal(t, desc.Digest.String(), result.Digest)
+			assert.Equal(t, tt.wantValidated, result.Validated)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Equal(t, len(tt.markers), result.Markers)
+			assert.Equal(t, tt.wantResult, Result)
+			if tt.wantValidated {
+				want := tt.markers[tt.wantMarker]
+				assert.Equal(t, digests[tt.wantMarker], result.Marker)
+				assert.Equal(t, want[annotationCreated], result.ValidatedAt)
+				assert.Equal(t, want[annotationPolicyHash], result.PolicyHash)
+			}
+		})
+	}
+}
+
+func TestRunStatus_AfterAnnotateRegistry(t *testing.T) {
+	resetAllGlobals(t)
+	includeChecks = "age"
+	annotateRegistry = true
+	image, _ := pushStatusTestImage(t)
+	captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, image))
+	})
+
+	resetAllGlobals(t)
+	out := captureStdout(t, func() {
+		require.NoError(t, runStatus(context.Background(), image))
+	})
+
+	assert.Equal(t, ValidationSucceeded, Result)
+	assert.Contains(t, out, "Image passed its last recorded validation")
+	assert.Contains(t, out, "Validated at: "+time.Now().UTC().Format("2006-01-02"))
+	assert.Contains(t, out, "Policy hash: sha256:")
+	assert.Contains(t, out, "(1 recorded)")
+}
+
+func TestRunStatus_RequiresRegistryImage(t *testing.T) {
+	resetAllGlobals(t)
+	err := runStatus(context.Background(), createTestImage(t, testImageOptions{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status requires a registry image reference")
+}
@@ -29,6 +29,11 @@ var (
 // termOut stores the output writer supplied to initRenderer for terminal width detection.
 var termOut io.Writer
 
+// hyperlinks reports whether text output may contain OSC 8 hyperlinks. It is
+// only enabled for color-capable terminals, so pipes and CI logs never receive
+// the escape sequences.
+var hyperlinks bool
+
 func init() {
 	// Pre-initialize styles with auto-detected color so that FailStyle/PassStyle
 	// render correctly even when PersistentPreRunE does not run (e.g. Cobra
@@ -60,6 +65,14 @@ func initRenderer(colorMode string, out io.Writer) {
 	sectionStyle = r.NewStyle().Bold(true).Foreground(lipgloss.Color("12")) // bright blue
 	valueStyle = r.NewStyle().Foreground(lipgloss.Color("6"))               // cyan
 	dimStyle = r.NewStyle().Faint(true)
+
+	hyperlinks = r.ColorProfile() != termenv.Ascii && isTerminal(out)
+}
+
+// isTerminal reports whether out is a terminal.
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && cterm.IsTerminal(f.Fd())
 }
 
 // terminalWidth returns the width of the terminal associated with termOut,
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// sizeUnits selects how sizes are written in text output and messages; see
+// output.Units.
+var sizeUnits = string(output.UnitsMB)
+
+// formatSize writes a byte count in the selected units.
+func formatSize(bytes int64) string {
+	return output.FormatBytes(bytes, output.Units(sizeUnits))
+}
+
+// formatSizeDetail writes a size of the details of a result, which carry both
+// the byte count and its value in megabytes.
+func formatSizeDetail(bytes int64, mb float64) string {
+	if output.Units(sizeUnits) == output.UnitsMB {
+		return fmt.Sprintf("%.2f MB", mb)
+	}
+	return formatSize(bytes)
+}
+
+// formatSizeLimit writes a limit given in megabytes in the selected units.
+// With the default units the limit is written as configured, e.g. "500 MB".
+func formatSizeLimit(mb uint) string {
+	bytes, err := megabytesToBytes("limit", mb)
+	if err != nil || output.Units(sizeUnits) == output.UnitsMB {
+		return fmt.Sprintf("%d MB", mb)
+	}
+	return formatSize(bytes)
+}
+
+// applyUnitsConfig applies the units of a config file. The returned cleanup
+// restores the previous units, so that the config of one image does not leak
+// into the next validation of a bulk or daemon-watch run.
+func applyUnitsConfig(cmd *cobra.Command, units string) (func(), error) {
+	if units == "" || cmd.Flags().Changed("units") {
+		return func() {}, nil
+	}
+	u, err := output.ParseUnits(units)
+	if err != nil {
+		return func() {}, fmt.Errorf("invalid units in config file: %w", err)
+	}
+	previous := sizeUnits
+	sizeUnits = string(u)
+	return func() { sizeUnits = previous }, nil
+}
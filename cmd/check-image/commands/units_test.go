@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeMessage_Units(t *testing.T) {
+	d := output.SizeDetails{MaxSizeMB: 500, MaxLayers: 20, MaxTotalSizeMB: 2048, Platforms: 2}
+
+	tests := []struct {
+		units string
+		want  string
+	}{
+		{"mb", "Image size exceeds the recommended limit of 500 MB and total size of all platforms (2) exceeds the limit of 2048 MB"},
+		{"iec", "Image size exceeds the recommended limit of 500.00 MiB and total size of all platforms (2) exceeds the limit of 2.00 GiB"},
+		{"si", "Image size exceeds the recommended limit of 524.29 MB and total size of all platforms (2) exceeds the limit of 2.15 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			resetAllGlobals(t)
+			sizeUnits = tt.units
+			assert.Equal(t, tt.want, sizeMessage(true, false, false, d))
+		})
+	}
+}
+
+func TestRenderSizeText_Units(t *testing.T) {
+	resetAllGlobals(t)
+	sizeUnits = string(output.UnitsIEC)
+	result := &output.CheckResult{
+		Check:  checkSize,
+		Image:  "alpine:latest",
+		Passed: true,
+		Details: output.SizeDetails{
+			LayerCount: 1,
+			MaxLayers:  20,
+			Layers:     []output.LayerInfo{{Index: 1, Bytes: 3 * 1024 * 1024 * 1024}},
+			TotalBytes: 3 * 1024 * 1024 * 1024,
+			TotalMB:    3072,
+			MaxSizeMB:  5000,
+		},
+	}
+
+	captured := captureStdout(t, func() {
+		renderSizeText(stdout, result)
+	})
+
+	assert.Contains(t, captured, "Layer 1: 3221225472 bytes (3.00 GiB)")
+	assert.Contains(t, captured, "Total size: 3221225472 bytes (3.00 GiB)")
+}
+
+func TestApplyUnitsConfig(t *testing.T) {
+	t.Run("Config value is applied", func(t *testing.T) {
+		resetAllGlobals(t)
+		cleanup, err := applyUnitsConfig(&cobra.Command{}, "IEC")
+		require.NoError(t, err)
+		assert.Equal(t, "iec", sizeUnits)
+		cleanup()
+		assert.Equal(t, "mb", sizeUnits, "the cleanup restores the previous units")
+	})
+
+	t.Run("Cleanup of applyConfigValues restores the units", func(t *testing.T) {
+		resetAllGlobals(t)
+		cleanup, err := applyConfigValues(&cobra.Command{}, &allConfig{Units: "si"})
+		require.NoError(t, err)
+		assert.Equal(t, "si", sizeUnits)
+		cleanup()
+		assert.Equal(t, "mb", sizeUnits)
+	})
+
+	t.Run("CLI flag takes precedence", func(t *testing.T) {
+		resetAllGlobals(t)
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVar(&sizeUnits, "units", "mb", "")
+		require.NoError(t, cmd.Flags().Set("units", "si"))
+		_, err := applyUnitsConfig(cmd, "iec")
+		require.NoError(t, err)
+		assert.Equal(t, "si", sizeUnits)
+	})
+
+	t.Run("Invalid config value", func(t *testing.T) {
+		resetAllGlobals(t)
+		_, err := applyUnitsConfig(&cobra.Command{}, "gb")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid units in config file")
+		assert.Equal(t, "mb", sizeUnits)
+	})
+}
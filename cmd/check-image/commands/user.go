@@ -3,7 +3,6 @@ package commands
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
@@ -56,7 +55,7 @@ func init() {
 	userCmd.Flags().StringVar(&userPolicy, "user-policy", "", "User policy file (JSON or YAML) (optional)")
 	userCmd.Flags().UintVar(&userMinUID, "min-uid", 0, "Minimum allowed UID (optional)")
 	userCmd.Flags().UintVar(&userMaxUID, "max-uid", 0, "Maximum allowed UID (optional)")
-	userCmd.Flags().StringVar(&blockedUsers, "blocked-users", "", "Comma-separated list of blocked usernames (optional)")
+	userCmd.Flags().StringVar(&blockedUsers, "blocked-users", "", "Comma-separated list of blocked usernames or @<file> (optional)")
 	userCmd.Flags().BoolVar(&requireNumeric, "require-numeric", false, "Require user to be a numeric UID (optional)")
 }
 
@@ -98,7 +97,11 @@ func resolveUserPolicy(cmd *cobra.Command) (*user.Policy, error) {
 		policy.MaxUID = &userMaxUID
 	}
 	if cmd.Flags().Changed("blocked-users") {
-		policy.BlockedUsers = parseBlockedUsers(blockedUsers)
+		users, err := parseBlockedUsers(blockedUsers)
+		if err != nil {
+			return nil, err
+		}
+		policy.BlockedUsers = users
 	}
 	if cmd.Flags().Changed("require-numeric") {
 		policy.RequireNumeric = &requireNumeric
@@ -112,19 +115,14 @@ func resolveUserPolicy(cmd *cobra.Command) (*user.Policy, error) {
 	return policy, nil
 }
 
-// parseBlockedUsers splits a comma-separated list of usernames, trimming whitespace.
-func parseBlockedUsers(s string) []string {
-	if s == "" {
-		return nil
-	}
-	var users []string
-	for part := range strings.SplitSeq(s, ",") {
-		name := strings.TrimSpace(part)
-		if name != "" {
-			users = append(users, name)
-		}
+// parseBlockedUsers parses the --blocked-users list (comma-separated or
+// @file, see parseListInput).
+func parseBlockedUsers(s string) ([]string, error) {
+	users, err := parseListInput(s, "blocked-users")
+	if err != nil {
+		return nil, fmt.Errorf("invalid --blocked-users: %w", err)
 	}
-	return users
+	return users, nil
 }
 
 func runUser(ctx context.Context, imageName string, policy *user.Policy) (*output.CheckResult, error) {
@@ -292,7 +292,8 @@ func TestParseBlockedUsers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseBlockedUsers(tt.input)
+			result, err := parseBlockedUsers(tt.input)
+			require.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -586,7 +587,7 @@ func TestRunUser_TextOutput_WithViolations(t *testing.T) {
 	require.NoError(t, err)
 
 	out := captureStdout(t, func() {
-		renderUserText(result)
+		renderUserText(stdout, result)
 	})
 
 	assert.Contains(t, out, "Checking user of image")
@@ -603,7 +604,7 @@ func TestRunUser_TextOutput_NotSet(t *testing.T) {
 	require.NoError(t, err)
 
 	out := captureStdout(t, func() {
-		renderUserText(result)
+		renderUserText(stdout, result)
 	})
 
 	assert.Contains(t, out, "(not set)")
@@ -617,7 +618,7 @@ func TestRunUser_TextOutput_Passing(t *testing.T) {
 	require.NoError(t, err)
 
 	out := captureStdout(t, func() {
-		renderUserText(result)
+		renderUserText(stdout, result)
 	})
 
 	assert.Contains(t, out, "User:")
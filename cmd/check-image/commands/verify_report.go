@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+var verifySignature string
+var verifyKey string
+
+var verifyReportCmd = &cobra.Command{
+	Use:   "verify-report report",
+	Short: "Verify the signature of a JSON report produced by all --sign-results",
+	Long: `Verify that a JSON report produced by "check-image all --sign-results" has not
+been altered since it was signed.
+
+The report must be byte-for-byte identical to what the all command wrote to
+stdout or --output-file. A report file compressed with gzip or zstd, such as a
+report.json.gz written with --output-file, is decompressed first, since the
+signature covers the uncompressed report. Use "-" to read the report from
+stdin. The key may be a PEM public key
+or the PEM private key used for signing.`,
+	Example: `  check-image verify-report report.json --key public.pem
+  check-image verify-report report.json --signature report.jws --key public.pem
+  check-image verify-report report.json.gz --key public.pem
+  cat report.json | check-image verify-report - --key public.pem -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runVerifyReport(args[0]); err != nil {
+			return fmt.Errorf("verify-report operation failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyReportCmd)
+	verifyReportCmd.Flags().StringVar(&verifySignature, "signature", defaultSignatureFile, "Detached JWS signature file written by --sign-results")
+	verifyReportCmd.Flags().StringVar(&verifyKey, "key", "", "PEM public key (or the signing private key) used to verify the report")
+	if err := verifyReportCmd.MarkFlagRequired("key"); err != nil {
+		panic(fmt.Sprintf("failed to mark key flag as required: %v", err))
+	}
+}
+
+func runVerifyReport(reportPath string) error {
+	data, err := fileutil.ReadFileOrStdin(reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	// Reports written to a compressed --output-file are signed before they
+	// are compressed.
+	report, err := output.Decompress(data)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	sig, err := fileutil.ReadSecureFile(verifySignature)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	pub, err := signing.LoadPublicKey(verifyKey)
+	if err != nil {
+		return fmt.Errorf("unable to load verification key: %w", err)
+	}
+
+	result := output.ReportVerificationResult{
+		Report:    reportPath,
+		Signature: verifySignature,
+	}
+
+	alg, err := signing.VerifyDetached(pub, report, string(sig))
+	switch {
+	case errors.Is(err, signing.ErrInvalidSignature):
+		result.Message = "Report signature is invalid: the report was modified or signed with a different key"
+		UpdateResult(ValidationFailed)
+	case err != nil:
+		return err
+	default:
+		result.Valid = true
+		result.Algorithm = alg
+		result.Message = fmt.Sprintf("Report signature is valid (%s)", alg)
+		UpdateResult(ValidationSucceeded)
+	}
+
+	if OutputFmt == output.FormatJSON {
+		return output.RenderJSON(os.Stdout, result)
+	}
+	fmt.Println(statusPrefix(result.Valid) + result.Message)
+	return nil
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestSigningKeys writes a fresh P-256 key pair and returns the private
+// and public key paths.
+func writeTestSigningKeys(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	privPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600))
+
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	pubPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0600))
+
+	return privPath, pubPath
+}
+
+func resetVerifyReportGlobals(t *testing.T) {
+	t.Helper()
+	resetAllGlobals(t)
+	reset := func() {
+		verifySignature = defaultSignatureFile
+		verifyKey = ""
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// signedTestReport runs the all command with --sign-results and returns the
+// paths to the written report, signature, and public key.
+func signedTestReport(t *testing.T) (string, string, string) {
+	t.Helper()
+	privPath, pubPath := writeTestSigningKeys(t)
+	dir := t.TempDir()
+
+	OutputFmt = output.FormatJSON
+	includeChecks = "healthcheck"
+	signResults = privPath
+	signatureOutput = filepath.Join(dir, "report.jws")
+
+	imageRef := createTestImage(t, testImageOptions{})
+	out := captureStdout(t, func() {
+		require.NoError(t, runAll(allCmd, imageRef))
+	})
+
+	reportPath := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(reportPath, []byte(out), 0600))
+	return reportPath, signatureOutput, pubPath
+}
+
+func TestVerifyReportCommand(t *testing.T) {
+	assert.Equal(t, "verify-report report", verifyReportCmd.Use)
+	assert.NotNil(t, verifyReportCmd.Flags().Lookup("signature"))
+	assert.NotNil(t, verifyReportCmd.Flags().Lookup("key"))
+}
+
+func TestRunVerifyReport_Valid(t *testing.T) {
+	resetVerifyReportGlobals(t)
+	reportPath, sigPath, pubPath := signedTestReport(t)
+
+	Result = ValidationSkipped
+	OutputFmt = output.FormatJSON
+	verifySignature = sigPath
+	verifyKey = pubPath
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runVerifyReport(reportPath))
+	})
+
+	var result output.ReportVerificationResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.True(t, result.Valid)
+	assert.Equal(t, "ES256", result.Algorithm)
+	assert.Equal(t, ValidationSucceeded, Result)
+}
+
+func TestRunVerifyReport_CompressedOutputFile(t *testing.T) {
+	for _, ext := range []string{".json.gz", ".json.zst"} {
+		t.Run(ext, func(t *testing.T) {
+			resetVerifyReportGlobals(t)
+			privPath, pubPath := writeTestSigningKeys(t)
+			dir := t.TempDir()
+
+			OutputFmt = output.FormatJSON
+			includeChecks = "healthcheck"
+			signResults = privPath
+			signatureOutput = filepath.Join(dir, "report.jws")
+			outputFile = filepath.Join(dir, "report"+ext)
+
+			imageRef := createTestImage(t, testImageOptions{})
+			require.NoError(t, withReportFile(func() error { return runAll(allCmd, imageRef) }))
+			data, err := os.ReadFile(outputFile)
+			require.NoError(t, err)
+			require.False(t, json.Valid(data), "the report file is compressed")
+
+			Result = ValidationSkipped
+			verifySignature = signatureOutput
+			verifyKey = pubPath
+			out := captureStdout(t, func() {
+				require.NoError(t, runVerifyReport(outputFile))
+			})
+
+			var result output.ReportVerificationResult
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			assert.True(t, result.Valid, result.Message)
+			assert.Equal(t, ValidationSucceeded, Result)
+		})
+	}
+}
+
+func TestRunVerifyReport_Tampered(t *testing.T) {
+	resetVerifyReportGlobals(t)
+	reportPath, sigPath, pubPath := signedTestReport(t)
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(data), `"passed": false`, `"passed": true`, 1)
+	require.NotEqual(t, string(data), tampered)
+	require.NoError(t, os.WriteFile(reportPath, []byte(tampered), 0600))
+
+	Result = ValidationSkipped
+	OutputFmt = output.FormatText
+	verifySignature = sigPath
+	verifyKey = pubPath
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runVerifyReport(reportPath))
+	})
+
+	assert.Contains(t, out, "Report signature is invalid")
+	assert.Equal(t, ValidationFailed, Result)
+}
+
+func TestRunVerifyReport_MissingSignature(t *testing.T) {
+	resetVerifyReportGlobals(t)
+	_, pubPath := writeTestSigningKeys(t)
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(reportPath, []byte("{}\n"), 0600))
+
+	verifySignature = filepath.Join(t.TempDir(), "missing.jws")
+	verifyKey = pubPath
+
+	err := runVerifyReport(reportPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read signature")
+}
+
+func TestRunAll_SignResultsRequiresJSON(t *testing.T) {
+	resetAllGlobals(t)
+	privPath, _ := writeTestSigningKeys(t)
+	signResults = privPath
+	includeChecks = "healthcheck"
+
+	err := runAll(allCmd, createTestImage(t, testImageOptions{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--sign-results requires --output json")
+}
+
+func TestRunAll_SignResultsInvalidKey(t *testing.T) {
+	resetAllGlobals(t)
+	OutputFmt = output.FormatJSON
+	signResults = filepath.Join(t.TempDir(), "missing.pem")
+	includeChecks = "healthcheck"
+
+	err := runAll(allCmd, createTestImage(t, testImageOptions{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load signing key")
+}
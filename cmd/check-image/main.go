@@ -9,25 +9,43 @@ import (
 	"syscall"
 
 	"github.com/jarfernandez/check-image/cmd/check-image/commands"
+	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
 )
 
+// executionErrorExitCode returns the exit code of an execution error: 3 when
+// the registry rejected the credentials, 4 when the image does not exist, and
+// 2 otherwise, including when several errors of different kinds occurred.
+func executionErrorExitCode(kind imageutil.ErrorKind) int {
+	switch kind {
+	case imageutil.ErrorKindUnauthorized:
+		return 3
+	case imageutil.ErrorKindNotFound:
+		return 4
+	}
+	return 2
+}
+
 // exitResult maps an ExecuteResult to an exit code and prints the final
 // status message when appropriate.
 func exitResult(result commands.ExecuteResult, stdout io.Writer) int {
-	// Execution error has the highest priority — exit code 2.
+	// Execution error has the highest priority — exit code 2, 3 or 4.
 	// The detailed error message is already logged to stderr by Execute().
 	if result.Validation == commands.ExecutionError {
-		if result.Format != output.FormatJSON {
-			if _, err := fmt.Fprintln(stdout, commands.FailStyle.Render("Execution error")); err != nil {
+		if result.Format == output.FormatText {
+			msg := "Execution error"
+			if result.ErrorKind != "" {
+				msg += fmt.Sprintf(" (%s)", result.ErrorKind)
+			}
+			if _, err := fmt.Fprintln(stdout, commands.FailStyle.Render(msg)); err != nil {
 				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
 			}
 		}
-		return 2
+		return executionErrorExitCode(result.ErrorKind)
 	}
 
-	// In JSON mode, suppress the final text message (already in JSON)
-	if result.Format == output.FormatJSON {
+	// In JSON and CSV modes, suppress the final text message (already in the output)
+	if result.Format != output.FormatText {
 		if result.Validation == commands.ValidationFailed {
 			return 1
 		}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jarfernandez/check-image/cmd/check-image/commands"
+	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/output"
 	"github.com/stretchr/testify/assert"
 )
@@ -178,6 +179,14 @@ func TestExitResult_JSONMode_SuppressesTextOutput(t *testing.T) {
 	}
 }
 
+func TestExitResult_CSVMode_SuppressesTextOutput(t *testing.T) {
+	for _, validation := range []commands.ValidationResult{commands.ValidationSucceeded, commands.ValidationFailed, commands.ExecutionError} {
+		var buf bytes.Buffer
+		exitResult(commands.ExecuteResult{Validation: validation, Format: output.FormatCSV}, &buf)
+		assert.Empty(t, buf.String(), "CSV mode should not print status messages")
+	}
+}
+
 // TestExitResult_WriteError exercises the defensive fmt.Fprintf(os.Stderr, ...) branches
 // inside exitResult that are reached when writing to stdout fails. The correct exit code
 // must still be returned even when the write fails.
@@ -215,3 +224,31 @@ func TestExitResult_WriteError(t *testing.T) {
 		})
 	}
 }
+
+func TestExitResult_ErrorKinds(t *testing.T) {
+	tests := []struct {
+		kind           imageutil.ErrorKind
+		expectedExit   int
+		expectedOutput string
+	}{
+		{imageutil.ErrorKindUnauthorized, 3, "Execution error (unauthorized)\n"},
+		{imageutil.ErrorKindNotFound, 4, "Execution error (not-found)\n"},
+		{imageutil.ErrorKindUnavailable, 2, "Execution error (unavailable)\n"},
+		{imageutil.ErrorKindNotRunnable, 2, "Execution error (not-runnable)\n"},
+		{"", 2, "Execution error\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			var buf bytes.Buffer
+			exitCode := exitResult(commands.ExecuteResult{
+				Validation: commands.ExecutionError,
+				Format:     output.FormatText,
+				ErrorKind:  tt.kind,
+			}, &buf)
+
+			assert.Equal(t, tt.expectedExit, exitCode)
+			assert.Equal(t, tt.expectedOutput, buf.String())
+		})
+	}
+}
@@ -130,6 +130,22 @@ if [[ -n "${INPUT_CONFIG}" ]]; then
   CMD_ARGS+=("--config" "${INPUT_CONFIG}")
 fi
 
+if [[ "${INPUT_STRICT_CONFIG}" == "true" ]]; then
+  CMD_ARGS+=("--strict-config")
+fi
+
+if [[ "${INPUT_ANONYMIZE}" == "true" ]]; then
+  CMD_ARGS+=("--anonymize")
+fi
+
+if [[ "${INPUT_REPORT_STATUS}" == "true" ]]; then
+  CMD_ARGS+=("--report-status" "github")
+fi
+
+if [[ -n "${INPUT_REPORT_STATUS_URL}" ]]; then
+  CMD_ARGS+=("--report-status-url" "${INPUT_REPORT_STATUS_URL}")
+fi
+
 if [[ -n "${INPUT_SKIP}" ]]; then
   CMD_ARGS+=("--skip" "${INPUT_SKIP}")
 fi
@@ -138,10 +154,30 @@ if [[ "${INPUT_FAIL_FAST}" == "true" ]]; then
   CMD_ARGS+=("--fail-fast")
 fi
 
+if [[ -n "${INPUT_SHOW}" ]]; then
+  CMD_ARGS+=("--show" "${INPUT_SHOW}")
+fi
+
+if [[ -n "${INPUT_ARTIFACTS}" ]]; then
+  CMD_ARGS+=("--artifacts" "${INPUT_ARTIFACTS}")
+fi
+
+if [[ "${INPUT_EARLY_EXIT_ON_METADATA_FAILURE}" == "true" ]]; then
+  CMD_ARGS+=("--early-exit-on-metadata-failure")
+fi
+
 if [[ -n "${INPUT_MAX_AGE}" ]]; then
   CMD_ARGS+=("--max-age" "${INPUT_MAX_AGE}")
 fi
 
+if [[ -n "${INPUT_NOT_BEFORE}" ]]; then
+  CMD_ARGS+=("--not-before" "${INPUT_NOT_BEFORE}")
+fi
+
+if [[ -n "${INPUT_NOT_AFTER}" ]]; then
+  CMD_ARGS+=("--not-after" "${INPUT_NOT_AFTER}")
+fi
+
 if [[ -n "${INPUT_MAX_SIZE}" ]]; then
   CMD_ARGS+=("--max-size" "${INPUT_MAX_SIZE}")
 fi
@@ -150,10 +186,22 @@ if [[ -n "${INPUT_MAX_LAYERS}" ]]; then
   CMD_ARGS+=("--max-layers" "${INPUT_MAX_LAYERS}")
 fi
 
+if [[ -n "${INPUT_MAX_TOTAL_SIZE}" ]]; then
+  CMD_ARGS+=("--max-total-size" "${INPUT_MAX_TOTAL_SIZE}")
+fi
+
 if [[ -n "${INPUT_ALLOWED_PORTS}" ]]; then
   CMD_ARGS+=("--allowed-ports" "${INPUT_ALLOWED_PORTS}")
 fi
 
+if [[ -n "${INPUT_MAX_EXPOSED_PORTS}" ]]; then
+  CMD_ARGS+=("--max-exposed-ports" "${INPUT_MAX_EXPOSED_PORTS}")
+fi
+
+if [[ "${INPUT_FORBID_PRIVILEGED_PORTS}" == "true" ]]; then
+  CMD_ARGS+=("--forbid-privileged-ports")
+fi
+
 if [[ -n "${INPUT_ALLOWED_PLATFORMS}" ]]; then
   CMD_ARGS+=("--allowed-platforms" "${INPUT_ALLOWED_PLATFORMS}")
 fi
@@ -178,6 +226,10 @@ if [[ "${INPUT_SKIP_FILES}" == "true" ]]; then
   CMD_ARGS+=("--skip-files")
 fi
 
+if [[ -n "${INPUT_FAIL_ON_SEVERITY}" ]]; then
+  CMD_ARGS+=("--fail-on-severity" "${INPUT_FAIL_ON_SEVERITY}")
+fi
+
 if [[ "${INPUT_ALLOW_SHELL_FORM}" == "true" ]]; then
   CMD_ARGS+=("--allow-shell-form")
 fi
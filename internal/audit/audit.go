@@ -0,0 +1,159 @@
+// Package audit schedules the validation of many images, such as every tag of
+// a repository, and persists progress so interrupted audits can resume.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+)
+
+// Image is one image of an audit, identified by its manifest digest.
+type Image struct {
+	// Ref is the reference validated, e.g. repository@digest.
+	Ref    string
+	Digest string
+}
+
+// Outcome is the result of validating one image, kept in the state so that a
+// resumed audit still reports the failures of the runs before it.
+type Outcome struct {
+	Passed       bool     `json:"passed"`
+	FailedChecks []string `json:"failed-checks,omitempty"`
+}
+
+// State records the digests an audit has already validated with their
+// outcomes, and the digests the immutable-looking tags of the repository
+// pointed at when first seen.
+type State struct {
+	Completed []string           `json:"completed"`
+	Outcomes  map[string]Outcome `json:"outcomes,omitempty"`
+	Tags      map[string]string  `json:"tags,omitempty"`
+	done      map[string]bool
+}
+
+// LoadState reads the state file at path. A missing file is an empty state,
+// so the first run of an audit needs no setup.
+func LoadState(path string) (*State, error) {
+	s := &State{done: make(map[string]bool)}
+	data, err := fileutil.ReadSecureFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	for _, d := range s.Completed {
+		s.done[d] = true
+	}
+	return s, nil
+}
+
+// Done reports whether digest was validated by a previous run.
+func (s *State) Done(digest string) bool {
+	return s.done[digest]
+}
+
+// Outcome returns the outcome recorded for digest by a previous run. The
+// second value is false when digest has no recorded outcome.
+func (s *State) Outcome(digest string) (Outcome, bool) {
+	o, ok := s.Outcomes[digest]
+	return o, ok
+}
+
+// MarkDone records digest as validated with outcome.
+func (s *State) MarkDone(digest string, outcome Outcome) {
+	if s.Outcomes == nil {
+		s.Outcomes = make(map[string]Outcome)
+	}
+	s.Outcomes[digest] = outcome
+	if s.done[digest] {
+		return
+	}
+	s.done[digest] = true
+	s.Completed = append(s.Completed, digest)
+}
+
+// RecordTags records the digest of every immutable-looking tag of tags that
+// is not recorded yet. Recorded digests are never replaced, so a re-pushed
+// tag keeps being reported until the state file is reset.
+func (s *State) RecordTags(tags map[string]string) {
+	for tag, digest := range tags {
+		if _, ok := s.Tags[tag]; ok || !IsImmutableTag(tag) {
+			continue
+		}
+		if s.Tags == nil {
+			s.Tags = make(map[string]string)
+		}
+		s.Tags[tag] = digest
+	}
+}
+
+// Save writes the state to path. The file is replaced atomically so an
+// interrupted write never leaves a truncated state behind.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".check-image-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Options controls which images of an audit are validated in a run.
+type Options struct {
+	// MaxImages caps the number of images validated; 0 means no limit.
+	MaxImages int
+	// Shuffle randomizes the order, so capped runs sample the whole set.
+	Shuffle bool
+}
+
+// Select returns the images still to validate: images already completed in
+// state (when not nil) are dropped, the rest are optionally shuffled and then
+// capped at opts.MaxImages. The second value is the number of images skipped
+// because a previous run completed them.
+func Select(images []Image, state *State, opts Options) ([]Image, int) {
+	var pending []Image
+	resumed := 0
+	for _, img := range images {
+		if state != nil && state.Done(img.Digest) {
+			resumed++
+			continue
+		}
+		pending = append(pending, img)
+	}
+	if opts.Shuffle {
+		pending = slices.Clone(pending)
+		rand.Shuffle(len(pending), func(i, j int) {
+			pending[i], pending[j] = pending[j], pending[i]
+		})
+	}
+	if opts.MaxImages > 0 && len(pending) > opts.MaxImages {
+		pending = pending[:opts.MaxImages]
+	}
+	return pending, resumed
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_LoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := LoadState(path)
+	require.NoError(t, err, "a missing state file is an empty state")
+	assert.False(t, s.Done("sha256:a"))
+
+	s.MarkDone("sha256:a", Outcome{Passed: true})
+	s.MarkDone("sha256:b", Outcome{FailedChecks: []string{"user"}})
+	s.MarkDone("sha256:a", Outcome{Passed: true})
+	require.NoError(t, s.Save(path))
+
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:a", "sha256:b"}, loaded.Completed)
+	assert.True(t, loaded.Done("sha256:b"))
+	assert.False(t, loaded.Done("sha256:c"))
+	outcome, ok := loaded.Outcome("sha256:b")
+	require.True(t, ok)
+	assert.Equal(t, Outcome{FailedChecks: []string{"user"}}, outcome)
+	_, ok = loaded.Outcome("sha256:c")
+	assert.False(t, ok)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestLoadState_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := LoadState(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse state file")
+}
+
+func TestSelect(t *testing.T) {
+	images := []Image{
+		{Ref: "r@sha256:a", Digest: "sha256:a"},
+		{Ref: "r@sha256:b", Digest: "sha256:b"},
+		{Ref: "r@sha256:c", Digest: "sha256:c"},
+		{Ref: "r@sha256:d", Digest: "sha256:d"},
+	}
+	state := &State{done: map[string]bool{}}
+	state.MarkDone("sha256:b", Outcome{Passed: true})
+
+	tests := []struct {
+		name        string
+		state       *State
+		opts        Options
+		wantDigests []string
+		wantResumed int
+	}{
+		{name: "All images", wantDigests: []string{"sha256:a", "sha256:b", "sha256:c", "sha256:d"}},
+		{name: "Completed images are skipped", state: state, wantDigests: []string{"sha256:a", "sha256:c", "sha256:d"}, wantResumed: 1},
+		{name: "Capped", state: state, opts: Options{MaxImages: 2}, wantDigests: []string{"sha256:a", "sha256:c"}, wantResumed: 1},
+		{name: "Cap larger than the set", opts: Options{MaxImages: 10}, wantDigests: []string{"sha256:a", "sha256:b", "sha256:c", "sha256:d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resumed := Select(images, tt.state, tt.opts)
+			var digests []string
+			for _, img := range got {
+				digests = append(digests, img.Digest)
+			}
+			assert.Equal(t, tt.wantDigests, digests)
+			assert.Equal(t, tt.wantResumed, resumed)
+		})
+	}
+}
+
+func TestSelect_Shuffle(t *testing.T) {
+	var images []Image
+	for _, d := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		images = append(images, Image{Ref: "r@" + d, Digest: d})
+	}
+
+	got, _ := Select(images, nil, Options{Shuffle: true, MaxImages: 3})
+	require.Len(t, got, 3)
+	for _, img := range got {
+		assert.Contains(t, images, img)
+	}
+	assert.Equal(t, "a", images[0].Digest, "the input is not reordered")
+}
@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// Coverage is the policy coverage of an audit: how many of the validated
+// images pass each check, the rules failed by the most images, and the
+// largest and oldest images. Every list is sorted, so the same reports always
+// produce the same coverage.
+type Coverage struct {
+	Repository string `json:"repository"`
+	Images     int    `json:"images"`
+	Passed     int    `json:"passed"`
+	// PassRate is the percentage of images that passed every check.
+	PassRate float64 `json:"pass-rate"`
+	// Checks is sorted by pass rate, lowest first.
+	Checks          []CheckCoverage `json:"checks"`
+	TopFailingRules []RuleCoverage  `json:"top-failing-rules"`
+	LargestImages   []ImageCoverage `json:"largest-images"`
+	OldestImages    []ImageCoverage `json:"oldest-images"`
+}
+
+// CheckCoverage counts the outcomes of one check across the images it ran
+// on, leaving out the images it was skipped for. PassRate is the percentage
+// of those images that passed.
+type CheckCoverage struct {
+	Check    string  `json:"check"`
+	Images   int     `json:"images"`
+	Passed   int     `json:"passed"`
+	Failed   int     `json:"failed"`
+	Errors   int     `json:"errors"`
+	PassRate float64 `json:"pass-rate"`
+}
+
+// RuleCoverage counts the images that failed a rule of a check. Rule is
+// empty for checks that itemize no rules, such as size or age.
+type RuleCoverage struct {
+	Check  string `json:"check"`
+	Rule   string `json:"rule,omitempty"`
+	Images int    `json:"images"`
+	// Percent is the percentage of all audited images that failed the rule.
+	Percent float64 `json:"percent"`
+}
+
+// ImageCoverage is an image ranked by size or age, as measured by the size
+// and age checks.
+type ImageCoverage struct {
+	Image   string  `json:"image"`
+	Passed  bool    `json:"passed"`
+	SizeMB  float64 `json:"size-mb,omitempty"`
+	AgeDays float64 `json:"age-days,omitempty"`
+}
+
+// BuildCoverage computes the coverage of the all command reports of an audit
+// of repository, keeping the top entries of the rule and image rankings.
+func BuildCoverage(repository string, reports []output.AllResult, top int) Coverage {
+	c := Coverage{Repository: repository, Images: len(reports)}
+	checks := make(map[string]*CheckCoverage)
+	rules := make(map[[2]string]*RuleCoverage)
+
+	for _, report := range reports {
+		if report.Passed {
+			c.Passed++
+		}
+		for _, r := range report.Checks {
+			if r.Skipped {
+				continue
+			}
+			cc, ok := checks[r.Check]
+			if !ok {
+				cc = &CheckCoverage{Check: r.Check}
+				checks[r.Check] = cc
+			}
+			cc.Images++
+			switch {
+			case r.Passed:
+				cc.Passed++
+			case r.Error != "":
+				cc.Errors++
+			default:
+				cc.Failed++
+			}
+			recordRankings(&c, report, r)
+		}
+
+		// Count each rule once per image, however many findings it has.
+		seen := make(map[[2]string]bool)
+		for _, f := range output.ReportFindings(report) {
+			key := [2]string{f.Check, f.Rule}
+			if f.Severity != output.SeverityFailure || seen[key] {
+				continue
+			}
+			seen[key] = true
+			rc, ok := rules[key]
+			if !ok {
+				rc = &RuleCoverage{Check: f.Check, Rule: f.Rule}
+				rules[key] = rc
+			}
+			rc.Images++
+		}
+	}
+
+	c.PassRate = percent(c.Passed, c.Images)
+	c.Checks = make([]CheckCoverage, 0, len(checks))
+	for _, cc := range checks {
+		cc.PassRate = percent(cc.Passed, cc.Images)
+		c.Checks = append(c.Checks, *cc)
+	}
+	slices.SortFunc(c.Checks, func(a, b CheckCoverage) int {
+		return cmp.Or(cmp.Compare(a.PassRate, b.PassRate), cmp.Compare(a.Check, b.Check))
+	})
+
+	c.TopFailingRules = make([]RuleCoverage, 0, len(rules))
+	for _, rc := range rules {
+		rc.Percent = percent(rc.Images, c.Images)
+		c.TopFailingRules = append(c.TopFailingRules, *rc)
+	}
+	slices.SortFunc(c.TopFailingRules, func(a, b RuleCoverage) int {
+		return cmp.Or(cmp.Compare(b.Images, a.Images), cmp.Compare(a.Check, b.Check), cmp.Compare(a.Rule, b.Rule))
+	})
+	c.TopFailingRules = truncate(c.TopFailingRules, top)
+
+	slices.SortFunc(c.LargestImages, func(a, b ImageCoverage) int {
+		return cmp.Or(cmp.Compare(b.SizeMB, a.SizeMB), cmp.Compare(a.Image, b.Image))
+	})
+	c.LargestImages = truncate(c.LargestImages, top)
+	slices.SortFunc(c.OldestImages, func(a, b ImageCoverage) int {
+		return cmp.Or(cmp.Compare(b.AgeDays, a.AgeDays), cmp.Compare(a.Image, b.Image))
+	})
+	c.OldestImages = truncate(c.OldestImages, top)
+	return c
+}
+
+// recordRankings adds the image of report to the size and age rankings when
+// r measured its size or age.
+func recordRankings(c *Coverage, report output.AllResult, r output.CheckResult) {
+	if r.Error != "" {
+		return
+	}
+	switch d := r.Details.(type) {
+	case output.SizeDetails:
+		c.LargestImages = append(c.LargestImages, ImageCoverage{Image: report.Image, Passed: report.Passed, SizeMB: d.TotalMB})
+	case output.AgeDetails:
+		c.OldestImages = append(c.OldestImages, ImageCoverage{Image: report.Image, Passed: report.Passed, AgeDays: d.AgeDays})
+	}
+}
+
+// percent returns n of total as a percentage rounded to one decimal, or 0
+// when total is 0.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*1000/float64(total)) / 10
+}
+
+func truncate[T any](s []T, n int) []T {
+	if n > 0 && len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// RenderCoverageMarkdown writes the coverage as a Markdown dashboard, e.g. for
+// a CI job summary or a wiki page.
+func RenderCoverageMarkdown(w io.Writer, c Coverage) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Policy coverage of %s\n\n", c.Repository)
+	fmt.Fprintf(&b, "%d of %d images (%.1f%%) pass every check.\n", c.Passed, c.Images, c.PassRate)
+
+	b.WriteString("\n## Checks\n\n")
+	if len(c.Checks) == 0 {
+		b.WriteString("No checks were run.\n")
+	} else {
+		b.WriteString("| Check | Pass rate | Passed | Failed | Errors |\n| --- | ---: | ---: | ---: | ---: |\n")
+		for _, cc := range c.Checks {
+			fmt.Fprintf(&b, "| %s | %.1f%% | %d | %d | %d |\n", markdownCell(cc.Check), cc.PassRate, cc.Passed, cc.Failed, cc.Errors)
+		}
+	}
+
+	b.WriteString("\n## Top failing rules\n\n")
+	if len(c.TopFailingRules) == 0 {
+		b.WriteString("No rules failed.\n")
+	} else {
+		b.WriteString("| Check | Rule | Images | Share |\n| --- | --- | ---: | ---: |\n")
+		for _, rc := range c.TopFailingRules {
+			fmt.Fprintf(&b, "| %s | %s | %d | %.1f%% |\n", markdownCell(rc.Check), markdownCell(ruleLabel(rc.Rule)), rc.Images, rc.Percent)
+		}
+	}
+
+	writeMarkdownRanking(&b, "Largest images", "Size (MB)", c.LargestImages, func(i ImageCoverage) float64 { return i.SizeMB })
+	writeMarkdownRanking(&b, "Oldest images", "Age (days)", c.OldestImages, func(i ImageCoverage) float64 { return i.AgeDays })
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRanking(b *strings.Builder, title, column string, images []ImageCoverage, value func(ImageCoverage) float64) {
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	if len(images) == 0 {
+		b.WriteString("Not measured.\n")
+		return
+	}
+	fmt.Fprintf(b, "| Image | %s | Passed |\n| --- | ---: | --- |\n", column)
+	for _, img := range images {
+		fmt.Fprintf(b, "| %s | %.1f | %s |\n", markdownCell(img.Image), value(img), yesNo(img.Passed))
+	}
+}
+
+// markdownCell escapes the characters that would break a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func ruleLabel(rule string) string {
+	if rule == "" {
+		return "-"
+	}
+	return rule
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var coverageHTML = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"rule":  ruleLabel,
+	"yesno": yesNo,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Policy coverage of {{.Repository}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.num { text-align: right; }
+.bar { background: #eee; width: 10em; height: 0.8em; }
+.bar div { background: #2e8b57; height: 100%; }
+</style>
+</head>
+<body>
+<h1>Policy coverage of {{.Repository}}</h1>
+<p>{{.Passed}} of {{.Images}} images ({{printf "%.1f" .PassRate}}%) pass every check.</p>
+<h2>Checks</h2>
+{{if .Checks}}<table>
+<tr><th>Check</th><th>Pass rate</th><th></th><th>Passed</th><th>Failed</th><th>Errors</th></tr>
+{{range .Checks}}<tr><td>{{.Check}}</td><td class="num">{{printf "%.1f" .PassRate}}%</td><td><div class="bar"><div style="width: {{printf "%.1f" .PassRate}}%"></div></div></td><td class="num">{{.Passed}}</td><td class="num">{{.Failed}}</td><td class="num">{{.Errors}}</td></tr>
+{{end}}</table>
+{{else}}<p>No checks were run.</p>
+{{end}}<h2>Top failing rules</h2>
+{{if .TopFailingRules}}<table>
+<tr><th>Check</th><th>Rule</th><th>Images</th><th>Share</th></tr>
+{{range .TopFailingRules}}<tr><td>{{.Check}}</td><td>{{rule .Rule}}</td><td class="num">{{.Images}}</td><td class="num">{{printf "%.1f" .Percent}}%</td></tr>
+{{end}}</table>
+{{else}}<p>No rules failed.</p>
+{{end}}<h2>Largest images</h2>
+{{if .LargestImages}}<table>
+<tr><th>Image</th><th>Size (MB)</th><th>Passed</th></tr>
+{{range .LargestImages}}<tr><td>{{.Image}}</td><td class="num">{{printf "%.1f" .SizeMB}}</td><td>{{yesno .Passed}}</td></tr>
+{{end}}</table>
+{{else}}<p>Not measured.</p>
+{{end}}<h2>Oldest images</h2>
+{{if .OldestImages}}<table>
+<tr><th>Image</th><th>Age (days)</th><th>Passed</th></tr>
+{{range .OldestImages}}<tr><td>{{.Image}}</td><td class="num">{{printf "%.1f" .AgeDays}}</td><td>{{yesno .Passed}}</td></tr>
+{{end}}</table>
+{{else}}<p>Not measured.</p>
+{{end}}</body>
+</html>
+`))
+
+// RenderCoverageHTML writes the coverage as a self-contained HTML dashboard.
+func RenderCoverageHTML(w io.Writer, c Coverage) error {
+	return coverageHTML.Execute(w, c)
+}
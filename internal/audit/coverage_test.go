@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coverageReports() []output.AllResult {
+	return []output.AllResult{
+		{
+			Image:  "repo@sha256:a",
+			Passed: false,
+			Checks: []output.CheckResult{
+				{Check: "size", Passed: true, Details: output.SizeDetails{TotalMB: 120}},
+				{Check: "labels", Passed: false, Details: output.LabelsDetails{MissingLabels: []string{"owner", "team"}}},
+				{Check: "age", Passed: false, Details: output.AgeDetails{AgeDays: 400}},
+			},
+		},
+		{
+			Image:  "repo@sha256:b",
+			Passed: false,
+			Checks: []output.CheckResult{
+				{Check: "size", Passed: true, Details: output.SizeDetails{TotalMB: 80}},
+				{Check: "labels", Passed: false, Details: output.LabelsDetails{MissingLabels: []string{"owner"}}},
+				{Check: "age", Passed: false, Error: "cannot read config"},
+			},
+		},
+		{
+			Image:  "repo@sha256:c",
+			Passed: true,
+			Checks: []output.CheckResult{
+				{Check: "size", Passed: true, Details: output.SizeDetails{TotalMB: 200}},
+				{Check: "labels", Passed: true},
+				{Check: "age", Passed: true, Details: output.AgeDetails{AgeDays: 3}},
+			},
+		},
+	}
+}
+
+func TestBuildCoverage(t *testing.T) {
+	c := BuildCoverage("repo", coverageReports(), 2)
+
+	assert.Equal(t, 3, c.Images)
+	assert.Equal(t, 1, c.Passed)
+	assert.InDelta(t, 33.3, c.PassRate, 0.001)
+	assert.Equal(t, []CheckCoverage{
+		{Check: "age", Images: 3, Passed: 1, Failed: 1, Errors: 1, PassRate: 33.3},
+		{Check: "labels", Images: 3, Passed: 1, Failed: 2, PassRate: 33.3},
+		{Check: "size", Images: 3, Passed: 3, PassRate: 100},
+	}, c.Checks)
+	assert.Equal(t, []RuleCoverage{
+		{Check: "labels", Rule: "missing-label", Images: 2, Percent: 66.7},
+		{Check: "age", Images: 1, Percent: 33.3},
+	}, c.TopFailingRules, "rules are counted once per image and errors are not rules")
+	assert.Equal(t, []ImageCoverage{
+		{Image: "repo@sha256:c", Passed: true, SizeMB: 200},
+		{Image: "repo@sha256:a", SizeMB: 120},
+	}, c.LargestImages)
+	assert.Equal(t, []ImageCoverage{
+		{Image: "repo@sha256:a", AgeDays: 400},
+		{Image: "repo@sha256:c", Passed: true, AgeDays: 3},
+	}, c.OldestImages, "checks that errored are not ranked")
+}
+
+func TestBuildCoverage_SkippedChecks(t *testing.T) {
+	reports := []output.AllResult{
+		{Image: "repo@sha256:a", Passed: true, Checks: []output.CheckResult{
+			{Check: "registry", Passed: true, Skipped: true, SkipReason: output.SkipReasonNotApplicable},
+		}},
+		{Image: "repo@sha256:b", Passed: false, Checks: []output.CheckResult{
+			{Check: "registry", Passed: false},
+		}},
+	}
+
+	c := BuildCoverage("repo", reports, 10)
+	assert.Equal(t, []CheckCoverage{
+		{Check: "registry", Images: 1, Failed: 1, PassRate: 0},
+	}, c.Checks, "images a check was skipped for are left out")
+}
+
+func TestBuildCoverage_Empty(t *testing.T) {
+	c := BuildCoverage("repo", nil, 10)
+	assert.Zero(t, c.PassRate)
+	assert.Empty(t, c.Checks)
+	assert.Empty(t, c.TopFailingRules)
+
+	var md bytes.Buffer
+	require.NoError(t, RenderCoverageMarkdown(&md, c))
+	assert.Contains(t, md.String(), "No checks were run.")
+	assert.Contains(t, md.String(), "No rules failed.")
+}
+
+func TestRenderCoverageMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderCoverageMarkdown(&buf, BuildCoverage("repo", coverageReports(), 10)))
+
+	out := buf.String()
+	assert.Contains(t, out, "# Policy coverage of repo\n\n1 of 3 images (33.3%) pass every check.\n")
+	assert.Contains(t, out, "| labels | 33.3% | 1 | 2 | 0 |\n")
+	assert.Contains(t, out, "| labels | missing-label | 2 | 66.7% |\n")
+	assert.Contains(t, out, "| age | - | 1 | 33.3% |\n")
+	assert.Contains(t, out, "| repo@sha256:c | 200.0 | yes |\n")
+}
+
+func TestRenderCoverageHTML(t *testing.T) {
+	var buf bytes.Buffer
+	c := BuildCoverage("<repo>", coverageReports(), 10)
+	require.NoError(t, RenderCoverageHTML(&buf, c))
+
+	out := buf.String()
+	assert.Contains(t, out, "<h1>Policy coverage of &lt;repo&gt;</h1>", "values are escaped")
+	assert.Contains(t, out, `<div style="width: 100.0%">`)
+	assert.Contains(t, out, "<td>missing-label</td>")
+}
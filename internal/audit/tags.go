@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Kinds of tag hygiene issues.
+const (
+	// TagIssueLatestDiverges is reported when latest points at another image
+	// than the newest release tag.
+	TagIssueLatestDiverges = "latest-diverges"
+	// TagIssueRepushed is reported when an immutable-looking tag points at
+	// another image than when an earlier audit recorded it.
+	TagIssueRepushed = "repushed"
+)
+
+// latestTag is the conventional tag of the newest image of a repository.
+const latestTag = "latest"
+
+var (
+	semverTag = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?$`)
+	// commitTag matches tags named after a git commit, as written by most CI
+	// pipelines, e.g. 3f2a1b9 or sha-3f2a1b9.
+	commitTag = regexp.MustCompile(`^(sha-)?[0-9a-f]{7,40}$`)
+)
+
+// TagIssue is a tag hygiene problem of a repository.
+type TagIssue struct {
+	Kind string
+	Tag  string
+	// Digest is the digest the tag points at.
+	Digest string
+	// Expected is the digest the tag should point at: that of the newest
+	// release for latest, or the recorded one for a re-pushed tag.
+	Expected string
+	Message  string
+}
+
+// release is the version of a semver tag.
+type release struct {
+	major, minor, patch int
+	prerelease          bool
+}
+
+func parseRelease(tag string) (release, bool) {
+	m := semverTag.FindStringSubmatch(tag)
+	if m == nil {
+		return release{}, false
+	}
+	var r release
+	var err error
+	for i, p := range []*int{&r.major, &r.minor, &r.patch} {
+		if *p, err = strconv.Atoi(m[i+1]); err != nil {
+			return release{}, false
+		}
+	}
+	r.prerelease = m[4] != ""
+	return r, true
+}
+
+func (r release) compare(o release) int {
+	switch {
+	case r.major != o.major:
+		return r.major - o.major
+	case r.minor != o.minor:
+		return r.minor - o.minor
+	default:
+		return r.patch - o.patch
+	}
+}
+
+// IsImmutableTag reports whether tag looks like it should never move: a full
+// semantic version, e.g. v1.2.3 or 1.2.3-rc.1, or a git commit. Floating tags
+// such as latest, v1, or 1.2 are expected to move.
+func IsImmutableTag(tag string) bool {
+	_, ok := parseRelease(tag)
+	return ok || commitTag.MatchString(tag)
+}
+
+// CheckTagHygiene returns the tag hygiene issues of a repository, sorted by
+// tag: latest pointing at another image than the newest release (pre-releases
+// are not releases), and immutable-looking tags that point at another digest
+// than in recorded, the tags of earlier audits. tags maps each tag of the
+// repository to its digest; recorded may be nil.
+func CheckTagHygiene(tags, recorded map[string]string) []TagIssue {
+	var issues []TagIssue
+	if issue, ok := checkLatest(tags); ok {
+		issues = append(issues, issue)
+	}
+	for _, tag := range slices.Sorted(maps.Keys(tags)) {
+		digest := tags[tag]
+		previous, ok := recorded[tag]
+		if !ok || previous == digest || !IsImmutableTag(tag) {
+			continue
+		}
+		issues = append(issues, TagIssue{
+			Kind:     TagIssueRepushed,
+			Tag:      tag,
+			Digest:   digest,
+			Expected: previous,
+			Message:  fmt.Sprintf("tag %s was re-pushed: it pointed at %s in an earlier audit and now points at %s", tag, previous, digest),
+		})
+	}
+	slices.SortStableFunc(issues, func(a, b TagIssue) int { return cmp.Compare(a.Tag, b.Tag) })
+	return issues
+}
+
+// checkLatest compares latest with the newest release tag. There is no issue
+// when the repository has no latest tag or no release tag.
+func checkLatest(tags map[string]string) (TagIssue, bool) {
+	latest, ok := tags[latestTag]
+	if !ok {
+		return TagIssue{}, false
+	}
+	var newest string
+	var newestRelease release
+	newestDigests := make(map[string]bool)
+	for _, tag := range slices.Sorted(maps.Keys(tags)) {
+		r, ok := parseRelease(tag)
+		if !ok || r.prerelease {
+			continue
+		}
+		c := r.compare(newestRelease)
+		if newest == "" || c > 0 {
+			newest, newestRelease = tag, r
+			clear(newestDigests)
+		}
+		if newest == tag || c == 0 {
+			newestDigests[tags[tag]] = true
+		}
+	}
+	if newest == "" || newestDigests[latest] {
+		return TagIssue{}, false
+	}
+	return TagIssue{
+		Kind:     TagIssueLatestDiverges,
+		Tag:      latestTag,
+		Digest:   latest,
+		Expected: tags[newest],
+		Message:  fmt.Sprintf("latest points at %s, but the newest release %s points at %s", latest, newest, tags[newest]),
+	}, true
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsImmutableTag(t *testing.T) {
+	for _, tag := range []string{"v1.2.3", "1.2.3", "1.2.3-rc.1", "3f2a1b9", "sha-3f2a1b9c0d"} {
+		assert.True(t, IsImmutableTag(tag), tag)
+	}
+	for _, tag := range []string{"latest", "v1", "1.2", "main", "v1.2.3.4", "01.2.3", "3f2a1b"} {
+		assert.False(t, IsImmutableTag(tag), tag)
+	}
+}
+
+func TestCheckTagHygiene_Latest(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      map[string]string
+		wantIssue bool
+	}{
+		{"latest is the newest release", map[string]string{"latest": "d3", "v1.9.0": "d1", "v1.10.0": "d3"}, false},
+		{"latest is an older release", map[string]string{"latest": "d1", "v1.9.0": "d1", "v1.10.0": "d3"}, true},
+		{"pre-releases are not releases", map[string]string{"latest": "d1", "v1.9.0": "d1", "v2.0.0-rc.1": "d2"}, false},
+		{"equal versions with and without v", map[string]string{"latest": "d2", "1.0.0": "d1", "v1.0.0": "d2"}, false},
+		{"no release tags", map[string]string{"latest": "d1", "main": "d2"}, false},
+		{"no latest tag", map[string]string{"v1.0.0": "d1", "v2.0.0": "d2"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckTagHygiene(tt.tags, nil)
+			if !tt.wantIssue {
+				assert.Empty(t, issues)
+				return
+			}
+			require.Len(t, issues, 1)
+			assert.Equal(t, TagIssueLatestDiverges, issues[0].Kind)
+			assert.Equal(t, "latest", issues[0].Tag)
+			assert.Equal(t, "d1", issues[0].Digest)
+			assert.Equal(t, "d3", issues[0].Expected)
+			assert.Equal(t, "latest points at d1, but the newest release v1.10.0 points at d3", issues[0].Message)
+		})
+	}
+}
+
+func TestCheckTagHygiene_Repushed(t *testing.T) {
+	tags := map[string]string{"v1.0.0": "d2", "3f2a1b9": "d3", "main": "d4", "v1.1.0": "d5"}
+	recorded := map[string]string{"v1.0.0": "d1", "3f2a1b9": "d3", "main": "d0"}
+
+	issues := CheckTagHygiene(tags, recorded)
+	require.Len(t, issues, 1, "unchanged tags, floating tags, and new tags are not reported")
+	assert.Equal(t, TagIssue{
+		Kind:     TagIssueRepushed,
+		Tag:      "v1.0.0",
+		Digest:   "d2",
+		Expected: "d1",
+		Message:  "tag v1.0.0 was re-pushed: it pointed at d1 in an earlier audit and now points at d2",
+	}, issues[0])
+}
+
+func TestState_RecordTags(t *testing.T) {
+	s := &State{}
+	s.RecordTags(map[string]string{"v1.0.0": "d1", "latest": "d1"})
+	s.RecordTags(map[string]string{"v1.0.0": "d2", "v1.1.0": "d3"})
+	assert.Equal(t, map[string]string{"v1.0.0": "d1", "v1.1.0": "d3"}, s.Tags, "floating tags are not recorded and recorded digests are kept")
+}
@@ -0,0 +1,219 @@
+// Package auditlog appends a record of every validation to an append-only
+// audit log, a JSON lines file or a syslog receiver, as compliance evidence of
+// who validated which image against which policy, and with what outcome.
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Syslog destinations. The local syslog socket is only available on Unix
+// systems; remote receivers are reached over UDP or TCP.
+const (
+	DestSyslog        = "syslog"
+	syslogUDPScheme   = "syslog"
+	syslogTCPScheme   = "syslog+tcp"
+	localSyslogSocket = "/dev/log"
+	dialTimeout       = 5 * time.Second
+)
+
+// Record is one validation of an image.
+type Record struct {
+	Time string `json:"time"`
+	// User and Host identify who requested the validation.
+	User    string `json:"user,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Command string `json:"command"`
+	Image   string `json:"image"`
+	// Digest is the manifest digest of the validated image, when it could be
+	// computed.
+	Digest        string   `json:"digest,omitempty"`
+	PolicyHash    string   `json:"policy-hash"`
+	PolicyProfile string   `json:"policy-profile,omitempty"`
+	Passed        bool     `json:"passed"`
+	FailedChecks  []string `json:"failed-checks,omitempty"`
+	// Reused is set when daemon-watch reported the result of an earlier
+	// validation of the same image and policy instead of validating it.
+	Reused  bool   `json:"reused,omitempty"`
+	Version string `json:"version"`
+}
+
+// NewRecord returns a record stamped with the current time and the user and
+// host running the validation.
+func NewRecord(command string) Record {
+	r := Record{Time: time.Now().UTC().Format(time.RFC3339), Command: command}
+	if u, err := user.Current(); err == nil {
+		r.User = u.Username
+	}
+	if h, err := os.Hostname(); err == nil {
+		r.Host = h
+	}
+	return r
+}
+
+// ValidateDest checks an audit log destination: a file path, "syslog" for
+// the local syslog socket, or a syslog://host:port (UDP) or
+// syslog+tcp://host:port receiver.
+func ValidateDest(dest string) error {
+	if dest == "-" {
+		return fmt.Errorf("the audit log cannot be written to stdout")
+	}
+	if u, ok := syslogURL(dest); ok && u.Host == "" {
+		return fmt.Errorf("invalid audit log destination %q, expected %s://host:port", dest, u.Scheme)
+	}
+	return nil
+}
+
+// Append writes r to the audit log at dest.
+func Append(ctx context.Context, dest string, r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error encoding audit record: %w", err)
+	}
+	if dest == DestSyslog {
+		return sendSyslog(ctx, "unixgram", localSyslogSocket, r, line)
+	}
+	if u, ok := syslogURL(dest); ok {
+		network := "udp"
+		if u.Scheme == syslogTCPScheme {
+			network = "tcp"
+		}
+		return sendSyslog(ctx, network, u.Host, r, line)
+	}
+	return appendFile(dest, line)
+}
+
+// IsSyslog reports whether dest is a syslog destination rather than a file.
+func IsSyslog(dest string) bool {
+	_, ok := syslogURL(dest)
+	return dest == DestSyslog || ok
+}
+
+// maxRecordSize bounds the lines ReadTail accepts.
+const maxRecordSize = 1024 * 1024
+
+// tailChunkSize is how much of the log ReadTail reads at a time.
+const tailChunkSize = 64 * 1024
+
+// ReadTail reads the last n records of the JSON lines audit log at path,
+// oldest first. The log is read backwards from its end, so the time and memory
+// it takes depend on n and not on the size of the log. Lines that do not hold
+// a record, such as a line being appended by another process, are skipped.
+func ReadTail(path string, n int) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	pos, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	// records are collected newest first; partial is the start of the
+	// line that the data read so far begins in the middle of.
+	var records []Record
+	var partial []byte
+	collect := func(line []byte) {
+		var r Record
+		if err := json.Unmarshal(line, &r); err == nil && r.Image != "" {
+			records = append(records, r)
+		}
+	}
+	for pos > 0 && len(records) < n {
+		size := min(tailChunkSize, pos)
+		pos -= size
+		chunk := make([]byte, size, size+int64(len(partial)))
+		if _, err := f.ReadAt(chunk, pos); err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		lines := bytes.Split(append(chunk, partial...), []byte("\n"))
+		partial = lines[0]
+		for i := len(lines) - 1; i > 0 && len(records) < n; i-- {
+			collect(lines[i])
+		}
+		if len(partial) > maxRecordSize {
+			return nil, fmt.Errorf("failed to read audit log: a line exceeds %d bytes", maxRecordSize)
+		}
+	}
+	if pos == 0 && len(records) < n {
+		collect(partial)
+	}
+	slices.Reverse(records)
+	return records, nil
+}
+
+func syslogURL(dest string) (*url.URL, bool) {
+	if !strings.HasPrefix(dest, syslogUDPScheme+"://") && !strings.HasPrefix(dest, syslogTCPScheme+"://") {
+		return nil, false
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return &url.URL{Scheme: strings.SplitN(dest, ":", 2)[0]}, true
+	}
+	return u, true
+}
+
+// appendFile appends line to the JSON lines file at path, creating it
+// (0600) when missing.
+func appendFile(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Syslog priority of the records: facility user, severity informational for
+// passed validations and warning for failed ones (RFC 5424).
+const (
+	facilityUser    = 1
+	severityWarning = 4
+	severityInfo    = 6
+)
+
+// sendSyslog sends line as the message of an RFC 5424 syslog message. TCP
+// messages are framed by octet counting (RFC 6587).
+func sendSyslog(ctx context.Context, network, addr string, r Record, line []byte) error {
+	severity := severityInfo
+	if !r.Passed {
+		severity = severityWarning
+	}
+	host := r.Host
+	if host == "" {
+		host = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s check-image %d - - %s", facilityUser*8+severity, r.Time, host, os.Getpid(), line)
+	if network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("failed to write to syslog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppend_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	first := Record{Time: "2026-01-01T00:00:00Z", Command: "check-image all", Image: "nginx:1.0", PolicyHash: "sha256:a", Passed: true}
+	second := Record{Time: "2026-01-01T00:01:00Z", Command: "check-image all", Image: "nginx:1.1", PolicyHash: "sha256:a", FailedChecks: []string{"user"}}
+	require.NoError(t, Append(context.Background(), path, first))
+	require.NoError(t, Append(context.Background(), path, second))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	assert.Equal(t, []Record{first, second}, records, "records are appended in order")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAppend_FileError(t *testing.T) {
+	err := Append(context.Background(), filepath.Join(t.TempDir(), "missing", "audit.jsonl"), Record{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open audit log")
+}
+
+func TestAppend_SyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	r := Record{Time: "2026-01-01T00:00:00Z", Host: "ci-runner", Command: "check-image all", Image: "nginx:1.0", PolicyHash: "sha256:a"}
+	require.NoError(t, Append(context.Background(), "syslog://"+conn.LocalAddr().String(), r))
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<12>1 2026-01-01T00:00:00Z ci-runner check-image "), "failed validations are warnings: %s", msg)
+	assert.Contains(t, msg, ` - - {"time":"2026-01-01T00:00:00Z"`)
+}
+
+func TestAppend_SyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		data, _ := bufio.NewReader(c).ReadString(0)
+		received <- data
+	}()
+
+	r := Record{Time: "2026-01-01T00:00:00Z", Command: "check-image all", Image: "nginx:1.0", Passed: true}
+	require.NoError(t, Append(context.Background(), "syslog+tcp://"+ln.Addr().String(), r))
+
+	msg := <-received
+	length, rest, ok := strings.Cut(msg, " ")
+	require.True(t, ok)
+	assert.Equal(t, length, strconv.Itoa(len(rest)), "messages are framed by octet counting")
+	assert.True(t, strings.HasPrefix(rest, "<14>1 2026-01-01T00:00:00Z - check-image "))
+}
+
+func TestValidateDest(t *testing.T) {
+	assert.NoError(t, ValidateDest("audit.jsonl"))
+	assert.NoError(t, ValidateDest("syslog"))
+	assert.NoError(t, ValidateDest("syslog://logs.example.com:514"))
+	assert.ErrorContains(t, ValidateDest("syslog+tcp://"), "expected syslog+tcp://host:port")
+	assert.ErrorContains(t, ValidateDest("-"), "cannot be written to stdout")
+}
+
+func TestReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	first := Record{Time: "2026-01-02T03:04:05Z", Command: "all", Image: "nginx:1.27", Passed: true}
+	second := Record{Time: "2026-01-02T03:05:05Z", Command: "daemon-watch", Image: "app:1.0", FailedChecks: []string{"user"}}
+	require.NoError(t, Append(context.Background(), path, first))
+	require.NoError(t, Append(context.Background(), path, second))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("{\"time\":\"2026-01-02T03:06:05Z\",\"ima")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	records, err := ReadTail(path, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{first, second}, records, "a partial line is skipped")
+
+	records, err = ReadTail(path, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{second}, records)
+
+	_, err = ReadTail(filepath.Join(t.TempDir(), "missing.jsonl"), 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open audit log")
+}
+
+func TestReadTail_SpansChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// Records larger than a chunk, so that lines are split across reads.
+	var want []Record
+	for i := range 5 {
+		r := Record{Image: fmt.Sprintf("app:%d", i), FailedChecks: []string{strings.Repeat("x", tailChunkSize/3)}}
+		require.NoError(t, Append(context.Background(), path, r))
+		want = append(want, r)
+	}
+
+	records, err := ReadTail(path, 3)
+	require.NoError(t, err)
+	assert.Equal(t, want[2:], records)
+
+	records, err = ReadTail(path, 100)
+	require.NoError(t, err)
+	assert.Equal(t, want, records)
+}
+
+func TestIsSyslog(t *testing.T) {
+	assert.True(t, IsSyslog("syslog"))
+	assert.True(t, IsSyslog("syslog://logs.example.com:514"))
+	assert.True(t, IsSyslog("syslog+tcp://logs.example.com:6514"))
+	assert.False(t, IsSyslog("/var/log/check-image/audit.jsonl"))
+	assert.False(t, IsSyslog("syslog.jsonl"))
+}
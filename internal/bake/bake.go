@@ -0,0 +1,138 @@
+// Package bake resolves the images produced by a docker buildx bake build:
+// the targets of a bake file, as printed by docker buildx bake --print, and
+// the images recorded in a bake metadata file.
+package bake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Definition is the resolved bake definition printed by
+// docker buildx bake --print.
+type Definition struct {
+	Target map[string]Target `json:"target"`
+}
+
+// Target is a target of a bake definition.
+type Target struct {
+	Tags []string `json:"tags"`
+}
+
+// Metadata is the entry of a target in a bake metadata file
+// (docker buildx bake --metadata-file).
+type Metadata struct {
+	// ImageName is the comma-separated list of names the image was given.
+	ImageName string `json:"image.name"`
+	// Digest is the digest of the image manifest or index.
+	Digest string `json:"containerimage.digest"`
+}
+
+// Image is the image built for a bake target.
+type Image struct {
+	Target string
+	Ref    string
+}
+
+// ParseDefinition parses the output of docker buildx bake --print.
+func ParseDefinition(data []byte) (*Definition, error) {
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("error parsing bake definition: %w", err)
+	}
+	return &def, nil
+}
+
+// ParseMetadata parses a bake metadata file, keyed by target. Entries that
+// are not targets, such as buildx.build.warnings, are ignored.
+func ParseMetadata(data []byte) (map[string]Metadata, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing bake metadata file: %w", err)
+	}
+	meta := make(map[string]Metadata, len(raw))
+	for name, value := range raw {
+		var m Metadata
+		if json.Unmarshal(value, &m) != nil || m.ImageName == "" && m.Digest == "" {
+			continue
+		}
+		meta[name] = m
+	}
+	return meta, nil
+}
+
+// Print resolves the targets of the bake files with
+// docker buildx bake --print, which evaluates HCL, variables, and
+// inheritance. With no files, bake looks for its default files; with no
+// targets, it resolves the default group.
+func Print(ctx context.Context, files, targets []string) ([]byte, error) {
+	args := []string{"buildx", "bake", "--print"}
+	for _, f := range files {
+		args = append(args, "--file", f)
+	}
+	args = append(args, "--")
+	args = append(args, targets...)
+
+	var stdout, stderr bytes.Buffer
+	// #nosec G204 -- runs docker with the bake files and targets given by the user
+	c := exec.CommandContext(ctx, "docker", args...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("docker buildx bake --print failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("docker buildx bake --print failed: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Images returns the image of every target, sorted by target name. The
+// targets are those of def, or of meta when def is nil. A target with an
+// entry in meta is pinned to the digest it was built with, under its first
+// name; other targets are located by their first tag. Targets without tags,
+// such as test or lint targets, build no image and are skipped.
+func Images(def *Definition, meta map[string]Metadata) []Image {
+	var names []string
+	if def != nil {
+		names = slices.Sorted(maps.Keys(def.Target))
+	} else {
+		names = slices.Sorted(maps.Keys(meta))
+	}
+
+	var images []Image
+	for _, name := range names {
+		if ref := metadataRef(meta[name]); ref != "" {
+			images = append(images, Image{Target: name, Ref: ref})
+			continue
+		}
+		if def != nil && len(def.Target[name].Tags) > 0 {
+			images = append(images, Image{Target: name, Ref: def.Target[name].Tags[0]})
+			continue
+		}
+		log.WithField("target", name).Debug("Skipping bake target without an image")
+	}
+	return images
+}
+
+// metadataRef returns the reference of the image recorded in m: its first
+// name, pinned to its digest when known.
+func metadataRef(m Metadata) string {
+	name, _, _ := strings.Cut(m.ImageName, ",")
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return ""
+	case m.Digest != "":
+		return name + "@" + m.Digest
+	}
+	return name
+}
@@ -0,0 +1,99 @@
+package bake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefinition(t *testing.T) {
+	data := []byte(`{
+		"group": {"default": {"targets": ["api", "web"]}},
+		"target": {
+			"api": {"context": ".", "dockerfile": "api/Dockerfile", "tags": ["ghcr.io/org/api:1.0", "ghcr.io/org/api:latest"]},
+			"web": {"context": ".", "tags": ["ghcr.io/org/web:1.0"]}
+		}
+	}`)
+
+	def, err := ParseDefinition(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ghcr.io/org/api:1.0", "ghcr.io/org/api:latest"}, def.Target["api"].Tags)
+	assert.Equal(t, []string{"ghcr.io/org/web:1.0"}, def.Target["web"].Tags)
+
+	_, err = ParseDefinition([]byte("not json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing bake definition")
+}
+
+func TestParseMetadata(t *testing.T) {
+	data := []byte(`{
+		"api": {"containerimage.digest": "sha256:aaa", "image.name": "ghcr.io/org/api:1.0,ghcr.io/org/api:latest"},
+		"lint": {"buildx.build.ref": "builder/builder0/xyz"},
+		"buildx.build.warnings": [{"vertex": "sha256:bbb"}]
+	}`)
+
+	meta, err := ParseMetadata(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Metadata{
+		"api": {ImageName: "ghcr.io/org/api:1.0,ghcr.io/org/api:latest", Digest: "sha256:aaa"},
+	}, meta)
+
+	_, err = ParseMetadata([]byte("[]"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing bake metadata file")
+}
+
+func TestImages(t *testing.T) {
+	def := &Definition{Target: map[string]Target{
+		"web":  {Tags: []string{"ghcr.io/org/web:1.0"}},
+		"api":  {Tags: []string{"ghcr.io/org/api:1.0", "ghcr.io/org/api:latest"}},
+		"lint": {},
+	}}
+	meta := map[string]Metadata{
+		"api":   {ImageName: "ghcr.io/org/api:1.0,ghcr.io/org/api:latest", Digest: "sha256:aaa"},
+		"other": {ImageName: "ghcr.io/org/other:1.0"},
+	}
+
+	tests := []struct {
+		name string
+		def  *Definition
+		meta map[string]Metadata
+		want []Image
+	}{
+		{
+			name: "definition only",
+			def:  def,
+			want: []Image{
+				{Target: "api", Ref: "ghcr.io/org/api:1.0"},
+				{Target: "web", Ref: "ghcr.io/org/web:1.0"},
+			},
+		},
+		{
+			name: "definition pinned by metadata",
+			def:  def,
+			meta: meta,
+			want: []Image{
+				{Target: "api", Ref: "ghcr.io/org/api:1.0@sha256:aaa"},
+				{Target: "web", Ref: "ghcr.io/org/web:1.0"},
+			},
+		},
+		{
+			name: "metadata only",
+			meta: meta,
+			want: []Image{
+				{Target: "api", Ref: "ghcr.io/org/api:1.0@sha256:aaa"},
+				{Target: "other", Ref: "ghcr.io/org/other:1.0"},
+			},
+		},
+		{
+			name: "nothing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Images(tt.def, tt.meta))
+		})
+	}
+}
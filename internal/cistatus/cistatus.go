@@ -0,0 +1,231 @@
+// Package cistatus reports the outcome of a validation as a commit status on
+// GitHub or GitLab, for the commit a CI pipeline is building.
+package cistatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Providers of commit statuses.
+const (
+	ProviderAuto   = "auto"
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// States of a commit status.
+const (
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// Environment variables holding the API tokens. GitHub Actions provides
+// GITHUB_TOKEN; GitLab's CI_JOB_TOKEN cannot post statuses, so a project or
+// personal access token with the api scope is needed.
+const (
+	EnvGitHubToken = "GITHUB_TOKEN"
+	EnvGitLabToken = "GITLAB_TOKEN"
+)
+
+// maxDescription is the length, in characters, GitHub truncates status
+// descriptions to.
+const maxDescription = 140
+
+const postTimeout = 10 * time.Second
+
+// Target is the commit a status is posted to, with the API to post it with.
+type Target struct {
+	Provider string
+	// APIURL is the base URL of the REST API, e.g. https://api.github.com or
+	// https://gitlab.com/api/v4.
+	APIURL string
+	// Project is owner/name on GitHub and the numeric project ID on GitLab.
+	Project string
+	Commit  string
+	Token   string
+	// RunURL is the page of the CI run, the default link of the status.
+	RunURL string
+}
+
+// Status is the commit status to post.
+type Status struct {
+	State       string
+	Description string
+	// Context names the status among the others of the commit.
+	Context   string
+	TargetURL string
+}
+
+// Detect returns the target of provider from the CI environment read with
+// getenv. ProviderAuto selects GitHub Actions or GitLab CI from
+// GITHUB_ACTIONS and GITLAB_CI. On GitHub pull request events, whose
+// GITHUB_SHA is a merge commit, the target is the head commit of the pull
+// request read from the GITHUB_EVENT_PATH payload.
+func Detect(provider string, getenv func(string) string) (Target, error) {
+	if provider == ProviderAuto {
+		switch {
+		case getenv("GITHUB_ACTIONS") == "true":
+			provider = ProviderGitHub
+		case getenv("GITLAB_CI") == "true":
+			provider = ProviderGitLab
+		default:
+			return Target{}, fmt.Errorf("no supported CI environment detected, expected GitHub Actions or GitLab CI")
+		}
+	}
+
+	var t Target
+	var required []string
+	switch provider {
+	case ProviderGitHub:
+		server := envOr(getenv, "GITHUB_SERVER_URL", "https://github.com")
+		t = Target{
+			Provider: ProviderGitHub,
+			APIURL:   envOr(getenv, "GITHUB_API_URL", "https://api.github.com"),
+			Project:  getenv("GITHUB_REPOSITORY"),
+			Commit:   getenv("GITHUB_SHA"),
+			Token:    getenv(EnvGitHubToken),
+		}
+		if runID := getenv("GITHUB_RUN_ID"); runID != "" && t.Project != "" {
+			t.RunURL = server + "/" + t.Project + "/actions/runs/" + runID
+		}
+		head, err := pullRequestHead(getenv("GITHUB_EVENT_PATH"))
+		if err != nil {
+			return Target{}, err
+		}
+		if head != "" {
+			t.Commit = head
+		}
+		required = []string{"GITHUB_REPOSITORY", "GITHUB_SHA", EnvGitHubToken}
+	case ProviderGitLab:
+		t = Target{
+			Provider: ProviderGitLab,
+			APIURL:   envOr(getenv, "CI_API_V4_URL", "https://gitlab.com/api/v4"),
+			Project:  getenv("CI_PROJECT_ID"),
+			Commit:   getenv("CI_COMMIT_SHA"),
+			Token:    getenv(EnvGitLabToken),
+			RunURL:   getenv("CI_JOB_URL"),
+		}
+		required = []string{"CI_PROJECT_ID", "CI_COMMIT_SHA", EnvGitLabToken}
+	default:
+		return Target{}, fmt.Errorf("invalid status provider %q, valid values are: %s, %s, %s", provider, ProviderAuto, ProviderGitHub, ProviderGitLab)
+	}
+
+	var missing []string
+	for _, name := range required {
+		if getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return Target{}, fmt.Errorf("cannot report status to %s: %s not set", provider, strings.Join(missing, ", "))
+	}
+	return t, nil
+}
+
+// pullRequestHead returns pull_request.head.sha of the GitHub event payload
+// at path, or "" when there is no payload or the event is not about a pull
+// request.
+func pullRequestHead(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the GitHub event payload: %w", err)
+	}
+	var event struct {
+		PullRequest struct {
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", fmt.Errorf("failed to parse the GitHub event payload %s: %w", path, err)
+	}
+	return event.PullRequest.Head.SHA, nil
+}
+
+func envOr(getenv func(string) string, name, fallback string) string {
+	if v := getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// truncateDescription shortens description to maxDescription characters,
+// ending with "...", without splitting a multi-byte character.
+func truncateDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= maxDescription {
+		return description
+	}
+	return string(runes[:maxDescription-3]) + "..."
+}
+
+// Post posts status to the commit of t.
+func Post(ctx context.Context, t Target, s Status) error {
+	s.Description = truncateDescription(s.Description)
+
+	var endpoint string
+	var body map[string]string
+	var header, token string
+	switch t.Provider {
+	case ProviderGitHub:
+		endpoint = fmt.Sprintf("%s/repos/%s/statuses/%s", strings.TrimSuffix(t.APIURL, "/"), t.Project, url.PathEscape(t.Commit))
+		body = map[string]string{"state": s.State, "description": s.Description, "context": s.Context, "target_url": s.TargetURL}
+		header, token = "Authorization", "Bearer "+t.Token
+	case ProviderGitLab:
+		// GitLab has no failure state distinct from errors.
+		state := s.State
+		if state != StateSuccess {
+			state = "failed"
+		}
+		endpoint = fmt.Sprintf("%s/projects/%s/statuses/%s", strings.TrimSuffix(t.APIURL, "/"), url.PathEscape(t.Project), url.PathEscape(t.Commit))
+		body = map[string]string{"state": state, "description": s.Description, "name": s.Context, "target_url": s.TargetURL}
+		header, token = "PRIVATE-TOKEN", t.Token
+	default:
+		return fmt.Errorf("invalid status provider %q", t.Provider)
+	}
+	if s.TargetURL == "" {
+		delete(body, "target_url")
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error encoding status: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating status request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(header, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting status: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d for the commit status", t.Provider, resp.StatusCode)
+	}
+	return nil
+}
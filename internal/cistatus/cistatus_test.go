@@ -0,0 +1,182 @@
+package cistatus
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestDetect(t *testing.T) {
+	github := map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_REPOSITORY": "org/app",
+		"GITHUB_SHA":        "abc123",
+		"GITHUB_RUN_ID":     "42",
+		"GITHUB_TOKEN":      "ghs_token",
+	}
+	gitlab := map[string]string{
+		"GITLAB_CI":     "true",
+		"CI_API_V4_URL": "https://gitlab.example.com/api/v4",
+		"CI_PROJECT_ID": "7",
+		"CI_COMMIT_SHA": "def456",
+		"CI_JOB_URL":    "https://gitlab.example.com/org/app/-/jobs/9",
+		"GITLAB_TOKEN":  "glpat-token",
+	}
+
+	t.Run("GitHub Actions", func(t *testing.T) {
+		target, err := Detect(ProviderAuto, envFunc(github))
+		require.NoError(t, err)
+		assert.Equal(t, Target{
+			Provider: ProviderGitHub,
+			APIURL:   "https://api.github.com",
+			Project:  "org/app",
+			Commit:   "abc123",
+			Token:    "ghs_token",
+			RunURL:   "https://github.com/org/app/actions/runs/42",
+		}, target)
+	})
+
+	t.Run("GitHub pull request", func(t *testing.T) {
+		eventPath := filepath.Join(t.TempDir(), "event.json")
+		require.NoError(t, os.WriteFile(eventPath, []byte(`{"pull_request": {"head": {"sha": "head789"}}}`), 0600))
+		env := maps.Clone(github)
+		env["GITHUB_EVENT_PATH"] = eventPath
+		target, err := Detect(ProviderAuto, envFunc(env))
+		require.NoError(t, err)
+		assert.Equal(t, "head789", target.Commit, "pull requests report on the head commit, not the merge commit")
+
+		require.NoError(t, os.WriteFile(eventPath, []byte(`{"ref": "refs/heads/main"}`), 0600))
+		target, err = Detect(ProviderAuto, envFunc(env))
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", target.Commit, "other events report on GITHUB_SHA")
+
+		env["GITHUB_EVENT_PATH"] = filepath.Join(t.TempDir(), "missing.json")
+		target, err = Detect(ProviderAuto, envFunc(env))
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", target.Commit)
+
+		require.NoError(t, os.WriteFile(eventPath, []byte(`{`), 0600))
+		env["GITHUB_EVENT_PATH"] = eventPath
+		_, err = Detect(ProviderAuto, envFunc(env))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse the GitHub event payload")
+	})
+
+	t.Run("GitLab CI", func(t *testing.T) {
+		target, err := Detect(ProviderAuto, envFunc(gitlab))
+		require.NoError(t, err)
+		assert.Equal(t, Target{
+			Provider: ProviderGitLab,
+			APIURL:   "https://gitlab.example.com/api/v4",
+			Project:  "7",
+			Commit:   "def456",
+			Token:    "glpat-token",
+			RunURL:   "https://gitlab.example.com/org/app/-/jobs/9",
+		}, target)
+	})
+
+	t.Run("No CI", func(t *testing.T) {
+		_, err := Detect(ProviderAuto, envFunc(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no supported CI environment detected")
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		_, err := Detect(ProviderGitLab, envFunc(map[string]string{"CI_PROJECT_ID": "7", "CI_COMMIT_SHA": "def456"}))
+		require.Error(t, err)
+		assert.Equal(t, "cannot report status to gitlab: GITLAB_TOKEN not set", err.Error())
+	})
+
+	t.Run("Invalid provider", func(t *testing.T) {
+		_, err := Detect("bitbucket", envFunc(github))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid status provider "bitbucket"`)
+	})
+}
+
+func TestPost(t *testing.T) {
+	var gotPath, gotAuth, gotToken string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if strings.Contains(r.URL.Path, "denied") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	status := Status{State: StateFailure, Description: "nginx:latest failed validation", Context: "check-image", TargetURL: "https://ci.example.com/run/1"}
+
+	t.Run("GitHub", func(t *testing.T) {
+		target := Target{Provider: ProviderGitHub, APIURL: server.URL, Project: "org/app", Commit: "abc123", Token: "ghs_token"}
+		require.NoError(t, Post(context.Background(), target, status))
+		assert.Equal(t, "/repos/org/app/statuses/abc123", gotPath)
+		assert.Equal(t, "Bearer ghs_token", gotAuth)
+		assert.Equal(t, map[string]string{
+			"state":       "failure",
+			"description": "nginx:latest failed validation",
+			"context":     "check-image",
+			"target_url":  "https://ci.example.com/run/1",
+		}, gotBody)
+	})
+
+	t.Run("GitLab", func(t *testing.T) {
+		target := Target{Provider: ProviderGitLab, APIURL: server.URL + "/", Project: "7", Commit: "def456", Token: "glpat-token"}
+		noLink := status
+		noLink.TargetURL = ""
+		require.NoError(t, Post(context.Background(), target, noLink))
+		assert.Equal(t, "/projects/7/statuses/def456", gotPath)
+		assert.Equal(t, "glpat-token", gotToken)
+		assert.Equal(t, map[string]string{
+			"state":       "failed",
+			"description": "nginx:latest failed validation",
+			"name":        "check-image",
+		}, gotBody)
+	})
+
+	t.Run("Long description", func(t *testing.T) {
+		target := Target{Provider: ProviderGitHub, APIURL: server.URL, Project: "org/app", Commit: "abc123", Token: "t"}
+		long := status
+		long.Description = strings.Repeat("a", 200)
+		require.NoError(t, Post(context.Background(), target, long))
+		assert.Len(t, gotBody["description"], maxDescription)
+		assert.True(t, strings.HasSuffix(gotBody["description"], "..."))
+	})
+
+	t.Run("Long multi-byte description", func(t *testing.T) {
+		target := Target{Provider: ProviderGitHub, APIURL: server.URL, Project: "org/app", Commit: "abc123", Token: "t"}
+		long := status
+		long.Description = strings.Repeat("é", 200)
+		require.NoError(t, Post(context.Background(), target, long))
+		assert.True(t, utf8.ValidString(gotBody["description"]), "characters are not split")
+		assert.Equal(t, maxDescription, utf8.RuneCountInString(gotBody["description"]))
+		assert.Equal(t, strings.Repeat("é", maxDescription-3)+"...", gotBody["description"])
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		target := Target{Provider: ProviderGitHub, APIURL: server.URL, Project: "org/denied", Commit: "abc123", Token: "t"}
+		err := Post(context.Background(), target, status)
+		require.Error(t, err)
+		assert.Equal(t, "github returned status 403 for the commit status", err.Error())
+	})
+}
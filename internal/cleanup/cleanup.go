@@ -0,0 +1,221 @@
+// Package cleanup finds and removes the leftovers of check-image runs: stale
+// temporary files and directories, and layer cache entries past their TTL or
+// beyond the size limit of the cache.
+package cleanup
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// Reasons an item is removed.
+const (
+	// ReasonStale marks a temporary file or directory older than the
+	// minimum age.
+	ReasonStale = "stale"
+	// ReasonExpired marks a cache entry not used within the TTL.
+	ReasonExpired = "expired"
+	// ReasonOverSize marks a cache entry evicted to bring the cache under its
+	// size limit, least recently used first.
+	ReasonOverSize = "over-size"
+)
+
+// KindCacheEntry and KindCachePartial are the kinds of layer cache files: a
+// cached layer, and the partial download of one that was never committed.
+const (
+	KindCacheEntry   = "cache-entry"
+	KindCachePartial = "cache-partial"
+)
+
+// TempPattern matches the temporary files or directories of a kind, as
+// filepath.Match patterns of their base names.
+type TempPattern struct {
+	Kind    string
+	Pattern string
+}
+
+// FindTemp returns the entries of dir matching patterns that were last
+// modified before cutoff. A missing dir has none.
+func FindTemp(dir string, patterns []TempPattern, cutoff time.Time) ([]output.CleanupItem, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %w", dir, err)
+	}
+
+	var items []output.CleanupItem
+	for _, e := range entries {
+		kind := matchKind(e.Name(), patterns)
+		if kind == "" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		size := info.Size()
+		if e.IsDir() {
+			size = dirSize(path)
+		}
+		items = append(items, output.CleanupItem{
+			Path:     path,
+			Kind:     kind,
+			Size:     size,
+			Modified: info.ModTime().UTC().Format(time.RFC3339),
+			Reason:   ReasonStale,
+		})
+	}
+	return items, nil
+}
+
+// matchKind returns the kind of the first pattern matching name, or "".
+func matchKind(name string, patterns []TempPattern) string {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p.Pattern, name); ok {
+			return p.Kind
+		}
+	}
+	return ""
+}
+
+// dirSize returns the total size of the regular files under dir, ignoring
+// the entries that cannot be read.
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// CacheOptions selects the layer cache entries to remove: entries not used
+// within TTL (0 keeps them), then the least recently used entries until the
+// cache holds at most MaxSize bytes (0 for no limit). Partial downloads last
+// modified before PartialCutoff are always removed.
+type CacheOptions struct {
+	TTL           time.Duration
+	MaxSize       int64
+	PartialCutoff time.Time
+	Now           time.Time
+}
+
+// cacheAlgorithm is the digest algorithm of the cached layers, and the name
+// of the directory they are stored in.
+const cacheAlgorithm = "sha256"
+
+// FindCache returns the entries of the layer cache in dir to remove. Cached
+// layers are stored as <algorithm>/<hex>, and their modification time is the
+// time they were last used; partial downloads are stored next to them as
+// .partial-*. Any other file is not part of the cache and is left alone, so
+// that a --cache-dir pointed at a directory holding other files does not
+// lose them. A missing dir has none.
+func FindCache(dir string, opts CacheOptions) (items []output.CleanupItem, kept int64, err error) {
+	layersDir := filepath.Join(dir, cacheAlgorithm)
+	files, err := os.ReadDir(layersDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, fmt.Errorf("error reading cache directory %s: %w", dir, err)
+	}
+
+	var entries []output.CleanupItem
+	var modified []time.Time
+	for _, f := range files {
+		partial := strings.HasPrefix(f.Name(), ".partial-")
+		if !partial && !isDigestHex(f.Name()) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		item := output.CleanupItem{
+			Path:     filepath.Join(layersDir, f.Name()),
+			Kind:     KindCacheEntry,
+			Size:     info.Size(),
+			Modified: info.ModTime().UTC().Format(time.RFC3339),
+		}
+		if partial {
+			if info.ModTime().Before(opts.PartialCutoff) {
+				item.Kind, item.Reason = KindCachePartial, ReasonStale
+				items = append(items, item)
+			}
+			continue
+		}
+		if opts.TTL > 0 && opts.Now.Sub(info.ModTime()) > opts.TTL {
+			item.Reason = ReasonExpired
+			items = append(items, item)
+			continue
+		}
+		entries = append(entries, item)
+		modified = append(modified, info.ModTime())
+	}
+
+	for _, e := range entries {
+		kept += e.Size
+	}
+	if opts.MaxSize <= 0 || kept <= opts.MaxSize {
+		return items, kept, nil
+	}
+
+	// Evict the least recently used entries first.
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Or(modified[a].Compare(modified[b]), cmp.Compare(entries[a].Path, entries[b].Path))
+	})
+	for _, i := range order {
+		if kept <= opts.MaxSize {
+			break
+		}
+		e := entries[i]
+		e.Reason = ReasonOverSize
+		items = append(items, e)
+		kept -= e.Size
+	}
+	return items, kept, nil
+}
+
+// isDigestHex reports whether name is the lowercase hex of a sha256 digest,
+// the name of a cached layer.
+func isDigestHex(name string) bool {
+	if len(name) != 64 {
+		return false
+	}
+	for _, c := range name {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes the items, returning the errors of those it could not
+// remove. Items already gone are not errors.
+func Remove(items []output.CleanupItem) error {
+	var errs []error
+	for _, item := range items {
+		if err := os.RemoveAll(item.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("error removing %s: %w", item.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
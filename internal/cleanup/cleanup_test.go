@@ -0,0 +1,148 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// writeFile creates path with size bytes, last modified at modified.
+func writeFile(t *testing.T, path string, size int, modified time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0600))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+}
+
+func paths(items []output.CleanupItem) []string {
+	var out []string
+	for _, item := range items {
+		out = append(out, filepath.Base(item.Path))
+	}
+	return out
+}
+
+func TestFindTemp(t *testing.T) {
+	dir := t.TempDir()
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	writeFile(t, filepath.Join(dir, "check-image-secrets-policy-123.json"), 10, old)
+	writeFile(t, filepath.Join(dir, "check-image-labels-policy-456.json"), 10, recent)
+	writeFile(t, filepath.Join(dir, "check-image-oci-archive-789", "index.json"), 25, old)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "check-image-oci-archive-789"), old, old))
+	writeFile(t, filepath.Join(dir, "unrelated.json"), 10, old)
+
+	patterns := []TempPattern{
+		{Kind: "temp-policy", Pattern: "check-image-*-policy-*.json"},
+		{Kind: "oci-archive", Pattern: "check-image-oci-archive-*"},
+	}
+	items, err := FindTemp(dir, patterns, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, []string{"check-image-oci-archive-789", "check-image-secrets-policy-123.json"}, paths(items))
+	assert.Equal(t, "oci-archive", items[0].Kind)
+	assert.Equal(t, int64(25), items[0].Size, "the size of a directory is the size of its files")
+	assert.Equal(t, ReasonStale, items[1].Reason)
+	assert.Equal(t, old.Format(time.RFC3339), items[1].Modified)
+
+	items, err = FindTemp(filepath.Join(dir, "missing"), patterns, now)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+// digestHex returns a cache entry name made of c repeated.
+func digestHex(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+func TestFindCache(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "sha256", digestHex("a")), 100, now.Add(-40*24*time.Hour))
+	writeFile(t, filepath.Join(dir, "sha256", digestHex("b")), 100, now.Add(-3*24*time.Hour))
+	writeFile(t, filepath.Join(dir, "sha256", digestHex("c")), 100, now.Add(-2*24*time.Hour))
+	writeFile(t, filepath.Join(dir, "sha256", digestHex("d")), 100, now.Add(-time.Hour))
+	writeFile(t, filepath.Join(dir, "sha256", ".partial-1"), 50, now.Add(-48*time.Hour))
+	writeFile(t, filepath.Join(dir, "sha256", ".partial-2"), 50, now.Add(-time.Minute))
+
+	t.Run("ttl", func(t *testing.T) {
+		items, kept, err := FindCache(dir, CacheOptions{TTL: 30 * 24 * time.Hour, PartialCutoff: now.Add(-24 * time.Hour), Now: now})
+		require.NoError(t, err)
+		assert.Equal(t, []string{".partial-1", digestHex("a")}, paths(items))
+		assert.Equal(t, KindCachePartial, items[0].Kind)
+		assert.Equal(t, ReasonExpired, items[1].Reason)
+		assert.Equal(t, int64(300), kept)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		items, kept, err := FindCache(dir, CacheOptions{TTL: 30 * 24 * time.Hour, MaxSize: 150, PartialCutoff: now.Add(-24 * time.Hour), Now: now})
+		require.NoError(t, err)
+		assert.Equal(t, []string{".partial-1", digestHex("a"), digestHex("b"), digestHex("c")}, paths(items), "least recently used first")
+		assert.Equal(t, ReasonOverSize, items[2].Reason)
+		assert.Equal(t, int64(100), kept)
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		items, kept, err := FindCache(dir, CacheOptions{Now: now})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.Equal(t, int64(400), kept)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		items, kept, err := FindCache(filepath.Join(dir, "missing"), CacheOptions{Now: now})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.Zero(t, kept)
+	})
+}
+
+func TestFindCache_ForeignFilesSurvive(t *testing.T) {
+	dir := t.TempDir()
+	old := now.Add(-400 * 24 * time.Hour)
+	entry := filepath.Join(dir, "sha256", digestHex("a"))
+	writeFile(t, entry, 100, old)
+	foreign := []string{
+		filepath.Join(dir, "notes.txt"),
+		filepath.Join(dir, "sha256", "README"),
+		filepath.Join(dir, "sha256", strings.ToUpper(digestHex("b"))),
+		filepath.Join(dir, "sha256", "nested", digestHex("c")),
+		filepath.Join(dir, "sha512", digestHex("d")),
+		filepath.Join(dir, ".partial-1"),
+	}
+	for _, path := range foreign {
+		writeFile(t, path, 100, old)
+	}
+
+	items, kept, err := FindCache(dir, CacheOptions{TTL: time.Hour, MaxSize: 1, PartialCutoff: now, Now: now})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, entry, items[0].Path)
+	assert.Zero(t, kept, "foreign files do not count towards the cache size")
+
+	require.NoError(t, Remove(items))
+	assert.NoFileExists(t, entry)
+	for _, path := range foreign {
+		assert.FileExists(t, path)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "file.json"), 1, now)
+	writeFile(t, filepath.Join(dir, "tree", "nested", "file"), 1, now)
+
+	require.NoError(t, Remove([]output.CleanupItem{
+		{Path: filepath.Join(dir, "file.json")},
+		{Path: filepath.Join(dir, "tree")},
+		{Path: filepath.Join(dir, "already-gone")},
+	}))
+	assert.NoFileExists(t, filepath.Join(dir, "file.json"))
+	assert.NoDirExists(t, filepath.Join(dir, "tree"))
+}
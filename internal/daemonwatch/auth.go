@@ -0,0 +1,32 @@
+package daemonwatch
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// RequireToken returns a handler that serves next only to requests that
+// present token, either as a bearer token or as the password of HTTP basic
+// authentication, which browsers prompt for; the basic auth user name is
+// ignored. Other requests are answered 401.
+func RequireToken(token string, next http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := requestToken(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="check-image"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestToken returns the token presented by r, if any.
+func requestToken(r *http.Request) (string, bool) {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password, true
+	}
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,41 @@
+package daemonwatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireToken(t *testing.T) {
+	handler := RequireToken("s3cret", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name string
+		auth func(r *http.Request)
+		want int
+	}{
+		{"No credentials", func(*http.Request) {}, http.StatusUnauthorized},
+		{"Bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, http.StatusNoContent},
+		{"Wrong bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") }, http.StatusUnauthorized},
+		{"Basic auth password", func(r *http.Request) { r.SetBasicAuth("anyone", "s3cret") }, http.StatusNoContent},
+		{"Wrong basic auth password", func(r *http.Request) { r.SetBasicAuth("s3cret", "guess") }, http.StatusUnauthorized},
+		{"Empty bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/policy", nil)
+			tt.auth(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="check-image"`, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
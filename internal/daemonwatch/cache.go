@@ -0,0 +1,47 @@
+package daemonwatch
+
+import "time"
+
+// Cache holds validation results for a limited time, so an image that
+// arrives several times in a burst (a pull followed by tags of the same
+// image) is validated once. Keys identify the image content and the policy.
+type Cache[V any] struct {
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cacheEntry[V]
+}
+
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewCache returns a cache whose entries expire after ttl. A zero ttl
+// disables the cache.
+func NewCache[V any](ttl time.Duration) *Cache[V] {
+	return &Cache[V]{ttl: ttl, now: time.Now, entries: map[string]cacheEntry[V]{}}
+}
+
+// Get returns the value stored under key, unless it expired.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Put stores value under key and drops expired entries.
+func (c *Cache[V]) Put(key string, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry[V]{value: value, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,36 @@
+package daemonwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCache[string](time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, ok := c.Get("sha256:a")
+	assert.False(t, ok)
+
+	c.Put("sha256:a", "passed")
+	v, ok := c.Get("sha256:a")
+	assert.True(t, ok)
+	assert.Equal(t, "passed", v)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("sha256:a")
+	assert.False(t, ok, "entries expire after the ttl")
+
+	c.Put("sha256:b", "failed")
+	assert.Len(t, c.entries, 1, "expired entries are dropped")
+}
+
+func TestCache_Disabled(t *testing.T) {
+	c := NewCache[string](0)
+	c.Put("sha256:a", "passed")
+	_, ok := c.Get("sha256:a")
+	assert.False(t, ok)
+}
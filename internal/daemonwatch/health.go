@@ -0,0 +1,103 @@
+package daemonwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// healthShutdownTimeout bounds how long the health and dashboard servers wait
+// for open requests when they stop.
+const healthShutdownTimeout = 5 * time.Second
+
+// Health reports the state of a watch to Kubernetes-style probes. /healthz
+// answers 200 while the process runs; /readyz answers 200 only while events
+// are being watched, and 503 before the event stream is subscribed and while
+// in-flight validations drain on shutdown. /policy reports the hash of the
+// active policy. When Token is set, /policy requires it (see RequireToken);
+// the probes do not, since Kubernetes and load balancers cannot authenticate.
+type Health struct {
+	ready      atomic.Bool
+	policyHash atomic.Value
+	// Token is the token required by /policy, set before Serve; empty serves
+	// it without authentication.
+	Token string
+}
+
+// SetReady marks the watch as ready, or not ready, for /readyz.
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// SetPolicyHash sets the policy hash reported on /policy.
+func (h *Health) SetPolicyHash(hash string) {
+	h.policyHash.Store(hash)
+}
+
+// Handler serves the /healthz and /readyz probes and the /policy endpoint.
+func (h *Health) Handler() http.Handler {
+	var policy http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hash, _ := h.policyHash.Load().(string)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"policy-hash": hash})
+	})
+	if h.Token != "" {
+		policy = RequireToken(h.Token, policy)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeProbe(w, http.StatusOK, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !h.ready.Load() {
+			writeProbe(w, http.StatusServiceUnavailable, "not ready")
+			return
+		}
+		writeProbe(w, http.StatusOK, "ok")
+	})
+	mux.Handle("GET /policy", policy)
+	return mux
+}
+
+func writeProbe(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintln(w, body)
+}
+
+// Serve listens on addr and serves the endpoints of h. It returns once the
+// listener is open; the server stops when ctx ends. Errors of the running
+// server are sent on the returned channel.
+func (h *Health) Serve(ctx context.Context, addr string) (<-chan error, error) {
+	return serve(ctx, "health", addr, h.Handler())
+}
+
+// serve listens on addr and serves handler until ctx ends. name identifies
+// the server in errors.
+func serve(ctx context.Context, name, addr string, handler http.Handler) (<-chan error, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s address %s: %w", name, addr, err)
+	}
+
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	errs := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("%s server failed: %w", name, err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	return errs, nil
+}
@@ -0,0 +1,72 @@
+package daemonwatch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	h := &Health{}
+	handler := h.Handler()
+
+	probe := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, probe("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe("/readyz"), "not ready before the watch starts")
+
+	h.SetReady(true)
+	assert.Equal(t, http.StatusOK, probe("/readyz"))
+
+	h.SetReady(false)
+	assert.Equal(t, http.StatusServiceUnavailable, probe("/readyz"), "not ready while draining")
+	assert.Equal(t, http.StatusOK, probe("/healthz"), "still live while draining")
+
+	assert.Equal(t, http.StatusNotFound, probe("/metrics"))
+}
+
+func TestHealthHandler_Policy(t *testing.T) {
+	h := &Health{}
+	h.SetPolicyHash("sha256:abc")
+
+	rec := httptest.NewRecorder()
+	h.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/policy", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"policy-hash":"sha256:abc"}`, rec.Body.String())
+}
+
+func TestHealthHandler_Token(t *testing.T) {
+	h := &Health{Token: "s3cret"}
+	h.SetReady(true)
+	handler := h.Handler()
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz", ""), "probes are not authenticated")
+	assert.Equal(t, http.StatusOK, get("/readyz", ""), "probes are not authenticated")
+	assert.Equal(t, http.StatusUnauthorized, get("/policy", ""))
+	assert.Equal(t, http.StatusOK, get("/policy", "s3cret"))
+}
+
+func TestHealthServe_InvalidAddress(t *testing.T) {
+	_, err := (&Health{}).Serve(context.Background(), "invalid-address")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen on health address invalid-address")
+}
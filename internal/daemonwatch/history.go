@@ -0,0 +1,53 @@
+package daemonwatch
+
+import (
+	"sync"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+)
+
+// History keeps the last validations shown on the dashboard in a ring, so
+// that pages are served from memory whatever the size of the audit log.
+// Validations are numbered from 1 in the order they were added; the numbers
+// of the validations that dropped out of the ring are not reused.
+type History struct {
+	mu      sync.Mutex
+	records []auditlog.Record
+	// next is the index of records the next validation is written to once
+	// the ring is full.
+	next int
+	// added is the number of validations added so far.
+	added int
+}
+
+// NewHistory returns a history that keeps the last size validations.
+func NewHistory(size int) *History {
+	return &History{records: make([]auditlog.Record, 0, size)}
+}
+
+// Add adds a validation, dropping the oldest one when the history is full.
+func (h *History) Add(r auditlog.Record) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.added++
+	if len(h.records) < cap(h.records) {
+		h.records = append(h.records, r)
+		return
+	}
+	if len(h.records) == 0 {
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+}
+
+// Records returns the kept validations, oldest first, and the number of the
+// first one.
+func (h *History) Records() ([]auditlog.Record, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records := make([]auditlog.Record, 0, len(h.records))
+	records = append(records, h.records[h.next:]...)
+	records = append(records, h.records[:h.next]...)
+	return records, h.added - len(records) + 1
+}
@@ -0,0 +1,46 @@
+package daemonwatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func images(records []auditlog.Record) []string {
+	var names []string
+	for _, r := range records {
+		names = append(names, r.Image)
+	}
+	return names
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	records, first := h.Records()
+	assert.Empty(t, records)
+	assert.Equal(t, 1, first)
+
+	for i := 1; i <= 2; i++ {
+		h.Add(auditlog.Record{Image: fmt.Sprintf("app:%d", i)})
+	}
+	records, first = h.Records()
+	assert.Equal(t, []string{"app:1", "app:2"}, images(records))
+	assert.Equal(t, 1, first)
+
+	for i := 3; i <= 7; i++ {
+		h.Add(auditlog.Record{Image: fmt.Sprintf("app:%d", i)})
+	}
+	records, first = h.Records()
+	assert.Equal(t, []string{"app:5", "app:6", "app:7"}, images(records), "the oldest validations are dropped")
+	assert.Equal(t, 5, first)
+}
+
+func TestHistory_Empty(t *testing.T) {
+	h := NewHistory(0)
+	h.Add(auditlog.Record{Image: "app:1"})
+	records, first := h.Records()
+	assert.Empty(t, records)
+	assert.Equal(t, 2, first)
+}
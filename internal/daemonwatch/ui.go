@@ -0,0 +1,203 @@
+package daemonwatch
+
+import (
+	"cmp"
+	"context"
+	"html/template"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	log "github.com/sirupsen/logrus"
+)
+
+// uiRecent is the number of validations listed on the dashboard.
+const uiRecent = 50
+
+// NewUI returns the handler of the dashboard served on /ui: the pass rate of
+// the validations kept in history, the pass rate of each repository, and the
+// most recent validations, each linked to /ui/validations/{n}, which shows its
+// record. Pages are rendered from history as it is at the time of the request.
+func NewUI(history *History) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ui", func(w http.ResponseWriter, _ *http.Request) {
+		renderPage(w, uiDashboard, buildDashboard(history.Records()))
+	})
+	mux.HandleFunc("GET /ui/validations/{n}", func(w http.ResponseWriter, r *http.Request) {
+		records, first := history.Records()
+		n, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil || n < first || n >= first+len(records) {
+			http.NotFound(w, r)
+			return
+		}
+		renderPage(w, uiValidation, validationView{ID: n, Record: records[n-first]})
+	})
+	return mux
+}
+
+// ServeUI listens on addr and serves ui, the dashboard, on a listener of its
+// own, so that it can be bound to another interface than the probes. It
+// returns once the listener is open; the server stops when ctx ends. Errors of
+// the running server are sent on the returned channel.
+func ServeUI(ctx context.Context, addr string, ui http.Handler) (<-chan error, error) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /ui", ui)
+	mux.Handle("GET /ui/", ui)
+	mux.Handle("GET /{$}", http.RedirectHandler("/ui", http.StatusFound))
+	return serve(ctx, "dashboard", addr, mux)
+}
+
+func renderPage(w http.ResponseWriter, page *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		log.WithField("error", err).Debug("Unable to render the dashboard")
+	}
+}
+
+// dashboard is the data of the /ui page.
+type dashboard struct {
+	Validations  int
+	Passed       int
+	PassRate     float64
+	Repositories []repositoryStats
+	Recent       []validationView
+}
+
+// repositoryStats summarizes the validations of the images of a repository.
+type repositoryStats struct {
+	Repository  string
+	Validations int
+	Passed      int
+	PassRate    float64
+	// Last is the most recent validation of the repository.
+	Last validationView
+}
+
+type validationView struct {
+	ID int
+	auditlog.Record
+}
+
+// buildDashboard summarizes records, numbered from first. Repositories are listed with the lowest
+// pass rate first, so that the ones needing attention lead.
+func buildDashboard(records []auditlog.Record, first int) dashboard {
+	d := dashboard{Validations: len(records)}
+	byRepo := make(map[string]*repositoryStats)
+	for i, r := range records {
+		v := validationView{ID: first + i, Record: r}
+		repo := repositoryOf(r.Image)
+		stats := byRepo[repo]
+		if stats == nil {
+			stats = &repositoryStats{Repository: repo}
+			byRepo[repo] = stats
+		}
+		stats.Validations++
+		stats.Last = v
+		if r.Passed {
+			stats.Passed++
+			d.Passed++
+		}
+	}
+	d.PassRate = percent(d.Passed, d.Validations)
+
+	for _, stats := range byRepo {
+		stats.PassRate = percent(stats.Passed, stats.Validations)
+		d.Repositories = append(d.Repositories, *stats)
+	}
+	slices.SortFunc(d.Repositories, func(a, b repositoryStats) int {
+		return cmp.Or(cmp.Compare(a.PassRate, b.PassRate), strings.Compare(a.Repository, b.Repository))
+	})
+
+	for i := len(records) - 1; i >= 0 && len(d.Recent) < uiRecent; i-- {
+		d.Recent = append(d.Recent, validationView{ID: first + i, Record: records[i]})
+	}
+	return d
+}
+
+// repositoryOf returns image without its tag or digest.
+func repositoryOf(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// percent returns n of total as a percentage rounded to one decimal, or 0
+// when total is 0.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*1000/float64(total)) / 10
+}
+
+const uiStyle = `<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.num { text-align: right; }
+.bar { background: #eee; width: 10em; height: 0.8em; }
+.bar div { background: #2e8b57; height: 100%; }
+.passed { color: #2e8b57; }
+.failed { color: #b22222; }
+</style>`
+
+var uiFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+var uiDashboard = template.Must(template.New("dashboard").Funcs(uiFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>check-image validations</title>
+` + uiStyle + `
+</head>
+<body>
+<h1>check-image validations</h1>
+{{if .Validations}}<p>{{.Passed}} of {{.Validations}} validations ({{printf "%.1f" .PassRate}}%) passed.</p>
+<h2>Repositories</h2>
+<table>
+<tr><th>Repository</th><th>Pass rate</th><th></th><th>Validations</th><th>Passed</th><th>Last validation</th></tr>
+{{range .Repositories}}<tr><td>{{.Repository}}</td><td class="num">{{printf "%.1f" .PassRate}}%</td><td><div class="bar"><div style="width: {{printf "%.1f" .PassRate}}%"></div></div></td><td class="num">{{.Validations}}</td><td class="num">{{.Passed}}</td><td><a href="/ui/validations/{{.Last.ID}}">{{.Last.Time}}</a> {{if .Last.Passed}}<span class="passed">passed</span>{{else}}<span class="failed">failed</span>{{end}}</td></tr>
+{{end}}</table>
+<h2>Recent validations</h2>
+<table>
+<tr><th>#</th><th>Time</th><th>Image</th><th>Command</th><th>Result</th><th>Failed checks</th></tr>
+{{range .Recent}}<tr><td class="num"><a href="/ui/validations/{{.ID}}">{{.ID}}</a></td><td>{{.Time}}</td><td>{{.Image}}</td><td>{{.Command}}</td><td>{{if .Passed}}<span class="passed">passed</span>{{else}}<span class="failed">failed</span>{{end}}</td><td>{{join .FailedChecks ", "}}</td></tr>
+{{end}}</table>
+{{else}}<p>No validations have been recorded yet.</p>
+{{end}}</body>
+</html>
+`))
+
+var uiValidation = template.Must(template.New("validation").Funcs(uiFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Validation {{.ID}} of {{.Image}}</title>
+` + uiStyle + `
+</head>
+<body>
+<p><a href="/ui">All validations</a></p>
+<h1>Validation {{.ID}} of {{.Image}}</h1>
+<table>
+<tr><th>Result</th><td>{{if .Passed}}<span class="passed">passed</span>{{else}}<span class="failed">failed</span>{{end}}</td></tr>
+{{if .FailedChecks}}<tr><th>Failed checks</th><td>{{join .FailedChecks ", "}}</td></tr>
+{{end}}<tr><th>Time</th><td>{{.Time}}</td></tr>
+<tr><th>Image</th><td>{{.Image}}</td></tr>
+{{if .Digest}}<tr><th>Digest</th><td>{{.Digest}}</td></tr>
+{{end}}<tr><th>Command</th><td>{{.Command}}</td></tr>
+<tr><th>Policy hash</th><td>{{.PolicyHash}}</td></tr>
+{{if .PolicyProfile}}<tr><th>Policy profile</th><td>{{.PolicyProfile}}</td></tr>
+{{end}}{{if .User}}<tr><th>User</th><td>{{.User}}</td></tr>
+{{end}}{{if .Host}}<tr><th>Host</th><td>{{.Host}}</td></tr>
+{{end}}<tr><th>Version</th><td>{{.Version}}</td></tr>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,157 @@
+package daemonwatch
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/auditlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHistory() []auditlog.Record {
+	return []auditlog.Record{
+		{Time: "2026-01-02T03:00:00Z", Command: "daemon-watch", Image: "nginx:1.26", Passed: true, PolicyHash: "sha256:aaa"},
+		{Time: "2026-01-02T03:01:00Z", Command: "daemon-watch", Image: "registry.example.com:5000/app:1.0", FailedChecks: []string{"user", "ports"}, PolicyHash: "sha256:aaa"},
+		{Time: "2026-01-02T03:02:00Z", Command: "all", Image: "nginx:1.27", Passed: true, PolicyHash: "sha256:aaa", Digest: "sha256:bbb"},
+		{Time: "2026-01-02T03:03:00Z", Command: "daemon-watch", Image: "registry.example.com:5000/app@sha256:ccc", Passed: true, PolicyHash: "sha256:aaa"},
+	}
+}
+
+// historyOf returns a history that keeps size validations, with records
+// added.
+func historyOf(size int, records ...auditlog.Record) *History {
+	h := NewHistory(size)
+	for _, r := range records {
+		h.Add(r)
+	}
+	return h
+}
+
+func serveUI(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestBuildDashboard(t *testing.T) {
+	d := buildDashboard(testHistory(), 1)
+
+	assert.Equal(t, 4, d.Validations)
+	assert.Equal(t, 3, d.Passed)
+	assert.InDelta(t, 75.0, d.PassRate, 0.001)
+
+	require.Len(t, d.Repositories, 2)
+	assert.Equal(t, "registry.example.com:5000/app", d.Repositories[0].Repository, "lowest pass rate first")
+	assert.Equal(t, 2, d.Repositories[0].Validations)
+	assert.InDelta(t, 50.0, d.Repositories[0].PassRate, 0.001)
+	assert.Equal(t, 4, d.Repositories[0].Last.ID)
+	assert.Equal(t, "nginx", d.Repositories[1].Repository)
+
+	require.Len(t, d.Recent, 4)
+	assert.Equal(t, 4, d.Recent[0].ID, "most recent first")
+	assert.Equal(t, 1, d.Recent[3].ID)
+}
+
+func TestBuildDashboard_RecentLimit(t *testing.T) {
+	records := make([]auditlog.Record, uiRecent+10)
+	for i := range records {
+		records[i] = auditlog.Record{Image: "app:1.0", Passed: true}
+	}
+	d := buildDashboard(records, 1)
+	assert.Len(t, d.Recent, uiRecent)
+	assert.Equal(t, uiRecent+10, d.Recent[0].ID)
+}
+
+func TestBuildDashboard_First(t *testing.T) {
+	d := buildDashboard(testHistory(), 11)
+	assert.Equal(t, 14, d.Recent[0].ID)
+	assert.Equal(t, 11, d.Recent[3].ID)
+	assert.Equal(t, 14, d.Repositories[0].Last.ID)
+}
+
+func TestRepositoryOf(t *testing.T) {
+	assert.Equal(t, "nginx", repositoryOf("nginx:1.27"))
+	assert.Equal(t, "nginx", repositoryOf("nginx"))
+	assert.Equal(t, "localhost:5000/app", repositoryOf("localhost:5000/app"))
+	assert.Equal(t, "localhost:5000/app", repositoryOf("localhost:5000/app:1.0@sha256:abc"))
+}
+
+func TestUI(t *testing.T) {
+	h := NewUI(historyOf(10, testHistory()...))
+
+	rec := serveUI(t, h, "/ui")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "3 of 4 validations (75.0%) passed.")
+	assert.Contains(t, body, "registry.example.com:5000/app")
+	assert.Contains(t, body, `<a href="/ui/validations/2">2</a>`)
+	assert.Contains(t, body, "user, ports")
+
+	rec = serveUI(t, h, "/ui/validations/3")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Validation 3 of nginx:1.27")
+	assert.Contains(t, rec.Body.String(), "sha256:bbb")
+
+	for _, path := range []string{"/ui/validations/0", "/ui/validations/5", "/ui/validations/x", "/ui/other"} {
+		assert.Equal(t, http.StatusNotFound, serveUI(t, h, path).Code, path)
+	}
+}
+
+func TestUI_DroppedValidations(t *testing.T) {
+	h := NewUI(historyOf(2, testHistory()...))
+
+	body := serveUI(t, h, "/ui").Body.String()
+	assert.Contains(t, body, "2 of 2 validations (100.0%) passed.", "only the kept validations are summarized")
+	assert.Equal(t, http.StatusNotFound, serveUI(t, h, "/ui/validations/2").Code, "dropped out of the history")
+	rec := serveUI(t, h, "/ui/validations/3")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Validation 3 of nginx:1.27")
+}
+
+func TestUI_Empty(t *testing.T) {
+	h := NewUI(NewHistory(10))
+	rec := serveUI(t, h, "/ui")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "No validations have been recorded yet.")
+}
+
+func TestUI_EscapesRecords(t *testing.T) {
+	h := NewUI(historyOf(10, auditlog.Record{Image: "<script>alert(1)</script>:1.0"}))
+	body := serveUI(t, h, "/ui").Body.String()
+	assert.NotContains(t, body, "<script>alert(1)</script>")
+	assert.Contains(t, body, "&lt;script&gt;")
+}
+
+func TestServeUI(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = ServeUI(ctx, addr, NewUI(historyOf(10, testHistory()...)))
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	get := func(path string) int {
+		resp, err := client.Get("http://" + addr + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get("/ui"))
+	assert.Equal(t, http.StatusOK, get("/ui/validations/1"))
+	assert.Equal(t, http.StatusFound, get("/"))
+	assert.Equal(t, http.StatusNotFound, get("/healthz"), "the probes are served by the health server")
+
+	_, err = ServeUI(ctx, "invalid-address", NewUI(NewHistory(1)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen on dashboard address invalid-address")
+}
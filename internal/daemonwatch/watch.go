@@ -0,0 +1,126 @@
+package daemonwatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// Actions lists the image event actions that can be watched.
+var Actions = []string{"pull", "load", "tag", "import"}
+
+// DefaultActions are the actions watched when none are given.
+var DefaultActions = []string{"pull", "load", "tag"}
+
+// Event is a new or changed image reported by the daemon.
+type Event struct {
+	Action string
+	Image  string
+	Time   time.Time
+}
+
+// Source streams image events until ctx is cancelled. The event channel is
+// closed when the stream ends; a stream failure is sent on the error channel.
+type Source interface {
+	Events(ctx context.Context) (<-chan Event, <-chan error)
+}
+
+// ParseActions validates a comma-separated list of event actions. An empty
+// list selects DefaultActions.
+func ParseActions(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return DefaultActions, nil
+	}
+	valid := make(map[string]bool, len(Actions))
+	for _, a := range Actions {
+		valid[a] = true
+	}
+	var actions []string
+	for part := range strings.SplitSeq(list, ",") {
+		a := strings.TrimSpace(part)
+		if a == "" {
+			continue
+		}
+		if !valid[a] {
+			return nil, fmt.Errorf("unsupported event %q, valid values are: %s", a, strings.Join(Actions, ", "))
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+type dockerSource struct {
+	client  *client.Client
+	actions []string
+}
+
+// NewDockerSource connects to the Docker daemon configured by the environment
+// (DOCKER_HOST, DOCKER_CERT_PATH, etc.) and watches the given image actions.
+func NewDockerSource(actions []string) (Source, error) {
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return &dockerSource{client: c, actions: actions}, nil
+}
+
+func (s *dockerSource) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	args := filters.NewArgs(filters.Arg("type", string(events.ImageEventType)))
+	for _, a := range s.actions {
+		args.Add("event", a)
+	}
+	messages, errs := s.client.Events(ctx, events.ListOptions{Filters: args})
+
+	out := make(chan Event)
+	outErrs := make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer s.client.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				if ctx.Err() == nil {
+					outErrs <- fmt.Errorf("docker event stream failed: %w", err)
+				}
+				return
+			case msg := <-messages:
+				ev, ok := eventFromMessage(msg)
+				if !ok {
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, outErrs
+}
+
+// eventFromMessage extracts the image reference of a Docker image event.
+// Pull events carry the reference as the actor ID; load, tag, and import
+// events carry the image ID there and the reference in the name attribute.
+// Events without a usable reference are ignored.
+func eventFromMessage(msg events.Message) (Event, bool) {
+	image := msg.Actor.ID
+	if image == "" || strings.HasPrefix(image, "sha256:") {
+		image = msg.Actor.Attributes["name"]
+	}
+	if image == "" || strings.HasPrefix(image, "sha256:") {
+		return Event{}, false
+	}
+	return Event{
+		Action: string(msg.Action),
+		Image:  image,
+		Time:   time.Unix(0, msg.TimeNano),
+	}, true
+}
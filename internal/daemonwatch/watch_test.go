@@ -0,0 +1,114 @@
+package daemonwatch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Empty selects defaults", input: "", want: DefaultActions},
+		{name: "Single action", input: "pull", want: []string{"pull"}},
+		{name: "Whitespace and empty entries", input: " load , import ,", want: []string{"load", "import"}},
+		{name: "Unsupported action", input: "pull,delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseActions(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "unsupported event")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEventFromMessage(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		msg       events.Message
+		wantImage string
+		wantOK    bool
+	}{
+		{
+			name:      "Pull uses the actor ID",
+			msg:       events.Message{Action: "pull", Actor: events.Actor{ID: "nginx:latest", Attributes: map[string]string{"name": "nginx"}}},
+			wantImage: "nginx:latest",
+			wantOK:    true,
+		},
+		{
+			name:      "Tag uses the name attribute",
+			msg:       events.Message{Action: "tag", Actor: events.Actor{ID: "sha256:abc", Attributes: map[string]string{"name": "app:1.0"}}},
+			wantImage: "app:1.0",
+			wantOK:    true,
+		},
+		{
+			name:   "Image ID only is ignored",
+			msg:    events.Message{Action: "load", Actor: events.Actor{ID: "sha256:abc"}},
+			wantOK: false,
+		},
+		{
+			name:   "Empty actor is ignored",
+			msg:    events.Message{Action: "import"},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.msg.TimeNano = ts.UnixNano()
+			ev, ok := eventFromMessage(tt.msg)
+			assert.Equal(t, tt.wantOK, ok)
+			if !tt.wantOK {
+				return
+			}
+			assert.Equal(t, tt.wantImage, ev.Image)
+			assert.Equal(t, string(tt.msg.Action), ev.Action)
+			assert.True(t, ts.Equal(ev.Time))
+		})
+	}
+}
+
+func TestSendAlert(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	require.NoError(t, SendAlert(context.Background(), server.URL, map[string]any{"image": "app:1.0"}))
+	assert.Equal(t, "app:1.0", received["image"])
+}
+
+func TestSendAlert_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := SendAlert(context.Background(), server.URL, map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 502")
+}
@@ -0,0 +1,44 @@
+// Package decision traces the evaluation of policy rules for
+// --explain-decisions: for each rule a check evaluates (a registry list, a
+// label requirement, a port rule, an exclusion), whether it matched and why.
+// Traces are log entries at info level, so they appear on stderr next to the
+// other logs and follow --log-level.
+package decision
+
+import (
+	"fmt"
+
+	"github.com/jarfernandez/check-image/internal/logutil"
+	log "github.com/sirupsen/logrus"
+)
+
+// enabled is set by --explain-decisions.
+var enabled bool
+
+// SetEnabled turns decision tracing on or off.
+func SetEnabled(on bool) {
+	enabled = on
+}
+
+// Enabled reports whether decision tracing is on, so that callers can skip
+// building traces that are not logged.
+func Enabled() bool {
+	return enabled
+}
+
+// Explain logs that check evaluated rule on subject, whether it matched, and
+// why, when decision tracing is on. The reason is formatted with args.
+// Subjects and reasons often carry image-controlled strings, so both are
+// sanitized.
+func Explain(check, rule, subject string, matched bool, reason string, args ...any) {
+	if !Enabled() {
+		return
+	}
+	log.WithFields(log.Fields{
+		"check":   check,
+		"rule":    rule,
+		"subject": logutil.SanitizeLogValue(subject),
+		"matched": matched,
+		"reason":  logutil.SanitizeLogValue(fmt.Sprintf(reason, args...)),
+	}).Info("Policy decision")
+}
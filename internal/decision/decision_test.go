@@ -0,0 +1,42 @@
+package decision
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	out, formatter := log.StandardLogger().Out, log.StandardLogger().Formatter
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.TextFormatter{DisableTimestamp: true, DisableColors: true})
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFormatter(formatter)
+		SetEnabled(false)
+	})
+	return &buf
+}
+
+func TestExplain(t *testing.T) {
+	buf := captureLog(t)
+
+	Explain("registry", "trusted-registries", "docker.io", true, "%s is listed", "docker.io")
+	assert.Empty(t, buf.String(), "nothing is logged while tracing is off")
+
+	SetEnabled(true)
+	assert.True(t, Enabled())
+	Explain("labels", "pattern", "version\nfake", false, "value %q does not match %q", "x\ny", `^\d+$`)
+	line := buf.String()
+	assert.Contains(t, line, `msg="Policy decision"`)
+	assert.Contains(t, line, "check=labels")
+	assert.Contains(t, line, "rule=pattern")
+	assert.Contains(t, line, `subject="version fake"`, "subjects are sanitized")
+	assert.Contains(t, line, "matched=false")
+	assert.Contains(t, line, `reason="value \"x\\ny\" does not match`)
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "sanitized values cannot forge log lines")
+}
@@ -0,0 +1,263 @@
+package deprecation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Rename describes a flag or configuration key that has been replaced. For
+// configuration keys, Old and New are dot-separated paths from the document
+// root (e.g. "checks.root-user"); for flags, they are flag names without the
+// leading dashes.
+type Rename struct {
+	Old   string
+	New   string
+	Since string
+	Note  string
+}
+
+// Notice reports a deprecated name found in a configuration file or on the
+// command line.
+type Notice struct {
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Since   string `json:"since"`
+	Message string `json:"message"`
+}
+
+// ConfigKeys lists the renamed configuration keys of the all command.
+var ConfigKeys = []Rename{
+	{
+		Old:   "checks.root-user",
+		New:   "checks.user",
+		Since: "1.0.0",
+		Note:  "the user check without a policy performs the same non-root validation",
+	},
+}
+
+// Flags lists the renamed command-line flags.
+var Flags = []Rename{}
+
+func configNotice(r Rename, conflict bool) Notice {
+	msg := fmt.Sprintf("Configuration key %s is deprecated since %s, use %s instead", r.Old, r.Since, r.New)
+	if conflict {
+		msg = fmt.Sprintf("Configuration key %s is deprecated since %s and ignored because %s is also set", r.Old, r.Since, r.New)
+	}
+	if r.Note != "" {
+		msg += " (" + r.Note + ")"
+	}
+	return Notice{Old: r.Old, New: r.New, Since: r.Since, Message: msg}
+}
+
+func flagNotice(r Rename) Notice {
+	msg := fmt.Sprintf("Flag --%s is deprecated since %s, use --%s instead", r.Old, r.Since, r.New)
+	if r.Note != "" {
+		msg += " (" + r.Note + ")"
+	}
+	return Notice{Old: "--" + r.Old, New: "--" + r.New, Since: r.Since, Message: msg}
+}
+
+// MigrateConfig rewrites the deprecated keys of a JSON or YAML configuration
+// document to their current names. Key order and, for YAML, comments are
+// preserved. When nothing is deprecated, data is returned unchanged. When both
+// the old and the new key are set, the old one is dropped. JSON comments are
+// accepted but not kept in a migrated JSON document.
+func MigrateConfig(data []byte, isYAML bool, renames []Rename) ([]byte, []Notice, error) {
+	parse, err := fileutil.NormalizeText(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if !isYAML {
+		parse = fileutil.StripJSONComments(parse)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(parse, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+	root := doc.Content[0]
+
+	var notices []Notice
+	for _, r := range renames {
+		if n, ok := migrateKey(root, r); ok {
+			notices = append(notices, n)
+		}
+	}
+	if len(notices) == 0 {
+		return data, nil, nil
+	}
+
+	out, err := encode(&doc, isYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, notices, nil
+}
+
+// migrateKey moves the value at r.Old to r.New. It reports false when r.Old
+// is not present.
+func migrateKey(root *yaml.Node, r Rename) (Notice, bool) {
+	oldPath := strings.Split(r.Old, ".")
+	oldParent := lookupMapping(root, oldPath[:len(oldPath)-1], false)
+	if oldParent == nil {
+		return Notice{}, false
+	}
+	key, value := removeKey(oldParent, oldPath[len(oldPath)-1])
+	if key == nil {
+		return Notice{}, false
+	}
+
+	newPath := strings.Split(r.New, ".")
+	newParent := lookupMapping(root, newPath[:len(newPath)-1], true)
+	newName := newPath[len(newPath)-1]
+	if _, existing := findKey(newParent, newName); existing != nil {
+		return configNotice(r, true), true
+	}
+	key.Value = newName
+	newParent.Content = append(newParent.Content, key, value)
+	return configNotice(r, false), true
+}
+
+// lookupMapping walks path from root through mapping nodes. With create set,
+// missing mappings are added; otherwise nil is returned.
+func lookupMapping(root *yaml.Node, path []string, create bool) *yaml.Node {
+	node := root
+	for _, name := range path {
+		_, next := findKey(node, name)
+		if next == nil {
+			if !create {
+				return nil
+			}
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func findKey(mapping *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func removeKey(mapping *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			key, value := mapping.Content[i], mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return key, value
+		}
+	}
+	return nil, nil
+}
+
+func encode(doc *yaml.Node, isYAML bool) ([]byte, error) {
+	if isYAML {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	raw, err := nodeToJSON(doc.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// nodeToJSON encodes a YAML node as compact JSON, keeping mapping key order.
+func nodeToJSON(n *yaml.Node) ([]byte, error) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return nil, err
+			}
+			v, err := nodeToJSON(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+	case yaml.SequenceNode:
+		parts := make([][]byte, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := nodeToJSON(c)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, v)
+		}
+		return append(append([]byte{'['}, bytes.Join(parts, []byte{','})...), ']'), nil
+	case yaml.AliasNode:
+		return nodeToJSON(n.Alias)
+	default:
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+}
+
+// FlagNormalizer returns a pflag normalization function that maps renamed
+// flags to their current names and calls warn once per deprecated flag used.
+func FlagNormalizer(renames []Rename, warn func(Notice)) func(*pflag.FlagSet, string) pflag.NormalizedName {
+	byOld := make(map[string]Rename, len(renames))
+	for _, r := range renames {
+		byOld[r.Old] = r
+	}
+	warned := make(map[string]bool)
+	return func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		r, ok := byOld[name]
+		if !ok {
+			return pflag.NormalizedName(name)
+		}
+		if !warned[name] {
+			warned[name] = true
+			warn(flagNotice(r))
+		}
+		return pflag.NormalizedName(r.New)
+	}
+}
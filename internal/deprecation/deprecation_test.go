@@ -0,0 +1,133 @@
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRenames = []Rename{
+	{Old: "checks.root-user", New: "checks.user", Since: "1.0.0"},
+	{Old: "old-top", New: "settings.new-top", Since: "2.0.0", Note: "moved under settings"},
+}
+
+func TestMigrateConfig_YAML(t *testing.T) {
+	input := `# Team config
+checks:
+  age:
+    max-age: 30 # days
+  root-user: {}
+old-top: value
+`
+	want := `# Team config
+checks:
+  age:
+    max-age: 30 # days
+  user: {}
+settings:
+  new-top: value
+`
+	out, notices, err := MigrateConfig([]byte(input), true, testRenames)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(out))
+	require.Len(t, notices, 2)
+	assert.Equal(t, "checks.root-user", notices[0].Old)
+	assert.Equal(t, "checks.user", notices[0].New)
+	assert.Equal(t, "1.0.0", notices[0].Since)
+	assert.Contains(t, notices[0].Message, "use checks.user instead")
+	assert.Contains(t, notices[1].Message, "(moved under settings)")
+}
+
+func TestMigrateConfig_JSONKeepsKeyOrder(t *testing.T) {
+	input := `{"checks":{"size":{"max-size":100},"root-user":{},"age":{"max-age":3}},"telemetry":false,"redact":["a","b"]}`
+	want := `{
+  "checks": {
+    "size": {
+      "max-size": 100
+    },
+    "age": {
+      "max-age": 3
+    },
+    "user": {}
+  },
+  "telemetry": false,
+  "redact": [
+    "a",
+    "b"
+  ]
+}
+`
+	out, notices, err := MigrateConfig([]byte(input), false, testRenames)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(out))
+	assert.Len(t, notices, 1)
+}
+
+func TestMigrateConfig_JSONComments(t *testing.T) {
+	input := "{\n  // legacy section\n  \"checks\": {\"root-user\": {}}\n}"
+	out, notices, err := MigrateConfig([]byte(input), false, testRenames)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"checks\": {\n    \"user\": {}\n  }\n}\n", string(out))
+	assert.Len(t, notices, 1)
+}
+
+func TestMigrateConfig_ConflictKeepsNewKey(t *testing.T) {
+	input := "checks:\n  root-user: {}\n  user:\n    min-uid: 1000\n"
+	out, notices, err := MigrateConfig([]byte(input), true, testRenames)
+	require.NoError(t, err)
+	assert.Equal(t, "checks:\n  user:\n    min-uid: 1000\n", string(out))
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0].Message, "ignored because checks.user is also set")
+}
+
+func TestMigrateConfig_NothingDeprecated(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Current schema", input: "checks:\n    age: {}\n"},
+		{name: "Empty document", input: ""},
+		{name: "Not a mapping", input: "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, notices, err := MigrateConfig([]byte(tt.input), true, testRenames)
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, string(out), "data must be returned unchanged")
+			assert.Empty(t, notices)
+		})
+	}
+}
+
+func TestMigrateConfig_Invalid(t *testing.T) {
+	_, _, err := MigrateConfig([]byte("checks: [unclosed"), true, testRenames)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid configuration")
+}
+
+func TestConfigKeys_MigrateRootUser(t *testing.T) {
+	out, notices, err := MigrateConfig([]byte("checks:\n  root-user: {}\n"), true, ConfigKeys)
+	require.NoError(t, err)
+	assert.Equal(t, "checks:\n  user: {}\n", string(out))
+	assert.Len(t, notices, 1)
+}
+
+func TestFlagNormalizer(t *testing.T) {
+	var notices []Notice
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.SetNormalizeFunc(FlagNormalizer(
+		[]Rename{{Old: "max-days", New: "max-age", Since: "2.0.0"}},
+		func(n Notice) { notices = append(notices, n) },
+	))
+	maxAge := fs.Uint("max-age", 90, "")
+
+	require.NoError(t, fs.Parse([]string{"--max-days", "30", "--max-days=20"}))
+	assert.Equal(t, uint(20), *maxAge)
+	require.Len(t, notices, 1, "each deprecated flag warns once")
+	assert.Equal(t, "--max-days", notices[0].Old)
+	assert.Equal(t, "--max-age", notices[0].New)
+	assert.Equal(t, "Flag --max-days is deprecated since 2.0.0, use --max-age instead", notices[0].Message)
+}
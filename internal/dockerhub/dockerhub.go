@@ -0,0 +1,159 @@
+// Package dockerhub looks up the status of Docker Hub official images
+// (docker.io/library/...) in the Docker Hub API, to detect official images
+// that were deprecated in favor of maintained replacements.
+package dockerhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Docker Hub API.
+const DefaultAPIURL = "https://hub.docker.com"
+
+// officialRepositoryPrefix is the repository prefix of official images on
+// Docker Hub, as normalized by go-containerregistry.
+const officialRepositoryPrefix = "index.docker.io/library/"
+
+// lookupTimeout bounds a Docker Hub API request.
+const lookupTimeout = 10 * time.Second
+
+// maxResponseSize bounds the repository document read from the API; full
+// descriptions are a few tens of kilobytes.
+const maxResponseSize = 1 << 20
+
+// deprecationHeading is the heading Docker Hub official image descriptions
+// open with once the image is deprecated.
+const deprecationHeading = "DEPRECATION NOTICE"
+
+// Replacements maps deprecated official images to their maintained
+// replacement. Deprecation notices list alternatives in free text, so the
+// replacement suggested for the best-known images is kept here.
+var Replacements = map[string]string{
+	"adoptopenjdk":       "eclipse-temurin",
+	"centos":             "almalinux or rockylinux",
+	"django":             "python",
+	"iojs":               "node",
+	"java":               "eclipse-temurin",
+	"jenkins":            "jenkins/jenkins",
+	"openjdk":            "eclipse-temurin",
+	"owncloud":           "owncloud/server",
+	"piwik":              "matomo",
+	"rails":              "ruby",
+	"rocket.chat":        "rocketchat/rocket.chat",
+	"sentry":             "getsentry/self-hosted",
+	"ubuntu-debootstrap": "ubuntu",
+}
+
+// Status is the deprecation status of an official image.
+type Status struct {
+	// Found is false when Docker Hub has no official image of that name.
+	Found      bool
+	Deprecated bool
+	// Notice is the first paragraph of the deprecation notice.
+	Notice string
+	// Replacement is the maintained image to use instead, when known.
+	Replacement string
+}
+
+// repository is the part of a Docker Hub API repository document that
+// describes it.
+type repository struct {
+	Description     string `json:"description"`
+	FullDescription string `json:"full_description"`
+}
+
+// OfficialImage returns the name of the official image of repository, a
+// repository with its registry as returned by imageutil.GetImageRepository
+// (e.g. index.docker.io/library/openjdk), or false when it is not a Docker Hub
+// official image.
+func OfficialImage(repository string) (string, bool) {
+	name, ok := strings.CutPrefix(repository, officialRepositoryPrefix)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// Lookup queries the Docker Hub API at baseURL for the status of the official
+// image name.
+func Lookup(ctx context.Context, baseURL, name string) (Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimRight(baseURL, "/") + "/v2/repositories/library/" + url.PathEscape(name) + "/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Status{}, fmt.Errorf("error creating Docker Hub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Status{}, fmt.Errorf("error querying Docker Hub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Status{}, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return Status{}, fmt.Errorf("unexpected status %d from Docker Hub for library/%s", resp.StatusCode, name)
+	}
+
+	var repo repository
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&repo); err != nil {
+		return Status{}, fmt.Errorf("error parsing Docker Hub response: %w", err)
+	}
+	return status(name, repo), nil
+}
+
+// status derives the deprecation status of the official image name from its
+// repository document.
+func status(name string, repo repository) Status {
+	s := Status{Found: true}
+	notice, ok := deprecationNotice(repo.FullDescription)
+	if !ok {
+		if desc := strings.TrimSpace(repo.Description); strings.HasPrefix(strings.ToUpper(desc), "DEPRECATED") {
+			notice, ok = desc, true
+		}
+	}
+	if !ok {
+		return s
+	}
+	s.Deprecated = true
+	s.Notice = notice
+	s.Replacement = Replacements[name]
+	return s
+}
+
+// deprecationNotice returns the first paragraph after the deprecation notice
+// heading of a full description, with Markdown emphasis removed.
+func deprecationNotice(description string) (string, bool) {
+	_, after, ok := strings.Cut(strings.ReplaceAll(description, "\r\n", "\n"), deprecationHeading)
+	if !ok {
+		return "", false
+	}
+	// Skip the rest of the heading line, then blank lines.
+	if _, rest, found := strings.Cut(after, "\n"); found {
+		after = rest
+	} else {
+		after = ""
+	}
+	var lines []string
+	for line := range strings.SplitSeq(strings.TrimLeft(after, "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			break
+		}
+		lines = append(lines, line)
+	}
+	notice := strings.NewReplacer("**", "", "__", "", "`", "").Replace(strings.Join(lines, " "))
+	return notice, true
+}
@@ -0,0 +1,94 @@
+package dockerhub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const openjdkDescription = "# **DEPRECATION NOTICE**\n\n" +
+	"This image is officially deprecated and all users are recommended to find and use suitable replacements ASAP.\n" +
+	"Some examples of other Official Image alternatives are `eclipse-temurin` and `amazoncorretto`.\n\n" +
+	"# Quick reference\n\n- Maintained by: ...\n"
+
+func TestOfficialImage(t *testing.T) {
+	tests := []struct {
+		repository string
+		want       string
+		wantOK     bool
+	}{
+		{repository: "index.docker.io/library/openjdk", want: "openjdk", wantOK: true},
+		{repository: "index.docker.io/jenkins/jenkins"},
+		{repository: "ghcr.io/library/openjdk"},
+		{repository: "index.docker.io/library/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			got, ok := OfficialImage(tt.repository)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	repos := map[string]repository{
+		"openjdk": {Description: "Pre-release builds of OpenJDK", FullDescription: openjdkDescription},
+		"nginx":   {Description: "Official build of Nginx.", FullDescription: "# Quick reference\n\n- Maintained by: ...\n"},
+		"clefos":  {Description: "DEPRECATED; The official build of ClefOS."},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/repositories/library/broken/":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for name, repo := range repos {
+			if r.URL.Path == "/v2/repositories/library/"+name+"/" {
+				_ = json.NewEncoder(w).Encode(repo)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name      string
+		want      Status
+		wantError string
+	}{
+		{
+			name: "openjdk",
+			want: Status{
+				Found:       true,
+				Deprecated:  true,
+				Notice:      "This image is officially deprecated and all users are recommended to find and use suitable replacements ASAP. Some examples of other Official Image alternatives are eclipse-temurin and amazoncorretto.",
+				Replacement: "eclipse-temurin",
+			},
+		},
+		{name: "nginx", want: Status{Found: true}},
+		{name: "clefos", want: Status{Found: true, Deprecated: true, Notice: "DEPRECATED; The official build of ClefOS."}},
+		{name: "removed", want: Status{}},
+		{name: "broken", wantError: "unexpected status 500 from Docker Hub for library/broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lookup(context.Background(), server.URL+"/", tt.name)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
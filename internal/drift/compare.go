@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Kinds of difference.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Difference is a single way in which an image differs from its spec. Key
+// names the env var, port, or label for list and map fields.
+type Difference struct {
+	Field    string
+	Kind     string
+	Key      string
+	Expected string
+	Actual   string
+}
+
+// Compare returns the differences between the image configuration and the
+// spec, ordered by field and key.
+func Compare(spec *Spec, config *cr.ConfigFile) []Difference {
+	actual := Record(config)
+
+	var diffs []Difference
+	if actual.User != spec.User {
+		diffs = append(diffs, Difference{Field: "user", Kind: Changed, Expected: spec.User, Actual: actual.User})
+	}
+	diffs = append(diffs, compareSets("env-keys", spec.EnvKeys, actual.EnvKeys)...)
+	if !slices.Equal(actual.Entrypoint, spec.Entrypoint) {
+		diffs = append(diffs, Difference{Field: "entrypoint", Kind: Changed, Expected: formatList(spec.Entrypoint), Actual: formatList(actual.Entrypoint)})
+	}
+	if !slices.Equal(actual.Cmd, spec.Cmd) {
+		diffs = append(diffs, Difference{Field: "cmd", Kind: Changed, Expected: formatList(spec.Cmd), Actual: formatList(actual.Cmd)})
+	}
+	diffs = append(diffs, compareSets("exposed-ports", spec.ExposedPorts, actual.ExposedPorts)...)
+	diffs = append(diffs, compareLabels(spec, config.Config.Labels)...)
+	return diffs
+}
+
+// compareSets reports entries of actual missing from expected as added, and
+// the reverse as removed.
+func compareSets(field string, expected, actual []string) []Difference {
+	expected = slices.Sorted(slices.Values(expected))
+	actual = slices.Sorted(slices.Values(actual))
+
+	var diffs []Difference
+	for _, k := range expected {
+		if _, found := slices.BinarySearch(actual, k); !found {
+			diffs = append(diffs, Difference{Field: field, Kind: Removed, Key: k})
+		}
+	}
+	for _, k := range actual {
+		if _, found := slices.BinarySearch(expected, k); !found {
+			diffs = append(diffs, Difference{Field: field, Kind: Added, Key: k})
+		}
+	}
+	slices.SortStableFunc(diffs, func(a, b Difference) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return diffs
+}
+
+func compareLabels(spec *Spec, labels map[string]string) []Difference {
+	keys := slices.Collect(maps.Keys(spec.Labels))
+	for k := range labels {
+		if _, ok := spec.Labels[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []Difference
+	for _, k := range keys {
+		if slices.Contains(spec.IgnoredLabels, k) {
+			continue
+		}
+		want, expected := spec.Labels[k]
+		got, present := labels[k]
+		switch {
+		case expected && !present:
+			diffs = append(diffs, Difference{Field: "labels", Kind: Removed, Key: k, Expected: want})
+		case !expected && present:
+			diffs = append(diffs, Difference{Field: "labels", Kind: Added, Key: k, Actual: got})
+		case want != got:
+			diffs = append(diffs, Difference{Field: "labels", Kind: Changed, Key: k, Expected: want, Actual: got})
+		}
+	}
+	return diffs
+}
+
+// formatList renders a command as a JSON array, so that argument boundaries
+// stay visible.
+func formatList(list []string) string {
+	if list == nil {
+		list = []string{}
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
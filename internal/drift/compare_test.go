@@ -0,0 +1,49 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare_NoDrift(t *testing.T) {
+	cfg := testConfig()
+	spec := Record(cfg)
+
+	cfg.Config.Env = append(cfg.Config.Env, "APP_MODE=staging")
+	cfg.Config.Labels["org.opencontainers.image.created"] = "2026-02-01T00:00:00Z"
+
+	assert.Empty(t, Compare(spec, cfg), "env values and ignored labels are not compared")
+}
+
+func TestCompare_Drift(t *testing.T) {
+	spec := Record(testConfig())
+
+	cfg := testConfig()
+	cfg.Config.User = "root"
+	cfg.Config.Env = []string{"PATH=/usr/bin", "DEBUG=1"}
+	cfg.Config.Entrypoint = []string{"/bin/sh", "-c", "/app/server"}
+	cfg.Config.ExposedPorts = map[string]struct{}{"8080/tcp": {}, "22/tcp": {}}
+	cfg.Config.Labels["org.opencontainers.image.title"] = "other"
+	cfg.Config.Labels["maintainer"] = "someone"
+
+	assert.Equal(t, []Difference{
+		{Field: "user", Kind: Changed, Expected: "1000", Actual: "root"},
+		{Field: "env-keys", Kind: Removed, Key: "APP_MODE"},
+		{Field: "env-keys", Kind: Added, Key: "DEBUG"},
+		{Field: "entrypoint", Kind: Changed, Expected: `["/app/server"]`, Actual: `["/bin/sh","-c","/app/server"]`},
+		{Field: "exposed-ports", Kind: Added, Key: "22/tcp"},
+		{Field: "exposed-ports", Kind: Removed, Key: "9090/udp"},
+		{Field: "labels", Kind: Added, Key: "maintainer", Actual: "someone"},
+		{Field: "labels", Kind: Changed, Key: "org.opencontainers.image.title", Expected: "app", Actual: "other"},
+	}, Compare(spec, cfg))
+}
+
+func TestCompare_EmptySpec(t *testing.T) {
+	diffs := Compare(&Spec{}, testConfig())
+
+	assert.Contains(t, diffs, Difference{Field: "user", Kind: Changed, Actual: "1000"})
+	assert.Contains(t, diffs, Difference{Field: "cmd", Kind: Changed, Expected: "[]", Actual: `["--port","8080"]`})
+	assert.Contains(t, diffs, Difference{Field: "labels", Kind: Added, Key: "org.opencontainers.image.created", Actual: "2026-01-01T00:00:00Z"},
+		"labels are only ignored when the spec lists them")
+}
@@ -0,0 +1,117 @@
+// Package drift compares the configuration of an image against a golden spec
+// recorded from a previously approved image.
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIgnoredLabels are labels whose values change with every build. They
+// are listed as ignored in recorded specs.
+var DefaultIgnoredLabels = []string{
+	"org.opencontainers.image.created",
+	"org.opencontainers.image.revision",
+	"org.opencontainers.image.version",
+}
+
+// Spec is the expected configuration of an image. Every field is compared:
+// an empty field expects the image not to set it.
+type Spec struct {
+	User string `json:"user" yaml:"user"`
+	// EnvKeys lists the names of the environment variables. Values are not
+	// compared, since they often differ between builds.
+	EnvKeys    []string `json:"env-keys" yaml:"env-keys"`
+	Entrypoint []string `json:"entrypoint" yaml:"entrypoint"`
+	Cmd        []string `json:"cmd" yaml:"cmd"`
+	// ExposedPorts lists ports as port/protocol, e.g. 8080/tcp.
+	ExposedPorts []string          `json:"exposed-ports" yaml:"exposed-ports"`
+	Labels       map[string]string `json:"labels" yaml:"labels"`
+	// IgnoredLabels lists labels that are not compared at all.
+	IgnoredLabels []string `json:"ignored-labels,omitempty" yaml:"ignored-labels,omitempty"`
+}
+
+// LoadSpec loads a golden spec from a file or stdin (if path is "-"), which
+// can be in either YAML or JSON format.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading golden spec: %w", err)
+	}
+
+	var spec Spec
+	if err := fileutil.UnmarshalConfigData(data, &spec, path); err != nil {
+		return nil, err
+	}
+	spec.ExposedPorts = normalizePorts(spec.ExposedPorts)
+	return &spec, nil
+}
+
+// Record returns the spec of an image configuration, with the
+// DefaultIgnoredLabels left out.
+func Record(config *cr.ConfigFile) *Spec {
+	spec := &Spec{
+		User:          config.Config.User,
+		EnvKeys:       envKeys(config.Config.Env),
+		Entrypoint:    slices.Clone(config.Config.Entrypoint),
+		Cmd:           slices.Clone(config.Config.Cmd),
+		ExposedPorts:  normalizePorts(slices.Collect(maps.Keys(config.Config.ExposedPorts))),
+		Labels:        make(map[string]string, len(config.Config.Labels)),
+		IgnoredLabels: slices.Clone(DefaultIgnoredLabels),
+	}
+	for k, v := range config.Config.Labels {
+		if !slices.Contains(spec.IgnoredLabels, k) {
+			spec.Labels[k] = v
+		}
+	}
+	return spec
+}
+
+// Marshal encodes the spec as YAML, or as indented JSON when asJSON is true.
+func (s *Spec) Marshal(asJSON bool) ([]byte, error) {
+	if asJSON {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding golden spec: %w", err)
+		}
+		return append(data, '\n'), nil
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding golden spec: %w", err)
+	}
+	return data, nil
+}
+
+// envKeys returns the sorted, unique names of KEY=VALUE entries.
+func envKeys(env []string) []string {
+	keys := make([]string, 0, len(env))
+	for _, e := range env {
+		k, _, _ := strings.Cut(e, "=")
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// normalizePorts sorts ports and adds the default /tcp protocol to entries
+// without one, as the image config does.
+func normalizePorts(ports []string) []string {
+	out := make([]string, 0, len(ports))
+	for _, p := range ports {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !strings.Contains(p, "/") {
+			p += "/tcp"
+		}
+		out = append(out, p)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
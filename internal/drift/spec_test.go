@@ -0,0 +1,88 @@
+package drift
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testConfig() *cr.ConfigFile {
+	return &cr.ConfigFile{Config: cr.Config{
+		User:         "1000",
+		Env:          []string{"PATH=/usr/bin", "APP_MODE=prod", "PATH=/bin"},
+		Entrypoint:   []string{"/app/server"},
+		Cmd:          []string{"--port", "8080"},
+		ExposedPorts: map[string]struct{}{"8080/tcp": {}, "9090/udp": {}},
+		Labels: map[string]string{
+			"org.opencontainers.image.title":   "app",
+			"org.opencontainers.image.created": "2026-01-01T00:00:00Z",
+		},
+	}}
+}
+
+func TestRecord(t *testing.T) {
+	spec := Record(testConfig())
+
+	assert.Equal(t, "1000", spec.User)
+	assert.Equal(t, []string{"APP_MODE", "PATH"}, spec.EnvKeys, "keys are sorted and unique")
+	assert.Equal(t, []string{"/app/server"}, spec.Entrypoint)
+	assert.Equal(t, []string{"--port", "8080"}, spec.Cmd)
+	assert.Equal(t, []string{"8080/tcp", "9090/udp"}, spec.ExposedPorts)
+	assert.Equal(t, map[string]string{"org.opencontainers.image.title": "app"}, spec.Labels, "ignored labels are not recorded")
+	assert.Equal(t, DefaultIgnoredLabels, spec.IgnoredLabels)
+}
+
+func TestSpec_MarshalRoundTrip(t *testing.T) {
+	spec := Record(testConfig())
+
+	for _, tt := range []struct {
+		name   string
+		asJSON bool
+		file   string
+	}{
+		{name: "YAML", file: "spec.yaml"},
+		{name: "JSON", asJSON: true, file: "spec.json"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := spec.Marshal(tt.asJSON)
+			require.NoError(t, err)
+			if tt.asJSON {
+				assert.True(t, json.Valid(data))
+			} else {
+				var node yaml.Node
+				require.NoError(t, yaml.Unmarshal(data, &node))
+			}
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, data, 0600))
+			loaded, err := LoadSpec(path)
+			require.NoError(t, err)
+			assert.Equal(t, spec, loaded)
+		})
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("user: app\nexposed-ports:\n  - \"8080\"\n  - 53/UDP\n"), 0600))
+
+	spec, err := LoadSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "app", spec.User)
+	assert.Equal(t, []string{"53/udp", "8080/tcp"}, spec.ExposedPorts, "ports are normalized")
+
+	_, err = LoadSpec(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading golden spec")
+
+	require.NoError(t, os.WriteFile(path, []byte("user: [\n"), 0600))
+	_, err = LoadSpec(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid YAML")
+}
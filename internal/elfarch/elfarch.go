@@ -0,0 +1,186 @@
+// Package elfarch samples the ELF binaries of image layers and reads the
+// machine architecture they were built for, to catch multi-arch builds whose
+// images declare one platform but ship binaries of another.
+package elfarch
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// headerLen is the number of leading bytes of an ELF file that hold its
+// identification, type, and machine.
+const headerLen = 20
+
+// Architecture returns the OCI architecture (GOARCH naming, e.g. amd64 or
+// arm64) of the ELF executable or shared object starting with head. It
+// returns false for other files, including relocatable objects, and for
+// machines without an OCI architecture.
+func Architecture(head []byte) (string, bool) {
+	if len(head) < headerLen || !bytes.HasPrefix(head, []byte(elf.ELFMAG)) {
+		return "", false
+	}
+	class := elf.Class(head[elf.EI_CLASS])
+	var order binary.ByteOrder
+	switch elf.Data(head[elf.EI_DATA]) {
+	case elf.ELFDATA2LSB:
+		order = binary.LittleEndian
+	case elf.ELFDATA2MSB:
+		order = binary.BigEndian
+	default:
+		return "", false
+	}
+	if t := elf.Type(order.Uint16(head[16:18])); t != elf.ET_EXEC && t != elf.ET_DYN {
+		return "", false
+	}
+
+	little := order == binary.LittleEndian
+	is64 := class == elf.ELFCLASS64
+	switch elf.Machine(order.Uint16(head[18:20])) {
+	case elf.EM_X86_64:
+		return "amd64", true
+	case elf.EM_386:
+		return "386", true
+	case elf.EM_AARCH64:
+		return "arm64", true
+	case elf.EM_ARM:
+		return "arm", true
+	case elf.EM_PPC64:
+		if little {
+			return "ppc64le", true
+		}
+		return "ppc64", true
+	case elf.EM_S390:
+		return "s390x", is64
+	case elf.EM_RISCV:
+		return "riscv64", is64
+	case elf.EM_LOONGARCH:
+		return "loong64", is64
+	case elf.EM_MIPS:
+		arch := "mips"
+		if is64 {
+			arch += "64"
+		}
+		if little {
+			arch += "le"
+		}
+		return arch, true
+	}
+	return "", false
+}
+
+// Result is the outcome of sampling the ELF binaries of an image.
+type Result struct {
+	// Inspected is the number of ELF binaries whose architecture was read.
+	Inspected int
+	// Architectures counts the inspected binaries by architecture.
+	Architectures map[string]int
+	// Mismatches are the binaries of another architecture than the declared
+	// one.
+	Mismatches []output.ArchitectureMismatch
+}
+
+// ScanLayers reads the architecture of up to maxBinaries ELF binaries of
+// image, 0 for all, and reports those not built for arch. Layers are read from
+// the top down, so the binaries the image adds on top of its base are sampled
+// first. Layers that cannot be read are logged and skipped, as in the secrets
+// check.
+func ScanLayers(ctx context.Context, image cr.Image, arch string, maxBinaries uint) (*Result, error) {
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error getting image layers: %w", err)
+	}
+
+	result := &Result{Architectures: map[string]int{}}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("scanning cancelled: %w", err)
+		}
+		if full(result, maxBinaries) {
+			break
+		}
+
+		log.WithFields(log.Fields{"layer": i + 1, "total": len(layers)}).Debug("Sampling ELF binaries of layer")
+
+		if err := scanLayer(ctx, layers[i], i, arch, maxBinaries, result); err != nil {
+			mediaType, _ := layers[i].MediaType()
+			log.WithFields(log.Fields{"layer": i, "media-type": mediaType, "error": err}).Warn("Error scanning layer, its binaries were not inspected")
+		}
+	}
+
+	return result, nil
+}
+
+func full(result *Result, maxBinaries uint) bool {
+	return maxBinaries > 0 && result.Inspected >= int(maxBinaries)
+}
+
+// scanLayer adds the binaries of a single layer to result.
+func scanLayer(ctx context.Context, layer cr.Layer, layerIndex int, arch string, maxBinaries uint, result *Result) error {
+	rc, err := imageutil.OpenLayer(layer)
+	if err != nil {
+		return fmt.Errorf("error uncompressing layer: %w", err)
+	}
+	defer func() {
+		if closeErr := rc.Close(); closeErr != nil {
+			log.WithField("error", closeErr).Warn("Failed to close layer reader")
+		}
+	}()
+
+	var layerDigest string
+	if digest, err := layer.Digest(); err == nil {
+		layerDigest = digest.String()
+	}
+
+	tarReader := tar.NewReader(rc)
+	head := make([]byte, headerLen)
+	for !full(result, maxBinaries) {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("scanning cancelled: %w", err)
+		}
+
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || header.Size < headerLen {
+			continue
+		}
+
+		if _, err := io.ReadFull(tarReader, head); err != nil {
+			return fmt.Errorf("error reading %s: %w", header.Name, err)
+		}
+		binaryArch, ok := Architecture(head)
+		if !ok {
+			continue
+		}
+		result.Inspected++
+		result.Architectures[binaryArch]++
+		if binaryArch == arch {
+			continue
+		}
+
+		result.Mismatches = append(result.Mismatches, output.ArchitectureMismatch{
+			Path:         header.Name,
+			LayerIndex:   layerIndex,
+			LayerDigest:  layerDigest,
+			Architecture: binaryArch,
+		})
+		log.WithFields(log.Fields{"layer": layerIndex, "path": logutil.SanitizeLogValue(header.Name), "architecture": binaryArch}).Debug("Found binary of another architecture")
+	}
+	return nil
+}
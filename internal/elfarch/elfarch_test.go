@@ -0,0 +1,165 @@
+package elfarch
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elfHeader returns the leading bytes of an ELF file of the given class,
+// byte order, type, and machine, padded as a small binary would be.
+func elfHeader(class elf.Class, data elf.Data, typ elf.Type, machine elf.Machine) []byte {
+	head := make([]byte, 64)
+	copy(head, elf.ELFMAG)
+	head[elf.EI_CLASS] = byte(class)
+	head[elf.EI_DATA] = byte(data)
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+	order.PutUint16(head[16:], uint16(typ))
+	order.PutUint16(head[18:], uint16(machine))
+	return head
+}
+
+func exe(machine elf.Machine) []byte {
+	return elfHeader(elf.ELFCLASS64, elf.ELFDATA2LSB, elf.ET_EXEC, machine)
+}
+
+func TestArchitecture(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+		ok   bool
+	}{
+		{"amd64 executable", exe(elf.EM_X86_64), "amd64", true},
+		{"arm64 shared object", elfHeader(elf.ELFCLASS64, elf.ELFDATA2LSB, elf.ET_DYN, elf.EM_AARCH64), "arm64", true},
+		{"arm", elfHeader(elf.ELFCLASS32, elf.ELFDATA2LSB, elf.ET_EXEC, elf.EM_ARM), "arm", true},
+		{"386", elfHeader(elf.ELFCLASS32, elf.ELFDATA2LSB, elf.ET_EXEC, elf.EM_386), "386", true},
+		{"ppc64le", exe(elf.EM_PPC64), "ppc64le", true},
+		{"ppc64", elfHeader(elf.ELFCLASS64, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_PPC64), "ppc64", true},
+		{"s390x", elfHeader(elf.ELFCLASS64, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_S390), "s390x", true},
+		{"riscv64", exe(elf.EM_RISCV), "riscv64", true},
+		{"mips64le", exe(elf.EM_MIPS), "mips64le", true},
+		{"mips", elfHeader(elf.ELFCLASS32, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_MIPS), "mips", true},
+		{"relocatable object", elfHeader(elf.ELFCLASS64, elf.ELFDATA2LSB, elf.ET_REL, elf.EM_X86_64), "", false},
+		{"unknown machine", exe(elf.EM_SPARCV9), "", false},
+		{"invalid byte order", elfHeader(elf.ELFCLASS64, elf.ELFDATANONE, elf.ET_EXEC, elf.EM_X86_64), "", false},
+		{"not ELF", []byte("#!/bin/sh\necho hello world\n"), "", false},
+		{"truncated", exe(elf.EM_X86_64)[:10], "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Architecture(tt.head)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type testFile struct {
+	name    string
+	content []byte
+}
+
+// createLayer returns a layer holding the given files, in order.
+func createLayer(t *testing.T, files ...testFile) v1.Layer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	data := buf.Bytes()
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	require.NoError(t, err)
+	return layer
+}
+
+func createImage(t *testing.T, layers ...v1.Layer) v1.Image {
+	t.Helper()
+	img, err := mutate.AppendLayers(empty.Image, layers...)
+	require.NoError(t, err)
+	return img
+}
+
+func TestScanLayers(t *testing.T) {
+	base := createLayer(t,
+		testFile{"bin/sh", exe(elf.EM_AARCH64)},
+		testFile{"lib/libc.so.6", elfHeader(elf.ELFCLASS64, elf.ELFDATA2LSB, elf.ET_DYN, elf.EM_AARCH64)},
+		testFile{"etc/os-release", []byte("ID=alpine\nVERSION_ID=3.20\n")},
+	)
+	app := createLayer(t,
+		testFile{"usr/local/bin/app", exe(elf.EM_X86_64)},
+		testFile{"app/start.sh", []byte("#!/bin/sh\nexec /usr/local/bin/app\n")},
+	)
+	img := createImage(t, base, app)
+
+	result, err := ScanLayers(context.Background(), img, "arm64", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Inspected)
+	assert.Equal(t, map[string]int{"arm64": 2, "amd64": 1}, result.Architectures)
+	require.Len(t, result.Mismatches, 1)
+	assert.Equal(t, "usr/local/bin/app", result.Mismatches[0].Path)
+	assert.Equal(t, 1, result.Mismatches[0].LayerIndex)
+	assert.Equal(t, "amd64", result.Mismatches[0].Architecture)
+	assert.NotEmpty(t, result.Mismatches[0].LayerDigest)
+}
+
+func TestScanLayers_MaxBinaries(t *testing.T) {
+	base := createLayer(t, testFile{"bin/sh", exe(elf.EM_X86_64)})
+	app := createLayer(t, testFile{"usr/local/bin/app", exe(elf.EM_AARCH64)})
+	img := createImage(t, base, app)
+
+	result, err := ScanLayers(context.Background(), img, "amd64", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Inspected)
+	assert.Equal(t, []output.ArchitectureMismatch{{
+		Path:         "usr/local/bin/app",
+		LayerIndex:   1,
+		LayerDigest:  result.Mismatches[0].LayerDigest,
+		Architecture: "arm64",
+	}}, result.Mismatches, "the top layer is sampled first")
+}
+
+func TestScanLayers_NoBinaries(t *testing.T) {
+	img := createImage(t, createLayer(t, testFile{"etc/motd", []byte("Welcome to the container\n")}))
+
+	result, err := ScanLayers(context.Background(), img, "amd64", 50)
+	require.NoError(t, err)
+	assert.Zero(t, result.Inspected)
+	assert.Empty(t, result.Mismatches)
+}
+
+func TestScanLayers_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ScanLayers(ctx, createImage(t, createLayer(t)), "amd64", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scanning cancelled")
+}
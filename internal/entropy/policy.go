@@ -0,0 +1,117 @@
+package entropy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+)
+
+// Defaults of the fields of a Policy left unset.
+const (
+	// DefaultMinSize is the size in megabytes from which files are measured.
+	DefaultMinSize = 10
+	// DefaultMaxEntropy is the highest accepted entropy in bits per byte. Text
+	// and executables stay well below it; compressed, encrypted, and packed
+	// data, and most model weights, are above it.
+	DefaultMaxEntropy = 7.5
+)
+
+// maxBitsPerByte is the entropy of uniformly random bytes.
+const maxBitsPerByte = 8
+
+// Policy defines which files of an image are flagged for their entropy.
+type Policy struct {
+	// MinSize is the size in megabytes from which a file is measured; smaller
+	// files are never flagged. Zero means DefaultMinSize.
+	MinSize uint `json:"min-size,omitempty" yaml:"min-size,omitempty"`
+	// MaxEntropy is the highest accepted Shannon entropy of a file, in bits
+	// per byte (0 to 8). Zero means DefaultMaxEntropy.
+	MaxEntropy float64 `json:"max-entropy,omitempty" yaml:"max-entropy,omitempty"`
+	// ExcludedPaths lists paths that are not measured, as globs matched
+	// against the full path or the file name, or directories ending in /**.
+	ExcludedPaths []string `json:"excluded-paths,omitempty" yaml:"excluded-paths,omitempty"`
+	// CheckKnownFormats also flags files that start with the signature of a
+	// compressed format (gzip, zip, PNG, ...). They are high-entropy by design
+	// and are skipped unless this is set.
+	CheckKnownFormats bool `json:"check-known-formats,omitempty" yaml:"check-known-formats,omitempty"`
+}
+
+// DefaultPolicy returns the policy used when none is given.
+func DefaultPolicy() *Policy {
+	return &Policy{MinSize: DefaultMinSize, MaxEntropy: DefaultMaxEntropy}
+}
+
+// LoadPolicy loads an entropy policy from a file or stdin (if path is "-"),
+// which can be in either YAML or JSON format. Unset fields take their
+// defaults. If path is empty, it returns DefaultPolicy.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading entropy policy: %w", err)
+	}
+
+	var policy Policy
+	if err := fileutil.UnmarshalConfigData(data, &policy, path); err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if policy.MinSize == 0 {
+		policy.MinSize = DefaultMinSize
+	}
+	if policy.MaxEntropy == 0 {
+		policy.MaxEntropy = DefaultMaxEntropy
+	}
+
+	return &policy, nil
+}
+
+// Validate checks that max-entropy is within 0 and 8 bits per byte and that
+// every excluded path is a valid pattern.
+func (p *Policy) Validate() error {
+	if p.MaxEntropy < 0 || p.MaxEntropy > maxBitsPerByte {
+		return fmt.Errorf("max-entropy must be between 0 and %d bits per byte, got %g", maxBitsPerByte, p.MaxEntropy)
+	}
+	for _, e := range p.ExcludedPaths {
+		if strings.TrimSpace(e) == "" {
+			return fmt.Errorf("excluded-paths cannot contain empty entries")
+		}
+		if _, err := filepath.Match(strings.TrimSuffix(e, "/**"), ""); err != nil {
+			return fmt.Errorf("excluded-paths entry %q is not a valid pattern: %w", e, err)
+		}
+	}
+	return nil
+}
+
+// minBytes returns MinSize in bytes.
+func (p *Policy) minBytes() int64 {
+	return int64(p.MinSize) * 1024 * 1024
+}
+
+// isExcluded reports whether path matches one of the excluded paths.
+func (p *Policy) isExcluded(path string) bool {
+	path = "/" + strings.TrimLeft(strings.TrimPrefix(path, "./"), "/")
+	for _, pattern := range p.ExcludedPaths {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if path == dir || strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+			continue
+		}
+		if matched, err := filepath.Match(pattern, path); err == nil && matched {
+			return true
+		}
+		if matched, err := filepath.Match(pattern, filepath.Base(path)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package entropy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPolicy_Defaults(t *testing.T) {
+	policy, err := LoadPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPolicy(), policy)
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("excluded-paths:\n  - /models/**\n"), 0600))
+	policy, err = LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint(DefaultMinSize), policy.MinSize)
+	assert.InDelta(t, DefaultMaxEntropy, policy.MaxEntropy, 0)
+	assert.Equal(t, []string{"/models/**"}, policy.ExcludedPaths)
+}
+
+func TestLoadPolicy_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	content := `{"min-size": 50, "max-entropy": 7.9, "check-known-formats": true}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint(50), policy.MinSize)
+	assert.InDelta(t, 7.9, policy.MaxEntropy, 0)
+	assert.True(t, policy.CheckKnownFormats)
+}
+
+func TestLoadPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"max-entropy above 8", `{"max-entropy": 9}`, "max-entropy must be between 0 and 8 bits per byte, got 9"},
+		{"negative max-entropy", `{"max-entropy": -1}`, "max-entropy must be between 0 and 8"},
+		{"empty excluded path", `{"excluded-paths": [" "]}`, "excluded-paths cannot contain empty entries"},
+		{"bad pattern", `{"excluded-paths": ["/data/[a"]}`, `excluded-paths entry "/data/[a" is not a valid pattern`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+			_, err := LoadPolicy(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadPolicy_MissingFile(t *testing.T) {
+	_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading entropy policy")
+}
+
+func TestPolicy_IsExcluded(t *testing.T) {
+	policy := &Policy{ExcludedPaths: []string{"/opt/models/**", "*.onnx", "/srv/data.bin"}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"opt/models/llama/weights.bin", true},
+		{"/opt/models", true},
+		{"opt/modelsx/weights.bin", false},
+		{"usr/share/model.onnx", true},
+		{"srv/data.bin", true},
+		{"./srv/data.bin", true},
+		{"srv/other.bin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.isExcluded(tt.path))
+		})
+	}
+}
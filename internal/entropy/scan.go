@@ -0,0 +1,190 @@
+package entropy
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/jarfernandez/check-image/internal/logutil"
+	"github.com/jarfernandez/check-image/internal/output"
+	log "github.com/sirupsen/logrus"
+)
+
+// knownFormats maps the leading bytes of compressed formats to their names.
+// Files of these formats are expected to be high-entropy.
+var knownFormats = []struct {
+	name  string
+	magic []byte
+}{
+	{"gzip", []byte{0x1f, 0x8b}},
+	{"bzip2", []byte("BZh")},
+	{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{"zip", []byte("PK\x03\x04")},
+	{"7z", []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}},
+	{"png", []byte("\x89PNG\r\n\x1a\n")},
+	{"jpeg", []byte{0xff, 0xd8, 0xff}},
+	{"gif", []byte("GIF8")},
+	{"webp", []byte("RIFF")},
+	{"woff", []byte("wOFF")},
+	{"woff2", []byte("wOF2")},
+}
+
+// signatureLen is the number of leading bytes read to detect a format.
+const signatureLen = 8
+
+// detectFormat returns the name of the known format of a file starting with
+// head, or an empty string.
+func detectFormat(head []byte) string {
+	for _, f := range knownFormats {
+		if bytes.HasPrefix(head, f.magic) {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// Result is the outcome of scanning the layers of an image.
+type Result struct {
+	// Findings are the files above the policy's max-entropy.
+	Findings []output.EntropyFinding
+	// Measured is the number of files that were large enough to be measured.
+	Measured int
+}
+
+// ScanLayers measures the entropy of the regular files of at least the
+// policy's min-size in every layer of image, and returns those above its
+// max-entropy. Layers that cannot be read are logged and skipped, as in the
+// secrets check.
+func ScanLayers(ctx context.Context, image cr.Image, policy *Policy) (*Result, error) {
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error getting image layers: %w", err)
+	}
+
+	result := &Result{}
+	for i, layer := range layers {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("scanning cancelled: %w", err)
+		}
+
+		log.WithFields(log.Fields{"layer": i + 1, "total": len(layers)}).Debug("Measuring entropy of layer")
+
+		if err := scanLayer(ctx, layer, i, policy, result); err != nil {
+			mediaType, _ := layer.MediaType()
+			log.WithFields(log.Fields{"layer": i, "media-type": mediaType, "error": err}).Warn("Error scanning layer, its files were not measured")
+		}
+	}
+
+	return result, nil
+}
+
+// scanLayer adds the findings of a single layer to result.
+func scanLayer(ctx context.Context, layer cr.Layer, layerIndex int, policy *Policy, result *Result) error {
+	rc, err := imageutil.OpenLayer(layer)
+	if err != nil {
+		return fmt.Errorf("error uncompressing layer: %w", err)
+	}
+	defer func() {
+		if closeErr := rc.Close(); closeErr != nil {
+			log.WithField("error", closeErr).Warn("Failed to close layer reader")
+		}
+	}()
+
+	var layerDigest string
+	if digest, err := layer.Digest(); err == nil {
+		layerDigest = digest.String()
+	}
+
+	tarReader := tar.NewReader(rc)
+	minBytes := policy.minBytes()
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("scanning cancelled: %w", err)
+		}
+
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || header.Size < minBytes {
+			continue
+		}
+		if policy.isExcluded(header.Name) {
+			log.WithField("path", logutil.SanitizeLogValue(header.Name)).Debug("Skipping excluded path")
+			continue
+		}
+
+		head := make([]byte, signatureLen)
+		n, err := io.ReadFull(tarReader, head)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("error reading %s: %w", header.Name, err)
+		}
+		format := detectFormat(head[:n])
+		if format != "" && !policy.CheckKnownFormats {
+			log.WithFields(log.Fields{"path": logutil.SanitizeLogValue(header.Name), "format": format}).Debug("Skipping file of a compressed format")
+			continue
+		}
+
+		bits, err := measure(io.MultiReader(bytes.NewReader(head[:n]), tarReader))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", header.Name, err)
+		}
+		result.Measured++
+		if bits <= policy.MaxEntropy {
+			continue
+		}
+
+		result.Findings = append(result.Findings, output.EntropyFinding{
+			Path:        header.Name,
+			LayerIndex:  layerIndex,
+			LayerDigest: layerDigest,
+			Size:        header.Size,
+			Entropy:     math.Round(bits*1000) / 1000,
+			Format:      format,
+		})
+		log.WithFields(log.Fields{"layer": layerIndex, "path": logutil.SanitizeLogValue(header.Name), "entropy": bits}).Debug("Found high-entropy file")
+	}
+}
+
+// measure returns the Shannon entropy of the bytes of r, in bits per byte.
+func measure(r io.Reader) (float64, error) {
+	var counts [256]int64
+	var total int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			counts[b]++
+		}
+		total += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	var bits float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(total)
+		bits -= p * math.Log2(p)
+	}
+	return bits, nil
+}
@@ -0,0 +1,128 @@
+package entropy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mb = 1024 * 1024
+
+// randomBytes returns n bytes of deterministic pseudo-random data.
+func randomBytes(n int) []byte {
+	r := rand.New(rand.NewPCG(1, 2))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(r.Uint32())
+	}
+	return data
+}
+
+type testFile struct {
+	name    string
+	content []byte
+}
+
+// createLayer returns a layer holding the given files, in order.
+func createLayer(t *testing.T, files ...testFile) v1.Layer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	data := buf.Bytes()
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	require.NoError(t, err)
+	return layer
+}
+
+func TestMeasure(t *testing.T) {
+	bits, err := measure(bytes.NewReader(nil))
+	require.NoError(t, err)
+	assert.Zero(t, bits)
+
+	bits, err = measure(strings.NewReader(strings.Repeat("a", 1000)))
+	require.NoError(t, err)
+	assert.Zero(t, bits)
+
+	bits, err = measure(strings.NewReader(strings.Repeat("ab", 1000)))
+	require.NoError(t, err)
+	assert.InDelta(t, 1, bits, 1e-9)
+
+	bits, err = measure(bytes.NewReader(randomBytes(mb)))
+	require.NoError(t, err)
+	assert.Greater(t, bits, 7.99)
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, "gzip", detectFormat([]byte{0x1f, 0x8b, 0x08, 0x00}))
+	assert.Equal(t, "zip", detectFormat([]byte("PK\x03\x04rest")))
+	assert.Equal(t, "png", detectFormat([]byte("\x89PNG\r\n\x1a\n")))
+	assert.Empty(t, detectFormat([]byte("\x7fELF")))
+	assert.Empty(t, detectFormat(nil))
+}
+
+func TestScanLayers(t *testing.T) {
+	random := randomBytes(2 * mb)
+	gzipped := append([]byte{0x1f, 0x8b}, random[2:]...)
+	text := bytes.Repeat([]byte("log line\n"), 2*mb/9)
+
+	img, err := mutate.AppendLayers(empty.Image,
+		createLayer(t, testFile{"etc/small.bin", random[:mb/2]}, testFile{"var/log/app.log", text}),
+		createLayer(t, testFile{"opt/payload.bin", random}, testFile{"opt/archive.gz", gzipped}, testFile{"opt/models/w.bin", random}),
+	)
+	require.NoError(t, err)
+
+	policy := &Policy{MinSize: 1, MaxEntropy: DefaultMaxEntropy, ExcludedPaths: []string{"/opt/models/**"}}
+	result, err := ScanLayers(context.Background(), img, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Measured, "the log and the payload are measured")
+	require.Len(t, result.Findings, 1)
+	f := result.Findings[0]
+	assert.Equal(t, "opt/payload.bin", f.Path)
+	assert.Equal(t, 1, f.LayerIndex)
+	assert.Equal(t, int64(2*mb), f.Size)
+	assert.Greater(t, f.Entropy, 7.99)
+	assert.True(t, strings.HasPrefix(f.LayerDigest, "sha256:"))
+	assert.Empty(t, f.Format)
+
+	policy.CheckKnownFormats = true
+	result, err = ScanLayers(context.Background(), img, policy)
+	require.NoError(t, err)
+	require.Len(t, result.Findings, 2)
+	assert.Equal(t, "opt/archive.gz", result.Findings[1].Path)
+	assert.Equal(t, "gzip", result.Findings[1].Format)
+}
+
+func TestScanLayers_CancelledContext(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image, createLayer(t, testFile{"a.bin", randomBytes(mb)}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ScanLayers(ctx, img, DefaultPolicy())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scanning cancelled")
+}
@@ -0,0 +1,134 @@
+package entrypoint
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+)
+
+// Policy defines regex rules for the arguments of the startup command of an
+// image, which is ENTRYPOINT followed by CMD.
+//   - ForbiddenArgs fail when any argument matches the rule pattern
+//   - InlineScripts fail when the executable matches the interpreter pattern
+//     and a later argument matches the flag pattern, such as sh -c or python -c
+//   - RequiredExecutable fails when the executable does not match the pattern,
+//     such as ^/ to require an absolute path
+type Policy struct {
+	ForbiddenArgs      []ArgRule          `yaml:"forbidden-args,omitempty"      json:"forbidden-args,omitempty"`
+	InlineScripts      []InlineScriptRule `yaml:"inline-scripts,omitempty"      json:"inline-scripts,omitempty"`
+	RequiredExecutable *ArgRule           `yaml:"required-executable,omitempty" json:"required-executable,omitempty"`
+}
+
+// ArgRule matches single arguments against a regex pattern.
+type ArgRule struct {
+	Name    string `yaml:"name"              json:"name"`
+	Pattern string `yaml:"pattern"           json:"pattern"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// InlineScriptRule matches an interpreter run with an inline script flag.
+type InlineScriptRule struct {
+	Name        string `yaml:"name"              json:"name"`
+	Interpreter string `yaml:"interpreter"       json:"interpreter"`
+	Flag        string `yaml:"flag"              json:"flag"`
+	Message     string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	interpreterRe *regexp.Regexp
+	flagRe        *regexp.Regexp
+}
+
+// LoadPolicy loads an entrypoint policy from a file or stdin (if path is "-"),
+// which can be in either YAML or JSON format, and returns the parsed Policy
+// object with its patterns compiled.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading entrypoint policy: %w", err)
+	}
+
+	var policy Policy
+	if err := fileutil.UnmarshalConfigData(data, &policy, path); err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Validate checks that the policy has at least one rule, that rule names are
+// present and unique, and compiles every pattern.
+func (p *Policy) Validate() error {
+	if len(p.ForbiddenArgs) == 0 && len(p.InlineScripts) == 0 && p.RequiredExecutable == nil {
+		return fmt.Errorf("policy must specify at least one rule")
+	}
+
+	seen := make(map[string]bool)
+	checkName := func(name, kind string, i int) error {
+		if name == "" {
+			return fmt.Errorf("%s rule at index %d is missing a name", kind, i)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate rule name %q in policy", name)
+		}
+		seen[name] = true
+		return nil
+	}
+
+	for i := range p.ForbiddenArgs {
+		r := &p.ForbiddenArgs[i]
+		if err := checkName(r.Name, "forbidden-args", i); err != nil {
+			return err
+		}
+		re, err := compilePattern(r.Name, "pattern", r.Pattern)
+		if err != nil {
+			return err
+		}
+		r.re = re
+	}
+
+	for i := range p.InlineScripts {
+		r := &p.InlineScripts[i]
+		if err := checkName(r.Name, "inline-scripts", i); err != nil {
+			return err
+		}
+		interpreterRe, err := compilePattern(r.Name, "interpreter", r.Interpreter)
+		if err != nil {
+			return err
+		}
+		flagRe, err := compilePattern(r.Name, "flag", r.Flag)
+		if err != nil {
+			return err
+		}
+		r.interpreterRe, r.flagRe = interpreterRe, flagRe
+	}
+
+	if r := p.RequiredExecutable; r != nil {
+		if err := checkName(r.Name, "required-executable", 0); err != nil {
+			return err
+		}
+		re, err := compilePattern(r.Name, "pattern", r.Pattern)
+		if err != nil {
+			return err
+		}
+		r.re = re
+	}
+
+	return nil
+}
+
+func compilePattern(rule, field, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("rule %q is missing a %s", rule, field)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s for rule %q: %w", field, rule, err)
+	}
+	return re, nil
+}
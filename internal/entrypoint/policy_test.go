@@ -0,0 +1,92 @@
+package entrypoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		errContains string
+	}{
+		{
+			name: "Valid YAML policy",
+			file: "policy.yaml",
+			content: `forbidden-args:
+  - name: insecure-flags
+    pattern: '^--insecure'
+inline-scripts:
+  - name: shell-one-liner
+    interpreter: '(^|/)sh$'
+    flag: '^-c$'
+required-executable:
+  name: absolute-path
+  pattern: '^/'
+`,
+		},
+		{
+			name:    "Valid JSON policy",
+			file:    "policy.json",
+			content: `{"required-executable": {"name": "absolute-path", "pattern": "^/"}}`,
+		},
+		{
+			name:        "No rules",
+			file:        "policy.yaml",
+			content:     "forbidden-args: []\n",
+			errContains: "policy must specify at least one rule",
+		},
+		{
+			name:        "Missing name",
+			file:        "policy.yaml",
+			content:     "forbidden-args:\n  - pattern: '^--insecure'\n",
+			errContains: "forbidden-args rule at index 0 is missing a name",
+		},
+		{
+			name:        "Duplicate name",
+			file:        "policy.yaml",
+			content:     "forbidden-args:\n  - name: a\n    pattern: x\n  - name: a\n    pattern: y\n",
+			errContains: `duplicate rule name "a"`,
+		},
+		{
+			name:        "Missing flag",
+			file:        "policy.yaml",
+			content:     "inline-scripts:\n  - name: py\n    interpreter: python\n",
+			errContains: `rule "py" is missing a flag`,
+		},
+		{
+			name:        "Invalid pattern",
+			file:        "policy.yaml",
+			content:     "required-executable:\n  name: abs\n  pattern: '('\n",
+			errContains: `invalid pattern for rule "abs"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			policy, err := LoadPolicy(path)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, policy)
+		})
+	}
+}
+
+func TestLoadPolicy_FileNotFound(t *testing.T) {
+	_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading entrypoint policy")
+}
@@ -0,0 +1,74 @@
+package entrypoint
+
+import "fmt"
+
+// Violation represents a single rule that the startup command breaks.
+type Violation struct {
+	Rule     string
+	Argument string
+	Message  string
+}
+
+// Command returns the command a container of the image starts with: the
+// entrypoint followed by cmd, as the container runtime runs it.
+func Command(entrypoint, cmd []string) []string {
+	command := make([]string, 0, len(entrypoint)+len(cmd))
+	command = append(command, entrypoint...)
+	return append(command, cmd...)
+}
+
+// ValidateCommand checks the startup command against every rule of the
+// policy and returns all violations, in rule order. The policy must have been
+// validated, which compiles its patterns.
+func ValidateCommand(command []string, policy *Policy) []Violation {
+	if len(command) == 0 {
+		return nil
+	}
+	executable := command[0]
+
+	var violations []Violation
+	for _, r := range policy.ForbiddenArgs {
+		for _, arg := range command {
+			if r.re.MatchString(arg) {
+				violations = append(violations, Violation{
+					Rule:     r.Name,
+					Argument: arg,
+					Message:  ruleMessage(r.Message, fmt.Sprintf("argument %q is forbidden", arg)),
+				})
+			}
+		}
+	}
+
+	for _, r := range policy.InlineScripts {
+		if !r.interpreterRe.MatchString(executable) {
+			continue
+		}
+		for _, arg := range command[1:] {
+			if r.flagRe.MatchString(arg) {
+				violations = append(violations, Violation{
+					Rule:     r.Name,
+					Argument: arg,
+					Message:  ruleMessage(r.Message, fmt.Sprintf("%s runs an inline script with %s", executable, arg)),
+				})
+				break
+			}
+		}
+	}
+
+	if r := policy.RequiredExecutable; r != nil && !r.re.MatchString(executable) {
+		violations = append(violations, Violation{
+			Rule:     r.Name,
+			Argument: executable,
+			Message:  ruleMessage(r.Message, fmt.Sprintf("executable %q does not match pattern %q", executable, r.Pattern)),
+		})
+	}
+
+	return violations
+}
+
+func ruleMessage(custom, fallback string) string {
+	if custom != "" {
+		return custom
+	}
+	return fallback
+}
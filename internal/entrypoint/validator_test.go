@@ -0,0 +1,76 @@
+package entrypoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	assert.Equal(t, []string{"/entrypoint.sh", "serve", "--port", "80"}, Command([]string{"/entrypoint.sh"}, []string{"serve", "--port", "80"}))
+	assert.Equal(t, []string{"nginx"}, Command(nil, []string{"nginx"}))
+	assert.Empty(t, Command(nil, nil))
+}
+
+func TestValidateCommand(t *testing.T) {
+	policy := &Policy{
+		ForbiddenArgs: []ArgRule{
+			{Name: "insecure-flags", Pattern: `^--(insecure|disable-auth)(=.*)?$`},
+		},
+		InlineScripts: []InlineScriptRule{
+			{Name: "shell-one-liner", Interpreter: `(^|/)(sh|bash)$`, Flag: `^-c$`},
+			{Name: "python-one-liner", Interpreter: `(^|/)python[0-9.]*$`, Flag: `^-c$`, Message: "python must run a script file"},
+		},
+		RequiredExecutable: &ArgRule{Name: "absolute-path", Pattern: `^/`},
+	}
+	require.NoError(t, policy.Validate())
+
+	tests := []struct {
+		name     string
+		command  []string
+		expected []Violation
+	}{
+		{
+			name:    "Compliant command",
+			command: []string{"/app/server", "--port", "8080"},
+		},
+		{
+			name:    "Empty command",
+			command: nil,
+		},
+		{
+			name:    "Forbidden flags",
+			command: []string{"/app/server", "--insecure", "--disable-auth=true"},
+			expected: []Violation{
+				{Rule: "insecure-flags", Argument: "--insecure", Message: `argument "--insecure" is forbidden`},
+				{Rule: "insecure-flags", Argument: "--disable-auth=true", Message: `argument "--disable-auth=true" is forbidden`},
+			},
+		},
+		{
+			name:    "Shell one-liner",
+			command: []string{"/bin/sh", "-c", "exec server"},
+			expected: []Violation{
+				{Rule: "shell-one-liner", Argument: "-c", Message: "/bin/sh runs an inline script with -c"},
+			},
+		},
+		{
+			name:    "Python one-liner with custom message and relative path",
+			command: []string{"python3", "-u", "-c", "import app"},
+			expected: []Violation{
+				{Rule: "python-one-liner", Argument: "-c", Message: "python must run a script file"},
+				{Rule: "absolute-path", Argument: "python3", Message: `executable "python3" does not match pattern "^/"`},
+			},
+		},
+		{
+			name:    "Flag without interpreter is allowed",
+			command: []string{"/app/server", "-c", "config.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidateCommand(tt.command, policy))
+		})
+	}
+}
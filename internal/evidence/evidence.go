@@ -0,0 +1,336 @@
+// Package evidence stores the supporting evidence of the file findings of a
+// validation in a directory: the tar header of each matched file, the
+// history entry of its layer, the sha256 digest of its content, and
+// optionally its first bytes. Auditors can verify the findings from these
+// artifacts without rerunning the scan.
+package evidence
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	log "github.com/sirupsen/logrus"
+)
+
+// File names of the evidence directory.
+const (
+	IndexFile    = "index.json"
+	EvidenceFile = "evidence.json"
+	ContentFile  = "content"
+)
+
+// Finding is a file finding of a check, located by its path in the tar
+// stream of the layer at LayerIndex.
+type Finding struct {
+	Check      string
+	Path       string
+	LayerIndex int
+}
+
+// Header is the tar header metadata of a matched file.
+type Header struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Linkname string `json:"linkname,omitempty"`
+	// Mode holds the permission bits, in octal.
+	Mode    string `json:"mode"`
+	UID     int    `json:"uid"`
+	GID     int    `json:"gid"`
+	Uname   string `json:"uname,omitempty"`
+	Gname   string `json:"gname,omitempty"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod-time"`
+}
+
+// History is the history entry of the layer holding a matched file.
+type History struct {
+	Created   string `json:"created,omitempty"`
+	CreatedBy string `json:"created-by,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Record is the evidence of one finding.
+type Record struct {
+	Check       string `json:"check"`
+	Path        string `json:"path"`
+	LayerIndex  int    `json:"layer-index"`
+	LayerDigest string `json:"layer-digest,omitempty"`
+	// Header is nil when the file was not found in its layer.
+	Header  *Header  `json:"header,omitempty"`
+	History *History `json:"history,omitempty"`
+	// SHA256 is the digest of the full content of a regular file.
+	SHA256 string `json:"sha256,omitempty"`
+	// ContentFile names the file holding the first ContentBytes bytes of the
+	// content, relative to the record, when content was captured.
+	ContentFile  string `json:"content-file,omitempty"`
+	ContentBytes int    `json:"content-bytes,omitempty"`
+	// Truncated reports that the content file holds only the start of the
+	// content.
+	Truncated bool `json:"truncated,omitempty"`
+
+	content []byte
+}
+
+// Index lists the evidence of an image.
+type Index struct {
+	Image   string       `json:"image"`
+	Digest  string       `json:"digest,omitempty"`
+	Entries []IndexEntry `json:"findings"`
+}
+
+// IndexEntry locates the evidence of a finding.
+type IndexEntry struct {
+	Check      string `json:"check"`
+	Path       string `json:"path"`
+	LayerIndex int    `json:"layer-index"`
+	// Dir is the directory of the evidence, relative to the index.
+	Dir string `json:"dir"`
+}
+
+// Collect reads the layers of img holding findings and returns their
+// evidence, in the order of findings. Up to contentBytes bytes of the content
+// of regular files are kept; 0 keeps none.
+func Collect(ctx context.Context, img cr.Image, config *cr.ConfigFile, findings []Finding, contentBytes int64) ([]Record, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error getting image layers: %w", err)
+	}
+	history := imageutil.LayerHistory(config, len(layers))
+
+	records := make([]Record, len(findings))
+	byLayer := make(map[int]map[string][]int)
+	for i, f := range findings {
+		records[i] = Record{Check: f.Check, Path: f.Path, LayerIndex: f.LayerIndex}
+		if f.LayerIndex < 0 || f.LayerIndex >= len(layers) {
+			continue
+		}
+		if digest, err := layers[f.LayerIndex].Digest(); err == nil {
+			records[i].LayerDigest = digest.String()
+		}
+		if f.LayerIndex < len(history) {
+			records[i].History = newHistory(history[f.LayerIndex])
+		}
+		if byLayer[f.LayerIndex] == nil {
+			byLayer[f.LayerIndex] = make(map[string][]int)
+		}
+		byLayer[f.LayerIndex][f.Path] = append(byLayer[f.LayerIndex][f.Path], i)
+	}
+
+	for idx, paths := range byLayer {
+		if err := collectLayer(ctx, layers[idx], paths, records, contentBytes); err != nil {
+			return nil, fmt.Errorf("error reading layer %d: %w", idx, err)
+		}
+	}
+	for _, r := range records {
+		if r.Header == nil {
+			log.WithFields(log.Fields{"check": r.Check, "layer": r.LayerIndex}).Debug("File of a finding not found in its layer, recording it without a header")
+		}
+	}
+	return records, nil
+}
+
+// collectLayer fills the records of the files of a layer, listed by tar
+// path.
+func collectLayer(ctx context.Context, layer cr.Layer, paths map[string][]int, records []Record, contentBytes int64) error {
+	rc, err := imageutil.OpenLayer(layer)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	tr := tar.NewReader(rc)
+	remaining := len(paths)
+	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		indexes, ok := paths[hdr.Name]
+		if !ok {
+			continue
+		}
+		delete(paths, hdr.Name)
+		remaining--
+
+		header := newHeader(hdr)
+		var digest string
+		var content []byte
+		if hdr.Typeflag == tar.TypeReg {
+			h := sha256.New()
+			var head bytes.Buffer
+			w := io.Writer(h)
+			if contentBytes > 0 {
+				w = io.MultiWriter(h, &limitedBuffer{buf: &head, n: contentBytes})
+			}
+			if _, err := io.Copy(w, tr); err != nil {
+				return fmt.Errorf("error reading %s: %w", hdr.Name, err)
+			}
+			digest = "sha256:" + hex.EncodeToString(h.Sum(nil))
+			content = head.Bytes()
+		}
+		for _, i := range indexes {
+			records[i].Header = header
+			records[i].SHA256 = digest
+			if contentBytes > 0 && hdr.Typeflag == tar.TypeReg {
+				records[i].content = content
+				records[i].ContentFile = ContentFile
+				records[i].ContentBytes = len(content)
+				records[i].Truncated = hdr.Size > int64(len(content))
+			}
+		}
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first n bytes written to it and discards the rest.
+type limitedBuffer struct {
+	buf *bytes.Buffer
+	n   int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.n - int64(b.buf.Len()); room > 0 {
+		b.buf.Write(p[:min(int64(len(p)), room)])
+	}
+	return len(p), nil
+}
+
+func newHeader(h *tar.Header) *Header {
+	return &Header{
+		Name:     h.Name,
+		Type:     typeName(h.Typeflag),
+		Linkname: h.Linkname,
+		Mode:     fmt.Sprintf("%04o", h.Mode&0o7777),
+		UID:      h.Uid,
+		GID:      h.Gid,
+		Uname:    h.Uname,
+		Gname:    h.Gname,
+		Size:     h.Size,
+		ModTime:  h.ModTime.UTC().Format(time.RFC3339),
+	}
+}
+
+func typeName(t byte) string {
+	switch t {
+	case tar.TypeReg:
+		return "file"
+	case tar.TypeSymlink:
+		return "symlink"
+	case tar.TypeLink:
+		return "hardlink"
+	case tar.TypeDir:
+		return "dir"
+	case tar.TypeChar:
+		return "char"
+	case tar.TypeBlock:
+		return "block"
+	case tar.TypeFifo:
+		return "fifo"
+	}
+	return fmt.Sprintf("type-%c", t)
+}
+
+func newHistory(h cr.History) *History {
+	out := &History{CreatedBy: h.CreatedBy, Author: h.Author, Comment: h.Comment}
+	if !h.Created.IsZero() {
+		out.Created = h.Created.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// unsafeDirChars are replaced in the directory names derived from images.
+var unsafeDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// unnamedImageDir is the directory of the evidence of an image whose digest
+// is unknown and whose name has no safe characters, e.g. "...".
+const unnamedImageDir = "unnamed"
+
+// ImageDir returns the directory of the evidence of an image inside dir,
+// named after its digest (sha256-<hex>), or after its name when the digest is
+// unknown. It is never dir itself, which Write would otherwise clear.
+func ImageDir(dir, image, digest string) string {
+	name := strings.Replace(digest, ":", "-", 1)
+	if digest == "" {
+		name = strings.Trim(unsafeDirChars.ReplaceAllString(image, "_"), "_.")
+	}
+	if name == "" {
+		name = unnamedImageDir
+	}
+	return filepath.Join(dir, name)
+}
+
+// Write stores records under the directory of the image in dir, replacing
+// the evidence of a previous run: an index, and a directory per finding,
+// <check>/<n>, holding its record and captured content. Directories are
+// created with mode 0700 and files with 0600, since content may include
+// secrets. It returns the directory of the image.
+func Write(dir, image, digest string, records []Record) (string, error) {
+	imageDir := ImageDir(dir, image, digest)
+	if err := os.RemoveAll(imageDir); err != nil {
+		return "", fmt.Errorf("failed to clear evidence directory %s: %w", imageDir, err)
+	}
+	if err := os.MkdirAll(imageDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create evidence directory %s: %w", imageDir, err)
+	}
+
+	index := Index{Image: image, Digest: digest, Entries: []IndexEntry{}}
+	counts := make(map[string]int)
+	for _, r := range records {
+		counts[r.Check]++
+		rel := filepath.Join(r.Check, fmt.Sprint(counts[r.Check]))
+		recordDir := filepath.Join(imageDir, rel)
+		if err := os.MkdirAll(recordDir, 0700); err != nil {
+			return "", fmt.Errorf("failed to create evidence directory %s: %w", recordDir, err)
+		}
+		if err := writeJSON(filepath.Join(recordDir, EvidenceFile), r); err != nil {
+			return "", err
+		}
+		if r.ContentFile != "" {
+			if err := os.WriteFile(filepath.Join(recordDir, r.ContentFile), r.content, 0600); err != nil {
+				return "", fmt.Errorf("failed to write evidence content: %w", err)
+			}
+		}
+		index.Entries = append(index.Entries, IndexEntry{
+			Check:      r.Check,
+			Path:       r.Path,
+			LayerIndex: r.LayerIndex,
+			Dir:        filepath.ToSlash(rel),
+		})
+	}
+	if err := writeJSON(filepath.Join(imageDir, IndexFile), index); err != nil {
+		return "", err
+	}
+	return imageDir, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode evidence: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write evidence: %w", err)
+	}
+	return nil
+}
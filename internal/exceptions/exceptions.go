@@ -0,0 +1,170 @@
+// Package exceptions loads time-boxed exemptions that let specific image
+// digests pass specific checks, together with their approval metadata, and
+// parses the finding suppressions images declare in their labels.
+package exceptions
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/fileutil"
+)
+
+const dateLayout = "2006-01-02"
+
+// File is the content of an exceptions file.
+type File struct {
+	Exceptions []Exception `yaml:"exceptions" json:"exceptions"`
+}
+
+// Exception exempts one image digest from a set of checks until it expires.
+type Exception struct {
+	// Digest is the image manifest digest (sha256:...) the exception covers.
+	Digest string   `yaml:"digest" json:"digest"`
+	Checks []string `yaml:"checks" json:"checks"`
+	// Approver identifies who granted the exception.
+	Approver string `yaml:"approver" json:"approver"`
+	// Ticket links to the approval record, e.g. an issue URL.
+	Ticket string `yaml:"ticket,omitempty" json:"ticket,omitempty"`
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+	// Expires is a date (YYYY-MM-DD, valid through that day in UTC) or an
+	// RFC 3339 timestamp.
+	Expires string `yaml:"expires" json:"expires"`
+
+	expiresAt time.Time
+}
+
+// Load reads an exceptions file from path or stdin (if path is "-"), in YAML
+// or JSON format, and validates it. validChecks lists the check names an
+// exception may cover.
+func Load(path string, validChecks []string) (*File, error) {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading exceptions file: %w", err)
+	}
+
+	var f File
+	if err := fileutil.UnmarshalConfigData(data, &f, path); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(validChecks); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate checks that every exception names a valid digest, at least one
+// known check, an approver, and a parseable expiry.
+func (f *File) Validate(validChecks []string) error {
+	for i := range f.Exceptions {
+		e := &f.Exceptions[i]
+		if _, err := cr.NewHash(e.Digest); err != nil {
+			return fmt.Errorf("exception %d: invalid digest %q: %w", i+1, e.Digest, err)
+		}
+		if len(e.Checks) == 0 {
+			return fmt.Errorf("exception %d: at least one check is required", i+1)
+		}
+		for _, c := range e.Checks {
+			if !slices.Contains(validChecks, c) {
+				return fmt.Errorf("exception %d: unknown check %q, valid checks are: %s", i+1, c, strings.Join(validChecks, ", "))
+			}
+		}
+		if strings.TrimSpace(e.Approver) == "" {
+			return fmt.Errorf("exception %d: approver is required", i+1)
+		}
+		at, err := parseExpiry(e.Expires)
+		if err != nil {
+			return fmt.Errorf("exception %d: %w", i+1, err)
+		}
+		e.expiresAt = at
+	}
+	return nil
+}
+
+func parseExpiry(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("expires is required")
+	}
+	if d, err := time.Parse(dateLayout, s); err == nil {
+		return d.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expires %q, expected YYYY-MM-DD or an RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
+
+// ExpiresAt returns the instant the exception stops applying.
+func (e Exception) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+// Expired reports whether the exception no longer applies at now.
+func (e Exception) Expired(now time.Time) bool {
+	return !now.Before(e.expiresAt)
+}
+
+// Covers reports whether the exception names check for one of digests.
+func (e Exception) Covers(digests []string, check string) bool {
+	return slices.Contains(digests, e.Digest) && slices.Contains(e.Checks, check)
+}
+
+// Match returns the exceptions covering check for one of digests, split into
+// those that still apply at now and those that have expired.
+func (f *File) Match(digests []string, check string, now time.Time) (active, expired []Exception) {
+	for _, e := range f.Exceptions {
+		if !e.Covers(digests, check) {
+			continue
+		}
+		if e.Expired(now) {
+			expired = append(expired, e)
+		} else {
+			active = append(active, e)
+		}
+	}
+	return active, expired
+}
+
+// ByExpiry returns all exceptions ordered by expiry, soonest first.
+func (f *File) ByExpiry() []Exception {
+	out := slices.Clone(f.Exceptions)
+	slices.SortStableFunc(out, func(a, b Exception) int {
+		return a.expiresAt.Compare(b.expiresAt)
+	})
+	return out
+}
+
+// Expiring returns the exceptions that expire before now+within, including
+// those that have already expired, ordered by expiry.
+func (f *File) Expiring(now time.Time, within time.Duration) []Exception {
+	limit := now.Add(within)
+	var out []Exception
+	for _, e := range f.ByExpiry() {
+		if e.expiresAt.Before(limit) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ParseWindow parses a look-ahead window such as "30d", or any Go duration
+// such as "72h".
+func ParseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid window %q, expected a number of days such as 30d or a duration such as 72h", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid window %q, expected a number of days such as 30d or a duration such as 72h", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,194 @@
+package exceptions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	digestA = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	digestB = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+var testChecks = []string{"age", "secrets", "user"}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+		wantLen int
+	}{
+		{
+			name: "Valid YAML",
+			file: "exceptions.yaml",
+			content: `exceptions:
+  - digest: ` + digestA + `
+    checks: [secrets, user]
+    approver: jane@example.com
+    ticket: https://issues.example.com/SEC-1
+    expires: 2026-12-31
+  - digest: ` + digestB + `
+    checks: [age]
+    approver: ops
+    expires: "2026-06-01T12:00:00Z"
+`,
+			wantLen: 2,
+		},
+		{
+			name:    "Valid JSON",
+			file:    "exceptions.json",
+			content: `{"exceptions": [{"digest": "` + digestA + `", "checks": ["age"], "approver": "ops", "expires": "2026-12-31"}]}`,
+			wantLen: 1,
+		},
+		{name: "Empty", file: "exceptions.yaml", content: "exceptions: []\n"},
+		{
+			name:    "Invalid digest",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: nginx, checks: [age], approver: ops, expires: 2026-12-31}\n",
+			wantErr: "exception 1: invalid digest",
+		},
+		{
+			name:    "No checks",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: " + digestA + ", approver: ops, expires: 2026-12-31}\n",
+			wantErr: "exception 1: at least one check is required",
+		},
+		{
+			name:    "Unknown check",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: " + digestA + ", checks: [size], approver: ops, expires: 2026-12-31}\n",
+			wantErr: `unknown check "size"`,
+		},
+		{
+			name:    "Missing approver",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: " + digestA + ", checks: [age], expires: 2026-12-31}\n",
+			wantErr: "approver is required",
+		},
+		{
+			name:    "Missing expiry",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: " + digestA + ", checks: [age], approver: ops}\n",
+			wantErr: "expires is required",
+		},
+		{
+			name:    "Invalid expiry",
+			file:    "exceptions.yaml",
+			content: "exceptions:\n  - {digest: " + digestA + ", checks: [age], approver: ops, expires: next week}\n",
+			wantErr: "invalid expires",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Load(writeFile(t, tt.file, tt.content), testChecks)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, f.Exceptions, tt.wantLen)
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), testChecks)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading exceptions file")
+}
+
+func testFile(t *testing.T) *File {
+	t.Helper()
+	f := &File{Exceptions: []Exception{
+		{Digest: digestA, Checks: []string{"secrets"}, Approver: "jane", Expires: "2026-03-31"},
+		{Digest: digestA, Checks: []string{"user"}, Approver: "ops", Expires: "2026-01-31"},
+		{Digest: digestB, Checks: []string{"user"}, Approver: "ops", Expires: "2026-02-15T00:00:00Z"},
+	}}
+	require.NoError(t, f.Validate(testChecks))
+	return f
+}
+
+func TestMatch(t *testing.T) {
+	f := testFile(t)
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	active, expired := f.Match([]string{digestA}, "secrets", now)
+	assert.Len(t, active, 1)
+	assert.Empty(t, expired)
+
+	active, expired = f.Match([]string{digestA}, "user", now)
+	assert.Empty(t, active)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "2026-01-31", expired[0].Expires)
+
+	active, expired = f.Match([]string{"sha256:other", digestB}, "user", now)
+	assert.Len(t, active, 1)
+	assert.Empty(t, expired)
+
+	active, expired = f.Match([]string{digestA}, "age", now)
+	assert.Empty(t, active)
+	assert.Empty(t, expired)
+}
+
+func TestExpired_DateIsInclusive(t *testing.T) {
+	f := testFile(t)
+	e := f.Exceptions[1] // expires 2026-01-31
+	assert.False(t, e.Expired(time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, e.Expired(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestExpiring(t *testing.T) {
+	f := testFile(t)
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	got := f.Expiring(now, 30*24*time.Hour)
+	require.Len(t, got, 2, "expired and soon-expiring exceptions are listed")
+	assert.Equal(t, "2026-01-31", got[0].Expires)
+	assert.Equal(t, "2026-02-15T00:00:00Z", got[1].Expires)
+
+	all := f.ByExpiry()
+	require.Len(t, all, 3)
+	assert.Equal(t, "2026-03-31", all[2].Expires)
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "0d", want: 0},
+		{in: "72h", want: 72 * time.Hour},
+		{in: "d", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseWindow(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
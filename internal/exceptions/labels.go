@@ -0,0 +1,114 @@
+package exceptions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SuppressionLabel is the image label that suppresses a finding. Further
+// suppressions use labels with this prefix and a dot, such as
+// check-image.suppress.fixtures.
+const SuppressionLabel = "check-image.suppress"
+
+// Suppression exempts the findings of a check about one subject, such as
+// the path of a file, declared by the image itself in a label:
+//
+//	check-image.suppress=secrets:/app/test/fixture.pem;expires=2025-12-31;reason=test key
+//
+// The subject is literal: an image cannot suppress a whole tree of findings
+// with a glob pattern. Expires and reason are optional.
+type Suppression struct {
+	// Label is the key of the label that declared the suppression.
+	Label   string
+	Check   string
+	Subject string
+	// Expires is a date (YYYY-MM-DD, valid through that day in UTC) or an
+	// RFC 3339 timestamp; empty when the suppression does not expire.
+	Expires string
+	Reason  string
+
+	expiresAt time.Time
+}
+
+// IsSuppressionLabel reports whether key is a suppression label.
+func IsSuppressionLabel(key string) bool {
+	return key == SuppressionLabel || strings.HasPrefix(key, SuppressionLabel+".")
+}
+
+// ParseSuppressionLabels returns the suppressions declared by labels, ordered
+// by label key, and an error for every suppression label that is malformed or
+// names a check that is not in validChecks.
+func ParseSuppressionLabels(labels map[string]string, validChecks []string) ([]Suppression, []error) {
+	var keys []string
+	for key := range labels {
+		if IsSuppressionLabel(key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var suppressions []Suppression
+	var errs []error
+	for _, key := range keys {
+		s, err := parseSuppression(key, labels[key], validChecks)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("label %s: %w", key, err))
+			continue
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, errs
+}
+
+// globChars are the characters of path.Match patterns, which label subjects
+// cannot contain.
+const globChars = "*?[\\"
+
+func parseSuppression(key, value string, validChecks []string) (Suppression, error) {
+	fields := strings.Split(value, ";")
+	check, subject, ok := strings.Cut(strings.TrimSpace(fields[0]), ":")
+	check, subject = strings.TrimSpace(check), strings.TrimSpace(subject)
+	if !ok || check == "" || subject == "" {
+		return Suppression{}, fmt.Errorf("invalid suppression %q, expected <check>:<subject>", value)
+	}
+	if !slices.Contains(validChecks, check) {
+		return Suppression{}, fmt.Errorf("unknown check %q", check)
+	}
+	if strings.ContainsAny(subject, globChars) {
+		return Suppression{}, fmt.Errorf("invalid subject %q, subjects must be literal and cannot contain any of %s", subject, globChars)
+	}
+
+	s := Suppression{Label: key, Check: check, Subject: subject}
+	for _, field := range fields[1:] {
+		name, v, _ := strings.Cut(field, "=")
+		v = strings.TrimSpace(v)
+		switch strings.TrimSpace(name) {
+		case "expires":
+			at, err := parseExpiry(v)
+			if err != nil {
+				return Suppression{}, err
+			}
+			s.Expires, s.expiresAt = v, at
+		case "reason":
+			s.Reason = v
+		case "":
+		default:
+			return Suppression{}, fmt.Errorf("unknown suppression field %q, valid fields are: expires, reason", strings.TrimSpace(name))
+		}
+	}
+	return s, nil
+}
+
+// Expired reports whether the suppression no longer applies at now.
+// Suppressions without an expiry never expire.
+func (s Suppression) Expired(now time.Time) bool {
+	return s.Expires != "" && !now.Before(s.expiresAt)
+}
+
+// Matches reports whether the suppression covers the finding of check about
+// subject.
+func (s Suppression) Matches(check, subject string) bool {
+	return s.Check == check && subject != "" && s.Subject == subject
+}
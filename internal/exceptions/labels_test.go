@@ -0,0 +1,58 @@
+package exceptions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSuppressionLabels(t *testing.T) {
+	labels := map[string]string{
+		"check-image.suppress":          "secrets:/app/test/fixture.pem;expires=2025-12-31;reason=test key",
+		"check-image.suppress.fixtures": " secrets : /app/test/server.key ",
+		"check-image.suppressed":        "secrets:/ignored",
+		"org.opencontainers.image.url":  "https://example.com",
+	}
+	suppressions, errs := ParseSuppressionLabels(labels, testChecks)
+	assert.Empty(t, errs)
+	require.Len(t, suppressions, 2)
+
+	s := suppressions[0]
+	assert.Equal(t, "check-image.suppress", s.Label)
+	assert.Equal(t, "secrets", s.Check)
+	assert.Equal(t, "/app/test/fixture.pem", s.Subject)
+	assert.Equal(t, "2025-12-31", s.Expires)
+	assert.Equal(t, "test key", s.Reason)
+	assert.False(t, s.Expired(time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)), "dates are valid through the day")
+	assert.True(t, s.Expired(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	s = suppressions[1]
+	assert.Equal(t, "/app/test/server.key", s.Subject)
+	assert.False(t, s.Expired(time.Now()), "suppressions without an expiry never expire")
+	assert.True(t, s.Matches("secrets", "/app/test/server.key"))
+	assert.False(t, s.Matches("secrets", "/app/test/server.key.bak"))
+	assert.False(t, s.Matches("user", "/app/test/server.key"))
+	assert.False(t, s.Matches("secrets", ""))
+}
+
+func TestParseSuppressionLabels_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"secrets":                          "expected <check>:<subject>",
+		"secrets:":                         "expected <check>:<subject>",
+		"cve:/app/key.pem":                 `unknown check "cve"`,
+		"secrets:/app/[":                   "subjects must be literal",
+		"secrets:/app/*":                   "subjects must be literal",
+		"secrets:/app/key-?.pem":           "subjects must be literal",
+		"secrets:/app/key.pem;expires=bad": `invalid expires "bad"`,
+		"secrets:/app/key.pem;owner=me":    `unknown suppression field "owner"`,
+	}
+	for value, want := range tests {
+		suppressions, errs := ParseSuppressionLabels(map[string]string{"check-image.suppress": value}, testChecks)
+		assert.Empty(t, suppressions, value)
+		require.Len(t, errs, 1, value)
+		assert.Contains(t, errs[0].Error(), "label check-image.suppress: ")
+		assert.Contains(t, errs[0].Error(), want)
+	}
+}
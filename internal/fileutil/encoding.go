@@ -0,0 +1,52 @@
+package fileutil
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some Windows editors write at the start of
+// UTF-8 files.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// NormalizeText prepares config or policy data for parsing: it strips a UTF-8
+// byte order mark and converts Windows (CRLF) line endings to LF. Data that is
+// not UTF-8, such as files saved as UTF-16, is rejected with an error naming
+// the encoding problem and its position, instead of failing later with a
+// confusing parse error.
+func NormalizeText(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) || bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
+		return nil, fmt.Errorf("file is UTF-16 encoded, save it as UTF-8")
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if err := checkUTF8(data); err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("\r\n")) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return data, nil
+}
+
+// checkUTF8 reports the position of the first byte of data that is not valid
+// UTF-8.
+func checkUTF8(data []byte) error {
+	if utf8.Valid(data) {
+		return nil
+	}
+	line, column := 1, 1
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return fmt.Errorf("file is not valid UTF-8: invalid byte 0x%02x at line %d, column %d", data[i], line, column)
+		}
+		if r == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+		i += size
+	}
+	return nil
+}
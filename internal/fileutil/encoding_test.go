@@ -0,0 +1,39 @@
+package fileutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{name: "plain", data: "key: value\n", want: "key: value\n"},
+		{name: "UTF-8 BOM", data: "\xef\xbb\xbfkey: value\n", want: "key: value\n"},
+		{name: "CRLF", data: "a: 1\r\nb: 2\r\n", want: "a: 1\nb: 2\n"},
+		{name: "BOM and CRLF", data: "\xef\xbb\xbf{\r\n  \"a\": 1\r\n}\r\n", want: "{\n  \"a\": 1\n}\n"},
+		{name: "non-ASCII UTF-8", data: "owner: José\n", want: "owner: José\n"},
+		{name: "UTF-16 LE", data: "\xff\xfek\x00e\x00y\x00", wantErr: "file is UTF-16 encoded, save it as UTF-8"},
+		{name: "UTF-16 BE", data: "\xfe\xff\x00k\x00e\x00y", wantErr: "file is UTF-16 encoded, save it as UTF-8"},
+		{name: "Latin-1", data: "a: 1\nowner: Jos\xe9\n", wantErr: "file is not valid UTF-8: invalid byte 0xe9 at line 2, column 11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeText([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -10,9 +10,10 @@ func HasYAMLExtension(path string) bool {
 	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
 }
 
-// IsYAML returns true if content appears to be YAML, false if JSON
+// IsYAML returns true if content appears to be YAML, false if JSON. A leading
+// byte order mark and JSONC comments are skipped.
 func IsYAML(data []byte) bool {
-	trimmed := bytes.TrimSpace(data)
+	trimmed := bytes.TrimSpace(StripJSONComments(bytes.TrimPrefix(data, utf8BOM)))
 	if len(trimmed) == 0 {
 		return false // default to JSON
 	}
@@ -23,3 +24,20 @@ func IsYAML(data []byte) bool {
 	}
 	return true // YAML
 }
+
+// IsYAMLConfig reports whether config data read from filePath is YAML. The
+// format is detected from content for stdin ("-") and for URLs whose path has
+// no .json, .yaml, or .yml extension, and from the extension otherwise.
+func IsYAMLConfig(data []byte, filePath string) bool {
+	if filePath == "-" {
+		return IsYAML(data)
+	}
+	if IsURL(filePath) {
+		path := urlPath(filePath)
+		if !HasYAMLExtension(path) && !strings.HasSuffix(path, ".json") {
+			return IsYAML(data)
+		}
+		return HasYAMLExtension(path)
+	}
+	return HasYAMLExtension(filePath)
+}
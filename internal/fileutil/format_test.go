@@ -32,6 +32,16 @@ func TestIsYAML(t *testing.T) {
 			data:   []byte("\n\n  { \"key\": \"value\" }"),
 			isYAML: false,
 		},
+		{
+			name:   "JSONC with leading comments",
+			data:   []byte("// Registry policy\n/* reviewed */ {\"key\": \"value\"}"),
+			isYAML: false,
+		},
+		{
+			name:   "JSON with a byte order mark",
+			data:   []byte("\xef\xbb\xbf{\"key\": \"value\"}"),
+			isYAML: false,
+		},
 		{
 			name:   "YAML simple",
 			data:   []byte("key: value"),
@@ -0,0 +1,64 @@
+package fileutil
+
+// StripJSONComments blanks out the // line comments and /* block */ comments
+// of JSONC data, so that it parses as JSON. Comments are replaced with spaces,
+// keeping line breaks, so that the offsets of syntax errors still point into
+// the original data. Comment markers inside strings are left alone, and an
+// unterminated block comment is kept to fail parsing. Data without comments
+// is returned as is.
+func StripJSONComments(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c != '/' || i+1 >= len(data) {
+			continue
+		}
+
+		end := -1
+		switch data[i+1] {
+		case '/':
+			end = i + 2
+			for end < len(data) && data[end] != '\n' {
+				end++
+			}
+		case '*':
+			for j := i + 2; j+1 < len(data); j++ {
+				if data[j] == '*' && data[j+1] == '/' {
+					end = j + 2
+					break
+				}
+			}
+		}
+		if end < 0 {
+			continue
+		}
+
+		if out == nil {
+			out = append([]byte(nil), data...)
+		}
+		for j := i; j < end; j++ {
+			if out[j] != '\n' && out[j] != '\r' {
+				out[j] = ' '
+			}
+		}
+		i = end - 1
+	}
+	if out == nil {
+		return data
+	}
+	return out
+}
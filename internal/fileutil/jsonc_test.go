@@ -0,0 +1,42 @@
+package fileutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "no comments", data: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "line comment", data: "{\n  // why\n  \"a\": 1\n}", want: "{\n        \n  \"a\": 1\n}"},
+		{name: "trailing line comment", data: "{\"a\": 1} // done", want: "{\"a\": 1}        "},
+		{name: "block comment", data: `{/* a */"a": 1}`, want: `{       "a": 1}`},
+		{name: "multi-line block comment keeps line breaks", data: "/* a\r\nb */{}", want: "    \r\n    {}"},
+		{name: "markers in strings", data: `{"url": "https://example.com/*x*/", "q": "\"//"}`, want: `{"url": "https://example.com/*x*/", "q": "\"//"}`},
+		{name: "unterminated block comment", data: `{} /* open`, want: `{} /* open`},
+		{name: "lone slash", data: `{"a": 1}/`, want: `{"a": 1}/`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(StripJSONComments([]byte(tt.data))))
+		})
+	}
+}
+
+func TestStripJSONComments_DoesNotModifyInput(t *testing.T) {
+	data := []byte(`{"a": 1} // comment`)
+	out := StripJSONComments(data)
+	assert.Equal(t, `{"a": 1} // comment`, string(data))
+
+	var v map[string]int
+	require.NoError(t, json.Unmarshal(out, &v))
+	assert.Equal(t, 1, v["a"])
+}
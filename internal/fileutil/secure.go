@@ -86,10 +86,14 @@ func ReadStdin() ([]byte, error) {
 	return data, nil
 }
 
-// ReadFileOrStdin reads from file path or stdin if path is "-"
+// ReadFileOrStdin reads from file path, stdin if path is "-", or an https://
+// URL, optionally pinned with a #sha256=<hex> fragment.
 func ReadFileOrStdin(path string) ([]byte, error) {
 	if path == "-" {
 		return ReadStdin()
 	}
+	if IsURL(path) {
+		return readSourceURL(path)
+	}
 	return ReadSecureFile(path)
 }
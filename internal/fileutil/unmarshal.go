@@ -7,23 +7,23 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// UnmarshalConfigData unmarshals data using content detection for stdin
+// UnmarshalConfigData unmarshals data using content detection for stdin.
+// JSON data may contain comments (JSONC). The data is normalized with
+// NormalizeText first, so BOMs and CRLF line endings are accepted.
 func UnmarshalConfigData(data []byte, v any, filePath string) error {
-	var isYAML bool
-	if filePath == "-" {
-		isYAML = IsYAML(data)
-	} else {
-		isYAML = HasYAMLExtension(filePath)
+	data, err := NormalizeText(data)
+	if err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
 	}
 
-	if isYAML {
+	if IsYAMLConfig(data, filePath) {
 		if err := yaml.Unmarshal(data, v); err != nil {
 			return fmt.Errorf("invalid YAML: %w", err)
 		}
 		return nil
 	}
 
-	if err := json.Unmarshal(data, v); err != nil {
+	if err := json.Unmarshal(StripJSONComments(data), v); err != nil {
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 	return nil
@@ -49,6 +49,35 @@ func TestUnmarshalConfigData_JSON(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid JSON",
 		},
+		{
+			name: "JSON with comments",
+			content: `{
+				// Approved by the platform team
+				"name": "test-config", /* inline */
+				"values": ["a", "b // not a comment"]
+			}`,
+			fileName: "config.json",
+			validate: func(t *testing.T, cfg *testConfig) {
+				assert.Equal(t, "test-config", cfg.Name)
+				assert.Equal(t, []string{"a", "b // not a comment"}, cfg.Values)
+			},
+		},
+		{
+			name:     "JSON with a byte order mark and CRLF line endings",
+			content:  "\xef\xbb\xbf{\r\n  \"name\": \"windows\",\r\n  \"count\": 3\r\n}\r\n",
+			fileName: "config.json",
+			validate: func(t *testing.T, cfg *testConfig) {
+				assert.Equal(t, "windows", cfg.Name)
+				assert.Equal(t, 3, cfg.Count)
+			},
+		},
+		{
+			name:        "UTF-16 JSON",
+			content:     "\xff\xfe{\x00}\x00",
+			fileName:    "config.json",
+			wantErr:     true,
+			errContains: "invalid encoding: file is UTF-16 encoded",
+		},
 		{
 			name:        "Invalid JSON - trailing comma",
 			content:     `{"name": "test",}`,
@@ -125,6 +154,22 @@ count: 42`,
 				assert.Equal(t, 42, cfg.Count)
 			},
 		},
+		{
+			name:     "YAML with a byte order mark and CRLF line endings",
+			content:  "\xef\xbb\xbfname: windows\r\nvalues:\r\n  - a\r\n",
+			fileName: "config.yaml",
+			validate: func(t *testing.T, cfg *testConfig) {
+				assert.Equal(t, "windows", cfg.Name)
+				assert.Equal(t, []string{"a"}, cfg.Values)
+			},
+		},
+		{
+			name:        "Latin-1 YAML",
+			content:     "name: caf\xe9\n",
+			fileName:    "config.yaml",
+			wantErr:     true,
+			errContains: "invalid encoding: file is not valid UTF-8: invalid byte 0xe9 at line 1, column 10",
+		},
 		{
 			name: "YAML with .yml extension",
 			content: `name: yml-config
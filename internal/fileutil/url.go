@@ -0,0 +1,156 @@
+package fileutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checksumFragment introduces the sha256 checksum a URL source is pinned to,
+// e.g. https://example.com/policy.yaml#sha256=<hex>.
+const checksumFragment = "sha256="
+
+// urlTimeout bounds the fetch of a URL source read by ReadFileOrStdin.
+const urlTimeout = 30 * time.Second
+
+// httpClient fetches URL sources. Tests replace it to trust their TLS servers.
+var httpClient = http.DefaultClient
+
+// maxRedirects is the number of redirects followed for a URL source, as the
+// default of net/http.
+const maxRedirects = 10
+
+// sourceDigests records the sha256 digest of the content of each URL source
+// read by ReadFileOrStdin, keyed by the source as given.
+var sourceDigests sync.Map
+
+// IsURL reports whether source is an http:// or https:// URL.
+func IsURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// ReadURL fetches the content at url with a GET request. The response body is
+// subject to the same 10MB limit as files and stdin. A #sha256=<hex> fragment
+// pins the content: it is not sent, and a body with another checksum is
+// rejected.
+func ReadURL(ctx context.Context, url string) ([]byte, error) {
+	return readURL(ctx, httpClient, url)
+}
+
+func readURL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	url, checksum, err := splitURLChecksum(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching URL: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching URL: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStdinSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if len(data) > maxStdinSize {
+		return nil, fmt.Errorf("response exceeds maximum size of %d bytes", maxStdinSize)
+	}
+
+	if checksum != nil {
+		sum := sha256.Sum256(data)
+		if !bytes.Equal(sum[:], checksum) {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", url, hex.EncodeToString(checksum), hex.EncodeToString(sum[:]))
+		}
+	}
+	return data, nil
+}
+
+// readSourceURL reads a URL source of ReadFileOrStdin. Only https:// URLs are
+// accepted, and redirects to other schemes are refused, since policies
+// fetched in clear text could be tampered with. The digest of the content is
+// recorded for SourceDigest.
+func readSourceURL(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "https://") {
+		return nil, fmt.Errorf("insecure URL %s: only https:// URLs are supported", source)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), urlTimeout)
+	defer cancel()
+	client := *httpClient
+	client.CheckRedirect = httpsOnlyRedirect
+	data, err := readURL(ctx, &client, source)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	sourceDigests.Store(source, "sha256:"+hex.EncodeToString(sum[:]))
+	return data, nil
+}
+
+// httpsOnlyRedirect refuses the redirects of a URL source to other schemes
+// than https://, so that a policy cannot be downgraded to clear text.
+func httpsOnlyRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("insecure redirect to %s: only https:// URLs are supported", req.URL.Redacted())
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+// SourceDigest returns the sha256 digest (sha256:<hex>) of the content last
+// read from the URL source by ReadFileOrStdin. The second value is false when
+// source was not read.
+func SourceDigest(source string) (string, bool) {
+	d, ok := sourceDigests.Load(source)
+	if !ok {
+		return "", false
+	}
+	return d.(string), true
+}
+
+// splitURLChecksum removes the fragment of source, returning the sha256
+// checksum it pins, or nil when there is no fragment.
+func splitURLChecksum(source string) (string, []byte, error) {
+	base, fragment, found := strings.Cut(source, "#")
+	if !found {
+		return source, nil, nil
+	}
+	value, ok := strings.CutPrefix(fragment, checksumFragment)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid URL fragment %q, expected #%s<hex>", fragment, checksumFragment)
+	}
+	checksum, err := hex.DecodeString(value)
+	if err != nil || len(checksum) != sha256.Size {
+		return "", nil, fmt.Errorf("invalid sha256 checksum %q, expected %d hexadecimal characters", value, 2*sha256.Size)
+	}
+	return base, checksum, nil
+}
+
+// urlPath returns the path of a URL source, without its query and fragment,
+// so that its extension can be checked.
+func urlPath(source string) string {
+	u, err := url.Parse(source)
+	if err != nil {
+		return source
+	}
+	return u.Path
+}
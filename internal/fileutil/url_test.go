@@ -0,0 +1,152 @@
+package fileutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsURL(t *testing.T) {
+	assert.True(t, IsURL("https://example.com/config.yaml"))
+	assert.True(t, IsURL("http://localhost:8080/config.yaml"))
+	assert.False(t, IsURL("config/config.yaml"))
+	assert.False(t, IsURL("oci://ghcr.io/org/policy:v1"))
+	assert.False(t, IsURL("-"))
+}
+
+func TestReadURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte("checks:\n  age: {}\n"))
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("a", maxStdinSize+1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		data, err := ReadURL(context.Background(), server.URL+"/ok")
+		require.NoError(t, err)
+		assert.Equal(t, "checks:\n  age: {}\n", string(data))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := ReadURL(context.Background(), server.URL+"/missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("Exceeds size limit", func(t *testing.T) {
+		_, err := ReadURL(context.Background(), server.URL+"/large")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds maximum size")
+	})
+}
+
+func TestReadURL_Checksum(t *testing.T) {
+	content := "trusted-registries:\n  - ghcr.io\n"
+	sum := sha256.Sum256([]byte(content))
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"Matching checksum", server.URL + "/policy.yaml#sha256=" + checksum, ""},
+		{"Uppercase checksum", server.URL + "/policy.yaml#sha256=" + strings.ToUpper(checksum), ""},
+		{"Mismatched checksum", server.URL + "/policy.yaml#sha256=" + strings.Repeat("0", 64), "checksum mismatch for " + server.URL + "/policy.yaml: expected sha256:" + strings.Repeat("0", 64) + ", got sha256:" + checksum},
+		{"Short checksum", server.URL + "/policy.yaml#sha256=abc", `invalid sha256 checksum "abc"`},
+		{"Other fragment", server.URL + "/policy.yaml#section", `invalid URL fragment "section"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ReadURL(context.Background(), tt.url)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+		})
+	}
+}
+
+func TestReadFileOrStdin_URL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"min-uid": 1000}`))
+	}))
+	defer server.Close()
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = server.Client()
+
+	data, err := ReadFileOrStdin(server.URL + "/policy.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"min-uid": 1000}`, string(data))
+
+	digest, ok := SourceDigest(server.URL + "/policy.json")
+	require.True(t, ok)
+	sum := sha256.Sum256([]byte(`{"min-uid": 1000}`))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), digest, "the digest of the fetched content is recorded")
+	_, ok = SourceDigest(server.URL + "/other.json")
+	assert.False(t, ok)
+
+	_, err = ReadFileOrStdin("http://example.com/policy.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only https:// URLs are supported")
+}
+
+func TestReadFileOrStdin_URLRedirect(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"min-uid": 0}`))
+	}))
+	defer plain.Close()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/downgrade.json":
+			http.Redirect(w, r, plain.URL+"/policy.json", http.StatusFound)
+		case "/moved.json":
+			http.Redirect(w, r, "/policy.json", http.StatusMovedPermanently)
+		default:
+			_, _ = w.Write([]byte(`{"min-uid": 1000}`))
+		}
+	}))
+	defer server.Close()
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = server.Client()
+
+	data, err := ReadFileOrStdin(server.URL + "/moved.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"min-uid": 1000}`, string(data), "https redirects are followed")
+
+	_, err = ReadFileOrStdin(server.URL + "/downgrade.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "insecure redirect")
+}
+
+func TestIsYAMLConfig_URL(t *testing.T) {
+	yamlData := []byte("min-uid: 1000\n")
+	jsonData := []byte(`{"min-uid": 1000}`)
+
+	assert.True(t, IsYAMLConfig(jsonData, "https://example.com/policy.yaml#sha256=abc"), "the extension wins over the content")
+	assert.False(t, IsYAMLConfig(yamlData, "https://example.com/policy.json?ref=main"), "the extension wins over the content")
+	assert.True(t, IsYAMLConfig(yamlData, "https://example.com/policy"))
+	assert.False(t, IsYAMLConfig(jsonData, "https://example.com/policy"))
+}
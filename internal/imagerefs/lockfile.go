@@ -0,0 +1,71 @@
+package imagerefs
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// lockfile holds the fields of the supported lockfile formats: the
+// dependencies of a helmfile.lock or Chart.lock, the images of a Carvel
+// imgpkg ImagesLock, and the overrides of a kbld lock.
+type lockfile struct {
+	Kind   string `yaml:"kind"`
+	Images []struct {
+		Image string `yaml:"image"`
+	} `yaml:"images"`
+	Dependencies []struct {
+		Name       string `yaml:"name"`
+		Repository string `yaml:"repository"`
+		Version    string `yaml:"version"`
+	} `yaml:"dependencies"`
+	Overrides []struct {
+		Image    string `yaml:"image"`
+		NewImage string `yaml:"newImage"`
+	} `yaml:"overrides"`
+}
+
+// FromLockfile returns the OCI references of a lockfile, in order of
+// appearance and without duplicates:
+//   - helmfile.lock and Chart.lock: the charts of dependencies stored in OCI
+//     registries (oci:// repositories); charts of HTTP repositories are
+//     skipped.
+//   - imgpkg ImagesLock (.imgpkg/images.yml): every image.
+//   - kbld lock (--lock-output): the resolved image of every override.
+//
+// JSON lockfiles are read too, as YAML.
+func FromLockfile(data []byte) ([]string, error) {
+	var lock lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("error parsing lockfile: %w", err)
+	}
+
+	var refs refList
+	switch {
+	case lock.Kind == "ImagesLock":
+		for _, i := range lock.Images {
+			refs.add(i.Image)
+		}
+	case len(lock.Overrides) > 0:
+		for _, o := range lock.Overrides {
+			refs.add(o.NewImage)
+		}
+	case len(lock.Dependencies) > 0:
+		for _, d := range lock.Dependencies {
+			repo, ok := strings.CutPrefix(d.Repository, "oci://")
+			if !ok {
+				log.WithFields(log.Fields{"chart": d.Name, "repository": d.Repository}).Debug("Skipping chart of a non-OCI repository")
+				continue
+			}
+			// Helm pushes chart versions with build metadata with "_" for "+",
+			// which tags cannot contain.
+			version := strings.ReplaceAll(d.Version, "+", "_")
+			refs.add(withVersion(strings.TrimSuffix(repo, "/")+"/"+d.Name, version, ""))
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized lockfile format: expected a helmfile.lock or Chart.lock, an imgpkg ImagesLock, or a kbld lock")
+	}
+	return refs.list, nil
+}
@@ -0,0 +1,77 @@
+package imagerefs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromLockfile_Helm(t *testing.T) {
+	data := `version: v0.150.0
+dependencies:
+- name: redis
+  repository: oci://registry-1.docker.io/bitnamicharts
+  version: 19.0.1
+- name: ingress-nginx
+  repository: https://kubernetes.github.io/ingress-nginx
+  version: 4.10.0
+- name: app
+  repository: oci://ghcr.io/org/charts/
+  version: 1.2.0+build.5
+digest: sha256:0123
+generated: "2026-01-01T00:00:00Z"
+`
+
+	refs, err := FromLockfile([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"registry-1.docker.io/bitnamicharts/redis:19.0.1",
+		"ghcr.io/org/charts/app:1.2.0_build.5",
+	}, refs)
+}
+
+func TestFromLockfile_ImagesLock(t *testing.T) {
+	data := `apiVersion: imgpkg.carvel.dev/v1alpha1
+kind: ImagesLock
+images:
+- image: index.docker.io/library/nginx@sha256:aaaa
+  annotations:
+    kbld.carvel.dev/id: nginx:1.27
+- image: ghcr.io/org/app@sha256:bbbb
+`
+
+	refs, err := FromLockfile([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"index.docker.io/library/nginx@sha256:aaaa", "ghcr.io/org/app@sha256:bbbb"}, refs)
+}
+
+func TestFromLockfile_Kbld(t *testing.T) {
+	data := `apiVersion: kbld.k14s.io/v1alpha1
+kind: Config
+overrides:
+- image: nginx:1.27
+  newImage: index.docker.io/library/nginx@sha256:aaaa
+  preresolved: true
+- image: nginx
+  newImage: index.docker.io/library/nginx@sha256:aaaa
+  preresolved: true
+- image: ghcr.io/org/app:1.0
+  newImage: ghcr.io/org/app@sha256:bbbb
+  preresolved: true
+`
+
+	refs, err := FromLockfile([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"index.docker.io/library/nginx@sha256:aaaa", "ghcr.io/org/app@sha256:bbbb"}, refs)
+}
+
+func TestFromLockfile_Errors(t *testing.T) {
+	_, err := FromLockfile([]byte("dependencies: [unclosed"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing lockfile")
+
+	_, err = FromLockfile([]byte("version: 1\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unrecognized lockfile format")
+}
@@ -0,0 +1,72 @@
+// Package imagerefs extracts the container image references listed in
+// artifacts such as SBOMs and lockfiles, so that the images they name can be
+// validated together.
+package imagerefs
+
+import (
+	"net/url"
+	"strings"
+)
+
+// FromPURL returns the image reference of a docker or oci package URL, e.g.
+// pkg:docker/org/app@1.0?repository_url=ghcr.io or
+// pkg:oci/app@sha256%3Aabc...?repository_url=ghcr.io/org/app. It reports
+// false for package URLs of other types and for malformed ones.
+func FromPURL(purl string) (string, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	rest, version, _ := strings.Cut(rest, "@")
+	typ, path, ok := strings.Cut(rest, "/")
+	if !ok || path == "" {
+		return "", false
+	}
+	qualifiers, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", false
+	}
+	if version, err = url.PathUnescape(version); err != nil {
+		return "", false
+	}
+	if path, err = url.PathUnescape(path); err != nil {
+		return "", false
+	}
+
+	var repo string
+	switch strings.ToLower(typ) {
+	case "docker":
+		// The namespace and name are the repository, of the registry named by
+		// repository_url, Docker Hub by default.
+		repo = path
+		if registry := qualifiers.Get("repository_url"); registry != "" {
+			repo = strings.TrimSuffix(registry, "/") + "/" + path
+		}
+	case "oci":
+		// repository_url is the full repository; the name alone is only a
+		// fallback.
+		repo = qualifiers.Get("repository_url")
+		if repo == "" {
+			repo = path
+		}
+	default:
+		return "", false
+	}
+	return withVersion(repo, version, qualifiers.Get("tag")), true
+}
+
+// withVersion appends version to repo as a digest when it is one, or as a
+// tag, falling back to tag when there is no version.
+func withVersion(repo, version, tag string) string {
+	switch {
+	case strings.Contains(version, ":"):
+		return repo + "@" + version
+	case version != "":
+		return repo + ":" + version
+	case tag != "":
+		return repo + ":" + tag
+	}
+	return repo
+}
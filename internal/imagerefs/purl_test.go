@@ -0,0 +1,36 @@
+package imagerefs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromPURL(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		purl   string
+		want   string
+		wantOK bool
+	}{
+		{"pkg:docker/nginx@1.27", "nginx:1.27", true},
+		{"pkg:docker/library/nginx@1.27?arch=amd64", "library/nginx:1.27", true},
+		{"pkg:docker/org/app@1.0?repository_url=ghcr.io", "ghcr.io/org/app:1.0", true},
+		{"pkg:docker/org/app@sha256%3A0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef?repository_url=gcr.io/", "gcr.io/org/app@" + digest, true},
+		{"pkg:oci/app@sha256%3A0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef?repository_url=ghcr.io/org/app&tag=v1", "ghcr.io/org/app@" + digest, true},
+		{"pkg:oci/app?repository_url=ghcr.io/org/app&tag=v1", "ghcr.io/org/app:v1", true},
+		{"pkg:oci/app", "app", true},
+		{"pkg:npm/lodash@4.17.21", "", false},
+		{"pkg:docker", "", false},
+		{"nginx:latest", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			got, ok := FromPURL(tt.purl)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
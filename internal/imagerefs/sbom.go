@@ -0,0 +1,141 @@
+package imagerefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// cycloneDXBOM is the part of a CycloneDX JSON BOM that lists components.
+type cycloneDXBOM struct {
+	BOMFormat string `json:"bomFormat"`
+	Metadata  struct {
+		Component *cycloneDXComponent `json:"component"`
+	} `json:"metadata"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string               `json:"type"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// spdxDocument is the part of an SPDX JSON document that lists packages.
+type spdxDocument struct {
+	SPDXVersion string        `json:"spdxVersion"`
+	Packages    []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	Name                  string `json:"name"`
+	VersionInfo           string `json:"versionInfo"`
+	PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+	ExternalRefs          []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+// FromSBOM returns the container images of a CycloneDX or SPDX JSON SBOM, in
+// order of appearance and without duplicates. Components and packages are
+// images when they have a docker or oci package URL, or when CycloneDX types
+// them as container or SPDX gives them the CONTAINER purpose, in which case
+// the image is their name and version.
+func FromSBOM(data []byte) ([]string, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("error parsing SBOM: %w", err)
+	}
+
+	var refs refList
+	switch {
+	case probe.BOMFormat == "CycloneDX":
+		var bom cycloneDXBOM
+		if err := json.Unmarshal(data, &bom); err != nil {
+			return nil, fmt.Errorf("error parsing CycloneDX SBOM: %w", err)
+		}
+		if bom.Metadata.Component != nil {
+			addCycloneDXComponents(&refs, []cycloneDXComponent{*bom.Metadata.Component})
+		}
+		addCycloneDXComponents(&refs, bom.Components)
+	case probe.SPDXVersion != "":
+		var doc spdxDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error parsing SPDX SBOM: %w", err)
+		}
+		for _, p := range doc.Packages {
+			refs.add(spdxPackageRef(p))
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized SBOM format: expected CycloneDX JSON (bomFormat) or SPDX JSON (spdxVersion)")
+	}
+	return refs.list, nil
+}
+
+// addCycloneDXComponents adds the images of components and of their nested
+// components.
+func addCycloneDXComponents(refs *refList, components []cycloneDXComponent) {
+	for _, c := range components {
+		if ref, ok := FromPURL(c.PURL); ok {
+			refs.add(ref)
+		} else if c.Type == "container" {
+			refs.add(nameVersionRef(c.Name, c.Version))
+		}
+		addCycloneDXComponents(refs, c.Components)
+	}
+}
+
+func spdxPackageRef(p spdxPackage) string {
+	for _, r := range p.ExternalRefs {
+		if r.ReferenceType != "purl" {
+			continue
+		}
+		if ref, ok := FromPURL(r.ReferenceLocator); ok {
+			return ref
+		}
+	}
+	if p.PrimaryPackagePurpose == "CONTAINER" {
+		return nameVersionRef(p.Name, p.VersionInfo)
+	}
+	return ""
+}
+
+// nameVersionRef returns the image of a component named name at version. SBOM
+// generators often name an image component by the reference it was scanned
+// as, tag included, with its digest as the version.
+func nameVersionRef(name, version string) string {
+	if name == "" || strings.Contains(name, "@") {
+		return name
+	}
+	if strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		if strings.Contains(version, ":") {
+			return name + "@" + version
+		}
+		return name
+	}
+	return withVersion(name, version, "")
+}
+
+// refList collects image references in order, dropping empty ones and
+// duplicates.
+type refList struct {
+	list []string
+	seen map[string]bool
+}
+
+func (l *refList) add(ref string) {
+	if ref == "" || l.seen[ref] {
+		return
+	}
+	if l.seen == nil {
+		l.seen = make(map[string]bool)
+	}
+	l.seen[ref] = true
+	l.list = append(l.list, ref)
+}
@@ -0,0 +1,82 @@
+package imagerefs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSBOM_CycloneDX(t *testing.T) {
+	data := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"metadata": {"component": {"type": "container", "name": "ghcr.io/org/app:1.0", "version": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}},
+		"components": [
+			{"type": "library", "name": "openssl", "version": "3.0.13", "purl": "pkg:deb/debian/openssl@3.0.13"},
+			{"type": "container", "name": "redis", "version": "7.2"},
+			{"type": "application", "name": "stack", "components": [
+				{"type": "container", "name": "web", "purl": "pkg:docker/org/web@2.1?repository_url=quay.io"},
+				{"type": "container", "name": "redis", "version": "7.2"}
+			]}
+		]
+	}`
+
+	refs, err := FromSBOM([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ghcr.io/org/app:1.0@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"redis:7.2",
+		"quay.io/org/web:2.1",
+	}, refs)
+}
+
+func TestFromSBOM_SPDX(t *testing.T) {
+	data := `{
+		"spdxVersion": "SPDX-2.3",
+		"packages": [
+			{"name": "nginx", "versionInfo": "1.27", "primaryPackagePurpose": "CONTAINER"},
+			{"name": "app", "externalRefs": [
+				{"referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:org:app:1.0"},
+				{"referenceType": "purl", "referenceLocator": "pkg:oci/app?repository_url=ghcr.io/org/app&tag=1.0"}
+			]},
+			{"name": "zlib", "versionInfo": "1.3", "externalRefs": [
+				{"referenceType": "purl", "referenceLocator": "pkg:apk/alpine/zlib@1.3"}
+			]}
+		]
+	}`
+
+	refs, err := FromSBOM([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nginx:1.27", "ghcr.io/org/app:1.0"}, refs)
+}
+
+func TestFromSBOM_Errors(t *testing.T) {
+	_, err := FromSBOM([]byte("not json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing SBOM")
+
+	_, err = FromSBOM([]byte(`{"components": []}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unrecognized SBOM format")
+}
+
+func TestNameVersionRef(t *testing.T) {
+	tests := []struct {
+		name, version, want string
+	}{
+		{"nginx", "1.27", "nginx:1.27"},
+		{"nginx", "", "nginx"},
+		{"nginx", "sha256:abc", "nginx@sha256:abc"},
+		{"nginx:latest", "latest", "nginx:latest"},
+		{"localhost:5000/app", "1.0", "localhost:5000/app:1.0"},
+		{"nginx@sha256:abc", "1.0", "nginx@sha256:abc"},
+		{"", "1.0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, nameVersionRef(tt.name, tt.version))
+		})
+	}
+}
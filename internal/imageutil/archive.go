@@ -3,16 +3,27 @@ package imageutil
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 const (
 	// maxDecompressedSize limits extraction to prevent decompression bombs (5GB)
 	maxDecompressedSize = 5 * 1024 * 1024 * 1024
+
+	// archiveProgressThreshold is the size from which the progress of an
+	// extraction is logged at info level; smaller archives log at debug only.
+	archiveProgressThreshold = 256 * 1024 * 1024 // 256MB
 )
 
 // extractOCIArchive extracts an OCI tarball to a temporary directory.
@@ -25,7 +36,7 @@ func extractOCIArchive(tarballPath string) (tempDir string, err error) {
 	}
 	defer func() { _ = file.Close() }()
 
-	tempDir, err = os.MkdirTemp("", "oci-archive-*")
+	tempDir, err = os.MkdirTemp("", "check-image-oci-archive-*")
 	if err != nil {
 		return "", fmt.Errorf("error creating temp directory: %w", err)
 	}
@@ -38,7 +49,13 @@ func extractOCIArchive(tarballPath string) (tempDir string, err error) {
 		}
 	}()
 
-	tarReader, closer, err := newTarReader(file, tarballPath)
+	var size int64
+	if info, statErr := file.Stat(); statErr == nil {
+		size = info.Size()
+	}
+	progress := &progressReader{r: file, path: tarballPath, total: size, start: time.Now()}
+
+	tarReader, closer, err := newTarReader(progress, tarballPath)
 	if err != nil {
 		// Use bare return so the deferred cleanup sees the real tempDir value.
 		// Writing return "", err here would zero out the named tempDir before the
@@ -47,8 +64,39 @@ func extractOCIArchive(tarballPath string) (tempDir string, err error) {
 	}
 	defer closer()
 
-	err = extractEntries(tarReader, tempDir)
-	return tempDir, err
+	log.WithFields(log.Fields{"archive": tarballPath, "size": size}).Debug("Extracting OCI archive")
+	if err = extractEntries(tarReader, tempDir); err != nil {
+		return
+	}
+	log.WithFields(log.Fields{"archive": tarballPath, "duration": time.Since(progress.start).Round(time.Millisecond)}).Debug("OCI archive extracted")
+	return tempDir, nil
+}
+
+// progressReader logs how much of an archive has been read, every 10% of
+// archives of at least archiveProgressThreshold. Reads of compressed archives
+// are counted before decompression, so the percentage tracks the file.
+type progressReader struct {
+	r      io.Reader
+	path   string
+	total  int64
+	read   int64
+	logged int64
+	start  time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.total >= archiveProgressThreshold {
+		if percent := p.read * 100 / p.total; percent >= p.logged+10 {
+			p.logged = percent - percent%10
+			log.WithFields(log.Fields{
+				"archive": p.path,
+				"elapsed": time.Since(p.start).Round(time.Second),
+			}).Infof("Extracting OCI archive: %d%%", p.logged)
+		}
+	}
+	return n, err
 }
 
 // newTarReader wraps r in a tar.Reader, adding a gzip layer when the path
@@ -138,6 +186,17 @@ func extractRegularFile(tarReader *tar.Reader, target string, header *tar.Header
 		return fmt.Errorf("error creating file %s: %w", target, err)
 	}
 
+	// Blobs are verified against the digest in their name as they are
+	// written, so a corrupt archive fails here rather than as a confusing
+	// error, or a wrong result, in a later check.
+	var digester hash.Hash
+	dst := io.Writer(outFile)
+	wantHex, isBlob := blobHex(header.Name)
+	if isBlob {
+		digester = sha256.New()
+		dst = io.MultiWriter(outFile, digester)
+	}
+
 	// Copy file contents, capping at header.Size+1 bytes to catch lying headers.
 	// Using header.Size+1 rather than header.Size is deliberate: reading exactly
 	// header.Size+1 bytes from an oversized stream produces written=header.Size+1,
@@ -146,7 +205,7 @@ func extractRegularFile(tarReader *tar.Reader, target string, header *tar.Header
 	// write unbounded data to disk before the mismatch was detected.
 	// #nosec G110 -- per-file size limit enforced via LimitReader
 	limitedReader := io.LimitReader(tarReader, header.Size+1)
-	written, err := io.Copy(outFile, limitedReader)
+	written, err := io.Copy(dst, limitedReader)
 	if err != nil {
 		_ = outFile.Close() // Best effort cleanup
 		return fmt.Errorf("error writing file %s: %w", target, err)
@@ -158,9 +217,27 @@ func extractRegularFile(tarReader *tar.Reader, target string, header *tar.Header
 		return fmt.Errorf("size mismatch for %s: expected %d, got %d", target, header.Size, written)
 	}
 
+	if isBlob {
+		if got := hex.EncodeToString(digester.Sum(nil)); got != wantHex {
+			_ = outFile.Close() // Best effort cleanup
+			return fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", header.Name, wantHex, got)
+		}
+	}
+
 	if err := outFile.Close(); err != nil {
 		return fmt.Errorf("error closing file %s: %w", target, err)
 	}
 
 	return nil
 }
+
+// blobHex returns the hex digest named by an entry of an OCI layout at
+// blobs/sha256/<hex>. Other entries, including blobs of other algorithms,
+// are not verified.
+func blobHex(name string) (string, bool) {
+	hexDigest, ok := strings.CutPrefix(path.Clean("/"+name), "/blobs/sha256/")
+	if !ok || len(hexDigest) != sha256.Size*2 || strings.Trim(hexDigest, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return hexDigest, true
+}
@@ -0,0 +1,127 @@
+package imageutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// sharedArchives tracks the OCI archives extracted by GetOCIArchiveImage, so
+// that the images loaded from the same archive share a single extraction.
+var sharedArchives = struct {
+	sync.Mutex
+	byKey map[archiveKey]*extractedArchive
+	// retained counts the RetainOCIArchives calls not yet released; while it
+	// is positive, unreferenced extractions stay on disk for reuse.
+	retained int
+}{byKey: map[archiveKey]*extractedArchive{}}
+
+// archiveKey identifies an archive by path and by the size and modification
+// time of the file, so an archive rewritten in place is extracted again.
+type archiveKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// extractedArchive is an archive extracted to dir. done is closed once the
+// extraction finishes, with err set if it failed.
+type extractedArchive struct {
+	done chan struct{}
+	dir  string
+	err  error
+	refs int
+}
+
+// RetainOCIArchives keeps extracted OCI archives on disk until the returned
+// function is called, instead of removing each one when the last image loaded
+// from it is cleaned up. Commands that load the same archive several times,
+// such as the checks of all, extract it once.
+func RetainOCIArchives() func() {
+	sharedArchives.Lock()
+	defer sharedArchives.Unlock()
+	sharedArchives.retained++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sharedArchives.Lock()
+			defer sharedArchives.Unlock()
+			sharedArchives.retained--
+			if sharedArchives.retained > 0 {
+				return
+			}
+			for key, a := range sharedArchives.byKey {
+				if a.refs == 0 {
+					delete(sharedArchives.byKey, key)
+					_ = os.RemoveAll(a.dir)
+				}
+			}
+		})
+	}
+}
+
+// acquireOCIArchive returns the directory of an extraction of the archive at
+// tarballPath, extracting it unless an extraction of the same file is already
+// on disk or in progress. The returned function releases the directory.
+func acquireOCIArchive(tarballPath string) (string, func(), error) {
+	abs, err := filepath.Abs(tarballPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("error resolving tarball path: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", nil, fmt.Errorf("error opening tarball: %w", err)
+	}
+	key := archiveKey{path: abs, size: info.Size(), modTime: info.ModTime()}
+
+	sharedArchives.Lock()
+	a, ok := sharedArchives.byKey[key]
+	if !ok {
+		a = &extractedArchive{done: make(chan struct{})}
+		sharedArchives.byKey[key] = a
+	}
+	a.refs++
+	sharedArchives.Unlock()
+
+	if ok {
+		<-a.done
+		if a.err == nil {
+			log.WithField("archive", tarballPath).Debug("Reusing extracted OCI archive")
+		}
+	} else {
+		a.dir, a.err = extractOCIArchive(tarballPath)
+		if a.err != nil {
+			// Later loads try again rather than reuse the failure.
+			sharedArchives.Lock()
+			delete(sharedArchives.byKey, key)
+			sharedArchives.Unlock()
+		}
+		close(a.done)
+	}
+
+	if a.err != nil {
+		return "", nil, a.err
+	}
+	var once sync.Once
+	return a.dir, func() { once.Do(func() { releaseOCIArchive(key, a) }) }, nil
+}
+
+// releaseOCIArchive drops a reference to an extraction, removing it when it
+// was the last one and no RetainOCIArchives call is active.
+func releaseOCIArchive(key archiveKey, a *extractedArchive) {
+	sharedArchives.Lock()
+	defer sharedArchives.Unlock()
+	a.refs--
+	if a.refs > 0 || sharedArchives.retained > 0 {
+		return
+	}
+	if sharedArchives.byKey[key] == a {
+		delete(sharedArchives.byKey, key)
+	}
+	_ = os.RemoveAll(a.dir)
+}
@@ -0,0 +1,95 @@
+package imageutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireOCIArchive_SharesExtraction(t *testing.T) {
+	tmpDir := t.TempDir()
+	layoutPath := filepath.Join(tmpDir, "layout")
+	createOCILayoutWithTag(t, layoutPath, "latest")
+	tarPath := filepath.Join(tmpDir, "image.tar")
+	createTarballFromOCILayout(t, layoutPath, tarPath)
+
+	dir1, release1, err := acquireOCIArchive(tarPath)
+	require.NoError(t, err)
+	dir2, release2, err := acquireOCIArchive(tarPath)
+	require.NoError(t, err)
+	assert.Equal(t, dir1, dir2, "loads of the same archive share the extraction")
+
+	release1()
+	release1() // releasing twice drops a single reference
+	assert.DirExists(t, dir1, "the extraction stays while an image uses it")
+
+	release2()
+	assert.NoDirExists(t, dir1)
+}
+
+func TestAcquireOCIArchive_ExtractsRewrittenArchiveAgain(t *testing.T) {
+	tmpDir := t.TempDir()
+	layoutPath := filepath.Join(tmpDir, "layout")
+	createOCILayoutWithTag(t, layoutPath, "latest")
+	tarPath := filepath.Join(tmpDir, "image.tar")
+	createTarballFromOCILayout(t, layoutPath, tarPath)
+
+	dir1, release1, err := acquireOCIArchive(tarPath)
+	require.NoError(t, err)
+	defer release1()
+
+	require.NoError(t, os.RemoveAll(layoutPath))
+	createOCILayoutWithTag(t, layoutPath, "latest")
+	createTarballFromOCILayout(t, layoutPath, tarPath)
+
+	dir2, release2, err := acquireOCIArchive(tarPath)
+	require.NoError(t, err)
+	defer release2()
+	assert.NotEqual(t, dir1, dir2)
+}
+
+func TestRetainOCIArchives(t *testing.T) {
+	tmpDir := t.TempDir()
+	layoutPath := filepath.Join(tmpDir, "layout")
+	_, digest := createOCILayoutWithTag(t, layoutPath, "latest")
+	tarPath := filepath.Join(tmpDir, "image.tar")
+	createTarballFromOCILayout(t, layoutPath, tarPath)
+
+	release := RetainOCIArchives()
+
+	_, cleanup1, err := GetOCIArchiveImage(tarPath, "latest")
+	require.NoError(t, err)
+	dir, releaseDir, err := acquireOCIArchive(tarPath)
+	require.NoError(t, err)
+	releaseDir()
+	cleanup1()
+	assert.DirExists(t, dir, "retained extractions outlive their images")
+
+	img, cleanup2, err := GetOCIArchiveImage(tarPath, digest.String())
+	require.NoError(t, err)
+	got, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest, got)
+
+	release()
+	assert.DirExists(t, dir, "an image still uses the extraction")
+	cleanup2()
+	assert.NoDirExists(t, dir)
+}
+
+func TestAcquireOCIArchive_FailureIsNotShared(t *testing.T) {
+	tarPath := filepath.Join(t.TempDir(), "image.tar")
+	require.NoError(t, os.WriteFile(tarPath, []byte("not a valid tar file"), 0600))
+
+	_, _, err := acquireOCIArchive(tarPath)
+	require.Error(t, err)
+
+	sharedArchives.Lock()
+	defer sharedArchives.Unlock()
+	for key := range sharedArchives.byKey {
+		assert.NotEqual(t, tarPath, key.path)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -373,3 +374,43 @@ func TestExtractOCIArchive_SkipsSymlinks(t *testing.T) {
 	require.Error(t, err)
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestExtractRegularFile_BlobChecksumMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte("tampered blob")
+	name := "blobs/sha256/" + strings.Repeat("ab", 32)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	err = extractEntries(tar.NewReader(&buf), tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for "+name)
+}
+
+func TestBlobHex(t *testing.T) {
+	digest := strings.Repeat("0f", 32)
+	tests := []struct {
+		name   string
+		want   string
+		isBlob bool
+	}{
+		{"blobs/sha256/" + digest, digest, true},
+		{"./blobs/sha256/" + digest, digest, true},
+		{"blobs/sha512/" + digest, "", false},
+		{"blobs/sha256/abc", "", false},
+		{"blobs/sha256/" + strings.ToUpper(digest), "", false},
+		{"index.json", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := blobHex(tt.name)
+			assert.Equal(t, tt.isBlob, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// maxArtifactSize caps the amount of data read from an OCI artifact layer,
+// matching the limit applied to config files and stdin.
+const maxArtifactSize = 10 * 1024 * 1024
+
+// GetArtifactData retrieves a single-file OCI artifact (for example one pushed
+// with `oras push`) from a registry and returns the raw content of its first
+// layer. The layer blob is returned as stored, without decompression.
+func GetArtifactData(ctx context.Context, ref string) ([]byte, error) {
+	img, err := GetRemoteImage(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error reading artifact layers: %w", err)
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("artifact %s has no layers", ref)
+	}
+
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("error opening artifact layer: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxArtifactSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading artifact layer: %w", err)
+	}
+	if len(data) > maxArtifactSize {
+		return nil, fmt.Errorf("artifact layer exceeds maximum size of %d bytes", maxArtifactSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,60 @@
+package imageutil
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pushTestArtifact(t *testing.T, host, repo string, content []byte) string {
+	t.Helper()
+	img := empty.Image
+	if content != nil {
+		var err error
+		img, err = mutate.AppendLayers(img, static.NewLayer(content, types.MediaType("application/yaml")))
+		require.NoError(t, err)
+	}
+	ref, err := name.ParseReference(host + "/" + repo)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+	return ref.String()
+}
+
+func TestGetArtifactData(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	t.Run("Returns first layer content", func(t *testing.T) {
+		ref := pushTestArtifact(t, host, "org/policy:v1", []byte("checks:\n  age: {}\n"))
+		data, err := GetArtifactData(context.Background(), ref)
+		require.NoError(t, err)
+		assert.Equal(t, "checks:\n  age: {}\n", string(data))
+	})
+
+	t.Run("Artifact without layers", func(t *testing.T) {
+		ref := pushTestArtifact(t, host, "org/empty:v1", nil)
+		_, err := GetArtifactData(context.Background(), ref)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no layers")
+	})
+
+	t.Run("Invalid reference", func(t *testing.T) {
+		_, err := GetArtifactData(context.Background(), "INVALID::ref")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error parsing the reference")
+	})
+}
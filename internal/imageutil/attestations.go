@@ -0,0 +1,252 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	log "github.com/sirupsen/logrus"
+)
+
+// Media types of attestation payloads.
+const (
+	InTotoMediaType         = "application/vnd.in-toto+json"
+	DSSEMediaType           = "application/vnd.dsse.envelope.v1+json"
+	SigstoreBundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+)
+
+// BuildKit stores attestations in the image index as manifests annotated
+// with this reference type.
+const (
+	buildkitReferenceTypeAnnotation = "vnd.docker.reference.type"
+	buildkitAttestationManifest     = "attestation-manifest"
+)
+
+// maxAttestationSize bounds the size of a single attestation payload.
+const maxAttestationSize = 10 * 1024 * 1024
+
+// Attestation sources.
+const (
+	AttestationSourceIndex    = "index"
+	AttestationSourceReferrer = "referrer"
+	AttestationSourceCosign   = "cosign"
+)
+
+var attestationMediaTypes = []string{InTotoMediaType, DSSEMediaType, SigstoreBundleMediaType}
+
+// Attestation is an attestation payload found for an image: an in-toto
+// statement, a DSSE envelope, or a Sigstore bundle.
+type Attestation struct {
+	// Source tells where the attestation was found: "index" (a BuildKit
+	// attestation manifest), "referrer" (the OCI referrers API), or "cosign"
+	// (the sha256-<hex>.att tag).
+	Source string
+	// Manifest is the digest of the manifest holding the attestation.
+	Manifest string
+	Data     []byte
+}
+
+// ImageAttestations holds the attestations found for an image, and the
+// digests of the image (its index and platform manifests) they may refer to.
+type ImageAttestations struct {
+	Digests      []string
+	Attestations []Attestation
+}
+
+// GetAttestations collects the attestations of imageName. Registry images are
+// searched in their index (BuildKit), the OCI referrers API, and the cosign
+// attestation tag; the last two are best effort, since not every registry
+// supports them. OCI layouts are searched in their index only. Other
+// transports do not keep attestations.
+func GetAttestations(ctx context.Context, imageName string) (*ImageAttestations, error) {
+	ref, err := ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+	switch ref.Transport {
+	case TransportDaemonRegistry:
+		return remoteAttestations(ctx, ref.Path)
+	case TransportOCI:
+		reference := ref.Digest
+		if reference == "" {
+			reference = ref.Tag
+		}
+		return layoutAttestations(ref.Path, reference)
+	default:
+		return nil, fmt.Errorf("attestations are not available for %s images, use a registry reference or an OCI layout", ref.Transport)
+	}
+}
+
+func remoteAttestations(ctx context.Context, imageName string) (*ImageAttestations, error) {
+	ref, err := parseRemoteReference(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the reference: %w", err)
+	}
+	desc, err := remoteGet(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the remote image: %w", err)
+	}
+
+	a := &ImageAttestations{Digests: []string{desc.Digest.String()}}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the image index: %w", err)
+		}
+		if err := a.addIndex(idx); err != nil {
+			return nil, err
+		}
+	}
+
+	subject := ref.Context().Digest(desc.Digest.String())
+	if err := a.addReferrers(ctx, subject); err != nil {
+		log.WithField("error", err).Debug("Unable to list attestation referrers")
+	}
+	if err := a.addCosign(ctx, subject); err != nil {
+		log.WithField("error", err).Debug("No cosign attestations found")
+	}
+	return a, nil
+}
+
+func layoutAttestations(layoutPath, reference string) (*ImageAttestations, error) {
+	if reference == "" {
+		return nil, fmt.Errorf("oci transport requires tag or digest")
+	}
+	path, err := layout.FromPath(layoutPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OCI layout: %w", err)
+	}
+	digest := reference
+	if _, err := cr.NewHash(reference); err != nil {
+		if digest, err = resolveTagInLayout(path, reference); err != nil {
+			return nil, fmt.Errorf("error resolving tag: %w", err)
+		}
+	}
+	hash, err := cr.NewHash(digest)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing resolved digest: %w", err)
+	}
+	root, err := path.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("error reading index: %w", err)
+	}
+
+	a := &ImageAttestations{Digests: []string{hash.String()}}
+	if idx, err := root.ImageIndex(hash); err == nil {
+		if mt, err := idx.MediaType(); err == nil && mt.IsIndex() {
+			if err := a.addIndex(idx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return a, nil
+}
+
+// addIndex records the platform manifests of idx as image digests and reads
+// the payloads of its BuildKit attestation manifests.
+func (a *ImageAttestations) addIndex(idx cr.ImageIndex) error {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("error reading the image index: %w", err)
+	}
+	for _, desc := range manifest.Manifests {
+		if !desc.MediaType.IsImage() {
+			continue
+		}
+		if desc.Annotations[buildkitReferenceTypeAnnotation] != buildkitAttestationManifest {
+			a.Digests = append(a.Digests, desc.Digest.String())
+			continue
+		}
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return fmt.Errorf("error reading attestation manifest %s: %w", desc.Digest, err)
+		}
+		if err := a.addImage(img, AttestationSourceIndex, desc.Digest.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addReferrers reads the attestation referrers of subject.
+func (a *ImageAttestations) addReferrers(ctx context.Context, subject name.Digest) error {
+	idx, err := remoteReferrers(ctx, subject)
+	if err != nil {
+		return err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range manifest.Manifests {
+		if !slices.Contains(attestationMediaTypes, desc.ArtifactType) {
+			continue
+		}
+		img, err := remoteImage(ctx, subject.Context().Digest(desc.Digest.String()))
+		if err != nil {
+			return fmt.Errorf("error retrieving referrer %s: %w", desc.Digest, err)
+		}
+		if err := a.addImage(img, AttestationSourceReferrer, desc.Digest.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addCosign reads the attestations cosign stores under the sha256-<hex>.att
+// tag of subject.
+func (a *ImageAttestations) addCosign(ctx context.Context, subject name.Digest) error {
+	tag := subject.Context().Tag(strings.Replace(subject.DigestStr(), ":", "-", 1) + ".att")
+	img, err := remoteImage(ctx, tag)
+	if err != nil {
+		return err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	return a.addImage(img, AttestationSourceCosign, digest.String())
+}
+
+// addImage reads the attestation layers of img.
+func (a *ImageAttestations) addImage(img cr.Image, source, manifestDigest string) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("error reading attestation layers of %s: %w", manifestDigest, err)
+	}
+	for _, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil || !slices.Contains(attestationMediaTypes, string(mt)) {
+			continue
+		}
+		data, err := readAttestation(l)
+		if err != nil {
+			return fmt.Errorf("error reading attestation of %s: %w", manifestDigest, err)
+		}
+		a.Attestations = append(a.Attestations, Attestation{Source: source, Manifest: manifestDigest, Data: data})
+	}
+	return nil
+}
+
+// readAttestation reads the raw blob of an attestation layer. Attestation
+// media types are not compressed, so the stored blob is the payload.
+func readAttestation(l cr.Layer) ([]byte, error) {
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxAttestationSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAttestationSize {
+		return nil, fmt.Errorf("attestation exceeds maximum size of %d bytes", maxAttestationSize)
+	}
+	return data, nil
+}
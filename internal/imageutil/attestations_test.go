@@ -0,0 +1,124 @@
+package imageutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStatement = `{"predicateType": "https://slsa.dev/provenance/v1", "predicate": {}}`
+
+// attestationImage returns an image with a single attestation layer.
+func attestationImage(t *testing.T, mediaType string, data string) cr.Image {
+	t.Helper()
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte(data), types.MediaType(mediaType)))
+	require.NoError(t, err)
+	return img
+}
+
+// buildkitIndex returns an index with one platform image and a BuildKit
+// attestation manifest for it.
+func buildkitIndex(t *testing.T) (cr.ImageIndex, cr.Hash) {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	imgDigest, err := img.Digest()
+	require.NoError(t, err)
+
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: img, Descriptor: cr.Descriptor{
+			Platform: &cr.Platform{OS: "linux", Architecture: "amd64"},
+		}},
+		mutate.IndexAddendum{Add: attestationImage(t, InTotoMediaType, testStatement), Descriptor: cr.Descriptor{
+			Platform: &cr.Platform{OS: "unknown", Architecture: "unknown"},
+			Annotations: map[string]string{
+				buildkitReferenceTypeAnnotation: buildkitAttestationManifest,
+				"vnd.docker.reference.digest":   imgDigest.String(),
+			},
+		}},
+	)
+	return idx, imgDigest
+}
+
+func TestGetAttestations_OCILayoutIndex(t *testing.T) {
+	idx, imgDigest := buildkitIndex(t)
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "v1"})))
+	idxDigest, err := idx.Digest()
+	require.NoError(t, err)
+
+	atts, err := GetAttestations(context.Background(), "oci:"+dir+":v1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{idxDigest.String(), imgDigest.String()}, atts.Digests)
+	require.Len(t, atts.Attestations, 1)
+	assert.Equal(t, AttestationSourceIndex, atts.Attestations[0].Source)
+	assert.JSONEq(t, testStatement, string(atts.Attestations[0].Data))
+}
+
+func TestGetAttestations_OCILayoutSingleImage(t *testing.T) {
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	atts, err := GetAttestations(context.Background(), "oci:"+writeTestLayout(t, img)+":v1")
+	require.NoError(t, err)
+	assert.Len(t, atts.Digests, 1)
+	assert.Empty(t, atts.Attestations)
+}
+
+func TestGetAttestations_Registry(t *testing.T) {
+	host := newTestRegistry(t)
+	idx, _ := buildkitIndex(t)
+	ref, err := name.ParseReference(host + "/prod/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(ref, idx))
+	idxDigest, err := idx.Digest()
+	require.NoError(t, err)
+
+	// cosign stores attestations under the sha256-<hex>.att tag
+	cosignTag, err := name.ParseReference(host + "/prod/app:sha256-" + idxDigest.Hex + ".att")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(cosignTag, attestationImage(t, DSSEMediaType, `{"payload": ""}`)))
+
+	// and the OCI referrers API
+	_, err = AttachArtifact(context.Background(), ref.String(), cr.Descriptor{
+		MediaType: types.OCIImageIndex,
+		Digest:    idxDigest,
+		Size:      mustSize(t, idx),
+	}, InTotoMediaType, InTotoMediaType, []byte(testStatement), nil)
+	require.NoError(t, err)
+
+	atts, err := GetAttestations(context.Background(), ref.String())
+	require.NoError(t, err)
+	assert.Len(t, atts.Digests, 2)
+	sources := make([]string, 0, len(atts.Attestations))
+	for _, a := range atts.Attestations {
+		sources = append(sources, a.Source)
+	}
+	assert.ElementsMatch(t, []string{AttestationSourceIndex, AttestationSourceReferrer, AttestationSourceCosign}, sources)
+}
+
+func TestGetAttestations_UnsupportedTransport(t *testing.T) {
+	_, err := GetAttestations(context.Background(), "oci-archive:/tmp/app.tar:v1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "attestations are not available")
+}
+
+func mustSize(t *testing.T, idx cr.ImageIndex) int64 {
+	t.Helper()
+	size, err := idx.Size()
+	require.NoError(t, err)
+	return size
+}
@@ -0,0 +1,47 @@
+package imageutil
+
+import (
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Annotations declaring the base image an image was built from. They are set
+// on the manifest by BuildKit and may be copied to the config labels.
+const (
+	baseNameAnnotation   = "org.opencontainers.image.base.name"
+	baseDigestAnnotation = "org.opencontainers.image.base.digest"
+)
+
+// DeclaredBaseImage returns a reference to the base image declared by the
+// image, read from the manifest annotations and then from the config labels.
+// The reference is pinned to the declared digest when there is one. It
+// returns an empty string when no base image is declared.
+func DeclaredBaseImage(image cr.Image, config *cr.ConfigFile) string {
+	sources := make([]map[string]string, 0, 2)
+	if m, err := image.Manifest(); err == nil && m != nil {
+		sources = append(sources, m.Annotations)
+	}
+	if config != nil {
+		sources = append(sources, config.Config.Labels)
+	}
+
+	for _, s := range sources {
+		name := strings.TrimSpace(s[baseNameAnnotation])
+		if name == "" {
+			continue
+		}
+		digest := strings.TrimSpace(s[baseDigestAnnotation])
+		if digest == "" {
+			return name
+		}
+		// Replace a tag, or an existing digest, with the declared digest.
+		if i := strings.Index(name, "@"); i >= 0 {
+			name = name[:i]
+		} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			name = name[:i]
+		}
+		return name + "@" + digest
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package imageutil
+
+import (
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeclaredBaseImage(t *testing.T) {
+	const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		want        string
+	}{
+		{name: "Not declared", want: ""},
+		{
+			name:        "Name only",
+			annotations: map[string]string{baseNameAnnotation: "docker.io/library/alpine:3.20"},
+			want:        "docker.io/library/alpine:3.20",
+		},
+		{
+			name:        "Tag replaced by digest",
+			annotations: map[string]string{baseNameAnnotation: "docker.io/library/alpine:3.20", baseDigestAnnotation: digest},
+			want:        "docker.io/library/alpine@" + digest,
+		},
+		{
+			name:        "Registry port kept",
+			annotations: map[string]string{baseNameAnnotation: "localhost:5000/base", baseDigestAnnotation: digest},
+			want:        "localhost:5000/base@" + digest,
+		},
+		{
+			name:   "Config labels",
+			labels: map[string]string{baseNameAnnotation: "ghcr.io/org/base:1", baseDigestAnnotation: digest},
+			want:   "ghcr.io/org/base@" + digest,
+		},
+		{
+			name:        "Manifest annotations take precedence",
+			annotations: map[string]string{baseNameAnnotation: "ghcr.io/org/manifest:1"},
+			labels:      map[string]string{baseNameAnnotation: "ghcr.io/org/label:1"},
+			want:        "ghcr.io/org/manifest:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := mutate.Annotations(empty.Image, tt.annotations).(cr.Image)
+			cfg := &cr.ConfigFile{Config: cr.Config{Labels: tt.labels}}
+			img, err := mutate.ConfigFile(img, cfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, DeclaredBaseImage(img, cfg))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package imageutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheDrainLimit is the largest unread remainder that is still downloaded
+// when a cached layer reader is closed early. Checks that stop at the end of
+// the tar stream leave only padding and the compression trailer unread, so
+// draining them lets the layer be committed to the cache.
+const cacheDrainLimit = 1 * 1024 * 1024 // 1MB
+
+// layerCacheDir is the directory of the on-disk layer cache. The cache is
+// disabled when it is empty.
+var layerCacheDir string
+
+// SetLayerCache enables the on-disk layer cache in dir. Compressed layers of
+// registry images are stored by digest as they are read, so later reads in
+// the same or a following invocation (validation, then copy) are served from
+// disk instead of downloading them again.
+func SetLayerCache(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	layerCacheDir = dir
+	return nil
+}
+
+// LayerCacheEnabled reports whether SetLayerCache has configured a cache.
+func LayerCacheEnabled() bool {
+	return layerCacheDir != ""
+}
+
+// ResetLayerCache disables the layer cache. Cached files are left on disk.
+func ResetLayerCache() {
+	layerCacheDir = ""
+}
+
+// withLayerCache wraps img so its layers are read through the layer cache.
+// It returns img unchanged when the cache is disabled.
+func withLayerCache(img cr.Image) cr.Image {
+	if layerCacheDir == "" {
+		return img
+	}
+	return &cachedImage{Image: img, dir: layerCacheDir}
+}
+
+type cachedImage struct {
+	cr.Image
+	dir string
+}
+
+func (i *cachedImage) Layers() ([]cr.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]cr.Layer, len(layers))
+	for idx, l := range layers {
+		if out[idx], err = newCachedLayer(l, i.dir); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (i *cachedImage) LayerByDigest(h cr.Hash) (cr.Layer, error) {
+	l, err := i.Image.LayerByDigest(h)
+	if err != nil {
+		return nil, err
+	}
+	return newCachedLayer(l, i.dir)
+}
+
+// cachedLayer serves the compressed blob from the cache when present and
+// stores it while reading otherwise. Uncompressed reads go through the
+// compressed blob, so scanning a layer also fills the cache for pushing it.
+type cachedLayer struct {
+	inner cr.Layer
+	dir   string
+}
+
+func newCachedLayer(l cr.Layer, dir string) (cr.Layer, error) {
+	return partial.CompressedToLayer(&cachedLayer{inner: l, dir: dir})
+}
+
+func (l *cachedLayer) Digest() (cr.Hash, error)            { return l.inner.Digest() }
+func (l *cachedLayer) DiffID() (cr.Hash, error)            { return l.inner.DiffID() }
+func (l *cachedLayer) Size() (int64, error)                { return l.inner.Size() }
+func (l *cachedLayer) MediaType() (types.MediaType, error) { return l.inner.MediaType() }
+
+func (l *cachedLayer) Compressed() (io.ReadCloser, error) {
+	digest, err := l.inner.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if digest.Algorithm != "sha256" {
+		return l.inner.Compressed()
+	}
+
+	path := filepath.Join(l.dir, digest.Algorithm, digest.Hex)
+	if f, err := os.Open(path); err == nil {
+		log.WithField("digest", digest.String()).Debug("Layer found in cache")
+		// The modification time records the last use, for cleanup --cache-ttl.
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return f, nil
+	}
+
+	rc, err := l.inner.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	size, err := l.inner.Size()
+	if err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+
+	w := &cacheWriter{rc: rc, path: path, want: digest.Hex, size: size, hasher: sha256.New()}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		w.tmp, err = os.CreateTemp(filepath.Dir(path), ".partial-*")
+		if err != nil {
+			log.WithField("error", err).Debug("Failed to create cache file, reading layer uncached")
+		}
+	}
+	return w, nil
+}
+
+// cacheWriter tees a layer stream into a temporary file and moves it into
+// the cache on Close once the full blob has been read and its digest checked.
+// Cache failures never fail the read itself.
+type cacheWriter struct {
+	rc     io.ReadCloser
+	tmp    *os.File
+	path   string
+	want   string
+	size   int64
+	read   int64
+	hasher hash.Hash
+	eof    bool
+}
+
+func (w *cacheWriter) Read(p []byte) (int, error) {
+	n, err := w.rc.Read(p)
+	w.record(p[:n])
+	if errors.Is(err, io.EOF) {
+		w.eof = true
+	}
+	return n, err
+}
+
+func (w *cacheWriter) record(p []byte) {
+	w.read += int64(len(p))
+	if w.tmp == nil {
+		return
+	}
+	w.hasher.Write(p)
+	if _, err := w.tmp.Write(p); err != nil {
+		log.WithField("error", err).Debug("Failed to write cache file, reading layer uncached")
+		w.discard()
+	}
+}
+
+func (w *cacheWriter) Close() error {
+	if w.tmp != nil && !w.eof && w.size-w.read <= cacheDrainLimit {
+		buf, err := io.ReadAll(io.LimitReader(w.rc, cacheDrainLimit+1))
+		w.record(buf)
+		w.eof = err == nil
+	}
+	err := w.rc.Close()
+	w.commit()
+	return err
+}
+
+func (w *cacheWriter) commit() {
+	if w.tmp == nil {
+		return
+	}
+	if !w.eof || w.read != w.size || hex.EncodeToString(w.hasher.Sum(nil)) != w.want {
+		w.discard()
+		return
+	}
+	name := w.tmp.Name()
+	if err := w.tmp.Close(); err != nil {
+		w.tmp = nil
+		_ = os.Remove(name)
+		return
+	}
+	w.tmp = nil
+	if err := os.Rename(name, w.path); err != nil {
+		_ = os.Remove(name)
+		return
+	}
+	log.WithField("digest", "sha256:"+w.want).Debug("Layer stored in cache")
+}
+
+func (w *cacheWriter) discard() {
+	if w.tmp == nil {
+		return
+	}
+	name := w.tmp.Name()
+	_ = w.tmp.Close()
+	_ = os.Remove(name)
+	w.tmp = nil
+}
@@ -0,0 +1,140 @@
+package imageutil
+
+import (
+	"archive/tar"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enableTestLayerCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, SetLayerCache(dir))
+	t.Cleanup(ResetLayerCache)
+	return dir
+}
+
+func cachedBlobPath(t *testing.T, dir string, l cr.Layer) string {
+	t.Helper()
+	digest, err := l.Digest()
+	require.NoError(t, err)
+	return filepath.Join(dir, digest.Algorithm, digest.Hex)
+}
+
+func TestWithLayerCache_Disabled(t *testing.T) {
+	ResetLayerCache()
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	assert.Same(t, img, withLayerCache(img))
+	assert.False(t, LayerCacheEnabled())
+}
+
+func TestLayerCache_ServesLayersAfterRegistryIsGone(t *testing.T) {
+	dir := enableTestLayerCache(t)
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	ref := strings.TrimPrefix(server.URL, "http://") + "/org/app:1.0"
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+	src, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(parsed, src))
+
+	img, err := GetRemoteImage(t.Context(), ref)
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		require.NoError(t, err)
+		_, err = io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.FileExists(t, cachedBlobPath(t, dir, l))
+	}
+
+	server.Close()
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, l := range layers {
+		require.NoError(t, os.Chtimes(cachedBlobPath(t, dir, l), old, old))
+	}
+
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		require.NoError(t, err, "layer must be served from the cache")
+		info, err := os.Stat(cachedBlobPath(t, dir, l))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().After(old), "a cache hit records the last use")
+		_, err = io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+}
+
+func TestLayerCache_TarScanFillsCache(t *testing.T) {
+	dir := enableTestLayerCache(t)
+
+	src, err := random.Image(2048, 1)
+	require.NoError(t, err)
+	layers, err := withLayerCache(src).Layers()
+	require.NoError(t, err)
+
+	rc, err := layers[0].Uncompressed()
+	require.NoError(t, err)
+	tr := tar.NewReader(rc)
+	for {
+		if _, err := tr.Next(); err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.NoError(t, rc.Close())
+
+	assert.FileExists(t, cachedBlobPath(t, dir, layers[0]))
+}
+
+func TestLayerCache_PartialReadIsNotCached(t *testing.T) {
+	dir := enableTestLayerCache(t)
+
+	src, err := random.Image(4*cacheDrainLimit, 1)
+	require.NoError(t, err)
+	layers, err := withLayerCache(src).Layers()
+	require.NoError(t, err)
+
+	rc, err := layers[0].Compressed()
+	require.NoError(t, err)
+	_, err = io.ReadFull(rc, make([]byte, 16))
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	assert.NoFileExists(t, cachedBlobPath(t, dir, layers[0]))
+	entries, err := os.ReadDir(filepath.Join(dir, "sha256"))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "temporary files must be removed")
+}
+
+func TestSetLayerCache_InvalidDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	t.Cleanup(ResetLayerCache)
+
+	err := SetLayerCache(filepath.Join(file, "cache"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create cache directory")
+	assert.False(t, LayerCacheEnabled())
+}
@@ -0,0 +1,313 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// ParseDestination parses a push destination. Only registry references are
+// accepted; local transports (oci:, oci-archive:, docker-archive:) cannot be
+// pushed to.
+func ParseDestination(dst string) (name.Reference, error) {
+	ref, err := ParseReference(dst)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Transport != TransportDaemonRegistry {
+		return nil, fmt.Errorf("destination must be a registry reference, got %s transport", ref.Transport)
+	}
+	parsed, err := parseRemoteReference(ref.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the destination reference: %w", err)
+	}
+	return parsed, nil
+}
+
+// remoteOptions returns the options of every registry call: the active
+// keychain, the transport with the configured request headers, and ctx.
+func remoteOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithAuthFromKeychain(activeKeychain),
+		remote.WithTransport(registryTransport()),
+		remote.WithContext(ctx),
+	}
+}
+
+// CopyImage copies src to the registry reference dst and returns the
+// descriptor of what was pushed. Registry sources are copied as stored, so
+// multi-platform indexes keep all their platforms; when the registry cannot be
+// reached the local daemon image is used instead. Other transports are loaded
+// with GetImage. Registry layers are read through the layer cache when one is
+// configured with SetLayerCache.
+func CopyImage(ctx context.Context, src, dst string) (*cr.Descriptor, error) {
+	dstRef, err := ParseDestination(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	srcRef, err := ParseReference(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcRef.Transport == TransportDaemonRegistry {
+		desc, err := copyRemote(ctx, srcRef.Path, dstRef)
+		if err == nil {
+			return desc, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.WithField("error", err).Debug("Remote copy failed, falling back to the local image")
+	}
+
+	img, cleanup, err := GetImage(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return writeImage(ctx, img, dstRef)
+}
+
+// PinnedSource is a copy source resolved once, so that the image copied by
+// CopyPinned is the image validated through Ref.
+type PinnedSource struct {
+	// Ref is the reference to validate: the registry manifest or OCI layout
+	// manifest pinned by digest, or the source as given for archives and for
+	// registry images only available in the local daemon.
+	Ref string
+	// Platforms is the number of platform images of a registry index, not
+	// counting attestation manifests, or 0 when the source is an image.
+	Platforms int
+
+	desc *remote.Descriptor
+	// imageID is the config digest of a source that is not pinned by digest,
+	// checked again when it is copied.
+	imageID cr.Hash
+}
+
+// PinSource resolves src once for a validation followed by a copy. Registry
+// tags are resolved to the digest of the manifest they point to, image or
+// index, and OCI layout tags to the digest in the layout. Other sources, and
+// registry images that fall back to the local daemon when the registry cannot
+// be reached, are loaded and their image ID recorded.
+func PinSource(ctx context.Context, src string) (*PinnedSource, error) {
+	srcRef, err := ParseReference(src)
+	if err != nil {
+		return nil, withKind(ErrorKindInvalidReference, err)
+	}
+
+	switch {
+	case srcRef.Transport == TransportDaemonRegistry:
+		ref, err := parseRemoteReference(srcRef.Path)
+		if err != nil {
+			return nil, withKind(ErrorKindInvalidReference, fmt.Errorf("error parsing the reference: %w", err))
+		}
+		desc, err := remoteGet(ctx, ref)
+		if err == nil {
+			pinned := &PinnedSource{Ref: ref.Context().Digest(desc.Digest.String()).String(), desc: desc}
+			if desc.MediaType.IsIndex() {
+				if pinned.Platforms, err = indexPlatforms(desc); err != nil {
+					return nil, err
+				}
+			}
+			return pinned, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.WithField("error", err).Debug("Registry source unavailable, pinning the local image")
+
+	case srcRef.Transport == TransportOCI && srcRef.Digest == "" && srcRef.Tag != "":
+		path, err := layout.FromPath(srcRef.Path)
+		if err != nil {
+			return nil, classifyImageError(fmt.Errorf("error reading OCI layout: %w", err))
+		}
+		digest, err := resolveTagInLayout(path, srcRef.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving tag: %w", err)
+		}
+		return &PinnedSource{Ref: string(TransportOCI) + ":" + srcRef.Path + "@" + digest}, nil
+	}
+
+	img, cleanup, err := GetImage(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	id, err := img.ConfigName()
+	if err != nil {
+		return nil, fmt.Errorf("error computing the image ID: %w", err)
+	}
+	return &PinnedSource{Ref: src, imageID: id}, nil
+}
+
+// indexPlatforms counts the platform images of a registry index, leaving out
+// attestation manifests (platform unknown/unknown).
+func indexPlatforms(desc *remote.Descriptor) (int, error) {
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return 0, fmt.Errorf("error reading the image index: %w", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return 0, fmt.Errorf("error reading the image index: %w", err)
+	}
+	platforms := 0
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil || m.Platform.OS != "unknown" || m.Platform.Architecture != "unknown" {
+			platforms++
+		}
+	}
+	return platforms, nil
+}
+
+// CopyPinned copies the source pinned by PinSource to the registry reference
+// dst. A registry manifest is pushed exactly as resolved, without fetching the
+// tag again. Other sources are loaded again, and the copy is refused when their
+// image ID changed since they were pinned.
+func CopyPinned(ctx context.Context, src *PinnedSource, dst string) (*cr.Descriptor, error) {
+	dstRef, err := ParseDestination(dst)
+	if err != nil {
+		return nil, err
+	}
+	if src.desc != nil {
+		return copyDescriptor(ctx, src.desc, dstRef)
+	}
+
+	img, cleanup, err := GetImage(ctx, src.Ref)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if src.imageID != (cr.Hash{}) {
+		id, err := img.ConfigName()
+		if err != nil {
+			return nil, fmt.Errorf("error computing the image ID: %w", err)
+		}
+		if id != src.imageID {
+			return nil, fmt.Errorf("source image changed during validation: image ID %s, validated %s", id, src.imageID)
+		}
+	}
+	return writeImage(ctx, img, dstRef)
+}
+
+// copyRemote copies a registry manifest (image or index) to dst without
+// resolving it to a single platform.
+func copyRemote(ctx context.Context, src string, dst name.Reference) (*cr.Descriptor, error) {
+	ref, err := parseRemoteReference(src)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the reference: %w", err)
+	}
+
+	desc, err := remoteGet(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the remote image: %w", err)
+	}
+	return copyDescriptor(ctx, desc, dst)
+}
+
+// copyDescriptor pushes the manifest of desc (image or index) to dst.
+func copyDescriptor(ctx context.Context, desc *remote.Descriptor, dst name.Reference) (*cr.Descriptor, error) {
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the image index: %w", err)
+		}
+		if err := remote.WriteIndex(dst, idx, remoteOptions(ctx)...); err != nil {
+			return nil, fmt.Errorf("error pushing the image index: %w", err)
+		}
+		d := desc.Descriptor
+		return &d, nil
+	}
+
+	img, err := desc.Image()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the image: %w", err)
+	}
+	return writeImage(ctx, withLayerCache(img), dst)
+}
+
+func writeImage(ctx context.Context, img cr.Image, dst name.Reference) (*cr.Descriptor, error) {
+	if err := remote.Write(dst, img, remoteOptions(ctx)...); err != nil {
+		return nil, fmt.Errorf("error pushing the image: %w", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("error computing the image digest: %w", err)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the image media type: %w", err)
+	}
+	size, err := img.Size()
+	if err != nil {
+		return nil, fmt.Errorf("error computing the image manifest size: %w", err)
+	}
+	return &cr.Descriptor{MediaType: mediaType, Digest: digest, Size: size}, nil
+}
+
+// ResolveDescriptor returns the descriptor of the manifest (image or index)
+// that the registry reference image points to, without resolving it to a
+// single platform.
+func ResolveDescriptor(ctx context.Context, image string) (*cr.Descriptor, error) {
+	ref, err := ParseDestination(image)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := remoteHead(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the remote manifest: %w", err)
+	}
+	return desc, nil
+}
+
+// AttachArtifact pushes data as a single-layer OCI artifact that refers to
+// subject (OCI 1.1 referrers), in the same repository as dst. The artifact
+// type is recorded as the config media type so registries without native
+// referrers support still index it through the fallback tag schema.
+// annotations, when not empty, are set on the artifact manifest.
+func AttachArtifact(ctx context.Context, dst string, subject cr.Descriptor, artifactType, mediaType string, data []byte, annotations map[string]string) (cr.Hash, error) {
+	dstRef, err := ParseDestination(dst)
+	if err != nil {
+		return cr.Hash{}, err
+	}
+
+	base := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	base = mutate.ConfigMediaType(base, types.MediaType(artifactType))
+	img, err := mutate.AppendLayers(base, static.NewLayer(data, types.MediaType(mediaType)))
+	if err != nil {
+		return cr.Hash{}, fmt.Errorf("error building artifact: %w", err)
+	}
+	if len(annotations) > 0 {
+		annotated, ok := mutate.Annotations(img, annotations).(cr.Image)
+		if !ok {
+			return cr.Hash{}, fmt.Errorf("error setting artifact annotations")
+		}
+		img = annotated
+	}
+	artifact, ok := mutate.Subject(img, subject).(cr.Image)
+	if !ok {
+		return cr.Hash{}, fmt.Errorf("error setting artifact subject")
+	}
+
+	digest, err := artifact.Digest()
+	if err != nil {
+		return cr.Hash{}, fmt.Errorf("error computing artifact digest: %w", err)
+	}
+	if err := remote.Write(dstRef.Context().Digest(digest.String()), artifact, remoteOptions(ctx)...); err != nil {
+		return cr.Hash{}, fmt.Errorf("error pushing artifact: %w", err)
+	}
+	return digest, nil
+}
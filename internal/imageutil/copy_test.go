@@ -0,0 +1,219 @@
+package imageutil
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestParseDestination(t *testing.T) {
+	ref, err := ParseDestination("ghcr.io/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/org/app:1.0", ref.String())
+
+	_, err = ParseDestination("oci:/tmp/layout:latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination must be a registry reference")
+}
+
+func TestCopyImage_RemoteImage(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	src, err := name.ParseReference(host + "/src/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(src, img))
+
+	desc, err := CopyImage(context.Background(), src.String(), host+"/prod/app:1.0")
+	require.NoError(t, err)
+
+	want, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, want, desc.Digest)
+
+	dst, err := name.ParseReference(host + "/prod/app:1.0")
+	require.NoError(t, err)
+	got, err := remote.Head(dst)
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Digest)
+}
+
+func TestCopyImage_RemoteIndex(t *testing.T) {
+	host := newTestRegistry(t)
+	idx, err := random.Index(512, 1, 2)
+	require.NoError(t, err)
+	src, err := name.ParseReference(host + "/src/multi:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(src, idx))
+
+	desc, err := CopyImage(context.Background(), src.String(), host+"/prod/multi:1.0")
+	require.NoError(t, err)
+
+	want, err := idx.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, want, desc.Digest)
+	assert.True(t, desc.MediaType.IsIndex())
+}
+
+func TestCopyImage_OCILayoutSource(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	desc, err := CopyImage(context.Background(), "oci:"+writeTestLayout(t, img)+":v1", host+"/prod/local:v1")
+	require.NoError(t, err)
+
+	want, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, want, desc.Digest)
+}
+
+func TestPinSource_RegistryTag(t *testing.T) {
+	host := newTestRegistry(t)
+	validated, err := random.Image(256, 1)
+	require.NoError(t, err)
+	src, err := name.ParseReference(host + "/src/app:rc")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(src, validated))
+
+	pinned, err := PinSource(context.Background(), src.String())
+	require.NoError(t, err)
+	want, err := validated.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, src.Context().Digest(want.String()).String(), pinned.Ref)
+	assert.Zero(t, pinned.Platforms)
+
+	// The tag moves after the validation: the pinned image is still copied.
+	moved, err := random.Image(256, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(src, moved))
+
+	desc, err := CopyPinned(context.Background(), pinned, host+"/prod/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, want, desc.Digest)
+}
+
+func TestPinSource_RegistryIndexPlatforms(t *testing.T) {
+	host := newTestRegistry(t)
+	idx, err := random.Index(256, 1, 3)
+	require.NoError(t, err)
+	src, err := name.ParseReference(host + "/src/multi:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(src, idx))
+
+	pinned, err := PinSource(context.Background(), src.String())
+	require.NoError(t, err)
+	assert.Equal(t, 3, pinned.Platforms)
+}
+
+func TestPinSource_OCILayoutTag(t *testing.T) {
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	dir := writeTestLayout(t, img)
+
+	pinned, err := PinSource(context.Background(), "oci:"+dir+":v1")
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, "oci:"+dir+"@"+digest.String(), pinned.Ref)
+}
+
+func TestCopyPinned_ImageChanged(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	dir := writeTestLayout(t, img)
+
+	pinned := &PinnedSource{Ref: "oci:" + dir + ":v1", imageID: cr.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}}
+	_, err = CopyPinned(context.Background(), pinned, host+"/prod/app:1.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source image changed during validation")
+}
+
+func TestCopyImage_InvalidDestination(t *testing.T) {
+	_, err := CopyImage(context.Background(), "nginx:latest", "oci:/tmp/layout:latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination must be a registry reference")
+}
+
+func TestAttachArtifact(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	desc, err := CopyImage(context.Background(), "oci:"+writeTestLayout(t, img)+":v1", host+"/prod/app:v1")
+	require.NoError(t, err)
+
+	digest, err := AttachArtifact(context.Background(), host+"/prod/app:v1", *desc,
+		"application/vnd.example.report.v1+json", "application/json", []byte(`{"passed":true}`),
+		map[string]string{"com.example.passed": "true"})
+	require.NoError(t, err)
+
+	subject, err := name.ParseReference(host + "/prod/app@" + desc.Digest.String())
+	require.NoError(t, err)
+	refs, err := remote.Referrers(subject.(name.Digest))
+	require.NoError(t, err)
+	manifest, err := refs.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Manifests, 1)
+	assert.Equal(t, digest, manifest.Manifests[0].Digest)
+	assert.Equal(t, "application/vnd.example.report.v1+json", manifest.Manifests[0].ArtifactType)
+
+	artifact, err := remote.Image(subject.Context().Digest(digest.String()))
+	require.NoError(t, err)
+	artifactManifest, err := artifact.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, "true", artifactManifest.Annotations["com.example.passed"])
+}
+
+func TestResolveDescriptor(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	pushed, err := CopyImage(context.Background(), "oci:"+writeTestLayout(t, img)+":v1", host+"/prod/app:v1")
+	require.NoError(t, err)
+
+	desc, err := ResolveDescriptor(context.Background(), host+"/prod/app:v1")
+	require.NoError(t, err)
+	assert.Equal(t, pushed.Digest, desc.Digest)
+
+	_, err = ResolveDescriptor(context.Background(), host+"/prod/app:missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error retrieving the remote manifest")
+
+	_, err = ResolveDescriptor(context.Background(), "oci:/tmp/layout:v1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination must be a registry reference")
+}
+
+// writeTestLayout writes img to a new OCI layout tagged "v1" and returns its path.
+func writeTestLayout(t *testing.T, img cr.Image) string {
+	t.Helper()
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(img, layout.WithAnnotations(map[string]string{
+		ociRefNameAnnotation: "v1",
+	})))
+	return dir
+}
@@ -0,0 +1,49 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ImageDigests returns the manifest digests that identify imageName: the
+// digest given in the reference, the digest the registry resolves it to (an
+// index digest for multi-platform images), and the digest of the image
+// manifest that is validated. Registry lookups are best effort, so images
+// only present in the local daemon still yield their image digest.
+func ImageDigests(ctx context.Context, imageName string) ([]string, error) {
+	ref, err := ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var digests []string
+	add := func(d string) {
+		if d != "" && !slices.Contains(digests, d) {
+			digests = append(digests, d)
+		}
+	}
+	add(ref.Digest)
+	if ref.Transport == TransportDaemonRegistry {
+		if d, err := name.NewDigest(ref.Path); err == nil {
+			add(d.DigestStr())
+		}
+		if desc, err := ResolveDescriptor(ctx, imageName); err == nil {
+			add(desc.Digest.String())
+		}
+	}
+
+	img, cleanup, err := GetImage(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("error computing the image digest: %w", err)
+	}
+	add(digest.String())
+	return digests, nil
+}
@@ -0,0 +1,121 @@
+package imageutil
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// ErrorKind categorizes a failure to load an image, so that automation can
+// tell an image that does not exist from credentials that are missing or
+// rejected, or from a registry that is temporarily unreachable.
+type ErrorKind string
+
+const (
+	// ErrorKindNotFound is an image, tag, repository, archive or layout that
+	// does not exist.
+	ErrorKindNotFound ErrorKind = "not-found"
+	// ErrorKindUnauthorized is a registry rejecting the request for missing
+	// or insufficient credentials (HTTP 401 or 403).
+	ErrorKindUnauthorized ErrorKind = "unauthorized"
+	// ErrorKindUnavailable is a network error, or a registry that kept
+	// answering with HTTP 429 or 5xx after the retries.
+	ErrorKindUnavailable ErrorKind = "unavailable"
+	// ErrorKindInvalidReference is an image reference that cannot be parsed.
+	ErrorKindInvalidReference ErrorKind = "invalid-reference"
+	// ErrorKindNotRunnable is an OCI artifact that is not a container image,
+	// such as a Helm chart, or an image without a configuration or layers.
+	ErrorKindNotRunnable ErrorKind = "not-runnable"
+)
+
+// kindError attaches an ErrorKind to an error without changing its message.
+type kindError struct {
+	kind ErrorKind
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() error { return e.err }
+
+// withKind returns err with kind attached, or err itself when it already
+// carries a kind.
+func withKind(kind ErrorKind, err error) error {
+	var ke *kindError
+	if err == nil || errors.As(err, &ke) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// ErrorKindOf returns the kind of an error, or "" when it has none. Errors
+// returned by GetImage carry a kind whatever the transport; registry errors
+// of other requests, such as fetching attestations, are classified by their
+// status code.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var ke *kindError
+	if errors.As(err, &ke) {
+		return ke.kind
+	}
+	return registryErrorKind(err)
+}
+
+// classifyImageError attaches the kind of a failure to load an image to err,
+// if it has one.
+func classifyImageError(err error) error {
+	if kind := registryErrorKind(err); kind != "" {
+		return withKind(kind, err)
+	}
+	var badName *name.ErrBadName
+	switch {
+	case errors.As(err, &badName):
+		return withKind(ErrorKindInvalidReference, err)
+	case errors.Is(err, fs.ErrNotExist):
+		// A missing archive file or layout directory.
+		return withKind(ErrorKindNotFound, err)
+	}
+	return err
+}
+
+// registryErrorKind classifies the HTTP status code and error codes of a
+// registry response, and network errors.
+func registryErrorKind(err error) ErrorKind {
+	var tErr *transport.Error
+	if errors.As(err, &tErr) {
+		for _, d := range tErr.Errors {
+			switch d.Code {
+			case transport.ManifestUnknownErrorCode, transport.NameUnknownErrorCode:
+				return ErrorKindNotFound
+			case transport.UnauthorizedErrorCode, transport.DeniedErrorCode:
+				return ErrorKindUnauthorized
+			}
+		}
+		switch {
+		case tErr.StatusCode == http.StatusNotFound:
+			return ErrorKindNotFound
+		case tErr.StatusCode == http.StatusUnauthorized, tErr.StatusCode == http.StatusForbidden:
+			return ErrorKindUnauthorized
+		case tErr.StatusCode == http.StatusTooManyRequests, tErr.StatusCode >= http.StatusInternalServerError:
+			return ErrorKindUnavailable
+		}
+		return ""
+	}
+	// Request failures of the HTTP client and network errors. net.Error is
+	// not matched as such, since syscall errors of file operations implement
+	// it too.
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ErrorKindUnavailable
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package imageutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nil", nil, ""},
+		{"plain error", errors.New("boom"), ""},
+		{"status 404", &transport.Error{StatusCode: 404}, ErrorKindNotFound},
+		{"status 401", &transport.Error{StatusCode: 401}, ErrorKindUnauthorized},
+		{"status 403", &transport.Error{StatusCode: 403}, ErrorKindUnauthorized},
+		{"status 429", &transport.Error{StatusCode: 429}, ErrorKindUnavailable},
+		{"status 503", &transport.Error{StatusCode: 503}, ErrorKindUnavailable},
+		{"status 400", &transport.Error{StatusCode: 400}, ""},
+		{"manifest unknown code", &transport.Error{StatusCode: 400, Errors: []transport.Diagnostic{{Code: transport.ManifestUnknownErrorCode}}}, ErrorKindNotFound},
+		{"denied code", &transport.Error{StatusCode: 400, Errors: []transport.Diagnostic{{Code: transport.DeniedErrorCode}}}, ErrorKindUnauthorized},
+		{"wrapped", fmt.Errorf("error retrieving the remote image: %w", &transport.Error{StatusCode: 401}), ErrorKindUnauthorized},
+		{"network error", &net.DNSError{Err: "no such host", Name: "registry.invalid"}, ErrorKindUnavailable},
+		{"attached kind", fmt.Errorf("outer: %w", withKind(ErrorKindNotFound, errors.New("tag missing"))), ErrorKindNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorKindOf(tt.err))
+		})
+	}
+}
+
+func TestWithKind_KeepsMessageAndFirstKind(t *testing.T) {
+	err := withKind(ErrorKindNotFound, errors.New("tag missing"))
+	assert.Equal(t, "tag missing", err.Error())
+	assert.Equal(t, ErrorKindNotFound, ErrorKindOf(withKind(ErrorKindUnauthorized, err)))
+}
+
+func TestGetImage_ErrorKinds(t *testing.T) {
+	layoutPath := filepath.Join(t.TempDir(), "layout")
+	createOCILayoutWithTag(t, layoutPath, "latest")
+	registry := newTestRegistry(t)
+
+	origLocal := getLocalImageFn
+	t.Cleanup(func() { getLocalImageFn = origLocal })
+	getLocalImageFn = func(_ context.Context, _ string) (v1.Image, error) {
+		return nil, errors.New("daemon unavailable")
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  ErrorKind
+	}{
+		{"missing layout tag", "oci:" + layoutPath + ":missing", ErrorKindNotFound},
+		{"missing layout", "oci:" + filepath.Join(t.TempDir(), "nope") + ":latest", ErrorKindNotFound},
+		{"missing archive", "oci-archive:" + filepath.Join(t.TempDir(), "nope.tar") + ":latest", ErrorKindNotFound},
+		{"missing registry image", registry + "/app/missing:1.0", ErrorKindNotFound},
+		{"layout without tag", "oci:" + layoutPath, ErrorKindInvalidReference},
+		{"invalid reference", "Invalid/UPPER:tag", ErrorKindInvalidReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cleanup, err := GetImage(context.Background(), tt.image)
+			defer cleanup()
+			require.Error(t, err)
+			assert.Equal(t, tt.want, ErrorKindOf(err), err.Error())
+			assert.False(t, strings.HasPrefix(err.Error(), string(tt.want)), "the message is unchanged")
+		})
+	}
+}
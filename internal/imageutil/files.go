@@ -0,0 +1,184 @@
+package imageutil
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// Whiteout markers of the OCI layer format: a ".wh." prefixed entry deletes
+// the file of the same name from lower layers, and an opaque whiteout deletes
+// every entry of its directory from lower layers.
+const (
+	whiteoutPrefix  = ".wh."
+	opaqueWhiteout  = ".wh..wh..opq"
+	maxSymlinkHops  = 40
+	fileTreeRootDir = "/"
+)
+
+// fileEntry is a file of the merged filesystem of an image.
+type fileEntry struct {
+	typeflag byte
+	linkname string
+}
+
+// fileTree maps the absolute paths of the merged filesystem of an image to
+// their entries.
+type fileTree map[string]fileEntry
+
+// FilesExist reports, for each of paths, whether it is a file in the
+// filesystem of img, the result of applying its layers in order with their
+// whiteouts. Symbolic links are followed, including links of parent
+// directories, such as /bin to usr/bin in merged-/usr images.
+func FilesExist(ctx context.Context, img cr.Image, paths []string) (map[string]bool, error) {
+	resolved, err := ResolveFiles(ctx, img, paths)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		_, exists[p] = resolved[p]
+	}
+	return exists, nil
+}
+
+// ResolveFiles returns, for each of paths that is a file in the filesystem of
+// img, the path of the file it refers to after following symbolic links, as
+// FilesExist does. Paths that are not files are left out, so that paths
+// referring to the same file, such as /bin/sh and /usr/bin/sh in merged-/usr
+// images, can be told apart from distinct files.
+func ResolveFiles(ctx context.Context, img cr.Image, paths []string) (map[string]string, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error getting image layers: %w", err)
+	}
+
+	tree := make(fileTree)
+	for i, layer := range layers {
+		log.WithFields(log.Fields{"layer": i + 1, "total": len(layers)}).Debug("Reading layer file list")
+		if err := tree.apply(ctx, layer); err != nil {
+			return nil, fmt.Errorf("error reading layer %d: %w", i, err)
+		}
+	}
+
+	resolved := make(map[string]string, len(paths))
+	for _, p := range paths {
+		target, entry, ok := tree.resolve(p)
+		if ok && (entry.typeflag == tar.TypeReg || entry.typeflag == tar.TypeLink) {
+			resolved[p] = target
+		}
+	}
+	return resolved, nil
+}
+
+// apply adds the entries of layer to the tree. The whiteouts of the layer
+// apply to lower layers only, so they are applied before its entries are
+// added.
+func (t fileTree) apply(ctx context.Context, layer cr.Layer) error {
+	rc, err := OpenLayer(layer)
+	if err != nil {
+		return fmt.Errorf("error uncompressing layer: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	var whiteouts []string
+	entries := make(fileTree)
+	tr := tar.NewReader(rc)
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("reading cancelled: %w", err)
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar: %w", err)
+		}
+
+		name := path.Clean(fileTreeRootDir + header.Name)
+		if strings.HasPrefix(path.Base(name), whiteoutPrefix) {
+			whiteouts = append(whiteouts, name)
+			continue
+		}
+		entries[name] = fileEntry{typeflag: header.Typeflag, linkname: header.Linkname}
+	}
+
+	for _, w := range whiteouts {
+		dir, base := path.Split(w)
+		dir = path.Clean(dir)
+		if base == opaqueWhiteout {
+			t.removeChildren(dir)
+			continue
+		}
+		removed := path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))
+		delete(t, removed)
+		t.removeChildren(removed)
+	}
+	for name, entry := range entries {
+		t[name] = entry
+	}
+	return nil
+}
+
+func (t fileTree) removeChildren(dir string) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for name := range t {
+		if strings.HasPrefix(name, prefix) {
+			delete(t, name)
+		}
+	}
+}
+
+// resolve returns the path and entry p refers to after following symbolic
+// links. Directories without an entry of their own, which layers may leave
+// out, are assumed to exist.
+func (t fileTree) resolve(p string) (string, fileEntry, bool) {
+	parts := splitPath(p)
+	cur := fileTreeRootDir
+	for hops := 0; len(parts) > 0; {
+		next := path.Join(cur, parts[0])
+		parts = parts[1:]
+
+		entry, ok := t[next]
+		if !ok {
+			if len(parts) == 0 {
+				return "", fileEntry{}, false
+			}
+			cur = next
+			continue
+		}
+		if entry.typeflag == tar.TypeSymlink {
+			if hops++; hops > maxSymlinkHops {
+				return "", fileEntry{}, false
+			}
+			target := entry.linkname
+			if !path.IsAbs(target) {
+				target = path.Join(cur, target)
+			}
+			parts = append(splitPath(target), parts...)
+			cur = fileTreeRootDir
+			continue
+		}
+		if len(parts) == 0 {
+			return next, entry, true
+		}
+		cur = next
+	}
+	return "", fileEntry{}, false
+}
+
+// splitPath returns the components of p relative to the root directory.
+func splitPath(p string) []string {
+	clean := strings.TrimPrefix(path.Clean(fileTreeRootDir+p), fileTreeRootDir)
+	if clean == "" {
+		return nil
+	}
+	return strings.Split(clean, "/")
+}
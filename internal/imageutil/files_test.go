@@ -0,0 +1,145 @@
+package imageutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileLayer builds a layer from tar headers; regular files are empty.
+func fileLayer(t *testing.T, headers ...tar.Header) cr.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range headers {
+		h.Mode = 0755
+		require.NoError(t, tw.WriteHeader(&h))
+	}
+	require.NoError(t, tw.Close())
+	data := buf.Bytes()
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	require.NoError(t, err)
+	return layer
+}
+
+func TestFilesExist(t *testing.T) {
+	reg := func(name string) tar.Header { return tar.Header{Name: name, Typeflag: tar.TypeReg} }
+	dir := func(name string) tar.Header { return tar.Header{Name: name, Typeflag: tar.TypeDir} }
+	link := func(name, target string) tar.Header {
+		return tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target}
+	}
+
+	tests := []struct {
+		name     string
+		layers   [][]tar.Header
+		expected map[string]bool
+	}{
+		{
+			name:     "Regular file",
+			layers:   [][]tar.Header{{dir("bin/"), reg("bin/sh")}},
+			expected: map[string]bool{"/bin/sh": true, "/bin/bash": false},
+		},
+		{
+			name:     "No layers",
+			expected: map[string]bool{"/bin/sh": false},
+		},
+		{
+			name:     "Directory is not a file",
+			layers:   [][]tar.Header{{dir("bin/sh/")}},
+			expected: map[string]bool{"/bin/sh": false},
+		},
+		{
+			name:     "Relative symlink to busybox",
+			layers:   [][]tar.Header{{reg("bin/busybox"), link("bin/sh", "busybox")}},
+			expected: map[string]bool{"/bin/sh": true},
+		},
+		{
+			name:     "Merged usr directory symlink",
+			layers:   [][]tar.Header{{link("bin", "usr/bin"), reg("usr/bin/dash"), link("usr/bin/sh", "dash")}},
+			expected: map[string]bool{"/bin/sh": true},
+		},
+		{
+			name:     "Dangling symlink",
+			layers:   [][]tar.Header{{link("bin/sh", "/bin/dash")}},
+			expected: map[string]bool{"/bin/sh": false},
+		},
+		{
+			name:     "Symlink loop",
+			layers:   [][]tar.Header{{link("bin/sh", "/bin/bash"), link("bin/bash", "sh")}},
+			expected: map[string]bool{"/bin/sh": false},
+		},
+		{
+			name:     "Whiteout in upper layer",
+			layers:   [][]tar.Header{{reg("bin/sh"), reg("bin/bash")}, {reg("bin/.wh.sh")}},
+			expected: map[string]bool{"/bin/sh": false, "/bin/bash": true},
+		},
+		{
+			name:     "Opaque whiteout keeps entries of its own layer",
+			layers:   [][]tar.Header{{reg("bin/sh"), reg("bin/bash")}, {reg("bin/.wh..wh..opq"), reg("bin/bash")}},
+			expected: map[string]bool{"/bin/sh": false, "/bin/bash": true},
+		},
+		{
+			name:     "Whiteout of a directory",
+			layers:   [][]tar.Header{{dir("bin/"), reg("bin/sh")}, {reg(".wh.bin")}},
+			expected: map[string]bool{"/bin/sh": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var layers []cr.Layer
+			for _, headers := range tt.layers {
+				layers = append(layers, fileLayer(t, headers...))
+			}
+			img, err := mutate.AppendLayers(empty.Image, layers...)
+			require.NoError(t, err)
+
+			paths := make([]string, 0, len(tt.expected))
+			for p := range tt.expected {
+				paths = append(paths, p)
+			}
+			exists, err := FilesExist(context.Background(), img, paths)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, exists)
+		})
+	}
+}
+
+func TestFilesExist_Cancelled(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image, fileLayer(t, tar.Header{Name: "bin/sh", Typeflag: tar.TypeReg}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FilesExist(ctx, img, []string{"/bin/sh"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading cancelled")
+}
+
+func TestResolveFiles(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image, fileLayer(t,
+		tar.Header{Name: "bin", Typeflag: tar.TypeSymlink, Linkname: "usr/bin"},
+		tar.Header{Name: "usr/bin/dash", Typeflag: tar.TypeReg},
+		tar.Header{Name: "usr/bin/sh", Typeflag: tar.TypeSymlink, Linkname: "dash"},
+	))
+	require.NoError(t, err)
+
+	resolved, err := ResolveFiles(context.Background(), img, []string{"/bin/sh", "/usr/bin/sh", "/usr/bin/dash", "/bin/bash"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/bin/sh":       "/usr/bin/dash",
+		"/usr/bin/sh":   "/usr/bin/dash",
+		"/usr/bin/dash": "/usr/bin/dash",
+	}, resolved)
+}
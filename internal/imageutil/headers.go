@@ -0,0 +1,87 @@
+package imageutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// requestHeaders holds the headers added to every registry request. A
+// User-Agent entry replaces the default user agent.
+var requestHeaders http.Header
+
+// reservedHeaders cannot be set with SetRequestHeaders: credentials have
+// their own flags and are scoped to a registry, and Host is derived from the
+// reference.
+var reservedHeaders = []string{"Authorization", "Host"}
+
+// SetRequestHeaders configures headers sent with every registry request, so
+// enterprise registries and WAFs can identify check-image traffic. userAgent,
+// when not empty, replaces the default user agent. headers are "Name=value"
+// pairs; a name given more than once is sent with all of its values.
+func SetRequestHeaders(userAgent string, headers []string) error {
+	h := http.Header{}
+	for _, kv := range headers {
+		key, value, err := ParseHeader(kv)
+		if err != nil {
+			return err
+		}
+		h.Add(key, value)
+	}
+	if userAgent != "" {
+		h.Set("User-Agent", userAgent)
+	}
+	if len(h) == 0 {
+		requestHeaders = nil
+		return nil
+	}
+	requestHeaders = h
+	return nil
+}
+
+// ParseHeader parses a "Name=value" header. The name is canonicalized.
+func ParseHeader(kv string) (string, string, error) {
+	key, value, ok := strings.Cut(kv, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid header %q, expected Name=value", kv)
+	}
+	if strings.ContainsAny(key, " \t\r\n:") || strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("invalid header %q, names cannot contain spaces or colons and values cannot contain line breaks", kv)
+	}
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	for _, reserved := range reservedHeaders {
+		if key == reserved {
+			return "", "", fmt.Errorf("header %s cannot be set with --registry-header", key)
+		}
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// registryTransport returns the transport for registry calls: remoteTransport,
+// without certificate verification for the insecure registries, wrapped to
+// add the configured request headers when there are any.
+func registryTransport() http.RoundTripper {
+	base := withInsecureRegistries(remoteTransport)
+	if len(requestHeaders) == 0 {
+		return base
+	}
+	return &headerTransport{base: base, headers: requestHeaders}
+}
+
+// headerTransport sets headers on each request before delegating to base. It
+// runs below go-containerregistry's own user agent transport, so a configured
+// User-Agent takes effect.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
+	return t.base.RoundTrip(req)
+}
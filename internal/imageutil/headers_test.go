@@ -0,0 +1,106 @@
+package imageutil
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantKey   string
+		wantValue string
+		errMsg    string
+	}{
+		{name: "simple", input: "X-Team=platform", wantKey: "X-Team", wantValue: "platform"},
+		{name: "canonicalized name", input: "x-request-source=ci", wantKey: "X-Request-Source", wantValue: "ci"},
+		{name: "value with equals", input: "X-Token=a=b", wantKey: "X-Token", wantValue: "a=b"},
+		{name: "empty value", input: "X-Empty=", wantKey: "X-Empty", wantValue: ""},
+		{name: "missing equals", input: "X-Team", errMsg: "expected Name=value"},
+		{name: "empty name", input: "=value", errMsg: "expected Name=value"},
+		{name: "colon in name", input: "X-Team:a=b", errMsg: "cannot contain"},
+		{name: "line break in value", input: "X-Team=a\r\nX-Other: b", errMsg: "cannot contain"},
+		{name: "authorization", input: "authorization=Bearer abc", errMsg: "Authorization cannot be set"},
+		{name: "host", input: "Host=example.com", errMsg: "Host cannot be set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, err := ParseHeader(tt.input)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantValue, value)
+		})
+	}
+}
+
+func TestSetRequestHeaders(t *testing.T) {
+	t.Cleanup(func() { requestHeaders = nil })
+
+	require.NoError(t, SetRequestHeaders("", nil))
+	assert.Same(t, remoteTransport, registryTransport())
+
+	require.NoError(t, SetRequestHeaders("acme-scanner/1.0", []string{"X-Team=platform", "X-Team=security"}))
+	assert.Equal(t, "acme-scanner/1.0", requestHeaders.Get("User-Agent"))
+	assert.Equal(t, []string{"platform", "security"}, requestHeaders.Values("X-Team"))
+	assert.IsType(t, &headerTransport{}, registryTransport())
+
+	require.Error(t, SetRequestHeaders("", []string{"invalid"}))
+}
+
+func TestRequestHeaders_SentToRegistry(t *testing.T) {
+	t.Cleanup(func() { requestHeaders = nil })
+
+	var mu sync.Mutex
+	var seen []http.Header
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Clone())
+		mu.Unlock()
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/org/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	require.NoError(t, SetRequestHeaders("acme-scanner/1.0", []string{"X-Team=platform"}))
+	mu.Lock()
+	seen = nil
+	mu.Unlock()
+
+	_, err = GetRemoteImage(context.Background(), host+"/org/app:1.0")
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, seen)
+	for _, h := range seen {
+		assert.Equal(t, "acme-scanner/1.0", h.Get("User-Agent"))
+		assert.Equal(t, "platform", h.Get("X-Team"))
+	}
+}
@@ -0,0 +1,42 @@
+package imageutil
+
+import (
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// LayerHistory returns the history entry that created each layer of an image,
+// indexed like its layers. History entries marked empty_layer (ENV, CMD, ...)
+// create no layer and are skipped, and CreatedBy is trimmed. It returns nil
+// when the non-empty entries do not match the layers one to one, since any
+// mapping would then be a guess.
+func LayerHistory(config *cr.ConfigFile, layerCount int) []cr.History {
+	if config == nil {
+		return nil
+	}
+	history := make([]cr.History, 0, layerCount)
+	for _, h := range config.History {
+		if !h.EmptyLayer {
+			h.CreatedBy = strings.TrimSpace(h.CreatedBy)
+			history = append(history, h)
+		}
+	}
+	if len(history) != layerCount {
+		log.WithFields(log.Fields{"history": len(history), "layers": layerCount}).
+			Debug("Image history does not match the layers, skipping layer attribution")
+		return nil
+	}
+	return history
+}
+
+// LayerCreatedBy returns the CreatedBy of the history entry of the layer at
+// index, as aligned by LayerHistory, or an empty string when the layer has no
+// aligned entry.
+func LayerCreatedBy(history []cr.History, index int) string {
+	if index < 0 || index >= len(history) {
+		return ""
+	}
+	return history[index].CreatedBy
+}
@@ -0,0 +1,37 @@
+package imageutil
+
+import (
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerHistory(t *testing.T) {
+	cfg := &cr.ConfigFile{History: []cr.History{
+		{CreatedBy: "ADD rootfs.tar /"},
+		{CreatedBy: "CMD [\"sh\"]", EmptyLayer: true},
+		{CreatedBy: "  RUN apk add curl  ", Comment: "buildkit.dockerfile.v0"},
+	}}
+
+	history := LayerHistory(cfg, 2)
+	require.Len(t, history, 2)
+	assert.Equal(t, "ADD rootfs.tar /", history[0].CreatedBy)
+	assert.Equal(t, "RUN apk add curl", history[1].CreatedBy)
+	assert.Equal(t, "buildkit.dockerfile.v0", history[1].Comment)
+	assert.Equal(t, "  RUN apk add curl  ", cfg.History[2].CreatedBy, "the config is not modified")
+
+	assert.Nil(t, LayerHistory(cfg, 3), "misaligned history is not used")
+	assert.Nil(t, LayerHistory(nil, 1))
+}
+
+func TestLayerCreatedBy(t *testing.T) {
+	history := []cr.History{{CreatedBy: "ADD rootfs.tar /"}, {CreatedBy: "RUN apk add curl"}}
+
+	assert.Equal(t, "ADD rootfs.tar /", LayerCreatedBy(history, 0))
+	assert.Equal(t, "RUN apk add curl", LayerCreatedBy(history, 1))
+	assert.Empty(t, LayerCreatedBy(history, 2))
+	assert.Empty(t, LayerCreatedBy(history, -1))
+	assert.Empty(t, LayerCreatedBy(nil, 0))
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -14,7 +13,6 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -64,6 +62,27 @@ func GetImageRegistry(imageName string) (string, error) {
 	return parsedRef.Context().RegistryStr(), nil
 }
 
+// GetImageRepository returns the repository of an image reference, including
+// its registry, e.g. index.docker.io/library/nginx. Like GetImageRegistry, it
+// fails for transports other than daemon-registry.
+func GetImageRepository(imageName string) (string, error) {
+	ref, err := ParseReference(imageName)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Transport != TransportDaemonRegistry {
+		return "", fmt.Errorf("repository not applicable for %s transport", ref.Transport)
+	}
+
+	parsedRef, err := name.ParseReference(ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the reference: %w", err)
+	}
+
+	return parsedRef.Context().Name(), nil
+}
+
 // GetLocalImage retrieves the local image from a reference name
 func GetLocalImage(ctx context.Context, imageName string) (cr.Image, error) {
 	ref, err := name.ParseReference(imageName)
@@ -81,23 +100,28 @@ func GetLocalImage(ctx context.Context, imageName string) (cr.Image, error) {
 
 // GetRemoteImage retrieves the remote image from a reference name.
 // Transient errors (network timeouts, HTTP 429/5xx) are retried up to
-// maxRetries times with exponential backoff.
+// maxRetries times with exponential backoff. When the registry has mirrors
+// (see SetRegistryMirrors), each attempt fails over from one mirror to the
+// next, and then to the registry, on transient errors.
 func GetRemoteImage(ctx context.Context, imageName string) (cr.Image, error) {
-	ref, err := name.ParseReference(imageName)
+	ref, err := parseRemoteReference(imageName)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing the reference: %w", err)
 	}
 
+	opts := remoteOptions(ctx)
+	if selectedPlatform != nil {
+		opts = append(opts, remote.WithPlatform(*selectedPlatform))
+	}
 	img, err := retryWithBackoff(ctx, maxRetries, retryBaseWait, func() (cr.Image, error) {
-		return remote.Image(ref,
-			remote.WithAuthFromKeychain(activeKeychain),
-			remote.WithTransport(remoteTransport),
-			remote.WithContext(ctx))
+		return fetchWithFailover(ref, imageName, func(r name.Reference) (cr.Image, error) {
+			return remote.Image(r, opts...)
+		})
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving the remote image: %w", err)
 	}
-	return img, nil
+	return withLayerCache(img), nil
 }
 
 // retryWithBackoff calls fn up to attempts+1 times, backing off exponentially
@@ -166,7 +190,7 @@ func isRetryableError(err error) bool {
 // A non-empty tag is required; use the format docker-archive:/path.tar:tag.
 func GetDockerArchiveImage(tarballPath string, tag string) (cr.Image, error) {
 	if tag == "" {
-		return nil, fmt.Errorf("docker-archive transport requires a tag (e.g., docker-archive:/path.tar:tag)")
+		return nil, withKind(ErrorKindInvalidReference, fmt.Errorf("docker-archive transport requires a tag (e.g., docker-archive:/path.tar:tag)"))
 	}
 
 	parsedTag, err := name.NewTag(tag)
@@ -174,28 +198,33 @@ func GetDockerArchiveImage(tarballPath string, tag string) (cr.Image, error) {
 		return nil, fmt.Errorf("error parsing tag %s: %w", tag, err)
 	}
 
-	// Load image from tarball
-	image, err := tarball.ImageFromPath(tarballPath, &parsedTag)
+	// Load image from tarball, picking the selected platform when the tag
+	// names one image per platform
+	image, err := selectDockerArchiveImage(tarballPath, parsedTag)
 	if err != nil {
 		return nil, fmt.Errorf("error loading docker archive from %s: %w", tarballPath, err)
 	}
+	if err := checkPlatform(image); err != nil {
+		return nil, err
+	}
 
 	return image, nil
 }
 
 // GetOCIArchiveImage retrieves an image from an OCI tarball.
 // The caller must call the returned cleanup function when done with the image
-// to remove the temporary directory created during extraction.
+// to remove the temporary directory created during extraction. Images loaded
+// from the same archive share the extraction, which is removed once all of
+// them are cleaned up (see RetainOCIArchives).
 func GetOCIArchiveImage(tarballPath string, reference string) (cr.Image, func(), error) {
 	// OCI archives need to be extracted to a temporary directory first
 	// then loaded using the OCI layout functions.
 	// v1.Image is lazy, so the temp dir must remain on disk until the caller
 	// is done accessing the image; cleanup is the caller's responsibility.
-	tempDir, err := extractOCIArchive(tarballPath)
+	tempDir, cleanup, err := acquireOCIArchive(tarballPath)
 	if err != nil {
 		return nil, func() {}, fmt.Errorf("error extracting OCI archive: %w", err)
 	}
-	cleanup := func() { _ = os.RemoveAll(tempDir) }
 
 	img, err := GetOCILayoutImage(tempDir, reference)
 	if err != nil {
@@ -207,11 +236,20 @@ func GetOCIArchiveImage(tarballPath string, reference string) (cr.Image, func(),
 
 // GetImage retrieves the image using transport-aware reference parsing.
 // The caller must call the returned cleanup function when done with the image.
-// For all transports except oci-archive, cleanup does nothing.
+// For all transports except oci-archive, cleanup does nothing. Errors carry
+// an ErrorKind when the failure has a known cause (see ErrorKindOf).
 func GetImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
+	img, cleanup, err := getImage(ctx, imageName)
+	if err != nil {
+		return nil, cleanup, classifyImageError(err)
+	}
+	return img, cleanup, nil
+}
+
+func getImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
 	ref, err := ParseReference(imageName)
 	if err != nil {
-		return nil, func() {}, err
+		return nil, func() {}, withKind(ErrorKindInvalidReference, err)
 	}
 
 	switch ref.Transport {
@@ -222,12 +260,15 @@ func GetImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
 			reference = ref.Tag
 		}
 		if reference == "" {
-			return nil, func() {}, fmt.Errorf("oci transport requires tag or digest")
+			return nil, func() {}, withKind(ErrorKindInvalidReference, fmt.Errorf("oci transport requires tag or digest"))
 		}
 		img, err := GetOCILayoutImage(ref.Path, reference)
 		if err != nil {
 			return nil, func() {}, err
 		}
+		if err := checkRunnable(img); err != nil {
+			return nil, func() {}, err
+		}
 		return img, func() {}, nil
 
 	case TransportOCIArchive:
@@ -237,9 +278,17 @@ func GetImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
 			reference = ref.Tag
 		}
 		if reference == "" {
-			return nil, func() {}, fmt.Errorf("oci-archive transport requires tag or digest")
+			return nil, func() {}, withKind(ErrorKindInvalidReference, fmt.Errorf("oci-archive transport requires tag or digest"))
 		}
-		return GetOCIArchiveImage(ref.Path, reference)
+		img, cleanup, err := GetOCIArchiveImage(ref.Path, reference)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		if err := checkRunnable(img); err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		return img, cleanup, nil
 
 	case TransportDockerArchive:
 		// Docker tarball - load directly.
@@ -253,7 +302,12 @@ func GetImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
 		// Default mode: try local daemon, fall back to remote registry
 		image, err := getLocalImageFn(ctx, ref.Path)
 		if err == nil {
-			return image, func() {}, nil
+			// The daemon stores one platform per image; skip it when it is
+			// not the selected one.
+			if err = checkPlatform(image); err == nil {
+				return image, func() {}, nil
+			}
+			log.WithField("error", err).Debug("Local image skipped, falling back to the registry")
 		}
 		// Honour context cancellation: do not attempt the remote fallback
 		// if the context was cancelled while the daemon call was in progress.
@@ -264,6 +318,11 @@ func GetImage(ctx context.Context, imageName string) (cr.Image, func(), error) {
 		if err != nil {
 			return nil, func() {}, err
 		}
+		// The daemon only stores container images, but registries also hold
+		// other artifacts.
+		if err := checkRunnable(image); err != nil {
+			return nil, func() {}, err
+		}
 		return image, func() {}, nil
 
 	default:
@@ -277,6 +336,9 @@ func GetImageConfig(image cr.Image) (*cr.ConfigFile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving the image configuration: %w", err)
 	}
+	if config == nil {
+		return nil, withKind(ErrorKindNotRunnable, fmt.Errorf("not a runnable container image: the image has no configuration"))
+	}
 
 	return config, nil
 }
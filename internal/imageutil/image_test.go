@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +71,32 @@ func TestRemoteTransport_IsConfigured(t *testing.T) {
 	assert.Equal(t, 30*time.Second, transport.ResponseHeaderTimeout)
 }
 
+func TestGetImageRepository(t *testing.T) {
+	tests := []struct {
+		imageName string
+		want      string
+		wantErr   bool
+	}{
+		{imageName: "nginx:latest", want: "index.docker.io/library/nginx"},
+		{imageName: "docker.io/org/app:1.0", want: "index.docker.io/org/app"},
+		{imageName: "ghcr.io/internal/team/app@sha256:" + strings.Repeat("a", 64), want: "ghcr.io/internal/team/app"},
+		{imageName: "localhost:5000/app", want: "localhost:5000/app"},
+		{imageName: "oci:/path/to/layout:latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageName, func(t *testing.T) {
+			got, err := GetImageRepository(tt.imageName)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestGetImageRegistry(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -761,12 +788,12 @@ func TestGetOCIArchiveImage_CleanupRemovesTempDir(t *testing.T) {
 	require.NoError(t, err)
 
 	// Find the extracted temp dir that was created by GetOCIArchiveImage.
-	// We identify it by listing oci-archive-* entries in os.TempDir() before
+	// We identify it by listing check-image-oci-archive-* entries in os.TempDir() before
 	// and after cleanup so the test remains independent of implementation details.
-	pattern := filepath.Join(os.TempDir(), "oci-archive-*")
+	pattern := filepath.Join(os.TempDir(), "check-image-oci-archive-*")
 	beforeDirs, err := filepath.Glob(pattern)
 	require.NoError(t, err)
-	require.NotEmpty(t, beforeDirs, "expected at least one oci-archive-* temp dir to exist before cleanup")
+	require.NotEmpty(t, beforeDirs, "expected at least one check-image-oci-archive-* temp dir to exist before cleanup")
 
 	// Call cleanup and verify all previously found dirs are gone
 	cleanup()
@@ -0,0 +1,187 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	log "github.com/sirupsen/logrus"
+)
+
+// IndexSize is the aggregate storage of an image across all the platforms of
+// its index.
+type IndexSize struct {
+	// Platforms is the number of platform images. Attestation manifests
+	// (platform unknown/unknown) are stored but not counted as platforms.
+	Platforms int
+	// Bytes is the sum of the unique config and layer blobs of every
+	// manifest in the index. Blobs shared between platforms count once.
+	Bytes int64
+}
+
+// GetIndexSize computes the total blob size of every platform of imageName.
+// Registry references and OCI layouts are read as stored, without resolving
+// them to a single platform; only manifests are fetched, never layers. When
+// the reference is a single-platform image, or comes from a transport that
+// stores one platform (Docker daemon, docker-archive, oci-archive), the total
+// is that image's size.
+func GetIndexSize(ctx context.Context, imageName string) (*IndexSize, error) {
+	ref, err := ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &blobSizer{seen: make(map[cr.Hash]bool)}
+	switch ref.Transport {
+	case TransportDaemonRegistry:
+		err = s.addRemote(ctx, ref.Path)
+		if err == nil {
+			return s.result(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.WithField("error", err).Debug("Remote index unavailable, using the local image size")
+	case TransportOCI:
+		reference := ref.Digest
+		if reference == "" {
+			reference = ref.Tag
+		}
+		if err := s.addLayout(ref.Path, reference); err != nil {
+			return nil, err
+		}
+		return s.result(), nil
+	}
+
+	img, cleanup, err := GetImage(ctx, imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if err := s.addImage(img, true); err != nil {
+		return nil, err
+	}
+	return s.result(), nil
+}
+
+type blobSizer struct {
+	seen      map[cr.Hash]bool
+	bytes     int64
+	platforms int
+}
+
+func (s *blobSizer) result() *IndexSize {
+	return &IndexSize{Platforms: s.platforms, Bytes: s.bytes}
+}
+
+func (s *blobSizer) addBlob(desc cr.Descriptor) {
+	if s.seen[desc.Digest] {
+		return
+	}
+	s.seen[desc.Digest] = true
+	s.bytes += desc.Size
+}
+
+func (s *blobSizer) addRemote(ctx context.Context, imageName string) error {
+	ref, err := parseRemoteReference(imageName)
+	if err != nil {
+		return fmt.Errorf("error parsing the reference: %w", err)
+	}
+	desc, err := remoteGet(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("error retrieving the remote image: %w", err)
+	}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return fmt.Errorf("error reading the image index: %w", err)
+		}
+		return s.addIndex(idx)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return fmt.Errorf("error reading the image: %w", err)
+	}
+	return s.addImage(img, true)
+}
+
+func (s *blobSizer) addLayout(layoutPath, reference string) error {
+	if reference == "" {
+		return fmt.Errorf("oci transport requires tag or digest")
+	}
+	path, err := layout.FromPath(layoutPath)
+	if err != nil {
+		return fmt.Errorf("error reading OCI layout: %w", err)
+	}
+
+	digest := reference
+	if _, err := cr.NewHash(reference); err != nil {
+		if digest, err = resolveTagInLayout(path, reference); err != nil {
+			return fmt.Errorf("error resolving tag: %w", err)
+		}
+	}
+	hash, err := cr.NewHash(digest)
+	if err != nil {
+		return fmt.Errorf("error parsing resolved digest: %w", err)
+	}
+
+	root, err := path.ImageIndex()
+	if err != nil {
+		return fmt.Errorf("error reading index: %w", err)
+	}
+	if idx, err := root.ImageIndex(hash); err == nil {
+		if mt, err := idx.MediaType(); err == nil && mt.IsIndex() {
+			return s.addIndex(idx)
+		}
+	}
+	img, err := root.Image(hash)
+	if err != nil {
+		return fmt.Errorf("error retrieving image from layout: %w", err)
+	}
+	return s.addImage(img, true)
+}
+
+func (s *blobSizer) addIndex(idx cr.ImageIndex) error {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("error reading the image index: %w", err)
+	}
+	for _, desc := range manifest.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return fmt.Errorf("error reading nested index %s: %w", desc.Digest, err)
+			}
+			if err := s.addIndex(child); err != nil {
+				return err
+			}
+		case desc.MediaType.IsImage():
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return fmt.Errorf("error reading manifest %s: %w", desc.Digest, err)
+			}
+			isPlatform := desc.Platform == nil || desc.Platform.OS != "unknown"
+			if err := s.addImage(img, isPlatform); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *blobSizer) addImage(img cr.Image, isPlatform bool) error {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("error reading the image manifest: %w", err)
+	}
+	if isPlatform {
+		s.platforms++
+	}
+	s.addBlob(manifest.Config)
+	for _, l := range manifest.Layers {
+		s.addBlob(l)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package imageutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blobBytes returns the sum of the config and layer sizes of img.
+func blobBytes(t *testing.T, img cr.Image) int64 {
+	t.Helper()
+	m, err := img.Manifest()
+	require.NoError(t, err)
+	total := m.Config.Size
+	for _, l := range m.Layers {
+		total += l.Size
+	}
+	return total
+}
+
+// newPlatformIndex returns an index with an amd64 and an arm64 image, an
+// attestation manifest, and the arm64 image listed again as arm64/v8 (shared
+// blobs), together with the expected total blob size.
+func newPlatformIndex(t *testing.T) (cr.ImageIndex, int64) {
+	t.Helper()
+	amd64, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	arm64, err := random.Image(2048, 1)
+	require.NoError(t, err)
+	attestation, err := random.Image(128, 1)
+	require.NoError(t, err)
+
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: cr.Descriptor{Platform: &cr.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: cr.Descriptor{Platform: &cr.Platform{OS: "linux", Architecture: "arm64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: cr.Descriptor{Platform: &cr.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}}},
+		mutate.IndexAddendum{Add: attestation, Descriptor: cr.Descriptor{Platform: &cr.Platform{OS: "unknown", Architecture: "unknown"}}},
+	)
+	return idx, blobBytes(t, amd64) + blobBytes(t, arm64) + blobBytes(t, attestation)
+}
+
+func TestGetIndexSize_RemoteIndex(t *testing.T) {
+	host := newTestRegistry(t)
+	idx, want := newPlatformIndex(t)
+	ref, err := name.ParseReference(host + "/multi/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(ref, idx))
+
+	got, err := GetIndexSize(context.Background(), ref.String())
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Bytes, "shared blobs are counted once")
+	assert.Equal(t, 3, got.Platforms, "attestations are not platforms")
+}
+
+func TestGetIndexSize_RemoteImage(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(1024, 3)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/single/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	got, err := GetIndexSize(context.Background(), ref.String())
+	require.NoError(t, err)
+	assert.Equal(t, blobBytes(t, img), got.Bytes)
+	assert.Equal(t, 1, got.Platforms)
+}
+
+func TestGetIndexSize_OCILayoutIndex(t *testing.T) {
+	idx, want := newPlatformIndex(t)
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx, layout.WithAnnotations(map[string]string{
+		ociRefNameAnnotation: "v1",
+	})))
+
+	got, err := GetIndexSize(context.Background(), "oci:"+dir+":v1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Bytes)
+	assert.Equal(t, 3, got.Platforms)
+}
+
+func TestGetIndexSize_OCILayoutImage(t *testing.T) {
+	img, err := random.Image(512, 2)
+	require.NoError(t, err)
+
+	got, err := GetIndexSize(context.Background(), "oci:"+writeTestLayout(t, img)+":v1")
+	require.NoError(t, err)
+	assert.Equal(t, blobBytes(t, img), got.Bytes)
+	assert.Equal(t, 1, got.Platforms)
+}
+
+func TestGetIndexSize_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		wantErr string
+	}{
+		{name: "Missing layout", image: "oci:/nonexistent/layout:v1", wantErr: "error reading OCI layout"},
+		{name: "Missing tag", image: "oci:" + t.TempDir(), wantErr: "oci transport requires tag or digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetIndexSize(context.Background(), tt.image)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
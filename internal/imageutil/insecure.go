@@ -0,0 +1,128 @@
+package imageutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// insecureRegistries are the registries (host[:port]) reached over plain
+// HTTP, or over TLS without certificate verification, keyed as returned by
+// name.Registry.RegistryStr. Every other registry requires verified TLS.
+var insecureRegistries map[string]bool
+
+// insecureRemoteTransport is remoteTransport without certificate
+// verification, used for the insecure registries only.
+var insecureRemoteTransport http.RoundTripper
+
+// SetInsecureRegistries marks registries, given as host[:port] (e.g.
+// dev-registry:5000), as insecure: they are tried over HTTPS without
+// certificate verification and then over plain HTTP, like the
+// insecure-registries of the Docker daemon. An empty list clears them.
+func SetInsecureRegistries(registries []string) error {
+	if len(registries) == 0 {
+		insecureRegistries = nil
+		insecureRemoteTransport = nil
+		return nil
+	}
+	hosts := make(map[string]bool, len(registries))
+	for _, r := range registries {
+		host, err := NormalizeRegistry(r)
+		if err != nil {
+			return err
+		}
+		hosts[host] = true
+	}
+	insecureRegistries = hosts
+	insecureRemoteTransport = skipVerify(remoteTransport)
+	return nil
+}
+
+// NormalizeRegistry validates a registry given as host[:port] and returns it
+// as go-containerregistry names it, e.g. index.docker.io for docker.io.
+func NormalizeRegistry(registry string) (string, error) {
+	registry = strings.TrimSpace(registry)
+	if registry == "" || strings.Contains(registry, "/") {
+		return "", fmt.Errorf("invalid insecure registry %q, expected host[:port]", registry)
+	}
+	reg, err := name.NewRegistry(registry, name.StrictValidation)
+	if err != nil {
+		return "", fmt.Errorf("invalid insecure registry %q: %w", registry, err)
+	}
+	return reg.RegistryStr(), nil
+}
+
+// InsecureRegistries returns the insecure registries, sorted.
+func InsecureRegistries() []string {
+	hosts := make([]string, 0, len(insecureRegistries))
+	for h := range insecureRegistries {
+		hosts = append(hosts, h)
+	}
+	slices.Sort(hosts)
+	return hosts
+}
+
+// parseRemoteReference parses a registry reference, marking it insecure when
+// its registry is, so go-containerregistry falls back to plain HTTP for it.
+func parseRemoteReference(s string) (name.Reference, error) {
+	ref, err := name.ParseReference(s)
+	if err != nil || !insecureRegistries[ref.Context().RegistryStr()] {
+		return ref, err
+	}
+	return name.ParseReference(s, name.Insecure)
+}
+
+// parseRemoteRepository parses a registry repository like
+// parseRemoteReference.
+func parseRemoteRepository(s string) (name.Repository, error) {
+	repo, err := name.NewRepository(s)
+	if err != nil || !insecureRegistries[repo.RegistryStr()] {
+		return repo, err
+	}
+	return name.NewRepository(s, name.Insecure)
+}
+
+// insecureTransport routes the requests to insecure registries through a
+// transport that skips certificate verification, and all other requests
+// through the strict one. Token endpoints on other hosts stay strict.
+type insecureTransport struct {
+	strict   http.RoundTripper
+	insecure http.RoundTripper
+	hosts    map[string]bool
+}
+
+func (t *insecureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hosts[req.URL.Host] {
+		return t.insecure.RoundTrip(req)
+	}
+	return t.strict.RoundTrip(req)
+}
+
+// skipVerify returns a copy of base that accepts any certificate, or base
+// itself when it is not an *http.Transport whose TLS settings can be changed.
+func skipVerify(base http.RoundTripper) http.RoundTripper {
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.InsecureSkipVerify = true // #nosec G402 -- only used for the registries listed as insecure by the user
+	return t
+}
+
+// withInsecureRegistries wraps base so that requests to the insecure
+// registries accept any certificate. base is returned as is when there are
+// none.
+func withInsecureRegistries(base http.RoundTripper) http.RoundTripper {
+	if len(insecureRegistries) == 0 {
+		return base
+	}
+	return &insecureTransport{strict: base, insecure: insecureRemoteTransport, hosts: insecureRegistries}
+}
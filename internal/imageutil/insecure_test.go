@@ -0,0 +1,85 @@
+package imageutil
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useInsecureRegistries(t *testing.T, registries ...string) {
+	t.Helper()
+	require.NoError(t, SetInsecureRegistries(registries))
+	t.Cleanup(func() { require.NoError(t, SetInsecureRegistries(nil)) })
+}
+
+func TestSetInsecureRegistries(t *testing.T) {
+	useInsecureRegistries(t, "dev-registry:5000", " docker.io ")
+	assert.Equal(t, []string{"dev-registry:5000", "index.docker.io"}, InsecureRegistries())
+
+	for _, invalid := range []string{"", "dev-registry:5000/org", "http://dev-registry", "bad host"} {
+		err := SetInsecureRegistries([]string{invalid})
+		require.Error(t, err, invalid)
+		assert.Contains(t, err.Error(), "invalid insecure registry")
+	}
+
+	require.NoError(t, SetInsecureRegistries(nil))
+	assert.Empty(t, InsecureRegistries())
+}
+
+func TestParseRemoteReference(t *testing.T) {
+	useInsecureRegistries(t, "dev-registry:5000")
+
+	ref, err := parseRemoteReference("dev-registry:5000/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "http", ref.Context().Scheme(), "insecure registries fall back to plain HTTP")
+
+	ref, err = parseRemoteReference("registry.example.com/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "https", ref.Context().Scheme(), "other registries stay strict")
+
+	repo, err := parseRemoteRepository("dev-registry:5000/org/app")
+	require.NoError(t, err)
+	assert.Equal(t, "http", repo.Scheme())
+
+	_, err = parseRemoteReference("INVALID//ref")
+	require.Error(t, err)
+}
+
+func TestInsecureRegistries_SelfSignedTLS(t *testing.T) {
+	server := httptest.NewTLSServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	img, err := random.Image(512, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/org/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img, remote.WithTransport(server.Client().Transport)))
+
+	_, err = GetRemoteImage(context.Background(), ref.String())
+	require.Error(t, err, "the certificate of a registry that is not insecure is verified")
+
+	useInsecureRegistries(t, host)
+	got, err := GetRemoteImage(context.Background(), ref.String())
+	require.NoError(t, err)
+	want, err := img.Digest()
+	require.NoError(t, err)
+	gotDigest, err := got.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, want, gotDigest)
+
+	tagged, err := ListRepository(context.Background(), host+"/org/app")
+	require.NoError(t, err, "repository listings use the insecure transport too")
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "1.0", tagged[0].Tag)
+}
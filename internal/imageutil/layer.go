@@ -0,0 +1,69 @@
+package imageutil
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+)
+
+// Magic numbers of the layer compression formats.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// OpenLayer returns the tar stream of a layer. Not every layer implementation
+// decompresses zstd (docker save tarballs only detect gzip), so the stream
+// returned by Uncompressed is decompressed once more when it still starts
+// with a gzip or zstd header. Uncompressed tar layers are read as is.
+func OpenLayer(layer cr.Layer) (io.ReadCloser, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(rc)
+	// A short read means a layer smaller than the header, which is an
+	// uncompressed (empty or truncated) tar stream.
+	header, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(header, zstdMagic):
+		logLayerCompression(layer, "zstd")
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("error reading zstd layer: %w", err), rc.Close())
+		}
+		return &layerReader{Reader: zr, close: func() error { zr.Close(); return rc.Close() }}, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		logLayerCompression(layer, "gzip")
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("error reading gzip layer: %w", err), rc.Close())
+		}
+		return &layerReader{Reader: zr, close: func() error { return errors.Join(zr.Close(), rc.Close()) }}, nil
+	default:
+		return &layerReader{Reader: br, close: rc.Close}, nil
+	}
+}
+
+func logLayerCompression(layer cr.Layer, compression string) {
+	mediaType, _ := layer.MediaType()
+	log.WithFields(log.Fields{"media-type": mediaType, "compression": compression}).Debug("Decompressing layer not decompressed by its source")
+}
+
+// layerReader reads a decompressed layer and closes both the decompressor
+// and the underlying stream.
+type layerReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *layerReader) Close() error { return r.close() }
@@ -0,0 +1,257 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+)
+
+// registryMirrors maps upstream registries, keyed as returned by
+// name.Registry.RegistryStr, to their mirrors in the order they are tried.
+var registryMirrors map[string][]string
+
+// servedBy records, for the registry images of upstreams with mirrors, the
+// endpoint that served them, keyed by the reference they were requested with.
+var servedBy sync.Map
+
+// SetRegistryMirrors configures the mirrors of upstream registries. Each
+// upstream (host[:port], e.g. docker.io) maps to its mirrors, given as
+// host[:port] optionally followed by a path the repositories are nested
+// under (e.g. registry.example.com/dockerhub). Images of an upstream are
+// requested from its mirrors in order and then from the upstream itself,
+// moving on to the next endpoint on network errors and HTTP 429/5xx. Empty
+// mirrors clear them.
+func SetRegistryMirrors(mirrors map[string][]string) error {
+	servedBy.Clear()
+	normalized, err := normalizeRegistryMirrors(mirrors)
+	if err != nil {
+		return err
+	}
+	registryMirrors = normalized
+	return nil
+}
+
+// ValidateRegistryMirrors checks the upstreams and mirrors of mirrors as
+// SetRegistryMirrors does, without configuring them.
+func ValidateRegistryMirrors(mirrors map[string][]string) error {
+	_, err := normalizeRegistryMirrors(mirrors)
+	return err
+}
+
+func normalizeRegistryMirrors(mirrors map[string][]string) (map[string][]string, error) {
+	if len(mirrors) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string][]string, len(mirrors))
+	for upstream, endpoints := range mirrors {
+		host, err := normalizeMirrorUpstream(upstream)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range endpoints {
+			mirror, err := NormalizeMirror(e)
+			if err != nil {
+				return nil, err
+			}
+			normalized[host] = append(normalized[host], mirror)
+		}
+	}
+	return normalized, nil
+}
+
+// ParseRegistryMirror parses a mirror given as upstream=mirror, e.g.
+// docker.io=mirror.gcr.io.
+func ParseRegistryMirror(s string) (string, string, error) {
+	upstream, mirror, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid registry mirror %q, expected upstream=mirror", s)
+	}
+	return strings.TrimSpace(upstream), strings.TrimSpace(mirror), nil
+}
+
+// NormalizeMirror validates a mirror given as host[:port][/path] and returns
+// it without surrounding spaces and slashes.
+func NormalizeMirror(mirror string) (string, error) {
+	mirror = strings.Trim(strings.TrimSpace(mirror), "/")
+	host, path, _ := strings.Cut(mirror, "/")
+	if _, err := name.NewRegistry(host, name.StrictValidation); err != nil || host == "" || strings.Contains(mirror, "://") {
+		return "", fmt.Errorf("invalid registry mirror %q, expected host[:port][/path]", mirror)
+	}
+	if path != "" {
+		if _, err := name.NewRepository(mirror, name.StrictValidation); err != nil {
+			return "", fmt.Errorf("invalid registry mirror %q: %w", mirror, err)
+		}
+	}
+	return mirror, nil
+}
+
+// normalizeMirrorUpstream validates an upstream registry of the mirrors and
+// returns it as go-containerregistry names it.
+func normalizeMirrorUpstream(upstream string) (string, error) {
+	upstream = strings.TrimSpace(upstream)
+	if upstream == "" || strings.Contains(upstream, "/") {
+		return "", fmt.Errorf("invalid mirrored registry %q, expected host[:port]", upstream)
+	}
+	reg, err := name.NewRegistry(upstream, name.StrictValidation)
+	if err != nil {
+		return "", fmt.Errorf("invalid mirrored registry %q: %w", upstream, err)
+	}
+	return reg.RegistryStr(), nil
+}
+
+// RegistryMirrors returns the mirrors of upstream, in the order they are
+// tried.
+func RegistryMirrors(upstream string) []string {
+	host, err := normalizeMirrorUpstream(upstream)
+	if err != nil {
+		return nil
+	}
+	return registryMirrors[host]
+}
+
+// ServedBy returns the endpoint, a mirror or the upstream registry, that
+// served the registry image imageName, or "" when its registry has no mirrors
+// or it was not retrieved from a registry.
+func ServedBy(imageName string) string {
+	ref, err := ParseReference(imageName)
+	if err != nil || ref.Transport != TransportDaemonRegistry {
+		return ""
+	}
+	if endpoint, ok := servedBy.Load(ref.Path); ok {
+		return endpoint.(string)
+	}
+	return ""
+}
+
+// ClearServedBy forgets the endpoint that served imageName, so that a new
+// validation of it does not report the endpoint of a previous one.
+func ClearServedBy(imageName string) {
+	if ref, err := ParseReference(imageName); err == nil {
+		servedBy.Delete(ref.Path)
+	}
+}
+
+// mirrorEndpoint is a reference of an image at one of the endpoints it can
+// be retrieved from.
+type mirrorEndpoint struct {
+	name string
+	ref  name.Reference
+}
+
+// mirrorEndpoints returns the endpoints of ref: the same repository and tag or
+// digest at each mirror of its registry, followed by ref itself.
+func mirrorEndpoints(ref name.Reference) ([]mirrorEndpoint, error) {
+	upstream := ref.Context().RegistryStr()
+	var endpoints []mirrorEndpoint
+	for _, mirror := range registryMirrors[upstream] {
+		sep := ":"
+		if _, ok := ref.(name.Digest); ok {
+			sep = "@"
+		}
+		mirrorRef, err := parseRemoteReference(mirror + "/" + ref.Context().RepositoryStr() + sep + ref.Identifier())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the reference at mirror %s: %w", mirror, err)
+		}
+		endpoints = append(endpoints, mirrorEndpoint{name: mirror, ref: mirrorRef})
+	}
+	return append(endpoints, mirrorEndpoint{name: upstream, ref: ref}), nil
+}
+
+// fetchWithFailover retrieves the image at each endpoint of ref in order with
+// fetch, as withFailover does, and records the endpoint that served it for
+// ServedBy under key.
+func fetchWithFailover(ref name.Reference, key string, fetch func(name.Reference) (cr.Image, error)) (cr.Image, error) {
+	img, endpoint, err := withFailover(ref, fetch)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != "" {
+		servedBy.Store(key, endpoint)
+		log.WithFields(log.Fields{"image": key, "endpoint": endpoint}).Debug("Image served by registry endpoint")
+	}
+	return img, nil
+}
+
+// withFailover calls fetch with ref at each of its endpoints in order, until
+// one succeeds or fails with an error other than a transient one, and returns
+// the name of the endpoint that succeeded, or "" when the registry of ref has
+// no mirrors. When every endpoint fails, the error of the last one is
+// returned, so that the caller can retry.
+func withFailover[T any](ref name.Reference, fetch func(name.Reference) (T, error)) (T, string, error) {
+	var zero T
+	if len(registryMirrors[ref.Context().RegistryStr()]) == 0 {
+		v, err := fetch(ref)
+		return v, "", err
+	}
+	endpoints, err := mirrorEndpoints(ref)
+	if err != nil {
+		return zero, "", err
+	}
+	var lastErr error
+	for i, e := range endpoints {
+		v, err := fetch(e.ref)
+		if err == nil {
+			return v, e.name, nil
+		}
+		if !isRetryableError(err) {
+			return zero, "", err
+		}
+		lastErr = err
+		if i < len(endpoints)-1 {
+			log.WithFields(log.Fields{"endpoint": e.name, "next": endpoints[i+1].name, "error": err}).Warn("Registry endpoint unavailable, trying the next one")
+		}
+	}
+	return zero, "", lastErr
+}
+
+// remoteGet is remote.Get through the mirrors of the registry of ref.
+func remoteGet(ctx context.Context, ref name.Reference) (*remote.Descriptor, error) {
+	desc, _, err := withFailover(ref, func(r name.Reference) (*remote.Descriptor, error) {
+		return remote.Get(r, remoteOptions(ctx)...)
+	})
+	return desc, err
+}
+
+// remoteHead is remote.Head through the mirrors of the registry of ref.
+func remoteHead(ctx context.Context, ref name.Reference) (*cr.Descriptor, error) {
+	desc, _, err := withFailover(ref, func(r name.Reference) (*cr.Descriptor, error) {
+		return remote.Head(r, remoteOptions(ctx)...)
+	})
+	return desc, err
+}
+
+// remoteImage is remote.Image through the mirrors of the registry of ref.
+func remoteImage(ctx context.Context, ref name.Reference) (cr.Image, error) {
+	img, _, err := withFailover(ref, func(r name.Reference) (cr.Image, error) {
+		return remote.Image(r, remoteOptions(ctx)...)
+	})
+	return img, err
+}
+
+// remoteReferrers is remote.Referrers through the mirrors of the registry of
+// subject.
+func remoteReferrers(ctx context.Context, subject name.Digest) (cr.ImageIndex, error) {
+	idx, _, err := withFailover(subject, func(r name.Reference) (cr.ImageIndex, error) {
+		d, ok := r.(name.Digest)
+		if !ok {
+			return nil, fmt.Errorf("referrers require a digest reference, got %s", r.Name())
+		}
+		return remote.Referrers(d, remoteOptions(ctx)...)
+	})
+	return idx, err
+}
+
+// remoteList is remote.List through the mirrors of the registry of repo. The
+// endpoints are those of a tag of repo; only their repository is listed.
+func remoteList(ctx context.Context, repo name.Repository) ([]string, error) {
+	tags, _, err := withFailover(repo.Tag(name.DefaultTag), func(r name.Reference) ([]string, error) {
+		return remote.List(r.Context(), remoteOptions(ctx)...)
+	})
+	return tags, err
+}
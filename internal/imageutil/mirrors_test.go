@@ -0,0 +1,189 @@
+package imageutil
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useRegistryMirrors(t *testing.T, mirrors map[string][]string) {
+	t.Helper()
+	require.NoError(t, SetRegistryMirrors(mirrors))
+	t.Cleanup(func() { require.NoError(t, SetRegistryMirrors(nil)) })
+}
+
+func TestSetRegistryMirrors(t *testing.T) {
+	useRegistryMirrors(t, map[string][]string{"docker.io": {" mirror.gcr.io ", "registry.example.com/dockerhub/"}})
+	assert.Equal(t, []string{"mirror.gcr.io", "registry.example.com/dockerhub"}, RegistryMirrors("index.docker.io"))
+	assert.Empty(t, RegistryMirrors("ghcr.io"))
+
+	tests := []struct {
+		mirrors   map[string][]string
+		wantError string
+	}{
+		{map[string][]string{"docker.io/library": {"mirror.gcr.io"}}, "invalid mirrored registry"},
+		{map[string][]string{"": {"mirror.gcr.io"}}, "invalid mirrored registry"},
+		{map[string][]string{"docker.io": {"https://mirror.gcr.io"}}, "invalid registry mirror"},
+		{map[string][]string{"docker.io": {""}}, "invalid registry mirror"},
+		{map[string][]string{"docker.io": {"mirror.gcr.io/Upper"}}, "invalid registry mirror"},
+	}
+	for _, tt := range tests {
+		err := ValidateRegistryMirrors(tt.mirrors)
+		require.Error(t, err, tt.mirrors)
+		assert.Contains(t, err.Error(), tt.wantError)
+	}
+
+	require.NoError(t, SetRegistryMirrors(nil))
+	assert.Empty(t, RegistryMirrors("docker.io"))
+}
+
+func TestParseRegistryMirror(t *testing.T) {
+	upstream, mirror, err := ParseRegistryMirror("docker.io = mirror.gcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io", upstream)
+	assert.Equal(t, "mirror.gcr.io", mirror)
+
+	_, _, err = ParseRegistryMirror("mirror.gcr.io")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected upstream=mirror")
+}
+
+func TestMirrorEndpoints(t *testing.T) {
+	useRegistryMirrors(t, map[string][]string{"docker.io": {"mirror.gcr.io", "registry.example.com/dockerhub"}})
+
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{"nginx:1.27", []string{
+			"mirror.gcr.io/library/nginx:1.27",
+			"registry.example.com/dockerhub/library/nginx:1.27",
+			"index.docker.io/library/nginx:1.27",
+		}},
+		{"docker.io/org/app@sha256:" + strings.Repeat("a", 64), []string{
+			"mirror.gcr.io/org/app@sha256:" + strings.Repeat("a", 64),
+			"registry.example.com/dockerhub/org/app@sha256:" + strings.Repeat("a", 64),
+			"index.docker.io/org/app@sha256:" + strings.Repeat("a", 64),
+		}},
+		{"ghcr.io/org/app:1.0", []string{"ghcr.io/org/app:1.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := name.ParseReference(tt.ref)
+			require.NoError(t, err)
+			endpoints, err := mirrorEndpoints(ref)
+			require.NoError(t, err)
+			var got []string
+			for _, e := range endpoints {
+				got = append(got, e.ref.Name())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetRemoteImage_MirrorFailover(t *testing.T) {
+	newRegistry := func() string {
+		server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+		t.Cleanup(server.Close)
+		return strings.TrimPrefix(server.URL, "http://")
+	}
+	upstream, mirror := newRegistry(), newRegistry()
+	// A closed server refuses connections, a transient network error.
+	down := httptest.NewServer(nil)
+	down.Close()
+	downHost := strings.TrimPrefix(down.URL, "http://")
+
+	img, err := random.Image(512, 1)
+	require.NoError(t, err)
+	want, err := img.Digest()
+	require.NoError(t, err)
+	for _, host := range []string{upstream, mirror} {
+		ref, err := name.ParseReference(host + "/org/app:1.0")
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+	imageName := upstream + "/org/app:1.0"
+
+	tests := []struct {
+		name    string
+		mirrors []string
+		want    string
+	}{
+		{"served by the first mirror", []string{mirror, downHost}, mirror},
+		{"fails over to the next mirror", []string{downHost, mirror}, mirror},
+		{"fails over to the upstream", []string{downHost}, upstream},
+		{"mirror without the image is not failed over", []string{newRegistry()}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRegistryMirrors(t, map[string][]string{upstream: tt.mirrors})
+
+			got, err := GetRemoteImage(context.Background(), imageName)
+			if tt.want == "" {
+				require.Error(t, err)
+				assert.Empty(t, ServedBy(imageName))
+				return
+			}
+			require.NoError(t, err)
+			gotDigest, err := got.Digest()
+			require.NoError(t, err)
+			assert.Equal(t, want, gotDigest)
+			assert.Equal(t, tt.want, ServedBy(imageName))
+
+			ClearServedBy(imageName)
+			assert.Empty(t, ServedBy(imageName))
+		})
+	}
+
+	require.NoError(t, SetRegistryMirrors(nil))
+	_, err = GetRemoteImage(context.Background(), imageName)
+	require.NoError(t, err)
+	assert.Empty(t, ServedBy(imageName), "the endpoint is only recorded for registries with mirrors")
+}
+
+func TestRegistryReads_MirrorFailover(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	mirror := strings.TrimPrefix(server.URL, "http://")
+	// The upstream refuses connections, so every read must go to the mirror.
+	down := httptest.NewServer(nil)
+	down.Close()
+	upstream := strings.TrimPrefix(down.URL, "http://")
+
+	img, err := random.Image(512, 1)
+	require.NoError(t, err)
+	want, err := img.Digest()
+	require.NoError(t, err)
+	ref, err := name.ParseReference(mirror + "/org/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+	useRegistryMirrors(t, map[string][]string{upstream: {mirror}})
+	ctx := context.Background()
+
+	tagged, err := ListRepository(ctx, upstream+"/org/app")
+	require.NoError(t, err)
+	assert.Equal(t, []TaggedDigest{{Tag: "1.0", Digest: want.String()}}, tagged)
+
+	desc, err := ResolveDescriptor(ctx, upstream+"/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, want, desc.Digest)
+
+	pinned, err := PinSource(ctx, upstream+"/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, upstream+"/org/app@"+want.String(), pinned.Ref, "the pinned reference names the upstream")
+
+	atts, err := GetAttestations(ctx, upstream+"/org/app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{want.String()}, atts.Digests)
+}
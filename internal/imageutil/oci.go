@@ -11,7 +11,9 @@ import (
 // human-readable reference name (tag) for an image in an OCI layout index.
 const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
 
-// GetOCILayoutImage loads an image from an OCI layout directory
+// GetOCILayoutImage loads an image from an OCI layout directory. A reference
+// to an image index resolves to the image of the selected platform (see
+// SetPlatform).
 func GetOCILayoutImage(layoutPath, reference string) (v1.Image, error) {
 	path, err := layout.FromPath(layoutPath)
 	if err != nil {
@@ -32,10 +34,23 @@ func GetOCILayoutImage(layoutPath, reference string) (v1.Image, error) {
 		}
 	}
 
-	image, err := path.Image(hash)
+	root, err := path.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("error reading index: %w", err)
+	}
+	if idx, err := root.ImageIndex(hash); err == nil {
+		if mt, err := idx.MediaType(); err == nil && mt.IsIndex() {
+			return selectIndexImage(idx)
+		}
+	}
+
+	image, err := root.Image(hash)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving image from layout: %w", err)
 	}
+	if err := checkPlatform(image); err != nil {
+		return nil, err
+	}
 
 	return image, nil
 }
@@ -62,5 +77,5 @@ func resolveTagInLayout(path layout.Path, tag string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("tag %q not found in layout index", tag)
+	return "", withKind(ErrorKindNotFound, fmt.Errorf("tag %q not found in layout index", tag))
 }
@@ -0,0 +1,221 @@
+package imageutil
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// selectedPlatform is the platform loaded from multi-platform images, or nil
+// when none was selected.
+var selectedPlatform *cr.Platform
+
+// SetPlatform selects the platform (os/arch[/variant]) to load from
+// multi-platform images. Registry images resolve to it, images of OCI layouts
+// and archives are picked from their index by it, and single-platform images
+// that do not match it are rejected. A local daemon image that does not match
+// it is skipped in favour of the registry. An empty platform clears the
+// selection.
+func SetPlatform(platform string) error {
+	if platform == "" {
+		selectedPlatform = nil
+		return nil
+	}
+	p, err := cr.ParsePlatform(platform)
+	if err != nil {
+		return fmt.Errorf("invalid platform %q: %w", platform, err)
+	}
+	if p.OS == "" || p.Architecture == "" {
+		return fmt.Errorf("invalid platform %q, expected os/arch[/variant]", platform)
+	}
+	selectedPlatform = p
+	return nil
+}
+
+// imagePlatform returns the platform an image was built for, from its
+// configuration.
+func imagePlatform(img cr.Image) (*cr.Platform, error) {
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the image configuration: %w", err)
+	}
+	if p := config.Platform(); p != nil {
+		return p, nil
+	}
+	return &cr.Platform{}, nil
+}
+
+// checkPlatform returns an error when img was not built for the selected
+// platform.
+func checkPlatform(img cr.Image) error {
+	if selectedPlatform == nil {
+		return nil
+	}
+	p, err := imagePlatform(img)
+	if err != nil {
+		return err
+	}
+	if !p.Satisfies(*selectedPlatform) {
+		return fmt.Errorf("image platform %s does not match --platform %s", p, selectedPlatform)
+	}
+	return nil
+}
+
+// selectIndexImage returns the image of idx for the selected platform.
+// Without a selected platform, idx must hold a single platform image;
+// attestation manifests (platform unknown/unknown) are never selected.
+func selectIndexImage(idx cr.ImageIndex) (cr.Image, error) {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the image index: %w", err)
+	}
+
+	var candidates []cr.Image
+	var platforms []string
+	for _, desc := range manifest.Manifests {
+		if !desc.MediaType.IsImage() || desc.Platform != nil && desc.Platform.OS == "unknown" {
+			continue
+		}
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest %s: %w", desc.Digest, err)
+		}
+		p := desc.Platform
+		if p == nil {
+			if p, err = imagePlatform(img); err != nil {
+				return nil, err
+			}
+		}
+		if selectedPlatform != nil && p.Satisfies(*selectedPlatform) {
+			return img, nil
+		}
+		candidates = append(candidates, img)
+		platforms = append(platforms, p.String())
+	}
+	return selectCandidate(candidates, platforms, "image index")
+}
+
+// selectCandidate returns the only candidate image when no platform is
+// selected, and otherwise reports why none could be chosen.
+func selectCandidate(candidates []cr.Image, platforms []string, source string) (cr.Image, error) {
+	switch {
+	case len(candidates) == 0:
+		return nil, fmt.Errorf("%s holds no platform images", source)
+	case selectedPlatform != nil:
+		return nil, fmt.Errorf("%s holds no image for platform %s (available: %s)", source, selectedPlatform, strings.Join(platforms, ", "))
+	case len(candidates) > 1:
+		return nil, fmt.Errorf("%s holds %d platforms (%s), select one with --platform", source, len(candidates), strings.Join(platforms, ", "))
+	default:
+		return candidates[0], nil
+	}
+}
+
+// selectDockerArchiveImage loads the image tagged tag from a docker save
+// tarball that lists several images under that tag, one per platform, such as
+// those saved from a multi-platform image store.
+func selectDockerArchiveImage(tarballPath string, tag name.Tag) (cr.Image, error) {
+	opener := func() (io.ReadCloser, error) { return os.Open(tarballPath) }
+	manifest, err := tarball.LoadManifest(opener)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries tarball.Manifest
+	for _, entry := range manifest {
+		for _, t := range entry.RepoTags {
+			if repoTag, err := name.NewTag(t); err == nil && repoTag.Name() == tag.Name() {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	if len(entries) < 2 {
+		img, err := tarball.ImageFromPath(tarballPath, &tag)
+		if err != nil && len(entries) == 0 {
+			return nil, withKind(ErrorKindNotFound, err)
+		}
+		return img, err
+	}
+
+	var candidates []cr.Image
+	var platforms []string
+	for _, entry := range entries {
+		img, err := tarball.Image(manifestEntryOpener(tarballPath, entry), nil)
+		if err != nil {
+			return nil, err
+		}
+		p, err := imagePlatform(img)
+		if err != nil {
+			return nil, err
+		}
+		if selectedPlatform != nil && p.Satisfies(*selectedPlatform) {
+			return img, nil
+		}
+		candidates = append(candidates, img)
+		platforms = append(platforms, p.String())
+	}
+	return selectCandidate(candidates, platforms, "tag "+tag.String())
+}
+
+// manifestEntryOpener opens a docker save tarball whose manifest.json lists
+// only entry, so that tarball.Image loads that image.
+func manifestEntryOpener(tarballPath string, entry tarball.Descriptor) tarball.Opener {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(tarballPath)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := json.Marshal(tarball.Manifest{entry})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			defer f.Close()
+			pw.CloseWithError(rewriteManifest(f, pw, manifest))
+		}()
+		return pr, nil
+	}
+}
+
+// rewriteManifest copies the tar stream in to out, replacing the content of
+// manifest.json.
+func rewriteManifest(in io.Reader, out io.Writer, manifest []byte) error {
+	tr := tar.NewReader(in)
+	tw := tar.NewWriter(out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name == "manifest.json" {
+			hdr.Size = int64(len(manifest))
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(manifest); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		// #nosec G110 -- entries are copied as is, not decompressed
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
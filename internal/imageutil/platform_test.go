@@ -0,0 +1,186 @@
+package imageutil
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// platformImage returns a random image whose configuration declares the
+// given platform.
+func platformImage(t *testing.T, osName, arch string) v1.Image {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	cfg, err := img.ConfigFile()
+	require.NoError(t, err)
+	cfg = cfg.DeepCopy()
+	cfg.OS = osName
+	cfg.Architecture = arch
+	img, err = mutate.ConfigFile(img, cfg)
+	require.NoError(t, err)
+	return img
+}
+
+func setPlatform(t *testing.T, platform string) {
+	t.Helper()
+	require.NoError(t, SetPlatform(platform))
+	t.Cleanup(func() { selectedPlatform = nil })
+}
+
+func digestOf(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	d, err := img.Digest()
+	require.NoError(t, err)
+	return d
+}
+
+func TestSetPlatform(t *testing.T) {
+	t.Cleanup(func() { selectedPlatform = nil })
+
+	require.NoError(t, SetPlatform("linux/arm64/v8"))
+	require.NotNil(t, selectedPlatform)
+	assert.Equal(t, "linux/arm64/v8", selectedPlatform.String())
+
+	require.NoError(t, SetPlatform(""))
+	assert.Nil(t, selectedPlatform)
+
+	err := SetPlatform("linux")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected os/arch[/variant]")
+}
+
+func TestGetOCILayoutImage_MultiPlatformIndex(t *testing.T) {
+	amd64 := platformImage(t, "linux", "amd64")
+	arm64 := platformImage(t, "linux", "arm64")
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+		mutate.IndexAddendum{Add: platformImage(t, "unknown", "unknown"), Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}}},
+	)
+	layoutPath := t.TempDir()
+	p, err := layout.Write(layoutPath, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "1.0"})))
+
+	t.Run("no platform selected", func(t *testing.T) {
+		_, err := GetOCILayoutImage(layoutPath, "1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "image index holds 2 platforms (linux/amd64, linux/arm64), select one with --platform")
+	})
+
+	t.Run("selected platform", func(t *testing.T) {
+		setPlatform(t, "linux/arm64")
+		img, err := GetOCILayoutImage(layoutPath, "1.0")
+		require.NoError(t, err)
+		assert.Equal(t, digestOf(t, arm64), digestOf(t, img))
+	})
+
+	t.Run("missing platform", func(t *testing.T) {
+		setPlatform(t, "linux/s390x")
+		_, err := GetOCILayoutImage(layoutPath, "1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no image for platform linux/s390x (available: linux/amd64, linux/arm64)")
+	})
+}
+
+func TestGetOCILayoutImage_PlatformMismatch(t *testing.T) {
+	layoutPath := t.TempDir()
+	p, err := layout.Write(layoutPath, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(platformImage(t, "linux", "amd64"), layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "1.0"})))
+
+	setPlatform(t, "linux/amd64")
+	_, err = GetOCILayoutImage(layoutPath, "1.0")
+	require.NoError(t, err)
+
+	setPlatform(t, "linux/arm64")
+	_, err = GetOCILayoutImage(layoutPath, "1.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "image platform linux/amd64 does not match --platform linux/arm64")
+}
+
+func TestGetDockerArchiveImage_MultiPlatform(t *testing.T) {
+	amd64 := platformImage(t, "linux", "amd64")
+	arm64 := platformImage(t, "linux", "arm64")
+	amdTag, err := name.NewTag("test/app:amd64")
+	require.NoError(t, err)
+	armTag, err := name.NewTag("test/app:arm64")
+	require.NoError(t, err)
+
+	// Write both images, then list them under the same tag, as a multi-platform
+	// image store does.
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.tar")
+	require.NoError(t, tarball.MultiWriteToFile(src, map[name.Tag]v1.Image{amdTag: amd64, armTag: arm64}))
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(src) })
+	require.NoError(t, err)
+	for i := range manifest {
+		manifest[i].RepoTags = []string{"test/app:1.0"}
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	in, err := os.Open(src)
+	require.NoError(t, err)
+	defer in.Close()
+	archive := filepath.Join(dir, "app.tar")
+	out, err := os.Create(archive)
+	require.NoError(t, err)
+	require.NoError(t, rewriteManifest(in, out, data))
+	require.NoError(t, out.Close())
+
+	t.Run("no platform selected", func(t *testing.T) {
+		_, err := GetDockerArchiveImage(archive, "test/app:1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "holds 2 platforms")
+		assert.Contains(t, err.Error(), "select one with --platform")
+	})
+
+	t.Run("selected platform", func(t *testing.T) {
+		setPlatform(t, "linux/arm64")
+		img, err := GetDockerArchiveImage(archive, "test/app:1.0")
+		require.NoError(t, err)
+		assert.Equal(t, digestOf(t, arm64), digestOf(t, img))
+		_, err = img.Layers()
+		require.NoError(t, err)
+	})
+
+	t.Run("single image of another platform", func(t *testing.T) {
+		setPlatform(t, "linux/arm64")
+		_, err := GetDockerArchiveImage(src, "test/app:amd64")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match --platform linux/arm64")
+	})
+}
+
+func TestGetImage_DaemonPlatformMismatchFallsBack(t *testing.T) {
+	local := platformImage(t, "linux", "amd64")
+	remote := platformImage(t, "linux", "arm64")
+
+	origLocal, origRemote := getLocalImageFn, getRemoteImageFn
+	t.Cleanup(func() { getLocalImageFn, getRemoteImageFn = origLocal, origRemote })
+	getLocalImageFn = func(_ context.Context, _ string) (v1.Image, error) { return local, nil }
+	getRemoteImageFn = func(_ context.Context, _ string) (v1.Image, error) { return remote, nil }
+
+	img, _, err := GetImage(context.Background(), "app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, digestOf(t, local), digestOf(t, img), "the daemon image is used without --platform")
+
+	setPlatform(t, "linux/arm64")
+	img, _, err = GetImage(context.Background(), "app:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, digestOf(t, remote), digestOf(t, img))
+}
@@ -0,0 +1,67 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Referrer is an artifact that refers to an image through the OCI 1.1
+// referrers API.
+type Referrer struct {
+	Digest       cr.Hash
+	ArtifactType string
+	Annotations  map[string]string
+}
+
+// GetReferrers resolves the registry reference image to its manifest and
+// returns the referrers of that manifest whose artifact type is one of
+// artifactTypes. Registries are not required to copy artifact annotations into
+// the referrers index, so when a descriptor carries none they are read from
+// the artifact manifest.
+func GetReferrers(ctx context.Context, image string, artifactTypes ...string) (*cr.Descriptor, []Referrer, error) {
+	ref, err := ParseDestination(image)
+	if err != nil {
+		return nil, nil, err
+	}
+	subject, err := ResolveDescriptor(ctx, image)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx, err := remoteReferrers(ctx, ref.Context().Digest(subject.Digest.String()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing referrers: %w", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading referrers index: %w", err)
+	}
+
+	var referrers []Referrer
+	for _, desc := range manifest.Manifests {
+		if !slices.Contains(artifactTypes, desc.ArtifactType) {
+			continue
+		}
+		annotations := desc.Annotations
+		if len(annotations) == 0 {
+			img, err := remoteImage(ctx, ref.Context().Digest(desc.Digest.String()))
+			if err != nil {
+				return nil, nil, fmt.Errorf("error retrieving referrer %s: %w", desc.Digest, err)
+			}
+			m, err := img.Manifest()
+			if err != nil {
+				return nil, nil, fmt.Errorf("error reading referrer %s: %w", desc.Digest, err)
+			}
+			annotations = m.Annotations
+		}
+		referrers = append(referrers, Referrer{
+			Digest:       desc.Digest,
+			ArtifactType: desc.ArtifactType,
+			Annotations:  annotations,
+		})
+	}
+	return subject, referrers, nil
+}
@@ -0,0 +1,37 @@
+package imageutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReferrers(t *testing.T) {
+	host := newTestRegistry(t)
+	img, err := random.Image(256, 1)
+	require.NoError(t, err)
+	image := host + "/prod/app:v1"
+	desc, err := CopyImage(context.Background(), "oci:"+writeTestLayout(t, img)+":v1", image)
+	require.NoError(t, err)
+
+	marker, err := AttachArtifact(context.Background(), image, *desc, "application/vnd.example.marker.v1+json",
+		"application/json", []byte(`{}`), map[string]string{"com.example.passed": "true"})
+	require.NoError(t, err)
+	_, err = AttachArtifact(context.Background(), image, *desc, "application/vnd.example.other.v1+json",
+		"application/json", []byte(`{}`), nil)
+	require.NoError(t, err)
+
+	subject, referrers, err := GetReferrers(context.Background(), image, "application/vnd.example.marker.v1+json")
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest, subject.Digest)
+	require.Len(t, referrers, 1)
+	assert.Equal(t, marker, referrers[0].Digest)
+	assert.Equal(t, "true", referrers[0].Annotations["com.example.passed"])
+
+	_, referrers, err = GetReferrers(context.Background(), image, "application/vnd.example.none.v1+json")
+	require.NoError(t, err)
+	assert.Empty(t, referrers)
+}
@@ -0,0 +1,38 @@
+package imageutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// TaggedDigest is a tag of a repository and the manifest digest it points to.
+type TaggedDigest struct {
+	Tag    string
+	Digest string
+}
+
+// ListRepository lists the tags of a registry repository (e.g.
+// registry.example.com/org/app) in lexical order and resolves each one to
+// its manifest digest. Tags that share a manifest are all returned.
+func ListRepository(ctx context.Context, repository string) ([]TaggedDigest, error) {
+	repo, err := parseRemoteRepository(repository)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the repository: %w", err)
+	}
+	tags, err := remoteList(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags: %w", err)
+	}
+	slices.Sort(tags)
+
+	images := make([]TaggedDigest, 0, len(tags))
+	for _, tag := range tags {
+		desc, err := remoteHead(ctx, repo.Tag(tag))
+		if err != nil {
+			return nil, fmt.Errorf("error resolving tag %s: %w", tag, err)
+		}
+		images = append(images, TaggedDigest{Tag: tag, Digest: desc.Digest.String()})
+	}
+	return images, nil
+}
@@ -0,0 +1,48 @@
+package imageutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRepository(t *testing.T) {
+	host := newTestRegistry(t)
+	first, err := random.Image(256, 1)
+	require.NoError(t, err)
+	second, err := random.Image(256, 1)
+	require.NoError(t, err)
+	for tag, img := range map[string]cr.Image{"v2": second, "v1": first, "latest": second} {
+		ref, err := name.ParseReference(host + "/org/app:" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+	firstDigest, err := first.Digest()
+	require.NoError(t, err)
+	secondDigest, err := second.Digest()
+	require.NoError(t, err)
+
+	got, err := ListRepository(context.Background(), host+"/org/app")
+	require.NoError(t, err)
+	assert.Equal(t, []TaggedDigest{
+		{Tag: "latest", Digest: secondDigest.String()},
+		{Tag: "v1", Digest: firstDigest.String()},
+		{Tag: "v2", Digest: secondDigest.String()},
+	}, got)
+}
+
+func TestListRepository_Errors(t *testing.T) {
+	_, err := ListRepository(context.Background(), "INVALID/Repo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing the repository")
+
+	_, err = ListRepository(context.Background(), newTestRegistry(t)+"/org/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error listing tags")
+}
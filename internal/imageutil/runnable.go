@@ -0,0 +1,137 @@
+package imageutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Artifact describes an OCI artifact that is not a container image.
+type Artifact struct {
+	// Type is the artifactType of the manifest, when set.
+	Type string
+	// ConfigMediaType is the media type of the config of the manifest.
+	ConfigMediaType string
+	// LayerMediaType is the media type of the first layer, when any.
+	LayerMediaType string
+}
+
+// artifactNames maps media type prefixes of well-known artifacts to the name
+// they are reported with.
+var artifactNames = []struct {
+	prefix string
+	name   string
+}{
+	{"application/vnd.cncf.helm.", "Helm chart"},
+	{"application/vnd.wasm.", "WebAssembly module"},
+	{"application/vnd.module.wasm.", "WebAssembly module"},
+	{"application/wasm", "WebAssembly module"},
+	{"application/spdx", "SBOM"},
+	{"text/spdx", "SBOM"},
+	{"application/vnd.cyclonedx", "SBOM"},
+	{"application/vnd.syft", "SBOM"},
+	{"application/vnd.dev.cosign.", "signature"},
+	{"application/vnd.dev.sigstore.bundle", "Sigstore bundle"},
+	{"application/vnd.in-toto", "attestation"},
+}
+
+// Name returns what the artifact is, e.g. "Helm chart", identified by its
+// artifactType, config media type or layer media type in that order, or
+// "OCI artifact" when none is known.
+func (a Artifact) Name() string {
+	for _, mt := range []string{a.Type, a.ConfigMediaType, a.LayerMediaType} {
+		for _, n := range artifactNames {
+			if mt != "" && strings.HasPrefix(mt, n.prefix) {
+				return n.name
+			}
+		}
+	}
+	return "OCI artifact"
+}
+
+// String describes the artifact with its name and the media types it was
+// identified by.
+func (a Artifact) String() string {
+	if a.Type != "" {
+		return fmt.Sprintf("%s (artifact type %s, config media type %s)", a.Name(), a.Type, a.ConfigMediaType)
+	}
+	return fmt.Sprintf("%s (config media type %s)", a.Name(), a.ConfigMediaType)
+}
+
+// NotRunnableError is the error of loading an OCI artifact that is not a
+// container image. It carries the kind not-runnable.
+type NotRunnableError struct {
+	Artifact Artifact
+}
+
+func (e *NotRunnableError) Error() string {
+	return "not a runnable container image: " + e.Artifact.String()
+}
+
+// ArtifactOf returns the artifact that err reports as not runnable, if any.
+func ArtifactOf(err error) (Artifact, bool) {
+	var nr *NotRunnableError
+	if errors.As(err, &nr) {
+		return nr.Artifact, true
+	}
+	return Artifact{}, false
+}
+
+// rawManifest holds the fields of a manifest that identify an artifact,
+// including artifactType, which v1.Manifest does not decode.
+type rawManifest struct {
+	ArtifactType string `json:"artifactType"`
+	Config       struct {
+		MediaType types.MediaType `json:"mediaType"`
+	} `json:"config"`
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+	} `json:"layers"`
+}
+
+// checkRunnable returns a *NotRunnableError of kind not-runnable when img is
+// not a container image but another OCI artifact stored in a registry or
+// layout, such as a Helm chart or an SBOM: its config is not a container
+// image configuration, so its layers are not a filesystem and checks would
+// report misleading results. A config without a media type, as in some older
+// manifests, is accepted. An image without layers, or whose configuration
+// lists no rootfs.diff_ids, has no filesystem to run and is not runnable
+// either; checks would pass it vacuously.
+func checkRunnable(img cr.Image) error {
+	raw, err := img.RawManifest()
+	if err != nil {
+		return fmt.Errorf("error reading the image manifest: %w", err)
+	}
+	var m rawManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return fmt.Errorf("error parsing the image manifest: %w", err)
+	}
+	if m.Config.MediaType == "" || m.Config.MediaType.IsConfig() {
+		return checkFilesystem(img, m)
+	}
+	artifact := Artifact{Type: m.ArtifactType, ConfigMediaType: string(m.Config.MediaType)}
+	if len(m.Layers) > 0 {
+		artifact.LayerMediaType = m.Layers[0].MediaType
+	}
+	return withKind(ErrorKindNotRunnable, &NotRunnableError{Artifact: artifact})
+}
+
+// checkFilesystem returns an error of kind not-runnable when the container
+// image img, whose manifest is m, has no layers or no rootfs.diff_ids.
+func checkFilesystem(img cr.Image, m rawManifest) error {
+	if len(m.Layers) == 0 {
+		return withKind(ErrorKindNotRunnable, fmt.Errorf("not a runnable container image: the image has no layers"))
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("error retrieving the image configuration: %w", err)
+	}
+	if config != nil && len(config.RootFS.DiffIDs) == 0 {
+		return withKind(ErrorKindNotRunnable, fmt.Errorf("not a runnable container image: the image configuration lists no rootfs.diff_ids"))
+	}
+	return nil
+}
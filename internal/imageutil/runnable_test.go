@@ -0,0 +1,179 @@
+package imageutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const helmConfigMediaType types.MediaType = "application/vnd.cncf.helm.config.v1+json"
+
+// artifactTypeImage sets artifactType in the manifest of an image.
+type artifactTypeImage struct {
+	v1.Image
+	artifactType string
+}
+
+func (i artifactTypeImage) RawManifest() ([]byte, error) {
+	raw, err := i.Image.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	m["artifactType"] = i.artifactType
+	return json.Marshal(m)
+}
+
+// noDiffIDsImage lists no rootfs.diff_ids in its configuration.
+type noDiffIDsImage struct {
+	v1.Image
+}
+
+func (i noDiffIDsImage) ConfigFile() (*v1.ConfigFile, error) {
+	cfg, err := i.Image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.RootFS.DiffIDs = nil
+	return cfg, nil
+}
+
+// helmChart returns an image shaped like a Helm chart pushed to a registry.
+func helmChart(t *testing.T) v1.Image {
+	t.Helper()
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	return mutate.ConfigMediaType(img, helmConfigMediaType)
+}
+
+func TestCheckRunnable(t *testing.T) {
+	randomImg, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		img     v1.Image
+		wantErr string
+	}{
+		{"docker image", randomImg, ""},
+		{"oci image", mutate.ConfigMediaType(mutate.MediaType(randomImg, types.OCIManifestSchema1), types.OCIConfigJSON), ""},
+		{"helm chart", helmChart(t), "not a runnable container image: Helm chart (config media type application/vnd.cncf.helm.config.v1+json)"},
+		{"unknown artifact", mutate.ConfigMediaType(empty.Image, "application/vnd.oci.empty.v1+json"), "not a runnable container image: OCI artifact (config media type application/vnd.oci.empty.v1+json)"},
+		{
+			"sbom with artifact type",
+			artifactTypeImage{Image: mutate.ConfigMediaType(empty.Image, "application/vnd.oci.empty.v1+json"), artifactType: "application/spdx+json"},
+			"not a runnable container image: SBOM (artifact type application/spdx+json, config media type application/vnd.oci.empty.v1+json)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRunnable(tt.img)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, ErrorKindNotRunnable, ErrorKindOf(err))
+			_, ok := ArtifactOf(err)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestCheckRunnable_NoFilesystem(t *testing.T) {
+	randomImg, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		img     v1.Image
+		wantErr string
+	}{
+		{"image without layers", empty.Image, "not a runnable container image: the image has no layers"},
+		{"image without diff_ids", noDiffIDsImage{randomImg}, "not a runnable container image: the image configuration lists no rootfs.diff_ids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRunnable(tt.img)
+			require.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, ErrorKindNotRunnable, ErrorKindOf(err))
+			_, ok := ArtifactOf(err)
+			assert.False(t, ok, "images without a filesystem are not artifacts")
+		})
+	}
+}
+
+func TestGetImage_NotRunnable_OCILayout(t *testing.T) {
+	layoutPath := filepath.Join(t.TempDir(), "oci-layout")
+	p, err := layout.Write(layoutPath, empty.Index)
+	require.NoError(t, err)
+	chart := helmChart(t)
+	require.NoError(t, p.AppendImage(chart))
+	digest, err := chart.Digest()
+	require.NoError(t, err)
+
+	_, _, cleanup, err := GetImageAndConfig(context.Background(), "oci:"+layoutPath+"@"+digest.String())
+	defer cleanup()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a runnable container image")
+	assert.Equal(t, ErrorKindNotRunnable, ErrorKindOf(err))
+}
+
+func TestGetImage_NotRunnable_Registry(t *testing.T) {
+	origLocal, origRemote := getLocalImageFn, getRemoteImageFn
+	t.Cleanup(func() { getLocalImageFn, getRemoteImageFn = origLocal, origRemote })
+	getLocalImageFn = func(_ context.Context, _ string) (v1.Image, error) {
+		return nil, assert.AnError
+	}
+	getRemoteImageFn = func(_ context.Context, _ string) (v1.Image, error) {
+		return helmChart(t), nil
+	}
+
+	img, cleanup, err := GetImage(context.Background(), "registry.example.com/charts/app:1.0.0")
+	defer cleanup()
+	require.Error(t, err)
+	assert.Nil(t, img)
+	assert.Equal(t, ErrorKindNotRunnable, ErrorKindOf(err))
+}
+
+func TestArtifactName(t *testing.T) {
+	tests := []struct {
+		artifact Artifact
+		want     string
+	}{
+		{Artifact{ConfigMediaType: "application/vnd.cncf.helm.config.v1+json"}, "Helm chart"},
+		{Artifact{ConfigMediaType: "application/vnd.wasm.config.v0+json"}, "WebAssembly module"},
+		{Artifact{Type: "application/vnd.cyclonedx+json", ConfigMediaType: "application/vnd.oci.empty.v1+json"}, "SBOM"},
+		{Artifact{ConfigMediaType: "application/vnd.oci.empty.v1+json", LayerMediaType: "application/vnd.in-toto+json"}, "attestation"},
+		{Artifact{ConfigMediaType: "application/vnd.example.config+json"}, "OCI artifact"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.artifact.Name(), tt.artifact.String())
+	}
+}
+
+func TestArtifactOf(t *testing.T) {
+	_, ok := ArtifactOf(assert.AnError)
+	assert.False(t, ok)
+
+	err := fmt.Errorf("check failed: %w", checkRunnable(helmChart(t)))
+	artifact, ok := ArtifactOf(err)
+	require.True(t, ok)
+	assert.Equal(t, "Helm chart", artifact.Name())
+}
@@ -3,6 +3,8 @@ package labels
 import (
 	"fmt"
 	"regexp"
+
+	"github.com/jarfernandez/check-image/internal/decision"
 )
 
 // ValidationResult represents the result of label validation
@@ -37,6 +39,7 @@ func ValidateLabels(imageLabels map[string]string, policy *Policy) (*ValidationR
 
 		// Check if label exists
 		if !exists {
+			decision.Explain("labels", "required", req.Name, false, "label %q is required but not set", req.Name)
 			result.Passed = false
 			result.MissingLabels = append(result.MissingLabels, req.Name)
 			continue
@@ -45,7 +48,10 @@ func ValidateLabels(imageLabels map[string]string, policy *Policy) (*ValidationR
 		// Label exists - validate value if required
 		if req.Value != "" {
 			// Exact value match required (case-sensitive)
-			if actualValue != req.Value {
+			if actualValue == req.Value {
+				decision.Explain("labels", "value", req.Name, true, "label %q has the expected value %q", req.Name, req.Value)
+			} else {
+				decision.Explain("labels", "value", req.Name, false, "label %q has value %q but expected %q", req.Name, actualValue, req.Value)
 				result.Passed = false
 				result.InvalidLabels = append(result.InvalidLabels, InvalidLabel{
 					Name:          req.Name,
@@ -62,7 +68,10 @@ func ValidateLabels(imageLabels map[string]string, policy *Policy) (*ValidationR
 				return nil, fmt.Errorf("failed to compile pattern for label %q: %w", req.Name, err)
 			}
 
-			if !re.MatchString(actualValue) {
+			if re.MatchString(actualValue) {
+				decision.Explain("labels", "pattern", req.Name, true, "label %q value %q matches pattern %q", req.Name, actualValue, req.Pattern)
+			} else {
+				decision.Explain("labels", "pattern", req.Name, false, "label %q value %q does not match pattern %q", req.Name, actualValue, req.Pattern)
 				result.Passed = false
 				result.InvalidLabels = append(result.InvalidLabels, InvalidLabel{
 					Name:            req.Name,
@@ -71,8 +80,10 @@ func ValidateLabels(imageLabels map[string]string, policy *Policy) (*ValidationR
 					Reason:          fmt.Sprintf("label %q value %q does not match pattern %q", req.Name, actualValue, req.Pattern),
 				})
 			}
+		} else {
+			// Neither value nor pattern is specified: existence is sufficient
+			decision.Explain("labels", "required", req.Name, true, "label %q is set, and no value or pattern is required", req.Name)
 		}
-		// If neither value nor pattern is specified, existence check is sufficient (already passed)
 	}
 
 	return result, nil
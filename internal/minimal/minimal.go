@@ -0,0 +1,100 @@
+// Package minimal evaluates whether an image is minimal in the distroless
+// sense: no shell, no package manager, no compiler toolchain, and a small size
+// budget, so that an attacker who gets into the container has as few tools as
+// possible to work with.
+package minimal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+)
+
+// The criteria of a minimal image.
+const (
+	CriterionShell          = "shell"
+	CriterionPackageManager = "package-manager"
+	CriterionCompiler       = "compiler"
+	CriterionSize           = "size"
+)
+
+// Criteria lists every criterion, in the order they are evaluated and
+// reported.
+var Criteria = []string{CriterionShell, CriterionPackageManager, CriterionCompiler, CriterionSize}
+
+// BinDirs are the directories searched for the executables of the file
+// criteria.
+var BinDirs = []string{"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/usr/local/bin", "/usr/local/sbin"}
+
+// Executables lists the names of the executables each file criterion looks
+// for in BinDirs.
+var Executables = map[string][]string{
+	CriterionShell:          {"sh", "bash", "ash", "dash", "zsh", "ksh", "mksh", "csh", "tcsh"},
+	CriterionPackageManager: {"apt", "apt-get", "dpkg", "apk", "yum", "dnf", "microdnf", "tdnf", "rpm", "zypper", "pacman"},
+	CriterionCompiler:       {"cc", "gcc", "c++", "g++", "clang", "clang++", "go", "rustc", "javac", "ld"},
+}
+
+// ParseCriteria validates the names of criteria and returns them in the order
+// of Criteria, without duplicates. No names select every criterion.
+func ParseCriteria(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return slices.Clone(Criteria), nil
+	}
+	for _, n := range names {
+		if !slices.Contains(Criteria, n) {
+			return nil, fmt.Errorf("unknown minimal criterion %q, valid criteria are: %s", n, strings.Join(Criteria, ", "))
+		}
+	}
+	var selected []string
+	for _, c := range Criteria {
+		if slices.Contains(names, c) {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// FindExecutables returns, for each file criterion of criteria, the paths of
+// BinDirs where img has one of its executables. Symbolic links are followed,
+// and paths referring to a file already found, such as /usr/bin/sh next to
+// /bin/sh in merged-/usr images, are left out.
+func FindExecutables(ctx context.Context, img cr.Image, criteria []string) (map[string][]string, error) {
+	var candidates []string
+	for _, c := range criteria {
+		for _, name := range Executables[c] {
+			for _, dir := range BinDirs {
+				candidates = append(candidates, path.Join(dir, name))
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	resolved, err := imageutil.ResolveFiles(ctx, img, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, c := range criteria {
+		for _, name := range Executables[c] {
+			for _, dir := range BinDirs {
+				p := path.Join(dir, name)
+				target, ok := resolved[p]
+				if !ok || seen[target] {
+					continue
+				}
+				seen[target] = true
+				found[c] = append(found[c], p)
+			}
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,75 @@
+package minimal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fileLayer(t *testing.T, headers ...tar.Header) cr.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range headers {
+		h.Mode = 0755
+		require.NoError(t, tw.WriteHeader(&h))
+	}
+	require.NoError(t, tw.Close())
+	data := buf.Bytes()
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	require.NoError(t, err)
+	return layer
+}
+
+func TestParseCriteria(t *testing.T) {
+	all, err := ParseCriteria(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Criteria, all)
+
+	selected, err := ParseCriteria([]string{"size", "shell", "size"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell", "size"}, selected, "criteria are ordered and deduplicated")
+
+	_, err = ParseCriteria([]string{"shell", "debugger"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown minimal criterion "debugger"`)
+}
+
+func TestFindExecutables(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image, fileLayer(t,
+		tar.Header{Name: "bin", Typeflag: tar.TypeSymlink, Linkname: "usr/bin"},
+		tar.Header{Name: "usr/bin/dash", Typeflag: tar.TypeReg},
+		tar.Header{Name: "usr/bin/sh", Typeflag: tar.TypeSymlink, Linkname: "dash"},
+		tar.Header{Name: "usr/bin/dpkg", Typeflag: tar.TypeReg},
+		tar.Header{Name: "usr/local/bin/go", Typeflag: tar.TypeReg},
+		tar.Header{Name: "app/gcc", Typeflag: tar.TypeReg},
+	))
+	require.NoError(t, err)
+
+	found, err := FindExecutables(context.Background(), img, Criteria)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		CriterionShell:          {"/bin/sh"},
+		CriterionPackageManager: {"/bin/dpkg"},
+		CriterionCompiler:       {"/usr/local/bin/go"},
+	}, found, "each file is reported once, by its first path")
+
+	found, err = FindExecutables(context.Background(), img, []string{CriterionCompiler, CriterionSize})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{CriterionCompiler: {"/usr/local/bin/go"}}, found)
+
+	found, err = FindExecutables(context.Background(), img, []string{CriterionSize})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
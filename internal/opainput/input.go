@@ -0,0 +1,165 @@
+// Package opainput builds the normalized JSON document that describes an
+// image to Open Policy Agent, the input a Rego policy evaluates.
+package opainput
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Input is the document a Rego policy receives as input. Lists and maps are
+// never null, and ordered where the image leaves their order undefined, so
+// the same image always produces the same document.
+type Input struct {
+	Image    Image    `json:"image"`
+	Config   Config   `json:"config"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// Image identifies the image.
+type Image struct {
+	Reference string `json:"reference"`
+	// Transport is daemon-registry, oci, oci-archive, or docker-archive.
+	Transport string `json:"transport"`
+	// Registry is only set for the daemon-registry transport.
+	Registry string `json:"registry,omitempty"`
+	Digest   string `json:"digest"`
+	// Platform is os/architecture, with the variant when set.
+	Platform string `json:"platform"`
+	// Created is the RFC 3339 creation time, empty when the image has none.
+	Created string `json:"created,omitempty"`
+}
+
+// Config holds the runtime configuration of the image.
+type Config struct {
+	User string `json:"user"`
+	// Env maps each variable to its value; the last definition wins, as at
+	// runtime.
+	Env        map[string]string `json:"env"`
+	Entrypoint []string          `json:"entrypoint"`
+	Cmd        []string          `json:"cmd"`
+	WorkingDir string            `json:"working-dir"`
+	// ExposedPorts lists ports as port/protocol, e.g. 8080/tcp, sorted.
+	ExposedPorts []string          `json:"exposed-ports"`
+	Labels       map[string]string `json:"labels"`
+	// Healthcheck is the healthcheck test command, empty when the image has
+	// none or disables it.
+	Healthcheck []string `json:"healthcheck"`
+	StopSignal  string   `json:"stop-signal,omitempty"`
+}
+
+// Manifest describes the layers of the image.
+type Manifest struct {
+	MediaType string  `json:"media-type"`
+	Layers    []Layer `json:"layers"`
+	// TotalSize is the sum of the compressed layer sizes in bytes.
+	TotalSize int64 `json:"total-size"`
+}
+
+// Layer is a layer descriptor of the manifest.
+type Layer struct {
+	Digest      string            `json:"digest"`
+	MediaType   string            `json:"media-type"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Build returns the input document of img, referenced as reference through
+// transport. registry is empty for transports other than daemon-registry.
+func Build(reference, transport, registry string, img cr.Image, config *cr.ConfigFile) (*Input, error) {
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	in := &Input{
+		Image: Image{
+			Reference: reference,
+			Transport: transport,
+			Registry:  registry,
+			Digest:    digest.String(),
+			Platform:  platform(config),
+		},
+		Config:   buildConfig(config.Config),
+		Manifest: Manifest{MediaType: string(manifest.MediaType), Layers: []Layer{}},
+	}
+	if !config.Created.IsZero() {
+		in.Image.Created = config.Created.UTC().Format(time.RFC3339)
+	}
+	for _, l := range manifest.Layers {
+		in.Manifest.Layers = append(in.Manifest.Layers, Layer{
+			Digest:      l.Digest.String(),
+			MediaType:   string(l.MediaType),
+			Size:        l.Size,
+			Annotations: orEmpty(l.Annotations),
+		})
+		in.Manifest.TotalSize += l.Size
+	}
+	return in, nil
+}
+
+func platform(config *cr.ConfigFile) string {
+	p := config.OS + "/" + config.Architecture
+	if config.Variant != "" {
+		p += "/" + config.Variant
+	}
+	return p
+}
+
+func buildConfig(c cr.Config) Config {
+	cfg := Config{
+		User:         c.User,
+		Env:          make(map[string]string, len(c.Env)),
+		Entrypoint:   orEmptyList(c.Entrypoint),
+		Cmd:          orEmptyList(c.Cmd),
+		WorkingDir:   c.WorkingDir,
+		ExposedPorts: []string{},
+		Labels:       orEmpty(c.Labels),
+		Healthcheck:  []string{},
+		StopSignal:   c.StopSignal,
+	}
+	for _, kv := range c.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		cfg.Env[k] = v
+	}
+	for p := range c.ExposedPorts {
+		cfg.ExposedPorts = append(cfg.ExposedPorts, normalizePort(p))
+	}
+	slices.Sort(cfg.ExposedPorts)
+	cfg.ExposedPorts = slices.Compact(cfg.ExposedPorts)
+	if hc := c.Healthcheck; hc != nil && len(hc.Test) > 0 && hc.Test[0] != "NONE" {
+		cfg.Healthcheck = slices.Clone(hc.Test)
+	}
+	return cfg
+}
+
+// normalizePort lowercases a port and adds the tcp protocol when it has none.
+func normalizePort(p string) string {
+	p = strings.ToLower(p)
+	if !strings.Contains(p, "/") {
+		p += "/tcp"
+	}
+	return p
+}
+
+func orEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(m)
+}
+
+func orEmptyList(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return slices.Clone(s)
+}
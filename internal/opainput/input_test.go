@@ -0,0 +1,125 @@
+package opainput
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(t *testing.T, config cr.Config, layers int64) (cr.Image, *cr.ConfigFile) {
+	t.Helper()
+	img := empty.Image
+	if layers > 0 {
+		var err error
+		img, err = random.Image(100, layers)
+		require.NoError(t, err)
+	}
+	cf, err := img.ConfigFile()
+	require.NoError(t, err)
+	cf = cf.DeepCopy()
+	cf.OS = "linux"
+	cf.Architecture = "arm"
+	cf.Variant = "v7"
+	cf.Created = cr.Time{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))}
+	cf.Config = config
+	img, err = mutate.ConfigFile(img, cf)
+	require.NoError(t, err)
+	cf, err = img.ConfigFile()
+	require.NoError(t, err)
+	return img, cf
+}
+
+func TestBuild(t *testing.T) {
+	img, cf := testImage(t, cr.Config{
+		User:         "1000",
+		Env:          []string{"PATH=/usr/bin", "MODE=dev", "MODE=prod", "EMPTY"},
+		Entrypoint:   []string{"/app"},
+		WorkingDir:   "/srv",
+		ExposedPorts: map[string]struct{}{"8080/TCP": {}, "53/udp": {}, "8080": {}, "443/tcp": {}},
+		Labels:       map[string]string{"team": "platform"},
+		Healthcheck:  &cr.HealthConfig{Test: []string{"CMD", "/health"}},
+	}, 2)
+
+	in, err := Build("registry.example.com/app:1.0", "daemon-registry", "registry.example.com", img, cf)
+	require.NoError(t, err)
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, Image{
+		Reference: "registry.example.com/app:1.0",
+		Transport: "daemon-registry",
+		Registry:  "registry.example.com",
+		Digest:    digest.String(),
+		Platform:  "linux/arm/v7",
+		Created:   "2026-03-01T11:00:00Z",
+	}, in.Image)
+
+	assert.Equal(t, "1000", in.Config.User)
+	assert.Equal(t, map[string]string{"PATH": "/usr/bin", "MODE": "prod", "EMPTY": ""}, in.Config.Env)
+	assert.Equal(t, []string{"/app"}, in.Config.Entrypoint)
+	assert.Equal(t, []string{}, in.Config.Cmd)
+	assert.Equal(t, "/srv", in.Config.WorkingDir)
+	assert.Equal(t, []string{"443/tcp", "53/udp", "8080/tcp"}, in.Config.ExposedPorts)
+	assert.Equal(t, map[string]string{"team": "platform"}, in.Config.Labels)
+	assert.Equal(t, []string{"CMD", "/health"}, in.Config.Healthcheck)
+
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+	require.Len(t, in.Manifest.Layers, 2)
+	assert.Equal(t, string(manifest.MediaType), in.Manifest.MediaType)
+	var total int64
+	for i, l := range manifest.Layers {
+		assert.Equal(t, l.Digest.String(), in.Manifest.Layers[i].Digest)
+		assert.Equal(t, l.Size, in.Manifest.Layers[i].Size)
+		total += l.Size
+	}
+	assert.Equal(t, total, in.Manifest.TotalSize)
+}
+
+func TestBuild_HealthcheckDisabled(t *testing.T) {
+	img, cf := testImage(t, cr.Config{Healthcheck: &cr.HealthConfig{Test: []string{"NONE"}}}, 0)
+
+	in, err := Build("oci:/tmp/layout:latest", "oci", "", img, cf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, in.Config.Healthcheck)
+}
+
+func TestBuild_EmptyValuesAreNotNull(t *testing.T) {
+	img, cf := testImage(t, cr.Config{}, 0)
+
+	in, err := Build("oci:/tmp/layout:latest", "oci", "", img, cf)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "null")
+	assert.NotContains(t, string(data), `"registry"`)
+	assert.Contains(t, string(data), `"layers":[]`)
+	assert.Contains(t, string(data), `"env":{}`)
+}
+
+func TestBuild_Deterministic(t *testing.T) {
+	img, cf := testImage(t, cr.Config{
+		ExposedPorts: map[string]struct{}{"80/tcp": {}, "443/tcp": {}, "8080/tcp": {}, "9090/udp": {}},
+		Labels:       map[string]string{"a": "1", "b": "2", "c": "3"},
+	}, 1)
+
+	first, err := Build("img", "oci", "", img, cf)
+	require.NoError(t, err)
+	want, err := json.Marshal(first)
+	require.NoError(t, err)
+	for range 10 {
+		in, err := Build("img", "oci", "", img, cf)
+		require.NoError(t, err)
+		got, err := json.Marshal(in)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+}
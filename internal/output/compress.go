@@ -0,0 +1,112 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression represents the compression applied to a report file.
+type Compression string
+
+const (
+	CompressionAuto Compression = "auto"
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression parses a string into a Compression. "auto" is resolved
+// from the extension of path: .gz selects gzip, .zst or .zstd selects zstd,
+// and anything else leaves the file uncompressed.
+func ParseCompression(s, path string) (Compression, error) {
+	switch Compression(s) {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return Compression(s), nil
+	case CompressionAuto:
+		return CompressionFromExtension(path), nil
+	default:
+		return "", fmt.Errorf("unsupported compression %q, valid values are: auto, none, gzip, zstd", s)
+	}
+}
+
+// CompressionFromExtension derives the compression of a file from its
+// extension.
+func CompressionFromExtension(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// NewCompressedWriter wraps w so that everything written is compressed with
+// c. Closing the returned writer flushes the compressed stream but does not
+// close w.
+func NewCompressedWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("error creating zstd writer: %w", err)
+		}
+		return zw, nil
+	case CompressionNone, "":
+		return nopWriteCloser{w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", c)
+	}
+}
+
+// Magic numbers of the compressed stream formats.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Decompress returns data decompressed when it starts with a gzip or zstd
+// header, and data unchanged otherwise, so reports written with --compress
+// can be read back whatever their file name.
+func Decompress(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip data: %w", err)
+		}
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip data: %w", err)
+		}
+		return out, nil
+	case bytes.HasPrefix(data, zstdMagic):
+		zr, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating zstd reader: %w", err)
+		}
+		defer zr.Close()
+		out, err := zr.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error reading zstd data: %w", err)
+		}
+		return out, nil
+	default:
+		return data, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
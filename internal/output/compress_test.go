@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		path    string
+		want    Compression
+		wantErr bool
+	}{
+		{name: "auto gzip", input: "auto", path: "report.json.gz", want: CompressionGzip},
+		{name: "auto zstd", input: "auto", path: "report.ndjson.zst", want: CompressionZstd},
+		{name: "auto zstd long extension", input: "auto", path: "report.json.ZSTD", want: CompressionZstd},
+		{name: "auto uncompressed", input: "auto", path: "report.json", want: CompressionNone},
+		{name: "explicit overrides extension", input: "zstd", path: "report.json.gz", want: CompressionZstd},
+		{name: "none", input: "none", path: "report.json.gz", want: CompressionNone},
+		{name: "unsupported", input: "bzip2", path: "report.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCompression(tt.input, tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "unsupported compression")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewCompressedWriter(t *testing.T) {
+	const payload = `{"image":"nginx:latest","passed":true}` + "\n"
+
+	decode := map[Compression]func(t *testing.T, r io.Reader) []byte{
+		CompressionNone: func(t *testing.T, r io.Reader) []byte {
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			return data
+		},
+		CompressionGzip: func(t *testing.T, r io.Reader) []byte {
+			zr, err := gzip.NewReader(r)
+			require.NoError(t, err)
+			data, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			return data
+		},
+		CompressionZstd: func(t *testing.T, r io.Reader) []byte {
+			zr, err := zstd.NewReader(r)
+			require.NoError(t, err)
+			defer zr.Close()
+			data, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			return data
+		},
+	}
+
+	for c, dec := range decode {
+		t.Run(string(c), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewCompressedWriter(&buf, c)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, payload)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			if c != CompressionNone {
+				assert.NotEqual(t, payload, buf.String())
+			}
+			assert.Equal(t, payload, string(dec(t, &buf)))
+		})
+	}
+
+	_, err := NewCompressedWriter(&bytes.Buffer{}, Compression("bzip2"))
+	require.Error(t, err)
+}
+
+func TestDecompress(t *testing.T) {
+	const payload = `{"image":"nginx:latest","passed":true}` + "\n"
+
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewCompressedWriter(&buf, c)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, payload)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			got, err := Decompress(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, payload, string(got))
+		})
+	}
+
+	_, err := Decompress([]byte{0x1f, 0x8b, 0x00})
+	require.Error(t, err, "a truncated gzip stream is an error")
+}
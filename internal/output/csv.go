@@ -0,0 +1,191 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// Severities of CSV findings: a check that could not be evaluated, or a
+// check that failed validation.
+const (
+	SeverityError   = "error"
+	SeverityFailure = "failure"
+)
+
+// csvHeader names the columns of CSV output, in Finding field order.
+var csvHeader = []string{"image", "check", "rule", "subject", "message", "severity"}
+
+// Finding is one row of CSV output: a single reason an image failed a check.
+// Subject is the path, name, port, or argument the finding is about, and is
+// empty for findings about the image as a whole.
+type Finding struct {
+	Image    string
+	Check    string
+	Rule     string
+	Subject  string
+	Message  string
+	Severity string
+}
+
+// CheckFindings returns the findings of a check result: one per violation,
+// finding, or failed constraint recorded in its details, or a single finding
+// with the result message when the details itemize none. Passed checks,
+// including checks passed by an exception, have no findings.
+func CheckFindings(r CheckResult) []Finding {
+	if r.Passed {
+		return nil
+	}
+	if r.Error != "" {
+		return []Finding{{Image: r.Image, Check: r.Check, Message: r.Message, Severity: SeverityError}}
+	}
+
+	var findings []Finding
+	add := func(rule, subject, message string) {
+		findings = append(findings, Finding{
+			Image:    r.Image,
+			Check:    r.Check,
+			Rule:     rule,
+			Subject:  subject,
+			Message:  message,
+			Severity: SeverityFailure,
+		})
+	}
+
+	switch d := r.Details.(type) {
+	case SecretsDetails:
+		for _, f := range d.EnvVarFindings {
+			add("env-var", f.Name, f.Description)
+		}
+		for _, f := range d.FileFindings {
+			add("file", f.Path, fmt.Sprintf("%s (layer %d)", f.Description, f.LayerIndex))
+		}
+	case LabelsDetails:
+		for _, name := range d.MissingLabels {
+			add("missing-label", name, fmt.Sprintf("required label %q is missing", name))
+		}
+		for _, l := range d.InvalidLabels {
+			add("invalid-label", l.Name, l.Reason)
+		}
+	case PortsDetails:
+		for _, constraint := range d.FailedConstraints {
+			switch constraint {
+			case "allowed-ports":
+				for _, port := range d.UnauthorizedPorts {
+					add(constraint, strconv.Itoa(port), fmt.Sprintf("port %d is not in the allowed list", port))
+				}
+			case "forbid-privileged-ports":
+				for _, port := range d.PrivilegedPorts {
+					add(constraint, strconv.Itoa(port), fmt.Sprintf("privileged port %d is exposed", port))
+				}
+			default:
+				add(constraint, "", r.Message)
+			}
+		}
+	case UserDetails:
+		for _, v := range d.Violations {
+			add(v.Rule, d.User, v.Message)
+		}
+	case EntrypointDetails:
+		for _, v := range d.Violations {
+			add(v.Rule, v.Argument, v.Message)
+		}
+	case ProvenanceDetails:
+		for _, v := range d.Violations {
+			add(v.Rule, "", v.Message)
+		}
+	case EntropyDetails:
+		for _, f := range d.Findings {
+			add("max-entropy", f.Path, fmt.Sprintf("entropy of %.3f bits per byte (layer %d)", f.Entropy, f.LayerIndex))
+		}
+	case DeprecationDetails:
+		if d.Deprecated {
+			add("deprecated", d.OfficialImage, r.Remediation)
+		}
+	case ArchitectureDetails:
+		for _, m := range d.Mismatches {
+			add("architecture-mismatch", m.Path, fmt.Sprintf("built for %s, the image declares %s (layer %d)", m.Architecture, d.Architecture, m.LayerIndex))
+		}
+	case MinimalDetails:
+		for _, p := range d.Shells {
+			add("shell", p, "shell executable")
+		}
+		for _, p := range d.PackageManagers {
+			add("package-manager", p, "package manager executable")
+		}
+		for _, p := range d.Compilers {
+			add("compiler", p, "compiler executable")
+		}
+		if slices.Contains(d.FailedCriteria, "size") {
+			add("size", fmt.Sprintf("%.2f MB", d.TotalMB), fmt.Sprintf("exceeds the minimal size budget of %d MB", d.MaxSizeMB))
+		}
+	case SmokeDetails:
+		switch {
+		case d.ExitCode != nil:
+			add("exit-code", strconv.Itoa(*d.ExitCode), r.Message)
+		case d.TimedOut:
+			add("timeout", "", r.Message)
+		case d.Health != "":
+			add("health", d.Health, r.Message)
+		}
+	case DriftDetails:
+		for _, diff := range d.Differences {
+			subject := diff.Field
+			if diff.Key != "" {
+				subject += "." + diff.Key
+			}
+			add(diff.Kind, subject, fmt.Sprintf("expected %q, got %q", diff.Expected, diff.Actual))
+		}
+	}
+
+	if len(findings) == 0 {
+		add("", "", r.Message)
+	}
+	return findings
+}
+
+// ReportFindings returns the findings of every check of an all command
+// report, preceded by its attempts to skip checks enforced by a required
+// config.
+func ReportFindings(report AllResult) []Finding {
+	var findings []Finding
+	for _, v := range report.PolicyViolations {
+		findings = append(findings, Finding{
+			Image:    report.Image,
+			Check:    "required-config",
+			Message:  v,
+			Severity: SeverityFailure,
+		})
+	}
+	for _, c := range report.Checks {
+		findings = append(findings, CheckFindings(c)...)
+	}
+	return findings
+}
+
+// RenderCSV writes a header row followed by one row per finding to w.
+func RenderCSV(w io.Writer, findings []Finding) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	return writeFindings(cw, findings)
+}
+
+// RenderCSVRows writes one row per finding to w, without a header row, to
+// continue the output of an earlier RenderCSV call.
+func RenderCSVRows(w io.Writer, findings []Finding) error {
+	return writeFindings(csv.NewWriter(w), findings)
+}
+
+func writeFindings(cw *csv.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if err := cw.Write([]string{f.Image, f.Check, f.Rule, f.Subject, f.Message, f.Severity}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
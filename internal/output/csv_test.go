@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFindings(t *testing.T) {
+	tests := []struct {
+		name   string
+		result CheckResult
+		want   []Finding
+	}{
+		{
+			name:   "passed check has no findings",
+			result: CheckResult{Check: "age", Image: "img", Passed: true, Message: "Image is recent"},
+		},
+		{
+			name:   "errored check",
+			result: CheckResult{Check: "age", Image: "img", Message: "Check failed with error: boom", Error: "boom"},
+			want:   []Finding{{Image: "img", Check: "age", Message: "Check failed with error: boom", Severity: SeverityError}},
+		},
+		{
+			name:   "failed check without itemized findings",
+			result: CheckResult{Check: "healthcheck", Image: "img", Message: "Image does not have a healthcheck defined", Details: HealthcheckDetails{}},
+			want:   []Finding{{Image: "img", Check: "healthcheck", Message: "Image does not have a healthcheck defined", Severity: SeverityFailure}},
+		},
+		{
+			name: "secrets findings",
+			result: CheckResult{Check: "secrets", Image: "img", Details: SecretsDetails{
+				EnvVarFindings: []EnvVarFinding{{Name: "DB_PASSWORD", Description: "password"}},
+				FileFindings:   []FileFinding{{Path: "/root/.ssh/id_rsa", LayerIndex: 2, Description: "SSH private key"}},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "secrets", Rule: "env-var", Subject: "DB_PASSWORD", Message: "password", Severity: SeverityFailure},
+				{Image: "img", Check: "secrets", Rule: "file", Subject: "/root/.ssh/id_rsa", Message: "SSH private key (layer 2)", Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "ports constraints",
+			result: CheckResult{Check: "ports", Image: "img", Message: "Image exposes more ports than allowed", Details: PortsDetails{
+				UnauthorizedPorts: []int{8080},
+				PrivilegedPorts:   []int{22},
+				FailedConstraints: []string{"allowed-ports", "max-exposed-ports", "forbid-privileged-ports"},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "ports", Rule: "allowed-ports", Subject: "8080", Message: "port 8080 is not in the allowed list", Severity: SeverityFailure},
+				{Image: "img", Check: "ports", Rule: "max-exposed-ports", Message: "Image exposes more ports than allowed", Severity: SeverityFailure},
+				{Image: "img", Check: "ports", Rule: "forbid-privileged-ports", Subject: "22", Message: "privileged port 22 is exposed", Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "labels",
+			result: CheckResult{Check: "labels", Image: "img", Details: LabelsDetails{
+				MissingLabels: []string{"maintainer"},
+				InvalidLabels: []InvalidLabelDetail{{Name: "version", Reason: "does not match pattern"}},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "labels", Rule: "missing-label", Subject: "maintainer", Message: `required label "maintainer" is missing`, Severity: SeverityFailure},
+				{Image: "img", Check: "labels", Rule: "invalid-label", Subject: "version", Message: "does not match pattern", Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "drift differences",
+			result: CheckResult{Check: "drift", Image: "img", Details: DriftDetails{
+				Differences: []DriftDifference{{Field: "env", Kind: "changed", Key: "MODE", Expected: "prod", Actual: "debug"}},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "drift", Rule: "changed", Subject: "env.MODE", Message: `expected "prod", got "debug"`, Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "entropy findings",
+			result: CheckResult{Check: "entropy", Image: "img", Details: EntropyDetails{
+				Findings: []EntropyFinding{{Path: "opt/payload.bin", LayerIndex: 2, Entropy: 7.999}},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "entropy", Rule: "max-entropy", Subject: "opt/payload.bin", Message: "entropy of 7.999 bits per byte (layer 2)", Severity: SeverityFailure},
+			},
+		},
+		{
+			name:   "deprecated official image",
+			result: CheckResult{Check: "deprecation", Image: "openjdk:17", Remediation: "Replace openjdk with the maintained eclipse-temurin image", Details: DeprecationDetails{OfficialImage: "openjdk", Deprecated: true}},
+			want: []Finding{
+				{Image: "openjdk:17", Check: "deprecation", Rule: "deprecated", Subject: "openjdk", Message: "Replace openjdk with the maintained eclipse-temurin image", Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "architecture mismatches",
+			result: CheckResult{Check: "architecture", Image: "img", Details: ArchitectureDetails{
+				Architecture: "arm64",
+				Mismatches:   []ArchitectureMismatch{{Path: "usr/local/bin/app", LayerIndex: 2, Architecture: "amd64"}},
+			}},
+			want: []Finding{
+				{Image: "img", Check: "architecture", Rule: "architecture-mismatch", Subject: "usr/local/bin/app", Message: "built for amd64, the image declares arm64 (layer 2)", Severity: SeverityFailure},
+			},
+		},
+		{
+			name: "minimal criteria",
+			result: CheckResult{Check: "minimal", Image: "img", Details: MinimalDetails{
+				FailedCriteria: []string{"shell", "size"},
+				Shells:         []string{"/bin/sh"},
+				TotalMB:        80.5,
+				MaxSizeMB:      50,
+			}},
+			want: []Finding{
+				{Image: "img", Check: "minimal", Rule: "shell", Subject: "/bin/sh", Message: "shell executable", Severity: SeverityFailure},
+				{Image: "img", Check: "minimal", Rule: "size", Subject: "80.50 MB", Message: "exceeds the minimal size budget of 50 MB", Severity: SeverityFailure},
+			},
+		},
+		{
+			name:   "smoke exit code",
+			result: CheckResult{Check: "smoke", Image: "img", Message: "Container exited with code 1", Details: SmokeDetails{ExitCode: new(1)}},
+			want: []Finding{
+				{Image: "img", Check: "smoke", Rule: "exit-code", Subject: "1", Message: "Container exited with code 1", Severity: SeverityFailure},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CheckFindings(tt.result))
+		})
+	}
+}
+
+func TestReportFindings(t *testing.T) {
+	report := AllResult{
+		Image:            "img",
+		PolicyViolations: []string{"check secrets is required by the required config"},
+		Checks: []CheckResult{
+			{Check: "age", Image: "img", Passed: true},
+			{Check: "user", Image: "img", Message: "Image runs as root", Details: UserDetails{
+				User:       "root",
+				Violations: []UserViolation{{Rule: "non-root", Message: "user root is not allowed"}},
+			}},
+		},
+	}
+
+	assert.Equal(t, []Finding{
+		{Image: "img", Check: "required-config", Message: "check secrets is required by the required config", Severity: SeverityFailure},
+		{Image: "img", Check: "user", Rule: "non-root", Subject: "root", Message: "user root is not allowed", Severity: SeverityFailure},
+	}, ReportFindings(report))
+}
+
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderCSV(&buf, []Finding{
+		{Image: "img", Check: "labels", Rule: "invalid-label", Subject: "version", Message: `value "1,0" is invalid`, Severity: SeverityFailure},
+	}))
+
+	assert.Equal(t, "image,check,rule,subject,message,severity\n"+
+		`img,labels,invalid-label,version,"value ""1,0"" is invalid",failure`+"\n", buf.String())
+}
+
+func TestRenderCSV_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderCSV(&buf, nil))
+	assert.Equal(t, "image,check,rule,subject,message,severity\n", buf.String())
+}
+
+func TestRenderCSVRows(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderCSVRows(&buf, []Finding{{Image: "img", Check: "age", Message: "Image is too old", Severity: SeverityFailure}}))
+	assert.Equal(t, "img,age,,,Image is too old,failure\n", buf.String())
+}
@@ -12,6 +12,7 @@ type Format string
 const (
 	FormatText Format = "text"
 	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
 )
 
 // ParseFormat parses a string into a Format, returning an error for unsupported values.
@@ -21,8 +22,10 @@ func ParseFormat(s string) (Format, error) {
 		return FormatText, nil
 	case string(FormatJSON):
 		return FormatJSON, nil
+	case string(FormatCSV):
+		return FormatCSV, nil
 	default:
-		return "", fmt.Errorf("unsupported output format %q, valid values are: text, json", s)
+		return "", fmt.Errorf("unsupported output format %q, valid values are: text, json, csv", s)
 	}
 }
 
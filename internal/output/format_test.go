@@ -26,6 +26,11 @@ func TestParseFormat(t *testing.T) {
 			input: "json",
 			want:  FormatJSON,
 		},
+		{
+			name:  "csv format",
+			input: "csv",
+			want:  FormatCSV,
+		},
 		{
 			name:    "unsupported format",
 			input:   "xml",
@@ -114,7 +119,7 @@ func TestRenderJSON(t *testing.T) {
 				Passed:  4,
 				Failed:  1,
 				Errored: 0,
-				Skipped: []string{"registry"},
+				Skipped: []SkippedCheck{{Name: "registry", Reason: SkipReasonSkipFlag}},
 			},
 		}
 
@@ -126,7 +131,8 @@ func TestRenderJSON(t *testing.T) {
 		assert.Contains(t, output, `"image": "nginx:latest"`)
 		assert.Contains(t, output, `"passed": false`)
 		assert.Contains(t, output, `"total": 6`)
-		assert.Contains(t, output, `"registry"`)
+		assert.Contains(t, output, `"name": "registry"`)
+		assert.Contains(t, output, `"reason": "skip-flag"`)
 	})
 
 	t.Run("renders version result", func(t *testing.T) {
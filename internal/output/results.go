@@ -8,6 +8,49 @@ type CheckResult struct {
 	Message string `json:"message"`
 	Details any    `json:"details,omitempty"`
 	Error   string `json:"error,omitempty"`
+	// ErrorKind categorizes Error when the cause is known: not-found,
+	// unauthorized, unavailable or invalid-reference.
+	ErrorKind string `json:"error-kind,omitempty"`
+	// DocsURL links to the documentation of the check.
+	DocsURL string `json:"docs-url,omitempty"`
+	// Remediation suggests how to fix a failed check, when the check knows.
+	Remediation string `json:"remediation,omitempty"`
+	// Exception is set when a failed check was passed by an active
+	// exception (--exceptions).
+	Exception *CheckException `json:"exception,omitempty"`
+	// ExitPolicy is set when the exit-policy of the config file decided
+	// whether the failed check fails the run.
+	ExitPolicy *CheckExitPolicy `json:"exit-policy,omitempty"`
+	// SuppressedFindings lists the findings of a failed check that
+	// suppression labels of the image exempted. The check is passed when
+	// every finding is suppressed.
+	SuppressedFindings []SuppressedFinding `json:"suppressed-findings,omitempty"`
+	// Redacted is set when redaction patterns altered any field of the result.
+	Redacted bool `json:"redacted,omitempty"`
+	// Skipped is set when the check ran but does not apply to the image,
+	// e.g. the registry check of an OCI layout. A skipped result is passed,
+	// and SkipReason says why it was skipped.
+	Skipped    bool   `json:"skipped,omitempty"`
+	SkipReason string `json:"skip-reason,omitempty"`
+}
+
+// Statuses of a check result.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Status returns whether the check passed, failed, or was skipped. Execution
+// errors are failed.
+func (r CheckResult) Status() string {
+	switch {
+	case r.Skipped:
+		return StatusSkipped
+	case r.Passed:
+		return StatusPassed
+	}
+	return StatusFailed
 }
 
 // AgeDetails holds details for the age check.
@@ -15,6 +58,25 @@ type AgeDetails struct {
 	CreatedAt string  `json:"created-at"`
 	AgeDays   float64 `json:"age-days"`
 	MaxAge    uint    `json:"max-age"`
+	// NotBefore and NotAfter are the absolute bounds of the creation date, in
+	// RFC3339, when set.
+	NotBefore string `json:"not-before,omitempty"`
+	NotAfter  string `json:"not-after,omitempty"`
+	// FailedConstraints names the constraints the image failed: max-age,
+	// not-before, not-after.
+	FailedConstraints []string `json:"failed-constraints,omitempty"`
+	// Rule is the match of the checks.age.rules entry that set MaxAge.
+	Rule string `json:"rule,omitempty"`
+	// PolicyWindow is set when a policy window set MaxAge.
+	PolicyWindow *PolicyWindow `json:"policy-window,omitempty"`
+}
+
+// PolicyWindow is a configured validity window whose values were in effect
+// for a check.
+type PolicyWindow struct {
+	From   string `json:"from,omitempty"`
+	Until  string `json:"until,omitempty"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // SizeDetails holds details for the size check.
@@ -25,12 +87,27 @@ type SizeDetails struct {
 	LayerCount int         `json:"layer-count"`
 	MaxLayers  uint        `json:"max-layers"`
 	Layers     []LayerInfo `json:"layers"`
+	// BaseLayerCount is the number of leading base image layers excluded
+	// from the count; it is only set when base layers are not counted.
+	BaseLayerCount *int `json:"base-layer-count,omitempty"`
+	// Index totals are only set when a maximum total size is configured.
+	IndexTotalBytes int64   `json:"index-total-bytes,omitempty"`
+	IndexTotalMB    float64 `json:"index-total-mb,omitempty"`
+	Platforms       int     `json:"platforms,omitempty"`
+	MaxTotalSizeMB  uint    `json:"max-total-size-mb,omitempty"`
+	// PolicyWindow is set when a policy window set any of the limits.
+	PolicyWindow *PolicyWindow `json:"policy-window,omitempty"`
 }
 
 // LayerInfo holds size information for a single layer.
 type LayerInfo struct {
 	Index int   `json:"index"`
 	Bytes int64 `json:"bytes"`
+	// Digest is the digest of the compressed layer.
+	Digest string `json:"digest,omitempty"`
+	// CreatedBy is the history entry (Dockerfile instruction) that created
+	// the layer, when the image history can be aligned with its layers.
+	CreatedBy string `json:"created-by,omitempty"`
 }
 
 // PortsDetails holds details for the ports check.
@@ -38,6 +115,13 @@ type PortsDetails struct {
 	ExposedPorts      []int `json:"exposed-ports"`
 	AllowedPorts      []int `json:"allowed-ports,omitempty"`
 	UnauthorizedPorts []int `json:"unauthorized-ports,omitempty"`
+	MaxExposedPorts   uint  `json:"max-exposed-ports,omitempty"`
+	// PrivilegedPorts lists the exposed ports below 1024 when they are
+	// forbidden.
+	PrivilegedPorts []int `json:"privileged-ports,omitempty"`
+	// FailedConstraints names the constraints the image failed:
+	// allowed-ports, max-exposed-ports, or forbid-privileged-ports.
+	FailedConstraints []string `json:"failed-constraints,omitempty"`
 }
 
 // RegistryDetails holds details for the registry check.
@@ -53,12 +137,17 @@ type SecretsDetails struct {
 	TotalFindings  int             `json:"total-findings"`
 	EnvVarCount    int             `json:"env-var-count"`
 	FileCount      int             `json:"file-count"`
+	// FailOnSeverity is the least severity that fails the check; findings
+	// below it are reported without failing. Empty when every finding fails.
+	FailOnSeverity string `json:"fail-on-severity,omitempty"`
 }
 
 // EnvVarFinding represents a sensitive environment variable finding.
 type EnvVarFinding struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Severity is low, medium, high, or critical.
+	Severity string `json:"severity,omitempty"`
 }
 
 // FileFinding represents a sensitive file finding.
@@ -66,6 +155,16 @@ type FileFinding struct {
 	Path        string `json:"path"`
 	LayerIndex  int    `json:"layer-index"`
 	Description string `json:"description"`
+	// LayerDigest is the digest of the compressed layer holding the file.
+	LayerDigest string `json:"layer-digest,omitempty"`
+	// Severity is low, medium, high, or critical.
+	Severity string `json:"severity,omitempty"`
+	// CreatedBy is the history entry (Dockerfile instruction) that created
+	// the layer, when the image history can be aligned with its layers.
+	CreatedBy string `json:"created-by,omitempty"`
+	// BaseLayer reports whether the layer belongs to the base image declared
+	// by the image; it is only set when a base image is declared.
+	BaseLayer *bool `json:"base-layer,omitempty"`
 }
 
 // HealthcheckDetails holds details for the healthcheck check.
@@ -110,6 +209,20 @@ type EntrypointDetails struct {
 	ShellFormAllowed bool     `json:"shell-form-allowed,omitempty"`
 	Entrypoint       []string `json:"entrypoint,omitempty"`
 	Cmd              []string `json:"cmd,omitempty"`
+	// Shell is the shell that shell-form commands run, and ShellAvailable
+	// whether it exists in the image layers; nil when they could not be read.
+	Shell          string `json:"shell,omitempty"`
+	ShellAvailable *bool  `json:"shell-available,omitempty"`
+	// Violations lists the entrypoint policy rules the startup command
+	// breaks.
+	Violations []EntrypointViolation `json:"violations,omitempty"`
+}
+
+// EntrypointViolation represents a single entrypoint policy failure.
+type EntrypointViolation struct {
+	Rule     string `json:"rule"`
+	Argument string `json:"argument"`
+	Message  string `json:"message"`
 }
 
 // UserDetails holds details for the user check.
@@ -130,21 +243,329 @@ type UserViolation struct {
 	Message string `json:"message"`
 }
 
+// LazyPullDetails holds details for the lazy-pull check.
+type LazyPullDetails struct {
+	// Format is the lazy-pull format of the image (estargz or nydus), empty
+	// when it has none.
+	Format          string   `json:"format"`
+	AcceptedFormats []string `json:"accepted-formats"`
+	Layers          int      `json:"layers"`
+	EStargzLayers   int      `json:"estargz-layers"`
+	NydusBlobLayers int      `json:"nydus-blob-layers"`
+	NydusBootstrap  bool     `json:"nydus-bootstrap"`
+}
+
+// DeprecationDetails holds details for the deprecation check.
+type DeprecationDetails struct {
+	// OfficialImage is the name of the Docker Hub official image, empty when
+	// the image is not one.
+	OfficialImage string `json:"official-image,omitempty"`
+	Deprecated    bool   `json:"deprecated"`
+	// Notice is the first paragraph of the deprecation notice on Docker Hub.
+	Notice      string `json:"notice,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// ArchitectureDetails holds details for the architecture check.
+type ArchitectureDetails struct {
+	// Architecture is the architecture the image config declares.
+	Architecture string `json:"architecture"`
+	// MaxBinaries is the most ELF binaries inspected, 0 for all.
+	MaxBinaries uint `json:"max-binaries"`
+	// InspectedBinaries is the number of ELF binaries inspected.
+	InspectedBinaries int `json:"inspected-binaries"`
+	// Architectures counts the inspected binaries by architecture.
+	Architectures map[string]int         `json:"architectures,omitempty"`
+	Mismatches    []ArchitectureMismatch `json:"mismatches,omitempty"`
+}
+
+// ArchitectureMismatch is an ELF binary built for another architecture than
+// the one the image declares.
+type ArchitectureMismatch struct {
+	Path       string `json:"path"`
+	LayerIndex int    `json:"layer-index"`
+	// LayerDigest is the digest of the compressed layer holding the binary.
+	LayerDigest  string `json:"layer-digest,omitempty"`
+	Architecture string `json:"architecture"`
+}
+
+// MinimalDetails holds details for the minimal check.
+type MinimalDetails struct {
+	// Criteria lists the criteria the image was evaluated against.
+	Criteria []string `json:"criteria"`
+	// FailedCriteria lists the criteria the image does not meet.
+	FailedCriteria  []string `json:"failed-criteria,omitempty"`
+	Shells          []string `json:"shells,omitempty"`
+	PackageManagers []string `json:"package-managers,omitempty"`
+	Compilers       []string `json:"compilers,omitempty"`
+	TotalBytes      int64    `json:"total-bytes"`
+	TotalMB         float64  `json:"total-mb"`
+	MaxSizeMB       uint     `json:"max-size-mb"`
+}
+
+// SmokeDetails holds details for the smoke check.
+type SmokeDetails struct {
+	// Command replaces the CMD of the image when set.
+	Command []string `json:"command,omitempty"`
+	// Mode is exit when the container must exit with code 0, or healthy when
+	// it must keep running.
+	Mode              string  `json:"mode"`
+	TimeoutSeconds    float64 `json:"timeout-seconds,omitempty"`
+	HealthyForSeconds float64 `json:"healthy-for-seconds,omitempty"`
+	// ExitCode is the exit code of the container, omitted when it did not exit.
+	ExitCode *int `json:"exit-code,omitempty"`
+	// Health is the last health status of an image with a HEALTHCHECK.
+	Health   string `json:"health,omitempty"`
+	TimedOut bool   `json:"timed-out,omitempty"`
+	// Pulled reports that the daemon did not have the image and pulled it.
+	Pulled         bool    `json:"pulled,omitempty"`
+	ElapsedSeconds float64 `json:"elapsed-seconds"`
+	// Logs are the last lines the container wrote.
+	Logs []string `json:"logs,omitempty"`
+	// ImageID is the ID of the image the container ran.
+	ImageID string `json:"image-id,omitempty"`
+	// MemoryMB and PidsLimit are the resource limits of the container, 0
+	// when unlimited.
+	MemoryMB  uint `json:"memory-mb,omitempty"`
+	PidsLimit uint `json:"pids-limit,omitempty"`
+}
+
+// EntropyDetails holds details for the entropy check.
+type EntropyDetails struct {
+	// MinSize is the size in megabytes from which files are measured.
+	MinSize uint `json:"min-size"`
+	// MaxEntropy is the highest accepted entropy, in bits per byte.
+	MaxEntropy float64 `json:"max-entropy"`
+	// MeasuredFiles is the number of files large enough to be measured.
+	MeasuredFiles int              `json:"measured-files"`
+	Findings      []EntropyFinding `json:"findings,omitempty"`
+}
+
+// EntropyFinding is a large file whose content is closer to random than the
+// entropy policy accepts.
+type EntropyFinding struct {
+	Path       string `json:"path"`
+	LayerIndex int    `json:"layer-index"`
+	// LayerDigest is the digest of the compressed layer holding the file.
+	LayerDigest string `json:"layer-digest,omitempty"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// Entropy is the Shannon entropy of the file, in bits per byte (0 to 8).
+	Entropy float64 `json:"entropy"`
+	// Format is the compressed format the file starts with, when it was
+	// measured because the policy checks known formats.
+	Format string `json:"format,omitempty"`
+}
+
+// DriftDetails holds details for the drift check.
+type DriftDetails struct {
+	Differences []DriftDifference `json:"differences"`
+}
+
+// DriftDifference is a difference between the image configuration and the
+// golden spec. Kind is added, removed, or changed; Key names the env var,
+// port, or label for list and map fields.
+type DriftDifference struct {
+	Field    string `json:"field"`
+	Kind     string `json:"kind"`
+	Key      string `json:"key,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// DriftRecordResult holds the outcome of recording a golden spec with
+// drift --record.
+type DriftRecordResult struct {
+	Image string `json:"image"`
+	Path  string `json:"path"`
+	Spec  any    `json:"spec"`
+}
+
+// ProvenanceDetails holds details for the provenance check.
+type ProvenanceDetails struct {
+	// Provenance lists the SLSA provenance statements that apply to the image.
+	Provenance         []ProvenanceStatement `json:"provenance"`
+	TrustedBuilders    []string              `json:"trusted-builders,omitempty"`
+	SourceRepositories []string              `json:"source-repositories,omitempty"`
+	BuildTypes         []string              `json:"build-types,omitempty"`
+	TrustedKeys        []string              `json:"trusted-keys,omitempty"`
+	AllowUnsigned      bool                  `json:"allow-unsigned,omitempty"`
+	Violations         []ProvenanceViolation `json:"violations,omitempty"`
+}
+
+// ProvenanceStatement describes one SLSA provenance attestation of an image.
+type ProvenanceStatement struct {
+	// Source is where the attestation was found: index, referrer, or cosign.
+	Source           string `json:"source"`
+	Manifest         string `json:"manifest"`
+	PredicateType    string `json:"predicate-type"`
+	BuilderID        string `json:"builder-id"`
+	BuildType        string `json:"build-type"`
+	SourceRepository string `json:"source-repository,omitempty"`
+	// Signed reports whether the statement carried DSSE signatures, and
+	// Verified whether one of them was made by a trusted key.
+	Signed   bool `json:"signed"`
+	Verified bool `json:"verified"`
+}
+
+// ProvenanceViolation represents a single provenance validation failure.
+type ProvenanceViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
 // AllResult is the aggregated result for the "all" command.
 type AllResult struct {
-	Image   string        `json:"image"`
-	Passed  bool          `json:"passed"`
-	Checks  []CheckResult `json:"checks"`
-	Summary Summary       `json:"summary"`
+	Image  string        `json:"image"`
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+	// PolicyViolations lists attempts to skip checks enforced by --required-config.
+	PolicyViolations []string `json:"policy-violations,omitempty"`
+	Summary          Summary  `json:"summary"`
+	// Redacted is set when redaction patterns altered the image or policy
+	// violations. Altered checks carry their own marker.
+	Redacted bool `json:"redacted,omitempty"`
+	// Annotation is the digest of the referrer artifact that records the
+	// outcome in the registry (--annotate-registry).
+	Annotation string `json:"annotation,omitempty"`
+	// PolicyHash identifies the checks, parameters, and policy files the image
+	// was validated against.
+	PolicyHash string `json:"policy-hash,omitempty"`
+	// PolicyProfile is the named policy profile (--policy-dir) the image was
+	// validated with.
+	PolicyProfile string `json:"policy-profile,omitempty"`
+	// ServedBy is the registry endpoint, a mirror or the upstream registry,
+	// that served the image when its registry has mirrors (--registry-mirror).
+	ServedBy string `json:"served-by,omitempty"`
+	// EffectiveConfig records the parameters of the executed checks
+	// (--effective-config).
+	EffectiveConfig *EffectiveConfig `json:"effective-config,omitempty"`
+	// Metadata identifies the check-image build and the files that produced
+	// the report.
+	Metadata *ReportMetadata `json:"metadata,omitempty"`
+}
+
+// ReportMetadata identifies the check-image build, config file, and policy
+// files a report was produced with. Files are recorded by the sha256 digest
+// of their content, or "stdin" when they were read from stdin.
+type ReportMetadata struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	ConfigHash string `json:"config-hash,omitempty"`
+	// PolicyFiles maps the policy flags of the selected checks to the digest
+	// of their file.
+	PolicyFiles map[string]string `json:"policy-files,omitempty"`
+}
+
+// EffectiveConfig records the resolved parameters every executed check ran
+// with, so that a report describes the policy it was validated against.
+type EffectiveConfig struct {
+	// Checks maps each executed check to its parameters, named like the keys
+	// of the checks section of a config file. Policy files are recorded by
+	// the sha256 digest of their content.
+	Checks map[string]map[string]any `json:"checks"`
+}
+
+// BulkResult is the aggregated result of the "all" command when the images
+// to validate are read from stdin.
+type BulkResult struct {
+	Passed bool `json:"passed"`
+	// Images is left empty when the results are grouped into Repositories,
+	// or keyed by service name in Services (--image-template) or by bake
+	// target name in Targets (bake).
+	Images       []AllResult          `json:"images,omitempty"`
+	Repositories []RepositoryResult   `json:"repositories,omitempty"`
+	Services     map[string]AllResult `json:"services,omitempty"`
+	Targets      map[string]AllResult `json:"targets,omitempty"`
+	Summary      BulkSummary          `json:"summary"`
+}
+
+// BulkSummary counts the images of a bulk run by outcome.
+type BulkSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Repositories is only set when the results are grouped by repository.
+	Repositories int `json:"repositories,omitempty"`
+}
+
+// Outcomes of an image or repository, from best to worst.
+const (
+	OutcomePassed  = "passed"
+	OutcomeFailed  = "failed"
+	OutcomeErrored = "errored"
+)
+
+// RepositoryResult aggregates the results of the images of one repository in
+// a bulk run (--group-by repository). Outcome is the worst outcome of its
+// images.
+type RepositoryResult struct {
+	Repository string      `json:"repository"`
+	Passed     bool        `json:"passed"`
+	Outcome    string      `json:"outcome"`
+	Images     []AllResult `json:"images"`
+	Summary    BulkSummary `json:"summary"`
+}
+
+// ValidationAnnotation is the payload of the referrer artifact pushed with
+// --annotate-registry. It records the outcome of a validation run so other
+// tools can discover it from the registry.
+type ValidationAnnotation struct {
+	Image      string   `json:"image"`
+	Digest     string   `json:"digest"`
+	Passed     bool     `json:"passed"`
+	PolicyHash string   `json:"policy-hash"`
+	Checks     []string `json:"checks"`
+	Version    string   `json:"version"`
+	CheckedAt  string   `json:"checked-at"`
 }
 
 // Summary holds counts for the "all" command.
 type Summary struct {
-	Total   int      `json:"total"`
-	Passed  int      `json:"passed"`
-	Failed  int      `json:"failed"`
-	Errored int      `json:"errored"`
-	Skipped []string `json:"skipped,omitempty"`
+	Total   int            `json:"total"`
+	Passed  int            `json:"passed"`
+	Failed  int            `json:"failed"`
+	Errored int            `json:"errored"`
+	Skipped []SkippedCheck `json:"skipped,omitempty"`
+}
+
+// Reasons a check did not run, reported in SkippedCheck.
+const (
+	// SkipReasonSkipFlag marks a check excluded with --skip.
+	SkipReasonSkipFlag = "skip-flag"
+	// SkipReasonNotIncluded marks a check not listed in --include.
+	SkipReasonNotIncluded = "not-included"
+	// SkipReasonNotInConfig marks a check absent from the --config file.
+	SkipReasonNotInConfig = "not-in-config"
+	// SkipReasonFailFast marks a selected check that did not run because
+	// --fail-fast stopped at an earlier failure.
+	SkipReasonFailFast = "fail-fast"
+	// SkipReasonNoPolicy marks an opt-in check that was not requested because
+	// its policy was not provided.
+	SkipReasonNoPolicy = "no-policy"
+	// SkipReasonNotInDefaults marks a check absent from the defaults.checks
+	// of the global config, without --config.
+	SkipReasonNotInDefaults = "not-in-defaults"
+	// SkipReasonMetadataFailure marks a layer check that did not run because
+	// a metadata check failed with --early-exit-on-metadata-failure.
+	SkipReasonMetadataFailure = "metadata-failure"
+	// SkipReasonNotApplicable marks a check that ran and found it does not
+	// apply to the image, such as the registry check of an image that is not
+	// in a registry.
+	SkipReasonNotApplicable = "not-applicable"
+	// SkipReasonNotContainerImage marks a check that could not run because
+	// the image is an OCI artifact other than a container image, such as a
+	// Helm chart, with --artifacts skip.
+	SkipReasonNotContainerImage = "not-container-image"
+)
+
+// SkippedCheck records a check that did not run and why, so intentional
+// skips can be told apart from checks that could not run.
+type SkippedCheck struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	// Message is the message of a check skipped by itself.
+	Message string `json:"message,omitempty"`
 }
 
 // VersionResult holds the short version output for JSON mode (--short flag).
@@ -160,3 +581,212 @@ type BuildInfoResult struct {
 	GoVersion string `json:"go-version"`
 	Platform  string `json:"platform"`
 }
+
+// ReportVerificationResult holds the outcome of the verify-report command.
+type ReportVerificationResult struct {
+	Report    string `json:"report"`
+	Signature string `json:"signature"`
+	Valid     bool   `json:"valid"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Message   string `json:"message"`
+}
+
+// ReportDiffResult holds the outcome of the report diff command.
+type ReportDiffResult struct {
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	OldImage string `json:"old-image"`
+	NewImage string `json:"new-image"`
+	// Passed is false when the new report regressed: a check newly fails or
+	// a check reports a new finding.
+	Passed          bool                `json:"passed"`
+	NewlyFailing    []string            `json:"newly-failing"`
+	NewlyPassing    []string            `json:"newly-passing"`
+	NewFindings     []ReportDiffFinding `json:"new-findings"`
+	RemovedFindings []ReportDiffFinding `json:"removed-findings"`
+}
+
+// ReportDiffFinding is a finding present in only one of two compared reports.
+type ReportDiffFinding struct {
+	Check string `json:"check"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// PolicyLintResult holds the outcome of the policy lint command.
+type PolicyLintResult struct {
+	Passed   bool                `json:"passed"`
+	Findings []PolicyLintFinding `json:"findings"`
+	Summary  PolicyLintSummary   `json:"summary"`
+}
+
+// PolicyLintFinding is a problem found in a policy. Field names the policy key
+// and list index the finding is about, when it concerns one.
+type PolicyLintFinding struct {
+	Policy   string `json:"policy"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// PolicyLintSummary counts the findings of the policy lint command.
+type PolicyLintSummary struct {
+	Policies int `json:"policies"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+// PromoteResult holds the outcome of the promote command.
+type PromoteResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Promoted    bool   `json:"promoted"`
+	Digest      string `json:"digest,omitempty"`
+	// Attestation is the digest of the validation report attached to the
+	// promoted image when --attest is set.
+	Attestation string    `json:"attestation,omitempty"`
+	Validation  AllResult `json:"validation"`
+	// Redacted is set when redaction patterns altered the source or destination.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// StatusResult holds the outcome of the status command: the most recent
+// validation recorded in the registry for an image digest.
+type StatusResult struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+	// Validated is false when no validation marker or attestation was found.
+	Validated   bool   `json:"validated"`
+	Passed      bool   `json:"passed"`
+	ValidatedAt string `json:"validated-at,omitempty"`
+	PolicyHash  string `json:"policy-hash,omitempty"`
+	// Marker is the digest of the referrer artifact the status was read from.
+	Marker  string `json:"marker,omitempty"`
+	Markers int    `json:"markers"`
+	Message string `json:"message"`
+}
+
+// CopyResult holds the outcome of the copy command.
+type CopyResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Digest      string `json:"digest"`
+}
+
+// CheckException describes an exception that exempts an image from a check.
+type CheckException struct {
+	Digest   string   `json:"digest"`
+	Checks   []string `json:"checks,omitempty"`
+	Approver string   `json:"approver"`
+	Ticket   string   `json:"ticket,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Expires  string   `json:"expires"`
+	// Expired is set by exceptions list for exceptions no longer in effect.
+	Expired bool `json:"expired,omitempty"`
+}
+
+// CheckExitPolicy is the exit policy decision for a failed check: the
+// highest severity of its findings with the strictest action, fail, warn, or
+// ignore. Checks only warned about or ignored are passed.
+type CheckExitPolicy struct {
+	Severity string `json:"severity"`
+	Action   string `json:"action"`
+}
+
+// SuppressedFinding is a finding exempted by a suppression label of the
+// image, allowed by the label-suppressions of the config file.
+type SuppressedFinding struct {
+	Rule    string `json:"rule,omitempty"`
+	Subject string `json:"subject"`
+	// Label is the key of the label that suppressed the finding.
+	Label   string `json:"label"`
+	Expires string `json:"expires,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ExceptionsListResult holds the outcome of the exceptions list command.
+type ExceptionsListResult struct {
+	File string `json:"file"`
+	// Expiring is the look-ahead window of --expiring, empty when every
+	// exception is listed.
+	Expiring   string           `json:"expiring,omitempty"`
+	Exceptions []CheckException `json:"exceptions"`
+	Expired    int              `json:"expired"`
+}
+
+// ConfigMigrationResult holds the outcome of the config migrate command.
+type ConfigMigrationResult struct {
+	File    string         `json:"file"`
+	Changes []ConfigChange `json:"changes"`
+	Written bool           `json:"written"`
+	// Config is the migrated configuration when it was not written back.
+	Config string `json:"config,omitempty"`
+}
+
+// ConfigValidationResult holds the outcome of the config validate command.
+type ConfigValidationResult struct {
+	File   string `json:"file"`
+	Valid  bool   `json:"valid"`
+	Strict bool   `json:"strict"`
+	// Error is the first problem found when the config is not valid.
+	Error string `json:"error,omitempty"`
+}
+
+// ConfigChange describes a deprecated configuration key that was migrated.
+type ConfigChange struct {
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Since   string `json:"since"`
+	Message string `json:"message"`
+}
+
+// TagHygieneResult holds the outcome of the tag hygiene check of an audit.
+type TagHygieneResult struct {
+	Check      string     `json:"check"`
+	Repository string     `json:"repository"`
+	Passed     bool       `json:"passed"`
+	Message    string     `json:"message"`
+	MaxIssues  uint       `json:"max-issues"`
+	Issues     []TagIssue `json:"issues"`
+}
+
+// TagIssue is a tag hygiene problem of a repository.
+type TagIssue struct {
+	// Kind is latest-diverges or repushed.
+	Kind   string `json:"kind"`
+	Tag    string `json:"tag"`
+	Digest string `json:"digest"`
+	// ExpectedDigest is the digest of the newest release for latest, or the
+	// digest recorded by an earlier audit for a re-pushed tag.
+	ExpectedDigest string `json:"expected-digest"`
+	Message        string `json:"message"`
+}
+
+// CleanupResult holds the outcome of the cleanup command.
+type CleanupResult struct {
+	DryRun bool `json:"dry-run"`
+	// TempDir is the directory searched for temporary files.
+	TempDir string `json:"temp-dir"`
+	// CacheDir is the layer cache directory, when one was given.
+	CacheDir string `json:"cache-dir,omitempty"`
+	// Items are the files and directories removed, or that would be with
+	// DryRun.
+	Items []CleanupItem `json:"items"`
+	// RemovedBytes is the total size of Items.
+	RemovedBytes int64 `json:"removed-bytes"`
+	// CacheBytes is the size of the layer cache left after the cleanup.
+	CacheBytes int64 `json:"cache-bytes,omitempty"`
+}
+
+// CleanupItem is a file or directory selected by the cleanup command.
+type CleanupItem struct {
+	Path string `json:"path"`
+	// Kind is the kind of leftover, e.g. temp-policy, oci-archive, or
+	// cache-entry.
+	Kind     string `json:"kind"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+	// Reason is stale, expired, or over-size.
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// Stdout writes to the os.Stdout of the time of each write, so that writers
+// created at startup follow later redirections of os.Stdout.
+var Stdout io.Writer = stdoutWriter{}
+
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// Sink serializes the writes of concurrent producers to one writer, so that
+// every Write reaches it whole, without interleaving with other writes.
+// Producers that write several times, such as the renderer of a check, buffer
+// their output in a Section and flush it at once.
+type Sink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewSink returns a Sink writing to w.
+func NewSink(w io.Writer) *Sink {
+	return &Sink{w: w}
+}
+
+// Write writes p to the underlying writer while holding the lock of the sink.
+func (s *Sink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Section returns an empty buffer whose content is written to the sink in a
+// single write by Flush.
+func (s *Sink) Section() *Section {
+	return &Section{sink: s}
+}
+
+// Section buffers the output of one producer of a Sink. It is not safe for
+// concurrent use; every producer uses its own.
+type Section struct {
+	sink *Sink
+	buf  bytes.Buffer
+}
+
+// Write appends p to the buffer of the section.
+func (s *Section) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+// Flush writes the buffered output to the sink in a single write and empties
+// the buffer.
+func (s *Section) Flush() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	defer s.buf.Reset()
+	_, err := s.sink.Write(s.buf.Bytes())
+	return err
+}
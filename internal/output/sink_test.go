@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingWriter records every write it receives separately.
+type recordingWriter struct {
+	writes []string
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestSink_SectionsAreWrittenWhole(t *testing.T) {
+	var rec recordingWriter
+	sink := NewSink(&rec)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			section := sink.Section()
+			for line := range 10 {
+				_, _ = fmt.Fprintf(section, "check %d line %d\n", i, line)
+			}
+			assert.NoError(t, section.Flush())
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, rec.writes, 20, "each section is a single write")
+	for _, w := range rec.writes {
+		lines := strings.Split(strings.TrimSuffix(w, "\n"), "\n")
+		require.Len(t, lines, 10)
+		prefix, _, _ := strings.Cut(lines[0], " line ")
+		for _, l := range lines {
+			assert.True(t, strings.HasPrefix(l, prefix+" line "), "lines of other sections are not interleaved: %q", w)
+		}
+	}
+}
+
+func TestSection_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	section := NewSink(&buf).Section()
+
+	require.NoError(t, section.Flush())
+	assert.Empty(t, buf.String(), "empty sections write nothing")
+
+	_, _ = section.Write([]byte("first\n"))
+	require.NoError(t, section.Flush())
+	_, _ = section.Write([]byte("second\n"))
+	require.NoError(t, section.Flush())
+	assert.Equal(t, "first\nsecond\n", buf.String(), "the buffer is emptied by each flush")
+}
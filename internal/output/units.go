@@ -0,0 +1,63 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Units selects how byte sizes are written in text output and messages. JSON
+// output always keeps the raw byte counts.
+type Units string
+
+const (
+	// UnitsMB writes sizes in megabytes of 1024*1024 bytes, e.g. "12.34 MB",
+	// the format of every size limit.
+	UnitsMB Units = "mb"
+	// UnitsIEC scales sizes to the largest fitting binary unit, e.g. "1.50 GiB".
+	UnitsIEC Units = "iec"
+	// UnitsSI scales sizes to the largest fitting decimal unit, e.g. "1.61 GB".
+	UnitsSI Units = "si"
+)
+
+// ParseUnits parses a string into Units, returning an error for unsupported
+// values.
+func ParseUnits(s string) (Units, error) {
+	switch u := Units(strings.ToLower(s)); u {
+	case UnitsMB, UnitsIEC, UnitsSI:
+		return u, nil
+	default:
+		return "", fmt.Errorf("unsupported units %q, valid values are: mb, iec, si", s)
+	}
+}
+
+// FormatBytes writes a byte count in units u. Unknown units fall back to
+// UnitsMB.
+func FormatBytes(bytes int64, u Units) string {
+	switch u {
+	case UnitsIEC:
+		return scaleBytes(bytes, 1024, []string{"KiB", "MiB", "GiB", "TiB", "PiB"})
+	case UnitsSI:
+		return scaleBytes(bytes, 1000, []string{"kB", "MB", "GB", "TB", "PB"})
+	default:
+		return fmt.Sprintf("%.2f MB", float64(bytes)/1024/1024)
+	}
+}
+
+// scaleBytes writes bytes in the largest of units, each base times the
+// previous one, that keeps the value at least 1. Counts under base are
+// written in bytes.
+func scaleBytes(bytes int64, base float64, units []string) string {
+	value := float64(bytes)
+	if value < base {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	unit := ""
+	for _, u := range units {
+		if value < base {
+			break
+		}
+		value /= base
+		unit = u
+	}
+	return fmt.Sprintf("%.2f %s", value, unit)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Units
+		wantErr bool
+	}{
+		{input: "mb", want: UnitsMB},
+		{input: "iec", want: UnitsIEC},
+		{input: "SI", want: UnitsSI},
+		{input: "gb", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseUnits(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "unsupported units")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes int64
+		units Units
+		want  string
+	}{
+		{"mb", 5 * 1024 * 1024, UnitsMB, "5.00 MB"},
+		{"mb large", 3 * 1024 * 1024 * 1024, UnitsMB, "3072.00 MB"},
+		{"empty units", 1024 * 1024, "", "1.00 MB"},
+		{"iec bytes", 512, UnitsIEC, "512 B"},
+		{"iec kib", 1536, UnitsIEC, "1.50 KiB"},
+		{"iec mib", 5 * 1024 * 1024, UnitsIEC, "5.00 MiB"},
+		{"iec gib", 3 * 1024 * 1024 * 1024, UnitsIEC, "3.00 GiB"},
+		{"si bytes", 999, UnitsSI, "999 B"},
+		{"si mb", 5 * 1024 * 1024, UnitsSI, "5.24 MB"},
+		{"si gb", 3 * 1024 * 1024 * 1024, UnitsSI, "3.22 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatBytes(tt.bytes, tt.units))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package policyexport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/jarfernandez/check-image/internal/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the admission controller a policy is exported for.
+type Format string
+
+const (
+	FormatKyverno    Format = "kyverno"
+	FormatGatekeeper Format = "gatekeeper"
+)
+
+// ParseFormat parses a string into a Format, returning an error for unsupported values.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatKyverno, FormatGatekeeper:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unsupported policy format %q, valid values are: kyverno, gatekeeper", s)
+	}
+}
+
+// dockerHubRegistry is the name admission controllers use for Docker Hub.
+// check-image reports it as index.docker.io, so both spellings are mapped here.
+const dockerHubRegistry = "docker.io"
+
+// Input holds the subset of check-image policies that can be enforced at
+// admission time.
+type Input struct {
+	// Name is used as the prefix for every generated resource name.
+	Name          string
+	Registry      *registry.Policy
+	Labels        *labels.Policy
+	RequireDigest bool
+}
+
+// Resource is a generic Kubernetes object. Field order follows the usual
+// manifest layout when rendered as YAML.
+type Resource struct {
+	APIVersion string         `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string         `json:"kind"       yaml:"kind"`
+	Metadata   Metadata       `json:"metadata"   yaml:"metadata"`
+	Spec       map[string]any `json:"spec"       yaml:"spec"`
+}
+
+// Metadata is the subset of Kubernetes object metadata set on exported resources.
+type Metadata struct {
+	Name        string            `json:"name"                  yaml:"name"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Result is the outcome of an export: the generated resources and warnings
+// about policies that could not be translated for the chosen format.
+type Result struct {
+	Resources []Resource
+	Warnings  []string
+}
+
+// Export translates in into native policies for format.
+func Export(format Format, in Input) (*Result, error) {
+	if in.Registry == nil && in.Labels == nil && !in.RequireDigest {
+		return nil, fmt.Errorf("nothing to export: provide a registry policy, a labels policy, or --require-digest")
+	}
+	if in.Name == "" {
+		in.Name = "check-image"
+	}
+
+	switch format {
+	case FormatKyverno:
+		return exportKyverno(in), nil
+	case FormatGatekeeper:
+		return exportGatekeeper(in), nil
+	default:
+		return nil, fmt.Errorf("unsupported policy format %q", format)
+	}
+}
+
+// normalizeRegistries maps registry names to the form used by admission
+// controllers and removes duplicates while preserving order.
+func normalizeRegistries(regs []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(regs))
+	for _, r := range regs {
+		r = strings.TrimSpace(r)
+		if r == "index.docker.io" || r == "registry-1.docker.io" {
+			r = dockerHubRegistry
+		}
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// RenderYAML writes resources as a multi-document YAML stream suitable for
+// kubectl apply -f.
+func RenderYAML(w io.Writer, resources []Resource) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, r := range resources {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("error encoding %s %s: %w", r.Kind, r.Metadata.Name, err)
+		}
+	}
+	return enc.Close()
+}
+
+// RenderJSON writes resources as a Kubernetes v1 List.
+func RenderJSON(w io.Writer, resources []Resource) error {
+	return output.RenderJSON(w, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      resources,
+	})
+}
@@ -0,0 +1,209 @@
+package policyexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("kyverno")
+	require.NoError(t, err)
+	assert.Equal(t, FormatKyverno, f)
+
+	f, err = ParseFormat("gatekeeper")
+	require.NoError(t, err)
+	assert.Equal(t, FormatGatekeeper, f)
+
+	_, err = ParseFormat("opa")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported policy format")
+}
+
+func TestNormalizeRegistries(t *testing.T) {
+	got := normalizeRegistries([]string{"index.docker.io", "docker.io", " ghcr.io ", "", "registry-1.docker.io"})
+	assert.Equal(t, []string{"docker.io", "ghcr.io"}, got)
+}
+
+func TestExport_NothingToExport(t *testing.T) {
+	_, err := Export(FormatKyverno, Input{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nothing to export")
+}
+
+func TestExport_Kyverno(t *testing.T) {
+	tests := []struct {
+		name             string
+		in               Input
+		expectedRules    []string
+		expectedOperator string
+		expectWarnings   bool
+	}{
+		{
+			name:             "Registry allowlist",
+			in:               Input{Registry: &registry.Policy{TrustedRegistries: []string{"ghcr.io"}}},
+			expectedRules:    []string{"registry"},
+			expectedOperator: "AnyNotIn",
+		},
+		{
+			name:             "Registry blocklist",
+			in:               Input{Registry: &registry.Policy{ExcludedRegistries: []string{"docker.io"}}},
+			expectedRules:    []string{"registry"},
+			expectedOperator: "AnyIn",
+		},
+		{
+			name:          "Digest pinning",
+			in:            Input{RequireDigest: true},
+			expectedRules: []string{"require-digest"},
+		},
+		{
+			name: "All policies",
+			in: Input{
+				Registry:      &registry.Policy{TrustedRegistries: []string{"ghcr.io"}},
+				Labels:        &labels.Policy{RequiredLabels: []labels.LabelRequirement{{Name: "maintainer"}}},
+				RequireDigest: true,
+			},
+			expectedRules:    []string{"registry", "require-digest", "required-labels"},
+			expectedOperator: "AnyNotIn",
+			expectWarnings:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Export(FormatKyverno, tt.in)
+			require.NoError(t, err)
+			require.Len(t, res.Resources, 1)
+
+			policy := res.Resources[0]
+			assert.Equal(t, "kyverno.io/v1", policy.APIVersion)
+			assert.Equal(t, "ClusterPolicy", policy.Kind)
+			assert.Equal(t, "check-image", policy.Metadata.Name)
+
+			rules, ok := policy.Spec["rules"].([]any)
+			require.True(t, ok)
+			var names []string
+			for _, r := range rules {
+				names = append(names, r.(kyvernoRule).Name)
+			}
+			assert.Equal(t, tt.expectedRules, names)
+			assert.Equal(t, tt.expectWarnings, len(res.Warnings) > 0)
+
+			if tt.expectedOperator != "" {
+				var buf bytes.Buffer
+				require.NoError(t, RenderYAML(&buf, res.Resources))
+				assert.Contains(t, buf.String(), "operator: "+tt.expectedOperator)
+			}
+		})
+	}
+}
+
+func TestKyvernoLabelConditions(t *testing.T) {
+	p := &labels.Policy{RequiredLabels: []labels.LabelRequirement{
+		{Name: "maintainer"},
+		{Name: "vendor", Value: "Acme"},
+		{Name: "version", Pattern: `^v\d+'x$`},
+		{Name: `odd"name`},
+	}}
+
+	conds := kyvernoLabelConditions(p)
+	require.Len(t, conds, 4)
+
+	exists := conds[0].(map[string]any)
+	assert.Equal(t, `{{ imageData.configData.config.Labels."maintainer" || '' }}`, exists["key"])
+	assert.Equal(t, "Equals", exists["operator"])
+	assert.Equal(t, "", exists["value"])
+
+	value := conds[1].(map[string]any)
+	assert.Equal(t, "NotEquals", value["operator"])
+	assert.Equal(t, "Acme", value["value"])
+
+	pattern := conds[2].(map[string]any)
+	assert.Equal(t, `{{ regex_match('^v\d+\'x$', imageData.configData.config.Labels."version" || '') }}`, pattern["key"])
+	assert.Equal(t, false, pattern["value"])
+
+	quoted := conds[3].(map[string]any)
+	assert.Contains(t, quoted["key"], `Labels."odd\"name"`)
+}
+
+func TestExport_Gatekeeper(t *testing.T) {
+	res, err := Export(FormatGatekeeper, Input{
+		Name:          "prod",
+		Registry:      &registry.Policy{TrustedRegistries: []string{"index.docker.io", "ghcr.io"}},
+		Labels:        &labels.Policy{RequiredLabels: []labels.LabelRequirement{{Name: "maintainer"}}},
+		RequireDigest: true,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Resources, 4)
+	assert.Equal(t, "ConstraintTemplate", res.Resources[0].Kind)
+	assert.Equal(t, "checkimagetrustedregistries", res.Resources[0].Metadata.Name)
+	assert.Equal(t, "CheckImageTrustedRegistries", res.Resources[1].Kind)
+	assert.Equal(t, "prod-registry", res.Resources[1].Metadata.Name)
+	assert.Equal(t,
+		map[string]any{"trustedRegistries": []string{"docker.io", "ghcr.io"}},
+		res.Resources[1].Spec["parameters"])
+	assert.Equal(t, "ConstraintTemplate", res.Resources[2].Kind)
+	assert.Equal(t, "CheckImageRequireDigest", res.Resources[3].Kind)
+
+	// Labels cannot be enforced by Gatekeeper and are reported as a warning.
+	require.Len(t, res.Warnings, 1)
+	assert.Contains(t, res.Warnings[0], "labels policy was not exported")
+}
+
+func TestExport_GatekeeperBlocklist(t *testing.T) {
+	res, err := Export(FormatGatekeeper, Input{Registry: &registry.Policy{ExcludedRegistries: []string{"docker.io"}}})
+	require.NoError(t, err)
+	require.Len(t, res.Resources, 2)
+	assert.Equal(t,
+		map[string]any{"excludedRegistries": []string{"docker.io"}},
+		res.Resources[1].Spec["parameters"])
+}
+
+func TestRenderYAML(t *testing.T) {
+	res, err := Export(FormatGatekeeper, Input{RequireDigest: true})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderYAML(&buf, res.Resources))
+
+	dec := yaml.NewDecoder(&buf)
+	var kinds []string
+	for {
+		var doc map[string]any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		kinds = append(kinds, doc["kind"].(string))
+	}
+	assert.Equal(t, []string{"ConstraintTemplate", "CheckImageRequireDigest"}, kinds)
+}
+
+func TestRenderJSON(t *testing.T) {
+	res, err := Export(FormatKyverno, Input{RequireDigest: true})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderJSON(&buf, res.Resources))
+
+	var list struct {
+		APIVersion string           `json:"apiVersion"`
+		Kind       string           `json:"kind"`
+		Items      []map[string]any `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+	assert.Equal(t, "v1", list.APIVersion)
+	assert.Equal(t, "List", list.Kind)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "ClusterPolicy", list.Items[0]["kind"])
+}
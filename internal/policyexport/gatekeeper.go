@@ -0,0 +1,176 @@
+package policyexport
+
+import (
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/registry"
+)
+
+const gatekeeperTarget = "admission.k8s.gatekeeper.sh"
+
+// gatekeeperInputContainers collects every container of the reviewed pod.
+const gatekeeperInputContainers = `
+input_containers[c] {
+  c := input.review.object.spec.containers[_]
+}
+
+input_containers[c] {
+  c := input.review.object.spec.initContainers[_]
+}
+
+input_containers[c] {
+  c := input.review.object.spec.ephemeralContainers[_]
+}
+`
+
+// gatekeeperRegistryRego mirrors the registry normalization of container
+// runtimes: a first path component is a registry host only when it contains
+// "." or ":" or is "localhost"; everything else lives on Docker Hub.
+const gatekeeperRegistryRego = `package checkimagetrustedregistries
+
+violation[{"msg": msg}] {
+  container := input_containers[_]
+  reg := image_registry(container.image)
+  not allowed(reg)
+  msg := sprintf("container <%v> uses image <%v> from registry <%v>, which is not allowed", [container.name, container.image, reg])
+}
+
+allowed(reg) {
+  trusted := object.get(input.parameters, "trustedRegistries", [])
+  count(trusted) > 0
+  reg == trusted[_]
+}
+
+allowed(reg) {
+  count(object.get(input.parameters, "trustedRegistries", [])) == 0
+  not excluded(reg)
+}
+
+excluded(reg) {
+  reg == object.get(input.parameters, "excludedRegistries", [])[_]
+}
+
+image_registry(image) = reg {
+  parts := split(image, "/")
+  count(parts) > 1
+  is_registry_host(parts[0])
+  reg := parts[0]
+}
+
+image_registry(image) = "docker.io" {
+  parts := split(image, "/")
+  count(parts) == 1
+}
+
+image_registry(image) = "docker.io" {
+  parts := split(image, "/")
+  count(parts) > 1
+  not is_registry_host(parts[0])
+}
+
+is_registry_host(h) {
+  contains(h, ".")
+}
+
+is_registry_host(h) {
+  contains(h, ":")
+}
+
+is_registry_host(h) {
+  h == "localhost"
+}
+` + gatekeeperInputContainers
+
+const gatekeeperDigestRego = `package checkimagerequiredigest
+
+violation[{"msg": msg}] {
+  container := input_containers[_]
+  not contains(container.image, "@sha256:")
+  msg := sprintf("container <%v> uses image <%v>, which is not pinned by digest", [container.name, container.image])
+}
+` + gatekeeperInputContainers
+
+func exportGatekeeper(in Input) *Result {
+	res := &Result{}
+
+	if in.Registry != nil {
+		res.Resources = append(res.Resources,
+			gatekeeperTemplate("CheckImageTrustedRegistries", gatekeeperRegistryRego, map[string]any{
+				"trustedRegistries":  stringArraySchema(),
+				"excludedRegistries": stringArraySchema(),
+			}),
+			gatekeeperConstraint("CheckImageTrustedRegistries", in.Name+"-registry", registryParameters(in.Registry)),
+		)
+	}
+	if in.RequireDigest {
+		res.Resources = append(res.Resources,
+			gatekeeperTemplate("CheckImageRequireDigest", gatekeeperDigestRego, nil),
+			gatekeeperConstraint("CheckImageRequireDigest", in.Name+"-require-digest", nil),
+		)
+	}
+	if in.Labels != nil {
+		res.Warnings = append(res.Warnings, "Image label requirements cannot be enforced by Gatekeeper without an external data provider; the labels policy was not exported")
+	}
+	return res
+}
+
+func stringArraySchema() map[string]any {
+	return map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "string"},
+	}
+}
+
+func registryParameters(p *registry.Policy) map[string]any {
+	if len(p.TrustedRegistries) > 0 {
+		return map[string]any{"trustedRegistries": normalizeRegistries(p.TrustedRegistries)}
+	}
+	return map[string]any{"excludedRegistries": normalizeRegistries(p.ExcludedRegistries)}
+}
+
+func gatekeeperTemplate(kind, rego string, properties map[string]any) Resource {
+	names := map[string]any{"kind": kind}
+	crdSpec := map[string]any{"names": names}
+	if properties != nil {
+		crdSpec["validation"] = map[string]any{
+			"openAPIV3Schema": map[string]any{
+				"type":       "object",
+				"properties": properties,
+			},
+		}
+	}
+
+	return Resource{
+		APIVersion: "templates.gatekeeper.sh/v1",
+		Kind:       "ConstraintTemplate",
+		Metadata: Metadata{
+			Name:        strings.ToLower(kind), // Gatekeeper requires the lowercase kind
+			Annotations: map[string]string{"description": "Generated by check-image policy export."},
+		},
+		Spec: map[string]any{
+			"crd": map[string]any{"spec": crdSpec},
+			"targets": []any{
+				map[string]any{"target": gatekeeperTarget, "rego": rego},
+			},
+		},
+	}
+}
+
+func gatekeeperConstraint(kind, name string, parameters map[string]any) Resource {
+	spec := map[string]any{
+		"match": map[string]any{
+			"kinds": []any{
+				map[string]any{"apiGroups": []string{""}, "kinds": []string{"Pod"}},
+			},
+		},
+	}
+	if parameters != nil {
+		spec["parameters"] = parameters
+	}
+	return Resource{
+		APIVersion: "constraints.gatekeeper.sh/v1beta1",
+		Kind:       kind,
+		Metadata:   Metadata{Name: name},
+		Spec:       spec,
+	}
+}
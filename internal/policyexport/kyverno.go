@@ -0,0 +1,192 @@
+package policyexport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/jarfernandez/check-image/internal/registry"
+)
+
+// podContainerLists are the pod spec fields holding containers. Kyverno's
+// built-in images context variable uses the same keys.
+var podContainerLists = []string{"containers", "initContainers", "ephemeralContainers"}
+
+func exportKyverno(in Input) *Result {
+	var rules []any
+	if in.Registry != nil {
+		rules = append(rules, kyvernoRegistryRule(in.Registry))
+	}
+	if in.RequireDigest {
+		rules = append(rules, kyvernoDigestRule())
+	}
+	if in.Labels != nil {
+		rules = append(rules, kyvernoLabelsRule(in.Labels))
+	}
+
+	policy := Resource{
+		APIVersion: "kyverno.io/v1",
+		Kind:       "ClusterPolicy",
+		Metadata: Metadata{
+			Name: in.Name,
+			Annotations: map[string]string{
+				"policies.kyverno.io/title":       "check-image admission policy",
+				"policies.kyverno.io/description": "Generated by check-image policy export from the registry, labels, and digest pinning policies.",
+			},
+		},
+		Spec: map[string]any{
+			"validationFailureAction": "Enforce",
+			"background":              true,
+			"rules":                   rules,
+		},
+	}
+
+	var warnings []string
+	if in.Labels != nil {
+		warnings = append(warnings, "The labels rule reads image configs from the registry at admission time; Kyverno needs pull access to every image it validates")
+	}
+	return &Result{Resources: []Resource{policy}, Warnings: warnings}
+}
+
+// kyvernoRule is a ClusterPolicy rule; a struct keeps the usual field order.
+type kyvernoRule struct {
+	Name     string         `json:"name"     yaml:"name"`
+	Match    map[string]any `json:"match"    yaml:"match"`
+	Validate map[string]any `json:"validate" yaml:"validate"`
+}
+
+func kyvernoMatchPods() map[string]any {
+	return map[string]any{
+		"any": []any{
+			map[string]any{"resources": map[string]any{"kinds": []string{"Pod"}}},
+		},
+	}
+}
+
+func kyvernoRegistryRule(p *registry.Policy) kyvernoRule {
+	operator, values, message := "AnyNotIn", normalizeRegistries(p.TrustedRegistries),
+		"Images must come from a trusted registry: "
+	if len(p.TrustedRegistries) == 0 {
+		operator, values, message = "AnyIn", normalizeRegistries(p.ExcludedRegistries),
+			"Images must not come from an excluded registry: "
+	}
+	message += strings.Join(values, ", ")
+
+	var foreach []any
+	for _, l := range podContainerLists {
+		foreach = append(foreach, map[string]any{
+			"list": "request.object.spec." + l,
+			"deny": map[string]any{
+				"conditions": map[string]any{
+					"any": []any{
+						map[string]any{
+							"key":      fmt.Sprintf(`{{ images.%s."{{element.name}}".registry }}`, l),
+							"operator": operator,
+							"value":    values,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	return kyvernoRule{
+		Name:  "registry",
+		Match: kyvernoMatchPods(),
+		Validate: map[string]any{
+			"message": message,
+			"foreach": foreach,
+		},
+	}
+}
+
+func kyvernoDigestRule() kyvernoRule {
+	digestPattern := []any{map[string]any{"image": "*@sha256:*"}}
+	return kyvernoRule{
+		Name:  "require-digest",
+		Match: kyvernoMatchPods(),
+		Validate: map[string]any{
+			"message": "Images must be pinned by digest (image@sha256:...)",
+			"pattern": map[string]any{
+				"spec": map[string]any{
+					"containers":             digestPattern,
+					"=(initContainers)":      digestPattern,
+					"=(ephemeralContainers)": digestPattern,
+				},
+			},
+		},
+	}
+}
+
+// jmesQuote escapes s for use inside a double-quoted JMESPath identifier.
+func jmesQuote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// jmesRaw escapes s for use inside a single-quoted JMESPath raw string literal.
+func jmesRaw(s string) string {
+	return strings.ReplaceAll(s, `'`, `\'`)
+}
+
+func kyvernoLabelConditions(p *labels.Policy) []any {
+	conditions := make([]any, 0, len(p.RequiredLabels))
+	for _, req := range p.RequiredLabels {
+		label := fmt.Sprintf(`imageData.configData.config.Labels."%s" || ''`, jmesQuote(req.Name))
+		var cond map[string]any
+		switch {
+		case req.Pattern != "":
+			cond = map[string]any{
+				"key":      fmt.Sprintf(`{{ regex_match('%s', %s) }}`, jmesRaw(req.Pattern), label),
+				"operator": "Equals",
+				"value":    false,
+			}
+		case req.Value != "":
+			cond = map[string]any{
+				"key":      fmt.Sprintf("{{ %s }}", label),
+				"operator": "NotEquals",
+				"value":    req.Value,
+			}
+		default:
+			cond = map[string]any{
+				"key":      fmt.Sprintf("{{ %s }}", label),
+				"operator": "Equals",
+				"value":    "",
+			}
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions
+}
+
+func kyvernoLabelsRule(p *labels.Policy) kyvernoRule {
+	names := make([]string, 0, len(p.RequiredLabels))
+	for _, req := range p.RequiredLabels {
+		names = append(names, req.Name)
+	}
+
+	conditions := kyvernoLabelConditions(p)
+	var foreach []any
+	for _, l := range podContainerLists {
+		foreach = append(foreach, map[string]any{
+			"list": "request.object.spec." + l,
+			"context": []any{
+				map[string]any{
+					"name":          "imageData",
+					"imageRegistry": map[string]any{"reference": "{{ element.image }}"},
+				},
+			},
+			"deny": map[string]any{
+				"conditions": map[string]any{"any": conditions},
+			},
+		})
+	}
+
+	return kyvernoRule{
+		Name:  "required-labels",
+		Match: kyvernoMatchPods(),
+		Validate: map[string]any{
+			"message": "Images must define the required labels: " + strings.Join(names, ", "),
+			"foreach": foreach,
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package policylint
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+)
+
+// Labels lints a labels policy. Unlike labels.Policy.Validate, which stops at
+// the first problem, every requirement is checked.
+func Labels(p *labels.Policy) []Finding {
+	var findings []Finding
+	if len(p.RequiredLabels) == 0 {
+		findings = append(findings, errorf("empty-policy", "required-labels", "policy requires no labels"))
+	}
+
+	seen := make(map[string]int, len(p.RequiredLabels))
+	for i, req := range p.RequiredLabels {
+		field := entryField("required-labels", i)
+		if req.Name == "" {
+			findings = append(findings, errorf("missing-name", field, "label requirement has no name"))
+		} else if first, ok := seen[req.Name]; ok {
+			findings = append(findings, errorf("duplicate-entry", field,
+				"label %q duplicates %s", req.Name, entryField("required-labels", first)))
+		} else {
+			seen[req.Name] = i
+		}
+
+		if req.Value != "" && req.Pattern != "" {
+			findings = append(findings, errorf("conflicting-requirements", field,
+				"label %q sets both value and pattern", req.Name))
+		}
+		if req.Value != "" && req.Value != strings.TrimSpace(req.Value) {
+			findings = append(findings, warnf("whitespace-value", field,
+				"value of label %q has leading or trailing whitespace, which must match exactly", req.Name))
+		}
+		if req.Pattern != "" {
+			findings = append(findings, lintLabelPattern(field, req.Name, req.Pattern)...)
+		}
+	}
+	return findings
+}
+
+func lintLabelPattern(field, name, pattern string) []Finding {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return []Finding{errorf("invalid-regex", field, "pattern of label %q does not compile: %v", name, err)}
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return []Finding{errorf("invalid-regex", field, "pattern of label %q does not compile: %v", name, err)}
+	}
+	re = re.Simplify()
+
+	if neverMatches(re) {
+		return []Finding{errorf("unreachable-pattern", field, "pattern %q of label %q can never match", pattern, name)}
+	}
+	if matchesEverything(re) {
+		return []Finding{warnf("broad-pattern", field,
+			"pattern %q of label %q matches any value; omit it for an existence check", pattern, name)}
+	}
+	if !strings.HasPrefix(pattern, "^") || !strings.HasSuffix(pattern, "$") {
+		return []Finding{warnf("unanchored-pattern", field,
+			"pattern %q of label %q is not anchored with ^...$, so any value containing a match passes", pattern, name)}
+	}
+	return nil
+}
+
+// neverMatches reports whether re can match no string: it contains an empty
+// character class, or it requires input after the end of the text or before
+// its beginning.
+func neverMatches(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return true
+	case syntax.OpCharClass:
+		return len(re.Rune) == 0
+	case syntax.OpConcat:
+		if slices.ContainsFunc(re.Sub, neverMatches) {
+			return true
+		}
+		for i, sub := range re.Sub {
+			if sub.Op == syntax.OpEndText && slices.ContainsFunc(re.Sub[i+1:], consumes) {
+				return true
+			}
+			if sub.Op == syntax.OpBeginText && slices.ContainsFunc(re.Sub[:i], consumes) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !neverMatches(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCapture, syntax.OpPlus:
+		return neverMatches(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min > 0 && neverMatches(re.Sub[0])
+	}
+	return false
+}
+
+// consumes reports whether every match of re consumes at least one character.
+func consumes(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) > 0
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpCapture, syntax.OpPlus:
+		return consumes(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min > 0 && consumes(re.Sub[0])
+	case syntax.OpConcat:
+		return slices.ContainsFunc(re.Sub, consumes)
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !consumes(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// matchesEverything reports whether re is a plain .* pattern,
+// optionally anchored, that accepts any label value.
+func matchesEverything(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return true
+	case syntax.OpStar:
+		return re.Sub[0].Op == syntax.OpAnyChar || re.Sub[0].Op == syntax.OpAnyCharNotNL
+	case syntax.OpCapture:
+		return matchesEverything(re.Sub[0])
+	case syntax.OpConcat:
+		var star bool
+		for _, sub := range re.Sub {
+			switch {
+			case sub.Op == syntax.OpBeginText || sub.Op == syntax.OpEndText ||
+				sub.Op == syntax.OpBeginLine || sub.Op == syntax.OpEndLine:
+			case matchesEverything(sub):
+				star = true
+			default:
+				return false
+			}
+		}
+		return star
+	}
+	return false
+}
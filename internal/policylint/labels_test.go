@@ -0,0 +1,96 @@
+package policylint
+
+import (
+	"regexp/syntax"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy labels.Policy
+		want   []string
+	}{
+		{
+			name: "Clean policy",
+			policy: labels.Policy{RequiredLabels: []labels.LabelRequirement{
+				{Name: "maintainer"},
+				{Name: "org.opencontainers.image.version", Pattern: `^v?\d+\.\d+\.\d+$`},
+				{Name: "org.opencontainers.image.vendor", Value: "MyCompany"},
+			}},
+			want: []string{},
+		},
+		{
+			name:   "Empty policy",
+			policy: labels.Policy{},
+			want:   []string{"error:empty-policy@required-labels"},
+		},
+		{
+			name: "Every problem is reported",
+			policy: labels.Policy{RequiredLabels: []labels.LabelRequirement{
+				{Name: ""},
+				{Name: "a", Value: "x", Pattern: "^x$"},
+				{Name: "a"},
+				{Name: "b", Value: " x"},
+				{Name: "c", Pattern: "("},
+				{Name: "d", Pattern: "^x$y"},
+				{Name: "e", Pattern: "^.*$"},
+				{Name: "f", Pattern: "release"},
+			}},
+			want: []string{
+				"error:missing-name@required-labels[0]",
+				"error:conflicting-requirements@required-labels[1]",
+				"error:duplicate-entry@required-labels[2]",
+				"warning:whitespace-value@required-labels[3]",
+				"error:invalid-regex@required-labels[4]",
+				"error:unreachable-pattern@required-labels[5]",
+				"warning:broad-pattern@required-labels[6]",
+				"warning:unanchored-pattern@required-labels[7]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(Labels(&tt.policy)))
+		})
+	}
+}
+
+func TestNeverMatches(t *testing.T) {
+	tests := map[string]bool{
+		`^x$`:                false,
+		`x$y`:                true,
+		`a^b`:                true,
+		`^(a|b$c)$`:          false,
+		`(a$b|c$d)`:          true,
+		`[^\x00-\x{10FFFF}]`: true,
+		`$(x)?`:              false,
+		`$x+`:                true,
+		`^$`:                 false,
+	}
+	for pattern, want := range tests {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		require.NoError(t, err, pattern)
+		assert.Equal(t, want, neverMatches(re.Simplify()), pattern)
+	}
+}
+
+func TestMatchesEverything(t *testing.T) {
+	tests := map[string]bool{
+		`.*`:     true,
+		`^.*$`:   true,
+		`^(.*)$`: true,
+		`.+`:     false,
+		`^a.*$`:  false,
+		`^$`:     false,
+	}
+	for pattern, want := range tests {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		require.NoError(t, err, pattern)
+		assert.Equal(t, want, matchesEverything(re.Simplify()), pattern)
+	}
+}
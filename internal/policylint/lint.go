@@ -0,0 +1,61 @@
+// Package policylint statically analyzes registry, secrets, and labels
+// policies for entries that can never take effect or that weaken the policy
+// more than intended.
+package policylint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity of a finding.
+type Severity string
+
+const (
+	// SeverityError marks a policy problem that makes an entry, or the whole
+	// policy, behave differently from what it says.
+	SeverityError Severity = "error"
+	// SeverityWarning marks an entry that is redundant or likely a mistake.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single lint result. Field names the policy key the finding is
+// about, with the index of the entry when it concerns a list item.
+type Finding struct {
+	Severity Severity
+	Rule     string
+	Field    string
+	Message  string
+}
+
+func errorf(rule, field, format string, args ...any) Finding {
+	return Finding{Severity: SeverityError, Rule: rule, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func warnf(rule, field, format string, args ...any) Finding {
+	return Finding{Severity: SeverityWarning, Rule: rule, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func entryField(key string, i int) string {
+	return fmt.Sprintf("%s[%d]", key, i)
+}
+
+// duplicates reports entries of key that repeat an earlier one. When fold is
+// true, entries are compared case-insensitively.
+func duplicates(key string, entries []string, fold bool) []Finding {
+	var findings []Finding
+	seen := make(map[string]int, len(entries))
+	for i, e := range entries {
+		k := e
+		if fold {
+			k = strings.ToLower(e)
+		}
+		if first, ok := seen[k]; ok {
+			findings = append(findings, warnf("duplicate-entry", entryField(key, i),
+				"%q duplicates %s", e, entryField(key, first)))
+			continue
+		}
+		seen[k] = i
+	}
+	return findings
+}
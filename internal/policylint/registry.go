@@ -0,0 +1,66 @@
+package policylint
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/registry"
+)
+
+// Registry lints a registry policy. Registries are compared exactly against
+// the registry host of the image reference, so entries with a scheme, a path,
+// or wildcards never match, and Docker Hub is only ever index.docker.io.
+func Registry(p *registry.Policy) []Finding {
+	hasTrusted := len(p.TrustedRegistries) > 0
+	hasExcluded := len(p.ExcludedRegistries) > 0
+
+	var findings []Finding
+	switch {
+	case hasTrusted && hasExcluded:
+		findings = append(findings, errorf("conflicting-modes", "",
+			"policy sets both trusted-registries and excluded-registries; only one mode is allowed"))
+		for i, e := range p.ExcludedRegistries {
+			if slices.Contains(p.TrustedRegistries, e) {
+				findings = append(findings, errorf("overlapping-entry", entryField("excluded-registries", i),
+					"%q is both trusted and excluded", e))
+			}
+		}
+	case !hasTrusted && !hasExcluded:
+		findings = append(findings, errorf("empty-policy", "",
+			"policy sets neither trusted-registries nor excluded-registries"))
+	}
+
+	for _, list := range []struct {
+		key     string
+		entries []string
+	}{
+		{"trusted-registries", p.TrustedRegistries},
+		{"excluded-registries", p.ExcludedRegistries},
+	} {
+		findings = append(findings, duplicates(list.key, list.entries, false)...)
+		for i, e := range list.entries {
+			if f, ok := lintRegistryEntry(entryField(list.key, i), e); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+func lintRegistryEntry(field, e string) (Finding, bool) {
+	switch {
+	case strings.TrimSpace(e) == "":
+		return errorf("unreachable-entry", field, "empty entry never matches"), true
+	case strings.Contains(e, "://"):
+		return errorf("unreachable-entry", field, "%q never matches: use the registry host without a scheme", e), true
+	case strings.ContainsAny(e, "*?["):
+		return errorf("unreachable-entry", field, "%q never matches: registries are compared exactly, wildcards are not supported", e), true
+	case strings.Contains(e, "/"):
+		return errorf("unreachable-entry", field, "%q never matches: use the registry host without a repository path", e), true
+	case e != strings.ToLower(e):
+		return errorf("unreachable-entry", field, "%q never matches: registry hosts are lowercase", e), true
+	case e == "docker.io" || e == "registry-1.docker.io":
+		return errorf("unreachable-entry", field, "%q never matches: Docker Hub images are reported as index.docker.io", e), true
+	}
+	return Finding{}, false
+}
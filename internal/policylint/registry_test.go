@@ -0,0 +1,73 @@
+package policylint
+
+import (
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func rules(findings []Finding) []string {
+	out := make([]string, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, string(f.Severity)+":"+f.Rule+"@"+f.Field)
+	}
+	return out
+}
+
+func TestRegistry(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy registry.Policy
+		want   []string
+	}{
+		{
+			name:   "Clean allowlist",
+			policy: registry.Policy{TrustedRegistries: []string{"index.docker.io", "ghcr.io", "localhost:5000"}},
+			want:   []string{},
+		},
+		{
+			name:   "Empty policy",
+			policy: registry.Policy{},
+			want:   []string{"error:empty-policy@"},
+		},
+		{
+			name: "Both modes with overlap",
+			policy: registry.Policy{
+				TrustedRegistries:  []string{"ghcr.io", "quay.io"},
+				ExcludedRegistries: []string{"quay.io"},
+			},
+			want: []string{"error:conflicting-modes@", "error:overlapping-entry@excluded-registries[0]"},
+		},
+		{
+			name:   "Duplicate entry",
+			policy: registry.Policy{ExcludedRegistries: []string{"ghcr.io", "ghcr.io"}},
+			want:   []string{"warning:duplicate-entry@excluded-registries[1]"},
+		},
+		{
+			name: "Unreachable entries",
+			policy: registry.Policy{TrustedRegistries: []string{
+				"https://ghcr.io", "*.gcr.io", "ghcr.io/org", "GHCR.io", "docker.io", " ",
+			}},
+			want: []string{
+				"error:unreachable-entry@trusted-registries[0]",
+				"error:unreachable-entry@trusted-registries[1]",
+				"error:unreachable-entry@trusted-registries[2]",
+				"error:unreachable-entry@trusted-registries[3]",
+				"error:unreachable-entry@trusted-registries[4]",
+				"error:unreachable-entry@trusted-registries[5]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(Registry(&tt.policy)))
+		})
+	}
+}
+
+func TestRegistry_DockerHubMessage(t *testing.T) {
+	findings := Registry(&registry.Policy{TrustedRegistries: []string{"docker.io"}})
+	assert.Len(t, findings, 1)
+	assert.Contains(t, findings[0].Message, "reported as index.docker.io")
+}
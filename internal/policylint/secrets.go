@@ -0,0 +1,130 @@
+package policylint
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/secrets"
+)
+
+// broadExcludes are excluded-paths entries that exclude every file.
+var broadExcludes = []string{"/**", "**", "*", "/*", "**/*", "/**/*"}
+
+// Secrets lints a secrets policy as written in the policy file, before the
+// defaults LoadSecretsPolicy fills in.
+func Secrets(p *secrets.Policy) []Finding {
+	var findings []Finding
+	if !p.CheckEnvVars && !p.CheckFiles {
+		findings = append(findings, errorf("checks-disabled", "",
+			"check-env-vars and check-files are both false or unset, so the secrets check never finds anything"))
+	}
+
+	findings = append(findings, duplicates("excluded-paths", p.ExcludedPaths, false)...)
+	for i, e := range p.ExcludedPaths {
+		findings = append(findings, lintExcludedPath(entryField("excluded-paths", i), e)...)
+	}
+
+	findings = append(findings, duplicates("excluded-env-vars", p.ExcludedEnvVars, false)...)
+	envPatterns := p.GetEnvPatterns()
+	for i, e := range p.ExcludedEnvVars {
+		if !matchesEnvPattern(e, envPatterns) {
+			findings = append(findings, warnf("unreachable-entry", entryField("excluded-env-vars", i),
+				"%q matches no sensitive pattern, so excluding it has no effect", e))
+		}
+	}
+
+	findings = append(findings, duplicates("custom-env-patterns", p.CustomEnvPatterns, true)...)
+	for i, pat := range p.CustomEnvPatterns {
+		field := entryField("custom-env-patterns", i)
+		switch {
+		case strings.TrimSpace(pat) == "":
+			findings = append(findings, errorf("broad-pattern", field,
+				"empty pattern matches every environment variable"))
+		case matchesEnvPattern(pat, secrets.DefaultEnvPatterns):
+			findings = append(findings, warnf("redundant-pattern", field,
+				"%q is already covered by a default pattern", pat))
+		}
+	}
+
+	findings = append(findings, duplicates("custom-file-patterns", p.CustomFilePatterns, false)...)
+	for i, pat := range p.CustomFilePatterns {
+		findings = append(findings, lintFilePattern(entryField("custom-file-patterns", i), pat, p.ExcludedPaths)...)
+	}
+
+	for i, h := range p.AllowedHashes {
+		if !isSHA256(h) {
+			findings = append(findings, errorf("invalid-hash", entryField("allowed-hashes", i),
+				"%q is not a sha256 digest (64 hex characters)", h))
+		}
+	}
+	return findings
+}
+
+func lintExcludedPath(field, e string) []Finding {
+	if strings.TrimSpace(e) == "" {
+		return []Finding{errorf("unreachable-entry", field, "empty entry never matches")}
+	}
+	if slices.Contains(broadExcludes, e) {
+		return []Finding{errorf("broad-exclude", field, "%q excludes every file from the secrets scan", e)}
+	}
+	if _, err := filepath.Match(e, ""); err != nil {
+		return []Finding{errorf("invalid-glob", field, "%q is not a valid glob and never matches", e)}
+	}
+	if dir, ok := strings.CutSuffix(e, "/**"); ok {
+		if dir == "" || strings.Count(dir, "/") == 1 && strings.HasPrefix(dir, "/") {
+			return []Finding{warnf("broad-exclude", field, "%q excludes a whole top-level directory", e)}
+		}
+		if strings.Contains(dir, "**") {
+			return []Finding{warnf("unsupported-glob", field,
+				"%q uses ** before the end; ** is only recursive as a trailing /**, elsewhere it matches a single path segment", e)}
+		}
+		return nil
+	}
+	if strings.Contains(e, "**") {
+		return []Finding{warnf("unsupported-glob", field,
+			"%q uses **, which is only recursive as a trailing /**; elsewhere it matches a single path segment", e)}
+	}
+	return nil
+}
+
+func lintFilePattern(field, pat string, excluded []string) []Finding {
+	if strings.TrimSpace(pat) == "" {
+		return []Finding{errorf("unreachable-entry", field, "empty pattern never matches")}
+	}
+	if _, err := filepath.Match(pat, ""); err != nil {
+		return []Finding{errorf("invalid-glob", field, "%q is not a valid glob and never matches", pat)}
+	}
+	if _, ok := secrets.DefaultFilePatterns[pat]; ok {
+		return []Finding{warnf("redundant-pattern", field, "%q is already a default pattern", pat)}
+	}
+	for _, e := range excluded {
+		if e == pat {
+			return []Finding{errorf("overlapping-entry", field,
+				"%q is also in excluded-paths, so it never reports anything", pat)}
+		}
+	}
+	return nil
+}
+
+// matchesEnvPattern mirrors the env var detection of the secrets package: a
+// case-insensitive substring match.
+func matchesEnvPattern(name string, patterns []string) bool {
+	lower := strings.ToLower(name)
+	for _, p := range patterns {
+		if p != "" && strings.Contains(lower, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSHA256(h string) bool {
+	h = strings.TrimPrefix(strings.TrimSpace(h), "sha256:")
+	if len(h) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(h)
+	return err == nil
+}
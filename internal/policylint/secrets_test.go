@@ -0,0 +1,88 @@
+package policylint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		name   string
+		policy secrets.Policy
+		want   []string
+	}{
+		{
+			name: "Clean policy",
+			policy: secrets.Policy{
+				CheckEnvVars:       true,
+				CheckFiles:         true,
+				ExcludedPaths:      []string{"/usr/share/doc/**", "*.md"},
+				ExcludedEnvVars:    []string{"PUBLIC_KEY"},
+				CustomEnvPatterns:  []string{"private"},
+				CustomFilePatterns: []string{"*.pem"},
+				AllowedHashes:      []string{"sha256:" + hash},
+			},
+			want: []string{},
+		},
+		{
+			name:   "Checks disabled",
+			policy: secrets.Policy{ExcludedPaths: []string{"/var/cache/apt/**"}},
+			want:   []string{"error:checks-disabled@"},
+		},
+		{
+			name: "Broad and invalid excludes",
+			policy: secrets.Policy{
+				CheckFiles:    true,
+				ExcludedPaths: []string{"/**", "*", "/usr/**", "[", "/opt/**/cache/**", "/a/**/b", ""},
+			},
+			want: []string{
+				"error:broad-exclude@excluded-paths[0]",
+				"error:broad-exclude@excluded-paths[1]",
+				"warning:broad-exclude@excluded-paths[2]",
+				"error:invalid-glob@excluded-paths[3]",
+				"warning:unsupported-glob@excluded-paths[4]",
+				"warning:unsupported-glob@excluded-paths[5]",
+				"error:unreachable-entry@excluded-paths[6]",
+			},
+		},
+		{
+			name: "Env var entries",
+			policy: secrets.Policy{
+				CheckEnvVars:      true,
+				ExcludedEnvVars:   []string{"PUBLIC_KEY", "PUBLIC_KEY", "HOME"},
+				CustomEnvPatterns: []string{"", "api_key", "Private", "private"},
+			},
+			want: []string{
+				"warning:duplicate-entry@excluded-env-vars[1]",
+				"warning:unreachable-entry@excluded-env-vars[2]",
+				"warning:duplicate-entry@custom-env-patterns[3]",
+				"error:broad-pattern@custom-env-patterns[0]",
+				"warning:redundant-pattern@custom-env-patterns[1]",
+			},
+		},
+		{
+			name: "File patterns and hashes",
+			policy: secrets.Policy{
+				CheckFiles:         true,
+				ExcludedPaths:      []string{"*.crt"},
+				CustomFilePatterns: []string{"id_rsa", "*.crt", "[", "*.p12"},
+				AllowedHashes:      []string{"not-a-hash"},
+			},
+			want: []string{
+				"warning:redundant-pattern@custom-file-patterns[0]",
+				"error:overlapping-entry@custom-file-patterns[1]",
+				"error:invalid-glob@custom-file-patterns[2]",
+				"error:invalid-hash@allowed-hashes[0]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(Secrets(&tt.policy)))
+		})
+	}
+}
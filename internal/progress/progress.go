@@ -0,0 +1,100 @@
+// Package progress reports the progress of runs that validate many images: a
+// progress line with the pass and fail counts and the estimated time
+// remaining, and a summary table of every image once the run ends.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Outcome of an image that passed every check; any other outcome counts as
+// failed.
+const outcomePassed = "passed"
+
+// Result is the outcome of one validated image.
+type Result struct {
+	Image string
+	// Outcome is passed, failed, or errored.
+	Outcome      string
+	FailedChecks []string
+}
+
+// Tracker prints the progress of a run over a known number of images. A live
+// tracker rewrites a single line, for terminals; otherwise every update is
+// printed on its own line, for logs.
+type Tracker struct {
+	w       io.Writer
+	total   int
+	live    bool
+	now     func() time.Time
+	start   time.Time
+	results []Result
+	passed  int
+}
+
+// New returns a tracker of a run over total images that writes to w.
+func New(w io.Writer, total int, live bool) *Tracker {
+	return newTracker(w, total, live, time.Now)
+}
+
+func newTracker(w io.Writer, total int, live bool, now func() time.Time) *Tracker {
+	return &Tracker{w: w, total: total, live: live, now: now, start: now()}
+}
+
+// Record adds the result of an image and prints the updated progress line.
+func (t *Tracker) Record(r Result) {
+	t.results = append(t.results, r)
+	if r.Outcome == outcomePassed {
+		t.passed++
+	}
+
+	line := fmt.Sprintf("Progress: %d/%d images, %d passed, %d failed", len(t.results), t.total, t.passed, len(t.results)-t.passed)
+	if eta, ok := t.ETA(); ok {
+		line += ", ETA " + eta.String()
+	}
+	if t.live {
+		// Clear the previous line, and end the last one.
+		_, _ = fmt.Fprint(t.w, "\r\033[K"+line)
+		if len(t.results) == t.total {
+			_, _ = fmt.Fprintln(t.w)
+		}
+		return
+	}
+	_, _ = fmt.Fprintln(t.w, line)
+}
+
+// ETA estimates the time until the run ends from the average time per image
+// so far, rounded to seconds. It reports false before the first image and
+// after the last.
+func (t *Tracker) ETA() (time.Duration, bool) {
+	done := len(t.results)
+	if done == 0 || done >= t.total {
+		return 0, false
+	}
+	perImage := t.now().Sub(t.start) / time.Duration(done)
+	return (perImage * time.Duration(t.total-done)).Round(time.Second), true
+}
+
+// Summary prints a table with the outcome and failed checks of every
+// recorded image, followed by the totals and the elapsed time. A live line
+// cut short by an interrupted run is ended first.
+func (t *Tracker) Summary() {
+	if t.live && len(t.results) > 0 && len(t.results) < t.total {
+		_, _ = fmt.Fprintln(t.w)
+	}
+
+	tw := tabwriter.NewWriter(t.w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "IMAGE\tRESULT\tFAILED CHECKS")
+	for _, r := range t.results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Image, r.Outcome, strings.Join(r.FailedChecks, ","))
+	}
+	_ = tw.Flush()
+
+	elapsed := t.now().Sub(t.start).Round(time.Second)
+	_, _ = fmt.Fprintf(t.w, "%d of %d images validated: %d passed, %d failed in %s\n",
+		len(t.results), t.total, t.passed, len(t.results)-t.passed, elapsed)
+}
@@ -0,0 +1,85 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock returns a clock that advances by step on every call after the
+// first.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := true
+	return func() time.Time {
+		if !first {
+			now = now.Add(step)
+		}
+		first = false
+		return now
+	}
+}
+
+func TestTracker_Lines(t *testing.T) {
+	var buf bytes.Buffer
+	tr := newTracker(&buf, 3, false, fakeClock(10*time.Second))
+
+	tr.Record(Result{Image: "app:1", Outcome: "passed"})
+	tr.Record(Result{Image: "app:2", Outcome: "failed", FailedChecks: []string{"user", "secrets"}})
+	tr.Record(Result{Image: "app:3", Outcome: "errored", FailedChecks: []string{"size"}})
+
+	assert.Equal(t, "Progress: 1/3 images, 1 passed, 0 failed, ETA 20s\n"+
+		"Progress: 2/3 images, 1 passed, 1 failed, ETA 10s\n"+
+		"Progress: 3/3 images, 1 passed, 2 failed\n", buf.String())
+}
+
+func TestTracker_Live(t *testing.T) {
+	var buf bytes.Buffer
+	tr := newTracker(&buf, 2, true, fakeClock(time.Second))
+
+	tr.Record(Result{Image: "app:1", Outcome: "passed"})
+	tr.Record(Result{Image: "app:2", Outcome: "passed"})
+
+	assert.Equal(t, "\r\033[KProgress: 1/2 images, 1 passed, 0 failed, ETA 1s"+
+		"\r\033[KProgress: 2/2 images, 2 passed, 0 failed\n", buf.String())
+}
+
+func TestTracker_ETA(t *testing.T) {
+	tr := newTracker(&bytes.Buffer{}, 4, false, fakeClock(3*time.Second))
+	_, ok := tr.ETA()
+	assert.False(t, ok, "no estimate before the first image")
+
+	tr.Record(Result{Image: "app:1", Outcome: "passed"})
+	eta, ok := tr.ETA()
+	assert.True(t, ok)
+	assert.Equal(t, 18*time.Second, eta, "two clock steps per image so far, three images left")
+}
+
+func TestTracker_Summary(t *testing.T) {
+	var buf bytes.Buffer
+	tr := newTracker(&buf, 3, false, fakeClock(time.Second))
+	tr.Record(Result{Image: "registry.example.com/app:1", Outcome: "passed"})
+	tr.Record(Result{Image: "app:2", Outcome: "failed", FailedChecks: []string{"user", "secrets"}})
+	buf.Reset()
+
+	tr.Summary()
+
+	assert.Equal(t, "IMAGE                       RESULT  FAILED CHECKS\n"+
+		"registry.example.com/app:1  passed  \n"+
+		"app:2                       failed  user,secrets\n"+
+		"2 of 3 images validated: 1 passed, 1 failed in 3s\n", buf.String())
+}
+
+func TestTracker_SummaryEndsInterruptedLiveLine(t *testing.T) {
+	var buf bytes.Buffer
+	tr := newTracker(&buf, 3, true, fakeClock(time.Second))
+	tr.Record(Result{Image: "app:1", Outcome: "passed"})
+	buf.Reset()
+
+	tr.Summary()
+
+	assert.True(t, strings.HasPrefix(buf.String(), "\nIMAGE  RESULT  FAILED CHECKS\n"))
+}
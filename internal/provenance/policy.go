@@ -0,0 +1,110 @@
+package provenance
+
+import (
+	"crypto"
+	"fmt"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+	"github.com/jarfernandez/check-image/internal/signing"
+)
+
+// Policy defines the SLSA provenance an image must carry. Statements must be
+// signed by one of TrustedKeys unless AllowUnsigned is set, so that the
+// builder, source and build type they claim can be trusted. The other lists
+// are optional; an empty list accepts any value. Entries match exactly, or by
+// prefix when they end with "*".
+type Policy struct {
+	// TrustedBuilders lists the accepted builder IDs.
+	TrustedBuilders []string `json:"trusted-builders,omitempty" yaml:"trusted-builders,omitempty"`
+	// SourceRepositories lists the accepted source repository URIs, without
+	// the git+ prefix, the @ref suffix, or the .git extension.
+	SourceRepositories []string `json:"source-repositories,omitempty" yaml:"source-repositories,omitempty"`
+	// BuildTypes lists the accepted build type URIs.
+	BuildTypes []string `json:"build-types,omitempty" yaml:"build-types,omitempty"`
+	// TrustedKeys lists PEM public key files. Statements must be DSSE
+	// envelopes signed by one of them, as "cosign attest --key" writes.
+	TrustedKeys []string `json:"trusted-keys,omitempty" yaml:"trusted-keys,omitempty"`
+	// AllowUnsigned accepts statements that are not signed by a trusted key,
+	// such as the unsigned provenance BuildKit stores in the image index.
+	AllowUnsigned bool `json:"allow-unsigned,omitempty" yaml:"allow-unsigned,omitempty"`
+
+	// keys holds the keys of TrustedKeys, loaded by LoadPolicy.
+	keys []crypto.PublicKey
+}
+
+// LoadPolicy loads a provenance policy from a file or stdin (if path is "-"),
+// which can be in either YAML or JSON format, and returns the parsed Policy object.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := fileutil.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading provenance policy: %w", err)
+	}
+
+	var policy Policy
+	if err := fileutil.UnmarshalConfigData(data, &policy, path); err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, path := range policy.TrustedKeys {
+		key, err := signing.LoadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load trusted key %s: %w", path, err)
+		}
+		policy.keys = append(policy.keys, key)
+	}
+
+	return &policy, nil
+}
+
+// Trusts reports whether st is signed by one of the trusted keys of p.
+func (p *Policy) Trusts(st *Provenance) bool {
+	return st.signed.verifiedBy(p.keys)
+}
+
+// Validate checks that no entry is empty and that "*" only appears at the end
+// of an entry.
+func (p *Policy) Validate() error {
+	lists := []struct {
+		key     string
+		entries []string
+	}{
+		{"trusted-builders", p.TrustedBuilders},
+		{"source-repositories", p.SourceRepositories},
+		{"build-types", p.BuildTypes},
+		{"trusted-keys", p.TrustedKeys},
+	}
+	for _, l := range lists {
+		for _, e := range l.entries {
+			if strings.TrimSpace(e) == "" {
+				return fmt.Errorf("%s cannot contain empty entries", l.key)
+			}
+			if strings.Contains(strings.TrimSuffix(e, "*"), "*") {
+				return fmt.Errorf("%s entry %q can only use * as a trailing wildcard", l.key, e)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether value matches one of patterns. An empty pattern
+// list matches everything.
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(value, prefix) {
+				return true
+			}
+		} else if p == value {
+			return true
+		}
+	}
+	return false
+}
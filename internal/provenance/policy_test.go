@@ -0,0 +1,93 @@
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPolicy_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	content := `trusted-builders:
+  - https://github.com/slsa-framework/slsa-github-generator/*
+source-repositories:
+  - https://github.com/org/app
+build-types:
+  - https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/slsa-framework/slsa-github-generator/*"}, policy.TrustedBuilders)
+	assert.Equal(t, []string{"https://github.com/org/app"}, policy.SourceRepositories)
+	assert.Equal(t, []string{"https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"}, policy.BuildTypes)
+}
+
+func TestLoadPolicy_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	content := `{"trusted-builders": ["https://github.com/docker/buildx*"]}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/docker/buildx*"}, policy.TrustedBuilders)
+	assert.Empty(t, policy.SourceRepositories)
+	assert.Empty(t, policy.BuildTypes)
+}
+
+func TestLoadPolicy_Errors(t *testing.T) {
+	_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading provenance policy")
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("trusted-builders:\n  - \"\"\n"), 0600))
+	_, err = LoadPolicy(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "trusted-builders cannot contain empty entries")
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{name: "Empty policy", policy: Policy{}},
+		{name: "Trailing wildcard", policy: Policy{TrustedBuilders: []string{"https://github.com/*"}}},
+		{
+			name:    "Inner wildcard",
+			policy:  Policy{SourceRepositories: []string{"https://github.com/*/app"}},
+			wantErr: `source-repositories entry "https://github.com/*/app" can only use * as a trailing wildcard`,
+		},
+		{
+			name:    "Blank entry",
+			policy:  Policy{BuildTypes: []string{"  "}},
+			wantErr: "build-types cannot contain empty entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	assert.True(t, matchesAny(nil, "anything"))
+	assert.True(t, matchesAny([]string{"https://github.com/org/app"}, "https://github.com/org/app"))
+	assert.False(t, matchesAny([]string{"https://github.com/org/app"}, "https://github.com/org/app2"))
+	assert.True(t, matchesAny([]string{"https://github.com/org/*"}, "https://github.com/org/app2"))
+	assert.False(t, matchesAny([]string{"https://github.com/org/*"}, "https://github.com/other/app"))
+	assert.True(t, matchesAny([]string{"*"}, ""))
+}
@@ -0,0 +1,73 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// signedEnvelope is the signed content of a DSSE envelope.
+type signedEnvelope struct {
+	payloadType string
+	payload     []byte
+	signatures  [][]byte
+}
+
+// newSignedEnvelope returns the signed content of an envelope, or nil when
+// it has no readable signature.
+func newSignedEnvelope(payloadType string, payload []byte, sigs []dsseSignature) *signedEnvelope {
+	e := &signedEnvelope{payloadType: payloadType, payload: payload}
+	for _, s := range sigs {
+		if sig, err := base64.StdEncoding.DecodeString(s.Sig); err == nil && len(sig) > 0 {
+			e.signatures = append(e.signatures, sig)
+		}
+	}
+	if len(e.signatures) == 0 {
+		return nil
+	}
+	return e
+}
+
+// verifiedBy reports whether one of the signatures of e was made by one of
+// keys.
+func (e *signedEnvelope) verifiedBy(keys []crypto.PublicKey) bool {
+	if e == nil {
+		return false
+	}
+	msg := pae(e.payloadType, e.payload)
+	for _, key := range keys {
+		for _, sig := range e.signatures {
+			if verifySignature(key, msg, sig) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, the message
+// that DSSE signatures sign.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// verifySignature verifies sig over msg with the SHA-256 digests that cosign
+// signs with: ASN.1 ECDSA, RSA PKCS #1 v1.5 or PSS, and Ed25519 over msg
+// itself.
+func verifySignature(key crypto.PublicKey, msg, sig []byte) bool {
+	digest := sha256.Sum256(msg)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil ||
+			rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signEnvelope returns statement in a DSSE envelope signed by key, as cosign
+// attest --key writes it.
+func signEnvelope(t *testing.T, key crypto.Signer, statement string) []byte {
+	t.Helper()
+	msg := pae(InTotoPayloadType, []byte(statement))
+	var sig []byte
+	var err error
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		sig, err = key.Sign(rand.Reader, msg, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(msg)
+		sig, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	require.NoError(t, err)
+	env, err := json.Marshal(map[string]any{
+		"payloadType": InTotoPayloadType,
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statement)),
+		"signatures":  []map[string]string{{"keyid": "", "sig": base64.StdEncoding.EncodeToString(sig)}},
+	})
+	require.NoError(t, err)
+	return env
+}
+
+func TestPAE(t *testing.T) {
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world", string(pae("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func TestPolicyTrusts(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{"ecdsa": ecKey, "rsa": rsaKey, "ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse(signEnvelope(t, key, statementV1))
+			require.NoError(t, err)
+			assert.True(t, p.Signed)
+
+			assert.True(t, (&Policy{keys: []crypto.PublicKey{other.Public(), key.Public()}}).Trusts(p))
+			assert.False(t, (&Policy{keys: []crypto.PublicKey{other.Public()}}).Trusts(p), "signed by another key")
+			assert.False(t, (&Policy{}).Trusts(p), "no trusted keys")
+		})
+	}
+
+	t.Run("tampered payload", func(t *testing.T) {
+		var env map[string]any
+		require.NoError(t, json.Unmarshal(signEnvelope(t, ecKey, statementV1), &env))
+		env["payload"] = base64.StdEncoding.EncodeToString([]byte(statementV02))
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+
+		p, err := Parse(data)
+		require.NoError(t, err)
+		assert.True(t, p.Signed)
+		assert.False(t, (&Policy{keys: []crypto.PublicKey{ecKey.Public()}}).Trusts(p))
+	})
+
+	t.Run("unsigned statement", func(t *testing.T) {
+		p, err := Parse([]byte(statementV1))
+		require.NoError(t, err)
+		assert.False(t, p.Signed)
+		assert.False(t, (&Policy{keys: []crypto.PublicKey{ecKey.Public()}}).Trusts(p))
+	})
+}
+
+func TestValidate_Signatures(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signed, err := Parse(signEnvelope(t, key, statementV1))
+	require.NoError(t, err)
+	digests := []string{"sha256:abc"}
+
+	_, result := Validate([]*Provenance{signed}, digests, &Policy{keys: []crypto.PublicKey{key.Public()}})
+	assert.True(t, result.Passed)
+
+	_, result = Validate([]*Provenance{signed}, digests, &Policy{keys: []crypto.PublicKey{other.Public()}})
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "provenance statement is not signed by a trusted key", result.Violations[0].Message)
+
+	_, result = Validate([]*Provenance{signed}, digests, &Policy{})
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "provenance signature cannot be verified: the policy lists no trusted-keys", result.Violations[0].Message)
+}
+
+func TestLoadPolicy_TrustedKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	policyPath := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("trusted-keys:\n  - "+keyPath+"\n"), 0600))
+	policy, err := LoadPolicy(policyPath)
+	require.NoError(t, err)
+	p, err := Parse(signEnvelope(t, key, statementV1))
+	require.NoError(t, err)
+	assert.True(t, policy.Trusts(p))
+
+	require.NoError(t, os.WriteFile(policyPath, []byte("trusted-keys:\n  - "+filepath.Join(dir, "missing.pub")+"\n"), 0600))
+	_, err = LoadPolicy(policyPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load trusted key")
+}
@@ -0,0 +1,215 @@
+// Package provenance reads SLSA provenance from in-toto attestations and
+// validates it against a policy of trusted builders, source repositories,
+// and build types.
+package provenance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SLSA provenance predicate types.
+const (
+	PredicateSLSAv02 = "https://slsa.dev/provenance/v0.2"
+	PredicateSLSAv1  = "https://slsa.dev/provenance/v1"
+)
+
+// InTotoPayloadType is the DSSE payload type of in-toto statements.
+const InTotoPayloadType = "application/vnd.in-toto+json"
+
+// ErrNotProvenance is returned by Parse for valid in-toto statements whose
+// predicate is not SLSA provenance, such as SBOMs.
+var ErrNotProvenance = errors.New("statement is not SLSA provenance")
+
+// Provenance holds the fields of a SLSA provenance predicate that policies
+// are checked against.
+type Provenance struct {
+	PredicateType    string
+	BuilderID        string
+	BuildType        string
+	SourceRepository string
+	// Subjects lists the digests (sha256:...) the statement is about.
+	Subjects []string
+	// Signed reports whether the statement came in a DSSE envelope with
+	// signatures. Whether they are valid is checked by Policy.Trusts.
+	Signed bool
+
+	// signed holds the envelope of a signed statement.
+	signed *signedEnvelope
+}
+
+type envelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+	// DSSEEnvelope is set in Sigstore bundles, which wrap the envelope.
+	DSSEEnvelope *envelope `json:"dsseEnvelope"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type statement struct {
+	Subject []struct {
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type predicateV02 struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource struct {
+			URI string `json:"uri"`
+		} `json:"configSource"`
+	} `json:"invocation"`
+	Materials []struct {
+		URI string `json:"uri"`
+	} `json:"materials"`
+}
+
+type predicateV1 struct {
+	BuildDefinition struct {
+		BuildType          string         `json:"buildType"`
+		ExternalParameters map[string]any `json:"externalParameters"`
+		// ResolvedDependencies lists the artifacts the build consumed.
+		ResolvedDependencies []struct {
+			URI string `json:"uri"`
+		} `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+// Parse reads SLSA provenance from an in-toto statement, either bare or
+// wrapped in a DSSE envelope or a Sigstore bundle. The signatures of the
+// envelope are kept for Policy.Trusts to verify.
+func Parse(data []byte) (*Provenance, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid attestation: %w", err)
+	}
+	if env.DSSEEnvelope != nil {
+		env = *env.DSSEEnvelope
+	}
+	if env.Payload != "" {
+		if env.PayloadType != InTotoPayloadType {
+			return nil, fmt.Errorf("unsupported DSSE payload type %q", env.PayloadType)
+		}
+		payload, err := base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DSSE payload: %w", err)
+		}
+		data = payload
+	}
+	signed := newSignedEnvelope(env.PayloadType, data, env.Signatures)
+
+	var st statement
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("invalid in-toto statement: %w", err)
+	}
+
+	p := &Provenance{PredicateType: st.PredicateType, Signed: signed != nil, signed: signed}
+	for _, s := range st.Subject {
+		if d, ok := s.Digest["sha256"]; ok {
+			p.Subjects = append(p.Subjects, "sha256:"+d)
+		}
+	}
+
+	switch st.PredicateType {
+	case PredicateSLSAv02:
+		var pred predicateV02
+		if err := json.Unmarshal(st.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("invalid SLSA v0.2 predicate: %w", err)
+		}
+		p.BuilderID = pred.Builder.ID
+		p.BuildType = pred.BuildType
+		p.SourceRepository = pred.Invocation.ConfigSource.URI
+		for _, m := range pred.Materials {
+			if p.SourceRepository != "" {
+				break
+			}
+			if strings.HasPrefix(m.URI, "git+") {
+				p.SourceRepository = m.URI
+			}
+		}
+	case PredicateSLSAv1:
+		var pred predicateV1
+		if err := json.Unmarshal(st.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("invalid SLSA v1 predicate: %w", err)
+		}
+		p.BuilderID = pred.RunDetails.Builder.ID
+		p.BuildType = pred.BuildDefinition.BuildType
+		p.SourceRepository = sourceFromParameters(pred.BuildDefinition.ExternalParameters)
+		for _, d := range pred.BuildDefinition.ResolvedDependencies {
+			if p.SourceRepository != "" {
+				break
+			}
+			if strings.HasPrefix(d.URI, "git+") {
+				p.SourceRepository = d.URI
+			}
+		}
+	default:
+		return nil, ErrNotProvenance
+	}
+
+	p.SourceRepository = NormalizeSource(p.SourceRepository)
+	return p, nil
+}
+
+// sourceFromParameters finds the source repository in SLSA v1 external
+// parameters: workflow.repository (GitHub Actions), configSource.uri
+// (BuildKit), or a top-level source string.
+func sourceFromParameters(params map[string]any) string {
+	for _, path := range [][]string{{"workflow", "repository"}, {"configSource", "uri"}, {"source"}} {
+		var v any = params
+		for _, key := range path {
+			m, ok := v.(map[string]any)
+			if !ok {
+				v = nil
+				break
+			}
+			v = m[key]
+		}
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// NormalizeSource strips the git+ prefix, the @ref suffix or #fragment, the
+// .git extension, and a trailing slash from a source repository URI, so
+// "git+https://github.com/org/app.git@refs/heads/main" becomes
+// "https://github.com/org/app".
+func NormalizeSource(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	if i := strings.Index(uri, "#"); i >= 0 {
+		uri = uri[:i]
+	}
+	rest := uri
+	offset := 0
+	if i := strings.Index(uri, "://"); i >= 0 {
+		offset = i + len("://")
+		rest = uri[offset:]
+	}
+	if slash := strings.Index(rest, "/"); slash >= 0 {
+		if at := strings.Index(rest[slash:], "@"); at >= 0 {
+			uri = uri[:offset+slash+at]
+		}
+	}
+	uri = strings.TrimSuffix(uri, "/")
+	return strings.TrimSuffix(uri, ".git")
+}
@@ -0,0 +1,114 @@
+package provenance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const statementV1 = `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [{"name": "ghcr.io/org/app", "digest": {"sha256": "abc"}}],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+      "externalParameters": {
+        "workflow": {"ref": "refs/heads/main", "repository": "https://github.com/org/app", "path": ".github/workflows/release.yml"}
+      }
+    },
+    "runDetails": {
+      "builder": {"id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"}
+    }
+  }
+}`
+
+const statementV02 = `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "subject": [{"name": "app", "digest": {"sha256": "def"}}],
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "predicate": {
+    "builder": {"id": "https://github.com/docker/buildx@v0.12.0"},
+    "buildType": "https://mobyproject.org/buildkit@v1",
+    "materials": [
+      {"uri": "pkg:docker/alpine@3.19"},
+      {"uri": "git+https://github.com/org/app.git@refs/heads/main"}
+    ]
+  }
+}`
+
+func TestParse_SLSAv1(t *testing.T) {
+	p, err := Parse([]byte(statementV1))
+	require.NoError(t, err)
+	assert.Equal(t, PredicateSLSAv1, p.PredicateType)
+	assert.Equal(t, "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0", p.BuilderID)
+	assert.Equal(t, "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1", p.BuildType)
+	assert.Equal(t, "https://github.com/org/app", p.SourceRepository)
+	assert.Equal(t, []string{"sha256:abc"}, p.Subjects)
+}
+
+func TestParse_SLSAv02(t *testing.T) {
+	p, err := Parse([]byte(statementV02))
+	require.NoError(t, err)
+	assert.Equal(t, PredicateSLSAv02, p.PredicateType)
+	assert.Equal(t, "https://github.com/docker/buildx@v0.12.0", p.BuilderID)
+	assert.Equal(t, "https://mobyproject.org/buildkit@v1", p.BuildType)
+	assert.Equal(t, "https://github.com/org/app", p.SourceRepository)
+	assert.Equal(t, []string{"sha256:def"}, p.Subjects)
+}
+
+func TestParse_DSSEEnvelope(t *testing.T) {
+	env, err := json.Marshal(map[string]string{
+		"payloadType": InTotoPayloadType,
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statementV1)),
+	})
+	require.NoError(t, err)
+
+	p, err := Parse(env)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/org/app", p.SourceRepository)
+
+	bundle, err := json.Marshal(map[string]any{
+		"mediaType":    "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"dsseEnvelope": json.RawMessage(env),
+	})
+	require.NoError(t, err)
+	p, err = Parse(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, PredicateSLSAv1, p.PredicateType)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`{"predicateType": "https://spdx.dev/Document", "predicate": {}}`))
+	assert.ErrorIs(t, err, ErrNotProvenance)
+
+	_, err = Parse([]byte("not json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid attestation")
+
+	_, err = Parse([]byte(`{"payloadType": "text/plain", "payload": "aGVsbG8="}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported DSSE payload type "text/plain"`)
+
+	_, err = Parse([]byte(`{"payloadType": "application/vnd.in-toto+json", "payload": "!!!"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid DSSE payload")
+}
+
+func TestNormalizeSource(t *testing.T) {
+	tests := map[string]string{
+		"":                                    "",
+		"https://github.com/org/app":          "https://github.com/org/app",
+		"git+https://github.com/org/app.git":  "https://github.com/org/app",
+		"git+https://github.com/org/app@main": "https://github.com/org/app",
+		"https://github.com/org/app.git#main": "https://github.com/org/app",
+		"https://github.com/org/app/":         "https://github.com/org/app",
+		"git+ssh://git@github.com/org/app.git@refs/heads/main": "ssh://git@github.com/org/app",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, NormalizeSource(in), in)
+	}
+}
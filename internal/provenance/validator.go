@@ -0,0 +1,102 @@
+package provenance
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Violation represents a single provenance policy failure.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Result holds the outcome of provenance validation.
+type Result struct {
+	Passed     bool
+	Violations []Violation
+}
+
+// Validate checks the provenance statements found for an image against
+// policy. digests lists the digests of the image (its index and manifests);
+// statements whose subjects name none of them are ignored, so provenance
+// attached to an image but describing another does not count. At least one
+// statement must apply, and every one that does must satisfy the policy,
+// including being signed by a trusted key unless the policy allows unsigned
+// statements. A nil policy has no trusted keys, so every statement fails the
+// signature rule.
+func Validate(statements []*Provenance, digests []string, policy *Policy) ([]*Provenance, Result) {
+	var applicable []*Provenance
+	for _, p := range statements {
+		if coversAny(p.Subjects, digests) {
+			applicable = append(applicable, p)
+		}
+	}
+
+	if len(applicable) == 0 {
+		return nil, Result{Violations: []Violation{{
+			Rule:    "missing",
+			Message: "no SLSA provenance attestation found for the image",
+		}}}
+	}
+	if policy == nil {
+		policy = &Policy{}
+	}
+
+	var violations []Violation
+	for _, p := range applicable {
+		if !policy.AllowUnsigned && !policy.Trusts(p) {
+			violations = append(violations, Violation{
+				Rule:    "signature",
+				Message: signatureViolation(p, policy),
+			})
+		}
+		if !matchesAny(policy.TrustedBuilders, p.BuilderID) {
+			violations = append(violations, Violation{
+				Rule:    "builder",
+				Message: fmt.Sprintf("builder %q is not trusted", p.BuilderID),
+			})
+		}
+		if !matchesAny(policy.SourceRepositories, p.SourceRepository) {
+			violations = append(violations, Violation{
+				Rule:    "source-repository",
+				Message: fmt.Sprintf("source repository %q is not allowed", p.SourceRepository),
+			})
+		}
+		if !matchesAny(policy.BuildTypes, p.BuildType) {
+			violations = append(violations, Violation{
+				Rule:    "build-type",
+				Message: fmt.Sprintf("build type %q is not allowed", p.BuildType),
+			})
+		}
+	}
+
+	return applicable, Result{Passed: len(violations) == 0, Violations: violations}
+}
+
+// signatureViolation explains why the signature of p is not trusted.
+func signatureViolation(p *Provenance, policy *Policy) string {
+	switch {
+	case !p.Signed:
+		return "provenance statement is not signed"
+	case len(policy.keys) == 0:
+		return "provenance signature cannot be verified: the policy lists no trusted-keys"
+	default:
+		return "provenance statement is not signed by a trusted key"
+	}
+}
+
+// coversAny reports whether subjects names one of digests. Statements without
+// subjects are accepted, since where they were found already ties them to the
+// image.
+func coversAny(subjects, digests []string) bool {
+	if len(subjects) == 0 {
+		return true
+	}
+	for _, s := range subjects {
+		if slices.Contains(digests, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package provenance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	trusted := &Provenance{
+		PredicateType:    PredicateSLSAv1,
+		BuilderID:        "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0",
+		BuildType:        "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+		SourceRepository: "https://github.com/org/app",
+		Subjects:         []string{"sha256:abc"},
+	}
+	policy := &Policy{
+		TrustedBuilders:    []string{"https://github.com/slsa-framework/slsa-github-generator/*"},
+		SourceRepositories: []string{"https://github.com/org/*"},
+		AllowUnsigned:      true,
+	}
+
+	t.Run("Trusted provenance passes", func(t *testing.T) {
+		applicable, result := Validate([]*Provenance{trusted}, []string{"sha256:abc"}, policy)
+		assert.True(t, result.Passed)
+		assert.Empty(t, result.Violations)
+		assert.Equal(t, []*Provenance{trusted}, applicable)
+	})
+
+	t.Run("Nil policy rejects unsigned provenance", func(t *testing.T) {
+		_, result := Validate([]*Provenance{trusted}, []string{"sha256:abc"}, nil)
+		assert.False(t, result.Passed)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, "signature", result.Violations[0].Rule)
+		assert.Equal(t, "provenance statement is not signed", result.Violations[0].Message)
+	})
+
+	t.Run("Unsigned provenance fails without allow-unsigned", func(t *testing.T) {
+		strict := *policy
+		strict.AllowUnsigned = false
+		_, result := Validate([]*Provenance{trusted}, []string{"sha256:abc"}, &strict)
+		assert.False(t, result.Passed)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, "signature", result.Violations[0].Rule)
+	})
+
+	t.Run("Missing provenance fails", func(t *testing.T) {
+		applicable, result := Validate(nil, []string{"sha256:abc"}, policy)
+		assert.False(t, result.Passed)
+		assert.Empty(t, applicable)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, "missing", result.Violations[0].Rule)
+	})
+
+	t.Run("Provenance for another image is ignored", func(t *testing.T) {
+		_, result := Validate([]*Provenance{trusted}, []string{"sha256:other"}, policy)
+		assert.False(t, result.Passed)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, "missing", result.Violations[0].Rule)
+	})
+
+	t.Run("Statement without subjects applies", func(t *testing.T) {
+		bare := *trusted
+		bare.Subjects = nil
+		_, result := Validate([]*Provenance{&bare}, []string{"sha256:other"}, policy)
+		assert.True(t, result.Passed)
+	})
+
+	t.Run("Untrusted builder, source, and build type fail", func(t *testing.T) {
+		bad := &Provenance{
+			BuilderID:        "https://example.com/builder",
+			BuildType:        "https://example.com/type",
+			SourceRepository: "https://gitlab.com/org/app",
+		}
+		strict := *policy
+		strict.BuildTypes = []string{"https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"}
+		_, result := Validate([]*Provenance{trusted, bad}, []string{"sha256:abc"}, &strict)
+		assert.False(t, result.Passed)
+		require.Len(t, result.Violations, 3)
+		assert.Equal(t, "builder", result.Violations[0].Rule)
+		assert.Equal(t, `builder "https://example.com/builder" is not trusted`, result.Violations[0].Message)
+		assert.Equal(t, "source-repository", result.Violations[1].Rule)
+		assert.Equal(t, "build-type", result.Violations[2].Rule)
+	})
+}
@@ -0,0 +1,50 @@
+package redact
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// pseudonymLength is the number of hex digits of the sha256 of a name kept in
+// its pseudonym.
+const pseudonymLength = 12
+
+// Pseudonym returns the stable anonymized form of name: kind followed by the
+// first digits of the sha256 of name, e.g. registry-3c1f0b7a9d2e. The same
+// name always maps to the same pseudonym, so anonymized reports can still be
+// compared.
+func Pseudonym(kind, name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return kind + "-" + hex.EncodeToString(sum[:])[:pseudonymLength]
+}
+
+// WithNames returns a copy of r, which may be nil, that also replaces every
+// occurrence of each key of names with its value, before applying the
+// patterns. Longer names are replaced first, so that a name is not split by a
+// shorter one it contains. Names registered on r are kept.
+func (r *Redactor) WithNames(names map[string]string) *Redactor {
+	out := &Redactor{names: make(map[string]string, len(names))}
+	if r != nil {
+		out.patterns = r.patterns
+		maps.Copy(out.names, r.names)
+	}
+	for name, replacement := range names {
+		if name != "" {
+			out.names[name] = replacement
+		}
+	}
+
+	keys := slices.SortedFunc(maps.Keys(out.names), func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k, out.names[k])
+	}
+	out.replacer = strings.NewReplacer(pairs...)
+	return out
+}
@@ -0,0 +1,160 @@
+package redact
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Replacement is the text that replaces every redacted match.
+const Replacement = "[REDACTED]"
+
+// Redactor replaces substrings matching any of its patterns with Replacement,
+// and the names registered with WithNames with their replacements.
+// A nil Redactor is valid and leaves every value unchanged.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	names    map[string]string
+	replacer *strings.Replacer
+}
+
+// New compiles the given regular expressions into a Redactor. It returns nil
+// when no patterns are given.
+func New(patterns []string) (*Redactor, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for _, p := range patterns {
+		if p == "" {
+			return nil, fmt.Errorf("redaction pattern cannot be empty")
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// String redacts s and reports whether it was altered.
+func (r *Redactor) String(s string) (string, bool) {
+	if r == nil {
+		return s, false
+	}
+	out := s
+	if r.replacer != nil {
+		out = r.replacer.Replace(out)
+	}
+	for _, re := range r.patterns {
+		out = re.ReplaceAllLiteralString(out, Replacement)
+	}
+	return out, out != s
+}
+
+// Apply returns a copy of v with every string reachable through exported
+// struct fields, slices, arrays, maps (keys and values), pointers, and
+// interfaces redacted, and reports whether anything was altered. v itself is
+// never modified.
+func (r *Redactor) Apply(v any) (any, bool) {
+	if r == nil || v == nil {
+		return v, false
+	}
+	out, changed := r.value(reflect.ValueOf(v))
+	if !changed {
+		return v, false
+	}
+	return out.Interface(), true
+}
+
+func (r *Redactor) value(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		s, changed := r.String(v.String())
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(s)
+		return out, true
+	case reflect.Struct:
+		return r.structValue(v)
+	case reflect.Slice, reflect.Array:
+		return r.sliceValue(v)
+	case reflect.Map:
+		return r.mapValue(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := r.value(v.Elem())
+		if !changed {
+			return v, false
+		}
+		if v.Kind() == reflect.Interface {
+			out := reflect.New(v.Type()).Elem()
+			out.Set(elem)
+			return out, true
+		}
+		out := reflect.New(elem.Type())
+		out.Elem().Set(elem)
+		return out, true
+	default:
+		return v, false
+	}
+}
+
+func (r *Redactor) structValue(v reflect.Value) (reflect.Value, bool) {
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	changed := false
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if f, ok := r.value(v.Field(i)); ok {
+			out.Field(i).Set(f)
+			changed = true
+		}
+	}
+	return out, changed
+}
+
+func (r *Redactor) sliceValue(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return v, false
+	}
+	var out reflect.Value
+	if v.Kind() == reflect.Slice {
+		out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	} else {
+		out = reflect.New(v.Type()).Elem()
+	}
+	reflect.Copy(out, v)
+	changed := false
+	for i := range v.Len() {
+		if e, ok := r.value(v.Index(i)); ok {
+			out.Index(i).Set(e)
+			changed = true
+		}
+	}
+	return out, changed
+}
+
+func (r *Redactor) mapValue(v reflect.Value) (reflect.Value, bool) {
+	if v.IsNil() {
+		return v, false
+	}
+	out := reflect.MakeMapWithSize(v.Type(), v.Len())
+	changed := false
+	iter := v.MapRange()
+	for iter.Next() {
+		k, kChanged := r.value(iter.Key())
+		e, eChanged := r.value(iter.Value())
+		changed = changed || kChanged || eChanged
+		out.SetMapIndex(k, e)
+	}
+	return out, changed
+}
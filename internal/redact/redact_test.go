@@ -0,0 +1,161 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantNil  bool
+		wantErr  string
+	}{
+		{name: "No patterns", patterns: nil, wantNil: true},
+		{name: "Valid patterns", patterns: []string{`internal\.corp`, `svc-[a-z]+`}},
+		{name: "Invalid pattern", patterns: []string{`(`}, wantErr: "invalid redaction pattern"},
+		{name: "Empty pattern", patterns: []string{""}, wantErr: "cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.patterns)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNil, r == nil)
+		})
+	}
+}
+
+func TestRedactor_String(t *testing.T) {
+	r, err := New([]string{`registry\.internal\.corp`, `svc-[a-z]+`})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		wantChanged bool
+	}{
+		{name: "No match", input: "docker.io/nginx", want: "docker.io/nginx"},
+		{name: "Hostname", input: "registry.internal.corp/app:1.0", want: "[REDACTED]/app:1.0", wantChanged: true},
+		{name: "Multiple patterns", input: "svc-deploy@registry.internal.corp", want: "[REDACTED]@[REDACTED]", wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := r.String(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
+func TestRedactor_NilIsNoOp(t *testing.T) {
+	var r *Redactor
+	s, changed := r.String("secret")
+	assert.Equal(t, "secret", s)
+	assert.False(t, changed)
+
+	v, changed := r.Apply(struct{ A string }{A: "secret"})
+	assert.Equal(t, struct{ A string }{A: "secret"}, v)
+	assert.False(t, changed)
+}
+
+type inner struct {
+	Name  string
+	Count int
+}
+
+type outer struct {
+	Message  string
+	Details  any
+	Items    []inner
+	Labels   map[string]string
+	Ptr      *inner
+	Fixed    [2]string
+	internal string
+}
+
+func TestRedactor_Apply(t *testing.T) {
+	r, err := New([]string{`secret`})
+	require.NoError(t, err)
+
+	input := outer{
+		Message:  "found secret",
+		Details:  inner{Name: "secret-name", Count: 3},
+		Items:    []inner{{Name: "ok"}, {Name: "secret"}},
+		Labels:   map[string]string{"owner": "secret-team", "secret-key": "v"},
+		Ptr:      &inner{Name: "my-secret"},
+		Fixed:    [2]string{"a", "secret"},
+		internal: "secret",
+	}
+
+	got, changed := r.Apply(input)
+	require.True(t, changed)
+	out := got.(outer)
+
+	assert.Equal(t, "found [REDACTED]", out.Message)
+	assert.Equal(t, inner{Name: "[REDACTED]-name", Count: 3}, out.Details)
+	assert.Equal(t, []inner{{Name: "ok"}, {Name: "[REDACTED]"}}, out.Items)
+	assert.Equal(t, map[string]string{"owner": "[REDACTED]-team", "[REDACTED]-key": "v"}, out.Labels)
+	assert.Equal(t, "my-[REDACTED]", out.Ptr.Name)
+	assert.Equal(t, [2]string{"a", "[REDACTED]"}, out.Fixed)
+	assert.Equal(t, "secret", out.internal, "unexported fields are copied as is")
+
+	// The input is never modified.
+	assert.Equal(t, "found secret", input.Message)
+	assert.Equal(t, "secret", input.Items[1].Name)
+	assert.Equal(t, "secret-team", input.Labels["owner"])
+	assert.Equal(t, "my-secret", input.Ptr.Name)
+}
+
+func TestRedactor_ApplyUnchanged(t *testing.T) {
+	r, err := New([]string{`secret`})
+	require.NoError(t, err)
+
+	input := outer{Message: "clean", Items: []inner{{Name: "ok"}}}
+	got, changed := r.Apply(input)
+	assert.False(t, changed)
+	assert.Equal(t, input, got)
+}
+
+func TestPseudonym(t *testing.T) {
+	p := Pseudonym("registry", "ghcr.io")
+	assert.Regexp(t, "^registry-[0-9a-f]{12}$", p)
+	assert.Equal(t, p, Pseudonym("registry", "ghcr.io"), "pseudonyms are stable")
+	assert.NotEqual(t, p, Pseudonym("registry", "quay.io"))
+}
+
+func TestWithNames(t *testing.T) {
+	r, err := New([]string{`svc-[a-z]+`})
+	require.NoError(t, err)
+
+	anon := r.WithNames(map[string]string{
+		"ghcr.io":         "registry-1",
+		"ghcr.io/org/app": "registry-1/repository-2",
+		"org/app":         "repository-2",
+	})
+	out, changed := anon.String("ghcr.io/org/app:1.0 pushed by svc-deploy to ghcr.io")
+	assert.True(t, changed)
+	assert.Equal(t, "registry-1/repository-2:1.0 pushed by [REDACTED] to registry-1", out)
+
+	out, _ = r.String("ghcr.io/org/app")
+	assert.Equal(t, "ghcr.io/org/app", out, "the original redactor is unchanged")
+
+	more := anon.WithNames(map[string]string{"quay.io": "registry-3"})
+	out, _ = more.String("ghcr.io quay.io")
+	assert.Equal(t, "registry-1 registry-3", out, "registered names are kept")
+
+	var nilRedactor *Redactor
+	out, changed = nilRedactor.WithNames(map[string]string{"ghcr.io": "registry-1"}).String("ghcr.io")
+	assert.True(t, changed)
+	assert.Equal(t, "registry-1", out)
+}
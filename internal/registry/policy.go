@@ -3,7 +3,9 @@ package registry
 import (
 	"fmt"
 	"slices"
+	"strings"
 
+	"github.com/jarfernandez/check-image/internal/decision"
 	"github.com/jarfernandez/check-image/internal/fileutil"
 )
 
@@ -33,19 +35,28 @@ func LoadRegistryPolicy(path string) (*Policy, error) {
 		return nil, err
 	}
 
-	// Validate that only one mode is specified
-	hasTrusted := len(policy.TrustedRegistries) > 0
-	hasExcluded := len(policy.ExcludedRegistries) > 0
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Validate checks that exactly one of trusted-registries or
+// excluded-registries is specified.
+func (p *Policy) Validate() error {
+	hasTrusted := len(p.TrustedRegistries) > 0
+	hasExcluded := len(p.ExcludedRegistries) > 0
 
 	if hasTrusted && hasExcluded {
-		return nil, fmt.Errorf("policy must specify either trusted-registries or excluded-registries, not both")
+		return fmt.Errorf("policy must specify either trusted-registries or excluded-registries, not both")
 	}
 
 	if !hasTrusted && !hasExcluded {
-		return nil, fmt.Errorf("policy must specify either trusted-registries or excluded-registries")
+		return fmt.Errorf("policy must specify either trusted-registries or excluded-registries")
 	}
 
-	return &policy, nil
+	return nil
 }
 
 // IsRegistryAllowed checks if the given registry is allowed based on the policy.
@@ -54,12 +65,24 @@ func LoadRegistryPolicy(path string) (*Policy, error) {
 func (p *Policy) IsRegistryAllowed(registry string) bool {
 	// Allowlist mode: only trusted registries are allowed
 	if len(p.TrustedRegistries) > 0 {
-		return slices.Contains(p.TrustedRegistries, registry)
+		listed := slices.Contains(p.TrustedRegistries, registry)
+		if listed {
+			decision.Explain("registry", "trusted-registries", registry, true, "registry %s is listed in trusted-registries, so it is allowed", registry)
+		} else {
+			decision.Explain("registry", "trusted-registries", registry, false, "registry %s is not listed in trusted-registries (%s), so it is not allowed", registry, strings.Join(p.TrustedRegistries, ", "))
+		}
+		return listed
 	}
 
 	// Blocklist mode: all registries except excluded ones are allowed
 	if len(p.ExcludedRegistries) > 0 {
-		return !slices.Contains(p.ExcludedRegistries, registry)
+		listed := slices.Contains(p.ExcludedRegistries, registry)
+		if listed {
+			decision.Explain("registry", "excluded-registries", registry, true, "registry %s is listed in excluded-registries, so it is not allowed", registry)
+		} else {
+			decision.Explain("registry", "excluded-registries", registry, false, "registry %s is not listed in excluded-registries (%s), so it is allowed", registry, strings.Join(p.ExcludedRegistries, ", "))
+		}
+		return !listed
 	}
 
 	// This should not happen if LoadRegistryPolicy validation works correctly
@@ -1,10 +1,13 @@
 package registry
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/jarfernandez/check-image/internal/decision"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -271,6 +274,13 @@ func TestIsRegistryAllowed_EmptyPolicy(t *testing.T) {
 	assert.False(t, got, "Empty policy should deny all registries")
 }
 
+func TestPolicyValidate(t *testing.T) {
+	require.NoError(t, (&Policy{TrustedRegistries: []string{"docker.io"}}).Validate())
+	require.NoError(t, (&Policy{ExcludedRegistries: []string{"docker.io"}}).Validate())
+	assert.ErrorContains(t, (&Policy{}).Validate(), "must specify either")
+	assert.ErrorContains(t, (&Policy{TrustedRegistries: []string{"a.io"}, ExcludedRegistries: []string{"b.io"}}).Validate(), "not both")
+}
+
 func TestLoadRegistryPolicy_Stdin(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -392,3 +402,23 @@ func FuzzLoadRegistryPolicyYAML(f *testing.F) {
 		_, _ = LoadRegistryPolicy(path)
 	})
 }
+
+func TestIsRegistryAllowed_ExplainDecisions(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	decision.SetEnabled(true)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		decision.SetEnabled(false)
+	})
+
+	trusted := &Policy{TrustedRegistries: []string{"docker.io", "ghcr.io"}}
+	assert.False(t, trusted.IsRegistryAllowed("quay.io"))
+	assert.Contains(t, buf.String(), "registry quay.io is not listed in trusted-registries (docker.io, ghcr.io), so it is not allowed")
+
+	buf.Reset()
+	excluded := &Policy{ExcludedRegistries: []string{"quay.io"}}
+	assert.False(t, excluded.IsRegistryAllowed("quay.io"))
+	assert.Contains(t, buf.String(), "rule=excluded-registries")
+	assert.Contains(t, buf.String(), "matched=true")
+}
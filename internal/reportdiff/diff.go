@@ -0,0 +1,200 @@
+// Package reportdiff compares two JSON reports of the all command, so that a
+// pipeline can fail only on regressions relative to a baseline report.
+package reportdiff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+// Finding is a single problem reported by a check, such as a secret file or a
+// missing label. Kind says what Value is.
+type Finding struct {
+	Check string
+	Kind  string
+	Value string
+}
+
+// Diff is the comparison of a new report with an old one. Every list is
+// sorted, so the same reports always produce the same diff.
+type Diff struct {
+	// NewlyFailing lists checks that fail in the new report but passed, or
+	// did not run, in the old one.
+	NewlyFailing []string
+	// NewlyPassing lists checks that failed in the old report and pass in the
+	// new one.
+	NewlyPassing    []string
+	NewFindings     []Finding
+	RemovedFindings []Finding
+}
+
+// Regressed reports whether the new report is worse than the old one.
+func (d Diff) Regressed() bool {
+	return len(d.NewlyFailing) > 0 || len(d.NewFindings) > 0
+}
+
+// findingSource is a list in the details of a check whose entries are
+// findings. Field is the key identifying an entry of a list of objects; it is
+// empty for lists of plain values.
+type findingSource struct {
+	list  string
+	field string
+	kind  string
+}
+
+var findingSources = map[string][]findingSource{
+	"secrets": {
+		{list: "env-var-findings", field: "name", kind: "env-var"},
+		{list: "file-findings", field: "path", kind: "file"},
+	},
+	"labels": {
+		{list: "missing-labels", kind: "missing-label"},
+		{list: "invalid-labels", field: "name", kind: "invalid-label"},
+	},
+	"ports": {
+		{list: "unauthorized-ports", kind: "port"},
+	},
+	"user": {
+		{list: "violations", field: "rule", kind: "violation"},
+	},
+	"provenance": {
+		{list: "violations", field: "message", kind: "violation"},
+	},
+	"entropy": {
+		{list: "findings", field: "path", kind: "file"},
+	},
+}
+
+// Parse reads an all command report. Reports compressed with --compress are
+// decompressed first.
+func Parse(data []byte) (*output.AllResult, error) {
+	data, err := output.Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		output.AllResult
+		Images json.RawMessage `json:"images"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid JSON report: %w", err)
+	}
+	if report.Images != nil {
+		return nil, fmt.Errorf("bulk reports are not supported, compare the reports of single images")
+	}
+	if report.Checks == nil {
+		return nil, fmt.Errorf("not a report of the all command: no checks")
+	}
+	return &report.AllResult, nil
+}
+
+// Compare returns what changed from the old report to the new one. Checks
+// that fail because of an error count as failing. Checks missing from the new
+// report, e.g. skipped, are not compared.
+func Compare(oldReport, newReport *output.AllResult) Diff {
+	oldChecks := make(map[string]output.CheckResult, len(oldReport.Checks))
+	for _, c := range oldReport.Checks {
+		oldChecks[c.Check] = c
+	}
+
+	d := Diff{
+		NewlyFailing:    []string{},
+		NewlyPassing:    []string{},
+		NewFindings:     []Finding{},
+		RemovedFindings: []Finding{},
+	}
+	for _, c := range newReport.Checks {
+		prev, ran := oldChecks[c.Check]
+		switch {
+		case !c.Passed && (!ran || prev.Passed):
+			d.NewlyFailing = append(d.NewlyFailing, c.Check)
+		case c.Passed && ran && !prev.Passed:
+			d.NewlyPassing = append(d.NewlyPassing, c.Check)
+		}
+
+		before := findings(prev)
+		after := findings(c)
+		for _, f := range after {
+			if !slices.Contains(before, f) {
+				d.NewFindings = append(d.NewFindings, f)
+			}
+		}
+		for _, f := range before {
+			if !slices.Contains(after, f) {
+				d.RemovedFindings = append(d.RemovedFindings, f)
+			}
+		}
+	}
+
+	slices.Sort(d.NewlyFailing)
+	slices.Sort(d.NewlyPassing)
+	slices.SortFunc(d.NewFindings, compareFindings)
+	slices.SortFunc(d.RemovedFindings, compareFindings)
+	return d
+}
+
+func compareFindings(a, b Finding) int {
+	return cmp.Or(
+		cmp.Compare(a.Check, b.Check),
+		cmp.Compare(a.Kind, b.Kind),
+		cmp.Compare(a.Value, b.Value),
+	)
+}
+
+// findings extracts the findings of a check result from its details. Details
+// are decoded generically, so reports of older or newer versions still
+// compare on the lists they share.
+func findings(c output.CheckResult) []Finding {
+	sources := findingSources[c.Check]
+	if len(sources) == 0 || c.Details == nil {
+		return nil
+	}
+	details, ok := asObject(c.Details)
+	if !ok {
+		return nil
+	}
+
+	var out []Finding
+	for _, src := range sources {
+		entries, _ := details[src.list].([]any)
+		for _, e := range entries {
+			v := e
+			if src.field != "" {
+				obj, ok := e.(map[string]any)
+				if !ok {
+					continue
+				}
+				v = obj[src.field]
+			}
+			if v == nil {
+				continue
+			}
+			f := Finding{Check: c.Check, Kind: src.kind, Value: fmt.Sprint(v)}
+			if !slices.Contains(out, f) {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// asObject returns details as a generic JSON object. Details parsed from a
+// report already are one; typed details are round-tripped through JSON.
+func asObject(details any) (map[string]any, bool) {
+	if m, ok := details.(map[string]any); ok {
+		return m, true
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
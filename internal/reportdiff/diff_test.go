@@ -0,0 +1,124 @@
+package reportdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Report", func(t *testing.T) {
+		r, err := Parse([]byte(`{"image":"nginx:latest","passed":false,"checks":[
+			{"check":"secrets","image":"nginx:latest","passed":false,"message":"m",
+			 "details":{"file-findings":[{"path":"/root/.ssh/id_rsa","layer-index":0}]}}
+		],"summary":{"total":1}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "nginx:latest", r.Image)
+		require.Len(t, r.Checks, 1)
+		assert.Equal(t, []Finding{{Check: "secrets", Kind: "file", Value: "/root/.ssh/id_rsa"}}, findings(r.Checks[0]))
+	})
+
+	t.Run("Compressed report", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := output.NewCompressedWriter(&buf, output.CompressionGzip)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, `{"image":"nginx:latest","passed":true,"checks":[]}`)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := Parse(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "nginx:latest", r.Image)
+	})
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "Invalid JSON", data: `{`, wantErr: "invalid JSON report"},
+		{name: "Bulk report", data: `{"passed":true,"images":[]}`, wantErr: "bulk reports are not supported"},
+		{name: "Not an all report", data: `{"check":"age","passed":true}`, wantErr: "not a report of the all command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	oldReport := &output.AllResult{Checks: []output.CheckResult{
+		{Check: "age", Passed: true},
+		{Check: "size", Passed: false},
+		{Check: "ports", Passed: false, Details: output.PortsDetails{UnauthorizedPorts: []int{22}}},
+		{Check: "secrets", Passed: false, Details: output.SecretsDetails{
+			EnvVarFindings: []output.EnvVarFinding{{Name: "API_TOKEN"}},
+			FileFindings:   []output.FileFinding{{Path: "/app/.env"}},
+		}},
+	}}
+	newReport := &output.AllResult{Checks: []output.CheckResult{
+		{Check: "age", Passed: false},
+		{Check: "size", Passed: true},
+		{Check: "ports", Passed: false, Details: output.PortsDetails{UnauthorizedPorts: []int{22}}},
+		{Check: "secrets", Passed: false, Details: output.SecretsDetails{
+			FileFindings: []output.FileFinding{{Path: "/root/.ssh/id_rsa"}, {Path: "/app/.env", LayerIndex: 3}},
+		}},
+		{Check: "labels", Passed: false, Details: output.LabelsDetails{MissingLabels: []string{"maintainer"}}},
+	}}
+
+	d := Compare(oldReport, newReport)
+	assert.Equal(t, []string{"age", "labels"}, d.NewlyFailing, "checks that did not run before count as newly failing")
+	assert.Equal(t, []string{"size"}, d.NewlyPassing)
+	assert.Equal(t, []Finding{
+		{Check: "labels", Kind: "missing-label", Value: "maintainer"},
+		{Check: "secrets", Kind: "file", Value: "/root/.ssh/id_rsa"},
+	}, d.NewFindings, "findings are compared on their identity, not their layer")
+	assert.Equal(t, []Finding{{Check: "secrets", Kind: "env-var", Value: "API_TOKEN"}}, d.RemovedFindings)
+	assert.True(t, d.Regressed())
+}
+
+func TestCompare_NoRegression(t *testing.T) {
+	report := &output.AllResult{Checks: []output.CheckResult{
+		{Check: "ports", Passed: false, Details: output.PortsDetails{UnauthorizedPorts: []int{22, 8080}}},
+		{Check: "user", Passed: false, Details: output.UserDetails{Violations: []output.UserViolation{{Rule: "root-user"}}}},
+	}}
+	improved := &output.AllResult{Checks: []output.CheckResult{
+		{Check: "ports", Passed: false, Details: output.PortsDetails{UnauthorizedPorts: []int{8080}}},
+	}}
+
+	d := Compare(report, improved)
+	assert.False(t, d.Regressed(), "removed checks and findings are not regressions")
+	assert.Empty(t, d.NewlyFailing)
+	assert.Empty(t, d.NewlyPassing, "checks missing from the new report are not compared")
+	assert.Equal(t, []Finding{{Check: "ports", Kind: "port", Value: "22"}}, d.RemovedFindings)
+
+	same := Compare(report, report)
+	assert.False(t, same.Regressed())
+	assert.Empty(t, same.RemovedFindings)
+}
+
+func TestCompare_Deterministic(t *testing.T) {
+	data, err := json.Marshal(output.AllResult{Checks: []output.CheckResult{
+		{Check: "secrets", Passed: false, Details: output.SecretsDetails{FileFindings: []output.FileFinding{
+			{Path: "/z"}, {Path: "/a"}, {Path: "/m"},
+		}}},
+	}})
+	require.NoError(t, err)
+	newReport, err := Parse(data)
+	require.NoError(t, err)
+
+	d := Compare(&output.AllResult{Checks: []output.CheckResult{}}, newReport)
+	assert.Equal(t, []Finding{
+		{Check: "secrets", Kind: "file", Value: "/a"},
+		{Check: "secrets", Kind: "file", Value: "/m"},
+		{Check: "secrets", Kind: "file", Value: "/z"},
+	}, d.NewFindings)
+}
@@ -3,6 +3,8 @@ package secrets
 import (
 	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"strings"
@@ -10,6 +12,8 @@ import (
 	cr "github.com/google/go-containerregistry/pkg/v1"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/jarfernandez/check-image/internal/decision"
+	"github.com/jarfernandez/check-image/internal/imageutil"
 	"github.com/jarfernandez/check-image/internal/logutil"
 	"github.com/jarfernandez/check-image/internal/output"
 )
@@ -35,22 +39,30 @@ func CheckEnvironmentVariables(envVars []string, policy *Policy) []output.EnvVar
 		// Check if this variable is in the exclusion list
 		if isExcluded(varName, policy.ExcludedEnvVars) {
 			log.WithField("var", logutil.SanitizeLogValue(varName)).Debug("Skipping excluded environment variable")
+			decision.Explain("secrets", "excluded-env-vars", varName, true, "environment variable %s is listed in excluded-env-vars, so it is not checked", varName)
 			continue
 		}
 
 		// Check if the variable name matches any sensitive patterns (case-insensitive)
 		varNameLower := strings.ToLower(varName)
+		matched := false
 		for _, pattern := range patterns {
 			patternLower := strings.ToLower(pattern)
 			if strings.Contains(varNameLower, patternLower) {
 				findings = append(findings, output.EnvVarFinding{
 					Name:        varName,
 					Description: "sensitive pattern detected",
+					Severity:    policy.EnvSeverity(pattern),
 				})
 				log.WithFields(log.Fields{"var": logutil.SanitizeLogValue(varName), "pattern": logutil.SanitizeLogValue(pattern)}).Debug("Found sensitive environment variable")
+				decision.Explain("secrets", "env-patterns", varName, true, "environment variable %s contains the sensitive pattern %q", varName, pattern)
+				matched = true
 				break
 			}
 		}
+		if !matched {
+			decision.Explain("secrets", "env-patterns", varName, false, "environment variable %s contains no sensitive pattern", varName)
+		}
 	}
 
 	return findings
@@ -80,7 +92,8 @@ func CheckFilesInLayers(ctx context.Context, image cr.Image, policy *Policy) ([]
 
 		findings, err := scanLayer(ctx, layer, i, policy)
 		if err != nil {
-			log.WithFields(log.Fields{"layer": i, "error": err}).Warn("Error scanning layer")
+			mediaType, _ := layer.MediaType()
+			log.WithFields(log.Fields{"layer": i, "media-type": mediaType, "error": err}).Warn("Error scanning layer, its files were not checked")
 			continue
 		}
 
@@ -99,7 +112,7 @@ func CheckFilesInLayers(ctx context.Context, image cr.Image, policy *Policy) ([]
 // scanLayer scans a single layer for sensitive files.
 // It checks for context cancellation before processing each tar entry.
 func scanLayer(ctx context.Context, layer cr.Layer, layerIndex int, policy *Policy) ([]output.FileFinding, error) {
-	rc, err := layer.Uncompressed()
+	rc, err := imageutil.OpenLayer(layer)
 	if err != nil {
 		return nil, fmt.Errorf("error uncompressing layer: %w", err)
 	}
@@ -132,17 +145,30 @@ func scanLayer(ctx context.Context, layer cr.Layer, layerIndex int, policy *Poli
 		}
 
 		// Check if path should be excluded
-		if isPathExcluded(header.Name, policy.ExcludedPaths) {
+		if pattern, excluded := matchExcludedPath(header.Name, policy.ExcludedPaths); excluded {
 			log.WithField("path", logutil.SanitizeLogValue(header.Name)).Debug("Skipping excluded path")
+			decision.Explain("secrets", "excluded-paths", header.Name, true, "path %s matches the exclusion %q, so it is not checked", header.Name, pattern)
 			continue
 		}
 
 		// Check if file matches any sensitive patterns
-		if matchesPattern, description := matchesFilePattern(header.Name, patterns); matchesPattern {
+		if pattern, matched := matchFilePattern(header.Name, patterns); matched {
+			allowed, err := isContentAllowed(tarReader, policy)
+			if err != nil {
+				return nil, fmt.Errorf("error hashing %s: %w", header.Name, err)
+			}
+			if allowed {
+				log.WithField("path", logutil.SanitizeLogValue(header.Name)).Debug("Skipping file with allow-listed content hash")
+				decision.Explain("secrets", "allowed-hashes", header.Name, true, "path %s matches the sensitive pattern %q, but its content hash is listed in allowed-hashes", header.Name, pattern)
+				continue
+			}
+			decision.Explain("secrets", "file-patterns", header.Name, true, "path %s in layer %d matches the sensitive pattern %q", header.Name, layerIndex, pattern)
+			description := describePattern(pattern)
 			findings = append(findings, output.FileFinding{
 				Path:        header.Name,
 				LayerIndex:  layerIndex,
 				Description: description,
+				Severity:    policy.FileSeverity(pattern),
 			})
 			log.WithFields(log.Fields{"layer": layerIndex, "path": logutil.SanitizeLogValue(header.Name), "description": logutil.SanitizeLogValue(description)}).Debug("Found sensitive file")
 		}
@@ -150,3 +176,18 @@ func scanLayer(ctx context.Context, layer cr.Layer, layerIndex int, policy *Poli
 
 	return findings, nil
 }
+
+// isContentAllowed hashes the current tar entry and reports whether its sha256
+// digest is in the policy's allow-list. Content is only read when the policy
+// defines allowed hashes, so the common case costs nothing beyond the header.
+func isContentAllowed(r io.Reader, policy *Policy) (bool, error) {
+	if len(policy.AllowedHashes) == 0 {
+		return false, nil
+	}
+	h := sha256.New()
+	// #nosec G110 -- entry size is bounded by the layer itself; nothing is written to disk
+	if _, err := io.Copy(h, r); err != nil {
+		return false, err
+	}
+	return policy.IsHashAllowed(hex.EncodeToString(h.Sum(nil))), nil
+}
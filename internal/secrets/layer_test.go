@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/jarfernandez/check-image/internal/imageutil"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawLayer is a stub v1.Layer whose Uncompressed() returns its data as is,
+// like layers whose source does not recognize their compression.
+type rawLayer struct {
+	data      []byte
+	mediaType types.MediaType
+}
+
+func (l rawLayer) Digest() (v1.Hash, error) { return v1.Hash{}, nil }
+func (l rawLayer) DiffID() (v1.Hash, error) { return v1.Hash{}, nil }
+func (l rawLayer) Compressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(l.data)), nil
+}
+func (l rawLayer) Uncompressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(l.data)), nil
+}
+func (l rawLayer) Size() (int64, error)                { return int64(len(l.data)), nil }
+func (l rawLayer) MediaType() (types.MediaType, error) { return l.mediaType, nil }
+
+func tarData(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestOpenLayer(t *testing.T) {
+	plain := tarData(t, map[string]string{"/root/.ssh/id_rsa": "key"})
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zs bytes.Buffer
+	zw, err := zstd.NewWriter(&zs)
+	require.NoError(t, err)
+	_, err = zw.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := []struct {
+		name  string
+		layer rawLayer
+	}{
+		{"zstd", rawLayer{zs.Bytes(), types.OCILayerZStd}},
+		{"gzip", rawLayer{gz.Bytes(), types.DockerLayer}},
+		{"uncompressed tar", rawLayer{plain, types.OCIUncompressedLayer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := imageutil.OpenLayer(tt.layer)
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, plain, data)
+
+			findings, err := scanLayer(context.Background(), tt.layer, 2, &Policy{CheckFiles: true})
+			require.NoError(t, err)
+			require.Len(t, findings, 1)
+			assert.Equal(t, "/root/.ssh/id_rsa", findings[0].Path)
+			assert.Equal(t, 2, findings[0].LayerIndex)
+		})
+	}
+
+	t.Run("empty layer", func(t *testing.T) {
+		findings, err := scanLayer(context.Background(), rawLayer{nil, types.OCIUncompressedLayer}, 0, &Policy{CheckFiles: true})
+		require.NoError(t, err)
+		assert.Empty(t, findings)
+	})
+
+	t.Run("corrupt gzip header", func(t *testing.T) {
+		_, err := imageutil.OpenLayer(rawLayer{[]byte{0x1f, 0x8b, 0x00}, types.DockerLayer})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading gzip layer")
+	})
+}
@@ -13,12 +13,18 @@ func isExcluded(value string, exclusionList []string) bool {
 
 // isPathExcluded checks if a path matches any exclusion patterns
 func isPathExcluded(path string, excludedPatterns []string) bool {
+	_, excluded := matchExcludedPath(path, excludedPatterns)
+	return excluded
+}
+
+// matchExcludedPath returns the first exclusion pattern that path matches.
+func matchExcludedPath(path string, excludedPatterns []string) (string, bool) {
 	for _, pattern := range excludedPatterns {
 		if isDirectoryPattern(path, pattern) || isGlobPattern(path, pattern) {
-			return true
+			return pattern, true
 		}
 	}
-	return false
+	return "", false
 }
 
 // isDirectoryPattern reports whether path falls under a directory exclusion pattern
@@ -41,21 +47,21 @@ func isGlobPattern(path, pattern string) bool {
 	return err == nil && matched
 }
 
-// matchesFilePattern checks if a file path matches any sensitive patterns
-func matchesFilePattern(path string, patterns []string) (bool, string) {
+// matchFilePattern returns the first sensitive pattern a file path matches.
+func matchFilePattern(path string, patterns []string) (string, bool) {
 	basename := filepath.Base(path)
 
 	for _, pattern := range patterns {
 		// Try matching against basename first
 		matched, err := filepath.Match(pattern, basename)
 		if err == nil && matched {
-			return true, describePattern(pattern)
+			return pattern, true
 		}
 
 		// Try matching against full path
 		matched, err = filepath.Match(pattern, path)
 		if err == nil && matched {
-			return true, describePattern(pattern)
+			return pattern, true
 		}
 
 		// Handle path-based patterns (e.g., .aws/credentials).
@@ -64,12 +70,12 @@ func matchesFilePattern(path string, patterns []string) (bool, string) {
 		// must not match ".aws/credentials-backup").
 		if strings.Contains(pattern, "/") {
 			if strings.HasSuffix(path, "/"+pattern) || strings.HasSuffix(path, pattern) {
-				return true, describePattern(pattern)
+				return pattern, true
 			}
 		}
 	}
 
-	return false, ""
+	return "", false
 }
 
 // describePattern provides a human-readable description for a pattern
@@ -1,8 +1,11 @@
 package secrets
 
 import (
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/jarfernandez/check-image/internal/fileutil"
 )
@@ -15,6 +18,14 @@ type Policy struct {
 	ExcludedEnvVars    []string `yaml:"excluded-env-vars" json:"excluded-env-vars"`
 	CustomEnvPatterns  []string `yaml:"custom-env-patterns" json:"custom-env-patterns"`
 	CustomFilePatterns []string `yaml:"custom-file-patterns" json:"custom-file-patterns"`
+	// AllowedHashes lists sha256 digests of known-benign files (e.g. test
+	// fixtures that look like keys). A file matching a sensitive pattern is
+	// not reported when its content hash is in this list, which suppresses a
+	// precise false positive without excluding the whole path.
+	AllowedHashes []string `yaml:"allowed-hashes" json:"allowed-hashes"`
+	// Severities overrides the severity of the findings of an environment
+	// variable or file pattern, default or custom, keyed by the pattern.
+	Severities map[string]string `yaml:"severities,omitempty" json:"severities,omitempty"`
 }
 
 // Default patterns for detection
@@ -104,9 +115,77 @@ func LoadSecretsPolicy(path string) (*Policy, error) {
 		policy.ExcludedEnvVars = DefaultExcludedEnvVars
 	}
 
+	if err := policy.normalizeAllowedHashes(); err != nil {
+		return nil, err
+	}
+
+	if err := policy.normalizeSeverities(); err != nil {
+		return nil, err
+	}
+
 	return &policy, nil
 }
 
+// normalizeAllowedHashes validates each allowed hash and rewrites it to its
+// canonical form (lowercase hex without the optional "sha256:" prefix) so that
+// lookups during layer scanning are a plain string comparison.
+func (p *Policy) normalizeAllowedHashes() error {
+	for i, h := range p.AllowedHashes {
+		normalized, err := normalizeHash(h)
+		if err != nil {
+			return err
+		}
+		p.AllowedHashes[i] = normalized
+	}
+	return nil
+}
+
+// normalizeSeverities validates each severity override and rewrites it to
+// its lowercase form.
+func (p *Policy) normalizeSeverities() error {
+	for pattern, s := range p.Severities {
+		severity, err := ParseSeverity(s)
+		if err != nil {
+			return fmt.Errorf("severity of pattern %q: %w", pattern, err)
+		}
+		p.Severities[pattern] = severity
+	}
+	return nil
+}
+
+// Validate checks that every allowed hash is a sha256 digest and every
+// severity override is a valid severity, without rewriting the policy.
+func (p *Policy) Validate() error {
+	for _, h := range p.AllowedHashes {
+		if _, err := normalizeHash(h); err != nil {
+			return err
+		}
+	}
+	for pattern, s := range p.Severities {
+		if _, err := ParseSeverity(s); err != nil {
+			return fmt.Errorf("severity of pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func normalizeHash(h string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(h), "sha256:"))
+	if len(normalized) != 64 {
+		return "", fmt.Errorf("invalid allowed hash %q: expected a sha256 digest (64 hex characters)", h)
+	}
+	if _, err := hex.DecodeString(normalized); err != nil {
+		return "", fmt.Errorf("invalid allowed hash %q: expected a sha256 digest (64 hex characters)", h)
+	}
+	return normalized, nil
+}
+
+// IsHashAllowed reports whether the given lowercase hex sha256 digest is in
+// the policy's allow-list.
+func (p *Policy) IsHashAllowed(hash string) bool {
+	return slices.Contains(p.AllowedHashes, hash)
+}
+
 // GetEnvPatterns returns all environment variable patterns (default + custom)
 func (p *Policy) GetEnvPatterns() []string {
 	patterns := make([]string, len(DefaultEnvPatterns))
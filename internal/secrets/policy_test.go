@@ -174,6 +174,79 @@ func TestLoadSecretsPolicy_FileErrors(t *testing.T) {
 	})
 }
 
+func TestLoadSecretsPolicy_AllowedHashes(t *testing.T) {
+	const digest = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	tests := []struct {
+		name        string
+		content     string
+		want        []string
+		errContains string
+	}{
+		{
+			name:    "Plain hex digest",
+			content: `{"allowed-hashes": ["` + digest + `"]}`,
+			want:    []string{digest},
+		},
+		{
+			name:    "Prefixed and uppercase digest is normalized",
+			content: `{"allowed-hashes": ["sha256:E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"]}`,
+			want:    []string{digest},
+		},
+		{
+			name:        "Too short",
+			content:     `{"allowed-hashes": ["abc123"]}`,
+			errContains: "invalid allowed hash",
+		},
+		{
+			name:        "Not hex",
+			content:     `{"allowed-hashes": ["zz` + digest[2:] + `"]}`,
+			errContains: "invalid allowed hash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyFile := filepath.Join(t.TempDir(), "policy.json")
+			require.NoError(t, os.WriteFile(policyFile, []byte(tt.content), 0600))
+
+			policy, err := LoadSecretsPolicy(policyFile)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy.AllowedHashes)
+			assert.True(t, policy.IsHashAllowed(digest))
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	const digest = "sha256:E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+
+	policy := &Policy{AllowedHashes: []string{digest}}
+	require.NoError(t, policy.Validate())
+	assert.Equal(t, []string{digest}, policy.AllowedHashes, "Validate must not rewrite the policy")
+
+	assert.ErrorContains(t, (&Policy{AllowedHashes: []string{"abc123"}}).Validate(), "invalid allowed hash")
+	assert.ErrorContains(t, (&Policy{Severities: map[string]string{"id_rsa": "severe"}}).Validate(), `severity of pattern "id_rsa"`)
+}
+
+func TestLoadSecretsPolicy_Severities(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte("severities:\n  token: HIGH\n"), 0600))
+
+	policy, err := LoadSecretsPolicy(policyFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"token": "high"}, policy.Severities)
+
+	require.NoError(t, os.WriteFile(policyFile, []byte("severities:\n  token: urgent\n"), 0600))
+	_, err = LoadSecretsPolicy(policyFile)
+	assert.ErrorContains(t, err, "invalid severity")
+}
+
 func TestGetEnvPatterns(t *testing.T) {
 	policy := &Policy{
 		CustomEnvPatterns: []string{"custom1", "custom2"},
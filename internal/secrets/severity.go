@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severities of findings, from least to most serious.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// severityOrder lists the valid severities, from least to most serious.
+var severityOrder = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// DefaultSeverity is the severity of a match that says little about what was
+// leaked: every environment variable pattern and every custom file pattern.
+const DefaultSeverity = SeverityMedium
+
+// DefaultFileSeverities maps each of the DefaultFilePatterns to the severity
+// of a file that matches it: private keys and cloud credentials are critical.
+var DefaultFileSeverities = map[string]string{
+	"id_rsa":           SeverityCritical,
+	"id_dsa":           SeverityCritical,
+	"id_ecdsa":         SeverityCritical,
+	"id_ed25519":       SeverityCritical,
+	"*.ppk":            SeverityCritical,
+	".aws/credentials": SeverityCritical,
+	".kube/config":     SeverityCritical,
+	"*.key":            SeverityCritical,
+	"/etc/shadow":      SeverityHigh,
+	".pgpass":          SeverityHigh,
+	".my.cnf":          SeverityHigh,
+	".netrc":           SeverityHigh,
+	".npmrc":           SeverityHigh,
+	".git-credentials": SeverityHigh,
+	"wallet.dat":       SeverityHigh,
+	"secrets.json":     SeverityMedium,
+	"secrets.yaml":     SeverityMedium,
+	"secrets.yml":      SeverityMedium,
+}
+
+// ParseSeverity returns the severity named by s, case-insensitively.
+func ParseSeverity(s string) (string, error) {
+	severity := strings.ToLower(strings.TrimSpace(s))
+	if severityRank(severity) == 0 {
+		return "", fmt.Errorf("invalid severity %q, valid values are: %s", s, strings.Join(severityOrder, ", "))
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether severity is as serious as threshold or more.
+func AtLeast(severity, threshold string) bool {
+	return severityRank(severity) >= severityRank(threshold)
+}
+
+// severityRank returns the position of severity in severityOrder counted from 1,
+// or 0 for an unknown severity.
+func severityRank(severity string) int {
+	for i, s := range severityOrder {
+		if s == severity {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// EnvSeverity returns the severity of an environment variable that matches
+// pattern: its override in the policy, or DefaultSeverity.
+func (p *Policy) EnvSeverity(pattern string) string {
+	if s, ok := p.Severities[pattern]; ok {
+		return s
+	}
+	return DefaultSeverity
+}
+
+// FileSeverity returns the severity of a file that matches pattern: its
+// override in the policy, its default severity, or DefaultSeverity.
+func (p *Policy) FileSeverity(pattern string) string {
+	if s, ok := p.Severities[pattern]; ok {
+		return s
+	}
+	if s, ok := DefaultFileSeverities[pattern]; ok {
+		return s
+	}
+	return DefaultSeverity
+}
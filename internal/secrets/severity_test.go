@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []string{"low", "Medium", " HIGH ", "critical"} {
+		got, err := ParseSeverity(s)
+		require.NoError(t, err)
+		assert.Contains(t, severityOrder, got)
+	}
+
+	_, err := ParseSeverity("severe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "valid values are: low, medium, high, critical")
+}
+
+func TestAtLeast(t *testing.T) {
+	assert.True(t, AtLeast(SeverityCritical, SeverityHigh))
+	assert.True(t, AtLeast(SeverityHigh, SeverityHigh))
+	assert.False(t, AtLeast(SeverityMedium, SeverityHigh))
+	assert.False(t, AtLeast("", SeverityLow), "findings without a severity are below every threshold")
+}
+
+func TestDefaultFileSeverities(t *testing.T) {
+	for pattern := range DefaultFilePatterns {
+		severity, ok := DefaultFileSeverities[pattern]
+		assert.True(t, ok, "Pattern %s should have a default severity", pattern)
+		assert.NotZero(t, severityRank(severity), "Pattern %s has an invalid severity", pattern)
+	}
+	assert.Len(t, DefaultFileSeverities, len(DefaultFilePatterns))
+	assert.Equal(t, SeverityCritical, DefaultFileSeverities["id_rsa"])
+	assert.Equal(t, SeverityCritical, DefaultFileSeverities[".aws/credentials"])
+}
+
+func TestPolicySeverity(t *testing.T) {
+	policy := &Policy{Severities: map[string]string{"token": SeverityHigh, "*.key": SeverityLow, "*.pem": SeverityCritical}}
+
+	assert.Equal(t, SeverityHigh, policy.EnvSeverity("token"))
+	assert.Equal(t, DefaultSeverity, policy.EnvSeverity("password"))
+	assert.Equal(t, SeverityLow, policy.FileSeverity("*.key"))
+	assert.Equal(t, SeverityCritical, policy.FileSeverity("id_rsa"))
+	assert.Equal(t, SeverityCritical, policy.FileSeverity("*.pem"))
+	assert.Equal(t, DefaultSeverity, policy.FileSeverity("*.secret"))
+}
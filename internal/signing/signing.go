@@ -0,0 +1,251 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jarfernandez/check-image/internal/fileutil"
+)
+
+// JWS algorithm identifiers supported for report signatures.
+const (
+	AlgES256 = "ES256"
+	AlgES384 = "ES384"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// ErrInvalidSignature is returned when a signature does not match the payload.
+var ErrInvalidSignature = errors.New("signature does not match report")
+
+// header is the protected JWS header attached to every signature.
+type header struct {
+	Alg string `json:"alg"`
+	Cty string `json:"cty"`
+}
+
+// LoadPrivateKey reads a PEM-encoded ECDSA, RSA, or Ed25519 private key.
+// PKCS#8, SEC 1 ("EC PRIVATE KEY"), and PKCS#1 ("RSA PRIVATE KEY") are accepted.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(block)
+}
+
+// LoadPublicKey reads a PEM-encoded public key. A private key file is also
+// accepted, in which case its public half is returned.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "PUBLIC KEY" {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		return pub, nil
+	}
+	signer, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Public(), nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := fileutil.ReadSecureFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key file %s does not contain PEM data", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC private key: %w", err)
+		}
+		return key, nil
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// algorithmFor returns the JWS algorithm matching the public key type.
+func algorithmFor(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return AlgES256, nil
+		case elliptic.P384():
+			return AlgES384, nil
+		default:
+			return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// SignDetached signs payload and returns a compact JWS with a detached payload
+// (RFC 7515, Appendix F): "<header>..<signature>". The payload must be kept
+// byte-for-byte identical for verification to succeed.
+func SignDetached(key crypto.Signer, payload []byte) (string, error) {
+	alg, err := algorithmFor(key.Public())
+	if err != nil {
+		return "", err
+	}
+
+	hdr, err := json.Marshal(header{Alg: alg, Cty: "json"})
+	if err != nil {
+		return "", fmt.Errorf("error encoding signature header: %w", err)
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(hdr)
+	signingInput := encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	sig, err := sign(key, alg, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("error signing report: %w", err)
+	}
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyDetached checks a detached compact JWS produced by SignDetached against
+// payload. It returns the algorithm on success and ErrInvalidSignature when the
+// signature does not match.
+func VerifyDetached(pub crypto.PublicKey, payload []byte, jws string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(jws), ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return "", fmt.Errorf("malformed detached signature: expected <header>..<signature>")
+	}
+
+	hdrBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("malformed signature header: %w", err)
+	}
+	var hdr header
+	if err := json.Unmarshal(hdrBytes, &hdr); err != nil {
+		return "", fmt.Errorf("malformed signature header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed signature value: %w", err)
+	}
+
+	// The algorithm is derived from the key, never trusted from the header,
+	// so a forged header cannot downgrade verification.
+	alg, err := algorithmFor(pub)
+	if err != nil {
+		return "", err
+	}
+	if hdr.Alg != alg {
+		return "", fmt.Errorf("signature algorithm %q does not match key algorithm %q", hdr.Alg, alg)
+	}
+
+	signingInput := []byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload))
+	if !verify(pub, alg, signingInput, sig) {
+		return "", ErrInvalidSignature
+	}
+	return alg, nil
+}
+
+func digest(alg string, data []byte) ([]byte, crypto.Hash) {
+	if alg == AlgES384 {
+		h := sha512.Sum384(data)
+		return h[:], crypto.SHA384
+	}
+	h := sha256.Sum256(data)
+	return h[:], crypto.SHA256
+}
+
+func sign(key crypto.Signer, alg string, data []byte) ([]byte, error) {
+	if alg == AlgEdDSA {
+		return key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	d, hash := digest(alg, data)
+	sig, err := key.Sign(rand.Reader, d, hash)
+	if err != nil {
+		return nil, err
+	}
+	if alg == AlgRS256 {
+		return sig, nil
+	}
+
+	// JWS encodes ECDSA signatures as fixed-size R||S instead of ASN.1 DER.
+	var esig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &esig); err != nil {
+		return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	size := (key.Public().(*ecdsa.PublicKey).Curve.Params().BitSize + 7) / 8
+	out := make([]byte, 2*size)
+	esig.R.FillBytes(out[:size])
+	esig.S.FillBytes(out[size:])
+	return out, nil
+}
+
+func verify(pub crypto.PublicKey, alg string, data, sig []byte) bool {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, data, sig)
+	case *rsa.PublicKey:
+		d, hash := digest(alg, data)
+		return rsa.VerifyPKCS1v15(k, hash, d, sig) == nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		d, _ := digest(alg, data)
+		return ecdsa.Verify(k, d, r, s)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,164 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+func writePublicKey(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return writePEM(t, "PUBLIC KEY", der)
+}
+
+func TestSignAndVerifyDetached(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecDER, err := x509.MarshalECPrivateKey(p256)
+	require.NoError(t, err)
+	p384DER, err := x509.MarshalPKCS8PrivateKey(p384)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		keyPath     string
+		pub         crypto.PublicKey
+		expectedAlg string
+	}{
+		{"ECDSA P-256 SEC 1", writePEM(t, "EC PRIVATE KEY", ecDER), p256.Public(), AlgES256},
+		{"ECDSA P-384 PKCS#8", writePEM(t, "PRIVATE KEY", p384DER), p384.Public(), AlgES384},
+		{"RSA PKCS#1", writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), rsaKey.Public(), AlgRS256},
+		{"Ed25519 PKCS#8", writePEM(t, "PRIVATE KEY", edDER), edKey.Public(), AlgEdDSA},
+	}
+
+	payload := []byte("{\n  \"image\": \"nginx:latest\",\n  \"passed\": true\n}\n")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadPrivateKey(tt.keyPath)
+			require.NoError(t, err)
+
+			jws, err := SignDetached(key, payload)
+			require.NoError(t, err)
+			assert.Contains(t, jws, "..")
+
+			pub, err := LoadPublicKey(writePublicKey(t, tt.pub))
+			require.NoError(t, err)
+
+			alg, err := VerifyDetached(pub, payload, jws)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAlg, alg)
+
+			tampered := []byte(strings.Replace(string(payload), "true", "false", 1))
+			_, err = VerifyDetached(pub, tampered, jws)
+			assert.ErrorIs(t, err, ErrInvalidSignature)
+
+			// The private key file can be used for verification too.
+			pubFromPriv, err := LoadPublicKey(tt.keyPath)
+			require.NoError(t, err)
+			_, err = VerifyDetached(pubFromPriv, payload, jws)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestVerifyDetached_WrongKey(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jws, err := SignDetached(signer, []byte("report"))
+	require.NoError(t, err)
+
+	_, err = VerifyDetached(other.Public(), []byte("report"), jws)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyDetached_AlgorithmMismatch(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jws, err := SignDetached(ecKey, []byte("report"))
+	require.NoError(t, err)
+
+	_, err = VerifyDetached(edPub, []byte("report"), jws)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match key algorithm")
+}
+
+func TestVerifyDetached_Malformed(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		jws  string
+	}{
+		{"Not a JWS", "garbage"},
+		{"Attached payload", "eyJhbGciOiJFUzI1NiJ9.cmVwb3J0.c2ln"},
+		{"Invalid header encoding", "!!!..c2ln"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyDetached(key.Public(), []byte("report"), tt.jws)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "malformed")
+		})
+	}
+}
+
+func TestLoadPrivateKey_Errors(t *testing.T) {
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := LoadPrivateKey(filepath.Join(t.TempDir(), "missing.pem"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read key file")
+	})
+
+	t.Run("Not PEM", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key.pem")
+		require.NoError(t, os.WriteFile(path, []byte("not a key"), 0600))
+		_, err := LoadPrivateKey(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not contain PEM data")
+	})
+
+	t.Run("Unsupported block type", func(t *testing.T) {
+		_, err := LoadPrivateKey(writePEM(t, "CERTIFICATE", []byte("x")))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported PEM block type")
+	})
+}
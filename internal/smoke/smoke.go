@@ -0,0 +1,284 @@
+// Package smoke runs an image as a container of the local Docker daemon to
+// verify that it starts: that its command exits successfully, or that it
+// keeps running, and stays healthy when it declares a HEALTHCHECK, for a
+// while.
+package smoke
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// logTailLines is the number of trailing log lines of the container kept in
+// the outcome.
+const logTailLines = 20
+
+// pollInterval is how often the state of a container that must keep running
+// is inspected.
+const pollInterval = 500 * time.Millisecond
+
+// network is the network mode of smoke containers: none, so that the image
+// under test cannot reach other hosts.
+const network = "none"
+
+// Spec describes a smoke run.
+type Spec struct {
+	// Image is the registry reference of the image pinned by digest, pulled
+	// when the daemon does not have it.
+	Image string
+	// ImageID is the ID (config digest) of the validated image. The container
+	// is created from it, so that the image that runs is the image validated
+	// even if the tag moved; empty to create it from Image.
+	ImageID string
+	// Command replaces the CMD of the image when set.
+	Command []string
+	// Timeout is the most the container may take to exit when HealthyFor is
+	// zero.
+	Timeout time.Duration
+	// HealthyFor, when set, requires the container to keep running for this
+	// long instead of exiting, without its health check reporting unhealthy.
+	HealthyFor time.Duration
+	// Platform (os/arch[/variant]) selects the image of a multi-platform
+	// image; empty for the platform of the daemon.
+	Platform string
+	// MemoryMB limits the memory of the container, without swap, in
+	// megabytes; 0 for no limit.
+	MemoryMB uint
+	// PidsLimit limits the number of processes of the container; 0 for no
+	// limit.
+	PidsLimit int64
+}
+
+// Outcome is what a smoke run observed.
+type Outcome struct {
+	// ExitCode is the exit code of the container, or nil when it did not exit.
+	ExitCode *int
+	// Health is the last health status of a container with a HEALTHCHECK
+	// (starting, healthy, unhealthy), or "" without one.
+	Health string
+	// TimedOut reports that the container did not exit within the timeout.
+	TimedOut bool
+	// Pulled reports that the image was pulled because the daemon lacked it.
+	Pulled bool
+	// Elapsed is how long the container ran.
+	Elapsed time.Duration
+	// Logs are the last lines the container wrote to stdout and stderr.
+	Logs []string
+}
+
+// Runtime runs smoke specs. Tests replace the Docker runtime with a fake.
+type Runtime interface {
+	Run(ctx context.Context, spec Spec) (*Outcome, error)
+}
+
+type dockerRuntime struct {
+	client *client.Client
+}
+
+// NewDockerRuntime connects to the Docker daemon configured by the environment
+// (DOCKER_HOST, DOCKER_CERT_PATH, etc.).
+func NewDockerRuntime() (Runtime, error) {
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return &dockerRuntime{client: c}, nil
+}
+
+// Run creates a container of spec.ImageID, or spec.Image, without network
+// access and with the resource limits of spec, starts it, and waits for it to
+// exit or for spec.HealthyFor to pass. The container is always removed.
+func (r *dockerRuntime) Run(ctx context.Context, spec Spec) (*Outcome, error) {
+	defer func() { _ = r.client.Close() }()
+
+	outcome := &Outcome{}
+	id, err := r.create(ctx, spec, outcome)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The run context may be cancelled already; removal must still happen.
+		if err := r.client.ContainerRemove(context.WithoutCancel(ctx), id, container.RemoveOptions{Force: true}); err != nil {
+			log.WithFields(log.Fields{"container": id, "error": err}).Warn("Unable to remove the smoke test container")
+		}
+	}()
+
+	waitCh, waitErrCh := r.client.ContainerWait(ctx, id, container.WaitConditionNextExit)
+	if err := r.client.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to start the container: %w", err)
+	}
+	start := time.Now()
+
+	limit := spec.Timeout
+	if spec.HealthyFor > 0 {
+		limit = spec.HealthyFor
+	}
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("smoke test cancelled: %w", ctx.Err())
+		case err := <-waitErrCh:
+			return nil, fmt.Errorf("failed to wait for the container: %w", err)
+		case res := <-waitCh:
+			code := int(res.StatusCode)
+			outcome.ExitCode = &code
+			break wait
+		case <-timer.C:
+			outcome.TimedOut = spec.HealthyFor == 0
+			break wait
+		case <-ticker.C:
+			if spec.HealthyFor == 0 {
+				continue
+			}
+			health, err := r.health(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			outcome.Health = health
+			if health == container.Unhealthy {
+				break wait
+			}
+		}
+	}
+	outcome.Elapsed = time.Since(start)
+
+	if spec.HealthyFor > 0 && outcome.ExitCode == nil {
+		health, err := r.health(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		outcome.Health = health
+	}
+	outcome.Logs = r.logs(ctx, id)
+	return outcome, nil
+}
+
+// create creates the container of spec, pulling the image when the daemon
+// does not have it.
+func (r *dockerRuntime) create(ctx context.Context, spec Spec, outcome *Outcome) (string, error) {
+	cfg := &container.Config{Image: spec.Image, Cmd: spec.Command}
+	if spec.ImageID != "" {
+		cfg.Image = spec.ImageID
+	}
+	hostCfg := &container.HostConfig{
+		NetworkMode: network,
+		SecurityOpt: []string{"no-new-privileges"},
+		Resources:   resources(spec),
+	}
+	var platform *ocispec.Platform
+	if spec.Platform != "" {
+		p, err := cr.ParsePlatform(spec.Platform)
+		if err != nil {
+			return "", fmt.Errorf("invalid platform %q: %w", spec.Platform, err)
+		}
+		platform = &ocispec.Platform{OS: p.OS, Architecture: p.Architecture, Variant: p.Variant}
+	}
+	resp, err := r.client.ContainerCreate(ctx, cfg, hostCfg, nil, platform, "")
+	if client.IsErrNotFound(err) {
+		log.WithField("image", spec.Image).Info("Pulling the image for the smoke test")
+		if err := r.pull(ctx, spec.Image, spec.Platform); err != nil {
+			return "", err
+		}
+		outcome.Pulled = true
+		resp, err = r.client.ContainerCreate(ctx, cfg, hostCfg, nil, platform, "")
+		if client.IsErrNotFound(err) && spec.ImageID != "" {
+			return "", fmt.Errorf("the pulled image %s is not the validated image %s", spec.Image, spec.ImageID)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create the container: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// resources returns the resource limits of the container of spec.
+func resources(spec Spec) container.Resources {
+	var res container.Resources
+	if spec.MemoryMB > 0 {
+		res.Memory = int64(spec.MemoryMB) * 1024 * 1024
+		// Without swap, so that the limit is the memory the container gets.
+		res.MemorySwap = res.Memory
+	}
+	if spec.PidsLimit > 0 {
+		res.PidsLimit = &spec.PidsLimit
+	}
+	return res
+}
+
+func (r *dockerRuntime) pull(ctx context.Context, ref, platform string) error {
+	rc, err := r.client.ImagePull(ctx, ref, image.PullOptions{Platform: platform})
+	if err != nil {
+		return fmt.Errorf("failed to pull the image: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+	// The pull completes when its progress stream ends.
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		return fmt.Errorf("failed to pull the image: %w", err)
+	}
+	return nil
+}
+
+func (r *dockerRuntime) health(ctx context.Context, id string) (string, error) {
+	info, err := r.client.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect the container: %w", err)
+	}
+	if info.State == nil || info.State.Health == nil {
+		return "", nil
+	}
+	return string(info.State.Health.Status), nil
+}
+
+// logs returns the last lines of the output of the container. Logs are
+// informational, so failures to read them are only logged.
+func (r *dockerRuntime) logs(ctx context.Context, id string) []string {
+	rc, err := r.client.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       fmt.Sprint(logTailLines),
+	})
+	if err != nil {
+		log.WithField("error", err).Debug("Unable to read the smoke test container logs")
+		return nil
+	}
+	defer func() { _ = rc.Close() }()
+
+	var buf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&buf, &buf, rc); err != nil && !errors.Is(err, io.EOF) {
+		log.WithField("error", err).Debug("Unable to read the smoke test container logs")
+	}
+	return TailLines(buf.String(), logTailLines)
+}
+
+// TailLines returns the last n non-empty lines of s.
+func TailLines(s string, n int) []string {
+	var lines []string
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,25 @@
+package smoke
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailLines(t *testing.T) {
+	assert.Nil(t, TailLines("", 3))
+	assert.Equal(t, []string{"starting", "ready"}, TailLines("starting\r\n\n  \nready\n", 3))
+	assert.Equal(t, []string{"3", "4"}, TailLines("1\n2\n3\n4", 2))
+}
+
+func TestResources(t *testing.T) {
+	assert.Equal(t, container.Resources{}, resources(Spec{}))
+
+	res := resources(Spec{MemoryMB: 256, PidsLimit: 64})
+	assert.Equal(t, int64(256*1024*1024), res.Memory)
+	assert.Equal(t, res.Memory, res.MemorySwap, "the container gets no swap")
+	require.NotNil(t, res.PidsLimit)
+	assert.Equal(t, int64(64), *res.PidsLimit)
+}
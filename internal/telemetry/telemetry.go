@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jarfernandez/check-image/internal/output"
+)
+
+const (
+	// EnvEnabled overrides the config file setting in both directions, so a
+	// platform team can force telemetry off on a runner regardless of config.
+	EnvEnabled = "CHECK_IMAGE_TELEMETRY"
+	// EnvEndpoint overrides the telemetry-endpoint config value.
+	EnvEndpoint = "CHECK_IMAGE_TELEMETRY_ENDPOINT"
+
+	sendTimeout = 5 * time.Second
+)
+
+// Settings is the resolved telemetry configuration.
+type Settings struct {
+	Enabled  bool
+	Endpoint string
+}
+
+// Report is the anonymous payload posted to the telemetry endpoint.
+// It deliberately carries only check names and outcome counters: never image
+// names, registries, file paths, or anything else derived from the image.
+type Report struct {
+	Version string                 `json:"version"`
+	Checks  map[string]CheckCounts `json:"checks"`
+}
+
+// CheckCounts holds the outcome counters for a single check.
+type CheckCounts struct {
+	Runs    int `json:"runs"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Errored int `json:"errored"`
+}
+
+// ResolveSettings applies environment overrides on top of the config values.
+// An unparsable CHECK_IMAGE_TELEMETRY value is rejected instead of silently
+// enabling or disabling telemetry.
+func ResolveSettings(enabled bool, endpoint string) (Settings, error) {
+	if v, ok := os.LookupEnv(EnvEnabled); ok && v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %s value %q: expected true or false", EnvEnabled, v)
+		}
+		enabled = b
+	}
+	if v := os.Getenv(EnvEndpoint); v != "" {
+		endpoint = v
+	}
+	return Settings{Enabled: enabled, Endpoint: endpoint}, nil
+}
+
+// BuildReport aggregates check outcomes into an anonymous report.
+func BuildReport(version string, results []output.CheckResult) Report {
+	r := Report{Version: version, Checks: make(map[string]CheckCounts)}
+	for _, res := range results {
+		c := r.Checks[res.Check]
+		c.Runs++
+		switch {
+		case res.Error != "":
+			c.Errored++
+		case res.Passed:
+			c.Passed++
+		default:
+			c.Failed++
+		}
+		r.Checks[res.Check] = c
+	}
+	return r
+}
+
+// Send posts the report as JSON to endpoint. It uses a short timeout so an
+// unreachable collector never delays the validation result noticeably.
+func Send(ctx context.Context, endpoint string, r Report) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error encoding telemetry report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending telemetry report: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jarfernandez/check-image/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSettings(t *testing.T) {
+	tests := []struct {
+		name             string
+		enabled          bool
+		endpoint         string
+		envEnabled       string
+		envEndpoint      string
+		expectedEnabled  bool
+		expectedEndpoint string
+		expectError      bool
+	}{
+		{
+			name:             "Disabled by default",
+			expectedEnabled:  false,
+			expectedEndpoint: "",
+		},
+		{
+			name:             "Enabled by config",
+			enabled:          true,
+			endpoint:         "https://example.com/collect",
+			expectedEnabled:  true,
+			expectedEndpoint: "https://example.com/collect",
+		},
+		{
+			name:             "Env disables config opt-in",
+			enabled:          true,
+			endpoint:         "https://example.com/collect",
+			envEnabled:       "false",
+			expectedEnabled:  false,
+			expectedEndpoint: "https://example.com/collect",
+		},
+		{
+			name:             "Env enables and overrides endpoint",
+			endpoint:         "https://example.com/collect",
+			envEnabled:       "1",
+			envEndpoint:      "https://other.example.com/collect",
+			expectedEnabled:  true,
+			expectedEndpoint: "https://other.example.com/collect",
+		},
+		{
+			name:        "Invalid env value",
+			envEnabled:  "maybe",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvEnabled, tt.envEnabled)
+			t.Setenv(EnvEndpoint, tt.envEndpoint)
+
+			s, err := ResolveSettings(tt.enabled, tt.endpoint)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), EnvEnabled)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedEnabled, s.Enabled)
+			assert.Equal(t, tt.expectedEndpoint, s.Endpoint)
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	results := []output.CheckResult{
+		{Check: "age", Image: "registry.example.com/app:1.0", Passed: true},
+		{Check: "size", Image: "registry.example.com/app:1.0", Passed: false},
+		{Check: "secrets", Image: "registry.example.com/app:1.0", Error: "boom"},
+	}
+
+	r := BuildReport("v1.2.3", results)
+
+	assert.Equal(t, "v1.2.3", r.Version)
+	assert.Equal(t, CheckCounts{Runs: 1, Passed: 1}, r.Checks["age"])
+	assert.Equal(t, CheckCounts{Runs: 1, Failed: 1}, r.Checks["size"])
+	assert.Equal(t, CheckCounts{Runs: 1, Errored: 1}, r.Checks["secrets"])
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "registry.example.com")
+	assert.NotContains(t, string(data), "boom")
+}
+
+func TestSend(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	report := Report{Version: "dev", Checks: map[string]CheckCounts{"age": {Runs: 1, Passed: 1}}}
+	err := Send(context.Background(), server.URL, report)
+	require.NoError(t, err)
+	assert.Equal(t, report, received)
+}
+
+func TestSend_NonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := Send(context.Background(), server.URL, Report{Version: "dev"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestSend_InvalidEndpoint(t *testing.T) {
+	err := Send(context.Background(), "://bad", Report{Version: "dev"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error creating telemetry request")
+}
@@ -5,6 +5,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/jarfernandez/check-image/internal/decision"
 )
 
 // UserInfo holds the parsed components of a USER directive.
@@ -89,6 +91,13 @@ func ValidateUser(user string, policy *Policy) Result {
 	}
 
 	// With policy, check all rules (collect all violations)
+	if policy.RequireNumeric != nil && *policy.RequireNumeric {
+		if info.IsNumeric {
+			decision.Explain("user", "require-numeric", info.UserPart, true, "user %q is a numeric UID", info.UserPart)
+		} else {
+			decision.Explain("user", "require-numeric", info.UserPart, false, "user %q is not a numeric UID", info.UserPart)
+		}
+	}
 	if policy.RequireNumeric != nil && *policy.RequireNumeric && !info.IsNumeric {
 		violations = append(violations, Violation{
 			Rule:    "require-numeric",
@@ -96,6 +105,13 @@ func ValidateUser(user string, policy *Policy) Result {
 		})
 	}
 
+	if len(policy.BlockedUsers) > 0 {
+		if slices.Contains(policy.BlockedUsers, info.UserPart) {
+			decision.Explain("user", "blocked-users", info.UserPart, true, "user %q is in the blocked users list", info.UserPart)
+		} else {
+			decision.Explain("user", "blocked-users", info.UserPart, false, "user %q is not in the blocked users list", info.UserPart)
+		}
+	}
 	if slices.Contains(policy.BlockedUsers, info.UserPart) {
 		violations = append(violations, Violation{
 			Rule:    "blocked-user",
@@ -105,6 +121,12 @@ func ValidateUser(user string, policy *Policy) Result {
 
 	// UID range checks only apply when userPart is numeric
 	if info.IsNumeric {
+		if policy.MinUID != nil {
+			decision.Explain("user", "min-uid", info.UserPart, *info.UID >= uint64(*policy.MinUID), "UID %d is compared with the minimum %d", *info.UID, *policy.MinUID)
+		}
+		if policy.MaxUID != nil {
+			decision.Explain("user", "max-uid", info.UserPart, *info.UID <= uint64(*policy.MaxUID), "UID %d is compared with the maximum %d", *info.UID, *policy.MaxUID)
+		}
 		if policy.MinUID != nil && *info.UID < uint64(*policy.MinUID) {
 			violations = append(violations, Violation{
 				Rule:    "min-uid",